@@ -0,0 +1,113 @@
+package cli
+
+import "github.com/spf13/cobra"
+
+var propletStatus = ""
+
+func NewPropletsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proplets [list|view|metrics]",
+		Short: "Proplets manager",
+		Long:  `List proplets, view a proplet and its resource metrics.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List proplets",
+		Long: `List proplets, optionally filtered by status.
+
+Examples:
+  # List the first 10 proplets
+  propeller-cli proplets list
+
+  # List active proplets
+  propeller-cli proplets list --status active`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 0 {
+				logUsageCmd(*cmd, cmd.Use)
+
+				return
+			}
+
+			page, err := psdk.ListProplets(cmd.Context(), defOffset, defLimit, propletStatus)
+			if err != nil {
+				logErrorCmd(*cmd, err)
+
+				return
+			}
+			logJSONCmd(*cmd, page)
+		},
+	}
+
+	listCmd.Flags().StringVarP(
+		&propletStatus,
+		"status",
+		"s",
+		propletStatus,
+		"Proplet status filter (active or inactive)",
+	)
+
+	viewCmd := &cobra.Command{
+		Use:   "view <id>",
+		Short: "View proplet",
+		Long:  `View proplet.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				logUsageCmd(*cmd, cmd.Use)
+
+				return
+			}
+
+			p, err := psdk.GetProplet(cmd.Context(), args[0])
+			if err != nil {
+				logErrorCmd(*cmd, err)
+
+				return
+			}
+			logJSONCmd(*cmd, p)
+		},
+	}
+
+	metricsCmd := &cobra.Command{
+		Use:   "metrics <id>",
+		Short: "View proplet metrics",
+		Long:  `View the CPU and memory metrics a proplet reported.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				logUsageCmd(*cmd, cmd.Use)
+
+				return
+			}
+
+			page, err := psdk.GetPropletMetrics(cmd.Context(), args[0], defOffset, defLimit)
+			if err != nil {
+				logErrorCmd(*cmd, err)
+
+				return
+			}
+			logJSONCmd(*cmd, page)
+		},
+	}
+
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(viewCmd)
+	cmd.AddCommand(metricsCmd)
+
+	cmd.PersistentFlags().Uint64VarP(
+		&defOffset,
+		"offset",
+		"o",
+		defOffset,
+		"Offset",
+	)
+
+	cmd.PersistentFlags().Uint64VarP(
+		&defLimit,
+		"limit",
+		"l",
+		defLimit,
+		"Limit",
+	)
+
+	return cmd
+}
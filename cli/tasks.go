@@ -48,7 +48,7 @@ Examples:
 				return
 			}
 
-			t, err := psdk.CreateTask(sdk.Task{
+			t, err := psdk.CreateTask(cmd.Context(), sdk.Task{
 				Name:    args[0],
 				CLIArgs: cliArgs,
 			})
@@ -78,7 +78,7 @@ Examples:
 				return
 			}
 
-			t, err := psdk.GetTask(args[0])
+			t, err := psdk.GetTask(cmd.Context(), args[0])
 			if err != nil {
 				logErrorCmd(*cmd, err)
 
@@ -99,7 +99,7 @@ Examples:
 				return
 			}
 
-			t, err := psdk.UpdateTask(sdk.Task{
+			t, err := psdk.UpdateTask(cmd.Context(), sdk.Task{
 				ID: args[0],
 			})
 			if err != nil {
@@ -122,7 +122,7 @@ Examples:
 				return
 			}
 
-			if err := psdk.DeleteTask(args[0]); err != nil {
+			if err := psdk.DeleteTask(cmd.Context(), args[0]); err != nil {
 				logErrorCmd(*cmd, err)
 
 				return
@@ -142,7 +142,7 @@ Examples:
 				return
 			}
 
-			if err := psdk.StartTask(args[0]); err != nil {
+			if err := psdk.StartTask(cmd.Context(), args[0]); err != nil {
 				logErrorCmd(*cmd, err)
 
 				return
@@ -162,7 +162,7 @@ Examples:
 				return
 			}
 
-			if err := psdk.StopTask(args[0]); err != nil {
+			if err := psdk.StopTask(cmd.Context(), args[0]); err != nil {
 				logErrorCmd(*cmd, err)
 
 				return
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	smqsdk "github.com/absmach/magistrala/pkg/sdk"
 	"github.com/absmach/propeller/cli"
@@ -12,6 +17,9 @@ import (
 var (
 	tlsVerification = false
 	managerURL      = "http://localhost:7070"
+	managerToken    = ""
+	managerTimeout  = 30 * time.Second
+	managerRetries  = uint(2)
 	usersURL        = "http://localhost:9002"
 	domainsURL      = "http://localhost:9003"
 	clientsURL      = "http://localhost:9006"
@@ -28,6 +36,9 @@ func main() {
 			sdkConf := sdk.Config{
 				ManagerURL:      managerURL,
 				TLSVerification: tlsVerification,
+				Token:           managerToken,
+				Timeout:         managerTimeout,
+				Retries:         managerRetries,
 			}
 			s := sdk.NewSDK(sdkConf)
 			cli.SetPropellerSDK(s)
@@ -49,9 +60,10 @@ func main() {
 	}
 
 	tasksCmd := cli.NewTasksCmd()
+	propletsCmd := cli.NewPropletsCmd()
 	provisionCmd := cli.NewProvisionCmd()
 
-	rootCmd.AddCommand(tasksCmd, provisionCmd)
+	rootCmd.AddCommand(tasksCmd, propletsCmd, provisionCmd)
 
 	rootCmd.PersistentFlags().StringVarP(
 		&managerURL,
@@ -61,6 +73,27 @@ func main() {
 		"Manager URL",
 	)
 
+	rootCmd.PersistentFlags().StringVar(
+		&managerToken,
+		"manager-token",
+		managerToken,
+		"Bearer token sent to the manager",
+	)
+
+	rootCmd.PersistentFlags().DurationVar(
+		&managerTimeout,
+		"manager-timeout",
+		managerTimeout,
+		"Timeout for each manager request",
+	)
+
+	rootCmd.PersistentFlags().UintVar(
+		&managerRetries,
+		"manager-retries",
+		managerRetries,
+		"Retries for GET, PUT and DELETE manager requests failing with a 5xx status",
+	)
+
 	rootCmd.PersistentFlags().BoolVarP(
 		&tlsVerification,
 		"tls-verification",
@@ -109,7 +142,12 @@ func main() {
 		"Message content type",
 	)
 
-	if err := rootCmd.Execute(); err != nil {
+	// Interrupting the CLI cancels requests in flight, including the
+	// backoff between retries.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		log.Fatal(err)
 	}
 }
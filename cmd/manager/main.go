@@ -20,11 +20,14 @@ import (
 	"github.com/absmach/propeller/manager"
 	"github.com/absmach/propeller/manager/api"
 	"github.com/absmach/propeller/manager/middleware"
+	"github.com/absmach/propeller/pkg/audit"
+	"github.com/absmach/propeller/pkg/logging"
 	"github.com/absmach/propeller/pkg/mqtt"
 	"github.com/absmach/propeller/pkg/plugin"
 	"github.com/absmach/propeller/pkg/scheduler"
 	"github.com/absmach/propeller/pkg/storage"
 	"github.com/caarlos0/env/v11"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
 	"golang.org/x/sync/errgroup"
@@ -39,23 +42,44 @@ const (
 )
 
 type config struct {
-	LogLevel        string        `env:"MANAGER_LOG_LEVEL"              envDefault:"info"`
-	MQTTAddress     string        `env:"MANAGER_MQTT_ADDRESS"           envDefault:"tcp://localhost:1883"`
-	MQTTQoS         uint8         `env:"MANAGER_MQTT_QOS"               envDefault:"2"`
-	MQTTTimeout     time.Duration `env:"MANAGER_MQTT_TIMEOUT"           envDefault:"30s"`
-	MQTTTLSCAPath   string        `env:"MANAGER_MQTT_TLS_CA_CERT"`
-	MQTTTLSCertPath string        `env:"MANAGER_MQTT_TLS_CLIENT_CERT"`
-	MQTTTLSKeyPath  string        `env:"MANAGER_MQTT_TLS_CLIENT_KEY"`
-	MQTTTLSInsecure bool          `env:"MANAGER_MQTT_TLS_INSECURE_SKIP_VERIFY"`
-	DomainID        string        `env:"MANAGER_DOMAIN_ID"`
-	ChannelID       string        `env:"MANAGER_CHANNEL_ID"`
-	ClientID        string        `env:"MANAGER_CLIENT_ID"`
-	ClientKey       string        `env:"MANAGER_CLIENT_KEY"`
-	CoordinatorURL  string        `env:"MANAGER_COORDINATOR_URL"`
-	Server          server.Config
-	OTELURL         url.URL `env:"MANAGER_OTEL_URL"`
-	TraceRatio      float64 `env:"MANAGER_TRACE_RATIO" envDefault:"0"`
-	PluginDir       string  `env:"MANAGER_PLUGIN_DIR"`
+	LogLevel           string        `env:"MANAGER_LOG_LEVEL"              envDefault:"info"`
+	MQTTAddress        string        `env:"MANAGER_MQTT_ADDRESS"           envDefault:"tcp://localhost:1883"`
+	MQTTQoS            uint8         `env:"MANAGER_MQTT_QOS"               envDefault:"2"`
+	MQTTTimeout        time.Duration `env:"MANAGER_MQTT_TIMEOUT"           envDefault:"30s"`
+	MQTTTLSCAPath      string        `env:"MANAGER_MQTT_TLS_CA_CERT"`
+	MQTTTLSCertPath    string        `env:"MANAGER_MQTT_TLS_CLIENT_CERT"`
+	MQTTTLSKeyPath     string        `env:"MANAGER_MQTT_TLS_CLIENT_KEY"`
+	MQTTTLSInsecure    bool          `env:"MANAGER_MQTT_TLS_INSECURE_SKIP_VERIFY"`
+	DomainID           string        `env:"MANAGER_DOMAIN_ID"`
+	ChannelID          string        `env:"MANAGER_CHANNEL_ID"`
+	TopicPrefix        string        `env:"MANAGER_TOPIC_PREFIX"           envDefault:"m"`
+	DeadLetterTopic    string        `env:"MANAGER_DEAD_LETTER_TOPIC"`
+	ClientID           string        `env:"MANAGER_CLIENT_ID"`
+	ClientKey          string        `env:"MANAGER_CLIENT_KEY"`
+	CoordinatorURL     string        `env:"MANAGER_COORDINATOR_URL"`
+	Server             server.Config
+	OTELURL            url.URL `env:"MANAGER_OTEL_URL"`
+	TraceRatio         float64 `env:"MANAGER_TRACE_RATIO" envDefault:"0"`
+	PluginDir          string  `env:"MANAGER_PLUGIN_DIR"`
+	AuditLogFile       string  `env:"MANAGER_AUDIT_LOG_FILE"`
+	SensitiveEnv       string  `env:"MANAGER_SENSITIVE_ENV_KEYS" envDefault:"*_PASSWORD,*_TOKEN,*_SECRET,*_KEY"`
+	Redelivery         manager.RedeliveryConfig
+	Dedup              manager.DedupConfig
+	CheckpointRepo     string `env:"MANAGER_FL_CHECKPOINT_REPOSITORY"`
+	Registry           manager.RegistryConfig
+	MetricsIngest      manager.MetricsIngestConfig
+	Models             manager.ModelsConfig
+	HTTPMaxBodySize    int64 `env:"MANAGER_HTTP_MAX_BODY_SIZE"`
+	HTTPDebugEndpoints bool  `env:"MANAGER_HTTP_DEBUG_ENDPOINTS" envDefault:"false"`
+	IngestLimit        manager.IngestLimitConfig
+	Identities         manager.PublisherIdentityConfig
+	Signing            manager.SigningConfig
+	Orphans            manager.OrphanConfig
+	DefaultTaskEnv     string `env:"MANAGER_DEFAULT_TASK_ENV"`
+	Secrets            manager.SecretStoreConfig
+	Breaker            manager.BreakerConfig
+	UpdateTransform    manager.UpdateTransformConfig
+	MaxPropletCPU      float64 `env:"MANAGER_MAX_PROPLET_CPU_PERCENT"`
 }
 
 func main() {
@@ -81,15 +105,15 @@ func main() {
 		return
 	}
 
-	var level slog.Level
-	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+	logLevel, err := logging.NewLevelVar(cfg.LogLevel)
+	if err != nil {
 		log.Printf("failed to parse log level: %s", err.Error())
 		exitCode = 1
 
 		return
 	}
 	logHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
+		Level: logLevel,
 	})
 	logger := slog.New(logHandler)
 	slog.SetDefault(logger)
@@ -109,6 +133,7 @@ func main() {
 		defer cancel()
 		shutdown(shutdownCtx)
 	}()
+	otel.SetTracerProvider(tp)
 	tracer := tp.Tracer(svcName)
 
 	var mqttTLS *mqtt.TLSConfig
@@ -121,7 +146,7 @@ func main() {
 		}
 	}
 
-	mqttPubSub, err := mqtt.NewPubSub(cfg.MQTTAddress, cfg.MQTTQoS, cfg.ClientID, cfg.ClientID, cfg.ClientKey, cfg.DomainID, cfg.ChannelID, cfg.MQTTTimeout, logger, mqttTLS)
+	mqttPubSub, err := mqtt.NewPubSub(cfg.MQTTAddress, cfg.MQTTQoS, cfg.ClientID, cfg.ClientID, cfg.ClientKey, cfg.DomainID, cfg.ChannelID, cfg.TopicPrefix, cfg.DeadLetterTopic, cfg.MQTTTimeout, logger, mqttTLS)
 	if err != nil {
 		logger.Error("failed to initialize mqtt pubsub", slog.String("error", err.Error()))
 		exitCode = 1
@@ -137,6 +162,8 @@ func main() {
 		return
 	}
 
+	storageCfg.Latency, storageCfg.Errors = storage.MakeMetrics(svcName)
+
 	repos, err := storage.NewRepositories(storageCfg)
 	if err != nil {
 		logger.Error("failed to initialize storage", slog.String("error", err.Error()))
@@ -165,6 +192,24 @@ func main() {
 		}
 	}()
 
+	auditLog := audit.NewNopLog()
+	if cfg.AuditLogFile != "" {
+		fileLog, err := audit.NewFileLog(cfg.AuditLogFile)
+		if err != nil {
+			logger.Error("failed to open audit log", slog.String("error", err.Error()))
+			exitCode = 1
+
+			return
+		}
+		defer func() {
+			if err := fileLog.Close(); err != nil {
+				logger.Error("audit log close error", slog.Any("error", err))
+			}
+		}()
+		auditLog = fileLog
+	}
+
+	sensitiveKeys := manager.ParseSensitiveEnvKeys(cfg.SensitiveEnv)
 	svc, cronScheduler, workflowCoordinator := manager.NewService(
 		repos,
 		scheduler.NewRoundRobin(),
@@ -174,8 +219,27 @@ func main() {
 		cfg.CoordinatorURL,
 		logger,
 		pluginRegistry,
+		manager.WithTopicPrefix(cfg.TopicPrefix),
+		manager.WithAuditLog(auditLog),
+		manager.WithRedelivery(cfg.Redelivery),
+		manager.WithDedup(cfg.Dedup),
+		manager.WithCheckpointRepository(cfg.CheckpointRepo),
+		manager.WithRegistry(cfg.Registry),
+		manager.WithMetricsIngest(cfg.MetricsIngest),
+		manager.WithModels(cfg.Models),
+		manager.WithIngestLimit(cfg.IngestLimit),
+		manager.WithPublisherIdentity(cfg.Identities),
+		manager.WithSigning(cfg.Signing),
+		manager.WithOrphans(cfg.Orphans),
+		manager.WithDefaultTaskEnv(manager.ParseDefaultTaskEnv(cfg.DefaultTaskEnv)),
+		manager.WithSecretStore(manager.NewSecretStore(cfg.Secrets)),
+		manager.WithSensitiveEnvKeys(sensitiveKeys),
+		manager.WithBreaker(cfg.Breaker),
+		manager.WithUpdateTransform(cfg.UpdateTransform),
+		manager.WithMaxPropletCPUPercent(cfg.MaxPropletCPU),
 	)
 	svc = middleware.Plugin(pluginRegistry, logger, svc)
+	svc = middleware.Redact(manager.NewEnvRedactor(sensitiveKeys), svc)
 	svc = middleware.Logging(logger, svc)
 	svc = middleware.Tracing(tracer, svc)
 	counter, latency := prometheus.MakeMetrics(svcName, "api")
@@ -209,7 +273,12 @@ func main() {
 		return
 	}
 
-	hs := httpserver.NewServer(ctx, stop, svcName, httpServerConfig, api.MakeHandler(svc, logger, cfg.ClientID), logger)
+	hs := httpserver.NewServer(ctx, stop, svcName, httpServerConfig, api.MakeHandler(
+		svc, logger, cfg.ClientID,
+		api.WithMaxBodySize(cfg.HTTPMaxBodySize),
+		api.WithDebugEndpoints(cfg.HTTPDebugEndpoints),
+		api.WithLogLevel(logLevel),
+	), logger)
 
 	g.Go(func() error {
 		return hs.Start()
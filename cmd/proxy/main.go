@@ -15,7 +15,9 @@ import (
 
 	"github.com/absmach/magistrala/pkg/jaeger"
 	"github.com/absmach/propeller"
+	"github.com/absmach/propeller/pkg/logging"
 	"github.com/absmach/propeller/pkg/mqtt"
+	"github.com/absmach/propeller/pkg/proplet"
 	"github.com/absmach/propeller/proxy"
 	"github.com/caarlos0/env/v11"
 	"go.opentelemetry.io/otel"
@@ -40,11 +42,16 @@ type config struct {
 	MQTTTLSInsecure bool          `env:"PROXY_MQTT_TLS_INSECURE_SKIP_VERIFY"`
 	DomainID        string        `env:"PROXY_DOMAIN_ID"`
 	ChannelID       string        `env:"PROXY_CHANNEL_ID"`
+	TopicPrefix     string        `env:"PROXY_TOPIC_PREFIX"            envDefault:"m"`
+	DeadLetterTopic string        `env:"PROXY_DEAD_LETTER_TOPIC"`
 	ClientID        string        `env:"PROXY_CLIENT_ID"`
 	ClientKey       string        `env:"PROXY_CLIENT_KEY"`
 	HTTPPort        int           `env:"PROXY_HTTP_PORT"              envDefault:"9191"`
 	OTELURL         url.URL       `env:"PROXY_OTEL_URL"`
 	TraceRatio      float64       `env:"PROXY_TRACE_RATIO"            envDefault:"0"`
+	// DebugEndpoints serves the unauthenticated /loglevel endpoint on the
+	// health server.
+	DebugEndpoints bool `env:"PROXY_HTTP_DEBUG_ENDPOINTS" envDefault:"false"`
 	// HTTP Registry configuration
 	ChunkSize    int    `env:"PROXY_CHUNK_SIZE"            envDefault:"512000"`
 	Authenticate bool   `env:"PROXY_AUTHENTICATE"          envDefault:"false"`
@@ -81,12 +88,12 @@ func main() {
 		log.Fatal("PROXY_DOMAIN_ID, PROXY_CHANNEL_ID, PROXY_CLIENT_ID, and PROXY_CLIENT_KEY must be set")
 	}
 
-	var level slog.Level
-	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+	logLevel, err := logging.NewLevelVar(cfg.LogLevel)
+	if err != nil {
 		log.Fatalf("failed to parse log level: %s", err.Error())
 	}
 	logHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
+		Level: logLevel,
 	})
 	logger := slog.New(logHandler)
 	slog.SetDefault(logger)
@@ -115,7 +122,7 @@ func main() {
 		}
 	}
 
-	mqttPubSub, err := mqtt.NewPubSub(cfg.MQTTAddress, cfg.MQTTQoS, cfg.ClientID, cfg.ClientID, cfg.ClientKey, cfg.DomainID, cfg.ChannelID, cfg.MQTTTimeout, logger, mqttTLS)
+	mqttPubSub, err := mqtt.NewPubSub(cfg.MQTTAddress, cfg.MQTTQoS, cfg.ClientID, cfg.ClientID, cfg.ClientKey, cfg.DomainID, cfg.ChannelID, cfg.TopicPrefix, cfg.DeadLetterTopic, cfg.MQTTTimeout, logger, mqttTLS)
 	if err != nil {
 		logger.Error("failed to initialize mqtt client", slog.Any("error", err))
 
@@ -140,7 +147,7 @@ func main() {
 
 	logger.Info("successfully initialized MQTT and HTTP config")
 
-	service, err := proxy.NewService(ctx, mqttPubSub, cfg.DomainID, cfg.ChannelID, httpCfg, logger)
+	service, err := proxy.NewService(ctx, mqttPubSub, cfg.DomainID, cfg.ChannelID, cfg.TopicPrefix, httpCfg, logger)
 	if err != nil {
 		logger.Error("failed to create proxy service", slog.Any("error", err))
 
@@ -149,16 +156,22 @@ func main() {
 
 	logger.Info("starting proxy service")
 
-	if err := mqttPubSub.Subscribe(ctx, fmt.Sprintf(proxy.SubTopic, cfg.DomainID, cfg.ChannelID), handle(logger, service.ContainerChan())); err != nil {
+	if err := mqttPubSub.Subscribe(ctx, mqtt.BaseTopic(cfg.TopicPrefix, cfg.DomainID, cfg.ChannelID)+proxy.SubTopic, handle(logger, service.ContainerChan())); err != nil {
 		logger.Error("failed to subscribe to container requests", slog.Any("error", err))
 
 		return
 	}
 
+	if err := mqttPubSub.Subscribe(ctx, mqtt.BaseTopic(cfg.TopicPrefix, cfg.DomainID, cfg.ChannelID)+proxy.MissingTopic, handleMissing(logger, service.MissingChan())); err != nil {
+		logger.Error("failed to subscribe to missing chunk requests", slog.Any("error", err))
+
+		return
+	}
+
 	slog.Info("successfully subscribed to topic")
 
 	g.Go(func() error {
-		if err := serveHealth(ctx, cfg, logger); err != nil {
+		if err := serveHealth(ctx, cfg, logger, logLevel); err != nil {
 			logger.Error("health server exited", slog.Any("error", err))
 		}
 
@@ -201,12 +214,15 @@ func initTracerProvider(ctx context.Context, cfg config, logger *slog.Logger) (f
 	}
 }
 
-func serveHealth(ctx context.Context, cfg config, logger *slog.Logger) error {
+func serveHealth(ctx context.Context, cfg config, logger *slog.Logger, logLevel *slog.LevelVar) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, `{"status":"ok","service":"proxy"}`)
 	})
+	if cfg.DebugEndpoints {
+		mux.HandleFunc("/loglevel", logging.LevelHandler(logLevel, logger))
+	}
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.HTTPPort),
 		Handler:      mux,
@@ -230,19 +246,24 @@ func serveHealth(ctx context.Context, cfg config, logger *slog.Logger) error {
 	return nil
 }
 
-func handle(logger *slog.Logger, containerChan chan<- string) func(topic string, msg map[string]any) error {
+func handle(logger *slog.Logger, containerChan chan<- proplet.ChunkRequest) func(topic string, msg map[string]any) error {
 	return func(topic string, msg map[string]any) error {
 		appName, ok := msg["app_name"].(string)
 		if !ok {
 			return errors.New("failed to unmarshal app_name")
 		}
+		req := proplet.ChunkRequest{AppName: appName}
+		if size, ok := msg["max_chunk_size"].(float64); ok {
+			req.MaxChunkSize = int(size)
+		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
 		select {
-		case containerChan <- appName:
-			logger.Info("Received container request", slog.String("app_name", appName))
+		case containerChan <- req:
+			logger.Info("Received container request",
+				slog.String("app_name", appName), slog.Int("max_chunk_size", req.MaxChunkSize))
 
 			return nil
 		case <-ctx.Done():
@@ -253,3 +274,43 @@ func handle(logger *slog.Logger, containerChan chan<- string) func(topic string,
 		}
 	}
 }
+
+func handleMissing(logger *slog.Logger, missingChan chan<- proplet.MissingChunks) func(topic string, msg map[string]any) error {
+	return func(topic string, msg map[string]any) error {
+		appName, ok := msg["app_name"].(string)
+		if !ok {
+			return errors.New("failed to unmarshal app_name")
+		}
+		rawMissing, ok := msg["missing"].([]any)
+		if !ok {
+			return errors.New("failed to unmarshal missing")
+		}
+		req := proplet.MissingChunks{AppName: appName, Missing: make([]int, 0, len(rawMissing))}
+		if size, ok := msg["max_chunk_size"].(float64); ok {
+			req.MaxChunkSize = int(size)
+		}
+		for _, v := range rawMissing {
+			idx, ok := v.(float64)
+			if !ok {
+				return errors.New("failed to unmarshal missing chunk index")
+			}
+			req.Missing = append(req.Missing, int(idx))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		select {
+		case missingChan <- req:
+			logger.Info("Received missing chunk request",
+				slog.String("app_name", appName), slog.Any("missing", req.Missing))
+
+			return nil
+		case <-ctx.Done():
+			logger.Error("Channel full, request timed out waiting for missingChan slot",
+				slog.String("app_name", appName))
+
+			return errors.New("timeout waiting for missing chunk channel slot")
+		}
+	}
+}
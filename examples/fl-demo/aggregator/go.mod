@@ -1,3 +1,7 @@
 module aggregator
 
-go 1.26.0
+go 1.26.3
+
+require github.com/absmach/propeller v0.0.0
+
+replace github.com/absmach/propeller => ../../..
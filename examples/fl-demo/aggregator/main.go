@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -9,10 +10,13 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+
+	"github.com/absmach/propeller/pkg/fl"
 )
 
 type AggregateRequest struct {
-	Updates []Update `json:"updates"`
+	Updates   []Update `json:"updates"`
+	Algorithm string   `json:"algorithm,omitempty"`
 }
 
 type Update struct {
@@ -30,7 +34,49 @@ type AggregatedModel struct {
 	Version int       `json:"version,omitempty"`
 }
 
+// defaultAlgorithm is used for requests that do not name an algorithm. It is
+// set from FL_ALGORITHM.
+var defaultAlgorithm = fl.AlgorithmFedAvg
+
+// aggregate runs the fl package's implementation of algorithm over the
+// updates, so the demo service and the manager share one set of
+// aggregators, including their shape checks.
+func aggregate(algorithm string, updates []Update) (AggregatedModel, error) {
+	aggregator, err := fl.NewAggregator(algorithm)
+	if err != nil {
+		return AggregatedModel{}, err
+	}
+
+	flUpdates := make([]fl.Update, len(updates))
+	for i, u := range updates {
+		flUpdates[i] = fl.Update{
+			RoundID:      u.RoundID,
+			PropletID:    u.PropletID,
+			BaseModelURI: u.BaseModelURI,
+			NumSamples:   u.NumSamples,
+			Metrics:      u.Metrics,
+			Update:       u.Update,
+		}
+	}
+
+	model, err := aggregator.Aggregate(flUpdates)
+	if err != nil {
+		return AggregatedModel{}, err
+	}
+	w, _ := model.Data["w"].([]float64)
+	b, _ := model.Data["b"].(float64)
+
+	return AggregatedModel{W: w, B: b}, nil
+}
+
 func main() {
+	if algorithm := os.Getenv("FL_ALGORITHM"); algorithm != "" {
+		if _, err := fl.NewAggregator(algorithm); err != nil {
+			log.Fatalf("Invalid FL_ALGORITHM: %v", err)
+		}
+		defaultAlgorithm = algorithm
+	}
+
 	port := "8082"
 	if p := os.Getenv("AGGREGATOR_PORT"); p != "" {
 		port = p
@@ -39,7 +85,7 @@ func main() {
 	http.HandleFunc("/health", healthHandler)
 	http.HandleFunc("/aggregate", aggregateHandler)
 
-	slog.Info("Aggregator service starting", "port", port)
+	slog.Info("Aggregator service starting", "port", port, "default_algorithm", defaultAlgorithm)
 
 	srv := &http.Server{
 		Addr: ":" + port,
@@ -75,81 +121,29 @@ func aggregateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	slog.Info("Aggregating updates", "num_updates", len(req.Updates))
-
-	var aggregatedW []float64
-	var aggregatedB float64
-	var totalSamples int
-
-	if len(req.Updates) > 0 && req.Updates[0].Update != nil {
-		// Debug: log the first update structure
-		updateJSON, _ := json.Marshal(req.Updates[0].Update)
-		slog.Info("First update structure", "update", string(updateJSON))
-
-		if w, ok := req.Updates[0].Update["w"].([]interface{}); ok {
-			aggregatedW = make([]float64, len(w))
-			for i := range w {
-				aggregatedW[i] = 0
-			}
-			slog.Info("Initialized weights array", "length", len(aggregatedW))
-		} else {
-			slog.Warn("First update missing 'w' field or wrong type", "update_keys", getKeys(req.Updates[0].Update))
-		}
+	algorithm := req.Algorithm
+	if algorithm == "" {
+		algorithm = defaultAlgorithm
 	}
 
-	for i, update := range req.Updates {
-		if update.Update == nil {
-			slog.Warn("Update is nil", "index", i)
-			continue
-		}
-
-		weight := float64(update.NumSamples)
-		totalSamples += update.NumSamples
-
-		if w, ok := update.Update["w"].([]interface{}); ok {
-			slog.Info("Processing weights", "update_index", i, "num_samples", update.NumSamples, "weights", w)
-			for j, v := range w {
-				if f, ok := v.(float64); ok {
-					if j < len(aggregatedW) {
-						aggregatedW[j] += f * weight
-					}
-				}
-			}
-		} else {
-			slog.Warn("Update missing 'w' field", "update_index", i, "update_keys", getKeys(update.Update))
-		}
-
-		if b, ok := update.Update["b"].(float64); ok {
-			slog.Info("Processing bias", "update_index", i, "bias", b)
-			aggregatedB += b * weight
-		} else {
-			slog.Warn("Update missing 'b' field", "update_index", i)
-		}
+	if _, err := fl.NewAggregator(algorithm); err != nil {
+		http.Error(w, fmt.Sprintf("Unknown aggregation algorithm: %s", algorithm), http.StatusBadRequest)
+		return
 	}
 
-	if totalSamples > 0 {
-		weightNorm := float64(totalSamples)
-		for i := range aggregatedW {
-			aggregatedW[i] /= weightNorm
-		}
-		aggregatedB /= weightNorm
-	}
+	slog.Info("Aggregating updates", "num_updates", len(req.Updates), "algorithm", algorithm)
 
-	model := AggregatedModel{
-		W: aggregatedW,
-		B: aggregatedB,
+	model, err := aggregate(algorithm, req.Updates)
+	if errors.Is(err, fl.ErrShapeMismatch) {
+		slog.Warn("Rejected round with mismatched updates", "error", err)
+		http.Error(w, fmt.Sprintf("Invalid updates: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Aggregation failed: %v", err), http.StatusInternalServerError)
+		return
 	}
-
-	slog.Info("Aggregation complete", "total_samples", totalSamples, "aggregated_w", aggregatedW, "aggregated_b", aggregatedB)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(model)
 }
-
-func getKeys(m map[string]interface{}) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
-}
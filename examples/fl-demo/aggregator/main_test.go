@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+)
+
+// inlineFedAvg is the sample-weighted average aggregateHandler computed
+// inline before aggregation moved behind the Aggregator interface.
+func inlineFedAvg(updates []Update) AggregatedModel {
+	var aggregatedW []float64
+	var aggregatedB float64
+	var totalSamples int
+
+	if len(updates) > 0 && updates[0].Update != nil {
+		if w, ok := updates[0].Update["w"].([]interface{}); ok {
+			aggregatedW = make([]float64, len(w))
+		}
+	}
+	for _, update := range updates {
+		if update.Update == nil {
+			continue
+		}
+		weight := float64(update.NumSamples)
+		totalSamples += update.NumSamples
+		if w, ok := update.Update["w"].([]interface{}); ok {
+			for j, v := range w {
+				if f, ok := v.(float64); ok && j < len(aggregatedW) {
+					aggregatedW[j] += f * weight
+				}
+			}
+		}
+		if b, ok := update.Update["b"].(float64); ok {
+			aggregatedB += b * weight
+		}
+	}
+	if totalSamples > 0 {
+		for i := range aggregatedW {
+			aggregatedW[i] /= float64(totalSamples)
+		}
+		aggregatedB /= float64(totalSamples)
+	}
+
+	return AggregatedModel{W: aggregatedW, B: aggregatedB}
+}
+
+func update(samples int, w []interface{}, b interface{}) Update {
+	u := Update{NumSamples: samples, Update: map[string]interface{}{"w": w}}
+	if b != nil {
+		u.Update["b"] = b
+	}
+
+	return u
+}
+
+func TestFedAvgAggregatorMatchesInlineMath(t *testing.T) {
+	cases := map[string][]Update{
+		"weighted": {
+			update(10, []interface{}{0.1, 0.2, 0.3}, 0.5),
+			update(30, []interface{}{0.4, 0.1, -0.2}, -0.1),
+			update(60, []interface{}{1.0, 0.0, 0.25}, 0.2),
+		},
+		"nil update skipped": {
+			update(5, []interface{}{1.0, 2.0}, 1.0),
+			{NumSamples: 7},
+			update(3, []interface{}{3.0, 4.0}, 2.0),
+		},
+		"negative weights": {
+			update(2, []interface{}{-1.0, 2.0}, -0.5),
+			update(4, []interface{}{5.0, -3.0}, 0.5),
+		},
+		"zero samples": {
+			update(0, []interface{}{1.0, 2.0}, 1.0),
+		},
+	}
+
+	for desc, updates := range cases {
+		got, err := aggregate(fl.AlgorithmFedAvg, updates)
+		if err != nil {
+			t.Fatalf("%s: aggregate: %v", desc, err)
+		}
+		if want := inlineFedAvg(updates); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %+v, want %+v", desc, got, want)
+		}
+	}
+}
+
+// TestFedAvgMatchesManagerPath checks that a round aggregated by this service
+// over HTTP gives the same model as the fl package's FedAvg the manager runs.
+func TestFedAvgMatchesManagerPath(t *testing.T) {
+	body := `{"algorithm":"fedavg","updates":[
+		{"proplet_id":"p1","num_samples":10,"update":{"w":[0.1,0.2,0.3],"b":0.5}},
+		{"proplet_id":"p2","num_samples":30,"update":{"w":[0.4,0.1,-0.2],"b":-0.1}},
+		{"proplet_id":"p3","num_samples":60,"update":{"w":[1.0,0.0,0.25],"b":0.2}}
+	]}`
+	rec := httptest.NewRecorder()
+	aggregateHandler(rec, httptest.NewRequest(http.MethodPost, "/aggregate", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var got AggregatedModel
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode model: %v", err)
+	}
+
+	var req struct {
+		Updates []fl.Update `json:"updates"`
+	}
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	want, err := fl.NewFedAvgAggregator().Aggregate(req.Updates)
+	if err != nil {
+		t.Fatalf("fl fedavg: %v", err)
+	}
+	if !reflect.DeepEqual(got.W, want.Data["w"]) || got.B != want.Data["b"] {
+		t.Fatalf("service model %+v differs from fl model %+v", got, want.Data)
+	}
+}
+
+func TestAggregateHandlerDefaultAlgorithm(t *testing.T) {
+	defaultAlgorithm = fl.AlgorithmMedian
+	t.Cleanup(func() { defaultAlgorithm = fl.AlgorithmFedAvg })
+
+	body := `{"updates":[
+		{"num_samples":1,"update":{"w":[1.0],"b":1.0}},
+		{"num_samples":1,"update":{"w":[2.0],"b":2.0}},
+		{"num_samples":100,"update":{"w":[9.0],"b":9.0}}
+	]}`
+	rec := httptest.NewRecorder()
+	aggregateHandler(rec, httptest.NewRequest(http.MethodPost, "/aggregate", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var got AggregatedModel
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode model: %v", err)
+	}
+	if want := (AggregatedModel{W: []float64{2.0}, B: 2.0}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want the median %+v", got, want)
+	}
+}
+
+func TestAggregateHandlerUnknownAlgorithm(t *testing.T) {
+	body := `{"algorithm":"fedprox","updates":[{"num_samples":1,"update":{"w":[1.0],"b":1.0}}]}`
+	rec := httptest.NewRecorder()
+	aggregateHandler(rec, httptest.NewRequest(http.MethodPost, "/aggregate", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAggregateRejectsMismatchedShapes(t *testing.T) {
+	cases := map[string]string{
+		"shorter w": `{"updates":[
+			{"proplet_id":"p1","num_samples":10,"update":{"w":[1.0,2.0,3.0],"b":1.0}},
+			{"proplet_id":"p2","num_samples":10,"update":{"w":[1.0,2.0],"b":1.0}}
+		]}`,
+		"missing b": `{"updates":[
+			{"proplet_id":"p1","num_samples":10,"update":{"w":[1.0,2.0],"b":1.0}},
+			{"proplet_id":"p2","num_samples":10,"update":{"w":[1.0,2.0]}}
+		]}`,
+		"missing w": `{"algorithm":"median","updates":[
+			{"proplet_id":"p1","num_samples":10,"update":{"w":[1.0,2.0],"b":1.0}},
+			{"proplet_id":"p2","num_samples":10,"update":{"b":1.0}}
+		]}`,
+	}
+	for desc, body := range cases {
+		rec := httptest.NewRecorder()
+		aggregateHandler(rec, httptest.NewRequest(http.MethodPost, "/aggregate", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want %d", desc, rec.Code, http.StatusBadRequest)
+		}
+		if !strings.Contains(rec.Body.String(), `update 1 from proplet "p2"`) {
+			t.Fatalf("%s: body %q does not name the offending update", desc, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultConnectAttempts   = 10
+	defaultConnectBackoff    = time.Second
+	defaultConnectMaxBackoff = 30 * time.Second
+)
+
+// connectRetry bounds how long startup waits for the MQTT broker, so a broker
+// that comes up shortly after the coordinator does not leave it without push
+// notifications.
+type connectRetry struct {
+	attempts   int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	sleep      func(time.Duration)
+}
+
+// connectRetryFromEnv reads MQTT_CONNECT_ATTEMPTS, MQTT_CONNECT_BACKOFF and
+// MQTT_CONNECT_MAX_BACKOFF, keeping the defaults for unset or invalid values.
+func connectRetryFromEnv() connectRetry {
+	r := connectRetry{
+		attempts:   defaultConnectAttempts,
+		backoff:    defaultConnectBackoff,
+		maxBackoff: defaultConnectMaxBackoff,
+		sleep:      time.Sleep,
+	}
+	if v := os.Getenv("MQTT_CONNECT_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			r.attempts = n
+		}
+	}
+	if v := os.Getenv("MQTT_CONNECT_BACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			r.backoff = d
+		}
+	}
+	if v := os.Getenv("MQTT_CONNECT_MAX_BACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			r.maxBackoff = d
+		}
+	}
+
+	return r
+}
+
+// do calls connect until it succeeds or the attempts run out, doubling the
+// wait between attempts up to maxBackoff. It returns the last error.
+func (r connectRetry) do(connect func() error) error {
+	backoff := r.backoff
+	var err error
+	for attempt := 1; attempt <= r.attempts; attempt++ {
+		if err = connect(); err == nil {
+			return nil
+		}
+		if attempt == r.attempts {
+			break
+		}
+		slog.Warn("MQTT connect failed, retrying", "attempt", attempt, "max_attempts", r.attempts, "backoff", backoff, "error", err)
+		r.sleep(backoff)
+		backoff = min(backoff*2, r.maxBackoff)
+	}
+
+	return err
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConnectRetry(t *testing.T) {
+	errRefused := errors.New("connection refused")
+
+	cases := []struct {
+		desc      string
+		failures  int
+		attempts  int
+		wantErr   bool
+		wantCalls int
+		wantWaits []time.Duration
+	}{
+		{
+			desc:      "connects first time",
+			attempts:  5,
+			wantCalls: 1,
+		},
+		{
+			desc:      "fails then connects",
+			failures:  3,
+			attempts:  5,
+			wantCalls: 4,
+			wantWaits: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			desc:      "gives up after the last attempt",
+			failures:  10,
+			attempts:  3,
+			wantErr:   true,
+			wantCalls: 3,
+			wantWaits: []time.Duration{time.Second, 2 * time.Second},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var waits []time.Duration
+			r := connectRetry{
+				attempts:   tc.attempts,
+				backoff:    time.Second,
+				maxBackoff: 3 * time.Second,
+				sleep:      func(d time.Duration) { waits = append(waits, d) },
+			}
+
+			calls := 0
+			err := r.do(func() error {
+				calls++
+				if calls <= tc.failures {
+					return errRefused
+				}
+
+				return nil
+			})
+
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("err = %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr && !errors.Is(err, errRefused) {
+				t.Errorf("err = %v, want %v", err, errRefused)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("connect called %d times, want %d", calls, tc.wantCalls)
+			}
+			if len(waits) != len(tc.wantWaits) {
+				t.Fatalf("waits = %v, want %v", waits, tc.wantWaits)
+			}
+			for i := range waits {
+				if waits[i] != tc.wantWaits[i] {
+					t.Errorf("waits = %v, want %v", waits, tc.wantWaits)
+
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestConnectRetryFromEnv(t *testing.T) {
+	t.Setenv("MQTT_CONNECT_ATTEMPTS", "3")
+	t.Setenv("MQTT_CONNECT_BACKOFF", "250ms")
+	t.Setenv("MQTT_CONNECT_MAX_BACKOFF", "bogus")
+
+	r := connectRetryFromEnv()
+	if r.attempts != 3 || r.backoff != 250*time.Millisecond || r.maxBackoff != defaultConnectMaxBackoff {
+		t.Errorf("connectRetryFromEnv() = %+v", r)
+	}
+}
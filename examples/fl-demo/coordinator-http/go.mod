@@ -1,14 +1,17 @@
 module coordinator-http
 
-go 1.26.0
+go 1.26.3
 
 require (
+	github.com/absmach/propeller v0.0.0
 	github.com/eclipse/paho.mqtt.golang v1.5.1
 	github.com/gorilla/mux v1.8.1
 )
 
 require (
-	github.com/gorilla/websocket v1.5.3 // indirect
-	golang.org/x/net v0.52.0 // indirect
+	github.com/gorilla/websocket v1.5.4-0.20250319132907-e064f32e3674 // indirect
+	golang.org/x/net v0.56.0 // indirect
 	golang.org/x/sync v0.21.0 // indirect
 )
+
+replace github.com/absmach/propeller => ../../..
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,24 +10,73 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/absmach/propeller/pkg/fl"
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/gorilla/mux"
 )
 
 type RoundState struct {
-	RoundID   string
-	ModelURI  string
-	KOfN      int
-	TimeoutS  int
-	StartTime time.Time
-	Updates   []Update
-	Completed bool
-	mu        sync.Mutex
+	RoundID              string
+	ExperimentID         string
+	Round                uint64
+	TotalRounds          uint64
+	ModelURI             string
+	KOfN                 int
+	QuorumPolicy         string
+	RequiredParticipants []string
+	TimeoutS             int
+	Algorithm            string
+	StartTime            time.Time
+	Updates              []Update
+	Completed            bool
+	GracePeriod          time.Duration
+	Expected             int
+	graceTimer           *time.Timer
+	mu                   sync.Mutex
+}
+
+// quorum is the round's completion rule, evaluated by the same fl package the
+// manager uses so both sides agree on when a round is complete.
+func (r *RoundState) quorum() fl.Quorum {
+	return fl.Quorum{
+		Policy:   fl.QuorumPolicy(r.QuorumPolicy),
+		K:        r.KOfN,
+		Required: r.RequiredParticipants,
+	}
+}
+
+// reporters returns the distinct proplets that have sent an update.
+func (r *RoundState) reporters() []string {
+	ids := make([]string, 0, len(r.Updates))
+	for _, u := range r.Updates {
+		if !slices.Contains(ids, u.PropletID) {
+			ids = append(ids, u.PropletID)
+		}
+	}
+	return ids
+}
+
+// requiredReported reports whether every required participant has sent an
+// update.
+func (r *RoundState) requiredReported() bool {
+	return fl.Quorum{Policy: fl.QuorumAllRequired, Required: r.RequiredParticipants}.Met(r.reporters())
+}
+
+// lastRound reports whether the round is the final one of its experiment, in
+// which case no further round is started once it has been aggregated.
+func (r *RoundState) lastRound() bool {
+	return r.TotalRounds > 0 && r.Round >= r.TotalRounds
+}
+
+// quorumMet applies the round's quorum policy to the updates received so far.
+func (r *RoundState) quorumMet() bool {
+	return r.quorum().Met(r.reporters())
 }
 
 type Update struct {
@@ -39,6 +89,15 @@ type Update struct {
 	ReceivedAt   string                 `json:"received_at,omitempty"`
 }
 
+type ParticipantStatus struct {
+	PropletID      string                 `json:"proplet_id"`
+	UpdateReceived bool                   `json:"update_received"`
+	UpdateBytes    int                    `json:"update_bytes"`
+	NumSamples     int                    `json:"num_samples"`
+	Metrics        map[string]interface{} `json:"metrics,omitempty"`
+	ReceivedAt     string                 `json:"received_at,omitempty"`
+}
+
 type Task struct {
 	RoundID     string                 `json:"round_id"`
 	ModelRef    string                 `json:"model_ref"`
@@ -59,20 +118,43 @@ type ExperimentConfig struct {
 	KOfN          int                    `json:"k_of_n"`
 	TimeoutS      int                    `json:"timeout_s"`
 	TaskWasmImage string                 `json:"task_wasm_image,omitempty"`
+	Algorithm     string                 `json:"algorithm,omitempty"`
+
+	QuorumPolicy         string   `json:"quorum_policy,omitempty"`
+	RequiredParticipants []string `json:"required_participants,omitempty"`
+
+	AggregationGracePeriodS int `json:"aggregation_grace_period_s,omitempty"`
+
+	// Round is the 1-based position of this round in the experiment and
+	// TotalRounds the number of rounds the experiment runs. Leaving
+	// TotalRounds unset keeps the experiment open-ended.
+	Round       uint64 `json:"round,omitempty"`
+	TotalRounds uint64 `json:"total_rounds,omitempty"`
 }
 
 var (
 	rounds           = make(map[string]*RoundState)
 	roundsMu         sync.RWMutex
-	modelVersion     = 0
-	modelMu          sync.Mutex
+	models           = newModelRegistry()
 	httpClient       *http.Client
 	modelRegistryURL string
 	aggregatorURL    string
 	mqttClient       mqtt.Client
 	mqttEnabled      bool
+
+	// aggregations tracks in-flight aggregateAndAdvance goroutines so that
+	// shutdown can let them finish instead of losing the round.
+	aggregations   sync.WaitGroup
+	aggregationsMu sync.Mutex
+	draining       bool
+
+	// finishedExperiments holds the experiments whose last round has been
+	// aggregated. It is guarded by roundsMu.
+	finishedExperiments = make(map[string]bool)
 )
 
+const defaultShutdownTimeout = 30 * time.Second
+
 func main() {
 	port := "8080"
 	if p := os.Getenv("COORDINATOR_PORT"); p != "" {
@@ -119,12 +201,18 @@ func main() {
 			opts.SetPassword(mqttPassword)
 		}
 		opts.SetAutoReconnect(true)
-		opts.SetConnectRetry(true)
-		opts.SetConnectRetryInterval(5 * time.Second)
 
+		// Paho only reconnects after a first successful connect, so startup
+		// retries on its own with a bounded backoff.
 		mqttClient = mqtt.NewClient(opts)
-		if token := mqttClient.Connect(); token.Wait() && token.Error() != nil {
-			slog.Warn("Failed to connect to MQTT broker, push notifications disabled", "error", token.Error())
+		err := connectRetryFromEnv().do(func() error {
+			token := mqttClient.Connect()
+			token.Wait()
+
+			return token.Error()
+		})
+		if err != nil {
+			slog.Warn("Failed to connect to MQTT broker, push notifications disabled", "error", err)
 			mqttEnabled = false
 		} else {
 			mqttEnabled = true
@@ -156,13 +244,75 @@ func main() {
 		}
 	}()
 
-	go checkRoundTimeouts()
+	ctx, cancel := context.WithCancel(context.Background())
+	timeoutsDone := make(chan struct{})
+	go func() {
+		defer close(timeoutsDone)
+		checkRoundTimeouts(ctx)
+	}()
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	<-sigChan
 
 	slog.Info("Shutting down FML Coordinator")
+
+	shutdownTimeout := defaultShutdownTimeout
+	if v := os.Getenv("COORDINATOR_SHUTDOWN_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			shutdownTimeout = d
+		}
+	}
+
+	cancel()
+	<-timeoutsDone
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("HTTP server shutdown did not complete", "error", err)
+	}
+	if !drainAggregations(shutdownCtx) {
+		slog.Warn("Shutdown timed out with aggregations still in flight")
+	}
+}
+
+// startAggregation runs aggregateAndAdvance in the background unless the
+// coordinator is shutting down.
+func startAggregation(round *RoundState) {
+	aggregationsMu.Lock()
+	defer aggregationsMu.Unlock()
+
+	if draining {
+		slog.Warn("Coordinator shutting down, not aggregating round", "round_id", round.RoundID)
+		return
+	}
+	aggregations.Add(1)
+	go func() {
+		defer aggregations.Done()
+		aggregateAndAdvance(round)
+	}()
+}
+
+// drainAggregations stops new aggregations from starting and waits for the
+// in-flight ones. It reports false if ctx ends first.
+func drainAggregations(ctx context.Context) bool {
+	aggregationsMu.Lock()
+	draining = true
+	aggregationsMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		aggregations.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
 func healthHandler(w http.ResponseWriter, r *http.Request) {
@@ -181,10 +331,25 @@ func postExperimentHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "round_id is required", http.StatusBadRequest)
 		return
 	}
+	if config.TotalRounds > 0 {
+		if config.Round == 0 {
+			http.Error(w, "round is required when total_rounds is set", http.StatusBadRequest)
+			return
+		}
+		if config.Round > config.TotalRounds {
+			http.Error(w, fmt.Sprintf("round %d exceeds total_rounds %d", config.Round, config.TotalRounds), http.StatusConflict)
+			return
+		}
+	}
 
-	if config.KOfN == 0 {
+	if config.KOfN == 0 && (config.QuorumPolicy == "" || config.QuorumPolicy == string(fl.QuorumAnyK)) {
 		config.KOfN = 3
 	}
+	quorum := fl.Quorum{Policy: fl.QuorumPolicy(config.QuorumPolicy), K: config.KOfN, Required: config.RequiredParticipants}
+	if err := quorum.Validate(config.Participants); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if config.TimeoutS == 0 {
 		config.TimeoutS = 60
 	}
@@ -196,7 +361,9 @@ func postExperimentHandler(w http.ResponseWriter, r *http.Request) {
 		"experiment_id", config.ExperimentID,
 		"round_id", config.RoundID,
 		"model_ref", config.ModelRef,
-		"k_of_n", config.KOfN)
+		"k_of_n", config.KOfN,
+		"quorum_policy", config.QuorumPolicy,
+		"algorithm", config.Algorithm)
 
 	modelVersion := extractModelVersion(config.ModelRef)
 
@@ -215,14 +382,27 @@ func postExperimentHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	roundsMu.Lock()
+	if config.ExperimentID != "" && finishedExperiments[config.ExperimentID] {
+		roundsMu.Unlock()
+		http.Error(w, fmt.Sprintf("experiment %s has completed all of its rounds", config.ExperimentID), http.StatusConflict)
+		return
+	}
 	round := &RoundState{
-		RoundID:   config.RoundID,
-		ModelURI:  config.ModelRef,
-		KOfN:      config.KOfN,
-		TimeoutS:  config.TimeoutS,
-		StartTime: time.Now(),
-		Updates:   make([]Update, 0),
-		Completed: false,
+		RoundID:              config.RoundID,
+		ExperimentID:         config.ExperimentID,
+		Round:                config.Round,
+		TotalRounds:          config.TotalRounds,
+		ModelURI:             config.ModelRef,
+		KOfN:                 config.KOfN,
+		QuorumPolicy:         config.QuorumPolicy,
+		RequiredParticipants: config.RequiredParticipants,
+		TimeoutS:             config.TimeoutS,
+		Algorithm:            config.Algorithm,
+		StartTime:            time.Now(),
+		Updates:              make([]Update, 0),
+		Completed:            false,
+		GracePeriod:          time.Duration(config.AggregationGracePeriodS) * time.Second,
+		Expected:             len(config.Participants),
 	}
 	rounds[config.RoundID] = round
 	roundsMu.Unlock()
@@ -273,30 +453,25 @@ func getTaskHandler(w http.ResponseWriter, r *http.Request) {
 
 	if !exists {
 		roundsMu.Lock()
-		modelMu.Lock()
-		currentVersion := modelVersion
-		modelMu.Unlock()
-
-		round = &RoundState{
-			RoundID:   roundID,
-			ModelURI:  fmt.Sprintf("fl/models/global_model_v%d", currentVersion),
-			KOfN:      3,
-			TimeoutS:  60,
-			StartTime: time.Now(),
-			Updates:   make([]Update, 0),
-			Completed: false,
+		if round, exists = rounds[roundID]; !exists {
+			round = &RoundState{
+				RoundID:   roundID,
+				ModelURI:  fmt.Sprintf("fl/models/global_model_v%d", models.Current()),
+				KOfN:      3,
+				TimeoutS:  60,
+				StartTime: time.Now(),
+				Updates:   make([]Update, 0),
+				Completed: false,
+			}
+			rounds[roundID] = round
+			slog.Info("Initialized round from task request", "round_id", roundID)
 		}
-		rounds[roundID] = round
 		roundsMu.Unlock()
-		slog.Info("Initialized round from task request", "round_id", roundID)
 	}
 
 	modelRef := round.ModelURI
 	if modelRef == "" {
-		modelMu.Lock()
-		currentVersion := modelVersion
-		modelMu.Unlock()
-		modelRef = fmt.Sprintf("fl/models/global_model_v%d", currentVersion)
+		modelRef = fmt.Sprintf("fl/models/global_model_v%d", models.Current())
 	}
 
 	task := Task{
@@ -364,16 +539,41 @@ func getRoundCompleteHandler(w http.ResponseWriter, r *http.Request) {
 	round.mu.Lock()
 	completed := round.Completed
 	numUpdates := len(round.Updates)
+	kOfN := round.KOfN
+	participants := make([]ParticipantStatus, 0, len(round.Updates))
+	for _, update := range round.Updates {
+		participants = append(participants, participantStatus(update))
+	}
 	round.mu.Unlock()
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"round_id":    roundID,
-		"completed":   completed,
-		"num_updates": numUpdates,
+		"round_id":     roundID,
+		"completed":    completed,
+		"num_updates":  numUpdates,
+		"k_of_n":       kOfN,
+		"participants": participants,
 	})
 }
 
+func participantStatus(update Update) ParticipantStatus {
+	size := 0
+	if len(update.Update) > 0 {
+		if data, err := json.Marshal(update.Update); err == nil {
+			size = len(data)
+		}
+	}
+
+	return ParticipantStatus{
+		PropletID:      update.PropletID,
+		UpdateReceived: len(update.Update) > 0,
+		UpdateBytes:    size,
+		NumSamples:     update.NumSamples,
+		Metrics:        update.Metrics,
+		ReceivedAt:     update.ReceivedAt,
+	}
+}
+
 func getNextRoundHandler(w http.ResponseWriter, r *http.Request) {
 	roundsMu.RLock()
 	defer roundsMu.RUnlock()
@@ -398,9 +598,19 @@ func getNextRoundHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	modelMu.Lock()
-	currentVersion := modelVersion
-	modelMu.Unlock()
+	currentVersion := models.Current()
+
+	if latestRound.lastRound() {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"next_round_available": false,
+			"last_completed_round": latestRoundID,
+			"new_model_version":    currentVersion,
+			"model_uri":            fmt.Sprintf("fl/models/global_model_v%d", currentVersion),
+			"status":               "experiment_complete",
+		})
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -451,13 +661,41 @@ func processUpdate(update Update) {
 	}
 
 	round.Updates = append(round.Updates, update)
-	slog.Info("Received update", "round_id", roundID, "proplet_id", update.PropletID, "total_updates", len(round.Updates), "k_of_n", round.KOfN)
+	status := participantStatus(update)
+	slog.Info("Received update", "round_id", roundID, "proplet_id", update.PropletID, "update_bytes", status.UpdateBytes, "num_samples", status.NumSamples, "total_updates", len(round.Updates), "k_of_n", round.KOfN)
 
-	if len(round.Updates) >= round.KOfN {
-		slog.Info("Round complete: received k_of_n updates", "round_id", roundID, "updates", len(round.Updates))
-		round.Completed = true
-		go aggregateAndAdvance(round)
+	if !round.quorumMet() {
+		return
 	}
+
+	if round.GracePeriod > 0 && len(round.Updates) < round.Expected {
+		if round.graceTimer == nil {
+			slog.Info("Quorum reached, waiting for stragglers", "round_id", roundID, "updates", len(round.Updates), "grace_period", round.GracePeriod)
+			round.graceTimer = time.AfterFunc(round.GracePeriod, func() { expireGrace(round) })
+		}
+		return
+	}
+
+	if round.graceTimer != nil {
+		round.graceTimer.Stop()
+	}
+	slog.Info("Round complete: quorum reached", "round_id", roundID, "updates", len(round.Updates), "quorum_policy", round.QuorumPolicy)
+	round.Completed = true
+	startAggregation(round)
+}
+
+// expireGrace aggregates a round whose grace period ran out before every
+// expected participant reported.
+func expireGrace(round *RoundState) {
+	round.mu.Lock()
+	defer round.mu.Unlock()
+
+	if round.Completed {
+		return
+	}
+	slog.Info("Round complete: grace period elapsed", "round_id", round.RoundID, "updates", len(round.Updates), "expected", round.Expected)
+	round.Completed = true
+	startAggregation(round)
 }
 
 // retryWithBackoff performs an HTTP request with exponential backoff retry
@@ -498,10 +736,11 @@ func aggregateAndAdvance(round *RoundState) {
 		return
 	}
 
-	slog.Info("Calling aggregator service", "round_id", round.RoundID, "num_updates", len(updates))
+	slog.Info("Calling aggregator service", "round_id", round.RoundID, "num_updates", len(updates), "algorithm", round.Algorithm)
 
 	aggregatorReq := map[string]interface{}{
-		"updates": updates,
+		"updates":   updates,
+		"algorithm": round.Algorithm,
 	}
 
 	reqBody, err := json.Marshal(aggregatorReq)
@@ -536,10 +775,7 @@ func aggregateAndAdvance(round *RoundState) {
 		return
 	}
 
-	modelMu.Lock()
-	modelVersion++
-	newVersion := modelVersion
-	modelMu.Unlock()
+	newVersion := models.Store(aggregatedModel)
 
 	modelData := map[string]interface{}{
 		"version": newVersion,
@@ -574,12 +810,27 @@ func aggregateAndAdvance(round *RoundState) {
 
 	slog.Info("Aggregated model stored", "round_id", round.RoundID, "version", newVersion)
 
+	experimentDone := round.lastRound()
+	if experimentDone {
+		if round.ExperimentID != "" {
+			roundsMu.Lock()
+			finishedExperiments[round.ExperimentID] = true
+			roundsMu.Unlock()
+		}
+		slog.Info("Experiment complete: last round aggregated",
+			"experiment_id", round.ExperimentID,
+			"round_id", round.RoundID,
+			"total_rounds", round.TotalRounds)
+	}
+
 	nextRoundNotification := map[string]interface{}{
 		"round_id":             round.RoundID,
 		"new_model_version":    newVersion,
 		"model_uri":            fmt.Sprintf("fl/models/global_model_v%d", newVersion),
+		"model":                aggregatedModel,
 		"status":               "complete",
-		"next_round_available": true,
+		"next_round_available": !experimentDone,
+		"experiment_complete":  experimentDone,
 		"timestamp":            time.Now().UTC().Format(time.RFC3339),
 	}
 
@@ -606,12 +857,18 @@ func aggregateAndAdvance(round *RoundState) {
 	}
 }
 
-func checkRoundTimeouts() {
+func checkRoundTimeouts(ctx context.Context) {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		now := time.Now()
+	for {
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			return
+		case now = <-ticker.C:
+		}
+
 		// Collect rounds that need timeout checking while holding read lock
 		roundsMu.RLock()
 		roundsToCheck := make([]*RoundState, 0, len(rounds))
@@ -628,8 +885,11 @@ func checkRoundTimeouts() {
 				if elapsed >= time.Duration(round.TimeoutS)*time.Second {
 					slog.Warn("Round timeout exceeded", "round_id", round.RoundID, "timeout_s", round.TimeoutS, "updates", len(round.Updates))
 					round.Completed = true
-					if len(round.Updates) > 0 {
-						go aggregateAndAdvance(round)
+					switch {
+					case !round.requiredReported():
+						slog.Warn("Required participants missing at timeout, skipping aggregation", "round_id", round.RoundID, "required", round.RequiredParticipants)
+					case len(round.Updates) > 0:
+						startAggregation(round)
 					}
 				}
 			}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShutdownDrainsInFlightAggregation(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	aggregator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(entered)
+		<-release
+		_ = json.NewEncoder(w).Encode(map[string]any{"w": []float64{0.1}})
+	}))
+	defer aggregator.Close()
+
+	stored := make(chan struct{})
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		close(stored)
+	}))
+	defer registry.Close()
+
+	aggregatorURL = aggregator.URL
+	modelRegistryURL = registry.URL
+	httpClient = &http.Client{Timeout: 5 * time.Second}
+
+	startAggregation(&RoundState{
+		RoundID: "round-1",
+		Updates: []Update{{RoundID: "round-1", PropletID: "proplet-a", NumSamples: 1}},
+	})
+	<-entered
+
+	drained := make(chan bool)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		drained <- drainAggregations(ctx)
+	}()
+
+	select {
+	case <-drained:
+		t.Fatal("shutdown returned while aggregation was in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if ok := <-drained; !ok {
+		t.Fatal("shutdown timed out waiting for aggregation")
+	}
+	select {
+	case <-stored:
+	default:
+		t.Fatal("aggregated model was not stored before shutdown returned")
+	}
+
+	startAggregation(&RoundState{RoundID: "round-2"})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if !drainAggregations(ctx) {
+		t.Fatal("aggregation started after shutdown began")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// modelRegistry tracks the global model versions produced by aggregation
+// and which of them is current. It owns its lock, so callers never hold it
+// across other coordinator state.
+type modelRegistry struct {
+	mu      sync.RWMutex
+	current int
+	latest  int
+	models  map[int]map[string]interface{}
+}
+
+func newModelRegistry() *modelRegistry {
+	return &modelRegistry{models: make(map[int]map[string]interface{})}
+}
+
+// Current returns the current global model version.
+func (r *modelRegistry) Current() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current
+}
+
+// Store saves model as the version after the newest one, makes it current
+// and returns its version.
+func (r *modelRegistry) Store(model map[string]interface{}) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latest++
+	r.models[r.latest] = model
+	r.current = r.latest
+
+	return r.latest
+}
+
+func (r *modelRegistry) Get(version int) (map[string]interface{}, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	model, ok := r.models[version]
+
+	return model, ok
+}
+
+// List returns the stored versions in ascending order.
+func (r *modelRegistry) List() []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(r.models))
+}
+
+// SetCurrent makes a stored version current and reports whether it exists.
+func (r *modelRegistry) SetCurrent(version int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.models[version]; !ok {
+		return false
+	}
+	r.current = version
+
+	return true
+}
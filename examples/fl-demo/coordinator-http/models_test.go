@@ -0,0 +1,56 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestModelRegistryConcurrentAccess(t *testing.T) {
+	reg := newModelRegistry()
+
+	const writers, perWriter = 8, 50
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range perWriter {
+				version := reg.Store(map[string]interface{}{"w": []float64{0.1}})
+				if _, ok := reg.Get(version); !ok {
+					t.Errorf("stored version %d not found", version)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range perWriter {
+				reg.SetCurrent(reg.Current())
+				_ = reg.List()
+			}
+		}()
+	}
+	wg.Wait()
+
+	versions := reg.List()
+	if len(versions) != writers*perWriter {
+		t.Fatalf("got %d versions, want %d", len(versions), writers*perWriter)
+	}
+	for i, version := range versions {
+		if version != i+1 {
+			t.Fatalf("versions not contiguous: got %d at index %d", version, i)
+		}
+	}
+	if got := reg.Current(); got != writers*perWriter {
+		t.Fatalf("current version %d, want %d", got, writers*perWriter)
+	}
+
+	if !reg.SetCurrent(3) || reg.Current() != 3 {
+		t.Fatalf("rollback to version 3 failed, current %d", reg.Current())
+	}
+	if reg.SetCurrent(0) {
+		t.Fatal("rollback to an unknown version succeeded")
+	}
+	if next := reg.Store(nil); next != writers*perWriter+1 {
+		t.Fatalf("version after rollback %d, want %d", next, writers*perWriter+1)
+	}
+}
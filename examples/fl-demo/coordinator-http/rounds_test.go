@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func postExperiment(t *testing.T, body string) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	postExperimentHandler(rec, httptest.NewRequest(http.MethodPost, "/experiments", strings.NewReader(body)))
+
+	return rec.Code
+}
+
+func TestExperimentStopsAtTotalRounds(t *testing.T) {
+	aggregator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"w": []float64{0.1}})
+	}))
+	defer aggregator.Close()
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer registry.Close()
+
+	aggregatorURL = aggregator.URL
+	modelRegistryURL = registry.URL
+	httpClient = &http.Client{Timeout: 5 * time.Second}
+
+	if code := postExperiment(t, `{"experiment_id":"exp-bounded","round_id":"exp-bounded-r3","round":3,"total_rounds":2}`); code != http.StatusConflict {
+		t.Fatalf("round past total_rounds: status = %d, want %d", code, http.StatusConflict)
+	}
+	if code := postExperiment(t, `{"experiment_id":"exp-bounded","round_id":"exp-bounded-r0","total_rounds":2}`); code != http.StatusBadRequest {
+		t.Fatalf("missing round: status = %d, want %d", code, http.StatusBadRequest)
+	}
+	if code := postExperiment(t, `{"experiment_id":"exp-bounded","round_id":"exp-bounded-r","round":-1,"total_rounds":2}`); code != http.StatusBadRequest {
+		t.Fatalf("negative round: status = %d, want %d", code, http.StatusBadRequest)
+	}
+	roundsMu.RLock()
+	_, created := rounds["exp-bounded-r3"]
+	roundsMu.RUnlock()
+	if created {
+		t.Fatal("round past total_rounds was created")
+	}
+
+	if code := postExperiment(t, `{"experiment_id":"exp-bounded","round_id":"exp-bounded-r2","round":2,"total_rounds":2}`); code != http.StatusCreated {
+		t.Fatalf("last round: status = %d, want %d", code, http.StatusCreated)
+	}
+	roundsMu.RLock()
+	round := rounds["exp-bounded-r2"]
+	roundsMu.RUnlock()
+	round.Updates = []Update{{RoundID: round.RoundID, PropletID: "proplet-a", NumSamples: 1}}
+	round.Completed = true
+	aggregateAndAdvance(round)
+
+	rec := httptest.NewRecorder()
+	getNextRoundHandler(rec, httptest.NewRequest(http.MethodGet, "/rounds/next", nil))
+	var next map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&next); err != nil {
+		t.Fatalf("decode next round: %v", err)
+	}
+	if next["next_round_available"] != false || next["status"] != "experiment_complete" {
+		t.Fatalf("next round after the last one = %v, want the experiment reported complete", next)
+	}
+
+	if code := postExperiment(t, `{"experiment_id":"exp-bounded","round_id":"exp-bounded-r2b","round":2,"total_rounds":2}`); code != http.StatusConflict {
+		t.Fatalf("round of a finished experiment: status = %d, want %d", code, http.StatusConflict)
+	}
+}
+
+func TestLastRoundAtUint64Bounds(t *testing.T) {
+	cases := []struct {
+		round, total uint64
+		last         bool
+	}{
+		{round: 1, total: 0, last: false},
+		{round: math.MaxUint64, total: 0, last: false},
+		{round: 1, total: 2, last: false},
+		{round: 2, total: 2, last: true},
+		{round: math.MaxUint64 - 1, total: math.MaxUint64, last: false},
+		{round: math.MaxUint64, total: math.MaxUint64, last: true},
+	}
+	for _, tc := range cases {
+		r := &RoundState{Round: tc.round, TotalRounds: tc.total}
+		if got := r.lastRound(); got != tc.last {
+			t.Fatalf("lastRound(%d of %d) = %v, want %v", tc.round, tc.total, got, tc.last)
+		}
+	}
+}
+
+func TestRoundCompleteReportsParticipants(t *testing.T) {
+	roundsMu.Lock()
+	rounds["round-status"] = &RoundState{
+		RoundID:   "round-status",
+		KOfN:      2,
+		Completed: true,
+		Updates: []Update{
+			{
+				RoundID:    "round-status",
+				PropletID:  "proplet-a",
+				NumSamples: 120,
+				Metrics:    map[string]interface{}{"loss": 0.25},
+				Update:     map[string]interface{}{"w": []interface{}{0.1, 0.2, 0.3}, "b": 0.5},
+				ReceivedAt: "2026-01-02T03:04:05Z",
+			},
+			{RoundID: "round-status", PropletID: "proplet-b", NumSamples: 3},
+		},
+	}
+	roundsMu.Unlock()
+	defer func() {
+		roundsMu.Lock()
+		delete(rounds, "round-status")
+		roundsMu.Unlock()
+	}()
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/rounds/round-status/complete", nil), map[string]string{"round_id": "round-status"})
+	rec := httptest.NewRecorder()
+	getRoundCompleteHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var got struct {
+		RoundID      string              `json:"round_id"`
+		Completed    bool                `json:"completed"`
+		NumUpdates   int                 `json:"num_updates"`
+		KOfN         int                 `json:"k_of_n"`
+		Participants []ParticipantStatus `json:"participants"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode round status: %v", err)
+	}
+	if got.RoundID != "round-status" || !got.Completed || got.NumUpdates != 2 || got.KOfN != 2 {
+		t.Fatalf("round status = %+v, want round-status completed with 2 of 2 updates", got)
+	}
+	if len(got.Participants) != 2 {
+		t.Fatalf("participants = %d, want 2", len(got.Participants))
+	}
+
+	a, b := got.Participants[0], got.Participants[1]
+	if a.PropletID != "proplet-a" || !a.UpdateReceived || a.NumSamples != 120 || a.ReceivedAt != "2026-01-02T03:04:05Z" {
+		t.Fatalf("participant a = %+v", a)
+	}
+	if want := len(`{"b":0.5,"w":[0.1,0.2,0.3]}`); a.UpdateBytes != want {
+		t.Fatalf("participant a update_bytes = %d, want %d", a.UpdateBytes, want)
+	}
+	if a.Metrics["loss"] != 0.25 {
+		t.Fatalf("participant a metrics = %v", a.Metrics)
+	}
+	if b.PropletID != "proplet-b" || b.UpdateReceived || b.UpdateBytes != 0 {
+		t.Fatalf("participant b = %+v, want no update received", b)
+	}
+}
+
+func TestRoundCompleteUnknownRound(t *testing.T) {
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/rounds/missing/complete", nil), map[string]string{"round_id": "missing"})
+	rec := httptest.NewRecorder()
+	getRoundCompleteHandler(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestRoundQuorumMet(t *testing.T) {
+	cases := []struct {
+		desc     string
+		round    *RoundState
+		reported []string
+		met      bool
+	}{
+		{desc: "any-k short of k", round: &RoundState{KOfN: 2}, reported: []string{"a"}, met: false},
+		{desc: "any-k at k", round: &RoundState{KOfN: 2}, reported: []string{"a", "b"}, met: true},
+		{desc: "any-k counts a proplet once", round: &RoundState{KOfN: 2}, reported: []string{"a", "a"}, met: false},
+		{desc: "all-required missing one", round: &RoundState{QuorumPolicy: "all-required", RequiredParticipants: []string{"a", "b"}}, reported: []string{"a", "c"}, met: false},
+		{desc: "all-required met", round: &RoundState{QuorumPolicy: "all-required", RequiredParticipants: []string{"a", "b"}}, reported: []string{"b", "a"}, met: true},
+		{desc: "required-plus-k without extras", round: &RoundState{QuorumPolicy: "required-plus-k", KOfN: 1, RequiredParticipants: []string{"a"}}, reported: []string{"a", "a"}, met: false},
+		{desc: "required-plus-k met", round: &RoundState{QuorumPolicy: "required-plus-k", KOfN: 1, RequiredParticipants: []string{"a"}}, reported: []string{"a", "b"}, met: true},
+	}
+	for _, tc := range cases {
+		for _, id := range tc.reported {
+			tc.round.Updates = append(tc.round.Updates, Update{PropletID: id})
+		}
+		if got := tc.round.quorumMet(); got != tc.met {
+			t.Fatalf("%s: quorumMet() = %v, want %v", tc.desc, got, tc.met)
+		}
+	}
+}
+
+func TestExperimentRejectsInvalidQuorum(t *testing.T) {
+	body := `{"round_id":"bad-quorum","participants":["a","b"],"quorum_policy":"all-required","required_participants":["z"]}`
+	if code := postExperiment(t, body); code != http.StatusBadRequest {
+		t.Fatalf("required participant outside the round: status = %d, want %d", code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// fetchDatasetStream fetches the dataset of propletID from the data store at
+// baseURL with ?stream=true and calls fn for each row as it is decoded, so
+// large datasets never have to be held in memory in full. It stops at the
+// first error returned by fn.
+func fetchDatasetStream(ctx context.Context, client *http.Client, baseURL, propletID string, fn func(row map[string]interface{}) error) error {
+	u := fmt.Sprintf("%s/datasets/%s?stream=true", baseURL, url.PathEscape(propletID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("data store returned %s: %s", resp.Status, body)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var row map[string]interface{}
+		if err := dec.Decode(&row); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("failed to decode dataset row: %w", err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
@@ -2,16 +2,20 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -26,15 +30,24 @@ type Dataset struct {
 
 type DatasetStore struct {
 	datasets map[string]*Dataset
+	touched  map[string]time.Time
 	mu       sync.RWMutex
 	dataDir  string
+	ttl      time.Duration
+	now      func() time.Time
 }
 
 var store = &DatasetStore{
 	datasets: make(map[string]*Dataset),
+	touched:  make(map[string]time.Time),
 	dataDir:  "/data/datasets",
+	now:      time.Now,
 }
 
+// featureDim is the number of features every row's x must have. Zero takes
+// the dimension from the first row of each posted dataset.
+var featureDim int
+
 func main() {
 	if dir := os.Getenv("DATA_DIR"); dir != "" {
 		store.dataDir = dir
@@ -50,6 +63,22 @@ func main() {
 	// Seed datasets for participants
 	seedDatasetsForParticipants()
 
+	if v := os.Getenv("DATASET_FEATURE_DIM"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid DATASET_FEATURE_DIM %q", v)
+		}
+		featureDim = n
+	}
+
+	if v := os.Getenv("DATASET_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl < 0 {
+			log.Fatalf("Invalid DATASET_TTL %q", v)
+		}
+		store.ttl = ttl
+	}
+
 	port := "8083"
 	if p := os.Getenv("DATA_STORE_PORT"); p != "" {
 		port = p
@@ -60,11 +89,20 @@ func main() {
 	r.HandleFunc("/datasets", listDatasetsHandler).Methods("GET")
 	// Support both proplet_id and client_id for backward compatibility
 	r.HandleFunc("/datasets/{proplet_id}", getDatasetHandler).Methods("GET")
+	r.HandleFunc("/datasets/{proplet_id}/shard", getDatasetShardHandler).Methods("GET")
 	r.HandleFunc("/datasets/{proplet_id}", postDatasetHandler).Methods("POST")
-	r.HandleFunc("/datasets/{client_id}", getDatasetHandler).Methods("GET")   // Legacy route
-	r.HandleFunc("/datasets/{client_id}", postDatasetHandler).Methods("POST") // Legacy route
+	r.HandleFunc("/datasets/{proplet_id}", deleteDatasetHandler).Methods("DELETE")
+	r.HandleFunc("/datasets/{client_id}", getDatasetHandler).Methods("GET")            // Legacy route
+	r.HandleFunc("/datasets/{client_id}/shard", getDatasetShardHandler).Methods("GET") // Legacy route
+	r.HandleFunc("/datasets/{client_id}", postDatasetHandler).Methods("POST")          // Legacy route
+	r.HandleFunc("/datasets/{client_id}", deleteDatasetHandler).Methods("DELETE")      // Legacy route
 
-	slog.Info("Local Data Store HTTP server starting", "port", port, "data_dir", store.dataDir)
+	slog.Info("Local Data Store HTTP server starting", "port", port, "data_dir", store.dataDir, "ttl", store.ttl)
+
+	stopPruning := make(chan struct{})
+	if store.ttl > 0 {
+		go pruneLoop(min(store.ttl, time.Minute), stopPruning)
+	}
 
 	srv := &http.Server{
 		Addr:    ":" + port,
@@ -80,6 +118,7 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	<-sigChan
+	close(stopPruning)
 
 	slog.Info("Shutting down Local Data Store")
 }
@@ -105,56 +144,165 @@ func listDatasetsHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func getDatasetHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	propletID := vars["proplet_id"]
-	if propletID == "" {
-		// Fallback to client_id for backward compatibility
-		propletID = vars["client_id"]
-	}
+	propletID := datasetID(r)
 	if propletID == "" {
 		http.Error(w, "proplet_id or client_id is required", http.StatusBadRequest)
 		return
 	}
 
-	store.mu.RLock()
-	dataset, exists := store.datasets[propletID]
-	store.mu.RUnlock()
+	dataset, ok := lookupDataset(w, propletID)
+	if !ok {
+		return
+	}
 
-	if !exists {
-		datasetFile := filepath.Join(store.dataDir, fmt.Sprintf("%s.json", propletID))
-		data, err := os.ReadFile(datasetFile)
-		if err != nil {
-			slog.Warn("Dataset missing", "proplet_id", propletID, "path", datasetFile)
-			http.Error(w, "Dataset not found", http.StatusNotFound)
-			return
-		}
+	if stream, _ := strconv.ParseBool(r.URL.Query().Get("stream")); stream {
+		streamDataset(w, propletID, dataset)
+		return
+	}
+
+	slog.Info("Dataset served", "proplet_id", propletID, "size", dataset.Size)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dataset)
+}
 
-		var loadedDataset Dataset
-		if err := json.Unmarshal(data, &loadedDataset); err != nil {
-			slog.Error("Invalid dataset file", "proplet_id", propletID, "error", err)
-			http.Error(w, "Invalid dataset file", http.StatusInternalServerError)
+// streamRowsPerFlush is how many rows a streamed response buffers before
+// flushing them to the client.
+const streamRowsPerFlush = 256
+
+// streamDataset writes the rows of dataset as newline-delimited JSON, one row
+// per line, flushing as it goes so neither side holds the whole encoded
+// dataset at once.
+func streamDataset(w http.ResponseWriter, propletID string, dataset *Dataset) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Dataset-Schema", dataset.Schema)
+	w.Header().Set("X-Dataset-Size", strconv.Itoa(len(dataset.Data)))
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	for i, row := range dataset.Data {
+		if err := enc.Encode(row); err != nil {
+			slog.Warn("Dataset stream aborted", "proplet_id", propletID, "row", i, "error", err)
 			return
 		}
+		if flusher != nil && (i+1)%streamRowsPerFlush == 0 {
+			flusher.Flush()
+		}
+	}
 
-		store.mu.Lock()
-		store.datasets[propletID] = &loadedDataset
-		store.mu.Unlock()
+	slog.Info("Dataset streamed", "proplet_id", propletID, "size", len(dataset.Data))
+}
 
-		dataset = &loadedDataset
+// DatasetShard is one of Count disjoint, contiguous slices of a dataset.
+type DatasetShard struct {
+	Dataset
+	Index int `json:"index"`
+	Count int `json:"count"`
+	Total int `json:"total"`
+}
+
+func getDatasetShardHandler(w http.ResponseWriter, r *http.Request) {
+	propletID := datasetID(r)
+	if propletID == "" {
+		http.Error(w, "proplet_id or client_id is required", http.StatusBadRequest)
+		return
 	}
 
-	slog.Info("Dataset served", "proplet_id", propletID, "size", dataset.Size)
+	query := r.URL.Query()
+	index, err := strconv.Atoi(query.Get("index"))
+	if err != nil || index < 0 {
+		http.Error(w, "index must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+	count, err := strconv.Atoi(query.Get("count"))
+	if err != nil || count < 1 {
+		http.Error(w, "count must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	if index >= count {
+		http.Error(w, "index must be less than count", http.StatusBadRequest)
+		return
+	}
+
+	dataset, ok := lookupDataset(w, propletID)
+	if !ok {
+		return
+	}
+
+	shard := shardDataset(dataset, index, count)
+	slog.Info("Dataset shard served", "proplet_id", propletID, "index", index, "count", count, "size", shard.Size)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(dataset)
+	json.NewEncoder(w).Encode(shard)
 }
 
-func postDatasetHandler(w http.ResponseWriter, r *http.Request) {
+// shardDataset returns the index-th of count contiguous slices of dataset.
+// Shard sizes differ by at most one row, and together the shards cover every
+// row exactly once.
+func shardDataset(dataset *Dataset, index, count int) DatasetShard {
+	total := len(dataset.Data)
+	start := index * total / count
+	end := (index + 1) * total / count
+
+	shard := DatasetShard{
+		Dataset: *dataset,
+		Index:   index,
+		Count:   count,
+		Total:   total,
+	}
+	shard.Data = dataset.Data[start:end]
+	shard.Size = len(shard.Data)
+
+	return shard
+}
+
+// datasetID returns the proplet_id path variable, falling back to the legacy
+// client_id.
+func datasetID(r *http.Request) string {
 	vars := mux.Vars(r)
-	propletID := vars["proplet_id"]
-	if propletID == "" {
-		// Fallback to client_id for backward compatibility
-		propletID = vars["client_id"]
+	if propletID := vars["proplet_id"]; propletID != "" {
+		return propletID
+	}
+
+	return vars["client_id"]
+}
+
+// lookupDataset returns the dataset of propletID, loading it from disk when it
+// is not cached. On failure it writes the error response and returns false.
+func lookupDataset(w http.ResponseWriter, propletID string) (*Dataset, bool) {
+	store.mu.Lock()
+	dataset, exists := store.datasets[propletID]
+	if exists {
+		store.touched[propletID] = store.now()
 	}
+	store.mu.Unlock()
+	if exists {
+		return dataset, true
+	}
+
+	datasetFile := filepath.Join(store.dataDir, fmt.Sprintf("%s.json", propletID))
+	data, err := os.ReadFile(datasetFile)
+	if err != nil {
+		slog.Warn("Dataset missing", "proplet_id", propletID, "path", datasetFile)
+		http.Error(w, "Dataset not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	var loadedDataset Dataset
+	if err := json.Unmarshal(data, &loadedDataset); err != nil {
+		slog.Error("Invalid dataset file", "proplet_id", propletID, "error", err)
+		http.Error(w, "Invalid dataset file", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	store.mu.Lock()
+	store.datasets[propletID] = &loadedDataset
+	store.touched[propletID] = store.now()
+	store.mu.Unlock()
+
+	return &loadedDataset, true
+}
+
+func postDatasetHandler(w http.ResponseWriter, r *http.Request) {
+	propletID := datasetID(r)
 	if propletID == "" {
 		http.Error(w, "proplet_id or client_id is required", http.StatusBadRequest)
 		return
@@ -165,6 +313,11 @@ func postDatasetHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
 		return
 	}
+	if err := validateDataset(dataset.Data, featureDim); err != nil {
+		slog.Warn("Rejected invalid dataset", "proplet_id", propletID, "error", err)
+		http.Error(w, fmt.Sprintf("Invalid dataset: %v", err), http.StatusBadRequest)
+		return
+	}
 
 	// Ensure schema and proplet_id are set
 	if dataset.Schema == "" {
@@ -176,6 +329,7 @@ func postDatasetHandler(w http.ResponseWriter, r *http.Request) {
 
 	store.mu.Lock()
 	store.datasets[propletID] = &dataset
+	store.touched[propletID] = store.now()
 	store.mu.Unlock()
 
 	// Save to file using atomic write
@@ -195,6 +349,123 @@ func postDatasetHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// validateDataset checks that every row has a numeric feature vector x of
+// dim entries and a numeric label y. A zero dim takes the length of the first
+// row's x. Errors name the offending row index.
+func validateDataset(rows []map[string]interface{}, dim int) error {
+	for i, row := range rows {
+		x, ok := row["x"].([]interface{})
+		if !ok {
+			return fmt.Errorf("row %d: x must be an array of numbers", i)
+		}
+		if len(x) == 0 {
+			return fmt.Errorf("row %d: x must not be empty", i)
+		}
+		for j, v := range x {
+			if _, ok := v.(float64); !ok {
+				return fmt.Errorf("row %d: x[%d] is not a number", i, j)
+			}
+		}
+		if dim == 0 {
+			dim = len(x)
+		}
+		if len(x) != dim {
+			return fmt.Errorf("row %d: x has %d features, expected %d", i, len(x), dim)
+		}
+		if _, ok := row["y"].(float64); !ok {
+			return fmt.Errorf("row %d: y must be a number", i)
+		}
+	}
+
+	return nil
+}
+
+func deleteDatasetHandler(w http.ResponseWriter, r *http.Request) {
+	propletID := datasetID(r)
+	if propletID == "" {
+		http.Error(w, "proplet_id or client_id is required", http.StatusBadRequest)
+		return
+	}
+
+	found, err := deleteDataset(propletID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to delete dataset file: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Dataset not found", http.StatusNotFound)
+		return
+	}
+
+	slog.Info("Dataset deleted", "proplet_id", propletID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteDataset removes the dataset of propletID from memory and disk and
+// reports whether there was one.
+func deleteDataset(propletID string) (bool, error) {
+	store.mu.Lock()
+	_, cached := store.datasets[propletID]
+	delete(store.datasets, propletID)
+	delete(store.touched, propletID)
+	store.mu.Unlock()
+
+	err := os.Remove(filepath.Join(store.dataDir, fmt.Sprintf("%s.json", propletID)))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return cached, nil
+	default:
+		return cached, err
+	}
+}
+
+// pruneExpired deletes every dataset that has not been read or written for
+// longer than the store's TTL and returns how many it removed. It is a no-op
+// when no TTL is set.
+func pruneExpired() int {
+	if store.ttl <= 0 {
+		return 0
+	}
+
+	cutoff := store.now().Add(-store.ttl)
+	var expired []string
+	store.mu.Lock()
+	for propletID := range store.datasets {
+		if store.touched[propletID].Before(cutoff) {
+			delete(store.datasets, propletID)
+			delete(store.touched, propletID)
+			expired = append(expired, propletID)
+		}
+	}
+	store.mu.Unlock()
+
+	for _, propletID := range expired {
+		err := os.Remove(filepath.Join(store.dataDir, fmt.Sprintf("%s.json", propletID)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Failed to remove expired dataset file", "proplet_id", propletID, "error", err)
+		}
+		slog.Info("Pruned expired dataset", "proplet_id", propletID, "ttl", store.ttl)
+	}
+
+	return len(expired)
+}
+
+func pruneLoop(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pruneExpired()
+		case <-stop:
+			return
+		}
+	}
+}
+
 // getParticipantUUIDs reads participant UUIDs from environment variables
 func getParticipantUUIDs() []string {
 	// First, try FL_DATASET_PARTICIPANTS (comma-separated list)
@@ -325,8 +596,14 @@ func loadDatasetsFromDisk() {
 			continue
 		}
 
+		touched := store.now()
+		if info, err := os.Stat(file); err == nil {
+			touched = info.ModTime()
+		}
+
 		store.mu.Lock()
 		store.datasets[propletID] = &dataset
+		store.touched[propletID] = touched
 		store.mu.Unlock()
 		loaded++
 	}
@@ -362,6 +639,7 @@ func seedDatasetsForParticipants() {
 		// Save to memory
 		store.mu.Lock()
 		store.datasets[propletID] = dataset
+		store.touched[propletID] = store.now()
 		store.mu.Unlock()
 
 		// Save to disk using atomic write
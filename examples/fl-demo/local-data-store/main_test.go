@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func postDataset(t *testing.T, dim int, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	store.dataDir = t.TempDir()
+	featureDim = dim
+	t.Cleanup(func() { featureDim = 0 })
+
+	r := mux.NewRouter()
+	r.HandleFunc("/datasets/{proplet_id}", postDatasetHandler).Methods("POST")
+
+	req := httptest.NewRequest(http.MethodPost, "/datasets/proplet-1", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestPostDatasetValid(t *testing.T) {
+	cases := []struct {
+		desc string
+		dim  int
+		body string
+	}{
+		{
+			desc: "inferred dimension",
+			body: `{"data":[{"x":[0.1,0.2],"y":1},{"x":[0.3,0.4],"y":0}]}`,
+		},
+		{
+			desc: "configured dimension",
+			dim:  3,
+			body: `{"data":[{"x":[0.1,0.2,0.3],"y":1}]}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rec := postDataset(t, tc.dim, tc.body)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPostDatasetInvalid(t *testing.T) {
+	cases := []struct {
+		desc string
+		dim  int
+		body string
+		want string
+	}{
+		{
+			desc: "missing x",
+			body: `{"data":[{"x":[0.1],"y":1},{"y":0}]}`,
+			want: "row 1: x must be an array of numbers",
+		},
+		{
+			desc: "non-numeric feature",
+			body: `{"data":[{"x":[0.1,"a"],"y":1}]}`,
+			want: "row 0: x[1] is not a number",
+		},
+		{
+			desc: "inconsistent length",
+			body: `{"data":[{"x":[0.1,0.2],"y":1},{"x":[0.1,0.2],"y":0},{"x":[0.1],"y":1}]}`,
+			want: "row 2: x has 1 features, expected 2",
+		},
+		{
+			desc: "configured dimension mismatch",
+			dim:  3,
+			body: `{"data":[{"x":[0.1,0.2],"y":1}]}`,
+			want: "row 0: x has 2 features, expected 3",
+		},
+		{
+			desc: "missing y",
+			body: `{"data":[{"x":[0.1,0.2],"y":1},{"x":[0.3,0.4]}]}`,
+			want: "row 1: y must be a number",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rec := postDataset(t, tc.dim, tc.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			if !strings.Contains(rec.Body.String(), tc.want) {
+				t.Fatalf("body = %q, want it to contain %q", rec.Body.String(), tc.want)
+			}
+		})
+	}
+}
+
+func getShard(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := mux.NewRouter()
+	r.HandleFunc("/datasets/{client_id}/shard", getDatasetShardHandler).Methods("GET")
+
+	req := httptest.NewRequest(http.MethodGet, "/datasets/client-1/shard?"+query, nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestGetDatasetShardPartitions(t *testing.T) {
+	store.dataDir = t.TempDir()
+	dataset := generateDataset("client-1", 10)
+	for i, row := range dataset.Data {
+		row["id"] = float64(i)
+	}
+	store.mu.Lock()
+	store.datasets["client-1"] = dataset
+	store.mu.Unlock()
+
+	for _, count := range []int{1, 3, 4, 10, 12} {
+		seen := make(map[int]int)
+		for index := 0; index < count; index++ {
+			rec := getShard(t, fmt.Sprintf("index=%d&count=%d", index, count))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("count %d index %d: status = %d: %s", count, index, rec.Code, rec.Body.String())
+			}
+			var shard DatasetShard
+			if err := json.NewDecoder(rec.Body).Decode(&shard); err != nil {
+				t.Fatalf("decode shard: %v", err)
+			}
+			if shard.Size != len(shard.Data) || shard.Total != 10 {
+				t.Fatalf("count %d index %d: size %d, total %d for %d rows", count, index, shard.Size, shard.Total, len(shard.Data))
+			}
+			for _, row := range shard.Data {
+				seen[int(row["id"].(float64))]++
+			}
+		}
+		if len(seen) != 10 {
+			t.Fatalf("count %d: shards cover %d of 10 rows", count, len(seen))
+		}
+		for id, n := range seen {
+			if n != 1 {
+				t.Fatalf("count %d: row %d served by %d shards", count, id, n)
+			}
+		}
+	}
+}
+
+func TestGetDatasetShardInvalid(t *testing.T) {
+	for _, query := range []string{
+		"index=3&count=3",
+		"index=-1&count=3",
+		"index=0&count=0",
+		"index=a&count=2",
+		"count=2",
+	} {
+		if rec := getShard(t, query); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestStreamDatasetMatchesBatch(t *testing.T) {
+	store.dataDir = t.TempDir()
+	store.mu.Lock()
+	store.datasets["client-1"] = generateDataset("client-1", 600)
+	store.mu.Unlock()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/datasets/{client_id}", getDatasetHandler).Methods("GET")
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/datasets/client-1")
+	if err != nil {
+		t.Fatalf("batch request: %v", err)
+	}
+	defer resp.Body.Close()
+	var batch Dataset
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		t.Fatalf("decode batch response: %v", err)
+	}
+
+	var streamed []map[string]interface{}
+	err = fetchDatasetStream(context.Background(), srv.Client(), srv.URL, "client-1", func(row map[string]interface{}) error {
+		streamed = append(streamed, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("stream dataset: %v", err)
+	}
+
+	if len(streamed) != 600 {
+		t.Fatalf("streamed %d rows, want 600", len(streamed))
+	}
+	if !reflect.DeepEqual(streamed, batch.Data) {
+		t.Fatal("streamed rows differ from the batch response")
+	}
+}
+
+func TestStreamDatasetNotFound(t *testing.T) {
+	store.dataDir = t.TempDir()
+	r := mux.NewRouter()
+	r.HandleFunc("/datasets/{client_id}", getDatasetHandler).Methods("GET")
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	err := fetchDatasetStream(context.Background(), srv.Client(), srv.URL, "missing", func(map[string]interface{}) error {
+		t.Fatal("unexpected row")
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected an error for a missing dataset")
+	}
+}
+
+func TestDeleteDataset(t *testing.T) {
+	rec := postDataset(t, 0, `{"data":[{"x":[0.1],"y":1}]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("post status = %d", rec.Code)
+	}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/datasets/{proplet_id}", deleteDatasetHandler).Methods("DELETE")
+	del := func() int {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/datasets/proplet-1", nil))
+		return rec.Code
+	}
+
+	if code := del(); code != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want %d", code, http.StatusNoContent)
+	}
+	store.mu.RLock()
+	_, cached := store.datasets["proplet-1"]
+	store.mu.RUnlock()
+	if cached {
+		t.Fatal("dataset still cached after delete")
+	}
+	if _, err := os.Stat(filepath.Join(store.dataDir, "proplet-1.json")); !os.IsNotExist(err) {
+		t.Fatalf("dataset file still present after delete: %v", err)
+	}
+	if code := del(); code != http.StatusNotFound {
+		t.Fatalf("second delete status = %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestPruneExpiredDatasets(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	store.ttl = time.Hour
+	store.mu.Lock()
+	store.datasets = make(map[string]*Dataset)
+	store.touched = make(map[string]time.Time)
+	store.mu.Unlock()
+	t.Cleanup(func() {
+		store.now = time.Now
+		store.ttl = 0
+	})
+
+	postDataset(t, 0, `{"data":[{"x":[0.1],"y":1}]}`)
+	store.mu.Lock()
+	store.datasets["proplet-2"] = generateDataset("proplet-2", 4)
+	store.touched["proplet-2"] = now
+	store.mu.Unlock()
+	if err := saveDatasetAtomic("proplet-2", store.datasets["proplet-2"]); err != nil {
+		t.Fatalf("save dataset: %v", err)
+	}
+
+	now = now.Add(40 * time.Minute)
+	if _, ok := lookupDataset(httptest.NewRecorder(), "proplet-2"); !ok {
+		t.Fatal("lookup proplet-2 failed")
+	}
+	if n := pruneExpired(); n != 0 {
+		t.Fatalf("pruned %d datasets before the TTL passed", n)
+	}
+
+	now = now.Add(30 * time.Minute)
+	if n := pruneExpired(); n != 1 {
+		t.Fatalf("pruned %d datasets, want 1", n)
+	}
+	store.mu.RLock()
+	_, kept := store.datasets["proplet-2"]
+	_, dropped := store.datasets["proplet-1"]
+	store.mu.RUnlock()
+	if !kept || dropped {
+		t.Fatalf("kept proplet-2 = %t, kept proplet-1 = %t", kept, dropped)
+	}
+	if _, err := os.Stat(filepath.Join(store.dataDir, "proplet-1.json")); !os.IsNotExist(err) {
+		t.Fatalf("expired dataset file still present: %v", err)
+	}
+}
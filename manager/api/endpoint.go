@@ -7,6 +7,7 @@ import (
 	apiutil "github.com/absmach/magistrala/api/http/util"
 	"github.com/absmach/propeller/manager"
 	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/task"
 	"github.com/go-kit/kit/endpoint"
 )
 
@@ -20,7 +21,7 @@ func listPropletsEndpoint(svc manager.Service) endpoint.Endpoint {
 			return listpropletResponse{}, errors.Join(apiutil.ErrValidation, err)
 		}
 
-		proplets, err := svc.ListProplets(ctx, req.offset, req.limit, req.status)
+		proplets, err := svc.ListProplets(ctx, req.offset, req.limit, req.status, req.sort)
 		if err != nil {
 			return listpropletResponse{}, err
 		}
@@ -110,6 +111,27 @@ func deletePropletEndpoint(svc manager.Service) endpoint.Endpoint {
 	}
 }
 
+func setPropletLabelsEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(propletLabelsReq)
+		if !ok {
+			return propletResponse{}, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+		if err := req.validate(); err != nil {
+			return propletResponse{}, errors.Join(apiutil.ErrValidation, err)
+		}
+
+		p, err := svc.SetPropletLabels(ctx, req.id, req.Labels)
+		if err != nil {
+			return propletResponse{}, err
+		}
+
+		return propletResponse{
+			PropletView: p.View(),
+		}, nil
+	}
+}
+
 func createTaskEndpoint(svc manager.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
 		req, ok := request.(taskReq)
@@ -271,9 +293,14 @@ func listTasksEndpoint(svc manager.Service) endpoint.Endpoint {
 		}
 
 		pm := manager.PageMetadata{
-			Offset:   req.offset,
-			Limit:    req.limit,
-			Metadata: req.metadata,
+			Offset:    req.offset,
+			Limit:     req.limit,
+			Metadata:  req.metadata,
+			State:     req.state,
+			Kind:      req.kind,
+			Mode:      req.mode,
+			PropletID: req.propletID,
+			Sort:      req.sort,
 		}
 		tasks, err := svc.ListTasks(ctx, pm)
 		if err != nil {
@@ -343,6 +370,31 @@ func deleteTaskEndpoint(svc manager.Service) endpoint.Endpoint {
 	}
 }
 
+func purgeTasksEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(purgeTasksReq)
+		if !ok {
+			return purgeTasksResponse{}, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+		if err := req.validate(); err != nil {
+			return purgeTasksResponse{}, errors.Join(apiutil.ErrValidation, err)
+		}
+
+		filter := manager.PurgeFilter{OlderThan: req.olderThan}
+		for _, s := range req.states {
+			st, _ := task.ToState(s)
+			filter.States = append(filter.States, st)
+		}
+
+		deleted, err := svc.PurgeTasks(ctx, filter)
+		if err != nil {
+			return purgeTasksResponse{}, err
+		}
+
+		return purgeTasksResponse{Deleted: deleted}, nil
+	}
+}
+
 func startTaskEndpoint(svc manager.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request any) (any, error) {
 		req, ok := request.(entityReq)
@@ -0,0 +1,207 @@
+package api_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/pkg/events"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsStream(t *testing.T) {
+	t.Parallel()
+	ts, svc := newServer(t)
+	defer ts.Close()
+
+	bus := events.NewBus()
+	filter := events.Filter{TaskID: "task-1"}
+	svc.On("SubscribeEvents", mock.Anything, filter).Return(bus.Subscribe(filter, 1), nil)
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/events?task_id=task-1"
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	bus.Publish(events.Event{Type: "task.start", EntityType: "task", EntityID: "task-2", Action: "start"})
+	bus.Publish(events.Event{Type: "task.start", EntityType: "task", EntityID: "task-1", Action: "start", NewState: "Running"})
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var got events.Event
+	require.NoError(t, conn.ReadJSON(&got))
+	assert.Equal(t, "task-1", got.EntityID)
+	assert.Equal(t, "task.start", got.Type)
+	assert.Equal(t, "Running", got.NewState)
+}
+
+func TestEventsStreamDropsSlowClient(t *testing.T) {
+	t.Parallel()
+	ts, svc := newServer(t)
+	defer ts.Close()
+
+	bus := events.NewBus()
+	sub := bus.Subscribe(events.Filter{}, 1)
+	svc.On("SubscribeEvents", mock.Anything, events.Filter{}).Return(sub, nil)
+
+	// Overflow the buffer before the client reads anything.
+	bus.Publish(events.Event{Type: "task.create", EntityType: "task", EntityID: "t1"})
+	bus.Publish(events.Event{Type: "task.create", EntityType: "task", EntityID: "t2"})
+	require.ErrorIs(t, sub.Err(), events.ErrSlowConsumer)
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/events"
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	defer resp.Body.Close()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, _, err = conn.ReadMessage()
+		if err != nil {
+			break
+		}
+	}
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "unexpected error: %v", err)
+}
+
+func TestEventsStreamSubscribeError(t *testing.T) {
+	t.Parallel()
+	ts, svc := newServer(t)
+	defer ts.Close()
+
+	svc.On("SubscribeEvents", mock.Anything, events.Filter{JobID: "job-1"}).Return(nil, assert.AnError)
+
+	resp, err := http.Get(ts.URL + "/events?job_id=job-1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.NotEqual(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.GreaterOrEqual(t, resp.StatusCode, http.StatusBadRequest)
+}
+
+func readSSE(t *testing.T, r *bufio.Reader) (string, map[string]any) {
+	t.Helper()
+	var name string
+	var data map[string]any
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &data))
+		}
+	}
+}
+
+func roundEvent(action, state string, received int) events.Event {
+	return events.Event{
+		Type:       "fl_round." + action,
+		EntityType: "fl_round",
+		EntityID:   "round-1",
+		Action:     action,
+		NewState:   state,
+		Metadata: map[string]string{
+			"experiment_id": "exp-1",
+			"k_of_n":        "2",
+			"received":      fmt.Sprint(received),
+		},
+	}
+}
+
+func TestFLProgressStream(t *testing.T) {
+	t.Parallel()
+	ts, svc := newServer(t)
+	defer ts.Close()
+
+	bus := events.NewBus()
+	filter := events.Filter{ExperimentID: "exp-1"}
+	svc.On("SubscribeEvents", mock.Anything, filter).Return(bus.Subscribe(filter, 8), nil)
+
+	resp, err := http.Get(ts.URL + "/fl/jobs/exp-1/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	body := bufio.NewReader(resp.Body)
+
+	bus.Publish(roundEvent("update", "running", 1))
+	name, data := readSSE(t, body)
+	assert.Equal(t, "update", name)
+	assert.Equal(t, "round-1", data["round_id"])
+	assert.InDelta(t, 2, data["k_of_n"], 0)
+	assert.InDelta(t, 1, data["received"], 0)
+
+	bus.Publish(roundEvent("update", "running", 2))
+	bus.Publish(roundEvent("complete", "completed", 2))
+	bus.Publish(roundEvent("aggregate", "aggregated", 2))
+
+	var got []string
+	for range 3 {
+		name, data = readSSE(t, body)
+		got = append(got, name)
+		assert.InDelta(t, 2, data["received"], 0)
+	}
+	assert.Equal(t, []string{"update", "complete", "aggregate"}, got)
+
+	rest, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Empty(t, rest, "stream should close after aggregation")
+}
+
+func TestFLJobProgressStream(t *testing.T) {
+	t.Parallel()
+	ts, svc := newServer(t)
+	defer ts.Close()
+
+	bus := events.NewBus()
+	filter := events.Filter{ExperimentID: "exp-1"}
+	svc.On("SubscribeEvents", mock.Anything, filter).Return(bus.Subscribe(filter, 16), nil)
+
+	resp, err := http.Get(ts.URL + "/fl/jobs/exp-1/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body := bufio.NewReader(resp.Body)
+
+	for round := 1; round <= 2; round++ {
+		for _, action := range []string{"complete", "aggregate"} {
+			evt := roundEvent(action, action+"d", 2)
+			evt.EntityID = fmt.Sprintf("round-%d", round)
+			evt.Metadata["round"] = fmt.Sprint(round)
+			evt.Metadata["total_rounds"] = "2"
+			bus.Publish(evt)
+		}
+	}
+	done := roundEvent("complete-job", "job-complete", 2)
+	done.EntityID = "round-2"
+	bus.Publish(done)
+
+	var got []string
+	for range 5 {
+		name, data := readSSE(t, body)
+		got = append(got, fmt.Sprintf("%s %v", name, data["round_id"]))
+	}
+	assert.Equal(t, []string{
+		"complete round-1", "aggregate round-1",
+		"complete round-2", "aggregate round-2",
+		"complete-job round-2",
+	}, got)
+
+	rest, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Empty(t, rest, "stream should close once the job completes")
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	apiutil "github.com/absmach/magistrala/api/http/util"
@@ -41,6 +42,137 @@ type roundStatusResponse struct {
 	Status manager.RoundStatus `json:"status"`
 }
 
+type flJobMetricsReq struct {
+	jobID string
+}
+
+type flJobMetricsResponse struct {
+	manager.FLJobMetrics
+}
+
+type storeModelReq struct {
+	Version  *int           `json:"version"`
+	Data     map[string]any `json:"data"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+func (req storeModelReq) validate() error {
+	if req.Version == nil || *req.Version < 0 {
+		return errors.New("version must be a non-negative integer")
+	}
+	if req.Data == nil {
+		return errors.New("data is required")
+	}
+
+	return nil
+}
+
+type storeModelResponse struct {
+	Version int    `json:"version"`
+	Status  string `json:"status"`
+}
+
+func (res storeModelResponse) Code() int {
+	return http.StatusCreated
+}
+
+func (res storeModelResponse) Headers() map[string]string {
+	return map[string]string{
+		"Location": "/fl/models/" + strconv.Itoa(res.Version),
+	}
+}
+
+func (res storeModelResponse) Empty() bool {
+	return false
+}
+
+type modelReq struct {
+	version int
+}
+
+type modelResponse struct {
+	Version int `json:"version"`
+	manager.Model
+}
+
+type rollbackModelReq struct {
+	version int
+}
+
+type rollbackModelResponse struct {
+	Version int    `json:"version"`
+	Status  string `json:"status"`
+}
+
+type personalizedModelReq struct {
+	clientID string
+}
+
+type storePersonalizedModelReq struct {
+	clientID string
+	Data     map[string]any `json:"data"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+func (req storePersonalizedModelReq) validate() error {
+	if req.Data == nil {
+		return errors.New("data is required")
+	}
+
+	return nil
+}
+
+type storePersonalizedModelResponse struct {
+	ClientID string `json:"client_id"`
+	Status   string `json:"status"`
+}
+
+func (res storePersonalizedModelResponse) Code() int {
+	return http.StatusCreated
+}
+
+func (res storePersonalizedModelResponse) Headers() map[string]string {
+	return map[string]string{
+		"Location": "/fl/models/clients/" + res.ClientID,
+	}
+}
+
+func (res storePersonalizedModelResponse) Empty() bool {
+	return false
+}
+
+type personalizedModelResponse struct {
+	manager.PersonalizedModel
+}
+
+type jobStateReq struct {
+	jobID string
+}
+
+type importJobStateReq struct {
+	jobID string
+	data  []byte
+}
+
+func (req importJobStateReq) validate() error {
+	var bundle struct {
+		JobID string `json:"job_id"`
+	}
+	if err := json.Unmarshal(req.data, &bundle); err != nil {
+		return err
+	}
+	if bundle.JobID != req.jobID {
+		return errors.New("job_id in the bundle does not match the path")
+	}
+
+	return nil
+}
+
+type importJobStateResponse struct {
+	JobID  string `json:"job_id"`
+	Status string `json:"status"`
+}
+
 type experimentConfigReq struct {
 	Config manager.ExperimentConfig `json:"config"`
 }
@@ -132,6 +264,147 @@ func getRoundStatusEndpoint(svc manager.Service) endpoint.Endpoint {
 	}
 }
 
+func getFLJobMetricsEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(flJobMetricsReq)
+		if !ok {
+			return flJobMetricsResponse{}, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+
+		metrics, err := svc.GetFLJobMetrics(ctx, req.jobID)
+		if err != nil {
+			return flJobMetricsResponse{}, err
+		}
+
+		return flJobMetricsResponse{FLJobMetrics: metrics}, nil
+	}
+}
+
+func storeModelEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(storeModelReq)
+		if !ok {
+			return storeModelResponse{}, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+		if err := req.validate(); err != nil {
+			return storeModelResponse{}, errors.Join(apiutil.ErrValidation, err)
+		}
+
+		model := manager.Model{Data: req.Data, Metadata: req.Metadata}
+		if err := svc.StoreModel(ctx, *req.Version, model); err != nil {
+			return storeModelResponse{}, err
+		}
+
+		return storeModelResponse{Version: *req.Version, Status: "stored"}, nil
+	}
+}
+
+func getModelEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(modelReq)
+		if !ok {
+			return modelResponse{}, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+
+		model, err := svc.GetModel(ctx, req.version)
+		if err != nil {
+			return modelResponse{}, err
+		}
+
+		return modelResponse{Version: req.version, Model: model}, nil
+	}
+}
+
+func rollbackModelEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(rollbackModelReq)
+		if !ok {
+			return rollbackModelResponse{}, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+
+		if err := svc.RollbackModel(ctx, req.version); err != nil {
+			return rollbackModelResponse{}, err
+		}
+
+		return rollbackModelResponse{Version: req.version, Status: "rolled_back"}, nil
+	}
+}
+
+func storePersonalizedModelEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(storePersonalizedModelReq)
+		if !ok {
+			return storePersonalizedModelResponse{}, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+		if err := req.validate(); err != nil {
+			return storePersonalizedModelResponse{}, errors.Join(apiutil.ErrValidation, err)
+		}
+
+		model := manager.Model{Data: req.Data, Metadata: req.Metadata}
+		if err := svc.StorePersonalizedModel(ctx, req.clientID, model); err != nil {
+			return storePersonalizedModelResponse{}, err
+		}
+
+		return storePersonalizedModelResponse{ClientID: req.clientID, Status: "stored"}, nil
+	}
+}
+
+func getPersonalizedModelEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(personalizedModelReq)
+		if !ok {
+			return personalizedModelResponse{}, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+
+		model, err := svc.GetPersonalizedModel(ctx, req.clientID)
+		if err != nil {
+			return personalizedModelResponse{}, err
+		}
+
+		return personalizedModelResponse{PersonalizedModel: model}, nil
+	}
+}
+
+func exportJobStateEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(jobStateReq)
+		if !ok {
+			return nil, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+
+		data, err := svc.ExportJobState(ctx, req.jobID)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.RawMessage(data), nil
+	}
+}
+
+func importJobStateEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		req, ok := request.(importJobStateReq)
+		if !ok {
+			return importJobStateResponse{}, errors.Join(apiutil.ErrValidation, pkgerrors.ErrInvalidData)
+		}
+		if err := req.validate(); err != nil {
+			return importJobStateResponse{}, errors.Join(apiutil.ErrValidation, err)
+		}
+
+		if err := svc.ImportJobState(ctx, req.data); err != nil {
+			return importJobStateResponse{}, err
+		}
+
+		return importJobStateResponse{JobID: req.jobID, Status: "imported"}, nil
+	}
+}
+
+func debugFLStateEndpoint(svc manager.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ any) (any, error) {
+		return svc.DebugFLState(ctx)
+	}
+}
+
 func decodeFLTaskReq(_ context.Context, r *http.Request) (any, error) {
 	roundID := r.URL.Query().Get("round_id")
 	propletID := r.URL.Query().Get("proplet_id")
@@ -147,13 +420,9 @@ func decodeFLTaskReq(_ context.Context, r *http.Request) (any, error) {
 }
 
 func decodeFLUpdateReq(_ context.Context, r *http.Request) (any, error) {
-	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
-		return nil, errors.Join(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
-	}
-
 	var update manager.FLUpdate
-	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
-		return nil, errors.Join(err, apiutil.ErrValidation)
+	if err := api.DecodeBody(r, &update); err != nil {
+		return nil, err
 	}
 
 	return flUpdateReq{Update: update}, nil
@@ -182,15 +451,113 @@ func decodeRoundStatusReq(_ context.Context, r *http.Request) (any, error) {
 	return roundStatusReq{roundID: roundID}, nil
 }
 
-func decodeExperimentConfigReq(_ context.Context, r *http.Request) (any, error) {
+func decodeFLJobMetricsReq(_ context.Context, r *http.Request) (any, error) {
+	jobID := chi.URLParam(r, "jobID")
+	if jobID == "" {
+		return nil, errors.Join(apiutil.ErrValidation, errors.New("job id is required"))
+	}
+
+	return flJobMetricsReq{jobID: jobID}, nil
+}
+
+func decodeJobStateReq(_ context.Context, r *http.Request) (any, error) {
+	jobID := chi.URLParam(r, "jobID")
+	if jobID == "" {
+		return nil, errors.Join(apiutil.ErrValidation, errors.New("job id is required"))
+	}
+
+	return jobStateReq{jobID: jobID}, nil
+}
+
+func decodeImportJobStateReq(_ context.Context, r *http.Request) (any, error) {
+	jobID := chi.URLParam(r, "jobID")
+	if jobID == "" {
+		return nil, errors.Join(apiutil.ErrValidation, errors.New("job id is required"))
+	}
 	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
 		return nil, errors.Join(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
 	}
 
-	var config manager.ExperimentConfig
-	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
 		return nil, errors.Join(err, apiutil.ErrValidation)
 	}
 
+	return importJobStateReq{jobID: jobID, data: data}, nil
+}
+
+func decodeStoreModelReq(_ context.Context, r *http.Request) (any, error) {
+	var req storeModelReq
+	if err := api.DecodeBody(r, &req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func decodeModelReq(_ context.Context, r *http.Request) (any, error) {
+	version, err := decodeModelVersion(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return modelReq{version: version}, nil
+}
+
+func decodeRollbackModelReq(_ context.Context, r *http.Request) (any, error) {
+	version, err := decodeModelVersion(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return rollbackModelReq{version: version}, nil
+}
+
+func decodeModelVersion(r *http.Request) (int, error) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 0 {
+		return 0, errors.Join(apiutil.ErrValidation, errors.New("version must be a non-negative integer"))
+	}
+
+	return version, nil
+}
+
+func decodeStorePersonalizedModelReq(_ context.Context, r *http.Request) (any, error) {
+	clientID, err := decodeClientID(r)
+	if err != nil {
+		return nil, err
+	}
+	req := storePersonalizedModelReq{clientID: clientID}
+	if err := api.DecodeBody(r, &req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func decodePersonalizedModelReq(_ context.Context, r *http.Request) (any, error) {
+	clientID, err := decodeClientID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return personalizedModelReq{clientID: clientID}, nil
+}
+
+func decodeClientID(r *http.Request) (string, error) {
+	clientID := chi.URLParam(r, "clientID")
+	if clientID == "" {
+		return "", errors.Join(apiutil.ErrValidation, errors.New("client id is required"))
+	}
+
+	return clientID, nil
+}
+
+func decodeExperimentConfigReq(_ context.Context, r *http.Request) (any, error) {
+	var config manager.ExperimentConfig
+	if err := api.DecodeBody(r, &config); err != nil {
+		return nil, err
+	}
+
 	return experimentConfigReq{Config: config}, nil
 }
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	apiutil "github.com/absmach/magistrala/api/http/util"
+	"github.com/absmach/propeller/manager"
 	"github.com/absmach/propeller/pkg/api"
 	"github.com/absmach/propeller/pkg/cron"
 	pkgerrors "github.com/absmach/propeller/pkg/errors"
@@ -14,7 +17,10 @@ import (
 	"github.com/google/uuid"
 )
 
-var errStatusFilterUnsupported = errors.New("status filter is not supported")
+var (
+	errStatusFilterUnsupported = errors.New("status filter is not supported")
+	errSortUnsupported         = errors.New("sorting is not supported")
+)
 
 const maxMetadataBytes = 1048576 // 1MB
 
@@ -120,6 +126,25 @@ func (e *entityReq) validate() error {
 	return nil
 }
 
+type propletLabelsReq struct {
+	id     string
+	Labels map[string]string `json:"labels"`
+}
+
+func (r *propletLabelsReq) validate() error {
+	e := entityReq{id: r.id}
+	if err := e.validate(); err != nil {
+		return err
+	}
+	for k := range r.Labels {
+		if strings.TrimSpace(k) == "" {
+			return pkgerrors.ErrInvalidValue
+		}
+	}
+
+	return nil
+}
+
 type listEntityStatus uint8
 
 const (
@@ -132,9 +157,13 @@ type listEntityReq struct {
 	offset, limit uint64
 	status        string
 	statusFilter  listEntityStatus
+	sort          manager.Sort
 }
 
 func (e listEntityReq) validate() error {
+	if e.sort != (manager.Sort{}) && e.statusFilter != propletStatusFilter {
+		return errSortUnsupported
+	}
 	if e.status == "" {
 		return nil
 	}
@@ -156,15 +185,57 @@ func (e listEntityReq) validate() error {
 }
 
 type listTasksReq struct {
-	offset   uint64
-	limit    uint64
-	metadata task.Metadata
+	offset    uint64
+	limit     uint64
+	metadata  task.Metadata
+	state     string
+	kind      task.TaskKind
+	mode      task.Mode
+	propletID string
+	sort      manager.Sort
 }
 
 func (r listTasksReq) validate() error {
 	if r.limit > api.MaxLimitSize || r.limit < 1 {
 		return apiutil.ErrLimitSize
 	}
+	if r.state != "" {
+		if _, err := task.ToState(r.state); err != nil {
+			return err
+		}
+	}
+	switch r.kind {
+	case "", task.TaskKindStandard, task.TaskKindFederated:
+	default:
+		return pkgerrors.ErrInvalidValue
+	}
+	switch r.mode {
+	case "", task.ModeInfer, task.ModeTrain:
+	default:
+		return pkgerrors.ErrInvalidValue
+	}
+
+	return nil
+}
+
+type purgeTasksReq struct {
+	states    []string
+	olderThan time.Duration
+}
+
+func (r purgeTasksReq) validate() error {
+	for _, s := range r.states {
+		st, err := task.ToState(s)
+		if err != nil {
+			return err
+		}
+		if !st.IsTerminal() {
+			return pkgerrors.ErrInvalidValue
+		}
+	}
+	if r.olderThan < 0 {
+		return pkgerrors.ErrInvalidValue
+	}
 
 	return nil
 }
@@ -2,9 +2,12 @@ package api
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/absmach/magistrala"
 	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/events"
 	"github.com/absmach/propeller/pkg/proplet"
 	"github.com/absmach/propeller/pkg/sdf"
 	"github.com/absmach/propeller/pkg/task"
@@ -17,6 +20,7 @@ var (
 	_ magistrala.Response = (*propletAliveHistoryResponse)(nil)
 	_ magistrala.Response = (*taskResponse)(nil)
 	_ magistrala.Response = (*listTaskResponse)(nil)
+	_ magistrala.Response = (*purgeTasksResponse)(nil)
 	_ magistrala.Response = (*messageResponse)(nil)
 	_ magistrala.Response = (*taskMetricsResponse)(nil)
 	_ magistrala.Response = (*propletMetricsResponse)(nil)
@@ -154,6 +158,22 @@ func (l listTaskResponse) Empty() bool {
 	return false
 }
 
+type purgeTasksResponse struct {
+	Deleted uint64 `json:"deleted"`
+}
+
+func (p purgeTasksResponse) Code() int {
+	return http.StatusOK
+}
+
+func (p purgeTasksResponse) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (p purgeTasksResponse) Empty() bool {
+	return false
+}
+
 type messageResponse map[string]any
 
 func (w messageResponse) Code() int {
@@ -266,3 +286,37 @@ func (l listJobResponse) Headers() map[string]string {
 func (l listJobResponse) Empty() bool {
 	return len(l.Jobs) == 0
 }
+
+// flProgressEvent is the data payload of a GET /fl/jobs/{jobID}/stream event.
+type flProgressEvent struct {
+	ExperimentID string    `json:"experiment_id"`
+	RoundID      string    `json:"round_id"`
+	Round        uint64    `json:"round,omitempty"`
+	TotalRounds  uint64    `json:"total_rounds,omitempty"`
+	State        string    `json:"state"`
+	KOfN         int       `json:"k_of_n"`
+	Received     int       `json:"received"`
+	PropletID    string    `json:"proplet_id,omitempty"`
+	ModelVersion string    `json:"model_version,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+func newFLProgressEvent(evt events.Event) flProgressEvent {
+	kOfN, _ := strconv.Atoi(evt.Metadata["k_of_n"])
+	received, _ := strconv.Atoi(evt.Metadata["received"])
+	round, _ := strconv.ParseUint(evt.Metadata["round"], 10, 64)
+	totalRounds, _ := strconv.ParseUint(evt.Metadata["total_rounds"], 10, 64)
+
+	return flProgressEvent{
+		ExperimentID: evt.Metadata["experiment_id"],
+		RoundID:      evt.EntityID,
+		Round:        round,
+		TotalRounds:  totalRounds,
+		State:        evt.NewState,
+		KOfN:         kOfN,
+		Received:     received,
+		PropletID:    evt.Metadata["proplet_id"],
+		ModelVersion: evt.Metadata["model_version"],
+		Timestamp:    evt.Timestamp,
+	}
+}
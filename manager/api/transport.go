@@ -4,18 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/absmach/magistrala"
 	apiutil "github.com/absmach/magistrala/api/http/util"
 	"github.com/absmach/propeller/manager"
 	"github.com/absmach/propeller/pkg/api"
+	"github.com/absmach/propeller/pkg/audit"
+	"github.com/absmach/propeller/pkg/events"
+	"github.com/absmach/propeller/pkg/logging"
 	"github.com/absmach/propeller/pkg/plugin"
+	"github.com/absmach/propeller/pkg/task"
 	"github.com/go-chi/chi/v5"
 	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
@@ -24,20 +31,66 @@ const (
 	maxFileSize = 1024 * 1024 * 100
 	fileKey     = "file"
 	wasmMagic   = "\x00asm"
+
+	eventsWriteWait  = 10 * time.Second
+	eventsPingPeriod = 30 * time.Second
 )
 
-func MakeHandler(svc manager.Service, logger *slog.Logger, instanceID string) http.Handler {
+// Option configures the handler built by MakeHandler.
+type Option func(*handlerOptions)
+
+type handlerOptions struct {
+	maxBodySize    int64
+	debugEndpoints bool
+	logLevel       *slog.LevelVar
+}
+
+// WithMaxBodySize bounds the size in bytes of request bodies; larger requests
+// are rejected with 413 Request Entity Too Large. Non-positive sizes keep the
+// default of 100 MiB.
+func WithMaxBodySize(size int64) Option {
+	return func(o *handlerOptions) {
+		if size > 0 {
+			o.maxBodySize = size
+		}
+	}
+}
+
+// WithDebugEndpoints enables GET /debug/fl, which dumps the manager's
+// in-memory FL round state, and /loglevel, which changes the log level at
+// runtime. Neither is authenticated, so both are off by default.
+func WithDebugEndpoints(enabled bool) Option {
+	return func(o *handlerOptions) {
+		o.debugEndpoints = enabled
+	}
+}
+
+// WithLogLevel serves the level controlled by logLevel at /loglevel when the
+// debug endpoints are enabled.
+func WithLogLevel(logLevel *slog.LevelVar) Option {
+	return func(o *handlerOptions) {
+		o.logLevel = logLevel
+	}
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+func MakeHandler(svc manager.Service, logger *slog.Logger, instanceID string, options ...Option) http.Handler {
+	o := handlerOptions{maxBodySize: maxFileSize}
+	for _, opt := range options {
+		opt(&o)
+	}
+
 	mux := chi.NewRouter()
-	mux.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			r.Body = http.MaxBytesReader(w, r.Body, maxFileSize)
-			next.ServeHTTP(w, r)
-		})
-	})
+	mux.Use(maxBodySizeMiddleware(o.maxBodySize))
 	mux.Use(authContextMiddleware)
 
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(apiutil.LoggingErrorEncoder(logger, api.EncodeError)),
+		kithttp.ServerBefore(api.PopulateAccept),
 	}
 
 	mux.Route("/proplets", func(r chi.Router) {
@@ -66,6 +119,12 @@ func MakeHandler(svc manager.Service, logger *slog.Logger, instanceID string) ht
 				api.EncodeResponse,
 				opts...,
 			), "get-proplet-sdf").ServeHTTP)
+			r.Post("/labels", otelhttp.NewHandler(kithttp.NewServer(
+				setPropletLabelsEndpoint(svc),
+				decodePropletLabelsReq,
+				api.EncodeResponse,
+				opts...,
+			), "set-proplet-labels").ServeHTTP)
 			r.Get("/metrics", otelhttp.NewHandler(kithttp.NewServer(
 				getPropletMetricsEndpoint(svc),
 				decodeMetricsReq("propletID"),
@@ -94,6 +153,12 @@ func MakeHandler(svc manager.Service, logger *slog.Logger, instanceID string) ht
 			api.EncodeResponse,
 			opts...,
 		), "list-tasks").ServeHTTP)
+		r.Delete("/", otelhttp.NewHandler(kithttp.NewServer(
+			purgeTasksEndpoint(svc),
+			decodePurgeTasksReq,
+			api.EncodeResponse,
+			opts...,
+		), "purge-tasks").ServeHTTP)
 		r.Route("/{taskID}", func(r chi.Router) {
 			r.Get("/", otelhttp.NewHandler(kithttp.NewServer(
 				getTaskEndpoint(svc),
@@ -201,6 +266,73 @@ func MakeHandler(svc manager.Service, logger *slog.Logger, instanceID string) ht
 			opts...,
 		), "post-fl-update-cbor").ServeHTTP)
 
+		// GET /jobs/{jobID}/stream - Server-sent round progress for an experiment
+		r.Get("/jobs/{jobID}/stream", flProgressHandler(svc, logger))
+
+		// GET /jobs/{jobID}/metrics - Per-round metric series for an experiment
+		r.Get("/jobs/{jobID}/metrics", otelhttp.NewHandler(kithttp.NewServer(
+			getFLJobMetricsEndpoint(svc),
+			decodeFLJobMetricsReq,
+			api.EncodeResponse,
+			opts...,
+		), "get-fl-job-metrics").ServeHTTP)
+
+		// GET /jobs/{jobID}/state - Export an experiment's tasks, rounds and models as a bundle
+		r.Get("/jobs/{jobID}/state", otelhttp.NewHandler(kithttp.NewServer(
+			exportJobStateEndpoint(svc),
+			decodeJobStateReq,
+			api.EncodeResponse,
+			opts...,
+		), "export-fl-job-state").ServeHTTP)
+
+		// POST /jobs/{jobID}/state - Restore an exported experiment bundle
+		r.Post("/jobs/{jobID}/state", otelhttp.NewHandler(kithttp.NewServer(
+			importJobStateEndpoint(svc),
+			decodeImportJobStateReq,
+			api.EncodeResponse,
+			opts...,
+		), "import-fl-job-state").ServeHTTP)
+
+		// POST /models - Upload a global model version into the manager's registry
+		r.Post("/models", otelhttp.NewHandler(kithttp.NewServer(
+			storeModelEndpoint(svc),
+			decodeStoreModelReq,
+			api.EncodeResponse,
+			opts...,
+		), "store-model").ServeHTTP)
+
+		// GET /models/{version} - Fetch a stored global model version
+		r.Get("/models/{version}", otelhttp.NewHandler(kithttp.NewServer(
+			getModelEndpoint(svc),
+			decodeModelReq,
+			api.EncodeResponse,
+			opts...,
+		), "get-model").ServeHTTP)
+
+		// POST /models/{version}/rollback - Revert the global model to a stored version
+		r.Post("/models/{version}/rollback", otelhttp.NewHandler(kithttp.NewServer(
+			rollbackModelEndpoint(svc),
+			decodeRollbackModelReq,
+			api.EncodeResponse,
+			opts...,
+		), "rollback-model").ServeHTTP)
+
+		// POST /models/clients/{clientID} - Store a client's personalized model
+		r.Post("/models/clients/{clientID}", otelhttp.NewHandler(kithttp.NewServer(
+			storePersonalizedModelEndpoint(svc),
+			decodeStorePersonalizedModelReq,
+			api.EncodeResponse,
+			opts...,
+		), "store-personalized-model").ServeHTTP)
+
+		// GET /models/clients/{clientID} - Fetch a client's personalized model with the global model
+		r.Get("/models/clients/{clientID}", otelhttp.NewHandler(kithttp.NewServer(
+			getPersonalizedModelEndpoint(svc),
+			decodePersonalizedModelReq,
+			api.EncodeResponse,
+			opts...,
+		), "get-personalized-model").ServeHTTP)
+
 		// GET /rounds/{round_id}/complete - Forward round status request to FL Coordinator
 		r.Get("/rounds/{round_id}/complete", otelhttp.NewHandler(kithttp.NewServer(
 			getRoundStatusEndpoint(svc),
@@ -252,12 +384,160 @@ func MakeHandler(svc manager.Service, logger *slog.Logger, instanceID string) ht
 		})
 	})
 
+	mux.Get("/events", eventsHandler(svc, logger))
+
+	if o.debugEndpoints {
+		mux.Get("/debug/fl", otelhttp.NewHandler(kithttp.NewServer(
+			debugFLStateEndpoint(svc),
+			kithttp.NopRequestDecoder,
+			api.EncodeResponse,
+			opts...,
+		), "debug-fl-state").ServeHTTP)
+		if o.logLevel != nil {
+			mux.Handle("/loglevel", logging.LevelHandler(o.logLevel, logger))
+		}
+	}
+
 	mux.Get("/health", magistrala.Health("manager", instanceID))
 	mux.Handle("/metrics", promhttp.Handler())
 
 	return mux
 }
 
+// eventsHandler upgrades to a WebSocket and streams lifecycle events as JSON
+// text frames, optionally filtered by the task_id and job_id query params.
+// Clients that fall behind are disconnected with a policy-violation close.
+func eventsHandler(svc manager.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter := events.Filter{
+			TaskID: r.URL.Query().Get("task_id"),
+			JobID:  r.URL.Query().Get("job_id"),
+		}
+
+		sub, err := svc.SubscribeEvents(r.Context(), filter)
+		if err != nil {
+			api.EncodeError(r.Context(), err, w)
+
+			return
+		}
+		defer sub.Close()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.Warn("failed to upgrade events connection", slog.Any("error", err))
+
+			return
+		}
+		defer conn.Close()
+
+		// The client never sends data; reading only detects disconnects.
+		gone := make(chan struct{})
+		go func() {
+			defer close(gone)
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+
+		ping := time.NewTicker(eventsPingPeriod)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-gone:
+				return
+			case <-ping.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventsWriteWait)); err != nil {
+					return
+				}
+			case evt, ok := <-sub.Events():
+				if !ok {
+					msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+					if errors.Is(sub.Err(), events.ErrSlowConsumer) {
+						msg = websocket.FormatCloseMessage(websocket.ClosePolicyViolation, events.ErrSlowConsumer.Error())
+					}
+					_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(eventsWriteWait))
+
+					return
+				}
+				if err := conn.SetWriteDeadline(time.Now().Add(eventsWriteWait)); err != nil {
+					return
+				}
+				if err := conn.WriteJSON(evt); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// flProgressHandler streams FL round progress for one experiment as
+// server-sent events named after the round action (configure, update,
+// complete, aggregate, complete-job). The stream ends once the experiment's
+// round is aggregated or, for a job with a set number of rounds, once the
+// job completes.
+func flProgressHandler(svc manager.Service, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+
+			return
+		}
+
+		jobID := chi.URLParam(r, "jobID")
+		sub, err := svc.SubscribeEvents(r.Context(), events.Filter{ExperimentID: jobID})
+		if err != nil {
+			api.EncodeError(r.Context(), err, w)
+
+			return
+		}
+		defer sub.Close()
+
+		// The stream outlives the server's write timeout.
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			logger.Warn("failed to clear write deadline of FL progress stream", "error", err)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case evt, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+				if evt.EntityType != audit.EntityFLRound {
+					continue
+				}
+
+				data, err := json.Marshal(newFLProgressEvent(evt))
+				if err != nil {
+					logger.Warn("failed to encode FL progress event", slog.Any("error", err))
+
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Action, data); err != nil {
+					return
+				}
+				flusher.Flush()
+
+				if evt.Action == "complete-job" || (evt.Action == "aggregate" && evt.Metadata["total_rounds"] == "") {
+					return
+				}
+			}
+		}
+	}
+}
+
 func decodeEntityReq(key string) kithttp.DecodeRequestFunc {
 	return func(_ context.Context, r *http.Request) (any, error) {
 		return entityReq{
@@ -266,14 +546,20 @@ func decodeEntityReq(key string) kithttp.DecodeRequestFunc {
 	}
 }
 
-func decodeTaskReq(_ context.Context, r *http.Request) (any, error) {
-	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
-		return nil, errors.Join(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+func decodePropletLabelsReq(_ context.Context, r *http.Request) (any, error) {
+	var req propletLabelsReq
+	if err := api.DecodeBody(r, &req); err != nil {
+		return nil, err
 	}
+	req.id = chi.URLParam(r, "propletID")
+
+	return req, nil
+}
 
+func decodeTaskReq(_ context.Context, r *http.Request) (any, error) {
 	var req taskReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return nil, errors.Join(err, apiutil.ErrValidation)
+	if err := api.DecodeBody(r, &req); err != nil {
+		return nil, err
 	}
 
 	return req, nil
@@ -307,12 +593,9 @@ func decodeUploadTaskFileReq(_ context.Context, r *http.Request) (any, error) {
 }
 
 func decodeUpdateTaskReq(_ context.Context, r *http.Request) (any, error) {
-	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
-		return nil, errors.Join(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
-	}
 	var req taskReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return nil, errors.Join(err, apiutil.ErrValidation)
+	if err := api.DecodeBody(r, &req); err != nil {
+		return nil, err
 	}
 	req.ID = chi.URLParam(r, "taskID")
 
@@ -336,11 +619,17 @@ func decodeListEntityReq(statusFilter listEntityStatus) kithttp.DecodeRequestFun
 			return nil, errors.Join(apiutil.ErrValidation, err)
 		}
 
+		sort, err := readSortQuery(r)
+		if err != nil {
+			return nil, errors.Join(apiutil.ErrValidation, err)
+		}
+
 		return listEntityReq{
 			offset:       o,
 			limit:        l,
 			status:       s,
 			statusFilter: statusFilter,
+			sort:         sort,
 		}, nil
 	}
 }
@@ -361,13 +650,85 @@ func decodeListTasksReq(_ context.Context, r *http.Request) (any, error) {
 		return nil, errors.Join(apiutil.ErrValidation, err)
 	}
 
+	state, err := apiutil.ReadStringQuery(r, "state", "")
+	if err != nil {
+		return nil, errors.Join(apiutil.ErrValidation, err)
+	}
+
+	kind, err := apiutil.ReadStringQuery(r, "kind", "")
+	if err != nil {
+		return nil, errors.Join(apiutil.ErrValidation, err)
+	}
+
+	mode, err := apiutil.ReadStringQuery(r, "mode", "")
+	if err != nil {
+		return nil, errors.Join(apiutil.ErrValidation, err)
+	}
+
+	propletID, err := apiutil.ReadStringQuery(r, "proplet_id", "")
+	if err != nil {
+		return nil, errors.Join(apiutil.ErrValidation, err)
+	}
+
+	sort, err := readSortQuery(r)
+	if err != nil {
+		return nil, errors.Join(apiutil.ErrValidation, err)
+	}
+
 	return listTasksReq{
-		offset:   o,
-		limit:    l,
-		metadata: meta,
+		offset:    o,
+		limit:     l,
+		metadata:  meta,
+		state:     state,
+		kind:      task.TaskKind(kind),
+		mode:      task.Mode(mode),
+		propletID: propletID,
+		sort:      sort,
 	}, nil
 }
 
+func decodePurgeTasksReq(_ context.Context, r *http.Request) (any, error) {
+	state, err := apiutil.ReadStringQuery(r, "state", "")
+	if err != nil {
+		return nil, errors.Join(apiutil.ErrValidation, err)
+	}
+
+	olderThan, err := apiutil.ReadStringQuery(r, "older_than", "")
+	if err != nil {
+		return nil, errors.Join(apiutil.ErrValidation, err)
+	}
+
+	req := purgeTasksReq{}
+	for s := range strings.SplitSeq(state, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			req.states = append(req.states, s)
+		}
+	}
+	if olderThan != "" {
+		if req.olderThan, err = time.ParseDuration(olderThan); err != nil {
+			return nil, errors.Join(apiutil.ErrValidation, err)
+		}
+	}
+
+	return req, nil
+}
+
+// readSortQuery reads the sort field and order of a listing. Which fields
+// can be sorted by is checked by the service.
+func readSortQuery(r *http.Request) (manager.Sort, error) {
+	field, err := apiutil.ReadStringQuery(r, api.SortKey, "")
+	if err != nil {
+		return manager.Sort{}, err
+	}
+
+	order, err := apiutil.ReadStringQuery(r, api.OrderKey, "")
+	if err != nil {
+		return manager.Sort{}, err
+	}
+
+	return manager.Sort{Field: field, Order: order}, nil
+}
+
 func decodeMetricsReq(key string) kithttp.DecodeRequestFunc {
 	return func(_ context.Context, r *http.Request) (any, error) {
 		o, err := apiutil.ReadNumQuery[uint64](r, api.OffsetKey, api.DefOffset)
@@ -389,18 +750,31 @@ func decodeMetricsReq(key string) kithttp.DecodeRequestFunc {
 }
 
 func decodeWorkflowReq(_ context.Context, r *http.Request) (any, error) {
-	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
-		return nil, errors.Join(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
-	}
-
 	var req workflowReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return nil, errors.Join(err, apiutil.ErrValidation)
+	if err := api.DecodeBody(r, &req); err != nil {
+		return nil, err
 	}
 
 	return req, nil
 }
 
+// maxBodySizeMiddleware rejects requests declaring a body larger than limit
+// and caps the rest, so that decoding a body that turns out to be too large
+// fails with 413.
+func maxBodySizeMiddleware(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				api.EncodeError(r.Context(), &http.MaxBytesError{Limit: limit}, w)
+
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // authContextMiddleware populates AuthContext from request headers.
 //
 // SECURITY: X-User-Id is accepted as-is from the client and is NOT authenticated.
@@ -425,13 +799,9 @@ func authContextMiddleware(next http.Handler) http.Handler {
 }
 
 func decodeJobReq(_ context.Context, r *http.Request) (any, error) {
-	if !strings.Contains(r.Header.Get("Content-Type"), api.ContentType) {
-		return nil, errors.Join(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
-	}
-
 	var req jobReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		return nil, errors.Join(err, apiutil.ErrValidation)
+	if err := api.DecodeBody(r, &req); err != nil {
+		return nil, err
 	}
 
 	return req, nil
@@ -0,0 +1,127 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	managerapi "github.com/absmach/propeller/manager/api"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newSortServer(t *testing.T) (*httptest.Server, manager.Service, *storage.Repositories) {
+	t.Helper()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil)
+	ts := httptest.NewServer(managerapi.MakeHandler(svc, slog.Default(), "test"))
+	t.Cleanup(ts.Close)
+
+	return ts, svc, repos
+}
+
+func getJSON(t *testing.T, url string, v any) int {
+	t.Helper()
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(res.Body).Decode(v))
+	}
+
+	return res.StatusCode
+}
+
+func TestListTasksSorted(t *testing.T) {
+	t.Parallel()
+	ts, svc, _ := newSortServer(t)
+	for _, name := range []string{"charlie", "alpha", "bravo"} {
+		_, err := svc.CreateTask(context.Background(), task.Task{Name: name})
+		require.NoError(t, err)
+	}
+
+	cases := []struct {
+		desc       string
+		query      string
+		wantStatus int
+		wantNames  []string
+	}{
+		{desc: "by name", query: "?sort=name", wantStatus: http.StatusOK, wantNames: []string{"alpha", "bravo", "charlie"}},
+		{desc: "by name descending", query: "?sort=name&order=desc", wantStatus: http.StatusOK, wantNames: []string{"charlie", "bravo", "alpha"}},
+		{desc: "by creation time", query: "?sort=created_at&order=asc", wantStatus: http.StatusOK, wantNames: []string{"charlie", "alpha", "bravo"}},
+		{desc: "newest first", query: "?sort=created_at&order=desc", wantStatus: http.StatusOK, wantNames: []string{"bravo", "alpha", "charlie"}},
+		{desc: "sorted page", query: "?sort=name&offset=1&limit=1", wantStatus: http.StatusOK, wantNames: []string{"bravo"}},
+		{desc: "unsortable field", query: "?sort=file", wantStatus: http.StatusBadRequest},
+		{desc: "invalid order", query: "?sort=name&order=sideways", wantStatus: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			var page task.TaskPage
+			require.Equal(t, tc.wantStatus, getJSON(t, ts.URL+"/tasks"+tc.query, &page))
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			names := make([]string, len(page.Tasks))
+			for i, tk := range page.Tasks {
+				names[i] = tk.Name
+			}
+			assert.Equal(t, tc.wantNames, names)
+			assert.Equal(t, uint64(3), page.Total)
+		})
+	}
+}
+
+func TestListPropletsSorted(t *testing.T) {
+	t.Parallel()
+	ts, _, repos := newSortServer(t)
+	for _, p := range []proplet.Proplet{
+		{ID: "p-1", Name: "edge-b", TaskCount: 2},
+		{ID: "p-2", Name: "edge-c", TaskCount: 5},
+		{ID: "p-3", Name: "edge-a", TaskCount: 1},
+	} {
+		require.NoError(t, repos.Proplets.Create(context.Background(), p))
+	}
+
+	cases := []struct {
+		desc       string
+		url        string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{desc: "by name", url: "/proplets?sort=name", wantStatus: http.StatusOK, wantIDs: []string{"p-3", "p-1", "p-2"}},
+		{desc: "busiest first", url: "/proplets?sort=task_count&order=desc", wantStatus: http.StatusOK, wantIDs: []string{"p-2", "p-1", "p-3"}},
+		{desc: "sorted with status filter", url: "/proplets?status=inactive&sort=id&order=desc", wantStatus: http.StatusOK, wantIDs: []string{"p-3", "p-2", "p-1"}},
+		{desc: "unsortable field", url: "/proplets?sort=alive_at", wantStatus: http.StatusBadRequest},
+		{desc: "jobs cannot be sorted", url: "/jobs?sort=name", wantStatus: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			var page proplet.PropletPageView
+			require.Equal(t, tc.wantStatus, getJSON(t, ts.URL+tc.url, &page))
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			ids := make([]string, len(page.Proplets))
+			for i, p := range page.Proplets {
+				ids[i] = p.ID
+			}
+			assert.Equal(t, tc.wantIDs, ids)
+		})
+	}
+}
@@ -1,29 +1,38 @@
 package api_test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/absmach/propeller/manager"
 	managerapi "github.com/absmach/propeller/manager/api"
 	"github.com/absmach/propeller/manager/mocks"
 	pkgerrors "github.com/absmach/propeller/pkg/errors"
 	"github.com/absmach/propeller/pkg/proplet"
 	"github.com/absmach/propeller/pkg/sdf"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/fxamacker/cbor/v2"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-func newServer(t *testing.T) (*httptest.Server, *mocks.MockService) {
+func newServer(t *testing.T, opts ...managerapi.Option) (*httptest.Server, *mocks.MockService) {
 	t.Helper()
 	svc := new(mocks.MockService)
-	handler := managerapi.MakeHandler(svc, slog.Default(), "test")
+	handler := managerapi.MakeHandler(svc, slog.Default(), "test", opts...)
 
 	return httptest.NewServer(handler), svc
 }
@@ -143,7 +152,7 @@ func TestListProplets(t *testing.T) {
 			defer ts.Close()
 
 			if tc.wantStatus != http.StatusBadRequest {
-				svc.On("ListProplets", mock.Anything, uint64(0), mock.AnythingOfType("uint64"), mock.AnythingOfType("string")).Return(tc.svcPage, tc.svcErr)
+				svc.On("ListProplets", mock.Anything, uint64(0), mock.AnythingOfType("uint64"), mock.AnythingOfType("string"), manager.Sort{}).Return(tc.svcPage, tc.svcErr)
 			}
 
 			res, err := http.Get(ts.URL + "/proplets/" + tc.query)
@@ -165,3 +174,819 @@ func TestListProplets(t *testing.T) {
 		})
 	}
 }
+
+func TestGetFLJobMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := manager.FLJobMetrics{
+		JobID: "exp-1",
+		Rounds: []manager.RoundMetrics{
+			{RoundID: "round-1", ModelVersion: 1, NumUpdates: 2, Metrics: map[string]float64{"loss": 0.5}},
+			{RoundID: "round-2", ModelVersion: 2, NumUpdates: 2, Metrics: map[string]float64{"loss": 0.25}},
+		},
+	}
+
+	cases := []struct {
+		desc       string
+		svcMetrics manager.FLJobMetrics
+		svcErr     error
+		wantStatus int
+	}{
+		{
+			desc:       "get metrics of a job with completed rounds",
+			svcMetrics: metrics,
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "get metrics of an unknown job returns 404",
+			svcErr:     pkgerrors.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			ts, svc := newServer(t)
+			defer ts.Close()
+
+			svc.On("GetFLJobMetrics", mock.Anything, "exp-1").Return(tc.svcMetrics, tc.svcErr)
+
+			res, err := http.Get(ts.URL + "/fl/jobs/exp-1/metrics")
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+
+			if tc.wantStatus == http.StatusOK {
+				var got manager.FLJobMetrics
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+				assert.Equal(t, "exp-1", got.JobID)
+				require.Len(t, got.Rounds, 2)
+				assert.Equal(t, "round-1", got.Rounds[0].RoundID)
+				assert.Equal(t, "round-2", got.Rounds[1].RoundID)
+			}
+		})
+	}
+}
+
+func TestRollbackModel(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc       string
+		version    string
+		svcErr     error
+		wantStatus int
+	}{
+		{
+			desc:       "roll back to a stored version",
+			version:    "2",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "roll back to an unknown version returns 404",
+			version:    "2",
+			svcErr:     pkgerrors.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "roll back to an invalid version returns 400",
+			version:    "latest",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			ts, svc := newServer(t)
+			defer ts.Close()
+
+			svc.On("RollbackModel", mock.Anything, 2).Return(tc.svcErr).Maybe()
+
+			res, err := http.Post(ts.URL+"/fl/models/"+tc.version+"/rollback", "application/json", http.NoBody)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestStoreModel(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc       string
+		body       string
+		svcErr     error
+		wantStatus int
+	}{
+		{
+			desc:       "upload a new model version",
+			body:       `{"version":2,"data":{"w":[0.1,0.2]},"metadata":{"source":"import"}}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			desc:       "upload an existing model version returns 409",
+			body:       `{"version":2,"data":{"w":[0.1,0.2]},"metadata":{"source":"import"}}`,
+			svcErr:     pkgerrors.ErrConflict,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			desc:       "upload without a version returns 400",
+			body:       `{"data":{"w":[0.1,0.2]}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "upload without data returns 400",
+			body:       `{"version":2}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			ts, svc := newServer(t)
+			defer ts.Close()
+
+			model := manager.Model{
+				Data:     map[string]any{"w": []any{0.1, 0.2}},
+				Metadata: map[string]any{"source": "import"},
+			}
+			svc.On("StoreModel", mock.Anything, 2, model).Return(tc.svcErr).Maybe()
+
+			res, err := http.Post(ts.URL+"/fl/models", "application/json", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+			if tc.wantStatus == http.StatusCreated {
+				assert.Equal(t, "/fl/models/2", res.Header.Get("Location"))
+			}
+		})
+	}
+}
+
+func TestGetModel(t *testing.T) {
+	t.Parallel()
+
+	model := manager.Model{
+		Data:     map[string]any{"w": []any{0.1, 0.2}},
+		Metadata: map[string]any{"source": "import"},
+	}
+
+	cases := []struct {
+		desc       string
+		svcModel   manager.Model
+		svcErr     error
+		wantStatus int
+	}{
+		{
+			desc:       "fetch a stored model version",
+			svcModel:   model,
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "fetch an unknown model version returns 404",
+			svcErr:     pkgerrors.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			ts, svc := newServer(t)
+			defer ts.Close()
+
+			svc.On("GetModel", mock.Anything, 2).Return(tc.svcModel, tc.svcErr)
+
+			res, err := http.Get(ts.URL + "/fl/models/2")
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+			if tc.wantStatus == http.StatusOK {
+				var got struct {
+					Version int `json:"version"`
+					manager.Model
+				}
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+				assert.Equal(t, 2, got.Version)
+				assert.Equal(t, model, got.Model)
+			}
+		})
+	}
+}
+
+func TestPersonalizedModelEndpoints(t *testing.T) {
+	t.Parallel()
+
+	model := manager.Model{Data: map[string]any{"head": []any{0.1}}}
+	global := manager.Model{Data: map[string]any{"w": []any{0.5}}}
+
+	t.Run("store a personalized model", func(t *testing.T) {
+		t.Parallel()
+		ts, svc := newServer(t)
+		defer ts.Close()
+
+		svc.On("StorePersonalizedModel", mock.Anything, "client-a", model).Return(nil)
+
+		res, err := http.Post(ts.URL+"/fl/models/clients/client-a", "application/json", strings.NewReader(`{"data":{"head":[0.1]}}`))
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Equal(t, "/fl/models/clients/client-a", res.Header.Get("Location"))
+	})
+
+	t.Run("store without data returns 400", func(t *testing.T) {
+		t.Parallel()
+		ts, _ := newServer(t)
+		defer ts.Close()
+
+		res, err := http.Post(ts.URL+"/fl/models/clients/client-a", "application/json", strings.NewReader(`{}`))
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("fetch a personalized model with the global model", func(t *testing.T) {
+		t.Parallel()
+		ts, svc := newServer(t)
+		defer ts.Close()
+
+		svc.On("GetPersonalizedModel", mock.Anything, "client-a").Return(manager.PersonalizedModel{
+			ClientID:      "client-a",
+			Personalized:  model,
+			GlobalVersion: 3,
+			Global:        &global,
+		}, nil)
+
+		res, err := http.Get(ts.URL + "/fl/models/clients/client-a")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var got manager.PersonalizedModel
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		assert.Equal(t, model, got.Personalized)
+		assert.Equal(t, 3, got.GlobalVersion)
+		require.NotNil(t, got.Global)
+		assert.Equal(t, global, *got.Global)
+	})
+
+	t.Run("fetch an unknown client returns 404", func(t *testing.T) {
+		t.Parallel()
+		ts, svc := newServer(t)
+		defer ts.Close()
+
+		svc.On("GetPersonalizedModel", mock.Anything, "client-x").Return(manager.PersonalizedModel{}, pkgerrors.ErrNotFound)
+
+		res, err := http.Get(ts.URL + "/fl/models/clients/client-x")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestJobStateEndpoints(t *testing.T) {
+	t.Parallel()
+
+	bundle := `{"version":1,"job_id":"exp-1","exported_at":"2025-01-01T00:00:00Z","tasks":[]}`
+
+	t.Run("export returns the bundle", func(t *testing.T) {
+		t.Parallel()
+		ts, svc := newServer(t)
+		defer ts.Close()
+
+		svc.On("ExportJobState", mock.Anything, "exp-1").Return([]byte(bundle), nil)
+
+		res, err := http.Get(ts.URL + "/fl/jobs/exp-1/state")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, bundle, string(body))
+	})
+
+	t.Run("export of an unknown job returns 404", func(t *testing.T) {
+		t.Parallel()
+		ts, svc := newServer(t)
+		defer ts.Close()
+
+		svc.On("ExportJobState", mock.Anything, "exp-x").Return(nil, pkgerrors.ErrNotFound)
+
+		res, err := http.Get(ts.URL + "/fl/jobs/exp-x/state")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("import passes the bundle through", func(t *testing.T) {
+		t.Parallel()
+		ts, svc := newServer(t)
+		defer ts.Close()
+
+		svc.On("ImportJobState", mock.Anything, []byte(bundle)).Return(nil)
+
+		res, err := http.Post(ts.URL+"/fl/jobs/exp-1/state", "application/json", strings.NewReader(bundle))
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("import into another job returns 400", func(t *testing.T) {
+		t.Parallel()
+		ts, _ := newServer(t)
+		defer ts.Close()
+
+		res, err := http.Post(ts.URL+"/fl/jobs/exp-2/state", "application/json", strings.NewReader(bundle))
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestCreateTaskContentNegotiation(t *testing.T) {
+	t.Parallel()
+
+	req := task.Task{
+		Name:     "cbor-task",
+		ImageURL: "oci://example/add:latest",
+		Inputs:   task.FlexStrings{"1", "2"},
+		Env:      map[string]string{"MODE": "edge"},
+		Metadata: task.Metadata{"owner": "fleet"},
+	}
+
+	cases := []struct {
+		desc            string
+		contentType     string
+		accept          string
+		wantContentType string
+	}{
+		{
+			desc:            "cbor request and response",
+			contentType:     "application/cbor",
+			accept:          "application/cbor",
+			wantContentType: "application/cbor",
+		},
+		{
+			desc:            "cbor request with json response by default",
+			contentType:     "application/cbor",
+			wantContentType: "application/json",
+		},
+		{
+			desc:            "json request with cbor response",
+			contentType:     "application/json",
+			accept:          "application/json;q=0.5, application/cbor",
+			wantContentType: "application/cbor",
+		},
+		{
+			desc:            "json preferred over cbor",
+			contentType:     "application/json",
+			accept:          "application/cbor;q=0.2, application/json",
+			wantContentType: "application/json",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			ts, svc := newServer(t)
+			defer ts.Close()
+
+			svc.On("CreateTask", mock.Anything, mock.MatchedBy(func(got task.Task) bool {
+				return got.Name == req.Name && got.ImageURL == req.ImageURL &&
+					assert.ObjectsAreEqual(req.Inputs, got.Inputs) &&
+					assert.ObjectsAreEqual(req.Env, got.Env) &&
+					assert.ObjectsAreEqual(req.Metadata, got.Metadata)
+			})).Return(func(_ context.Context, got task.Task) (task.Task, error) {
+				got.ID = "task-1"
+				got.CreatedAt = time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+
+				return got, nil
+			})
+
+			var body []byte
+			var err error
+			if tc.contentType == "application/cbor" {
+				body, err = cbor.Marshal(req)
+			} else {
+				body, err = json.Marshal(req)
+			}
+			require.NoError(t, err)
+
+			httpReq, err := http.NewRequest(http.MethodPost, ts.URL+"/tasks", bytes.NewReader(body))
+			require.NoError(t, err)
+			httpReq.Header.Set("Content-Type", tc.contentType)
+			if tc.accept != "" {
+				httpReq.Header.Set("Accept", tc.accept)
+			}
+			res, err := http.DefaultClient.Do(httpReq)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			require.Equal(t, http.StatusCreated, res.StatusCode)
+			assert.Equal(t, tc.wantContentType, res.Header.Get("Content-Type"))
+
+			var got task.Task
+			if tc.wantContentType == "application/cbor" {
+				require.NoError(t, cbor.NewDecoder(res.Body).Decode(&got))
+			} else {
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+			}
+			assert.Equal(t, "task-1", got.ID)
+			assert.Equal(t, req.Name, got.Name)
+			assert.Equal(t, req.Inputs, got.Inputs)
+			assert.Equal(t, req.Env, got.Env)
+			assert.True(t, got.CreatedAt.Equal(time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)))
+		})
+	}
+}
+
+func TestRequestBodyLimits(t *testing.T) {
+	t.Parallel()
+	ts, svc := newServer(t, managerapi.WithMaxBodySize(64))
+	defer ts.Close()
+	svc.On("CreateTask", mock.Anything, mock.Anything).Return(task.Task{ID: "task-1", Name: "small"}, nil).Maybe()
+
+	small := `{"name":"small"}`
+	large := `{"name":"` + strings.Repeat("x", 128) + `"}`
+
+	cases := []struct {
+		desc       string
+		body       io.Reader
+		wantStatus int
+	}{
+		{
+			desc:       "body within the limit",
+			body:       strings.NewReader(small),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			desc:       "declared body over the limit",
+			body:       strings.NewReader(large),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:       "chunked body over the limit",
+			body:       io.MultiReader(strings.NewReader(large)),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := http.Post(ts.URL+"/tasks", "application/json", tc.body)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestSlowRequestBodyTimesOut(t *testing.T) {
+	t.Parallel()
+	svc := new(mocks.MockService)
+	ts := httptest.NewUnstartedServer(managerapi.MakeHandler(svc, slog.Default(), "test"))
+	ts.Config.ReadTimeout = 200 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = fmt.Fprint(conn, "POST /tasks HTTP/1.1\r\nHost: manager\r\nContent-Type: application/json\r\nContent-Length: 64\r\n\r\n{\"name\":")
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusRequestTimeout, res.StatusCode)
+}
+
+func TestListTasksFilters(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc       string
+		query      string
+		wantPM     manager.PageMetadata
+		wantStatus int
+	}{
+		{
+			desc:       "no filters",
+			query:      "",
+			wantPM:     manager.PageMetadata{Limit: 100},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "filter by state",
+			query:      "?state=running",
+			wantPM:     manager.PageMetadata{Limit: 100, State: "running"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "filter by kind",
+			query:      "?kind=federated",
+			wantPM:     manager.PageMetadata{Limit: 100, Kind: task.TaskKindFederated},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "filter by mode",
+			query:      "?mode=train",
+			wantPM:     manager.PageMetadata{Limit: 100, Mode: task.ModeTrain},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "filter by proplet",
+			query:      "?proplet_id=proplet-1",
+			wantPM:     manager.PageMetadata{Limit: 100, PropletID: "proplet-1"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:  "combined filters with pagination",
+			query: "?state=Running&kind=federated&mode=train&proplet_id=proplet-1&offset=5&limit=20",
+			wantPM: manager.PageMetadata{
+				Offset: 5, Limit: 20, State: "Running", Kind: task.TaskKindFederated,
+				Mode: task.ModeTrain, PropletID: "proplet-1",
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "invalid state returns 400",
+			query:      "?state=sleeping",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "invalid kind returns 400",
+			query:      "?kind=batch",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "invalid mode returns 400",
+			query:      "?mode=serve",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			ts, svc := newServer(t)
+			defer ts.Close()
+
+			if tc.wantStatus == http.StatusOK {
+				svc.On("ListTasks", mock.Anything, tc.wantPM).Return(task.TaskPage{Total: 1, Tasks: []task.Task{{ID: "task-1"}}}, nil)
+			}
+
+			res, err := http.Get(ts.URL + "/tasks" + tc.query)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPurgeTasks(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc       string
+		query      string
+		wantFilter manager.PurgeFilter
+		wantStatus int
+	}{
+		{
+			desc:       "completed older than a day",
+			query:      "?state=completed&older_than=24h",
+			wantFilter: manager.PurgeFilter{States: []task.State{task.Completed}, OlderThan: 24 * time.Hour},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "several states",
+			query:      "?state=completed,Failed",
+			wantFilter: manager.PurgeFilter{States: []task.State{task.Completed, task.Failed}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "no filters",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "non-terminal state returns 400",
+			query:      "?state=running",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "unknown state returns 400",
+			query:      "?state=sleeping",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "invalid duration returns 400",
+			query:      "?older_than=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "negative duration returns 400",
+			query:      "?older_than=-1h",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			ts, svc := newServer(t)
+			defer ts.Close()
+
+			if tc.wantStatus == http.StatusOK {
+				svc.On("PurgeTasks", mock.Anything, tc.wantFilter).Return(uint64(3), nil)
+			}
+
+			req, err := http.NewRequest(http.MethodDelete, ts.URL+"/tasks"+tc.query, http.NoBody)
+			require.NoError(t, err)
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+			if tc.wantStatus == http.StatusOK {
+				var body struct {
+					Deleted uint64 `json:"deleted"`
+				}
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+				assert.Equal(t, uint64(3), body.Deleted)
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSetPropletLabels(t *testing.T) {
+	t.Parallel()
+
+	validID := uuid.NewString()
+	labels := map[string]string{proplet.GroupLabel: "edge-us-east"}
+
+	cases := []struct {
+		desc       string
+		propletID  string
+		body       string
+		svcErr     error
+		wantStatus int
+	}{
+		{
+			desc:       "set labels",
+			propletID:  validID,
+			body:       `{"labels":{"group":"edge-us-east"}}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "set labels on unknown proplet returns 404",
+			propletID:  validID,
+			body:       `{"labels":{"group":"edge-us-east"}}`,
+			svcErr:     pkgerrors.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "empty label key returns 400",
+			propletID:  validID,
+			body:       `{"labels":{"":"edge-us-east"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "invalid proplet ID returns 400",
+			propletID:  "not-a-valid-uuid",
+			body:       `{"labels":{"group":"edge-us-east"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "malformed body returns 400",
+			propletID:  validID,
+			body:       `{"labels":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			ts, svc := newServer(t)
+			defer ts.Close()
+
+			if tc.wantStatus == http.StatusOK || tc.svcErr != nil {
+				svc.On("SetPropletLabels", mock.Anything, tc.propletID, labels).
+					Return(proplet.Proplet{ID: tc.propletID, Labels: labels}, tc.svcErr)
+			}
+
+			reqURL := fmt.Sprintf("%s/proplets/%s/labels", ts.URL, tc.propletID)
+			res, err := http.Post(reqURL, "application/json", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, res.StatusCode)
+			if tc.wantStatus == http.StatusOK {
+				var view proplet.PropletView
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&view))
+				assert.Equal(t, labels, view.Labels)
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDebugFLStateEndpoint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+		ts, _ := newServer(t)
+		defer ts.Close()
+
+		res, err := http.Get(ts.URL + "/debug/fl")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("dumps round state when enabled", func(t *testing.T) {
+		t.Parallel()
+		ts, svc := newServer(t, managerapi.WithDebugEndpoints(true))
+		defer ts.Close()
+
+		svc.On("DebugFLState", mock.Anything).Return(manager.FLDebugState{
+			Jobs: map[string][]manager.FLRoundDebug{
+				"exp-1": {{RoundID: "round-1", KOfN: 2, Received: []string{"proplet-a"}, Missing: []string{"proplet-b"}}},
+			},
+		}, nil)
+
+		res, err := http.Get(ts.URL + "/debug/fl")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var got manager.FLDebugState
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&got))
+		require.Len(t, got.Jobs["exp-1"], 1)
+		assert.Equal(t, []string{"proplet-b"}, got.Jobs["exp-1"][0].Missing)
+	})
+}
+
+func TestLogLevelEndpoint(t *testing.T) {
+	t.Parallel()
+
+	newLevelServer := func(t *testing.T, opts ...managerapi.Option) (*httptest.Server, *slog.LevelVar) {
+		t.Helper()
+		lv := new(slog.LevelVar)
+		ts := httptest.NewServer(managerapi.MakeHandler(new(mocks.MockService), slog.Default(), "test", append(opts, managerapi.WithLogLevel(lv))...))
+		t.Cleanup(ts.Close)
+
+		return ts, lv
+	}
+	putLevel := func(t *testing.T, url string) int {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPut, url+"/loglevel", strings.NewReader(`{"level":"debug"}`))
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		return res.StatusCode
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+		ts, lv := newLevelServer(t)
+
+		assert.Equal(t, http.StatusNotFound, putLevel(t, ts.URL))
+		assert.Equal(t, slog.LevelInfo, lv.Level())
+	})
+
+	t.Run("changes the level when enabled", func(t *testing.T) {
+		t.Parallel()
+		ts, lv := newLevelServer(t, managerapi.WithDebugEndpoints(true))
+
+		assert.Equal(t, http.StatusOK, putLevel(t, ts.URL))
+		assert.Equal(t, slog.LevelDebug, lv.Level())
+	})
+}
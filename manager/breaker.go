@@ -0,0 +1,165 @@
+package manager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/absmach/propeller/pkg/proplet"
+)
+
+const (
+	defaultBreakerWindow   = 5
+	defaultBreakerCooldown = time.Minute
+)
+
+// BreakerConfig configures the per-proplet circuit breaker.
+type BreakerConfig struct {
+	// FailureRate is the fraction (0-1] of a proplet's recent task results
+	// that must be failures to stop sending it new tasks. Zero disables the
+	// breaker.
+	FailureRate float64 `env:"MANAGER_PROPLET_BREAKER_FAILURE_RATE"`
+	// Window is how many of a proplet's most recent task results the
+	// failure rate is computed over.
+	Window int `env:"MANAGER_PROPLET_BREAKER_WINDOW" envDefault:"5"`
+	// Cooldown is how long a tripped proplet is skipped before it is
+	// admitted again.
+	Cooldown time.Duration `env:"MANAGER_PROPLET_BREAKER_COOLDOWN" envDefault:"1m"`
+}
+
+type propletBreaker struct {
+	results   []bool
+	openUntil time.Time
+}
+
+func (b *propletBreaker) failures() int {
+	n := 0
+	for _, failed := range b.results {
+		if failed {
+			n++
+		}
+	}
+
+	return n
+}
+
+// breakers trips a circuit breaker for each proplet whose recent task results
+// are mostly failures, so the scheduler skips it until its cooldown passes.
+// A re-admitted proplet starts over with an empty window.
+type breakers struct {
+	mu          sync.Mutex
+	proplets    map[string]*propletBreaker
+	failureRate float64
+	window      int
+	cooldown    time.Duration
+}
+
+func newBreakers(cfg BreakerConfig) *breakers {
+	b := &breakers{
+		proplets: make(map[string]*propletBreaker),
+		window:   defaultBreakerWindow,
+		cooldown: defaultBreakerCooldown,
+	}
+	if cfg.FailureRate > 0 && cfg.FailureRate <= 1 {
+		b.failureRate = cfg.FailureRate
+	}
+	if cfg.Window > 0 {
+		b.window = cfg.Window
+	}
+	if cfg.Cooldown > 0 {
+		b.cooldown = cfg.Cooldown
+	}
+
+	return b
+}
+
+// record adds a task result reported by propletID and reports whether it
+// tripped the proplet's breaker. Results reported while the breaker is open
+// are ignored.
+func (b *breakers) record(propletID string, failed bool, now time.Time) bool {
+	if b.failureRate == 0 || propletID == "" {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pb := b.get(propletID, now)
+	if now.Before(pb.openUntil) {
+		return false
+	}
+	pb.results = append(pb.results, failed)
+	if len(pb.results) > b.window {
+		pb.results = pb.results[len(pb.results)-b.window:]
+	}
+	if len(pb.results) < b.window || float64(pb.failures()) < b.failureRate*float64(b.window) {
+		return false
+	}
+	pb.openUntil = now.Add(b.cooldown)
+
+	return true
+}
+
+// open reports whether propletID must not receive new tasks.
+func (b *breakers) open(propletID string, now time.Time) bool {
+	if b.failureRate == 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pb, ok := b.proplets[propletID]
+	if !ok {
+		return false
+	}
+	b.readmit(pb, now)
+
+	return now.Before(pb.openUntil)
+}
+
+// state returns the breaker state of propletID, or nil when the breaker is
+// disabled or the proplet has reported no results.
+func (b *breakers) state(propletID string, now time.Time) *proplet.BreakerState {
+	if b.failureRate == 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pb, ok := b.proplets[propletID]
+	if !ok {
+		return nil
+	}
+	b.readmit(pb, now)
+
+	st := &proplet.BreakerState{
+		Open:           now.Before(pb.openUntil),
+		RecentResults:  len(pb.results),
+		RecentFailures: pb.failures(),
+	}
+	if st.Open {
+		until := pb.openUntil
+		st.OpenUntil = &until
+	}
+
+	return st
+}
+
+func (b *breakers) get(propletID string, now time.Time) *propletBreaker {
+	pb, ok := b.proplets[propletID]
+	if !ok {
+		pb = &propletBreaker{}
+		b.proplets[propletID] = pb
+	}
+	b.readmit(pb, now)
+
+	return pb
+}
+
+func (b *breakers) readmit(pb *propletBreaker, now time.Time) {
+	if !pb.openUntil.IsZero() && !now.Before(pb.openUntil) {
+		pb.openUntil = time.Time{}
+		pb.results = nil
+	}
+}
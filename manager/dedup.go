@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDedupWindow = 10 * time.Minute
+	defaultDedupSize   = 4096
+	messageIDKey       = "message_id"
+)
+
+// DedupConfig configures the deduplication of results and metrics messages.
+type DedupConfig struct {
+	// Window is how long a message ID is remembered. Zero disables
+	// deduplication.
+	Window time.Duration `env:"MANAGER_DEDUP_WINDOW" envDefault:"10m"`
+	// Size bounds how many message IDs are remembered at once; the oldest
+	// are forgotten first.
+	Size int `env:"MANAGER_DEDUP_SIZE" envDefault:"4096"`
+}
+
+type dedupEntry struct {
+	id   string
+	seen time.Time
+}
+
+// dedup remembers recently handled MQTT message IDs so that QoS-1
+// redeliveries of results and metrics are processed once. Messages without
+// an ID are never treated as duplicates.
+type dedup struct {
+	mu     sync.Mutex
+	window time.Duration
+	size   int
+	order  *list.List
+	index  map[string]*list.Element
+}
+
+func newDedup(cfg DedupConfig) *dedup {
+	window := cfg.Window
+	if window < 0 {
+		window = defaultDedupWindow
+	}
+	size := cfg.Size
+	if size <= 0 {
+		size = defaultDedupSize
+	}
+
+	return &dedup{
+		window: window,
+		size:   size,
+		order:  list.New(),
+		index:  make(map[string]*list.Element),
+	}
+}
+
+// seen reports whether id was already handled within the window and, when it
+// was not, remembers it.
+func (d *dedup) seen(id string) bool {
+	if id == "" || d.window == 0 {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	d.expire(now)
+	if _, ok := d.index[id]; ok {
+		return true
+	}
+
+	d.index[id] = d.order.PushBack(dedupEntry{id: id, seen: now})
+	for d.order.Len() > d.size {
+		d.remove(d.order.Front())
+	}
+
+	return false
+}
+
+// forget drops id so a redelivery of a message that failed is processed
+// again.
+func (d *dedup) forget(id string) {
+	if id == "" {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if e, ok := d.index[id]; ok {
+		d.remove(e)
+	}
+}
+
+func (d *dedup) expire(now time.Time) {
+	for e := d.order.Front(); e != nil; e = d.order.Front() {
+		if now.Sub(e.Value.(dedupEntry).seen) < d.window {
+			return
+		}
+		d.remove(e)
+	}
+}
+
+func (d *dedup) remove(e *list.Element) {
+	delete(d.index, e.Value.(dedupEntry).id)
+	d.order.Remove(e)
+}
@@ -0,0 +1,36 @@
+package manager
+
+import (
+	"maps"
+	"strings"
+)
+
+// ParseDefaultTaskEnv splits comma-separated "KEY=VALUE" pairs, as set in
+// MANAGER_DEFAULT_TASK_ENV, into the default task environment. Malformed
+// pairs are skipped.
+func ParseDefaultTaskEnv(value string) map[string]string {
+	env := make(map[string]string)
+	for pair := range strings.SplitSeq(value, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" {
+			continue
+		}
+		env[key] = value
+	}
+
+	return env
+}
+
+// withDefaultEnv returns env merged over the default task environment,
+// leaving env itself unchanged.
+func (svc *service) withDefaultEnv(env map[string]string) map[string]string {
+	if len(svc.defaultEnv) == 0 {
+		return env
+	}
+
+	out := make(map[string]string, len(svc.defaultEnv)+len(env))
+	maps.Copy(out, svc.defaultEnv)
+	maps.Copy(out, env)
+
+	return out
+}
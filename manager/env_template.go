@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"context"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/absmach/propeller/pkg/task"
+)
+
+// envVarPattern matches the ${name} references resolved in task env values.
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// envTemplateVars returns the values ${name} references in a task's env
+// resolve to when it starts on propletID. Fields the task does not have are
+// left out, so references to them stay unresolved.
+func (svc *service) envTemplateVars(t task.Task, propletID string) map[string]string {
+	vars := map[string]string{
+		"id":          t.ID,
+		"name":        t.Name,
+		"job_id":      t.JobID,
+		"workflow_id": t.WorkflowID,
+		"proplet_id":  propletID,
+		"round_id":    t.Env["ROUND_ID"],
+	}
+	if vars["round_id"] == "" {
+		vars["round_id"], _ = t.Metadata[roundMetadataKey].(string)
+	}
+	if len(svc.models.List()) > 0 {
+		vars["global_version"] = strconv.Itoa(svc.models.Current())
+	}
+	for name, value := range vars {
+		if value == "" {
+			delete(vars, name)
+		}
+	}
+
+	return vars
+}
+
+// expandEnv returns env with ${name} references in its values replaced by
+// the task's fields. Unknown or unset references are kept as written and
+// logged. env itself is left unchanged.
+func (svc *service) expandEnv(ctx context.Context, t task.Task, propletID string, env map[string]string) map[string]string {
+	var vars map[string]string
+	var out map[string]string
+	for key, value := range env {
+		if !strings.Contains(value, "${") {
+			continue
+		}
+		if vars == nil {
+			vars = svc.envTemplateVars(t, propletID)
+			out = maps.Clone(env)
+		}
+		out[key] = envVarPattern.ReplaceAllStringFunc(value, func(ref string) string {
+			name := ref[2 : len(ref)-1]
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			svc.logger.WarnContext(ctx, "unresolved variable in task env",
+				"task_id", t.ID, "env", key, "variable", name)
+
+			return ref
+		})
+	}
+	if out == nil {
+		return env
+	}
+
+	return out
+}
@@ -0,0 +1,140 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/absmach/propeller/pkg/audit"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/absmach/propeller/pkg/plugin"
+)
+
+// flModelTopic carries the global model after every asynchronous blend.
+const flModelTopic = "/fl/models/global"
+
+type asyncRound struct {
+	experimentID string
+	alpha        float64
+	version      int
+	model        map[string]any
+	updatedAt    time.Time
+}
+
+// flAsync holds the global model of each FedAsync round. Updates are blended
+// into it as they arrive instead of being handed to the coordinator. A round
+// that receives no update for flStateTTL is dropped.
+type flAsync struct {
+	mu     sync.Mutex
+	rounds map[string]*asyncRound
+}
+
+func newFLAsync() *flAsync {
+	return &flAsync{rounds: make(map[string]*asyncRound)}
+}
+
+func (a *flAsync) configure(roundID, experimentID string, alpha float64, version int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	evictExpired(a.rounds, func(r *asyncRound) time.Time { return r.updatedAt })
+	a.rounds[roundID] = &asyncRound{
+		experimentID: experimentID,
+		alpha:        alpha,
+		version:      version,
+		updatedAt:    time.Now(),
+	}
+}
+
+func (a *flAsync) active(roundID string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, ok := a.rounds[roundID]
+
+	return ok
+}
+
+// blendResult describes the global model produced by one blend. Staleness is
+// how many versions the update's base model lagged behind.
+type blendResult struct {
+	experimentID string
+	version      int
+	staleness    int
+	alpha        float64
+	model        map[string]any
+}
+
+// blend mixes update into the round's global model and bumps its version.
+func (a *flAsync) blend(roundID string, update FLUpdate) (blendResult, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	r, ok := a.rounds[roundID]
+	if !ok {
+		return blendResult{}, fmt.Errorf("round %s is not asynchronous", roundID)
+	}
+	res := blendResult{experimentID: r.experimentID}
+	if base, ok := fl.ModelVersion(update.BaseModelURI); ok && base < r.version {
+		res.staleness = r.version - base
+	}
+	res.alpha = fl.StalenessAlpha(r.alpha, res.staleness)
+	model, err := fl.AsyncBlend(r.model, update, res.alpha)
+	if err != nil {
+		return blendResult{}, fmt.Errorf("%w: %w", pkgerrors.ErrInvalidValue, err)
+	}
+	r.model = model
+	r.version++
+	r.updatedAt = time.Now()
+	res.version = r.version
+	res.model = model
+
+	return res, nil
+}
+
+// postAsyncUpdate applies a FedAsync update and publishes the new global
+// model.
+func (svc *service) postAsyncUpdate(ctx context.Context, update FLUpdate) error {
+	res, err := svc.flAsync.blend(update.RoundID, update)
+	if err != nil {
+		return err
+	}
+
+	modelURI := fmt.Sprintf("fl/models/global_model_v%d", res.version)
+	msg := map[string]any{
+		"round_id":          update.RoundID,
+		"new_model_version": res.version,
+		"model_uri":         modelURI,
+		"model":             res.model,
+	}
+	if err := svc.pubsub.Publish(ctx, svc.baseTopic+flModelTopic, msg); err != nil {
+		svc.logger.WarnContext(ctx, "failed to publish async global model",
+			"round_id", update.RoundID, "model_version", res.version, "error", err)
+	}
+
+	svc.logger.InfoContext(ctx, "blended async FL update",
+		"round_id", update.RoundID, "proplet_id", update.PropletID,
+		"model_version", res.version, "staleness", res.staleness, "alpha", res.alpha)
+
+	svc.recordAudit(ctx, audit.Entry{
+		Actor:      plugin.SystemUserID,
+		Action:     "blend",
+		EntityType: audit.EntityFLRound,
+		EntityID:   update.RoundID,
+		OldState:   "running",
+		NewState:   "running",
+		Metadata: map[string]string{
+			"experiment_id": res.experimentID,
+			"proplet_id":    update.PropletID,
+			"model_version": strconv.Itoa(res.version),
+			"model_uri":     modelURI,
+			"staleness":     strconv.Itoa(res.staleness),
+			"alpha":         strconv.FormatFloat(res.alpha, 'f', -1, 64),
+		},
+	})
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package manager
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/absmach/propeller/pkg/audit"
+	"github.com/absmach/propeller/pkg/plugin"
+)
+
+// flJobCompleteTopic is where the manager announces that the last round of
+// an FL job has been aggregated.
+const flJobCompleteTopic = "/control/manager/fl/complete"
+
+// FLJobComplete is published on flJobCompleteTopic once per FL job, after its
+// final round is aggregated. Metrics are the final round's client metrics.
+type FLJobComplete struct {
+	JobID        string             `json:"job_id"`
+	RoundID      string             `json:"round_id"`
+	TotalRounds  uint64             `json:"total_rounds"`
+	ModelVersion int                `json:"model_version"`
+	ModelURI     string             `json:"model_uri,omitempty"`
+	Metrics      map[string]float64 `json:"metrics,omitempty"`
+	CompletedAt  time.Time          `json:"completed_at"`
+}
+
+// roundPosition places a round within its job. A zero total means the job
+// has no fixed number of rounds, so none of its rounds is the last.
+type roundPosition struct {
+	round uint64
+	total uint64
+}
+
+func (p roundPosition) last() bool {
+	return p.total > 0 && p.round >= p.total
+}
+
+// flJobs remembers which FL jobs have recently completed so concurrent
+// aggregations of the last round publish the completion event once. Jobs are
+// forgotten after flStateTTL; from then on the fl_job_complete marker on the
+// job's tasks keeps the event from being published again.
+type flJobs struct {
+	mu   sync.Mutex
+	done map[string]time.Time
+}
+
+func newFLJobs() *flJobs {
+	return &flJobs{done: make(map[string]time.Time)}
+}
+
+// finish marks jobID complete and reports whether it was not already.
+func (j *flJobs) finish(jobID string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, ok := j.done[jobID]; ok {
+		return false
+	}
+	evictExpired(j.done, func(at time.Time) time.Time { return at })
+	j.done[jobID] = time.Now()
+
+	return true
+}
+
+// completeJob publishes the completion event of jobID, whose final round
+// roundID produced model version, and tags the job's tasks as complete. A job
+// whose tasks are already tagged, as after a restart, is not announced again.
+func (svc *service) completeJob(ctx context.Context, jobID, roundID string, pos roundPosition, version int, modelURI string) {
+	if jobID == "" || !svc.flJobs.finish(jobID) {
+		return
+	}
+
+	tasks, err := svc.listJobTasks(ctx, jobID)
+	if err != nil {
+		svc.logger.WarnContext(ctx, "failed to list tasks of completed FL job", "job_id", jobID, "error", err)
+	}
+	for _, t := range tasks {
+		if t.Metadata[jobCompleteMetadataKey] == "true" {
+			return
+		}
+	}
+
+	event := FLJobComplete{
+		JobID:        jobID,
+		RoundID:      roundID,
+		TotalRounds:  pos.total,
+		ModelVersion: version,
+		ModelURI:     modelURI,
+		CompletedAt:  time.Now().UTC(),
+	}
+	rounds, _ := svc.flMetrics.series(jobID)
+	for _, r := range rounds {
+		if r.RoundID == roundID {
+			event.Metrics = r.Metrics
+		}
+	}
+	if err := svc.pubsub.Publish(ctx, svc.baseTopic+flJobCompleteTopic, event); err != nil {
+		svc.logger.WarnContext(ctx, "failed to publish FL job completion",
+			"job_id", jobID, "round_id", roundID, "error", err)
+	}
+
+	for _, t := range tasks {
+		t.Metadata[jobCompleteMetadataKey] = "true"
+		if err := svc.taskRepo.Update(ctx, t); err != nil {
+			svc.logger.WarnContext(ctx, "failed to mark task of completed FL job",
+				"job_id", jobID, "task_id", t.ID, "error", err)
+		}
+	}
+
+	svc.logger.InfoContext(ctx, "FL job complete",
+		"job_id", jobID, "round_id", roundID, "total_rounds", pos.total, "model_version", version)
+	svc.recordAudit(ctx, audit.Entry{
+		Actor:      plugin.SystemUserID,
+		Action:     "complete-job",
+		EntityType: audit.EntityFLRound,
+		EntityID:   roundID,
+		OldState:   "aggregated",
+		NewState:   "job-complete",
+		Metadata: map[string]string{
+			"experiment_id": jobID,
+			"total_rounds":  strconv.FormatUint(pos.total, 10),
+			"model_version": strconv.Itoa(version),
+		},
+	})
+}
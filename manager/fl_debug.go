@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FLDebugState is a dump of the FL round state the manager holds in memory,
+// keyed by job. Rounds that received updates before they were configured are
+// listed under the empty job ID.
+type FLDebugState struct {
+	Jobs map[string][]FLRoundDebug `json:"jobs"`
+}
+
+// FLRoundDebug is the in-memory state of one round. Aggregated is set once
+// the round met its quorum and was handed off for aggregation.
+type FLRoundDebug struct {
+	RoundID       string        `json:"round_id"`
+	KOfN          int           `json:"k_of_n"`
+	QuorumPolicy  string        `json:"quorum_policy,omitempty"`
+	StartedAt     time.Time     `json:"started_at,omitzero"`
+	Expected      []string      `json:"expected,omitempty"`
+	Received      []string      `json:"received"`
+	Missing       []string      `json:"missing,omitempty"`
+	StaleRejected int           `json:"stale_rejected"`
+	GraceStarted  bool          `json:"grace_started"`
+	Aggregated    bool          `json:"aggregated"`
+	Async         *FLAsyncDebug `json:"async,omitempty"`
+}
+
+// FLAsyncDebug is the state of a FedAsync round's global model.
+type FLAsyncDebug struct {
+	Alpha        float64 `json:"alpha"`
+	ModelVersion int     `json:"model_version"`
+}
+
+// debugRound is a round's debug state together with the job it belongs to.
+type debugRound struct {
+	jobID string
+	round FLRoundDebug
+}
+
+func (p *flProgress) dump() map[string]debugRound {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make(map[string]debugRound, len(p.rounds))
+	for roundID, r := range p.rounds {
+		received := slices.Sorted(maps.Keys(r.received))
+		var missing []string
+		for _, propletID := range r.participants {
+			if _, ok := r.received[propletID]; !ok {
+				missing = append(missing, propletID)
+			}
+		}
+		out[roundID] = debugRound{jobID: r.experimentID, round: FLRoundDebug{
+			RoundID:       roundID,
+			KOfN:          r.quorum.K,
+			QuorumPolicy:  string(r.quorum.Policy),
+			StartedAt:     r.startTime,
+			Expected:      slices.Clone(r.participants),
+			Received:      received,
+			Missing:       missing,
+			StaleRejected: r.stale,
+			GraceStarted:  r.graceStarted,
+			Aggregated:    r.completed,
+		}}
+	}
+
+	return out
+}
+
+func (a *flAsync) dump() map[string]asyncRound {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	out := make(map[string]asyncRound, len(a.rounds))
+	for roundID, r := range a.rounds {
+		out[roundID] = asyncRound{experimentID: r.experimentID, alpha: r.alpha, version: r.version}
+	}
+
+	return out
+}
+
+func (svc *service) DebugFLState(_ context.Context) (FLDebugState, error) {
+	rounds := svc.flProgress.dump()
+	for roundID, r := range svc.flAsync.dump() {
+		d, ok := rounds[roundID]
+		if !ok {
+			d = debugRound{jobID: r.experimentID, round: FLRoundDebug{RoundID: roundID}}
+		}
+		d.round.Async = &FLAsyncDebug{Alpha: r.alpha, ModelVersion: r.version}
+		rounds[roundID] = d
+	}
+
+	state := FLDebugState{Jobs: make(map[string][]FLRoundDebug)}
+	for _, d := range rounds {
+		state.Jobs[d.jobID] = append(state.Jobs[d.jobID], d.round)
+	}
+	for _, jobRounds := range state.Jobs {
+		slices.SortFunc(jobRounds, func(a, b FLRoundDebug) int {
+			return strings.Compare(a.RoundID, b.RoundID)
+		})
+	}
+
+	return state, nil
+}
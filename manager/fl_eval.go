@@ -0,0 +1,192 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/absmach/propeller/pkg/audit"
+	"github.com/absmach/propeller/pkg/plugin"
+	"github.com/absmach/propeller/pkg/task"
+)
+
+// evalRoundMetadataKey marks an evaluation task with the round whose
+// aggregated model it evaluates.
+const evalRoundMetadataKey = "fl_eval_round_id"
+
+type evalConfig struct {
+	experimentID  string
+	propletID     string
+	datasetRef    string
+	taskWasmImage string
+	configuredAt  time.Time
+}
+
+type recordedEval struct {
+	values     map[string]float64
+	recordedAt time.Time
+}
+
+// flEvaluations tracks the optional evaluation phase of FL rounds: once a
+// round is aggregated its new global model is run in infer mode on a
+// designated evaluator proplet, and the metrics it returns are kept for the
+// round. Both are dropped after flStateTTL.
+type flEvaluations struct {
+	mu      sync.Mutex
+	configs map[string]evalConfig
+	metrics map[string]recordedEval
+}
+
+func newFLEvaluations() *flEvaluations {
+	return &flEvaluations{
+		configs: make(map[string]evalConfig),
+		metrics: make(map[string]recordedEval),
+	}
+}
+
+func (e *flEvaluations) configure(roundID string, cfg evalConfig) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	evictExpired(e.configs, func(c evalConfig) time.Time { return c.configuredAt })
+	cfg.configuredAt = time.Now()
+	e.configs[roundID] = cfg
+}
+
+// take returns and forgets the evaluation configured for roundID.
+func (e *flEvaluations) take(roundID string) (evalConfig, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cfg, ok := e.configs[roundID]
+	delete(e.configs, roundID)
+
+	return cfg, ok
+}
+
+func (e *flEvaluations) record(roundID string, metrics map[string]float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	evictExpired(e.metrics, func(m recordedEval) time.Time { return m.recordedAt })
+	e.metrics[roundID] = recordedEval{values: metrics, recordedAt: time.Now()}
+}
+
+func (e *flEvaluations) get(roundID string) map[string]float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return maps.Clone(e.metrics[roundID].values)
+}
+
+// startEvaluation dispatches the aggregated model of roundID to the round's
+// evaluator proplet, if one was configured.
+func (svc *service) startEvaluation(ctx context.Context, roundID, modelURI string, model any) {
+	cfg, ok := svc.flEvals.take(roundID)
+	if !ok {
+		return
+	}
+
+	t := task.Task{
+		Name:      fmt.Sprintf("fl-eval-%s", roundID),
+		Kind:      task.TaskKindStandard,
+		Mode:      task.ModeInfer,
+		State:     task.Pending,
+		ImageURL:  cfg.taskWasmImage,
+		PropletID: cfg.propletID,
+		CreatedAt: time.Now(),
+		Env: map[string]string{
+			"MODEL_URI":        modelURI,
+			"EVAL_DATASET_REF": cfg.datasetRef,
+		},
+		Metadata: task.Metadata{
+			evalRoundMetadataKey:  roundID,
+			experimentMetadataKey: cfg.experimentID,
+		},
+	}
+	if model != nil {
+		if data, err := json.Marshal(model); err == nil {
+			t.Env["MODEL_DATA"] = string(data)
+		}
+	}
+
+	created, err := svc.CreateTask(ctx, t)
+	if err != nil {
+		svc.logger.ErrorContext(ctx, "failed to create evaluation task", "round_id", roundID, "error", err)
+
+		return
+	}
+	if err := svc.StartTask(ctx, created.ID); err != nil {
+		svc.logger.ErrorContext(ctx, "failed to start evaluation task",
+			"round_id", roundID, "proplet_id", cfg.propletID, "task_id", created.ID, "error", err)
+
+		return
+	}
+
+	svc.logger.InfoContext(ctx, "dispatched aggregated model for evaluation",
+		"round_id", roundID, "proplet_id", cfg.propletID, "task_id", created.ID)
+}
+
+// recordEvaluation stores the metrics an evaluator proplet returned for the
+// round it evaluated.
+func (svc *service) recordEvaluation(ctx context.Context, t task.Task) {
+	roundID, _ := t.Metadata[evalRoundMetadataKey].(string)
+	if roundID == "" || t.State != task.Completed {
+		return
+	}
+
+	metrics, err := evalMetrics(t.Results)
+	if err != nil {
+		svc.logger.WarnContext(ctx, "ignoring unparseable evaluation results", "round_id", roundID, "task_id", t.ID, "error", err)
+
+		return
+	}
+	svc.flEvals.record(roundID, metrics)
+
+	experimentID, _ := t.Metadata[experimentMetadataKey].(string)
+	meta := map[string]string{
+		"experiment_id": experimentID,
+		"proplet_id":    t.PropletID,
+	}
+	for name, value := range metrics {
+		meta["eval_"+name] = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	svc.recordAudit(ctx, audit.Entry{
+		Actor:      plugin.SystemUserID,
+		Action:     "evaluate",
+		EntityType: audit.EntityFLRound,
+		EntityID:   roundID,
+		OldState:   "aggregated",
+		NewState:   "evaluated",
+		Metadata:   meta,
+	})
+}
+
+// evalMetrics extracts the numeric metrics from evaluation results, which
+// are either a JSON object or a string holding one.
+func evalMetrics(results any) (map[string]float64, error) {
+	if s, ok := results.(string); ok {
+		var decoded any
+		if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+			return nil, err
+		}
+		results = decoded
+	}
+	obj, ok := results.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object of metrics, got %T", results)
+	}
+
+	metrics := make(map[string]float64, len(obj))
+	for name, value := range obj {
+		if v, ok := value.(float64); ok {
+			metrics[name] = v
+		}
+	}
+
+	return metrics, nil
+}
@@ -0,0 +1,146 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+)
+
+type metricsAccumulator struct {
+	experimentID string
+	updates      int
+	sums         map[string]float64
+	weights      map[string]float64
+}
+
+// flJobMetrics builds a per-experiment time series of round metrics. The
+// metrics clients report with their updates are averaged, weighted by sample
+// count, while a round runs and the result is appended to the experiment's
+// series when the round is aggregated.
+type flJobMetrics struct {
+	mu      sync.Mutex
+	pending map[string]*metricsAccumulator
+	jobs    map[string][]RoundMetrics
+}
+
+func newFLJobMetrics() *flJobMetrics {
+	return &flJobMetrics{
+		pending: make(map[string]*metricsAccumulator),
+		jobs:    make(map[string][]RoundMetrics),
+	}
+}
+
+func (m *flJobMetrics) configure(roundID, experimentID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pending[roundID] = &metricsAccumulator{
+		experimentID: experimentID,
+		sums:         make(map[string]float64),
+		weights:      make(map[string]float64),
+	}
+}
+
+func (m *flJobMetrics) add(update FLUpdate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc, ok := m.pending[update.RoundID]
+	if !ok {
+		return
+	}
+	acc.updates++
+	weight := float64(max(update.NumSamples, 1))
+	for name, value := range update.Metrics {
+		v, ok := value.(float64)
+		if !ok {
+			continue
+		}
+		acc.sums[name] += v * weight
+		acc.weights[name] += weight
+	}
+}
+
+// complete closes roundID and appends its averaged metrics to its
+// experiment's series.
+func (m *flJobMetrics) complete(roundID string, modelVersion int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc, ok := m.pending[roundID]
+	if !ok {
+		return
+	}
+	delete(m.pending, roundID)
+
+	round := RoundMetrics{
+		RoundID:      roundID,
+		ModelVersion: modelVersion,
+		NumUpdates:   acc.updates,
+		CompletedAt:  time.Now(),
+	}
+	if len(acc.sums) > 0 {
+		round.Metrics = make(map[string]float64, len(acc.sums))
+		for name, sum := range acc.sums {
+			round.Metrics[name] = sum / acc.weights[name]
+		}
+	}
+	m.jobs[acc.experimentID] = append(m.jobs[acc.experimentID], round)
+}
+
+func (m *flJobMetrics) series(experimentID string) ([]RoundMetrics, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rounds, ok := m.jobs[experimentID]
+	if !ok {
+		return nil, false
+	}
+	out := make([]RoundMetrics, len(rounds))
+	for i, r := range rounds {
+		out[i] = r
+		out[i].Metrics = maps.Clone(r.Metrics)
+	}
+
+	return out, true
+}
+
+// restore replaces the metric series of experimentID, as when importing a
+// job exported from another manager.
+func (m *flJobMetrics) restore(experimentID string, rounds []RoundMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(rounds) == 0 {
+		delete(m.jobs, experimentID)
+
+		return
+	}
+	out := make([]RoundMetrics, len(rounds))
+	for i, r := range rounds {
+		out[i] = r
+		out[i].Metrics = maps.Clone(r.Metrics)
+		out[i].EvalMetrics = nil
+	}
+	m.jobs[experimentID] = out
+}
+
+func (svc *service) GetFLJobMetrics(_ context.Context, jobID string) (FLJobMetrics, error) {
+	if jobID == "" {
+		return FLJobMetrics{}, pkgerrors.ErrInvalidData
+	}
+
+	rounds, ok := svc.flMetrics.series(jobID)
+	if !ok {
+		return FLJobMetrics{}, fmt.Errorf("%w: no completed rounds for job %s", pkgerrors.ErrNotFound, jobID)
+	}
+	for i := range rounds {
+		rounds[i].EvalMetrics = svc.flEvals.get(rounds[i].RoundID)
+	}
+
+	return FLJobMetrics{JobID: jobID, Rounds: rounds}, nil
+}
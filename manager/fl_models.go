@@ -0,0 +1,160 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/absmach/propeller/pkg/audit"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/fl"
+)
+
+// ModelsConfig configures where aggregated global models are kept.
+type ModelsConfig struct {
+	// Dir is the directory aggregated global models are persisted in.
+	// Models are kept in memory only when it is empty.
+	Dir string `env:"MANAGER_FL_MODELS_DIR"`
+	// MaxVersions bounds how many global model versions are kept, besides
+	// v0 and the current one. Zero keeps every version.
+	MaxVersions int `env:"MANAGER_FL_MAX_MODEL_VERSIONS" envDefault:"0"`
+}
+
+func newModelRegistry(cfg ModelsConfig, logger *slog.Logger) *fl.ModelRegistry {
+	maxVersions := max(cfg.MaxVersions, 0)
+
+	var store *fl.PersistentStorage
+	if dir := strings.TrimSpace(cfg.Dir); dir != "" {
+		ps, err := fl.NewPersistentStorage(filepath.Join(dir, "rounds"), filepath.Join(dir, "models"))
+		if err != nil {
+			logger.Warn("failed to open FL model storage, keeping models in memory", "dir", dir, "error", err)
+		} else {
+			store = ps
+		}
+	}
+
+	models, err := fl.NewModelRegistry(store, maxVersions)
+	if err != nil {
+		logger.Warn("failed to load stored FL models, starting empty", "error", err)
+		models, _ = fl.NewModelRegistry(nil, maxVersions)
+	}
+
+	return models
+}
+
+// storeModel records an aggregated global model in the registry.
+func (svc *service) storeModel(ctx context.Context, version int, roundID string, model any) {
+	data, ok := model.(map[string]any)
+	if !ok {
+		return
+	}
+	if err := svc.models.Store(version, fl.Model{Data: data, Metadata: map[string]any{"round_id": roundID}}); err != nil {
+		svc.logger.WarnContext(ctx, "failed to store aggregated model",
+			"round_id", roundID, "model_version", version, "error", err)
+	}
+}
+
+// currentModelRef returns the registry reference of the current global
+// model, or false while no model has been stored.
+func (svc *service) currentModelRef() (string, bool) {
+	if len(svc.models.List()) == 0 {
+		return "", false
+	}
+
+	return modelRef(svc.models.Current()), true
+}
+
+func modelRef(version int) string {
+	return fmt.Sprintf("fl/models/global_model_v%d", version)
+}
+
+// injectGlobalModel returns env with the current global model set as
+// MODEL_DATA and its reference as MODEL_URI, so an infer task runs against
+// the latest aggregate. A task that already names its model, or a registry
+// without models, leaves env unchanged.
+func (svc *service) injectGlobalModel(ctx context.Context, env map[string]string) map[string]string {
+	if env["MODEL_DATA"] != "" || env["MODEL_URI"] != "" {
+		return env
+	}
+	version := svc.models.Current()
+	model, err := svc.models.Get(version)
+	if err != nil {
+		return env
+	}
+	data, err := json.Marshal(model.Data)
+	if err != nil {
+		svc.logger.WarnContext(ctx, "failed to encode global model", "model_version", version, "error", err)
+
+		return env
+	}
+
+	out := make(map[string]string, len(env)+2)
+	maps.Copy(out, env)
+	out["MODEL_URI"] = modelRef(version)
+	out["MODEL_DATA"] = string(data)
+
+	return out
+}
+
+func (svc *service) RollbackModel(ctx context.Context, version int) error {
+	from := svc.models.Current()
+	if err := svc.models.SetCurrent(version); err != nil {
+		if errors.Is(err, fl.ErrModelNotFound) {
+			return fmt.Errorf("%w: model version %d", pkgerrors.ErrNotFound, version)
+		}
+
+		return err
+	}
+
+	svc.recordAudit(ctx, audit.Entry{
+		Action:     "rollback",
+		EntityType: audit.EntityFLModel,
+		EntityID:   "global_model",
+		OldState:   "v" + strconv.Itoa(from),
+		NewState:   "v" + strconv.Itoa(version),
+	})
+
+	return nil
+}
+
+func (svc *service) StoreModel(ctx context.Context, version int, model Model) error {
+	if version < 0 || model.Data == nil {
+		return pkgerrors.ErrInvalidValue
+	}
+
+	if err := svc.models.Add(version, model); err != nil {
+		if errors.Is(err, fl.ErrModelExists) {
+			return fmt.Errorf("%w: model version %d", pkgerrors.ErrConflict, version)
+		}
+
+		return err
+	}
+
+	svc.recordAudit(ctx, audit.Entry{
+		Action:     "store",
+		EntityType: audit.EntityFLModel,
+		EntityID:   "global_model",
+		NewState:   "v" + strconv.Itoa(version),
+	})
+
+	return nil
+}
+
+func (svc *service) GetModel(_ context.Context, version int) (Model, error) {
+	model, err := svc.models.Get(version)
+	if err != nil {
+		if errors.Is(err, fl.ErrModelNotFound) {
+			return Model{}, fmt.Errorf("%w: model version %d", pkgerrors.ErrNotFound, version)
+		}
+
+		return Model{}, err
+	}
+
+	return model, nil
+}
@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/absmach/propeller/pkg/audit"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+)
+
+// personalizedModels holds the fine-tuned model of each FL client, kept next
+// to the shared global models for personalized federated learning.
+type personalizedModels struct {
+	mu     sync.RWMutex
+	models map[string]Model
+}
+
+func newPersonalizedModels() *personalizedModels {
+	return &personalizedModels{models: make(map[string]Model)}
+}
+
+func (p *personalizedModels) store(clientID string, model Model) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.models[clientID] = model
+}
+
+func (p *personalizedModels) get(clientID string) (Model, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	model, ok := p.models[clientID]
+
+	return model, ok
+}
+
+func (svc *service) StorePersonalizedModel(ctx context.Context, clientID string, model Model) error {
+	clientID = strings.TrimSpace(clientID)
+	if clientID == "" || model.Data == nil {
+		return pkgerrors.ErrInvalidValue
+	}
+
+	svc.personalized.store(clientID, model)
+
+	svc.recordAudit(ctx, audit.Entry{
+		Action:     "store",
+		EntityType: audit.EntityFLModel,
+		EntityID:   "personalized_model/" + clientID,
+	})
+
+	return nil
+}
+
+func (svc *service) GetPersonalizedModel(_ context.Context, clientID string) (PersonalizedModel, error) {
+	clientID = strings.TrimSpace(clientID)
+	model, ok := svc.personalized.get(clientID)
+	if !ok {
+		return PersonalizedModel{}, fmt.Errorf("%w: personalized model for client %s", pkgerrors.ErrNotFound, clientID)
+	}
+
+	pm := PersonalizedModel{ClientID: clientID, Personalized: model}
+	if len(svc.models.List()) > 0 {
+		version := svc.models.Current()
+		if global, err := svc.models.Get(version); err == nil {
+			pm.GlobalVersion = version
+			pm.Global = &global
+		}
+	}
+
+	return pm, nil
+}
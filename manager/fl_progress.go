@@ -0,0 +1,496 @@
+package manager
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/absmach/propeller/pkg/audit"
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/absmach/propeller/pkg/plugin"
+	"github.com/absmach/propeller/pkg/task"
+)
+
+// flRoundNextTopic is where coordinators announce that a round's updates
+// were aggregated into a new global model.
+const flRoundNextTopic = "fl/rounds/next"
+
+// flStateTTL bounds how long FL round and job state is kept in memory once
+// nothing touches it, so rounds the coordinator never closes do not pile up.
+const flStateTTL = 24 * time.Hour
+
+// evictExpired drops the entries of m last touched more than flStateTTL ago.
+// Trackers call it whenever they add an entry.
+func evictExpired[K comparable, V any](m map[K]V, touched func(V) time.Time) {
+	cutoff := time.Now().Add(-flStateTTL)
+	maps.DeleteFunc(m, func(_ K, v V) bool {
+		return touched(v).Before(cutoff)
+	})
+}
+
+type roundProgress struct {
+	experimentID string
+	quorum       fl.Quorum
+	startTime    time.Time
+	maxAge       time.Duration
+	grace        time.Duration
+	expected     int
+	participants []string
+	position     roundPosition
+	graceStarted bool
+	received     map[string]struct{}
+	stale        int
+	completed    bool
+}
+
+func roundStarted(r *roundProgress) time.Time {
+	return r.startTime
+}
+
+func (r *roundProgress) snapshot() roundSnapshot {
+	return roundSnapshot{
+		experimentID: r.experimentID,
+		position:     r.position,
+		kOfN:         r.quorum.K,
+		policy:       r.quorum.Policy,
+		received:     len(r.received),
+		stale:        r.stale,
+		completed:    r.completed,
+	}
+}
+
+// flProgress counts the updates each FL round has received so progress can
+// be streamed without polling the coordinator. It only lives in memory, and a
+// round is dropped once aggregated or after flStateTTL.
+type flProgress struct {
+	mu     sync.Mutex
+	rounds map[string]*roundProgress
+}
+
+func newFLProgress() *flProgress {
+	return &flProgress{rounds: make(map[string]*roundProgress)}
+}
+
+// configure starts tracking a round. With a grace period the round completes
+// that long after its quorum is met, or as soon as all expected participants
+// have reported, so stragglers are still aggregated.
+func (p *flProgress) configure(roundID, experimentID string, quorum fl.Quorum, startTime time.Time, maxAge, grace time.Duration, participants []string, pos roundPosition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	evictExpired(p.rounds, roundStarted)
+	p.rounds[roundID] = &roundProgress{
+		experimentID: experimentID,
+		quorum:       quorum,
+		startTime:    startTime,
+		maxAge:       maxAge,
+		grace:        grace,
+		expected:     len(participants),
+		participants: slices.Clone(participants),
+		position:     pos,
+		received:     make(map[string]struct{}),
+	}
+}
+
+// roundSnapshot is a copy of a round's progress taken under the lock.
+type roundSnapshot struct {
+	experimentID string
+	position     roundPosition
+	kOfN         int
+	policy       fl.QuorumPolicy
+	received     int
+	stale        int
+	completed    bool
+}
+
+func (s roundSnapshot) metadata() map[string]string {
+	meta := map[string]string{
+		"experiment_id":  s.experimentID,
+		"k_of_n":         strconv.Itoa(s.kOfN),
+		"quorum_policy":  string(s.policy),
+		"received":       strconv.Itoa(s.received),
+		"stale_rejected": strconv.Itoa(s.stale),
+	}
+	if s.position.total > 0 {
+		meta["round"] = strconv.FormatUint(s.position.round, 10)
+		meta["total_rounds"] = strconv.FormatUint(s.position.total, 10)
+	}
+
+	return meta
+}
+
+// receive records an update from propletID. It reports whether the count
+// changed and whether this update is the one that completed the round.
+// Updates for rounds that are not tracked are ignored. A
+// non-zero grace is returned when this update met the quorum of a round that
+// waits for stragglers; the caller completes it with expireGrace.
+func (p *flProgress) receive(roundID, propletID string) (snap roundSnapshot, changed, completed bool, grace time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.rounds[roundID]
+	if !ok {
+		return roundSnapshot{}, false, false, 0
+	}
+	if _, seen := r.received[propletID]; !seen {
+		r.received[propletID] = struct{}{}
+		changed = true
+	}
+	if changed && !r.completed && r.quorum.Met(slices.Collect(maps.Keys(r.received))) {
+		switch {
+		case r.grace > 0 && len(r.received) < r.expected:
+			if !r.graceStarted {
+				r.graceStarted = true
+				grace = r.grace
+			}
+		default:
+			r.completed = true
+			completed = true
+		}
+	}
+
+	return r.snapshot(), changed, completed, grace
+}
+
+// expireGrace completes a round whose grace period ran out. It reports false
+// when the round already completed or is no longer tracked.
+func (p *flProgress) expireGrace(roundID string) (roundSnapshot, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.rounds[roundID]
+	if !ok || r.completed {
+		return roundSnapshot{}, false
+	}
+	r.completed = true
+
+	return r.snapshot(), true
+}
+
+// rejectStale reports whether an update received at receivedAt falls outside
+// the round's staleness window, counting it if so. Rounds without a window,
+// or not tracked at all, accept every update.
+func (p *flProgress) rejectStale(roundID string, receivedAt time.Time) (roundSnapshot, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.rounds[roundID]
+	if !ok || r.maxAge <= 0 || receivedAt.Sub(r.startTime) <= r.maxAge {
+		return roundSnapshot{}, false
+	}
+	r.stale++
+
+	return r.snapshot(), true
+}
+
+// restore seeds a round recovered from storage. Rounds already tracked are
+// left alone.
+func (p *flProgress) restore(roundID, experimentID string, quorum fl.Quorum, startTime time.Time, maxAge, grace time.Duration, participants, received []string, pos roundPosition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.rounds[roundID]; ok {
+		return
+	}
+	evictExpired(p.rounds, roundStarted)
+	r := &roundProgress{
+		experimentID: experimentID,
+		quorum:       quorum,
+		startTime:    startTime,
+		maxAge:       maxAge,
+		grace:        grace,
+		expected:     len(participants),
+		participants: participants,
+		position:     pos,
+		received:     make(map[string]struct{}, len(received)),
+	}
+	for _, propletID := range received {
+		r.received[propletID] = struct{}{}
+	}
+	p.rounds[roundID] = r
+}
+
+// finish drops a round once the coordinator has aggregated it and returns its
+// final progress. The coordinator owns the decision to aggregate, so a round
+// it closed on timeout short of its quorum is dropped too and reported as not
+// completed.
+func (p *flProgress) finish(roundID string) (roundSnapshot, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.rounds[roundID]
+	if !ok {
+		return roundSnapshot{}, false
+	}
+	delete(p.rounds, roundID)
+
+	return r.snapshot(), true
+}
+
+// recordRoundUpdate counts an update from propletID towards roundID and emits
+// "update" and, once k-of-n is reached, "complete" round events.
+func (svc *service) recordRoundUpdate(ctx context.Context, roundID, propletID string) {
+	if roundID == "" || propletID == "" {
+		return
+	}
+
+	snap, changed, completed, grace := svc.flProgress.receive(roundID, propletID)
+	if !changed {
+		return
+	}
+
+	meta := snap.metadata()
+	meta["proplet_id"] = propletID
+	svc.recordAudit(ctx, audit.Entry{
+		Actor:      "proplet:" + propletID,
+		Action:     "update",
+		EntityType: audit.EntityFLRound,
+		EntityID:   roundID,
+		OldState:   "running",
+		NewState:   "running",
+		Metadata:   meta,
+	})
+
+	if grace > 0 {
+		svc.logger.InfoContext(ctx, "FL round quorum met, waiting for stragglers",
+			"round_id", roundID, "received", snap.received, "grace_period", grace)
+		detached := context.WithoutCancel(ctx)
+		time.AfterFunc(grace, func() {
+			if snap, ok := svc.flProgress.expireGrace(roundID); ok {
+				svc.completeRound(detached, roundID, snap)
+			}
+		})
+	}
+
+	if completed {
+		svc.completeRound(ctx, roundID, snap)
+	}
+}
+
+func (svc *service) completeRound(ctx context.Context, roundID string, snap roundSnapshot) {
+	svc.recordAudit(ctx, audit.Entry{
+		Actor:      plugin.SystemUserID,
+		Action:     "complete",
+		EntityType: audit.EntityFLRound,
+		EntityID:   roundID,
+		OldState:   "running",
+		NewState:   "completed",
+		Metadata:   snap.metadata(),
+	})
+}
+
+// rejectStaleUpdate drops an update from propletID that arrived after the
+// round's staleness window and records the rejection.
+func (svc *service) rejectStaleUpdate(ctx context.Context, roundID, propletID string, receivedAt time.Time) bool {
+	snap, stale := svc.flProgress.rejectStale(roundID, receivedAt)
+	if !stale {
+		return false
+	}
+
+	svc.logger.WarnContext(ctx, "rejected stale FL update",
+		"round_id", roundID, "proplet_id", propletID, "stale_rejected", snap.stale)
+	meta := snap.metadata()
+	meta["proplet_id"] = propletID
+	svc.recordAudit(ctx, audit.Entry{
+		Actor:      "proplet:" + propletID,
+		Action:     "reject-stale",
+		EntityType: audit.EntityFLRound,
+		EntityID:   roundID,
+		Metadata:   meta,
+	})
+
+	return true
+}
+
+func (svc *service) handleRoundNext(ctx context.Context) func(topic string, msg map[string]any) error {
+	return func(_ string, msg map[string]any) error {
+		roundID, _ := msg["round_id"].(string)
+		if roundID == "" {
+			return nil
+		}
+
+		snap, ok := svc.flProgress.finish(roundID)
+		if !ok {
+			return nil
+		}
+		meta := snap.metadata()
+		if !snap.completed {
+			svc.logger.WarnContext(ctx, "coordinator aggregated round before its quorum was met",
+				"round_id", roundID, "quorum_policy", snap.policy, "received", snap.received)
+			meta["quorum_met"] = "false"
+			svc.completeRound(ctx, roundID, snap)
+		}
+		version, hasVersion := msg["new_model_version"].(float64)
+		if hasVersion {
+			meta["model_version"] = strconv.FormatFloat(version, 'f', -1, 64)
+		}
+		svc.flMetrics.complete(roundID, int(version))
+		model, hasModel := msg["model"]
+		if hasVersion && hasModel {
+			svc.storeModel(ctx, int(version), roundID, model)
+		}
+		if uri, ok := msg["model_uri"].(string); ok {
+			meta["model_uri"] = uri
+		}
+		if hasModel && svc.checkpoints != nil {
+			ref, err := svc.checkpoints.export(ctx, roundID, model)
+			if err != nil {
+				svc.logger.WarnContext(ctx, "failed to export aggregated model checkpoint",
+					"round_id", roundID, "error", err)
+			} else {
+				meta["aggregated_model_ref"] = ref
+			}
+		}
+		svc.recordAudit(ctx, audit.Entry{
+			Actor:      plugin.SystemUserID,
+			Action:     "aggregate",
+			EntityType: audit.EntityFLRound,
+			EntityID:   roundID,
+			OldState:   "completed",
+			NewState:   "aggregated",
+			Metadata:   meta,
+		})
+		svc.startEvaluation(ctx, roundID, meta["model_uri"], msg["model"])
+		if snap.position.last() {
+			svc.completeJob(ctx, snap.experimentID, roundID, snap.position, int(version), meta["model_uri"])
+		}
+
+		return nil
+	}
+}
+
+// recoverRounds rebuilds round progress from persisted round tasks so that a
+// round still in flight when the manager went down completes once its
+// remaining updates arrive. Rounds without an active task are left alone.
+// The staleness window runs from the round's persisted start time, or from
+// its earliest task for rounds launched before that was recorded.
+func (svc *service) recoverRounds(ctx context.Context, tasks []task.Task) {
+	type recovered struct {
+		experimentID string
+		quorum       fl.Quorum
+		startTime    time.Time
+		persisted    bool
+		maxAge       time.Duration
+		grace        time.Duration
+		position     roundPosition
+		participants map[string]struct{}
+		received     []string
+		active       bool
+	}
+
+	rounds := make(map[string]*recovered)
+	for i := range tasks {
+		t := &tasks[i]
+		roundID, _ := t.Metadata[roundMetadataKey].(string)
+		if roundID == "" {
+			continue
+		}
+		r, ok := rounds[roundID]
+		if !ok {
+			r = &recovered{participants: make(map[string]struct{})}
+			r.experimentID, _ = t.Metadata[experimentMetadataKey].(string)
+			r.quorum = quorumFromMetadata(t.Metadata)
+			if v, ok := t.Metadata[maxAgeMetadataKey].(string); ok {
+				seconds, _ := strconv.Atoi(v)
+				r.maxAge = time.Duration(seconds) * time.Second
+			}
+			if v, ok := t.Metadata[graceMetadataKey].(string); ok {
+				seconds, _ := strconv.Atoi(v)
+				r.grace = time.Duration(seconds) * time.Second
+			}
+			r.position = positionFromMetadata(t.Metadata)
+			if v, ok := t.Metadata[roundStartedMetadataKey].(string); ok {
+				r.startTime, _ = time.Parse(time.RFC3339Nano, v)
+				r.persisted = !r.startTime.IsZero()
+			}
+			rounds[roundID] = r
+		}
+		if !r.persisted && (r.startTime.IsZero() || t.CreatedAt.Before(r.startTime)) {
+			r.startTime = t.CreatedAt
+		}
+		r.participants[t.PropletID] = struct{}{}
+		switch {
+		case t.State == task.Completed:
+			r.received = append(r.received, t.PropletID)
+		case !t.State.IsTerminal():
+			r.active = true
+		}
+	}
+
+	for roundID, r := range rounds {
+		r.quorum = defaultQuorumK(r.quorum, len(r.participants))
+		if !r.active || r.quorum.Met(r.received) {
+			continue
+		}
+
+		svc.flProgress.restore(roundID, r.experimentID, r.quorum, r.startTime, r.maxAge, r.grace,
+			slices.Sorted(maps.Keys(r.participants)), r.received, r.position)
+		svc.logger.InfoContext(ctx, "recovered in-flight FL round",
+			"round_id", roundID, "participants", len(r.participants), "received", len(r.received),
+			"k_of_n", r.quorum.K, "quorum_policy", r.quorum.Policy)
+	}
+}
+
+// defaultQuorumK makes an any-k round without a K wait for all of its
+// participants, as a K of zero would never complete it.
+func defaultQuorumK(quorum fl.Quorum, participants int) fl.Quorum {
+	if (quorum.Policy == "" || quorum.Policy == fl.QuorumAnyK) && quorum.K <= 0 {
+		quorum.K = participants
+	}
+
+	return quorum
+}
+
+// quorumMetadata and quorumFromMetadata persist a round's quorum on its tasks
+// so it survives a manager restart.
+func quorumMetadata(quorum fl.Quorum) task.Metadata {
+	return task.Metadata{
+		kOfNMetadataKey:     strconv.Itoa(quorum.K),
+		quorumMetadataKey:   string(quorum.Policy),
+		requiredMetadataKey: strings.Join(quorum.Required, ","),
+	}
+}
+
+// positionMetadata and positionFromMetadata do the same for the round's
+// position within its job.
+func positionMetadata(pos roundPosition) task.Metadata {
+	if pos.total == 0 {
+		return task.Metadata{}
+	}
+
+	return task.Metadata{
+		roundNumberMetadataKey: strconv.FormatUint(pos.round, 10),
+		totalRoundsMetadataKey: strconv.FormatUint(pos.total, 10),
+	}
+}
+
+func positionFromMetadata(meta task.Metadata) roundPosition {
+	var pos roundPosition
+	if v, ok := meta[roundNumberMetadataKey].(string); ok {
+		pos.round, _ = strconv.ParseUint(v, 10, 64)
+	}
+	if v, ok := meta[totalRoundsMetadataKey].(string); ok {
+		pos.total, _ = strconv.ParseUint(v, 10, 64)
+	}
+
+	return pos
+}
+
+func quorumFromMetadata(meta task.Metadata) fl.Quorum {
+	quorum := fl.Quorum{Policy: fl.QuorumAnyK}
+	if v, ok := meta[kOfNMetadataKey].(string); ok {
+		quorum.K, _ = strconv.Atoi(v)
+	}
+	if v, ok := meta[quorumMetadataKey].(string); ok && v != "" {
+		quorum.Policy = fl.QuorumPolicy(v)
+	}
+	if v, ok := meta[requiredMetadataKey].(string); ok && v != "" {
+		quorum.Required = strings.Split(v, ",")
+	}
+
+	return quorum
+}
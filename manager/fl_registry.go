@@ -0,0 +1,140 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/absmach/propeller/proxy"
+)
+
+const (
+	checkpointArtifactType = "application/vnd.propeller.fl.model.v1"
+	checkpointMediaType    = "application/vnd.propeller.fl.model.v1+json"
+	ociScheme              = "oci://"
+)
+
+// RegistryConfig authenticates against the OCI registries FL models are
+// exported to and imported from.
+type RegistryConfig struct {
+	// Username and Password are used when both are set; Token is used
+	// instead otherwise.
+	Username string `env:"MANAGER_FL_REGISTRY_USERNAME"`
+	Password string `env:"MANAGER_FL_REGISTRY_PASSWORD"`
+	Token    string `env:"MANAGER_FL_REGISTRY_TOKEN"`
+	// PlainHTTP talks to the registries over HTTP.
+	PlainHTTP bool `env:"MANAGER_FL_REGISTRY_PLAIN_HTTP"`
+}
+
+var invalidTagChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// client returns the proxy registry client configuration for the repository
+// at ref, so FL model transfers reuse the proxy's authentication.
+func (c RegistryConfig) client(ref string) proxy.HTTPProxyConfig {
+	cfg := proxy.HTTPProxyConfig{
+		Username:    c.Username,
+		Password:    c.Password,
+		Token:       c.Token,
+		RegistryURL: strings.SplitN(ref, "/", 2)[0],
+		PlainHTTP:   c.PlainHTTP,
+	}
+	cfg.Authenticate = (cfg.Username != "" && cfg.Password != "") || cfg.Token != ""
+
+	return cfg
+}
+
+// checkpointer exports each round's aggregated model to an OCI registry so
+// it outlives the coordinator.
+type checkpointer struct {
+	repository string
+	registry   proxy.HTTPProxyConfig
+}
+
+func newCheckpointer(repository string, registry RegistryConfig) *checkpointer {
+	repository = strings.TrimSpace(repository)
+	if repository == "" {
+		return nil
+	}
+
+	return &checkpointer{repository: repository, registry: registry.client(repository)}
+}
+
+// export pushes model as the round's aggregated checkpoint and returns its
+// digest-pinned OCI reference.
+func (c *checkpointer) export(ctx context.Context, roundID string, model any) (string, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return "", err
+	}
+	tag := invalidTagChars.ReplaceAllString(roundID, "-") + "-aggregated"
+
+	ref, err := c.registry.PushToReg(ctx, c.repository, tag, checkpointArtifactType, checkpointMediaType, data)
+	if err != nil {
+		return "", err
+	}
+
+	return ociScheme + ref, nil
+}
+
+// pullModel fetches the JSON model stored at an oci:// model URI, such as a
+// pretrained starting model or an exported checkpoint. It returns an empty
+// string for model URIs that are not OCI references; proplets fetch those
+// from the model registry themselves.
+func pullModel(ctx context.Context, registry RegistryConfig, modelURI string) (string, error) {
+	ref, ok := strings.CutPrefix(modelURI, ociScheme)
+	if !ok {
+		return "", nil
+	}
+
+	client := registry.client(ref)
+	data, err := client.PullFromReg(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if !json.Valid(data) {
+		return "", fmt.Errorf("model %s is not valid JSON", modelURI)
+	}
+
+	return string(data), nil
+}
+
+type startingModelKey struct{}
+
+type startingModel struct {
+	uri  string
+	data string
+}
+
+// withStartingModel hands the starting model a round start pulled to the
+// start commands of the round's tasks, so it is pulled once per round.
+func withStartingModel(ctx context.Context, modelURI, data string) context.Context {
+	return context.WithValue(ctx, startingModelKey{}, startingModel{uri: modelURI, data: data})
+}
+
+// injectStartingModel returns env with the model at its oci:// MODEL_URI set
+// as MODEL_DATA. Round tasks only store the reference; the model itself is
+// added to the start command, from ctx when the round start pulled it and
+// from the registry otherwise.
+func (svc *service) injectStartingModel(ctx context.Context, env map[string]string) (map[string]string, error) {
+	modelURI := env["MODEL_URI"]
+	if env["MODEL_DATA"] != "" || !strings.HasPrefix(modelURI, ociScheme) {
+		return env, nil
+	}
+	model, ok := ctx.Value(startingModelKey{}).(startingModel)
+	if !ok || model.uri != modelURI {
+		data, err := pullModel(ctx, svc.registry, modelURI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pull starting model %s: %w", modelURI, err)
+		}
+		model.data = data
+	}
+
+	out := make(map[string]string, len(env)+1)
+	maps.Copy(out, env)
+	out["MODEL_DATA"] = model.data
+
+	return out, nil
+}
@@ -6,10 +6,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/absmach/propeller/pkg/audit"
 	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/absmach/propeller/pkg/task"
 	"github.com/fxamacker/cbor/v2"
 )
 
@@ -27,6 +33,30 @@ func (svc *service) ConfigureExperiment(ctx context.Context, config ExperimentCo
 	if config.ModelRef == "" {
 		return errors.New("model_ref is required")
 	}
+	if err := config.spec().Validate(); err != nil {
+		return fmt.Errorf("%w: %w", pkgerrors.ErrInvalidValue, err)
+	}
+	quorum := config.quorum()
+	if config.MaxUpdateAgeS < 0 {
+		return fmt.Errorf("%w: negative max_update_age_s", pkgerrors.ErrInvalidValue)
+	}
+	if config.AggregationGracePeriodS < 0 {
+		return fmt.Errorf("%w: negative aggregation_grace_period_s", pkgerrors.ErrInvalidValue)
+	}
+	if config.EvalDatasetRef != "" && config.EvaluatorProplet == "" {
+		return fmt.Errorf("%w: eval_dataset_ref requires evaluator_proplet", pkgerrors.ErrInvalidValue)
+	}
+	if config.AsyncAlpha < 0 || config.AsyncAlpha > 1 {
+		return fmt.Errorf("%w: async_alpha must be within [0, 1]", pkgerrors.ErrInvalidValue)
+	}
+	if config.TotalRounds > 0 {
+		switch {
+		case config.ExperimentID == "":
+			return fmt.Errorf("%w: total_rounds requires experiment_id", pkgerrors.ErrInvalidValue)
+		case config.Round == 0 || config.Round > config.TotalRounds:
+			return fmt.Errorf("%w: round must be within [1, %d]", pkgerrors.ErrInvalidValue, config.TotalRounds)
+		}
+	}
 
 	if svc.flCoordinatorURL == "" || svc.httpClient == nil {
 		return errors.New("MANAGER_COORDINATOR_URL must be configured for HTTP-based FL coordination")
@@ -53,16 +83,65 @@ func (svc *service) ConfigureExperiment(ctx context.Context, config ExperimentCo
 		return fmt.Errorf("HTTP coordinator returned error: %d", resp.StatusCode)
 	}
 
+	startedAt := time.Now()
+	svc.flProgress.configure(config.RoundID, config.ExperimentID, quorum, startedAt,
+		time.Duration(config.MaxUpdateAgeS)*time.Second,
+		time.Duration(config.AggregationGracePeriodS)*time.Second,
+		config.Participants, config.position())
+	svc.flMetrics.configure(config.RoundID, config.ExperimentID)
+	if config.EvaluatorProplet != "" {
+		svc.flEvals.configure(config.RoundID, evalConfig{
+			experimentID:  config.ExperimentID,
+			propletID:     config.EvaluatorProplet,
+			datasetRef:    config.EvalDatasetRef,
+			taskWasmImage: config.TaskWasmImage,
+		})
+	}
+	if config.Async {
+		alpha := config.AsyncAlpha
+		if alpha == 0 {
+			alpha = fl.DefaultAsyncAlpha
+		}
+		version, _ := fl.ModelVersion(config.ModelRef)
+		svc.flAsync.configure(config.RoundID, config.ExperimentID, alpha, version)
+	}
+
 	svc.logger.InfoContext(ctx, "Configured experiment with FL Coordinator",
 		"experiment_id", config.ExperimentID,
 		"round_id", config.RoundID)
 
+	svc.recordAudit(ctx, audit.Entry{
+		Action:     "configure",
+		EntityType: audit.EntityFLRound,
+		EntityID:   config.RoundID,
+		NewState:   "configured",
+		Metadata: map[string]string{
+			"experiment_id": config.ExperimentID,
+			"model_ref":     config.ModelRef,
+			"algorithm":     config.Algorithm,
+			"k_of_n":        strconv.Itoa(quorum.K),
+			"quorum_policy": string(quorum.Policy),
+			"received":      "0",
+		},
+	})
+
 	roundStartMsg := map[string]any{
-		"round_id":        config.RoundID,
-		"model_uri":       config.ModelRef,
-		"task_wasm_image": config.TaskWasmImage,
-		"participants":    config.Participants,
-		"hyperparams":     config.Hyperparams,
+		"round_id":                   config.RoundID,
+		"experiment_id":              config.ExperimentID,
+		"k_of_n":                     quorum.K,
+		"quorum_policy":              string(quorum.Policy),
+		"required_participants":      quorum.Required,
+		"max_update_age_s":           config.MaxUpdateAgeS,
+		"aggregation_grace_period_s": config.AggregationGracePeriodS,
+		"started_at":                 startedAt.Format(time.RFC3339Nano),
+		"model_uri":                  config.ModelRef,
+		"task_wasm_image":            config.TaskWasmImage,
+		"participants":               config.Participants,
+		"hyperparams":                config.Hyperparams,
+	}
+	if config.TotalRounds > 0 {
+		roundStartMsg["round"] = config.Round
+		roundStartMsg["total_rounds"] = config.TotalRounds
 	}
 
 	topic := svc.baseTopic + "/fl/rounds/start"
@@ -117,6 +196,10 @@ func (svc *service) GetFLTask(ctx context.Context, roundID, propletID string) (F
 		return FLTask{}, fmt.Errorf("failed to decode coordinator response: %w", err)
 	}
 
+	if ref, ok := svc.currentModelRef(); ok {
+		taskResp.Task.ModelRef = ref
+	}
+
 	svc.logger.InfoContext(ctx, "Forwarded FL task request to coordinator", "round_id", roundID, "proplet_id", propletID)
 
 	return taskResp.Task, nil
@@ -135,6 +218,28 @@ func (svc *service) PostFLUpdate(ctx context.Context, update FLUpdate) error {
 		return errors.New("MANAGER_COORDINATOR_URL must be configured")
 	}
 
+	if update.ReceivedAt.IsZero() {
+		update.ReceivedAt = time.Now()
+	}
+	if svc.rejectStaleUpdate(ctx, update.RoundID, update.PropletID, update.ReceivedAt) {
+		return fmt.Errorf("%w: %w", pkgerrors.ErrConflict, errStaleUpdate)
+	}
+	update, err := svc.transform.Transform(ctx, update)
+	if err != nil {
+		return fmt.Errorf("%w: failed to transform update: %w", pkgerrors.ErrInvalidValue, err)
+	}
+
+	if svc.flAsync.active(update.RoundID) {
+		if err := svc.postAsyncUpdate(ctx, update); err != nil {
+			return err
+		}
+		svc.flMetrics.add(update)
+		svc.releaseUpdateSlot(ctx, update)
+		svc.recordRoundUpdate(ctx, update.RoundID, update.PropletID)
+
+		return nil
+	}
+
 	updateJSON, err := json.Marshal(update)
 	if err != nil {
 		return fmt.Errorf("failed to marshal update: %w", err)
@@ -160,9 +265,35 @@ func (svc *service) PostFLUpdate(ctx context.Context, update FLUpdate) error {
 		"round_id", update.RoundID,
 		"proplet_id", update.PropletID)
 
+	svc.flMetrics.add(update)
+	svc.releaseUpdateSlot(ctx, update)
+	svc.recordRoundUpdate(ctx, update.RoundID, update.PropletID)
+
 	return nil
 }
 
+// releaseUpdateSlot frees the load slot of the round task that produced
+// update. A participant's work is done once its update is accepted, and its
+// task may never report results.
+func (svc *service) releaseUpdateSlot(ctx context.Context, update FLUpdate) {
+	if update.PropletID == "" {
+		return
+	}
+	tasks, err := queryAllTasks(ctx, svc.taskRepo, task.Query{
+		Metadata:  task.Metadata{roundMetadataKey: update.RoundID},
+		PropletID: update.PropletID,
+	})
+	if err != nil {
+		svc.logger.WarnContext(ctx, "failed to look up round task of FL update",
+			"round_id", update.RoundID, "proplet_id", update.PropletID, "error", err)
+
+		return
+	}
+	for i := range tasks {
+		svc.load.release(tasks[i].ID)
+	}
+}
+
 func (svc *service) PostFLUpdateCBOR(ctx context.Context, updateData []byte) error {
 	var update FLUpdate
 
@@ -201,14 +332,40 @@ func (svc *service) GetRoundStatus(ctx context.Context, roundID string) (RoundSt
 		return RoundStatus{}, fmt.Errorf("coordinator returned error: %d", resp.StatusCode)
 	}
 
-	var statusResp struct {
-		Status RoundStatus `json:"status"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&statusResp); err != nil {
+	status, err := decodeRoundStatus(resp.Body)
+	if err != nil {
 		return RoundStatus{}, fmt.Errorf("failed to decode coordinator response: %w", err)
 	}
 
 	svc.logger.InfoContext(ctx, "Forwarded round status request to coordinator", "round_id", roundID)
 
-	return statusResp.Status, nil
+	status.EvalMetrics = svc.flEvals.get(roundID)
+
+	return status, nil
+}
+
+// decodeRoundStatus reads a coordinator round status, which coordinators
+// return either flat or wrapped in a "status" object.
+func decodeRoundStatus(r io.Reader) (RoundStatus, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return RoundStatus{}, err
+	}
+
+	var wrapper struct {
+		Status json.RawMessage `json:"status"`
+	}
+	if err := json.Unmarshal(raw, &wrapper); err != nil {
+		return RoundStatus{}, err
+	}
+	if len(wrapper.Status) > 0 && wrapper.Status[0] == '{' {
+		raw = wrapper.Status
+	}
+
+	var status RoundStatus
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return RoundStatus{}, err
+	}
+
+	return status, nil
 }
@@ -0,0 +1,84 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/absmach/propeller/pkg/task"
+)
+
+// SigningConfig configures the verification of FL update envelope signatures.
+type SigningConfig struct {
+	// Key is the HMAC key FL update envelopes must be signed with. Setting
+	// it, or Keys, enables verification.
+	Key string `env:"MANAGER_FL_SIGNING_KEY"`
+	// Keys overrides Key per proplet, as comma-separated "proplet_id=key"
+	// pairs.
+	Keys string `env:"MANAGER_FL_SIGNING_KEYS"`
+}
+
+// updateSigning verifies the HMAC signatures of the FL update envelopes
+// proplets report as round task results.
+type updateSigning struct {
+	shared []byte
+	keys   map[string][]byte
+}
+
+// newUpdateSigning returns nil when no key is configured.
+func newUpdateSigning(cfg SigningConfig) *updateSigning {
+	s := &updateSigning{keys: make(map[string][]byte)}
+	if cfg.Key != "" {
+		s.shared = []byte(cfg.Key)
+	}
+	for pair := range strings.SplitSeq(cfg.Keys, ",") {
+		propletID, key, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && propletID != "" && key != "" {
+			s.keys[propletID] = []byte(key)
+		}
+	}
+	if s.shared == nil && len(s.keys) == 0 {
+		return nil
+	}
+
+	return s
+}
+
+// verify checks the signature of the update envelope propletID reported.
+func (s *updateSigning) verify(propletID string, results any) error {
+	if s == nil {
+		return nil
+	}
+	key, ok := s.keys[propletID]
+	if !ok {
+		key = s.shared
+	}
+	if key == nil {
+		return fmt.Errorf("%w: no signing key for proplet %q", fl.ErrInvalidSignature, propletID)
+	}
+	envelope, ok := results.(map[string]any)
+	if !ok {
+		return fl.ErrMissingSignature
+	}
+
+	return fl.VerifyEnvelope(key, envelope)
+}
+
+// rejectUnsignedUpdate fails a completed round task whose update envelope
+// does not carry a valid signature, so the update never reaches its round.
+func (svc *service) rejectUnsignedUpdate(ctx context.Context, t *task.Task, propletID string) {
+	if t.Env["ROUND_ID"] == "" || t.State != task.Completed {
+		return
+	}
+	if propletID == "" {
+		propletID = t.PropletID
+	}
+	if err := svc.flSigning.verify(propletID, t.Results); err != nil {
+		svc.logger.WarnContext(ctx, "rejecting FL update with invalid signature",
+			"task_id", t.ID, "round_id", t.Env["ROUND_ID"], "proplet_id", propletID, "error", err)
+		t.Results = nil
+		t.State = task.Failed
+		t.Error = "FL update rejected: " + err.Error()
+	}
+}
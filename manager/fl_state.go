@@ -0,0 +1,217 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/absmach/propeller/pkg/audit"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/absmach/propeller/pkg/task"
+)
+
+// flJobStateVersion is the format version of exported FL job bundles.
+const flJobStateVersion = 1
+
+// FLJobState is a portable bundle of what the manager holds for an FL job:
+// the tasks of its rounds, the metrics of its completed rounds and the global
+// model versions those rounds produced.
+type FLJobState struct {
+	Version    int            `json:"version"`
+	JobID      string         `json:"job_id"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Tasks      []task.Task    `json:"tasks"`
+	Rounds     []RoundMetrics `json:"rounds,omitempty"`
+	Models     map[int]Model  `json:"models,omitempty"`
+}
+
+// ExportJobState collects the tasks tagged with the job's experiment ID, the
+// job's round metrics and the model versions produced by its rounds.
+func (svc *service) ExportJobState(ctx context.Context, jobID string) ([]byte, error) {
+	if jobID == "" {
+		return nil, pkgerrors.ErrInvalidData
+	}
+
+	tasks, err := svc.listJobTasks(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	rounds, _ := svc.flMetrics.series(jobID)
+	if len(tasks) == 0 && len(rounds) == 0 {
+		return nil, fmt.Errorf("%w: no state for job %s", pkgerrors.ErrNotFound, jobID)
+	}
+
+	roundIDs := make(map[string]struct{})
+	for _, t := range tasks {
+		if roundID, _ := t.Metadata[roundMetadataKey].(string); roundID != "" {
+			roundIDs[roundID] = struct{}{}
+		}
+	}
+	for i := range rounds {
+		rounds[i].EvalMetrics = svc.flEvals.get(rounds[i].RoundID)
+		roundIDs[rounds[i].RoundID] = struct{}{}
+	}
+
+	state := FLJobState{
+		Version:    flJobStateVersion,
+		JobID:      jobID,
+		ExportedAt: time.Now().UTC(),
+		Tasks:      tasks,
+		Rounds:     rounds,
+	}
+	for _, version := range svc.models.List() {
+		model, err := svc.models.Get(version)
+		if err != nil {
+			continue
+		}
+		roundID, _ := model.Metadata["round_id"].(string)
+		if _, ok := roundIDs[roundID]; !ok {
+			continue
+		}
+		if state.Models == nil {
+			state.Models = make(map[int]Model)
+		}
+		state.Models[version] = model
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job state: %w", err)
+	}
+
+	return data, nil
+}
+
+// ImportJobState restores a bundle written by ExportJobState. The whole
+// bundle is validated before anything is written: its tasks must pass the
+// same checks as created tasks and may only overwrite tasks of the same job,
+// and its model versions must be new or identical to the ones held. Restored
+// models do not change the current global model version, and the round
+// metrics of the job replace any the manager holds. Rounds that were in
+// flight are not resumed.
+func (svc *service) ImportJobState(ctx context.Context, data []byte) error {
+	var state FLJobState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("%w: %w", pkgerrors.ErrInvalidData, err)
+	}
+	if state.Version != flJobStateVersion {
+		return fmt.Errorf("%w: unsupported job state version %d", pkgerrors.ErrInvalidValue, state.Version)
+	}
+	if state.JobID == "" {
+		return fmt.Errorf("%w: job state has no job_id", pkgerrors.ErrInvalidValue)
+	}
+
+	existing := make(map[string]bool, len(state.Tasks))
+	for i := range state.Tasks {
+		t := &state.Tasks[i]
+		if t.ID == "" {
+			return fmt.Errorf("%w: job state has a task without an id", pkgerrors.ErrInvalidValue)
+		}
+		if _, dup := existing[t.ID]; dup {
+			return fmt.Errorf("%w: job state has task %s more than once", pkgerrors.ErrInvalidValue, t.ID)
+		}
+		if experimentID, _ := t.Metadata[experimentMetadataKey].(string); experimentID != state.JobID {
+			return fmt.Errorf("%w: task %s does not belong to job %s", pkgerrors.ErrInvalidValue, t.ID, state.JobID)
+		}
+		if err := validateTask(t); err != nil {
+			return fmt.Errorf("invalid task %s: %w", t.ID, err)
+		}
+
+		current, err := svc.GetTask(ctx, t.ID)
+		switch {
+		case err == nil:
+			if experimentID, _ := current.Metadata[experimentMetadataKey].(string); experimentID != state.JobID {
+				return fmt.Errorf("%w: task %s already exists outside job %s", pkgerrors.ErrConflict, t.ID, state.JobID)
+			}
+			existing[t.ID] = true
+		case errors.Is(err, pkgerrors.ErrNotFound):
+			existing[t.ID] = false
+		default:
+			return err
+		}
+	}
+
+	var newModels []int
+	for _, version := range slices.Sorted(maps.Keys(state.Models)) {
+		held, err := svc.models.Get(version)
+		if errors.Is(err, fl.ErrModelNotFound) {
+			newModels = append(newModels, version)
+
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get model version %d: %w", version, err)
+		}
+		if !sameModel(held, state.Models[version]) {
+			return fmt.Errorf("%w: model version %d differs from the one held", pkgerrors.ErrConflict, version)
+		}
+	}
+
+	for _, t := range state.Tasks {
+		var err error
+		if existing[t.ID] {
+			err = svc.taskRepo.Update(ctx, t)
+		} else {
+			_, err = svc.taskRepo.Create(ctx, t)
+		}
+		if err != nil {
+			return repoErr(err, "failed to restore task %s", t.ID)
+		}
+	}
+	for _, version := range newModels {
+		if err := svc.models.Restore(version, state.Models[version]); err != nil {
+			return fmt.Errorf("failed to restore model version %d: %w", version, err)
+		}
+	}
+	svc.flMetrics.restore(state.JobID, state.Rounds)
+	for _, round := range state.Rounds {
+		if round.EvalMetrics != nil {
+			svc.flEvals.record(round.RoundID, round.EvalMetrics)
+		}
+	}
+
+	svc.recordAudit(ctx, audit.Entry{
+		Action:     "import",
+		EntityType: audit.EntityFLRound,
+		EntityID:   state.JobID,
+		Metadata: map[string]string{
+			"tasks":  strconv.Itoa(len(state.Tasks)),
+			"rounds": strconv.Itoa(len(state.Rounds)),
+			"models": strconv.Itoa(len(state.Models)),
+		},
+	})
+
+	return nil
+}
+
+// sameModel reports whether a and b encode to the same JSON, so a model read
+// back from a bundle matches the one it was exported from.
+func sameModel(a, b Model) bool {
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && bytes.Equal(da, db)
+}
+
+func (svc *service) listJobTasks(ctx context.Context, jobID string) ([]task.Task, error) {
+	const pageLimit = uint64(1000)
+	filter := task.Metadata{experimentMetadataKey: jobID}
+	var tasks []task.Task
+	for offset := uint64(0); ; offset += pageLimit {
+		page, total, err := svc.taskRepo.List(ctx, filter, offset, pageLimit)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page...)
+		if offset+pageLimit >= total || len(page) == 0 {
+			return tasks, nil
+		}
+	}
+}
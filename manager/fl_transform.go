@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"strings"
+)
+
+// UpdateTransformConfig selects the transform applied to every FL update
+// before it is aggregated.
+type UpdateTransformConfig struct {
+	// Name is "none", the default, or "scale".
+	Name string `env:"MANAGER_FL_UPDATE_TRANSFORM" envDefault:"none"`
+	// Scale is the factor the "scale" transform multiplies each update's
+	// weights and bias by, e.g. a quantization step to undo.
+	Scale float64 `env:"MANAGER_FL_UPDATE_SCALE" envDefault:"1"`
+}
+
+// UpdateTransformer rewrites an FL update before it is aggregated, for
+// example to dequantize a compressed payload. It runs once per accepted
+// update, ahead of both FedAsync blending and forwarding to the coordinator.
+type UpdateTransformer interface {
+	Transform(ctx context.Context, update FLUpdate) (FLUpdate, error)
+}
+
+type nopTransformer struct{}
+
+// NopUpdateTransformer returns an UpdateTransformer that leaves updates
+// unchanged.
+func NopUpdateTransformer() UpdateTransformer {
+	return nopTransformer{}
+}
+
+func (nopTransformer) Transform(_ context.Context, update FLUpdate) (FLUpdate, error) {
+	return update, nil
+}
+
+type scaleTransformer struct {
+	factor float64
+}
+
+// NewScaleUpdateTransformer returns an UpdateTransformer multiplying the
+// update's "w" weights and "b" bias by factor. Other payload keys are kept
+// as they are.
+func NewScaleUpdateTransformer(factor float64) UpdateTransformer {
+	return scaleTransformer{factor: factor}
+}
+
+func (s scaleTransformer) Transform(_ context.Context, update FLUpdate) (FLUpdate, error) {
+	payload := maps.Clone(update.Update)
+	if raw, ok := payload["w"].([]any); ok {
+		w := make([]any, len(raw))
+		for i, v := range raw {
+			f, ok := v.(float64)
+			if !ok {
+				return FLUpdate{}, fmt.Errorf("weight %d is not a number", i)
+			}
+			w[i] = f * s.factor
+		}
+		payload["w"] = w
+	}
+	if b, ok := payload["b"].(float64); ok {
+		payload["b"] = b * s.factor
+	}
+	update.Update = payload
+
+	return update, nil
+}
+
+// newUpdateTransformer builds the transform cfg selects. A misconfigured
+// transform is logged and updates are left unchanged.
+func newUpdateTransformer(cfg UpdateTransformConfig, logger *slog.Logger) UpdateTransformer {
+	switch name := strings.TrimSpace(cfg.Name); name {
+	case "", "none":
+		return NopUpdateTransformer()
+	case "scale":
+		if math.IsNaN(cfg.Scale) || math.IsInf(cfg.Scale, 0) {
+			logger.Warn("ignoring FL update transform with invalid scale", "scale", cfg.Scale)
+
+			return NopUpdateTransformer()
+		}
+
+		return NewScaleUpdateTransformer(cfg.Scale)
+	default:
+		logger.Warn("ignoring unknown FL update transform", "transform", name)
+
+		return NopUpdateTransformer()
+	}
+}
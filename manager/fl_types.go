@@ -1,6 +1,8 @@
 package manager
 
 import (
+	"time"
+
 	"github.com/absmach/propeller/pkg/fl"
 )
 
@@ -13,12 +15,45 @@ type FLTask struct {
 
 type FLUpdate = fl.Update
 
+type Model = fl.Model
+
+// PersonalizedModel is an FL client's fine-tuned model together with the
+// current global model it personalizes. Global is nil while the registry
+// holds no global model.
+type PersonalizedModel struct {
+	ClientID      string `json:"client_id"`
+	Personalized  Model  `json:"personalized"`
+	GlobalVersion int    `json:"global_version"`
+	Global        *Model `json:"global,omitempty"`
+}
+
+type RoundParticipantStatus = fl.ParticipantStatus
+
 type RoundStatus struct {
-	RoundID      string `json:"round_id"`
-	Completed    bool   `json:"completed"`
-	NumUpdates   int    `json:"num_updates"`
-	KOfN         int    `json:"k_of_n"`
-	ModelVersion int    `json:"model_version,omitempty"`
+	RoundID      string                   `json:"round_id"`
+	Completed    bool                     `json:"completed"`
+	NumUpdates   int                      `json:"num_updates"`
+	KOfN         int                      `json:"k_of_n"`
+	ModelVersion int                      `json:"model_version,omitempty"`
+	Participants []RoundParticipantStatus `json:"participants,omitempty"`
+	EvalMetrics  map[string]float64       `json:"eval_metrics,omitempty"`
+}
+
+// RoundMetrics is one completed round in an FL job's metric series. Metrics
+// averages the metrics clients reported with their updates, weighted by
+// sample count; EvalMetrics holds the evaluator's results, if any.
+type RoundMetrics struct {
+	RoundID      string             `json:"round_id"`
+	ModelVersion int                `json:"model_version,omitempty"`
+	NumUpdates   int                `json:"num_updates"`
+	Metrics      map[string]float64 `json:"metrics,omitempty"`
+	EvalMetrics  map[string]float64 `json:"eval_metrics,omitempty"`
+	CompletedAt  time.Time          `json:"completed_at"`
+}
+
+type FLJobMetrics struct {
+	JobID  string         `json:"job_id"`
+	Rounds []RoundMetrics `json:"rounds"`
 }
 
 type ExperimentConfig struct {
@@ -30,4 +65,62 @@ type ExperimentConfig struct {
 	KOfN          int            `json:"k_of_n"`
 	TimeoutS      int            `json:"timeout_s"`
 	TaskWasmImage string         `json:"task_wasm_image,omitempty"`
+	Algorithm     string         `json:"algorithm,omitempty"`
+
+	// QuorumPolicy selects which updates the round needs before it is
+	// aggregated; RequiredParticipants lists the proplets the
+	// all-required and required-plus-k policies wait for.
+	QuorumPolicy         fl.QuorumPolicy `json:"quorum_policy,omitempty"`
+	RequiredParticipants []string        `json:"required_participants,omitempty"`
+
+	// MaxUpdateAgeS rejects updates arriving more than this many seconds
+	// after the round was configured. Zero accepts updates of any age.
+	MaxUpdateAgeS int `json:"max_update_age_s,omitempty"`
+
+	// AggregationGracePeriodS keeps a round open this many seconds after its
+	// quorum is met so stragglers are aggregated too. The round completes
+	// early once every participant has reported.
+	AggregationGracePeriodS int `json:"aggregation_grace_period_s,omitempty"`
+
+	// EvaluatorProplet, when set, runs each aggregated model in infer mode
+	// against EvalDatasetRef and the returned metrics are recorded on the
+	// round.
+	EvaluatorProplet string `json:"evaluator_proplet,omitempty"`
+	EvalDatasetRef   string `json:"eval_dataset_ref,omitempty"`
+
+	// Async switches the round to FedAsync: the manager blends each update
+	// into the global model as it arrives, weighted by AsyncAlpha discounted
+	// for staleness, instead of waiting for the quorum.
+	Async      bool    `json:"async,omitempty"`
+	AsyncAlpha float64 `json:"async_alpha,omitempty"`
+
+	// Round is the 1-based position of this round in the job and
+	// TotalRounds the number of rounds the job runs. Once the last round is
+	// aggregated the manager publishes a job completion event. Leaving
+	// TotalRounds unset keeps the job open-ended.
+	Round       uint64 `json:"round,omitempty"`
+	TotalRounds uint64 `json:"total_rounds,omitempty"`
+}
+
+func (c ExperimentConfig) spec() fl.Spec {
+	return fl.Spec{
+		RoundID:      c.RoundID,
+		Algorithm:    c.Algorithm,
+		Participants: c.Participants,
+		Quorum:       c.quorum(),
+		TimeoutS:     c.TimeoutS,
+	}
+}
+
+func (c ExperimentConfig) position() roundPosition {
+	return roundPosition{round: c.Round, total: c.TotalRounds}
+}
+
+func (c ExperimentConfig) quorum() fl.Quorum {
+	policy := c.QuorumPolicy
+	if policy == "" {
+		policy = fl.QuorumAnyK
+	}
+
+	return defaultQuorumK(fl.Quorum{Policy: policy, K: c.KOfN, Required: c.RequiredParticipants}, len(c.Participants))
 }
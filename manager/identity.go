@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// PublisherIdentityConfig configures how results are bound to the MQTT
+// identity that published them.
+type PublisherIdentityConfig struct {
+	// Enforce rejects results that are not published on an identity-scoped
+	// ".../control/proplet/<identity>/results" topic. The broker must only
+	// let each MQTT client publish under its own identity, e.g. with a
+	// mosquitto "pattern write .../control/proplet/%c/#" ACL.
+	Enforce bool `env:"MANAGER_ENFORCE_PUBLISHER_IDENTITY" envDefault:"false"`
+	// Bindings binds MQTT identities to the proplet IDs they may claim, as
+	// comma-separated "identity=proplet_id" pairs. An identity without a
+	// binding may only claim the proplet ID equal to it, which is how
+	// proplets register by default.
+	Bindings string `env:"MANAGER_PROPLET_IDENTITIES"`
+}
+
+// identityScopedActions are the proplet control actions whose claimed
+// proplet_id is checked against the publisher identity.
+var identityScopedActions = map[string]bool{"results": true}
+
+// publisherIdentities verifies that a proplet control message was published
+// by the proplet it claims to come from.
+type publisherIdentities struct {
+	logger   *slog.Logger
+	enforce  bool
+	bindings map[string]map[string]bool
+}
+
+func newPublisherIdentities(cfg PublisherIdentityConfig, logger *slog.Logger) *publisherIdentities {
+	p := &publisherIdentities{
+		logger:   logger,
+		enforce:  cfg.Enforce,
+		bindings: make(map[string]map[string]bool),
+	}
+	for pair := range strings.SplitSeq(cfg.Bindings, ",") {
+		identity, propletID, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || identity == "" || propletID == "" {
+			continue
+		}
+		if p.bindings[identity] == nil {
+			p.bindings[identity] = make(map[string]bool)
+		}
+		p.bindings[identity][propletID] = true
+	}
+
+	return p
+}
+
+// verify reports whether msg, received for action under the publisher
+// identity taken from its topic, may be processed. Messages on scoped topics
+// are always checked; unscoped ones are rejected only when enforcing.
+func (p *publisherIdentities) verify(ctx context.Context, action, identity string, msg map[string]any) bool {
+	if !identityScopedActions[action] {
+		return true
+	}
+	propletID, _ := msg["proplet_id"].(string)
+	if identity == "" {
+		if p.enforce {
+			p.logger.WarnContext(ctx, "rejecting proplet message without publisher identity",
+				"action", action, "proplet_id", propletID)
+
+			return false
+		}
+
+		return true
+	}
+
+	allowed, bound := p.bindings[identity]
+	if (bound && allowed[propletID]) || (!bound && propletID == identity) {
+		return true
+	}
+	p.logger.WarnContext(ctx, "rejecting proplet message from another publisher",
+		"action", action, "proplet_id", propletID, "publisher", identity)
+
+	return false
+}
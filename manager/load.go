@@ -0,0 +1,97 @@
+package manager
+
+import "sync"
+
+type propletLoad struct {
+	running    uint64
+	limit      uint64
+	cpuPercent float64
+}
+
+// loadTracker keeps the most recent load each proplet reported. It is
+// refreshed by heartbeats and metrics and bumped locally on every dispatch so
+// that bursts between heartbeats do not overshoot a proplet's limit.
+type loadTracker struct {
+	mu    sync.Mutex
+	loads map[string]propletLoad
+	// slots maps each dispatched task to the proplet it took a slot on, so
+	// a task reported finished by several paths frees its slot once.
+	slots         map[string]string
+	maxCPUPercent float64
+}
+
+func newLoadTracker(maxCPUPercent float64) *loadTracker {
+	return &loadTracker{
+		loads:         make(map[string]propletLoad),
+		slots:         make(map[string]string),
+		maxCPUPercent: max(maxCPUPercent, 0),
+	}
+}
+
+func (l *loadTracker) observeTasks(propletID string, running, limit uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	load := l.loads[propletID]
+	load.running, load.limit = running, limit
+	l.loads[propletID] = load
+}
+
+func (l *loadTracker) observeCPU(propletID string, percent float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	load := l.loads[propletID]
+	load.cpuPercent = percent
+	l.loads[propletID] = load
+}
+
+func (l *loadTracker) reserve(propletID, taskID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.slots[taskID]; ok {
+		return
+	}
+	l.slots[taskID] = propletID
+	load := l.loads[propletID]
+	load.running++
+	l.loads[propletID] = load
+}
+
+// release frees the slot taken by a finished task until the next heartbeat
+// reports the authoritative count. Releasing a task without a slot is a
+// no-op.
+func (l *loadTracker) release(taskID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	propletID, ok := l.slots[taskID]
+	if !ok {
+		return
+	}
+	delete(l.slots, taskID)
+	load, ok := l.loads[propletID]
+	if !ok || load.running == 0 {
+		return
+	}
+	load.running--
+	l.loads[propletID] = load
+}
+
+// saturated reports whether the proplet is at its own concurrency limit or
+// above the manager's CPU threshold.
+func (l *loadTracker) saturated(propletID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	load, ok := l.loads[propletID]
+	if !ok {
+		return false
+	}
+	if load.limit > 0 && load.running >= load.limit {
+		return true
+	}
+
+	return l.maxCPUPercent > 0 && load.cpuPercent > l.maxCPUPercent
+}
@@ -2,7 +2,9 @@ package manager
 
 import (
 	"context"
+	"time"
 
+	"github.com/absmach/propeller/pkg/events"
 	"github.com/absmach/propeller/pkg/proplet"
 	"github.com/absmach/propeller/pkg/sdf"
 	"github.com/absmach/propeller/pkg/task"
@@ -12,14 +14,43 @@ type PageMetadata struct {
 	Offset   uint64        `json:"offset"`
 	Limit    uint64        `json:"limit"`
 	Metadata task.Metadata `json:"metadata,omitempty"`
+
+	// State, Kind, Mode and PropletID optionally narrow ListTasks to the
+	// tasks matching all of those that are set.
+	State     string        `json:"state,omitempty"`
+	Kind      task.TaskKind `json:"kind,omitempty"`
+	Mode      task.Mode     `json:"mode,omitempty"`
+	PropletID string        `json:"proplet_id,omitempty"`
+
+	Sort Sort `json:"sort"`
+}
+
+// PurgeFilter selects the finished tasks PurgeTasks deletes.
+type PurgeFilter struct {
+	// States limits the purge to tasks in these terminal states; all
+	// terminal states are purged when it is empty.
+	States []task.State `json:"states,omitempty"`
+	// OlderThan limits the purge to tasks that finished at least this long
+	// ago.
+	OlderThan time.Duration `json:"older_than,omitempty"`
+}
+
+// Sort orders a listing by one of its sortable fields, ascending unless
+// Order is "desc". The zero value keeps the repository's order.
+type Sort struct {
+	Field string `json:"field,omitempty"`
+	Order string `json:"order,omitempty"`
 }
 
 type Service interface {
 	GetProplet(ctx context.Context, propletID string) (proplet.Proplet, error)
 	GetPropletSDF(ctx context.Context, propletID string) (sdf.Document, error)
-	ListProplets(ctx context.Context, offset, limit uint64, status string) (proplet.PropletPage, error)
+	ListProplets(ctx context.Context, offset, limit uint64, status string, sort Sort) (proplet.PropletPage, error)
 	SelectProplet(ctx context.Context, task task.Task) (proplet.Proplet, error)
 	DeleteProplet(ctx context.Context, propletID string) error
+	// SetPropletLabels replaces the labels of a proplet. Tasks with a group
+	// are only scheduled on proplets whose "group" label matches it.
+	SetPropletLabels(ctx context.Context, propletID string, labels map[string]string) (proplet.Proplet, error)
 
 	CreateTask(ctx context.Context, task task.Task) (task.Task, error)
 	CreateWorkflow(ctx context.Context, tasks []task.Task) ([]task.Task, error)
@@ -32,6 +63,9 @@ type Service interface {
 	ListTasks(ctx context.Context, pm PageMetadata) (task.TaskPage, error)
 	UpdateTask(ctx context.Context, task task.Task) (task.Task, error)
 	DeleteTask(ctx context.Context, taskID string) error
+	// PurgeTasks deletes the finished tasks matching filter and returns how
+	// many were deleted.
+	PurgeTasks(ctx context.Context, filter PurgeFilter) (uint64, error)
 	StartTask(ctx context.Context, taskID string) error
 	StopTask(ctx context.Context, taskID string) error
 
@@ -42,6 +76,10 @@ type Service interface {
 	GetPropletMetrics(ctx context.Context, propletID string, offset, limit uint64) (PropletMetricsPage, error)
 	GetPropletAliveHistory(ctx context.Context, propletID string, offset, limit uint64) (proplet.PropletAliveHistoryPage, error)
 
+	// SubscribeEvents streams task and FL round lifecycle events matching
+	// filter. Callers must Close the subscription when done.
+	SubscribeEvents(ctx context.Context, filter events.Filter) (*events.Subscription, error)
+
 	// Orchestrator/Experiment Config API (Manager acts as Orchestrator per diagram)
 	// Step 1: Configure experiment with FL Coordinator
 	ConfigureExperiment(ctx context.Context, config ExperimentConfig) error
@@ -53,6 +91,30 @@ type Service interface {
 	PostFLUpdate(ctx context.Context, update FLUpdate) error
 	PostFLUpdateCBOR(ctx context.Context, updateData []byte) error
 	GetRoundStatus(ctx context.Context, roundID string) (RoundStatus, error)
+	// GetFLJobMetrics returns the metrics of each completed round of an FL
+	// job (experiment), in the order the rounds completed.
+	GetFLJobMetrics(ctx context.Context, jobID string) (FLJobMetrics, error)
+	// RollbackModel reverts the global model to a stored earlier version,
+	// which FL tasks are then served until a newer round is aggregated.
+	RollbackModel(ctx context.Context, version int) error
+	// StoreModel adds a global model version to the manager's registry;
+	// versions cannot be overwritten.
+	StoreModel(ctx context.Context, version int, model Model) error
+	GetModel(ctx context.Context, version int) (Model, error)
+	// StorePersonalizedModel saves an FL client's fine-tuned model,
+	// replacing any it stored before.
+	StorePersonalizedModel(ctx context.Context, clientID string, model Model) error
+	// GetPersonalizedModel returns a client's fine-tuned model along with
+	// the current global model.
+	GetPersonalizedModel(ctx context.Context, clientID string) (PersonalizedModel, error)
+	// ExportJobState serializes an FL job's tasks, round metrics and the
+	// global models its rounds produced into a portable FLJobState bundle.
+	ExportJobState(ctx context.Context, jobID string) ([]byte, error)
+	// ImportJobState restores a bundle written by ExportJobState.
+	ImportJobState(ctx context.Context, data []byte) error
+	// DebugFLState dumps the in-memory progress of every FL round the
+	// manager tracks, for operators diagnosing a stuck job.
+	DebugFLState(ctx context.Context) (FLDebugState, error)
 
 	// Note: Round completion notifications are handled by FL Coordinator directly.
 	// Coordinators publish MQTT notifications to "fl/rounds/next" topic.
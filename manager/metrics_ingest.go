@@ -0,0 +1,112 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	MetricsPolicyDrop  = "drop"
+	MetricsPolicyBlock = "block"
+
+	defaultMetricsBuffer = 1024
+)
+
+// MetricsIngestConfig configures the workers that persist metrics.
+type MetricsIngestConfig struct {
+	// Workers is how many workers persist task and proplet metrics off the
+	// MQTT callback. Zero, the default, stores metrics inline.
+	Workers int `env:"MANAGER_METRICS_WORKERS" envDefault:"0"`
+	// Buffer bounds how many metrics writes may wait for a worker.
+	Buffer int `env:"MANAGER_METRICS_BUFFER" envDefault:"1024"`
+	// FullPolicy selects what happens when the buffer is full:
+	// MetricsPolicyDrop discards the write, MetricsPolicyBlock waits for
+	// room.
+	FullPolicy string `env:"MANAGER_METRICS_FULL_POLICY" envDefault:"drop"`
+}
+
+// metricsIngest persists metrics on a pool of workers so that a slow metrics
+// store does not hold up the processing of other control messages.
+type metricsIngest struct {
+	logger  *slog.Logger
+	block   bool
+	writes  chan func()
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Uint64
+	wg      sync.WaitGroup
+}
+
+// newMetricsIngest returns nil when no workers are configured.
+func newMetricsIngest(cfg MetricsIngestConfig, logger *slog.Logger) *metricsIngest {
+	workers := cfg.Workers
+	if workers <= 0 {
+		return nil
+	}
+	buffer := cfg.Buffer
+	if buffer < 0 {
+		buffer = defaultMetricsBuffer
+	}
+
+	m := &metricsIngest{
+		logger: logger,
+		block:  strings.TrimSpace(cfg.FullPolicy) == MetricsPolicyBlock,
+		writes: make(chan func(), buffer),
+	}
+	for range workers {
+		m.wg.Go(func() {
+			for write := range m.writes {
+				write()
+			}
+		})
+	}
+
+	return m
+}
+
+// submit queues write for a worker, applying the full-buffer policy. It
+// reports false when the write was dropped.
+func (m *metricsIngest) submit(ctx context.Context, write func()) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return m.drop(ctx)
+	}
+	if m.block {
+		select {
+		case m.writes <- write:
+			return true
+		case <-ctx.Done():
+			return m.drop(ctx)
+		}
+	}
+	select {
+	case m.writes <- write:
+		return true
+	default:
+		return m.drop(ctx)
+	}
+}
+
+func (m *metricsIngest) drop(ctx context.Context) bool {
+	dropped := m.dropped.Add(1)
+	m.logger.WarnContext(ctx, "metrics buffer full, dropping metrics", "dropped_total", dropped)
+
+	return false
+}
+
+// close stops accepting metrics and waits for the queued writes to finish.
+func (m *metricsIngest) close() {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.writes)
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
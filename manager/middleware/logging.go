@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/events"
 	"github.com/absmach/propeller/pkg/proplet"
 	"github.com/absmach/propeller/pkg/sdf"
 	"github.com/absmach/propeller/pkg/task"
@@ -63,13 +64,15 @@ func (lm *loggingMiddleware) GetPropletSDF(ctx context.Context, id string) (resp
 	return lm.svc.GetPropletSDF(ctx, id)
 }
 
-func (lm *loggingMiddleware) ListProplets(ctx context.Context, offset, limit uint64, status string) (resp proplet.PropletPage, err error) {
+func (lm *loggingMiddleware) ListProplets(ctx context.Context, offset, limit uint64, status string, sort manager.Sort) (resp proplet.PropletPage, err error) {
 	defer func(begin time.Time) {
 		args := []any{
 			slog.String("duration", time.Since(begin).String()),
 			slog.Uint64("offset", offset),
 			slog.Uint64("limit", limit),
 			slog.String("status", status),
+			slog.String("sort", sort.Field),
+			slog.String("order", sort.Order),
 		}
 		if err != nil {
 			args = append(args, slog.Any("error", err))
@@ -80,7 +83,7 @@ func (lm *loggingMiddleware) ListProplets(ctx context.Context, offset, limit uin
 		lm.logger.Info("List proplets completed successfully", args...)
 	}(time.Now())
 
-	return lm.svc.ListProplets(ctx, offset, limit, status)
+	return lm.svc.ListProplets(ctx, offset, limit, status, sort)
 }
 
 func (lm *loggingMiddleware) SelectProplet(ctx context.Context, t task.Task) (w proplet.Proplet, err error) {
@@ -130,6 +133,25 @@ func (lm *loggingMiddleware) GetPropletAliveHistory(ctx context.Context, proplet
 	return lm.svc.GetPropletAliveHistory(ctx, propletID, offset, limit)
 }
 
+func (lm *loggingMiddleware) SubscribeEvents(ctx context.Context, filter events.Filter) (sub *events.Subscription, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("task_id", filter.TaskID),
+			slog.String("job_id", filter.JobID),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Subscribe events failed", args...)
+
+			return
+		}
+		lm.logger.Info("Subscribe events completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.SubscribeEvents(ctx, filter)
+}
+
 func (lm *loggingMiddleware) DeleteProplet(ctx context.Context, id string) (err error) {
 	defer func(begin time.Time) {
 		args := []any{
@@ -150,6 +172,27 @@ func (lm *loggingMiddleware) DeleteProplet(ctx context.Context, id string) (err
 	return lm.svc.DeleteProplet(ctx, id)
 }
 
+func (lm *loggingMiddleware) SetPropletLabels(ctx context.Context, id string, labels map[string]string) (resp proplet.Proplet, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("proplet",
+				slog.String("id", id),
+				slog.Any("labels", labels),
+			),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Set proplet labels failed", args...)
+
+			return
+		}
+		lm.logger.Info("Set proplet labels completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.SetPropletLabels(ctx, id, labels)
+}
+
 func (lm *loggingMiddleware) CreateTask(ctx context.Context, t task.Task) (resp task.Task, err error) {
 	defer func(begin time.Time) {
 		args := []any{
@@ -250,6 +293,32 @@ func (lm *loggingMiddleware) DeleteTask(ctx context.Context, id string) (err err
 	return lm.svc.DeleteTask(ctx, id)
 }
 
+func (lm *loggingMiddleware) PurgeTasks(ctx context.Context, filter manager.PurgeFilter) (deleted uint64, err error) {
+	defer func(begin time.Time) {
+		states := make([]string, len(filter.States))
+		for i, st := range filter.States {
+			states[i] = st.String()
+		}
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Group("filter",
+				slog.Any("states", states),
+				slog.String("older_than", filter.OlderThan.String()),
+			),
+			slog.Uint64("deleted", deleted),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Purge tasks failed", args...)
+
+			return
+		}
+		lm.logger.Info("Purge tasks completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.PurgeTasks(ctx, filter)
+}
+
 func (lm *loggingMiddleware) StartTask(ctx context.Context, id string) (err error) {
 	defer func(begin time.Time) {
 		args := []any{
@@ -599,6 +668,169 @@ func (lm *loggingMiddleware) GetRoundStatus(ctx context.Context, roundID string)
 	return lm.svc.GetRoundStatus(ctx, roundID)
 }
 
+func (lm *loggingMiddleware) GetFLJobMetrics(ctx context.Context, jobID string) (resp manager.FLJobMetrics, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("job_id", jobID),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Get FL job metrics failed", args...)
+
+			return
+		}
+		lm.logger.Info("Get FL job metrics completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.GetFLJobMetrics(ctx, jobID)
+}
+
+func (lm *loggingMiddleware) RollbackModel(ctx context.Context, version int) (err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Int("version", version),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Rollback model failed", args...)
+
+			return
+		}
+		lm.logger.Info("Rollback model completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.RollbackModel(ctx, version)
+}
+
+func (lm *loggingMiddleware) StoreModel(ctx context.Context, version int, model manager.Model) (err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Int("version", version),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Store model failed", args...)
+
+			return
+		}
+		lm.logger.Info("Store model completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.StoreModel(ctx, version, model)
+}
+
+func (lm *loggingMiddleware) GetModel(ctx context.Context, version int) (resp manager.Model, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Int("version", version),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Get model failed", args...)
+
+			return
+		}
+		lm.logger.Info("Get model completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.GetModel(ctx, version)
+}
+
+func (lm *loggingMiddleware) StorePersonalizedModel(ctx context.Context, clientID string, model manager.Model) (err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("client_id", clientID),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Store personalized model failed", args...)
+
+			return
+		}
+		lm.logger.Info("Store personalized model completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.StorePersonalizedModel(ctx, clientID, model)
+}
+
+func (lm *loggingMiddleware) GetPersonalizedModel(ctx context.Context, clientID string) (resp manager.PersonalizedModel, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("client_id", clientID),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Get personalized model failed", args...)
+
+			return
+		}
+		lm.logger.Info("Get personalized model completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.GetPersonalizedModel(ctx, clientID)
+}
+
+func (lm *loggingMiddleware) ExportJobState(ctx context.Context, jobID string) (resp []byte, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.String("job_id", jobID),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Export job state failed", args...)
+
+			return
+		}
+		args = append(args, slog.Int("bytes", len(resp)))
+		lm.logger.Info("Export job state completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.ExportJobState(ctx, jobID)
+}
+
+func (lm *loggingMiddleware) ImportJobState(ctx context.Context, data []byte) (err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+			slog.Int("bytes", len(data)),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Import job state failed", args...)
+
+			return
+		}
+		lm.logger.Info("Import job state completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.ImportJobState(ctx, data)
+}
+
+func (lm *loggingMiddleware) DebugFLState(ctx context.Context) (resp manager.FLDebugState, err error) {
+	defer func(begin time.Time) {
+		args := []any{
+			slog.String("duration", time.Since(begin).String()),
+		}
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+			lm.logger.Warn("Debug FL state failed", args...)
+
+			return
+		}
+		args = append(args, slog.Int("jobs", len(resp.Jobs)))
+		lm.logger.Info("Debug FL state completed successfully", args...)
+	}(time.Now())
+
+	return lm.svc.DebugFLState(ctx)
+}
+
 func (lm *loggingMiddleware) Shutdown(ctx context.Context) (err error) {
 	defer func(begin time.Time) {
 		args := []any{
@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/absmach/propeller/manager/middleware"
+	managermocks "github.com/absmach/propeller/manager/mocks"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLogLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line), "expected exactly one JSON log line, got %q", buf.String())
+
+	return line
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc      string
+		err       error
+		wantLevel string
+		wantMsg   string
+	}{
+		{
+			desc:      "successful call logs at info",
+			wantLevel: "INFO",
+			wantMsg:   "Get task completed successfully",
+		},
+		{
+			desc:      "failed call logs the error at warn",
+			err:       errors.New("task not found"),
+			wantLevel: "WARN",
+			wantMsg:   "Get task failed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			svc := managermocks.NewMockService(t)
+			svc.On("GetTask", mock.Anything, "task-1").Return(task.Task{ID: "task-1"}, tc.err)
+
+			got, err := middleware.Logging(logger, svc).GetTask(context.Background(), "task-1")
+			assert.Equal(t, tc.err, err)
+			if tc.err == nil {
+				assert.Equal(t, "task-1", got.ID)
+			}
+
+			line := decodeLogLine(t, &buf)
+			assert.Equal(t, tc.wantLevel, line["level"])
+			assert.Equal(t, tc.wantMsg, line["msg"])
+			assert.NotEmpty(t, line["duration"])
+			assert.Equal(t, map[string]any{"id": "task-1"}, line["task"])
+			if tc.err != nil {
+				assert.Equal(t, tc.err.Error(), line["error"])
+			} else {
+				assert.NotContains(t, line, "error")
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/events"
 	"github.com/absmach/propeller/pkg/proplet"
 	"github.com/absmach/propeller/pkg/sdf"
 	"github.com/absmach/propeller/pkg/task"
@@ -45,13 +46,13 @@ func (mm *metricsMiddleware) GetPropletSDF(ctx context.Context, id string) (sdf.
 	return mm.svc.GetPropletSDF(ctx, id)
 }
 
-func (mm *metricsMiddleware) ListProplets(ctx context.Context, offset, limit uint64, status string) (proplet.PropletPage, error) {
+func (mm *metricsMiddleware) ListProplets(ctx context.Context, offset, limit uint64, status string, sort manager.Sort) (proplet.PropletPage, error) {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "list-proplets").Add(1)
 		mm.latency.With("method", "list-proplets").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return mm.svc.ListProplets(ctx, offset, limit, status)
+	return mm.svc.ListProplets(ctx, offset, limit, status, sort)
 }
 
 func (mm *metricsMiddleware) SelectProplet(ctx context.Context, t task.Task) (proplet.Proplet, error) {
@@ -72,6 +73,15 @@ func (mm *metricsMiddleware) GetPropletAliveHistory(ctx context.Context, proplet
 	return mm.svc.GetPropletAliveHistory(ctx, propletID, offset, limit)
 }
 
+func (mm *metricsMiddleware) SubscribeEvents(ctx context.Context, filter events.Filter) (*events.Subscription, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "subscribe-events").Add(1)
+		mm.latency.With("method", "subscribe-events").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.SubscribeEvents(ctx, filter)
+}
+
 func (mm *metricsMiddleware) DeleteProplet(ctx context.Context, id string) error {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "delete-proplet").Add(1)
@@ -81,6 +91,15 @@ func (mm *metricsMiddleware) DeleteProplet(ctx context.Context, id string) error
 	return mm.svc.DeleteProplet(ctx, id)
 }
 
+func (mm *metricsMiddleware) SetPropletLabels(ctx context.Context, id string, labels map[string]string) (proplet.Proplet, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "set-proplet-labels").Add(1)
+		mm.latency.With("method", "set-proplet-labels").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.SetPropletLabels(ctx, id, labels)
+}
+
 func (mm *metricsMiddleware) CreateTask(ctx context.Context, t task.Task) (task.Task, error) {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "create-task").Add(1)
@@ -126,6 +145,15 @@ func (mm *metricsMiddleware) DeleteTask(ctx context.Context, id string) error {
 	return mm.svc.DeleteTask(ctx, id)
 }
 
+func (mm *metricsMiddleware) PurgeTasks(ctx context.Context, filter manager.PurgeFilter) (uint64, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "purge-tasks").Add(1)
+		mm.latency.With("method", "purge-tasks").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.PurgeTasks(ctx, filter)
+}
+
 func (mm *metricsMiddleware) StartTask(ctx context.Context, id string) error {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "start-task").Add(1)
@@ -288,6 +316,87 @@ func (mm *metricsMiddleware) GetRoundStatus(ctx context.Context, roundID string)
 	return mm.svc.GetRoundStatus(ctx, roundID)
 }
 
+func (mm *metricsMiddleware) GetFLJobMetrics(ctx context.Context, jobID string) (manager.FLJobMetrics, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "get-fl-job-metrics").Add(1)
+		mm.latency.With("method", "get-fl-job-metrics").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.GetFLJobMetrics(ctx, jobID)
+}
+
+func (mm *metricsMiddleware) RollbackModel(ctx context.Context, version int) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "rollback-model").Add(1)
+		mm.latency.With("method", "rollback-model").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.RollbackModel(ctx, version)
+}
+
+func (mm *metricsMiddleware) StoreModel(ctx context.Context, version int, model manager.Model) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "store-model").Add(1)
+		mm.latency.With("method", "store-model").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.StoreModel(ctx, version, model)
+}
+
+func (mm *metricsMiddleware) GetModel(ctx context.Context, version int) (manager.Model, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "get-model").Add(1)
+		mm.latency.With("method", "get-model").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.GetModel(ctx, version)
+}
+
+func (mm *metricsMiddleware) StorePersonalizedModel(ctx context.Context, clientID string, model manager.Model) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "store-personalized-model").Add(1)
+		mm.latency.With("method", "store-personalized-model").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.StorePersonalizedModel(ctx, clientID, model)
+}
+
+func (mm *metricsMiddleware) GetPersonalizedModel(ctx context.Context, clientID string) (manager.PersonalizedModel, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "get-personalized-model").Add(1)
+		mm.latency.With("method", "get-personalized-model").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.GetPersonalizedModel(ctx, clientID)
+}
+
+func (mm *metricsMiddleware) ExportJobState(ctx context.Context, jobID string) ([]byte, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "export-job-state").Add(1)
+		mm.latency.With("method", "export-job-state").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ExportJobState(ctx, jobID)
+}
+
+func (mm *metricsMiddleware) ImportJobState(ctx context.Context, data []byte) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "import-job-state").Add(1)
+		mm.latency.With("method", "import-job-state").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ImportJobState(ctx, data)
+}
+
+func (mm *metricsMiddleware) DebugFLState(ctx context.Context) (manager.FLDebugState, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "debug-fl-state").Add(1)
+		mm.latency.With("method", "debug-fl-state").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.DebugFLState(ctx)
+}
+
 func (mm *metricsMiddleware) Shutdown(ctx context.Context) error {
 	defer func(begin time.Time) {
 		mm.counter.With("method", "shutdown").Add(1)
@@ -0,0 +1,94 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/absmach/propeller/manager/middleware"
+	managermocks "github.com/absmach/propeller/manager/mocks"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// observations collects the values a counter or histogram records, keyed by
+// its "method" label.
+type observations struct {
+	mu     sync.Mutex
+	values map[string][]float64
+}
+
+func (o *observations) record(method string, v float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.values == nil {
+		o.values = map[string][]float64{}
+	}
+	o.values[method] = append(o.values[method], v)
+}
+
+func (o *observations) get(method string) []float64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.values[method]
+}
+
+func methodLabel(labelValues []string) string {
+	for i := 0; i+1 < len(labelValues); i += 2 {
+		if labelValues[i] == "method" {
+			return labelValues[i+1]
+		}
+	}
+
+	return ""
+}
+
+type fakeCounter struct {
+	obs    *observations
+	method string
+}
+
+func (c fakeCounter) With(labelValues ...string) metrics.Counter {
+	return fakeCounter{obs: c.obs, method: methodLabel(labelValues)}
+}
+
+func (c fakeCounter) Add(delta float64) { c.obs.record(c.method, delta) }
+
+type fakeHistogram struct {
+	obs    *observations
+	method string
+}
+
+func (h fakeHistogram) With(labelValues ...string) metrics.Histogram {
+	return fakeHistogram{obs: h.obs, method: methodLabel(labelValues)}
+}
+
+func (h fakeHistogram) Observe(value float64) { h.obs.record(h.method, value) }
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var counts, latencies observations
+	svc := managermocks.NewMockService(t)
+	svc.On("GetTask", mock.Anything, "task-1").Return(task.Task{ID: "task-1"}, nil).Once()
+	svc.On("StartTask", mock.Anything, "task-1").Return(errors.New("no proplets")).Once()
+	mm := middleware.Metrics(fakeCounter{obs: &counts}, fakeHistogram{obs: &latencies}, svc)
+
+	got, err := mm.GetTask(context.Background(), "task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "task-1", got.ID)
+	assert.Error(t, mm.StartTask(context.Background(), "task-1"))
+
+	for _, method := range []string{"get-task", "start-task"} {
+		assert.Equal(t, []float64{1}, counts.get(method), "calls counted for %s", method)
+		observed := latencies.get(method)
+		if assert.Len(t, observed, 1, "latency observed for %s", method) {
+			assert.GreaterOrEqual(t, observed[0], 0.0)
+		}
+	}
+	assert.Empty(t, counts.get("list-tasks"))
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/task"
+)
+
+// redactMiddleware hides sensitive task env values from the tasks returned to
+// callers. Tasks are stored and delivered to proplets unchanged.
+type redactMiddleware struct {
+	manager.Service
+
+	redactor manager.EnvRedactor
+}
+
+func Redact(redactor manager.EnvRedactor, svc manager.Service) manager.Service {
+	return &redactMiddleware{
+		Service:  svc,
+		redactor: redactor,
+	}
+}
+
+func (rm *redactMiddleware) CreateTask(ctx context.Context, t task.Task) (task.Task, error) {
+	created, err := rm.Service.CreateTask(ctx, t)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	return rm.redactor.Task(created), nil
+}
+
+func (rm *redactMiddleware) CreateWorkflow(ctx context.Context, tasks []task.Task) ([]task.Task, error) {
+	created, err := rm.Service.CreateWorkflow(ctx, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return rm.redactTasks(created), nil
+}
+
+func (rm *redactMiddleware) CreateJob(ctx context.Context, name string, tasks []task.Task, executionMode string) (string, []task.Task, error) {
+	jobID, created, err := rm.Service.CreateJob(ctx, name, tasks, executionMode)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return jobID, rm.redactTasks(created), nil
+}
+
+func (rm *redactMiddleware) GetTask(ctx context.Context, taskID string) (task.Task, error) {
+	t, err := rm.Service.GetTask(ctx, taskID)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	return rm.redactor.Task(t), nil
+}
+
+func (rm *redactMiddleware) GetJob(ctx context.Context, jobID string) ([]task.Task, error) {
+	tasks, err := rm.Service.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return rm.redactTasks(tasks), nil
+}
+
+func (rm *redactMiddleware) ListTasks(ctx context.Context, pm manager.PageMetadata) (task.TaskPage, error) {
+	page, err := rm.Service.ListTasks(ctx, pm)
+	if err != nil {
+		return task.TaskPage{}, err
+	}
+	page.Tasks = rm.redactTasks(page.Tasks)
+
+	return page, nil
+}
+
+func (rm *redactMiddleware) UpdateTask(ctx context.Context, t task.Task) (task.Task, error) {
+	updated, err := rm.Service.UpdateTask(ctx, t)
+	if err != nil {
+		return task.Task{}, err
+	}
+
+	return rm.redactor.Task(updated), nil
+}
+
+func (rm *redactMiddleware) redactTasks(tasks []task.Task) []task.Task {
+	if tasks == nil {
+		return nil
+	}
+	out := make([]task.Task, len(tasks))
+	for i := range tasks {
+		out[i] = rm.redactor.Task(tasks[i])
+	}
+
+	return out
+}
@@ -0,0 +1,67 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/manager/middleware"
+	managermocks "github.com/absmach/propeller/manager/mocks"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactMiddlewareHidesSensitiveEnv(t *testing.T) {
+	t.Parallel()
+
+	stored := task.Task{
+		ID: "task-1",
+		Env: map[string]string{
+			"DATASET_TOKEN": "tok-123",
+			"db_password":   "hunter2",
+			"DB_URL":        "postgres://db",
+			"MODE":          "edge",
+		},
+		SecretRefs: map[string]string{"DB_URL": "db-url"},
+	}
+	want := map[string]string{
+		"DATASET_TOKEN": "[REDACTED]",
+		"db_password":   "[REDACTED]",
+		"DB_URL":        "[REDACTED]",
+		"MODE":          "edge",
+	}
+
+	svc := managermocks.NewMockService(t)
+	svc.On("GetTask", mock.Anything, "task-1").Return(stored, nil)
+	svc.On("ListTasks", mock.Anything, mock.Anything).Return(task.TaskPage{Tasks: []task.Task{stored}, Total: 1}, nil)
+	redacted := middleware.Redact(manager.NewEnvRedactor(manager.ParseSensitiveEnvKeys("*_TOKEN,*_PASSWORD")), svc)
+
+	got, err := redacted.GetTask(context.Background(), "task-1")
+	require.NoError(t, err)
+	assert.Equal(t, want, got.Env)
+
+	page, err := redacted.ListTasks(context.Background(), manager.PageMetadata{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, want, page.Tasks[0].Env)
+
+	assert.Equal(t, "tok-123", stored.Env["DATASET_TOKEN"], "the wrapped service's task is not modified")
+}
+
+func TestRedactMiddlewareConfigurablePatterns(t *testing.T) {
+	t.Parallel()
+
+	svc := managermocks.NewMockService(t)
+	svc.On("GetTask", mock.Anything, "task-1").Return(task.Task{
+		ID:  "task-1",
+		Env: map[string]string{"MODEL_URI": "oci://registry/model", "API_TOKEN": "tok-123"},
+	}, nil)
+	redacted := middleware.Redact(manager.NewEnvRedactor(manager.ParseSensitiveEnvKeys("model_*")), svc)
+
+	got, err := redacted.GetTask(context.Background(), "task-1")
+	require.NoError(t, err)
+	assert.Equal(t, "[REDACTED]", got.Env["MODEL_URI"])
+	assert.Equal(t, "tok-123", got.Env["API_TOKEN"], "only the configured patterns are redacted")
+}
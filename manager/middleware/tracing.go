@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/events"
 	"github.com/absmach/propeller/pkg/proplet"
 	"github.com/absmach/propeller/pkg/sdf"
 	"github.com/absmach/propeller/pkg/task"
@@ -40,23 +41,23 @@ func (tm *tracing) GetPropletSDF(ctx context.Context, id string) (resp sdf.Docum
 	return tm.svc.GetPropletSDF(ctx, id)
 }
 
-func (tm *tracing) ListProplets(ctx context.Context, offset, limit uint64, status string) (resp proplet.PropletPage, err error) {
+func (tm *tracing) ListProplets(ctx context.Context, offset, limit uint64, status string, sort manager.Sort) (resp proplet.PropletPage, err error) {
 	ctx, span := tm.tracer.Start(ctx, "list-proplets", trace.WithAttributes(
 		attribute.Int64("offset", int64(offset)),
 		attribute.Int64("limit", int64(limit)),
 		attribute.String("status", status),
+		attribute.String("sort", sort.Field),
+		attribute.String("order", sort.Order),
 	))
 	defer span.End()
 
-	return tm.svc.ListProplets(ctx, offset, limit, status)
+	return tm.svc.ListProplets(ctx, offset, limit, status, sort)
 }
 
 func (tm *tracing) SelectProplet(ctx context.Context, t task.Task) (resp proplet.Proplet, err error) {
-	ctx, span := tm.tracer.Start(ctx, "create-task", trace.WithAttributes(
-		attribute.String("task.name", t.Name),
-		attribute.String("task.id", t.ID),
-		attribute.String("proplet.name", resp.Name),
-		attribute.String("proplet.id", resp.ID),
+	ctx, span := tm.tracer.Start(ctx, "select-proplet", trace.WithAttributes(
+		attribute.String("task_id", t.ID),
+		attribute.String("job_id", t.JobID),
 	))
 	defer span.End()
 
@@ -74,6 +75,16 @@ func (tm *tracing) GetPropletAliveHistory(ctx context.Context, propletID string,
 	return tm.svc.GetPropletAliveHistory(ctx, propletID, offset, limit)
 }
 
+func (tm *tracing) SubscribeEvents(ctx context.Context, filter events.Filter) (*events.Subscription, error) {
+	ctx, span := tm.tracer.Start(ctx, "subscribe-events", trace.WithAttributes(
+		attribute.String("task_id", filter.TaskID),
+		attribute.String("job_id", filter.JobID),
+	))
+	defer span.End()
+
+	return tm.svc.SubscribeEvents(ctx, filter)
+}
+
 func (tm *tracing) DeleteProplet(ctx context.Context, id string) (err error) {
 	ctx, span := tm.tracer.Start(ctx, "delete-proplet", trace.WithAttributes(
 		attribute.String("id", id),
@@ -83,19 +94,32 @@ func (tm *tracing) DeleteProplet(ctx context.Context, id string) (err error) {
 	return tm.svc.DeleteProplet(ctx, id)
 }
 
+func (tm *tracing) SetPropletLabels(ctx context.Context, id string, labels map[string]string) (resp proplet.Proplet, err error) {
+	ctx, span := tm.tracer.Start(ctx, "set-proplet-labels", trace.WithAttributes(
+		attribute.String("id", id),
+	))
+	defer span.End()
+
+	return tm.svc.SetPropletLabels(ctx, id, labels)
+}
+
 func (tm *tracing) CreateTask(ctx context.Context, t task.Task) (resp task.Task, err error) {
 	ctx, span := tm.tracer.Start(ctx, "create-task", trace.WithAttributes(
-		attribute.String("name", resp.Name),
-		attribute.String("id", resp.ID),
+		attribute.String("name", t.Name),
+		attribute.String("job_id", t.JobID),
 	))
 	defer span.End()
 
-	return tm.svc.CreateTask(ctx, t)
+	resp, err = tm.svc.CreateTask(ctx, t)
+	// The service assigns the ID, so it is only known once the call returns.
+	span.SetAttributes(attribute.String("task_id", resp.ID))
+
+	return resp, err
 }
 
 func (tm *tracing) GetTask(ctx context.Context, id string) (resp task.Task, err error) {
 	ctx, span := tm.tracer.Start(ctx, "get-task", trace.WithAttributes(
-		attribute.String("id", id),
+		attribute.String("task_id", id),
 	))
 	defer span.End()
 
@@ -114,8 +138,8 @@ func (tm *tracing) ListTasks(ctx context.Context, pm manager.PageMetadata) (resp
 
 func (tm *tracing) UpdateTask(ctx context.Context, t task.Task) (resp task.Task, err error) {
 	ctx, span := tm.tracer.Start(ctx, "update-task", trace.WithAttributes(
-		attribute.String("id", resp.ID),
-		attribute.String("name", resp.Name),
+		attribute.String("task_id", t.ID),
+		attribute.String("name", t.Name),
 	))
 	defer span.End()
 
@@ -124,16 +148,26 @@ func (tm *tracing) UpdateTask(ctx context.Context, t task.Task) (resp task.Task,
 
 func (tm *tracing) DeleteTask(ctx context.Context, id string) (err error) {
 	ctx, span := tm.tracer.Start(ctx, "delete-task", trace.WithAttributes(
-		attribute.String("id", id),
+		attribute.String("task_id", id),
 	))
 	defer span.End()
 
 	return tm.svc.DeleteTask(ctx, id)
 }
 
+func (tm *tracing) PurgeTasks(ctx context.Context, filter manager.PurgeFilter) (deleted uint64, err error) {
+	ctx, span := tm.tracer.Start(ctx, "purge-tasks", trace.WithAttributes(
+		attribute.Int("states", len(filter.States)),
+		attribute.String("older_than", filter.OlderThan.String()),
+	))
+	defer span.End()
+
+	return tm.svc.PurgeTasks(ctx, filter)
+}
+
 func (tm *tracing) StartTask(ctx context.Context, id string) (err error) {
 	ctx, span := tm.tracer.Start(ctx, "start-task", trace.WithAttributes(
-		attribute.String("id", id),
+		attribute.String("task_id", id),
 	))
 	defer span.End()
 
@@ -142,7 +176,7 @@ func (tm *tracing) StartTask(ctx context.Context, id string) (err error) {
 
 func (tm *tracing) StopTask(ctx context.Context, id string) (err error) {
 	ctx, span := tm.tracer.Start(ctx, "stop-task", trace.WithAttributes(
-		attribute.String("id", id),
+		attribute.String("task_id", id),
 	))
 	defer span.End()
 
@@ -302,6 +336,85 @@ func (tm *tracing) GetRoundStatus(ctx context.Context, roundID string) (resp man
 	return tm.svc.GetRoundStatus(ctx, roundID)
 }
 
+func (tm *tracing) GetFLJobMetrics(ctx context.Context, jobID string) (resp manager.FLJobMetrics, err error) {
+	ctx, span := tm.tracer.Start(ctx, "get-fl-job-metrics", trace.WithAttributes(
+		attribute.String("job_id", jobID),
+	))
+	defer span.End()
+
+	return tm.svc.GetFLJobMetrics(ctx, jobID)
+}
+
+func (tm *tracing) RollbackModel(ctx context.Context, version int) error {
+	ctx, span := tm.tracer.Start(ctx, "rollback-model", trace.WithAttributes(
+		attribute.Int("version", version),
+	))
+	defer span.End()
+
+	return tm.svc.RollbackModel(ctx, version)
+}
+
+func (tm *tracing) StoreModel(ctx context.Context, version int, model manager.Model) error {
+	ctx, span := tm.tracer.Start(ctx, "store-model", trace.WithAttributes(
+		attribute.Int("version", version),
+	))
+	defer span.End()
+
+	return tm.svc.StoreModel(ctx, version, model)
+}
+
+func (tm *tracing) GetModel(ctx context.Context, version int) (manager.Model, error) {
+	ctx, span := tm.tracer.Start(ctx, "get-model", trace.WithAttributes(
+		attribute.Int("version", version),
+	))
+	defer span.End()
+
+	return tm.svc.GetModel(ctx, version)
+}
+
+func (tm *tracing) StorePersonalizedModel(ctx context.Context, clientID string, model manager.Model) error {
+	ctx, span := tm.tracer.Start(ctx, "store-personalized-model", trace.WithAttributes(
+		attribute.String("client_id", clientID),
+	))
+	defer span.End()
+
+	return tm.svc.StorePersonalizedModel(ctx, clientID, model)
+}
+
+func (tm *tracing) GetPersonalizedModel(ctx context.Context, clientID string) (manager.PersonalizedModel, error) {
+	ctx, span := tm.tracer.Start(ctx, "get-personalized-model", trace.WithAttributes(
+		attribute.String("client_id", clientID),
+	))
+	defer span.End()
+
+	return tm.svc.GetPersonalizedModel(ctx, clientID)
+}
+
+func (tm *tracing) ExportJobState(ctx context.Context, jobID string) ([]byte, error) {
+	ctx, span := tm.tracer.Start(ctx, "export-job-state", trace.WithAttributes(
+		attribute.String("job_id", jobID),
+	))
+	defer span.End()
+
+	return tm.svc.ExportJobState(ctx, jobID)
+}
+
+func (tm *tracing) ImportJobState(ctx context.Context, data []byte) error {
+	ctx, span := tm.tracer.Start(ctx, "import-job-state", trace.WithAttributes(
+		attribute.Int("bytes", len(data)),
+	))
+	defer span.End()
+
+	return tm.svc.ImportJobState(ctx, data)
+}
+
+func (tm *tracing) DebugFLState(ctx context.Context) (manager.FLDebugState, error) {
+	ctx, span := tm.tracer.Start(ctx, "debug-fl-state")
+	defer span.End()
+
+	return tm.svc.DebugFLState(ctx)
+}
+
 func (tm *tracing) Shutdown(ctx context.Context) (err error) {
 	ctx, span := tm.tracer.Start(ctx, "shutdown")
 	defer span.End()
@@ -0,0 +1,86 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager/middleware"
+	managermocks "github.com/absmach/propeller/manager/mocks"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+
+	return attrs
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("manager-test")
+
+	svc := managermocks.NewMockService(t)
+	svc.On("CreateTask", mock.Anything, mock.Anything).Return(task.Task{ID: "task-1", Name: "train", JobID: "job-1"}, nil).Once()
+	svc.On("StartTask", mock.Anything, "task-1").Return(nil).Once()
+	svc.On("GetJob", mock.Anything, "job-1").Return([]task.Task{}, nil).Once()
+	tm := middleware.Tracing(tracer, svc)
+
+	ctx, parent := tracer.Start(context.Background(), "http-request")
+	_, err := tm.CreateTask(ctx, task.Task{Name: "train", JobID: "job-1"})
+	require.NoError(t, err)
+	require.NoError(t, tm.StartTask(ctx, "task-1"))
+	_, err = tm.GetJob(ctx, "job-1")
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	want := []struct {
+		name  string
+		attrs map[attribute.Key]string
+	}{
+		{name: "create-task", attrs: map[attribute.Key]string{"task_id": "task-1", "job_id": "job-1", "name": "train"}},
+		{name: "start-task", attrs: map[attribute.Key]string{"task_id": "task-1"}},
+		{name: "get-job", attrs: map[attribute.Key]string{"job_id": "job-1"}},
+	}
+	for i, w := range want {
+		span := spans[i]
+		assert.Equal(t, w.name, span.Name())
+		assert.Equal(t, w.attrs, spanAttributes(span))
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID(), "%s is a child of the request span", w.name)
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+}
+
+func TestTracingMiddlewarePropagatesSpan(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("manager-test")
+
+	var inner trace.SpanContext
+	svc := managermocks.NewMockService(t)
+	svc.On("StopTask", mock.Anything, "task-1").Return(nil).Run(func(args mock.Arguments) {
+		inner = trace.SpanContextFromContext(args.Get(0).(context.Context))
+	}).Once()
+
+	require.NoError(t, middleware.Tracing(tracer, svc).StopTask(context.Background(), "task-1"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, spans[0].SpanContext().SpanID(), inner.SpanID(), "the service runs inside the method span")
+}
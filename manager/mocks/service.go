@@ -8,6 +8,7 @@ import (
 	"context"
 
 	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/events"
 	"github.com/absmach/propeller/pkg/proplet"
 	"github.com/absmach/propeller/pkg/sdf"
 	"github.com/absmach/propeller/pkg/task"
@@ -318,6 +319,66 @@ func (_c *MockService_CreateWorkflow_Call) RunAndReturn(run func(ctx context.Con
 	return _c
 }
 
+// DebugFLState provides a mock function for the type MockService
+func (_mock *MockService) DebugFLState(ctx context.Context) (manager.FLDebugState, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DebugFLState")
+	}
+
+	var r0 manager.FLDebugState
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (manager.FLDebugState, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) manager.FLDebugState); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(manager.FLDebugState)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_DebugFLState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DebugFLState'
+type MockService_DebugFLState_Call struct {
+	*mock.Call
+}
+
+// DebugFLState is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockService_Expecter) DebugFLState(ctx interface{}) *MockService_DebugFLState_Call {
+	return &MockService_DebugFLState_Call{Call: _e.mock.On("DebugFLState", ctx)}
+}
+
+func (_c *MockService_DebugFLState_Call) Run(run func(ctx context.Context)) *MockService_DebugFLState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_DebugFLState_Call) Return(fLDebugState manager.FLDebugState, err error) *MockService_DebugFLState_Call {
+	_c.Call.Return(fLDebugState, err)
+	return _c
+}
+
+func (_c *MockService_DebugFLState_Call) RunAndReturn(run func(ctx context.Context) (manager.FLDebugState, error)) *MockService_DebugFLState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteProplet provides a mock function for the type MockService
 func (_mock *MockService) DeleteProplet(ctx context.Context, propletID string) error {
 	ret := _mock.Called(ctx, propletID)
@@ -432,6 +493,140 @@ func (_c *MockService_DeleteTask_Call) RunAndReturn(run func(ctx context.Context
 	return _c
 }
 
+// ExportJobState provides a mock function for the type MockService
+func (_mock *MockService) ExportJobState(ctx context.Context, jobID string) ([]byte, error) {
+	ret := _mock.Called(ctx, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportJobState")
+	}
+
+	var r0 []byte
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]byte, error)); ok {
+		return returnFunc(ctx, jobID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []byte); ok {
+		r0 = returnFunc(ctx, jobID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_ExportJobState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportJobState'
+type MockService_ExportJobState_Call struct {
+	*mock.Call
+}
+
+// ExportJobState is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jobID string
+func (_e *MockService_Expecter) ExportJobState(ctx interface{}, jobID interface{}) *MockService_ExportJobState_Call {
+	return &MockService_ExportJobState_Call{Call: _e.mock.On("ExportJobState", ctx, jobID)}
+}
+
+func (_c *MockService_ExportJobState_Call) Run(run func(ctx context.Context, jobID string)) *MockService_ExportJobState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_ExportJobState_Call) Return(bytes []byte, err error) *MockService_ExportJobState_Call {
+	_c.Call.Return(bytes, err)
+	return _c
+}
+
+func (_c *MockService_ExportJobState_Call) RunAndReturn(run func(ctx context.Context, jobID string) ([]byte, error)) *MockService_ExportJobState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetFLJobMetrics provides a mock function for the type MockService
+func (_mock *MockService) GetFLJobMetrics(ctx context.Context, jobID string) (manager.FLJobMetrics, error) {
+	ret := _mock.Called(ctx, jobID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFLJobMetrics")
+	}
+
+	var r0 manager.FLJobMetrics
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (manager.FLJobMetrics, error)); ok {
+		return returnFunc(ctx, jobID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) manager.FLJobMetrics); ok {
+		r0 = returnFunc(ctx, jobID)
+	} else {
+		r0 = ret.Get(0).(manager.FLJobMetrics)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, jobID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_GetFLJobMetrics_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFLJobMetrics'
+type MockService_GetFLJobMetrics_Call struct {
+	*mock.Call
+}
+
+// GetFLJobMetrics is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jobID string
+func (_e *MockService_Expecter) GetFLJobMetrics(ctx interface{}, jobID interface{}) *MockService_GetFLJobMetrics_Call {
+	return &MockService_GetFLJobMetrics_Call{Call: _e.mock.On("GetFLJobMetrics", ctx, jobID)}
+}
+
+func (_c *MockService_GetFLJobMetrics_Call) Run(run func(ctx context.Context, jobID string)) *MockService_GetFLJobMetrics_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_GetFLJobMetrics_Call) Return(fLJobMetrics manager.FLJobMetrics, err error) *MockService_GetFLJobMetrics_Call {
+	_c.Call.Return(fLJobMetrics, err)
+	return _c
+}
+
+func (_c *MockService_GetFLJobMetrics_Call) RunAndReturn(run func(ctx context.Context, jobID string) (manager.FLJobMetrics, error)) *MockService_GetFLJobMetrics_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetFLTask provides a mock function for the type MockService
 func (_mock *MockService) GetFLTask(ctx context.Context, roundID string, propletID string) (manager.FLTask, error) {
 	ret := _mock.Called(ctx, roundID, propletID)
@@ -572,6 +767,72 @@ func (_c *MockService_GetJob_Call) RunAndReturn(run func(ctx context.Context, jo
 	return _c
 }
 
+// GetModel provides a mock function for the type MockService
+func (_mock *MockService) GetModel(ctx context.Context, version int) (manager.Model, error) {
+	ret := _mock.Called(ctx, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetModel")
+	}
+
+	var r0 manager.Model
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) (manager.Model, error)); ok {
+		return returnFunc(ctx, version)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) manager.Model); ok {
+		r0 = returnFunc(ctx, version)
+	} else {
+		r0 = ret.Get(0).(manager.Model)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_GetModel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetModel'
+type MockService_GetModel_Call struct {
+	*mock.Call
+}
+
+// GetModel is a helper method to define mock.On call
+//   - ctx context.Context
+//   - version int
+func (_e *MockService_Expecter) GetModel(ctx interface{}, version interface{}) *MockService_GetModel_Call {
+	return &MockService_GetModel_Call{Call: _e.mock.On("GetModel", ctx, version)}
+}
+
+func (_c *MockService_GetModel_Call) Run(run func(ctx context.Context, version int)) *MockService_GetModel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_GetModel_Call) Return(model manager.Model, err error) *MockService_GetModel_Call {
+	_c.Call.Return(model, err)
+	return _c
+}
+
+func (_c *MockService_GetModel_Call) RunAndReturn(run func(ctx context.Context, version int) (manager.Model, error)) *MockService_GetModel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetParentResults provides a mock function for the type MockService
 func (_mock *MockService) GetParentResults(ctx context.Context, taskID string) (map[string]any, error) {
 	ret := _mock.Called(ctx, taskID)
@@ -640,6 +901,72 @@ func (_c *MockService_GetParentResults_Call) RunAndReturn(run func(ctx context.C
 	return _c
 }
 
+// GetPersonalizedModel provides a mock function for the type MockService
+func (_mock *MockService) GetPersonalizedModel(ctx context.Context, clientID string) (manager.PersonalizedModel, error) {
+	ret := _mock.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPersonalizedModel")
+	}
+
+	var r0 manager.PersonalizedModel
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (manager.PersonalizedModel, error)); ok {
+		return returnFunc(ctx, clientID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) manager.PersonalizedModel); ok {
+		r0 = returnFunc(ctx, clientID)
+	} else {
+		r0 = ret.Get(0).(manager.PersonalizedModel)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_GetPersonalizedModel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPersonalizedModel'
+type MockService_GetPersonalizedModel_Call struct {
+	*mock.Call
+}
+
+// GetPersonalizedModel is a helper method to define mock.On call
+//   - ctx context.Context
+//   - clientID string
+func (_e *MockService_Expecter) GetPersonalizedModel(ctx interface{}, clientID interface{}) *MockService_GetPersonalizedModel_Call {
+	return &MockService_GetPersonalizedModel_Call{Call: _e.mock.On("GetPersonalizedModel", ctx, clientID)}
+}
+
+func (_c *MockService_GetPersonalizedModel_Call) Run(run func(ctx context.Context, clientID string)) *MockService_GetPersonalizedModel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_GetPersonalizedModel_Call) Return(personalizedModel manager.PersonalizedModel, err error) *MockService_GetPersonalizedModel_Call {
+	_c.Call.Return(personalizedModel, err)
+	return _c
+}
+
+func (_c *MockService_GetPersonalizedModel_Call) RunAndReturn(run func(ctx context.Context, clientID string) (manager.PersonalizedModel, error)) *MockService_GetPersonalizedModel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetProplet provides a mock function for the type MockService
 func (_mock *MockService) GetProplet(ctx context.Context, propletID string) (proplet.Proplet, error) {
 	ret := _mock.Called(ctx, propletID)
@@ -1199,40 +1526,97 @@ func (_c *MockService_GetTaskResults_Call) RunAndReturn(run func(ctx context.Con
 	return _c
 }
 
-// ListJobs provides a mock function for the type MockService
-func (_mock *MockService) ListJobs(ctx context.Context, offset uint64, limit uint64, status string) (manager.JobPage, error) {
-	ret := _mock.Called(ctx, offset, limit, status)
+// ImportJobState provides a mock function for the type MockService
+func (_mock *MockService) ImportJobState(ctx context.Context, data []byte) error {
+	ret := _mock.Called(ctx, data)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListJobs")
+		panic("no return value specified for ImportJobState")
 	}
 
-	var r0 manager.JobPage
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, uint64, string) (manager.JobPage, error)); ok {
-		return returnFunc(ctx, offset, limit, status)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, uint64, string) manager.JobPage); ok {
-		r0 = returnFunc(ctx, offset, limit, status)
-	} else {
-		r0 = ret.Get(0).(manager.JobPage)
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, uint64, uint64, string) error); ok {
-		r1 = returnFunc(ctx, offset, limit, status)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []byte) error); ok {
+		r0 = returnFunc(ctx, data)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockService_ListJobs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListJobs'
-type MockService_ListJobs_Call struct {
+// MockService_ImportJobState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportJobState'
+type MockService_ImportJobState_Call struct {
 	*mock.Call
 }
 
-// ListJobs is a helper method to define mock.On call
+// ImportJobState is a helper method to define mock.On call
 //   - ctx context.Context
-//   - offset uint64
+//   - data []byte
+func (_e *MockService_Expecter) ImportJobState(ctx interface{}, data interface{}) *MockService_ImportJobState_Call {
+	return &MockService_ImportJobState_Call{Call: _e.mock.On("ImportJobState", ctx, data)}
+}
+
+func (_c *MockService_ImportJobState_Call) Run(run func(ctx context.Context, data []byte)) *MockService_ImportJobState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []byte
+		if args[1] != nil {
+			arg1 = args[1].([]byte)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_ImportJobState_Call) Return(err error) *MockService_ImportJobState_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockService_ImportJobState_Call) RunAndReturn(run func(ctx context.Context, data []byte) error) *MockService_ImportJobState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListJobs provides a mock function for the type MockService
+func (_mock *MockService) ListJobs(ctx context.Context, offset uint64, limit uint64, status string) (manager.JobPage, error) {
+	ret := _mock.Called(ctx, offset, limit, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListJobs")
+	}
+
+	var r0 manager.JobPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, uint64, string) (manager.JobPage, error)); ok {
+		return returnFunc(ctx, offset, limit, status)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, uint64, string) manager.JobPage); ok {
+		r0 = returnFunc(ctx, offset, limit, status)
+	} else {
+		r0 = ret.Get(0).(manager.JobPage)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint64, uint64, string) error); ok {
+		r1 = returnFunc(ctx, offset, limit, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_ListJobs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListJobs'
+type MockService_ListJobs_Call struct {
+	*mock.Call
+}
+
+// ListJobs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - offset uint64
 //   - limit uint64
 //   - status string
 func (_e *MockService_Expecter) ListJobs(ctx interface{}, offset interface{}, limit interface{}, status interface{}) *MockService_ListJobs_Call {
@@ -1278,8 +1662,8 @@ func (_c *MockService_ListJobs_Call) RunAndReturn(run func(ctx context.Context,
 }
 
 // ListProplets provides a mock function for the type MockService
-func (_mock *MockService) ListProplets(ctx context.Context, offset uint64, limit uint64, status string) (proplet.PropletPage, error) {
-	ret := _mock.Called(ctx, offset, limit, status)
+func (_mock *MockService) ListProplets(ctx context.Context, offset uint64, limit uint64, status string, sort manager.Sort) (proplet.PropletPage, error) {
+	ret := _mock.Called(ctx, offset, limit, status, sort)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListProplets")
@@ -1287,16 +1671,16 @@ func (_mock *MockService) ListProplets(ctx context.Context, offset uint64, limit
 
 	var r0 proplet.PropletPage
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, uint64, string) (proplet.PropletPage, error)); ok {
-		return returnFunc(ctx, offset, limit, status)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, uint64, string, manager.Sort) (proplet.PropletPage, error)); ok {
+		return returnFunc(ctx, offset, limit, status, sort)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, uint64, string) proplet.PropletPage); ok {
-		r0 = returnFunc(ctx, offset, limit, status)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint64, uint64, string, manager.Sort) proplet.PropletPage); ok {
+		r0 = returnFunc(ctx, offset, limit, status, sort)
 	} else {
 		r0 = ret.Get(0).(proplet.PropletPage)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, uint64, uint64, string) error); ok {
-		r1 = returnFunc(ctx, offset, limit, status)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint64, uint64, string, manager.Sort) error); ok {
+		r1 = returnFunc(ctx, offset, limit, status, sort)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1313,11 +1697,12 @@ type MockService_ListProplets_Call struct {
 //   - offset uint64
 //   - limit uint64
 //   - status string
-func (_e *MockService_Expecter) ListProplets(ctx interface{}, offset interface{}, limit interface{}, status interface{}) *MockService_ListProplets_Call {
-	return &MockService_ListProplets_Call{Call: _e.mock.On("ListProplets", ctx, offset, limit, status)}
+//   - sort manager.Sort
+func (_e *MockService_Expecter) ListProplets(ctx interface{}, offset interface{}, limit interface{}, status interface{}, sort interface{}) *MockService_ListProplets_Call {
+	return &MockService_ListProplets_Call{Call: _e.mock.On("ListProplets", ctx, offset, limit, status, sort)}
 }
 
-func (_c *MockService_ListProplets_Call) Run(run func(ctx context.Context, offset uint64, limit uint64, status string)) *MockService_ListProplets_Call {
+func (_c *MockService_ListProplets_Call) Run(run func(ctx context.Context, offset uint64, limit uint64, status string, sort manager.Sort)) *MockService_ListProplets_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -1335,11 +1720,16 @@ func (_c *MockService_ListProplets_Call) Run(run func(ctx context.Context, offse
 		if args[3] != nil {
 			arg3 = args[3].(string)
 		}
+		var arg4 manager.Sort
+		if args[4] != nil {
+			arg4 = args[4].(manager.Sort)
+		}
 		run(
 			arg0,
 			arg1,
 			arg2,
 			arg3,
+			arg4,
 		)
 	})
 	return _c
@@ -1350,7 +1740,7 @@ func (_c *MockService_ListProplets_Call) Return(propletPage proplet.PropletPage,
 	return _c
 }
 
-func (_c *MockService_ListProplets_Call) RunAndReturn(run func(ctx context.Context, offset uint64, limit uint64, status string) (proplet.PropletPage, error)) *MockService_ListProplets_Call {
+func (_c *MockService_ListProplets_Call) RunAndReturn(run func(ctx context.Context, offset uint64, limit uint64, status string, sort manager.Sort) (proplet.PropletPage, error)) *MockService_ListProplets_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -1535,6 +1925,72 @@ func (_c *MockService_PostFLUpdateCBOR_Call) RunAndReturn(run func(ctx context.C
 	return _c
 }
 
+// PurgeTasks provides a mock function for the type MockService
+func (_mock *MockService) PurgeTasks(ctx context.Context, filter manager.PurgeFilter) (uint64, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeTasks")
+	}
+
+	var r0 uint64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, manager.PurgeFilter) (uint64, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, manager.PurgeFilter) uint64); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, manager.PurgeFilter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_PurgeTasks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeTasks'
+type MockService_PurgeTasks_Call struct {
+	*mock.Call
+}
+
+// PurgeTasks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter manager.PurgeFilter
+func (_e *MockService_Expecter) PurgeTasks(ctx interface{}, filter interface{}) *MockService_PurgeTasks_Call {
+	return &MockService_PurgeTasks_Call{Call: _e.mock.On("PurgeTasks", ctx, filter)}
+}
+
+func (_c *MockService_PurgeTasks_Call) Run(run func(ctx context.Context, filter manager.PurgeFilter)) *MockService_PurgeTasks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 manager.PurgeFilter
+		if args[1] != nil {
+			arg1 = args[1].(manager.PurgeFilter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_PurgeTasks_Call) Return(v uint64, err error) *MockService_PurgeTasks_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockService_PurgeTasks_Call) RunAndReturn(run func(ctx context.Context, filter manager.PurgeFilter) (uint64, error)) *MockService_PurgeTasks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RecoverInterruptedTasks provides a mock function for the type MockService
 func (_mock *MockService) RecoverInterruptedTasks(ctx context.Context) error {
 	ret := _mock.Called(ctx)
@@ -1586,6 +2042,63 @@ func (_c *MockService_RecoverInterruptedTasks_Call) RunAndReturn(run func(ctx co
 	return _c
 }
 
+// RollbackModel provides a mock function for the type MockService
+func (_mock *MockService) RollbackModel(ctx context.Context, version int) error {
+	ret := _mock.Called(ctx, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RollbackModel")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = returnFunc(ctx, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockService_RollbackModel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RollbackModel'
+type MockService_RollbackModel_Call struct {
+	*mock.Call
+}
+
+// RollbackModel is a helper method to define mock.On call
+//   - ctx context.Context
+//   - version int
+func (_e *MockService_Expecter) RollbackModel(ctx interface{}, version interface{}) *MockService_RollbackModel_Call {
+	return &MockService_RollbackModel_Call{Call: _e.mock.On("RollbackModel", ctx, version)}
+}
+
+func (_c *MockService_RollbackModel_Call) Run(run func(ctx context.Context, version int)) *MockService_RollbackModel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_RollbackModel_Call) Return(err error) *MockService_RollbackModel_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockService_RollbackModel_Call) RunAndReturn(run func(ctx context.Context, version int) error) *MockService_RollbackModel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SelectProplet provides a mock function for the type MockService
 func (_mock *MockService) SelectProplet(ctx context.Context, task1 task.Task) (proplet.Proplet, error) {
 	ret := _mock.Called(ctx, task1)
@@ -1652,6 +2165,78 @@ func (_c *MockService_SelectProplet_Call) RunAndReturn(run func(ctx context.Cont
 	return _c
 }
 
+// SetPropletLabels provides a mock function for the type MockService
+func (_mock *MockService) SetPropletLabels(ctx context.Context, propletID string, labels map[string]string) (proplet.Proplet, error) {
+	ret := _mock.Called(ctx, propletID, labels)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPropletLabels")
+	}
+
+	var r0 proplet.Proplet
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, map[string]string) (proplet.Proplet, error)); ok {
+		return returnFunc(ctx, propletID, labels)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, map[string]string) proplet.Proplet); ok {
+		r0 = returnFunc(ctx, propletID, labels)
+	} else {
+		r0 = ret.Get(0).(proplet.Proplet)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, map[string]string) error); ok {
+		r1 = returnFunc(ctx, propletID, labels)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_SetPropletLabels_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPropletLabels'
+type MockService_SetPropletLabels_Call struct {
+	*mock.Call
+}
+
+// SetPropletLabels is a helper method to define mock.On call
+//   - ctx context.Context
+//   - propletID string
+//   - labels map[string]string
+func (_e *MockService_Expecter) SetPropletLabels(ctx interface{}, propletID interface{}, labels interface{}) *MockService_SetPropletLabels_Call {
+	return &MockService_SetPropletLabels_Call{Call: _e.mock.On("SetPropletLabels", ctx, propletID, labels)}
+}
+
+func (_c *MockService_SetPropletLabels_Call) Run(run func(ctx context.Context, propletID string, labels map[string]string)) *MockService_SetPropletLabels_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 map[string]string
+		if args[2] != nil {
+			arg2 = args[2].(map[string]string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_SetPropletLabels_Call) Return(proplet1 proplet.Proplet, err error) *MockService_SetPropletLabels_Call {
+	_c.Call.Return(proplet1, err)
+	return _c
+}
+
+func (_c *MockService_SetPropletLabels_Call) RunAndReturn(run func(ctx context.Context, propletID string, labels map[string]string) (proplet.Proplet, error)) *MockService_SetPropletLabels_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Shutdown provides a mock function for the type MockService
 func (_mock *MockService) Shutdown(ctx context.Context) error {
 	ret := _mock.Called(ctx)
@@ -1931,6 +2516,132 @@ func (_c *MockService_StopTask_Call) RunAndReturn(run func(ctx context.Context,
 	return _c
 }
 
+// StoreModel provides a mock function for the type MockService
+func (_mock *MockService) StoreModel(ctx context.Context, version int, model manager.Model) error {
+	ret := _mock.Called(ctx, version, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StoreModel")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, manager.Model) error); ok {
+		r0 = returnFunc(ctx, version, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockService_StoreModel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StoreModel'
+type MockService_StoreModel_Call struct {
+	*mock.Call
+}
+
+// StoreModel is a helper method to define mock.On call
+//   - ctx context.Context
+//   - version int
+//   - model manager.Model
+func (_e *MockService_Expecter) StoreModel(ctx interface{}, version interface{}, model interface{}) *MockService_StoreModel_Call {
+	return &MockService_StoreModel_Call{Call: _e.mock.On("StoreModel", ctx, version, model)}
+}
+
+func (_c *MockService_StoreModel_Call) Run(run func(ctx context.Context, version int, model manager.Model)) *MockService_StoreModel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 manager.Model
+		if args[2] != nil {
+			arg2 = args[2].(manager.Model)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_StoreModel_Call) Return(err error) *MockService_StoreModel_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockService_StoreModel_Call) RunAndReturn(run func(ctx context.Context, version int, model manager.Model) error) *MockService_StoreModel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StorePersonalizedModel provides a mock function for the type MockService
+func (_mock *MockService) StorePersonalizedModel(ctx context.Context, clientID string, model manager.Model) error {
+	ret := _mock.Called(ctx, clientID, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StorePersonalizedModel")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, manager.Model) error); ok {
+		r0 = returnFunc(ctx, clientID, model)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockService_StorePersonalizedModel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StorePersonalizedModel'
+type MockService_StorePersonalizedModel_Call struct {
+	*mock.Call
+}
+
+// StorePersonalizedModel is a helper method to define mock.On call
+//   - ctx context.Context
+//   - clientID string
+//   - model manager.Model
+func (_e *MockService_Expecter) StorePersonalizedModel(ctx interface{}, clientID interface{}, model interface{}) *MockService_StorePersonalizedModel_Call {
+	return &MockService_StorePersonalizedModel_Call{Call: _e.mock.On("StorePersonalizedModel", ctx, clientID, model)}
+}
+
+func (_c *MockService_StorePersonalizedModel_Call) Run(run func(ctx context.Context, clientID string, model manager.Model)) *MockService_StorePersonalizedModel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 manager.Model
+		if args[2] != nil {
+			arg2 = args[2].(manager.Model)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_StorePersonalizedModel_Call) Return(err error) *MockService_StorePersonalizedModel_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockService_StorePersonalizedModel_Call) RunAndReturn(run func(ctx context.Context, clientID string, model manager.Model) error) *MockService_StorePersonalizedModel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Subscribe provides a mock function for the type MockService
 func (_mock *MockService) Subscribe(ctx context.Context) error {
 	ret := _mock.Called(ctx)
@@ -1982,6 +2693,74 @@ func (_c *MockService_Subscribe_Call) RunAndReturn(run func(ctx context.Context)
 	return _c
 }
 
+// SubscribeEvents provides a mock function for the type MockService
+func (_mock *MockService) SubscribeEvents(ctx context.Context, filter events.Filter) (*events.Subscription, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubscribeEvents")
+	}
+
+	var r0 *events.Subscription
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, events.Filter) (*events.Subscription, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, events.Filter) *events.Subscription); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*events.Subscription)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, events.Filter) error); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_SubscribeEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SubscribeEvents'
+type MockService_SubscribeEvents_Call struct {
+	*mock.Call
+}
+
+// SubscribeEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter events.Filter
+func (_e *MockService_Expecter) SubscribeEvents(ctx interface{}, filter interface{}) *MockService_SubscribeEvents_Call {
+	return &MockService_SubscribeEvents_Call{Call: _e.mock.On("SubscribeEvents", ctx, filter)}
+}
+
+func (_c *MockService_SubscribeEvents_Call) Run(run func(ctx context.Context, filter events.Filter)) *MockService_SubscribeEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 events.Filter
+		if args[1] != nil {
+			arg1 = args[1].(events.Filter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockService_SubscribeEvents_Call) Return(subscription *events.Subscription, err error) *MockService_SubscribeEvents_Call {
+	_c.Call.Return(subscription, err)
+	return _c
+}
+
+func (_c *MockService_SubscribeEvents_Call) RunAndReturn(run func(ctx context.Context, filter events.Filter) (*events.Subscription, error)) *MockService_SubscribeEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateTask provides a mock function for the type MockService
 func (_mock *MockService) UpdateTask(ctx context.Context, task1 task.Task) (task.Task, error) {
 	ret := _mock.Called(ctx, task1)
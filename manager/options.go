@@ -0,0 +1,201 @@
+package manager
+
+import (
+	"github.com/absmach/propeller/pkg/audit"
+	"github.com/absmach/propeller/pkg/mqtt"
+)
+
+// Option configures the service built by NewService. Options are set from the
+// manager's environment configuration; without them every feature uses its
+// defaults.
+type Option func(*options)
+
+type options struct {
+	redelivery RedeliveryConfig
+	dedup      DedupConfig
+	// checkpointRepository is the OCI repository aggregated FL models are
+	// exported to. Export is disabled when it is empty.
+	checkpointRepository string
+	registry             RegistryConfig
+	metricsIngest        MetricsIngestConfig
+	models               ModelsConfig
+	ingestLimit          IngestLimitConfig
+	identities           PublisherIdentityConfig
+	signing              SigningConfig
+	orphans              OrphanConfig
+	defaultEnv           map[string]string
+	secrets              SecretStore
+	sensitiveKeys        []string
+	breakers             BreakerConfig
+	transform            UpdateTransformConfig
+	maxPropletCPUPercent float64
+	topicPrefix          string
+	auditLog             audit.AuditLog
+}
+
+func defaultOptions() options {
+	return options{
+		redelivery: RedeliveryConfig{
+			Attempts:  defaultRetryAttempts,
+			Backoff:   defaultRetryBackoff,
+			QueueSize: defaultRetryQueueSize,
+		},
+		dedup: DedupConfig{
+			Window: defaultDedupWindow,
+			Size:   defaultDedupSize,
+		},
+		secrets:       NewEnvSecretStore(defaultSecretPrefix),
+		sensitiveKeys: ParseSensitiveEnvKeys(defaultSensitiveEnvKeys),
+		orphans: OrphanConfig{
+			Confirmations: defaultOrphanConfirmations,
+			Grace:         defaultOrphanGrace,
+		},
+		metricsIngest: MetricsIngestConfig{
+			Buffer:     defaultMetricsBuffer,
+			FullPolicy: MetricsPolicyDrop,
+		},
+		topicPrefix: mqtt.DefaultTopicPrefix,
+		auditLog:    audit.NewNopLog(),
+	}
+}
+
+// WithTopicPrefix sets the leading segment of the manager's MQTT topics,
+// "m" by default. Deployments sharing a broker pick distinct prefixes.
+func WithTopicPrefix(prefix string) Option {
+	return func(o *options) {
+		o.topicPrefix = prefix
+	}
+}
+
+// WithAuditLog records task and FL lifecycle actions to auditLog. Nothing is
+// recorded by default.
+func WithAuditLog(auditLog audit.AuditLog) Option {
+	return func(o *options) {
+		o.auditLog = auditLog
+	}
+}
+
+// WithRedelivery sets how failed result messages are retried.
+func WithRedelivery(cfg RedeliveryConfig) Option {
+	return func(o *options) {
+		o.redelivery = cfg
+	}
+}
+
+// WithDedup sets how long results and metrics message IDs are remembered to
+// drop redeliveries.
+func WithDedup(cfg DedupConfig) Option {
+	return func(o *options) {
+		o.dedup = cfg
+	}
+}
+
+// WithCheckpointRepository exports each round's aggregated FL model to the
+// OCI repository, e.g. "registry.example.com/fl/model".
+func WithCheckpointRepository(repository string) Option {
+	return func(o *options) {
+		o.checkpointRepository = repository
+	}
+}
+
+// WithRegistry sets how the OCI registries holding FL models are reached.
+func WithRegistry(cfg RegistryConfig) Option {
+	return func(o *options) {
+		o.registry = cfg
+	}
+}
+
+// WithMetricsIngest persists task and proplet metrics on a pool of workers.
+func WithMetricsIngest(cfg MetricsIngestConfig) Option {
+	return func(o *options) {
+		o.metricsIngest = cfg
+	}
+}
+
+// WithModels sets where aggregated FL global models are kept and how many
+// versions are retained.
+func WithModels(cfg ModelsConfig) Option {
+	return func(o *options) {
+		o.models = cfg
+	}
+}
+
+// WithIngestLimit rate-limits the results and metrics each proplet publishes.
+func WithIngestLimit(cfg IngestLimitConfig) Option {
+	return func(o *options) {
+		o.ingestLimit = cfg
+	}
+}
+
+// WithPublisherIdentity sets how results are checked against the MQTT
+// identity that published them.
+func WithPublisherIdentity(cfg PublisherIdentityConfig) Option {
+	return func(o *options) {
+		o.identities = cfg
+	}
+}
+
+// WithSigning verifies the HMAC signatures of FL update envelopes.
+func WithSigning(cfg SigningConfig) Option {
+	return func(o *options) {
+		o.signing = cfg
+	}
+}
+
+// WithOrphans sets in how many heartbeats, and for how long, a task must be
+// missing from its proplet before it is failed as lost.
+func WithOrphans(cfg OrphanConfig) Option {
+	return func(o *options) {
+		o.orphans = cfg
+	}
+}
+
+// WithDefaultTaskEnv injects env into every task when it is started. A task's
+// own env wins over a default of the same name.
+func WithDefaultTaskEnv(env map[string]string) Option {
+	return func(o *options) {
+		o.defaultEnv = env
+	}
+}
+
+// WithSecretStore resolves task secret refs from store.
+func WithSecretStore(store SecretStore) Option {
+	return func(o *options) {
+		o.secrets = store
+	}
+}
+
+// WithSensitiveEnvKeys redacts the values of task env keys matching any of
+// patterns, as returned by ParseSensitiveEnvKeys, from the service's log
+// output. Values are still delivered to the proplet. No patterns disables
+// redaction.
+func WithSensitiveEnvKeys(patterns []string) Option {
+	return func(o *options) {
+		o.sensitiveKeys = patterns
+	}
+}
+
+// WithBreaker configures the per-proplet circuit breaker. It is disabled by
+// default.
+func WithBreaker(cfg BreakerConfig) Option {
+	return func(o *options) {
+		o.breakers = cfg
+	}
+}
+
+// WithUpdateTransform selects the transform applied to every FL update
+// before it is aggregated. Updates are left unchanged by default.
+func WithUpdateTransform(cfg UpdateTransformConfig) Option {
+	return func(o *options) {
+		o.transform = cfg
+	}
+}
+
+// WithMaxPropletCPUPercent caps the CPU usage (100 = one core) above which a
+// proplet stops receiving new tasks. Zero, the default, disables the CPU
+// check.
+func WithMaxPropletCPUPercent(percent float64) Option {
+	return func(o *options) {
+		o.maxPropletCPUPercent = percent
+	}
+}
@@ -0,0 +1,123 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	ingestResults      = "results"
+	ingestTaskMetrics  = "task_metrics"
+	ingestPropletStats = "metrics"
+
+	maxTrackedProplets = 4096
+)
+
+var rateLimitedMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "manager",
+	Subsystem: "ingest",
+	Name:      "rate_limited_total",
+	Help:      "Results and metrics messages dropped by the per-proplet rate limit.",
+}, []string{"kind"})
+
+// IngestLimitConfig configures the per-proplet rate limit of results and
+// metrics messages.
+type IngestLimitConfig struct {
+	// Rate is how many results and metrics messages per second each proplet
+	// may publish; excess messages are dropped. Zero, the default, disables
+	// rate limiting.
+	Rate float64 `env:"MANAGER_INGEST_RATE" envDefault:"0"`
+	// Burst is how many messages a proplet may publish at once before Rate
+	// applies. Zero defaults it to the rate, rounded up.
+	Burst int `env:"MANAGER_INGEST_BURST" envDefault:"0"`
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// ingestLimiter is a token bucket per proplet and message kind guarding the
+// ingestion of results and metrics against proplets publishing in a tight
+// loop.
+type ingestLimiter struct {
+	logger  *slog.Logger
+	rate    float64
+	burst   float64
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	dropped map[string]uint64
+}
+
+// newIngestLimiter returns nil when no rate is configured.
+func newIngestLimiter(cfg IngestLimitConfig, logger *slog.Logger) *ingestLimiter {
+	rate := cfg.Rate
+	if rate <= 0 {
+		return nil
+	}
+	burst := math.Ceil(rate)
+	if cfg.Burst > 0 {
+		burst = float64(cfg.Burst)
+	}
+
+	return &ingestLimiter{
+		logger:  logger,
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*bucket),
+		dropped: make(map[string]uint64),
+	}
+}
+
+// allow takes a token from the bucket of propletID for kind and reports
+// whether the message may be processed. Messages without a proplet ID are
+// not limited.
+func (l *ingestLimiter) allow(ctx context.Context, kind, propletID string) bool {
+	if l == nil || propletID == "" {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	key := kind + "/" + propletID
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxTrackedProplets {
+			l.evictFull(now)
+		}
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return true
+	}
+
+	l.dropped[kind]++
+	rateLimitedMessages.WithLabelValues(kind).Inc()
+	l.logger.WarnContext(ctx, "proplet exceeded ingest rate, dropping message",
+		"kind", kind, "proplet_id", propletID, "dropped_total", l.dropped[kind])
+
+	return false
+}
+
+// evictFull forgets buckets that have refilled, which behave exactly like
+// new ones.
+func (l *ingestLimiter) evictFull(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
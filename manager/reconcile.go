@@ -0,0 +1,177 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/absmach/propeller/pkg/task"
+)
+
+const (
+	defaultOrphanConfirmations = 3
+	defaultOrphanGrace         = 30 * time.Second
+	taskIDsKey                 = "task_ids"
+)
+
+// OrphanConfig configures how tasks lost on their proplet are detected.
+type OrphanConfig struct {
+	// Confirmations is how many consecutive heartbeats from a proplet must
+	// omit a task the manager has running on it before the task is failed
+	// as lost. Zero disables orphan detection.
+	Confirmations int `env:"MANAGER_ORPHAN_CONFIRMATIONS" envDefault:"3"`
+	// Grace is the least time a task must have been missing from its
+	// proplet's heartbeats, so a burst of heartbeats cannot fail it early.
+	Grace time.Duration `env:"MANAGER_ORPHAN_GRACE" envDefault:"30s"`
+}
+
+type missingTask struct {
+	since time.Time
+	count int
+}
+
+// orphanTracker remembers, per proplet, since when and in how many
+// consecutive heartbeats each of its running tasks has been missing. Waiting
+// for several confirmations tolerates heartbeats racing a start that has not
+// reached the proplet yet, or results that are still in flight.
+type orphanTracker struct {
+	mu            sync.Mutex
+	confirmations int
+	grace         time.Duration
+	missing       map[string]map[string]missingTask
+}
+
+// newOrphanTracker returns nil when orphan detection is disabled.
+func newOrphanTracker(cfg OrphanConfig) *orphanTracker {
+	confirmations := cfg.Confirmations
+	if confirmations < 0 {
+		confirmations = defaultOrphanConfirmations
+	}
+	if confirmations == 0 {
+		return nil
+	}
+	grace := cfg.Grace
+	if grace < 0 {
+		grace = defaultOrphanGrace
+	}
+
+	return &orphanTracker{
+		confirmations: confirmations,
+		grace:         grace,
+		missing:       make(map[string]map[string]missingTask),
+	}
+}
+
+// observe compares the tasks the manager has running on propletID with the
+// ones the proplet reported and returns those missing from enough
+// consecutive heartbeats for long enough. A task that is reported again
+// starts over.
+func (o *orphanTracker) observe(propletID string, assigned []string, reported map[string]bool, now time.Time) []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	prev := o.missing[propletID]
+	missing := make(map[string]missingTask)
+	var orphaned []string
+	for _, id := range assigned {
+		if reported[id] {
+			continue
+		}
+		m, ok := prev[id]
+		if !ok {
+			m.since = now
+		}
+		m.count++
+		if m.count >= o.confirmations && now.Sub(m.since) >= o.grace {
+			orphaned = append(orphaned, id)
+
+			continue
+		}
+		missing[id] = m
+	}
+
+	if len(missing) == 0 {
+		delete(o.missing, propletID)
+	} else {
+		o.missing[propletID] = missing
+	}
+
+	return orphaned
+}
+
+// reportedTaskIDs returns the task IDs a heartbeat lists as running, or false
+// for proplets that do not report them.
+func reportedTaskIDs(msg map[string]any) ([]string, bool) {
+	raw, ok := msg[taskIDsKey].([]any)
+	if !ok {
+		return nil, false
+	}
+
+	ids := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if id, ok := v.(string); ok && id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, true
+}
+
+// reconcileProplet aligns the proplet's task count with the tasks it reports
+// running and fails the tasks the manager believes it runs but the proplet
+// has lost.
+func (svc *service) reconcileProplet(ctx context.Context, p *proplet.Proplet, reported []string) {
+	p.TaskCount = uint64(len(reported))
+	if svc.orphans == nil {
+		return
+	}
+
+	state := task.Running
+	tasks, err := queryAllTasks(ctx, svc.taskRepo, task.Query{State: &state, PropletID: p.ID})
+	if err != nil {
+		svc.logger.WarnContext(ctx, "failed to list tasks for reconciliation", "proplet_id", p.ID, "error", err)
+
+		return
+	}
+
+	running := make(map[string]task.Task)
+	assigned := make([]string, 0)
+	for _, t := range tasks {
+		if !t.Broadcast {
+			running[t.ID] = t
+			assigned = append(assigned, t.ID)
+		}
+	}
+	reportedSet := make(map[string]bool, len(reported))
+	for _, id := range reported {
+		reportedSet[id] = true
+	}
+
+	for _, id := range svc.orphans.observe(p.ID, assigned, reportedSet, time.Now()) {
+		svc.failOrphanedTask(ctx, running[id], p.ID)
+	}
+}
+
+func (svc *service) failOrphanedTask(ctx context.Context, t task.Task, propletID string) {
+	oldState := t.State.String()
+	now := time.Now()
+	t.State = task.Failed
+	t.Error = fmt.Sprintf("lost on proplet %s: missing from %d consecutive heartbeats", propletID, svc.orphans.confirmations)
+	t.FinishTime = now
+	t.UpdatedAt = now
+	if err := svc.taskRepo.Update(ctx, t); err != nil {
+		svc.logger.ErrorContext(ctx, "failed to fail orphaned task", "task_id", t.ID, "proplet_id", propletID, "error", err)
+
+		return
+	}
+	if err := svc.taskPropletRepo.Delete(ctx, t.ID); err != nil {
+		svc.logger.WarnContext(ctx, "failed to unpin orphaned task", "task_id", t.ID, "error", err)
+	}
+	svc.load.release(t.ID)
+
+	svc.logger.WarnContext(ctx, "task lost on proplet", "task_id", t.ID, "proplet_id", propletID)
+	svc.auditTask(ctx, "orphan", t, oldState)
+	svc.taskFinished(ctx, t)
+}
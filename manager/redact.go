@@ -0,0 +1,144 @@
+package manager
+
+import (
+	"context"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/absmach/propeller/pkg/task"
+)
+
+const (
+	defaultSensitiveEnvKeys = "*_PASSWORD,*_TOKEN,*_SECRET,*_KEY"
+	redactedValue           = "[REDACTED]"
+)
+
+// ParseSensitiveEnvKeys splits a comma-separated list of glob patterns,
+// matched case-insensitively, of sensitive task env keys.
+func ParseSensitiveEnvKeys(value string) []string {
+	var patterns []string
+	for pattern := range strings.SplitSeq(value, ",") {
+		if pattern = strings.ToUpper(strings.TrimSpace(pattern)); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns
+}
+
+// EnvRedactor replaces the values of sensitive task env keys so tasks can be
+// returned to API clients without disclosing them.
+type EnvRedactor struct {
+	patterns []string
+}
+
+// NewEnvRedactor returns an EnvRedactor for keys matching any of patterns,
+// as returned by ParseSensitiveEnvKeys.
+func NewEnvRedactor(patterns []string) EnvRedactor {
+	return EnvRedactor{patterns: patterns}
+}
+
+// Sensitive reports whether key matches one of the redactor's patterns.
+func (r EnvRedactor) Sensitive(key string) bool {
+	key = strings.ToUpper(key)
+	for _, pattern := range r.patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Env returns a copy of env with the values of sensitive keys replaced.
+func (r EnvRedactor) Env(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		if r.Sensitive(k) {
+			v = redactedValue
+		}
+		out[k] = v
+	}
+
+	return out
+}
+
+// Task returns a copy of t whose env has sensitive values replaced, along
+// with any variable the task also resolves from a secret ref.
+func (r EnvRedactor) Task(t task.Task) task.Task {
+	env := r.Env(t.Env)
+	for name := range t.SecretRefs {
+		if _, ok := env[name]; ok {
+			env[name] = redactedValue
+		}
+	}
+	t.Env = env
+
+	return t
+}
+
+// redactingHandler replaces the values of sensitive log attributes, and of
+// sensitive keys in logged env maps, before passing records on.
+type redactingHandler struct {
+	slog.Handler
+	redactor EnvRedactor
+}
+
+func newRedactingHandler(h slog.Handler, patterns []string) slog.Handler {
+	if len(patterns) == 0 {
+		return h
+	}
+
+	return &redactingHandler{Handler: h, redactor: NewEnvRedactor(patterns)}
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.redact(a))
+
+		return true
+	})
+
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redact(a)
+	}
+
+	return &redactingHandler{Handler: h.Handler.WithAttrs(redacted), redactor: h.redactor}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{Handler: h.Handler.WithGroup(name), redactor: h.redactor}
+}
+
+func (h *redactingHandler) redact(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if h.redactor.Sensitive(a.Key) {
+		return slog.String(a.Key, redactedValue)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = h.redact(ga)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		if env, ok := a.Value.Any().(map[string]string); ok {
+			a.Value = slog.AnyValue(h.redactor.Env(env))
+		}
+	}
+
+	return a
+}
@@ -0,0 +1,173 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/mqtt"
+	"github.com/absmach/propeller/pkg/storage"
+)
+
+const (
+	defaultRetryAttempts  = 5
+	defaultRetryBackoff   = 200 * time.Millisecond
+	defaultRetryQueueSize = 256
+	maxRetryBackoff       = 10 * time.Second
+)
+
+// RedeliveryConfig configures the retries of failed result messages.
+type RedeliveryConfig struct {
+	// Attempts is how many times a failed result message is retried before
+	// it is dead-lettered. Zero disables retries.
+	Attempts int `env:"MANAGER_RESULT_RETRY_ATTEMPTS" envDefault:"5"`
+	// Backoff is the delay before the first retry; it doubles on every
+	// attempt up to maxRetryBackoff.
+	Backoff time.Duration `env:"MANAGER_RESULT_RETRY_BACKOFF" envDefault:"200ms"`
+	// QueueSize bounds how many messages may be awaiting a retry at once.
+	QueueSize int `env:"MANAGER_RESULT_RETRY_QUEUE_SIZE" envDefault:"256"`
+}
+
+// redelivery retries MQTT handler invocations that failed transiently, so a
+// storage hiccup does not lose a proplet's results. Messages that still fail
+// after the last attempt, or are pending at shutdown, are dead-lettered to
+// the log with their payload and handed to onDeadLetter.
+type redelivery struct {
+	logger       *slog.Logger
+	onDeadLetter func(ctx context.Context, topic string, msg map[string]any, err error)
+	attempts     int
+	backoff      time.Duration
+	slots        chan struct{}
+	stop         chan struct{}
+	stopOnce     sync.Once
+	wg           sync.WaitGroup
+}
+
+func newRedelivery(cfg RedeliveryConfig, logger *slog.Logger, onDeadLetter func(ctx context.Context, topic string, msg map[string]any, err error)) *redelivery {
+	attempts := cfg.Attempts
+	if attempts < 0 {
+		attempts = defaultRetryAttempts
+	}
+	backoff := cfg.Backoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	size := cfg.QueueSize
+	if size <= 0 {
+		size = defaultRetryQueueSize
+	}
+
+	return &redelivery{
+		logger:       logger,
+		onDeadLetter: onDeadLetter,
+		attempts:     attempts,
+		backoff:      backoff,
+		slots:        make(chan struct{}, size),
+		stop:         make(chan struct{}),
+	}
+}
+
+// retryable reports whether err may go away on a later attempt. Malformed
+// messages and unknown entities will not.
+func retryable(err error) bool {
+	return !errors.Is(err, pkgerrors.ErrInvalidData) &&
+		!errors.Is(err, pkgerrors.ErrInvalidValue) &&
+		!errors.Is(err, pkgerrors.ErrNotFound) &&
+		!errors.Is(err, storage.ErrTaskNotFound)
+}
+
+// submit schedules fn to be retried after it failed with err. It returns
+// false when the message is not retried: the error is permanent, retries are
+// disabled, the queue is full or the manager is stopping.
+func (r *redelivery) submit(ctx context.Context, topic string, msg map[string]any, err error, fn func() error) bool {
+	if r.attempts == 0 || !retryable(err) {
+		return false
+	}
+	select {
+	case <-r.stop:
+		return false
+	default:
+	}
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		r.logger.WarnContext(ctx, "redelivery queue full, not retrying message", "topic", topic, "error", err)
+
+		return false
+	}
+
+	r.wg.Go(func() {
+		defer func() { <-r.slots }()
+		r.run(ctx, topic, msg, err, fn)
+	})
+
+	return true
+}
+
+func (r *redelivery) run(ctx context.Context, topic string, msg map[string]any, err error, fn func() error) {
+	delay := r.backoff
+	for attempt := 1; attempt <= r.attempts; attempt++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.stop:
+			timer.Stop()
+			r.deadLetter(ctx, topic, msg, attempt-1, err)
+
+			return
+		case <-timer.C:
+		}
+
+		if err = fn(); err == nil {
+			r.logger.InfoContext(ctx, "redelivered MQTT message", "topic", topic, "attempt", attempt)
+
+			return
+		}
+		if !retryable(err) {
+			r.deadLetter(ctx, topic, msg, attempt, err)
+
+			return
+		}
+		r.logger.WarnContext(ctx, "redelivery attempt failed",
+			"topic", topic, "attempt", attempt, "max_attempts", r.attempts, "error", err)
+		delay = min(delay*2, maxRetryBackoff)
+	}
+
+	r.deadLetter(ctx, topic, msg, r.attempts, err)
+}
+
+func (r *redelivery) deadLetter(ctx context.Context, topic string, msg map[string]any, attempts int, err error) {
+	r.logger.ErrorContext(ctx, "dead-lettered MQTT message",
+		"topic", topic, "attempts", attempts, "error", err, "payload", msg)
+	if r.onDeadLetter != nil {
+		r.onDeadLetter(ctx, topic, msg, err)
+	}
+}
+
+// publishDeadLetter republishes a message the manager gave up on to the
+// dead-letter topic, when the PubSub has one configured.
+func (svc *service) publishDeadLetter(ctx context.Context, topic string, msg map[string]any, cause error) {
+	dl, ok := svc.pubsub.(mqtt.DeadLetterer)
+	if !ok {
+		return
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		svc.logger.WarnContext(ctx, "failed to encode dead-lettered message", "topic", topic, "error", err)
+
+		return
+	}
+	if err := dl.DeadLetter(ctx, topic, payload, cause); err != nil {
+		svc.logger.WarnContext(ctx, "failed to publish dead-lettered message", "topic", topic, "error", err)
+	}
+}
+
+// close stops pending retries, dead-lettering their messages, and waits for
+// them to finish.
+func (r *redelivery) close() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	r.wg.Wait()
+}
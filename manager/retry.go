@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/task"
+)
+
+func validateRetryPolicy(t task.Task) error {
+	if t.RetryPolicy == nil {
+		return nil
+	}
+	if t.Broadcast {
+		return fmt.Errorf("%w: task %s: retry_policy is not supported for broadcast tasks", pkgerrors.ErrInvalidValue, t.Name)
+	}
+	if t.RetryPolicy.MaxAttempts < 0 || t.RetryPolicy.Backoff < 0 {
+		return fmt.Errorf("%w: task %s: negative retry_policy", pkgerrors.ErrInvalidValue, t.Name)
+	}
+
+	return nil
+}
+
+// canRetry reports whether the failed task t has attempts left under its
+// retry policy.
+func canRetry(t task.Task) bool {
+	return t.State == task.Failed && t.RetryPolicy != nil && t.Attempts < t.RetryPolicy.MaxAttempts
+}
+
+// scheduleRetry starts t again at its persisted RetryAt. t is left Pending in
+// the meantime; a retry that cannot be started fails the task for good.
+// StartTask keeps the proplet t last ran on, so FL train tasks are retried on
+// the proplet they are pinned to.
+func (svc *service) scheduleRetry(ctx context.Context, t task.Task) {
+	svc.logger.InfoContext(ctx, "retrying failed task",
+		"task_id", t.ID, "attempt", t.Attempts+1, "max_attempts", t.RetryPolicy.MaxAttempts,
+		"retry_at", t.RetryAt, "error", t.Error)
+
+	detached := context.WithoutCancel(ctx)
+	time.AfterFunc(time.Until(t.RetryAt), func() {
+		current, err := svc.GetTask(detached, t.ID)
+		if err != nil || current.State != task.Pending || current.Attempts != t.Attempts {
+			return
+		}
+		if err := svc.taskPropletRepo.Delete(detached, t.ID); err != nil {
+			svc.logger.WarnContext(detached, "failed to delete task proplet mapping", "task_id", t.ID, "error", err)
+		}
+		err = svc.StartTask(detached, t.ID)
+		if err == nil || errors.Is(err, errShuttingDown) {
+			return
+		}
+		svc.logger.WarnContext(detached, "failed to retry task", "task_id", t.ID, "error", err)
+		svc.failRetry(detached, t.ID, err)
+	})
+}
+
+// recoverRetries re-arms the retries of tasks that were waiting for one when
+// the manager went down. Retries already due start right away.
+func (svc *service) recoverRetries(ctx context.Context, tasks []task.Task) {
+	for i := range tasks {
+		t := tasks[i]
+		if t.State != task.Pending || t.RetryAt.IsZero() || t.RetryPolicy == nil {
+			continue
+		}
+		svc.scheduleRetry(ctx, t)
+	}
+}
+
+func (svc *service) failRetry(ctx context.Context, taskID string, cause error) {
+	t, err := svc.GetTask(ctx, taskID)
+	if err != nil {
+		return
+	}
+	if t.State == task.Pending {
+		now := time.Now()
+		t.State = task.Failed
+		t.Error = fmt.Sprintf("retry failed: %v", cause)
+		t.FinishTime = now
+		t.UpdatedAt = now
+		if err := svc.taskRepo.Update(ctx, t); err != nil {
+			svc.logger.ErrorContext(ctx, "failed to mark retried task failed", "task_id", taskID, "error", err)
+
+			return
+		}
+	}
+	if t.State == task.Failed {
+		svc.taskFinished(ctx, t)
+	}
+}
@@ -0,0 +1,114 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/task"
+)
+
+const defaultSecretPrefix = "PROPELLER_SECRET_"
+
+// SecretStoreConfig selects the SecretStore task secret refs are resolved
+// from.
+type SecretStoreConfig struct {
+	// Store is "env", the default, or "file".
+	Store string `env:"MANAGER_SECRET_STORE" envDefault:"env"`
+	// Prefix is prepended to a secret key to form the name of the manager
+	// environment variable holding it in the "env" store.
+	Prefix string `env:"MANAGER_SECRET_PREFIX" envDefault:"PROPELLER_SECRET_"`
+	// Dir is the directory holding one file per secret key in the "file"
+	// store, e.g. a mounted Kubernetes or Docker secret.
+	Dir string `env:"MANAGER_SECRET_DIR"`
+}
+
+// ErrSecretNotFound indicates that a secret store has no value for a key.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore resolves the secret keys referenced by a task's SecretRefs.
+type SecretStore interface {
+	// Secret returns the value stored under key, or ErrSecretNotFound.
+	Secret(ctx context.Context, key string) (string, error)
+}
+
+type envSecretStore struct {
+	prefix string
+}
+
+// NewEnvSecretStore returns a SecretStore reading each key from the manager
+// environment variable named prefix+key.
+func NewEnvSecretStore(prefix string) SecretStore {
+	return envSecretStore{prefix: prefix}
+}
+
+func (s envSecretStore) Secret(_ context.Context, key string) (string, error) {
+	value, ok := os.LookupEnv(s.prefix + key)
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+
+	return value, nil
+}
+
+type fileSecretStore struct {
+	dir string
+}
+
+// NewFileSecretStore returns a SecretStore reading each key from the file of
+// the same name in dir. A trailing newline is not part of the secret.
+func NewFileSecretStore(dir string) SecretStore {
+	return fileSecretStore{dir: dir}
+}
+
+func (s fileSecretStore) Secret(_ context.Context, key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || strings.HasPrefix(key, ".") {
+		return "", ErrSecretNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, key))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// NewSecretStore returns the SecretStore cfg selects.
+func NewSecretStore(cfg SecretStoreConfig) SecretStore {
+	switch strings.TrimSpace(cfg.Store) {
+	case "file":
+		return NewFileSecretStore(cfg.Dir)
+	default:
+		return NewEnvSecretStore(cfg.Prefix)
+	}
+}
+
+// injectSecrets returns a copy of env with each of t's secret refs resolved
+// into it. Secret values are never logged; only the variable names are.
+func (svc *service) injectSecrets(ctx context.Context, t task.Task, env map[string]string) (map[string]string, error) {
+	if len(t.SecretRefs) == 0 {
+		return env, nil
+	}
+
+	out := make(map[string]string, len(env)+len(t.SecretRefs))
+	maps.Copy(out, env)
+	for name, key := range t.SecretRefs {
+		value, err := svc.secrets.Secret(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: resolving secret for env %s: %w", pkgerrors.ErrInvalidValue, name, err)
+		}
+		out[name] = value
+	}
+	svc.logger.DebugContext(ctx, "injected task secrets", "task_id", t.ID, "env", slices.Sorted(maps.Keys(t.SecretRefs)))
+
+	return out, nil
+}
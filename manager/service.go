@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,15 +11,19 @@ import (
 	"net/http"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/0x6flab/namegenerator"
+	"github.com/absmach/propeller/pkg/audit"
 	"github.com/absmach/propeller/pkg/cron"
 	"github.com/absmach/propeller/pkg/dag"
 	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/events"
+	"github.com/absmach/propeller/pkg/fl"
 	"github.com/absmach/propeller/pkg/job"
 	"github.com/absmach/propeller/pkg/maps"
 	"github.com/absmach/propeller/pkg/mqtt"
@@ -29,6 +34,12 @@ import (
 	"github.com/absmach/propeller/pkg/storage"
 	"github.com/absmach/propeller/pkg/task"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -40,12 +51,36 @@ const (
 	ExecutionModeConfigurable = "configurable"
 	EnvJobExecutionMode       = "JOB_EXECUTION_MODE"
 	shutdownTaskStopWait      = 200 * time.Millisecond
+	traceparentKey            = "traceparent"
+	roundMetadataKey          = "fl_round_id"
+	experimentMetadataKey     = "fl_experiment_id"
+	kOfNMetadataKey           = "fl_k_of_n"
+	quorumMetadataKey         = "fl_quorum_policy"
+	requiredMetadataKey       = "fl_required_participants"
+	maxAgeMetadataKey         = "fl_max_update_age_s"
+	graceMetadataKey          = "fl_aggregation_grace_period_s"
+	roundStartedMetadataKey   = "fl_round_started_at"
+	roundNumberMetadataKey    = "fl_round"
+	totalRoundsMetadataKey    = "fl_total_rounds"
+	jobCompleteMetadataKey    = "fl_job_complete"
 )
 
 var (
-	baseTopicFmt    = "m/%s/c/%s"
 	namegen         = namegenerator.NewGenerator()
 	errShuttingDown = errors.New("service is shutting down")
+	errNoProplets   = errors.New("no active proplets available")
+	errNoMatch      = errors.New("no proplet satisfies plugin-required constraints")
+	errDepFailed    = errors.New("dependency failed")
+	errSaturated    = errors.New("all proplets are at capacity")
+	errStaleUpdate  = errors.New("update arrived after the round's staleness window")
+
+	// tracer covers the MQTT-driven paths, which bypass the tracing
+	// middleware. It resolves against the global tracer provider.
+	tracer = otel.Tracer("github.com/absmach/propeller/manager")
+
+	// traceContext propagates spans over MQTT through the traceparent field
+	// of start commands and results.
+	traceContext = propagation.TraceContext{}
 )
 
 type service struct {
@@ -54,6 +89,7 @@ type service struct {
 	taskPropletRepo  storage.TaskPropletRepository
 	jobRepo          storage.JobRepository
 	metricsRepo      storage.MetricsRepository
+	roundRepo        storage.RoundRepository
 	scheduler        scheduler.Scheduler
 	cronScheduler    CronScheduler
 	baseTopic        string
@@ -63,6 +99,30 @@ type service struct {
 	httpClient       *http.Client
 	coordinator      *WorkflowCoordinator
 	plugins          plugin.Registry
+	pending          *scheduler.Queue
+	load             *loadTracker
+	breakers         *breakers
+	flProgress       *flProgress
+	flAsync          *flAsync
+	flEvals          *flEvaluations
+	flMetrics        *flJobMetrics
+	flJobs           *flJobs
+	transform        UpdateTransformer
+	redelivery       *redelivery
+	dedup            *dedup
+	metricsIngest    *metricsIngest
+	ingestLimit      *ingestLimiter
+	identities       *publisherIdentities
+	flSigning        *updateSigning
+	orphans          *orphanTracker
+	defaultEnv       map[string]string
+	checkpoints      *checkpointer
+	registry         RegistryConfig
+	models           *fl.ModelRegistry
+	personalized     *personalizedModels
+	secrets          SecretStore
+	auditLog         audit.AuditLog
+	events           *events.Bus
 	shuttingDown     atomic.Bool
 	wg               sync.WaitGroup
 }
@@ -71,11 +131,22 @@ func NewService(
 	repos *storage.Repositories,
 	s scheduler.Scheduler, pubsub mqtt.PubSub,
 	domainID, channelID, coordinatorURL string, logger *slog.Logger, plugins plugin.Registry,
+	opts ...Option,
 ) (Service, CronScheduler, *WorkflowCoordinator) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.auditLog == nil {
+		o.auditLog = audit.NewNopLog()
+	}
+	logger = slog.New(newRedactingHandler(logger.Handler(), o.sensitiveKeys))
+
 	var httpClient *http.Client
 	if coordinatorURL != "" {
 		httpClient = &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
 		}
 		logger.Info("HTTP FL Coordinator enabled", "url", coordinatorURL)
 	} else {
@@ -88,14 +159,39 @@ func NewService(
 		taskPropletRepo:  repos.TaskProplets,
 		jobRepo:          repos.Jobs,
 		metricsRepo:      repos.Metrics,
+		roundRepo:        repos.Rounds,
 		scheduler:        s,
-		baseTopic:        fmt.Sprintf(baseTopicFmt, domainID, channelID),
+		baseTopic:        mqtt.BaseTopic(o.topicPrefix, domainID, channelID),
 		pubsub:           pubsub,
 		logger:           logger,
 		flCoordinatorURL: coordinatorURL,
 		httpClient:       httpClient,
 		plugins:          plugins,
-	}
+		pending:          scheduler.NewQueue(),
+		load:             newLoadTracker(o.maxPropletCPUPercent),
+		breakers:         newBreakers(o.breakers),
+		flProgress:       newFLProgress(),
+		flAsync:          newFLAsync(),
+		flEvals:          newFLEvaluations(),
+		flMetrics:        newFLJobMetrics(),
+		flJobs:           newFLJobs(),
+		transform:        newUpdateTransformer(o.transform, logger),
+		auditLog:         o.auditLog,
+		events:           events.NewBus(),
+		dedup:            newDedup(o.dedup),
+		metricsIngest:    newMetricsIngest(o.metricsIngest, logger),
+		ingestLimit:      newIngestLimiter(o.ingestLimit, logger),
+		identities:       newPublisherIdentities(o.identities, logger),
+		flSigning:        newUpdateSigning(o.signing),
+		orphans:          newOrphanTracker(o.orphans),
+		defaultEnv:       o.defaultEnv,
+		checkpoints:      newCheckpointer(o.checkpointRepository, o.registry),
+		registry:         o.registry,
+		models:           newModelRegistry(o.models, logger),
+		personalized:     newPersonalizedModels(),
+		secrets:          o.secrets,
+	}
+	svc.redelivery = newRedelivery(o.redelivery, logger, svc.publishDeadLetter)
 	coordinator := NewWorkflowCoordinator(repos.Tasks, svc, logger)
 	svc.coordinator = coordinator
 
@@ -108,13 +204,10 @@ func NewService(
 func (svc *service) GetProplet(ctx context.Context, propletID string) (proplet.Proplet, error) {
 	w, err := svc.propletRepo.Get(ctx, propletID)
 	if err != nil {
-		if errors.Is(err, storage.ErrPropletNotFound) {
-			return proplet.Proplet{}, pkgerrors.ErrNotFound
-		}
-
-		return proplet.Proplet{}, err
+		return proplet.Proplet{}, repoErr(err, "failed to get proplet %s", propletID)
 	}
 	w.SetAlive()
+	w.Breaker = svc.breakers.state(w.ID, time.Now())
 
 	return w, nil
 }
@@ -128,37 +221,60 @@ func (svc *service) GetPropletSDF(ctx context.Context, propletID string) (sdf.Do
 	return sdf.PropletDocument(p), nil
 }
 
-func (svc *service) ListProplets(ctx context.Context, offset, limit uint64, status string) (proplet.PropletPage, error) {
-	if status == "" {
-		proplets, total, err := svc.propletRepo.List(ctx, offset, limit)
+func (svc *service) ListProplets(ctx context.Context, offset, limit uint64, status string, sort Sort) (proplet.PropletPage, error) {
+	if err := validateSort(sort, propletSortFields); err != nil {
+		return proplet.PropletPage{}, err
+	}
+
+	list := func(offset, limit uint64) ([]proplet.Proplet, uint64, error) {
+		return svc.propletRepo.List(ctx, offset, limit)
+	}
+	if status != "" {
+		st, err := proplet.ToStatus(status)
 		if err != nil {
-			return proplet.PropletPage{}, err
+			return proplet.PropletPage{}, fmt.Errorf("%w: %w", pkgerrors.ErrInvalidValue, err)
 		}
-		for i := range proplets {
-			proplets[i].SetAlive()
+		alive := st == proplet.ActiveStatus
+		since := time.Now().Add(-proplet.AliveTimeout)
+		list = func(offset, limit uint64) ([]proplet.Proplet, uint64, error) {
+			return svc.propletRepo.ListByAlive(ctx, offset, limit, alive, since)
 		}
-
-		return proplet.PropletPage{
-			Offset:   offset,
-			Limit:    limit,
-			Total:    total,
-			Proplets: proplets,
-		}, nil
-	}
-
-	st, err := proplet.ToStatus(status)
-	if err != nil {
-		return proplet.PropletPage{}, fmt.Errorf("%w: %w", pkgerrors.ErrInvalidValue, err)
 	}
 
-	alive := st == proplet.ActiveStatus
-	since := time.Now().Add(-proplet.AliveTimeout)
-	proplets, total, err := svc.propletRepo.ListByAlive(ctx, offset, limit, alive, since)
-	if err != nil {
-		return proplet.PropletPage{}, err
+	var (
+		proplets []proplet.Proplet
+		total    uint64
+		err      error
+	)
+	if sort.Field == "" {
+		proplets, total, err = list(offset, limit)
+		if err != nil {
+			return proplet.PropletPage{}, repoErr(err, "failed to list proplets")
+		}
+	} else {
+		// The repositories have a fixed order, so sorted listings are
+		// ordered in memory before paginating.
+		const pageSize uint64 = 100
+		var all []proplet.Proplet
+		for o := uint64(0); ; {
+			page, n, err := list(o, pageSize)
+			if err != nil {
+				return proplet.PropletPage{}, repoErr(err, "failed to list proplets")
+			}
+			all = append(all, page...)
+			o += uint64(len(page))
+			if o >= n || len(page) == 0 {
+				break
+			}
+		}
+		sortBy(all, sort, propletSortFields)
+		total = uint64(len(all))
+		proplets = paginate(all, offset, limit)
 	}
+	now := time.Now()
 	for i := range proplets {
 		proplets[i].SetAlive()
+		proplets[i].Breaker = svc.breakers.state(proplets[i].ID, now)
 	}
 
 	return proplet.PropletPage{
@@ -172,10 +288,38 @@ func (svc *service) ListProplets(ctx context.Context, offset, limit uint64, stat
 func (svc *service) SelectProplet(ctx context.Context, t task.Task) (proplet.Proplet, error) {
 	proplets, err := svc.listAllActiveProplets(ctx)
 	if err != nil {
-		return proplet.Proplet{}, err
+		return proplet.Proplet{}, repoErr(err, "failed to list active proplets")
+	}
+	proplets = placeableFor(proplets, t)
+
+	available := svc.unsaturated(proplets)
+	if len(proplets) > 0 && len(available) == 0 {
+		return proplet.Proplet{}, errSaturated
+	}
+
+	return svc.scheduler.SelectProplet(t, available)
+}
+
+// unsaturated drops proplets that have no room for another task or whose
+// failure breaker is open.
+func (svc *service) unsaturated(proplets []proplet.Proplet) []proplet.Proplet {
+	now := time.Now()
+	available := make([]proplet.Proplet, 0, len(proplets))
+	for i := range proplets {
+		if !svc.load.saturated(proplets[i].ID) && !svc.breakers.open(proplets[i].ID, now) {
+			available = append(available, proplets[i])
+		}
 	}
 
-	return svc.scheduler.SelectProplet(t, proplets)
+	return available
+}
+
+func (svc *service) SubscribeEvents(_ context.Context, filter events.Filter) (*events.Subscription, error) {
+	if svc.shuttingDown.Load() {
+		return nil, errShuttingDown
+	}
+
+	return svc.events.Subscribe(filter, events.DefaultBuffer), nil
 }
 
 func (svc *service) DeleteProplet(ctx context.Context, propletID string) error {
@@ -188,16 +332,59 @@ func (svc *service) DeleteProplet(ctx context.Context, propletID string) error {
 		return fmt.Errorf("%w: proplet %s has %d active tasks", pkgerrors.ErrConflict, propletID, p.TaskCount)
 	}
 
-	return svc.propletRepo.Delete(ctx, propletID)
+	if err := svc.propletRepo.Delete(ctx, propletID); err != nil {
+		return repoErr(err, "failed to delete proplet %s", propletID)
+	}
+
+	return nil
 }
 
-func (svc *service) CreateTask(ctx context.Context, t task.Task) (task.Task, error) {
-	if t.Broadcast && t.PropletID != "" {
-		return task.Task{}, errors.New("proplet_id must not be set when broadcast is true")
+func (svc *service) SetPropletLabels(ctx context.Context, propletID string, labels map[string]string) (proplet.Proplet, error) {
+	for k := range labels {
+		if strings.TrimSpace(k) == "" {
+			return proplet.Proplet{}, fmt.Errorf("%w: empty label key", pkgerrors.ErrInvalidValue)
+		}
 	}
 
-	if len(t.DependsOn) > 0 && t.WorkflowID == "" {
-		return task.Task{}, errors.New("workflow_id is required when depends_on is specified")
+	p, err := svc.GetProplet(ctx, propletID)
+	if err != nil {
+		return proplet.Proplet{}, err
+	}
+	p.Labels = labels
+	if len(labels) == 0 {
+		p.Labels = nil
+	}
+	if err := svc.propletRepo.Update(ctx, p); err != nil {
+		return proplet.Proplet{}, repoErr(err, "failed to update labels of proplet %s", propletID)
+	}
+
+	return p, nil
+}
+
+// placeable reports whether t may run on p: p must be in t's group and
+// namespace when t sets them.
+func placeable(p *proplet.Proplet, t task.Task) bool {
+	return (t.Group == "" || p.InGroup(t.Group)) && p.InNamespace(t.Namespace)
+}
+
+// placeableFor keeps the proplets t may run on.
+func placeableFor(proplets []proplet.Proplet, t task.Task) []proplet.Proplet {
+	if t.Group == "" && t.Namespace == "" {
+		return proplets
+	}
+	matched := make([]proplet.Proplet, 0, len(proplets))
+	for i := range proplets {
+		if placeable(&proplets[i], t) {
+			matched = append(matched, proplets[i])
+		}
+	}
+
+	return matched
+}
+
+func (svc *service) CreateTask(ctx context.Context, t task.Task) (task.Task, error) {
+	if err := validateTask(&t); err != nil {
+		return task.Task{}, err
 	}
 
 	if len(t.DependsOn) > 0 && t.WorkflowID != "" {
@@ -221,6 +408,7 @@ func (svc *service) CreateTask(ctx context.Context, t task.Task) (task.Task, err
 
 	t.ID = uuid.NewString()
 	t.CreatedAt = time.Now()
+	t.Attempts = 0
 
 	// Set default kind if not specified
 	if t.Kind == "" {
@@ -247,9 +435,11 @@ func (svc *service) CreateTask(ctx context.Context, t task.Task) (task.Task, err
 
 	t, err := svc.taskRepo.Create(ctx, t)
 	if err != nil {
-		return task.Task{}, err
+		return task.Task{}, repoErr(err, "failed to create task %s", t.ID)
 	}
 
+	svc.auditTask(ctx, "create", t, "")
+
 	if t.Schedule != "" && svc.cronScheduler != nil {
 		if err := svc.cronScheduler.ScheduleTask(ctx, t.ID); err != nil {
 			svc.logger.WarnContext(ctx, "failed to schedule task in cron scheduler", "error", err, "task_id", t.ID)
@@ -259,6 +449,36 @@ func (svc *service) CreateTask(ctx context.Context, t task.Task) (task.Task, err
 	return t, nil
 }
 
+// validateTask checks the fields of t that do not depend on other tasks. It
+// normalizes t's FL settings in place.
+func validateTask(t *task.Task) error {
+	if t.Broadcast && t.PropletID != "" {
+		return errors.New("proplet_id must not be set when broadcast is true")
+	}
+
+	if t.Broadcast && (t.Group != "" || t.Namespace != "") {
+		return fmt.Errorf("%w: group and namespace must not be set when broadcast is true", pkgerrors.ErrInvalidValue)
+	}
+
+	if len(t.DependsOn) > 0 && t.WorkflowID == "" {
+		return errors.New("workflow_id is required when depends_on is specified")
+	}
+
+	if err := validateInputsFrom(*t); err != nil {
+		return err
+	}
+
+	if err := validateFLTask(t); err != nil {
+		return err
+	}
+
+	if err := validateMonitoringProfile(*t); err != nil {
+		return err
+	}
+
+	return validateRetryPolicy(*t)
+}
+
 func (svc *service) CreateWorkflow(ctx context.Context, tasks []task.Task) ([]task.Task, error) {
 	if len(tasks) == 0 {
 		return nil, errors.New("workflow must contain at least one task")
@@ -293,6 +513,15 @@ func (svc *service) CreateWorkflow(ctx context.Context, tasks []task.Task) ([]ta
 		if tasks[i].RunIf != "" && tasks[i].RunIf != task.RunIfSuccess && tasks[i].RunIf != task.RunIfFailure {
 			return nil, fmt.Errorf("invalid run_if value for task %s: must be 'success' or 'failure'", tasks[i].ID)
 		}
+		if err := validateInputsFrom(tasks[i]); err != nil {
+			return nil, err
+		}
+		if err := validateFLTask(&tasks[i]); err != nil {
+			return nil, err
+		}
+		if err := validateMonitoringProfile(tasks[i]); err != nil {
+			return nil, err
+		}
 	}
 
 	createdTasks := make([]task.Task, 0, len(tasks))
@@ -346,6 +575,18 @@ func (svc *service) CreateJob(ctx context.Context, name string, tasks []task.Tas
 		return "", nil, fmt.Errorf("DAG validation failed: %w", err)
 	}
 
+	for i := range tasks {
+		if err := validateInputsFrom(tasks[i]); err != nil {
+			return "", nil, err
+		}
+		if err := validateFLTask(&tasks[i]); err != nil {
+			return "", nil, err
+		}
+		if err := validateMonitoringProfile(tasks[i]); err != nil {
+			return "", nil, err
+		}
+	}
+
 	if svc.jobRepo != nil {
 		storedJob := job.Job{
 			ID:            jobID,
@@ -385,7 +626,12 @@ func (svc *service) CreateJob(ctx context.Context, name string, tasks []task.Tas
 }
 
 func (svc *service) GetJob(ctx context.Context, jobID string) ([]task.Task, error) {
-	return svc.getJobTasks(ctx, jobID)
+	tasks, err := svc.getJobTasks(ctx, jobID)
+	if err != nil {
+		return nil, repoErr(err, "failed to list tasks of job %s", jobID)
+	}
+
+	return tasks, nil
 }
 
 // ListJobs is O(total tasks): job state is derived in-memory from the full task
@@ -405,7 +651,7 @@ func (svc *service) ListJobs(ctx context.Context, offset, limit uint64, status s
 	// so filtering has to happen after we aggregate tasks into job summaries in memory.
 	allTasks, err := svc.listAllTasks(ctx)
 	if err != nil {
-		return JobPage{}, err
+		return JobPage{}, repoErr(err, "failed to list tasks")
 	}
 
 	jobMap := make(map[string][]task.Task)
@@ -420,7 +666,7 @@ func (svc *service) ListJobs(ctx context.Context, offset, limit uint64, status s
 	if svc.jobRepo != nil {
 		storedJobs, err := svc.listAllStoredJobs(ctx)
 		if err != nil {
-			return JobPage{}, err
+			return JobPage{}, repoErr(err, "failed to list jobs")
 		}
 
 		for _, sj := range storedJobs {
@@ -563,7 +809,7 @@ func ComputeJobState(tasks []task.Task) task.State {
 		case task.Completed:
 			allSkipped = false
 		case task.Skipped:
-		case task.Pending:
+		case task.Pending, task.Blocked:
 			allCompleted = false
 			allSkipped = false
 		}
@@ -585,22 +831,40 @@ func ComputeJobState(tasks []task.Task) task.State {
 func (svc *service) GetTask(ctx context.Context, taskID string) (task.Task, error) {
 	t, err := svc.taskRepo.Get(ctx, taskID)
 	if err != nil {
-		if errors.Is(err, storage.ErrTaskNotFound) {
-			return task.Task{}, pkgerrors.ErrNotFound
-		}
-
-		return task.Task{}, err
+		return task.Task{}, repoErr(err, "failed to get task %s", taskID)
 	}
 
 	return t, nil
 }
 
 func (svc *service) ListTasks(ctx context.Context, pm PageMetadata) (task.TaskPage, error) {
-	tasks, total, err := svc.taskRepo.List(ctx, pm.Metadata, pm.Offset, pm.Limit)
-	if err != nil {
+	if err := validateSort(pm.Sort, taskSortFields); err != nil {
 		return task.TaskPage{}, err
 	}
 
+	q := task.Query{
+		Metadata:  pm.Metadata,
+		Kind:      pm.Kind,
+		Mode:      pm.Mode,
+		PropletID: pm.PropletID,
+		Sort:      pm.Sort.Field,
+		Desc:      pm.Sort.Order == OrderDesc,
+		Offset:    pm.Offset,
+		Limit:     pm.Limit,
+	}
+	if pm.State != "" {
+		st, err := task.ToState(pm.State)
+		if err != nil {
+			return task.TaskPage{}, fmt.Errorf("%w: %w", pkgerrors.ErrInvalidValue, err)
+		}
+		q.State = &st
+	}
+
+	tasks, total, err := svc.taskRepo.Query(ctx, q)
+	if err != nil {
+		return task.TaskPage{}, repoErr(err, "failed to list tasks")
+	}
+
 	return task.TaskPage{
 		Offset: pm.Offset,
 		Limit:  pm.Limit,
@@ -631,6 +895,21 @@ func (svc *service) UpdateTask(ctx context.Context, t task.Task) (task.Task, err
 	if t.Metadata != nil {
 		dbT.Metadata = t.Metadata
 	}
+	if t.RetryPolicy != nil {
+		dbT.RetryPolicy = t.RetryPolicy
+		if err := validateRetryPolicy(dbT); err != nil {
+			return task.Task{}, err
+		}
+	}
+	if t.MaxInstructions != 0 {
+		dbT.MaxInstructions = t.MaxInstructions
+	}
+	if t.Group != "" {
+		dbT.Group = t.Group
+	}
+	if t.Namespace != "" {
+		dbT.Namespace = t.Namespace
+	}
 
 	scheduleChanged := false
 	if t.Schedule != "" && t.Schedule != dbT.Schedule {
@@ -657,7 +936,7 @@ func (svc *service) UpdateTask(ctx context.Context, t task.Task) (task.Task, err
 	}
 
 	if err := svc.taskRepo.Update(ctx, dbT); err != nil {
-		return task.Task{}, err
+		return task.Task{}, repoErr(err, "failed to update task %s", dbT.ID)
 	}
 
 	if scheduleChanged {
@@ -668,13 +947,66 @@ func (svc *service) UpdateTask(ctx context.Context, t task.Task) (task.Task, err
 }
 
 func (svc *service) DeleteTask(ctx context.Context, taskID string) error {
+	svc.pending.Remove(taskID)
+
 	if svc.cronScheduler != nil {
 		if err := svc.cronScheduler.UnscheduleTask(ctx, taskID); err != nil {
 			svc.logger.WarnContext(ctx, "failed to unschedule task from cron scheduler", "error", err, "task_id", taskID)
 		}
 	}
 
-	return svc.taskRepo.Delete(ctx, taskID)
+	if err := svc.taskRepo.Delete(ctx, taskID); err != nil {
+		return repoErr(err, "failed to delete task %s", taskID)
+	}
+
+	return nil
+}
+
+func (svc *service) PurgeTasks(ctx context.Context, filter PurgeFilter) (uint64, error) {
+	states := filter.States
+	for _, st := range states {
+		if !st.IsTerminal() {
+			return 0, fmt.Errorf("%w: cannot purge %s tasks", pkgerrors.ErrInvalidValue, st)
+		}
+	}
+	if len(states) == 0 {
+		states = []task.State{task.Completed, task.Failed, task.Skipped, task.Interrupted}
+	}
+	if filter.OlderThan < 0 {
+		return 0, fmt.Errorf("%w: negative age %s", pkgerrors.ErrInvalidValue, filter.OlderThan)
+	}
+
+	cutoff := time.Now().Add(-filter.OlderThan)
+	var purged uint64
+	for _, st := range slices.Compact(slices.Sorted(slices.Values(states))) {
+		tasks, err := queryAllTasks(ctx, svc.taskRepo, task.Query{State: &st})
+		if err != nil {
+			return purged, repoErr(err, "failed to list %s tasks", st)
+		}
+		for _, t := range tasks {
+			finished := t.FinishTime
+			if finished.IsZero() {
+				finished = t.UpdatedAt
+			}
+			if finished.After(cutoff) {
+				continue
+			}
+
+			if err := svc.DeleteTask(ctx, t.ID); err != nil {
+				return purged, err
+			}
+			if err := svc.taskPropletRepo.Delete(ctx, t.ID); err != nil {
+				svc.logger.WarnContext(ctx, "failed to delete task proplet mapping", "task_id", t.ID, "error", err)
+			}
+			svc.load.release(t.ID)
+			if roundID, _ := t.Metadata[roundMetadataKey].(string); roundID != "" {
+				svc.releaseParticipant(ctx, roundID, t.PropletID)
+			}
+			purged++
+		}
+	}
+
+	return purged, nil
 }
 
 func (svc *service) StartTask(ctx context.Context, taskID string) error {
@@ -686,22 +1018,40 @@ func (svc *service) StartTask(ctx context.Context, taskID string) error {
 	if err != nil {
 		return err
 	}
+	if t.State == task.Running && !t.Broadcast {
+		// A repeated start, e.g. a client retrying a request whose response
+		// was lost, must not publish again or take a second slot.
+		if propletID, err := svc.taskPropletRepo.Get(ctx, taskID); err == nil {
+			svc.logger.InfoContext(ctx, "task already running, ignoring start", "task_id", taskID, "proplet_id", propletID)
+
+			return nil
+		}
+	}
+	oldState := t.State.String()
+	if t.State.IsTerminal() {
+		t.Attempts = 0
+	}
 
 	if len(t.DependsOn) > 0 {
-		if err := svc.checkTaskDependencies(ctx, taskID, t); err != nil {
+		ready, err := svc.checkTaskDependencies(ctx, &t)
+		if err != nil || !ready {
 			return err
 		}
 	}
 
 	if t.Broadcast {
 		if err := svc.persistTaskBeforeStart(ctx, &t); err != nil {
-			return err
+			return repoErr(err, "failed to persist task %s", taskID)
 		}
 		if err := svc.publishStart(ctx, t, ""); err != nil {
-			return err
+			return fmt.Errorf("failed to publish start of task %s: %w", taskID, err)
 		}
+		if err := svc.markTaskRunning(ctx, &t); err != nil {
+			return repoErr(err, "failed to mark task %s running", taskID)
+		}
+		svc.auditTask(ctx, "start", t, oldState)
 
-		return svc.markTaskRunning(ctx, &t)
+		return nil
 	}
 
 	constraints, err := svc.runOnBeforePropletSelect(ctx, t)
@@ -713,6 +1063,15 @@ func (svc *service) StartTask(ctx context.Context, taskID string) error {
 	switch t.PropletID {
 	case "":
 		p, err = svc.selectPropletWithConstraints(ctx, t, constraints)
+		if errors.Is(err, errNoProplets) || errors.Is(err, errNoMatch) || errors.Is(err, errSaturated) {
+			if err := svc.queueTask(ctx, t); err != nil {
+				return repoErr(err, "failed to queue task %s", taskID)
+			}
+			svc.logger.InfoContext(ctx, "no proplet available, task queued", "task_id", taskID, "priority", t.Priority, "reason", err)
+			svc.auditTask(ctx, "queue", t, oldState)
+
+			return nil
+		}
 		if err != nil {
 			return err
 		}
@@ -724,58 +1083,71 @@ func (svc *service) StartTask(ctx context.Context, taskID string) error {
 		if !p.Alive {
 			return fmt.Errorf("specified proplet %s is not alive", t.PropletID)
 		}
+		if !placeable(&p, t) {
+			return fmt.Errorf("%w: specified proplet %s is outside the task's group or namespace", pkgerrors.ErrInvalidValue, t.PropletID)
+		}
 	}
 
 	if err := svc.runOnBeforeDispatch(ctx, &t, p); err != nil {
 		return err
 	}
 
+	// The pin and the proplet's task count are undone if any later step
+	// fails, so a task that never started does not hold a slot.
+	var undo rollback
 	if err := svc.pinTaskToProplet(ctx, taskID, p.ID); err != nil {
-		return err
+		return repoErr(err, "failed to pin task %s to proplet %s", taskID, p.ID)
+	}
+	undo.add(func() {
+		if err := svc.taskPropletRepo.Delete(ctx, taskID); err != nil {
+			svc.logger.ErrorContext(ctx, "failed to unpin task after start failure", "task_id", taskID, "error", err)
+		}
+	})
+
+	if err := svc.bumpPropletTaskCount(ctx, p, +1); err != nil {
+		undo.run()
+
+		return repoErr(err, "failed to bump task count of proplet %s", p.ID)
 	}
+	undo.add(func() {
+		if err := svc.releasePropletTask(ctx, p.ID); err != nil {
+			svc.logger.ErrorContext(ctx, "failed to restore proplet task count after start failure", "task_id", taskID, "proplet_id", p.ID, "error", err)
+		}
+	})
 
 	t.PropletID = p.ID
 
 	if err := svc.persistTaskBeforeStart(ctx, &t); err != nil {
-		return err
+		undo.run()
+
+		return repoErr(err, "failed to persist task %s", taskID)
 	}
 
 	if err := svc.publishStart(ctx, t, p.ID); err != nil {
-		_ = svc.taskPropletRepo.Delete(ctx, taskID)
+		undo.run()
 		t.State = task.Failed
 		t.Error = fmt.Sprintf("failed to publish start message: %v", err)
 		t.UpdatedAt = time.Now()
 		_ = svc.taskRepo.Update(ctx, t)
 
-		return err
-	}
-
-	if err := svc.bumpPropletTaskCount(ctx, p, +1); err != nil {
-		_ = svc.taskPropletRepo.Delete(ctx, taskID)
-		if stopErr := svc.publishStop(ctx, t, p.ID); stopErr != nil {
-			svc.logger.ErrorContext(ctx, "failed to send stop after bump failure", "task_id", taskID, "error", stopErr)
-		}
-		t.State = task.Failed
-		t.Error = fmt.Sprintf("failed to bump proplet task count: %v", err)
-		t.UpdatedAt = time.Now()
-		_ = svc.taskRepo.Update(ctx, t)
-
-		return err
+		return fmt.Errorf("failed to publish start of task %s to proplet %s: %w", taskID, p.ID, err)
 	}
 
 	if err := svc.markTaskRunning(ctx, &t); err != nil {
-		_ = svc.taskPropletRepo.Delete(ctx, taskID)
-		_ = svc.bumpPropletTaskCount(ctx, p, -1)
 		if stopErr := svc.publishStop(ctx, t, p.ID); stopErr != nil {
 			svc.logger.ErrorContext(ctx, "failed to send stop after markRunning failure", "task_id", taskID, "error", stopErr)
 		}
+		undo.run()
 		t.State = task.Failed
 		t.Error = fmt.Sprintf("failed to mark task running: %v", err)
 		t.UpdatedAt = time.Now()
 		_ = svc.taskRepo.Update(ctx, t)
 
-		return err
+		return repoErr(err, "failed to mark task %s running", taskID)
 	}
+	svc.load.reserve(p.ID, t.ID)
+
+	svc.auditTask(ctx, "start", t, oldState)
 
 	return nil
 }
@@ -786,6 +1158,16 @@ func (svc *service) StopTask(ctx context.Context, taskID string) error {
 		return err
 	}
 
+	if svc.pending.Remove(taskID) {
+		t.QueuedAt = time.Time{}
+		if err := svc.taskRepo.Update(ctx, t); err != nil {
+			return repoErr(err, "failed to dequeue task %s", taskID)
+		}
+		svc.auditTask(ctx, "dequeue", t, t.State.String())
+
+		return nil
+	}
+
 	stopPayload := map[string]any{
 		"id":        t.ID,
 		"broadcast": t.Broadcast,
@@ -794,15 +1176,16 @@ func (svc *service) StopTask(ctx context.Context, taskID string) error {
 	if t.Broadcast {
 		topic := svc.baseTopic + "/control/manager/stop"
 		if err := svc.pubsub.Publish(ctx, topic, stopPayload); err != nil {
-			return err
+			return fmt.Errorf("failed to publish stop of task %s: %w", taskID, err)
 		}
+		svc.auditTask(ctx, "stop", t, t.State.String())
 
 		return nil
 	}
 
 	propletID, err := svc.taskPropletRepo.Get(ctx, taskID)
 	if err != nil {
-		return err
+		return repoErr(err, "failed to get proplet of task %s", taskID)
 	}
 	p, err := svc.GetProplet(ctx, propletID)
 	if err != nil {
@@ -813,17 +1196,19 @@ func (svc *service) StopTask(ctx context.Context, taskID string) error {
 
 	topic := svc.baseTopic + "/control/manager/stop"
 	if err := svc.pubsub.Publish(ctx, topic, stopPayload); err != nil {
-		return err
+		return fmt.Errorf("failed to publish stop of task %s to proplet %s: %w", taskID, propletID, err)
 	}
 
 	if err := svc.taskPropletRepo.Delete(ctx, taskID); err != nil {
-		return err
+		return repoErr(err, "failed to unpin task %s from proplet %s", taskID, propletID)
 	}
 
 	if err := svc.bumpPropletTaskCount(ctx, p, -1); err != nil {
-		return err
+		return repoErr(err, "failed to bump task count of proplet %s", propletID)
 	}
 
+	svc.auditTask(ctx, "stop", t, t.State.String())
+
 	return nil
 }
 
@@ -834,7 +1219,7 @@ func (svc *service) StartJob(ctx context.Context, jobID string) error {
 
 	tasks, err := svc.getJobTasks(ctx, jobID)
 	if err != nil {
-		return err
+		return repoErr(err, "failed to list tasks of job %s", jobID)
 	}
 
 	if len(tasks) == 0 {
@@ -878,7 +1263,7 @@ func (svc *service) StartJob(ctx context.Context, jobID string) error {
 func (svc *service) StopJob(ctx context.Context, jobID string) error {
 	tasks, err := svc.getJobTasks(ctx, jobID)
 	if err != nil {
-		return err
+		return repoErr(err, "failed to list tasks of job %s", jobID)
 	}
 
 	svc.stopJobTasks(ctx, tasks)
@@ -897,13 +1282,19 @@ func (svc *service) Subscribe(ctx context.Context) error {
 		return err
 	}
 
+	// Round progress streaming degrades gracefully without aggregation
+	// notices, so a broker that refuses this topic is not fatal.
+	if err := svc.pubsub.Subscribe(ctx, flRoundNextTopic, svc.handleRoundNext(ctx)); err != nil {
+		svc.logger.WarnContext(ctx, "failed to subscribe to FL round completion notices", "topic", flRoundNextTopic, "error", err)
+	}
+
 	return nil
 }
 
 func (svc *service) GetTaskMetrics(ctx context.Context, taskID string, offset, limit uint64) (TaskMetricsPage, error) {
 	metrics, total, err := svc.metricsRepo.ListTaskMetrics(ctx, taskID, offset, limit)
 	if err != nil {
-		return TaskMetricsPage{}, err
+		return TaskMetricsPage{}, repoErr(err, "failed to list metrics of task %s", taskID)
 	}
 
 	return TaskMetricsPage{
@@ -917,7 +1308,7 @@ func (svc *service) GetTaskMetrics(ctx context.Context, taskID string, offset, l
 func (svc *service) GetPropletMetrics(ctx context.Context, propletID string, offset, limit uint64) (PropletMetricsPage, error) {
 	metrics, total, err := svc.metricsRepo.ListPropletMetrics(ctx, propletID, offset, limit)
 	if err != nil {
-		return PropletMetricsPage{}, err
+		return PropletMetricsPage{}, repoErr(err, "failed to list metrics of proplet %s", propletID)
 	}
 
 	return PropletMetricsPage{
@@ -931,11 +1322,7 @@ func (svc *service) GetPropletMetrics(ctx context.Context, propletID string, off
 func (svc *service) GetPropletAliveHistory(ctx context.Context, propletID string, offset, limit uint64) (proplet.PropletAliveHistoryPage, error) {
 	history, total, err := svc.propletRepo.GetAliveHistory(ctx, propletID, offset, limit)
 	if err != nil {
-		if errors.Is(err, storage.ErrPropletNotFound) {
-			return proplet.PropletAliveHistoryPage{}, pkgerrors.ErrNotFound
-		}
-
-		return proplet.PropletAliveHistoryPage{}, err
+		return proplet.PropletAliveHistoryPage{}, repoErr(err, "failed to get alive history of proplet %s", propletID)
 	}
 
 	return proplet.PropletAliveHistoryPage{
@@ -993,6 +1380,11 @@ func (svc *service) Shutdown(ctx context.Context) error {
 		svc.logger.Error("failed to signal stop to active tasks", slog.Any("error", err))
 	}
 
+	svc.redelivery.close()
+	if svc.metricsIngest != nil {
+		svc.metricsIngest.close()
+	}
+
 	// Wait for in-flight FL round goroutines with timeout.
 	done := make(chan struct{})
 	go func() {
@@ -1082,6 +1474,10 @@ func (svc *service) RecoverInterruptedTasks(ctx context.Context) error {
 		svc.logger.Info("recovered interrupted tasks", slog.Int64("count", count))
 	}
 
+	svc.recoverRounds(ctx, allTasks)
+	svc.recoverRetries(ctx, allTasks)
+	svc.recoverQueued(allTasks)
+
 	return nil
 }
 
@@ -1125,26 +1521,99 @@ func (svc *service) listAllStoredJobs(ctx context.Context) ([]job.Job, error) {
 	return jobs, nil
 }
 
-func (svc *service) checkTaskDependencies(ctx context.Context, taskID string, t task.Task) error {
+// checkTaskDependencies reports whether t can start now. A task whose
+// dependencies are still in flight is parked as Blocked and started again by
+// releaseBlockedDependents; a task whose dependency failed is marked Failed.
+func (svc *service) checkTaskDependencies(ctx context.Context, t *task.Task) (bool, error) {
 	for _, depID := range t.DependsOn {
 		dep, err := svc.GetTask(ctx, depID)
 		if err != nil {
-			return fmt.Errorf("failed to get dependency task %s: %w", depID, err)
+			return false, fmt.Errorf("failed to get dependency task %s: %w", depID, err)
 		}
-		if dep.State != task.Completed && dep.State != task.Failed && dep.State != task.Skipped {
+		switch dep.State {
+		case task.Completed, task.Skipped:
+		case task.Failed, task.Interrupted:
+			if t.RunIf != task.RunIfFailure {
+				return false, svc.failOnDependency(ctx, t, depID)
+			}
+		default:
+			if err := svc.blockTask(ctx, t); err != nil {
+				return false, err
+			}
 			if t.WorkflowID != "" {
 				if err := svc.coordinator.CheckAndStartReadyTasks(ctx, t.WorkflowID); err != nil {
 					svc.logger.WarnContext(ctx, "workflow coordinator error", "workflow_id", t.WorkflowID, "error", err)
 				}
 			}
 
-			return fmt.Errorf("task %s has unmet dependencies", taskID)
+			return false, nil
 		}
 	}
 
+	return true, nil
+}
+
+func (svc *service) blockTask(ctx context.Context, t *task.Task) error {
+	if t.State == task.Blocked {
+		return nil
+	}
+	t.State = task.Blocked
+	t.UpdatedAt = time.Now()
+	if err := svc.taskRepo.Update(ctx, *t); err != nil {
+		return err
+	}
+	svc.logger.InfoContext(ctx, "task blocked on dependencies", "task_id", t.ID, "depends_on", t.DependsOn)
+
 	return nil
 }
 
+func (svc *service) failOnDependency(ctx context.Context, t *task.Task, depID string) error {
+	now := time.Now()
+	t.State = task.Failed
+	t.Error = fmt.Sprintf("dependency %s failed", depID)
+	t.FinishTime = now
+	t.UpdatedAt = now
+	if err := svc.taskRepo.Update(ctx, *t); err != nil {
+		return err
+	}
+	svc.releaseBlockedDependents(ctx, *t)
+
+	return fmt.Errorf("%w: task %s depends on %s", errDepFailed, t.ID, depID)
+}
+
+// releaseBlockedDependents retries every Blocked task waiting on finished.
+// StartTask decides whether each one starts, stays blocked, or fails.
+func (svc *service) releaseBlockedDependents(ctx context.Context, finished task.Task) {
+	var (
+		siblings []task.Task
+		err      error
+	)
+	switch {
+	case finished.WorkflowID != "":
+		siblings, err = svc.getWorkflowTasks(ctx, finished.WorkflowID)
+	case finished.JobID != "":
+		siblings, err = svc.getJobTasks(ctx, finished.JobID)
+	default:
+		return
+	}
+	if err != nil {
+		svc.logger.WarnContext(ctx, "failed to list dependent tasks", "task_id", finished.ID, "error", err)
+
+		return
+	}
+
+	siblings = scheduler.GetReadyTasksByPriority(siblings)
+	for i := range siblings {
+		dep := &siblings[i]
+		if dep.State != task.Blocked || !slices.Contains(dep.DependsOn, finished.ID) {
+			continue
+		}
+		if err := svc.StartTask(ctx, dep.ID); err != nil && !errors.Is(err, errDepFailed) {
+			svc.logger.WarnContext(ctx, "failed to start blocked task", "task_id", dep.ID, "error", err)
+		}
+	}
+}
+
 func (svc *service) startJobParallel(ctx context.Context, tasks []task.Task) error {
 	var wg sync.WaitGroup
 	errCh := make(chan error, len(tasks))
@@ -1223,26 +1692,86 @@ func (svc *service) stopJobTasks(ctx context.Context, tasks []task.Task) {
 	}
 }
 
+// propletControlTopic splits a ".../control/proplet/<action>" or an
+// identity-scoped ".../control/proplet/<identity>/<action>" topic. Only the
+// trailing control path is inspected so routing does not depend on how the
+// prefix, domain and channel segments are spelled.
+func propletControlTopic(topic string) (action, identity string, ok bool) {
+	segments := mqtt.SplitTopic(topic)
+	n := len(segments)
+	switch {
+	case n >= 3 && segments[n-3] == "control" && segments[n-2] == "proplet":
+		return segments[n-1], "", true
+	case n >= 4 && segments[n-4] == "control" && segments[n-3] == "proplet":
+		return segments[n-1], segments[n-2], true
+	default:
+		return "", "", false
+	}
+}
+
 func (svc *service) handle(ctx context.Context) func(topic string, msg map[string]any) error {
 	return func(topic string, msg map[string]any) error {
-		switch topic {
-		case svc.baseTopic + "/control/proplet/create":
-			if err := svc.createPropletHandler(ctx, msg); err != nil {
-				return err
-			}
-			svc.logger.InfoContext(ctx, "successfully created proplet")
-		case svc.baseTopic + "/control/proplet/alive":
-			return svc.updateLivenessHandler(ctx, msg)
-		case svc.baseTopic + "/control/proplet/results":
-			return svc.updateResultsHandler(ctx, msg)
-		case svc.baseTopic + "/control/proplet/task_metrics":
-			return svc.handleTaskMetrics(ctx, msg)
-		case svc.baseTopic + "/control/proplet/metrics":
-			return svc.handlePropletMetrics(ctx, msg)
+		action, identity, ok := propletControlTopic(topic)
+		if !ok {
+			return nil
+		}
+		if !svc.identities.verify(ctx, action, identity, msg) {
+			return nil
 		}
 
-		return nil
-	}
+		id, _ := msg[messageIDKey].(string)
+		if svc.dedup.seen(id) {
+			svc.logger.DebugContext(ctx, "dropping duplicate MQTT message", "topic", topic, "message_id", id)
+
+			return nil
+		}
+		if err := svc.route(ctx, topic, action, msg); err != nil {
+			svc.dedup.forget(id)
+
+			return err
+		}
+
+		return nil
+	}
+}
+
+// route dispatches a proplet control message by its action.
+func (svc *service) route(ctx context.Context, topic, action string, msg map[string]any) error {
+	switch action {
+	case "create":
+		if err := svc.createPropletHandler(ctx, msg); err != nil {
+			return err
+		}
+		svc.logger.InfoContext(ctx, "successfully created proplet")
+		svc.schedulePending(ctx)
+	case "alive":
+		if err := svc.updateLivenessHandler(ctx, msg); err != nil {
+			return err
+		}
+		svc.schedulePending(ctx)
+	case "results":
+		handle := func() error {
+			if err := svc.updateResultsHandler(ctx, msg); err != nil {
+				return err
+			}
+			svc.schedulePending(ctx)
+
+			return nil
+		}
+		if err := handle(); err != nil {
+			if svc.redelivery.submit(ctx, topic, msg, err, handle) {
+				return nil
+			}
+
+			return err
+		}
+	case "task_metrics":
+		return svc.handleTaskMetrics(ctx, msg)
+	case "metrics":
+		return svc.handlePropletMetrics(ctx, msg)
+	}
+
+	return nil
 }
 
 func (svc *service) createPropletHandler(ctx context.Context, msg map[string]any) error {
@@ -1256,9 +1785,11 @@ func (svc *service) createPropletHandler(ctx context.Context, msg map[string]any
 
 	meta := maps.GetMap(msg, "metadata")
 
+	namespace, _ := msg["namespace"].(string)
 	p := proplet.Proplet{
-		ID:   propletID,
-		Name: namegen.Generate(),
+		ID:        propletID,
+		Name:      namegen.Generate(),
+		Namespace: namespace,
 		Metadata: proplet.PropletMetadata{
 			Description:      maps.GetString(meta, "description", ""),
 			Tags:             maps.GetStringSlice(meta, "tags"),
@@ -1289,6 +1820,8 @@ func (svc *service) updateLivenessHandler(ctx context.Context, msg map[string]an
 		return errors.New("proplet id is empty")
 	}
 
+	svc.load.observeTasks(propletID, maps.GetUint64(msg, "running_tasks"), maps.GetUint64(msg, "max_concurrent_tasks"))
+
 	p, err := svc.GetProplet(ctx, propletID)
 	if errors.Is(err, pkgerrors.ErrNotFound) {
 		return svc.createPropletHandler(ctx, msg)
@@ -1298,10 +1831,16 @@ func (svc *service) updateLivenessHandler(ctx context.Context, msg map[string]an
 	}
 
 	p.Alive = true
+	if namespace, _ := msg["namespace"].(string); namespace != "" {
+		p.Namespace = namespace
+	}
 	p.AliveHistory = append(p.AliveHistory, time.Now())
 	if len(p.AliveHistory) > aliveHistoryLimit {
 		p.AliveHistory = p.AliveHistory[len(p.AliveHistory)-aliveHistoryLimit:]
 	}
+	if reported, ok := reportedTaskIDs(msg); ok {
+		svc.reconcileProplet(ctx, &p, reported)
+	}
 	if err := svc.propletRepo.Update(ctx, p); err != nil {
 		return err
 	}
@@ -1309,13 +1848,30 @@ func (svc *service) updateLivenessHandler(ctx context.Context, msg map[string]an
 	return nil
 }
 
-func (svc *service) updateResultsHandler(ctx context.Context, msg map[string]any) error {
-	taskID, ok := msg["task_id"].(string)
-	if !ok {
-		return errors.New("invalid task_id")
+func (svc *service) updateResultsHandler(ctx context.Context, msg map[string]any) (err error) {
+	taskID, _ := msg["task_id"].(string)
+	if tp, ok := msg[traceparentKey].(string); ok && tp != "" {
+		ctx = traceContext.Extract(ctx, propagation.MapCarrier{traceparentKey: tp})
+	}
+	ctx, span := tracer.Start(ctx, "handle-task-results", trace.WithAttributes(
+		attribute.String("task.id", taskID),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if _, ok := msg["task_id"].(string); !ok {
+		return fmt.Errorf("%w: invalid task_id", pkgerrors.ErrInvalidData)
 	}
 	if taskID == "" {
-		return errors.New("task id is empty")
+		return fmt.Errorf("%w: task id is empty", pkgerrors.ErrInvalidData)
+	}
+	if propletID, _ := msg["proplet_id"].(string); !svc.ingestLimit.allow(ctx, ingestResults, propletID) {
+		return nil
 	}
 
 	t, err := svc.GetTask(ctx, taskID)
@@ -1323,29 +1879,86 @@ func (svc *service) updateResultsHandler(ctx context.Context, msg map[string]any
 		return err
 	}
 
+	oldState := t.State.String()
 	now := time.Now()
 	t.Results = msg["results"]
+	// Outputs uploaded to a proplet's result sink arrive as a reference
+	// (uri, sha256 and size) in place of the results themselves.
+	if ref, ok := msg["result_ref"].(map[string]any); ok {
+		t.Results = ref
+	}
 	t.State = task.Completed
 	t.UpdatedAt = now
 	t.FinishTime = now
 
-	if errMsg, ok := msg["error"].(string); ok && errMsg != "" {
+	errMsg, _ := msg["error"].(string)
+	if errMsg != "" {
 		t.Error = errMsg
 		t.State = task.Failed
 	}
+	retry := canRetry(t)
+	if retry {
+		t.State = task.Pending
+		t.FinishTime = time.Time{}
+		t.RetryAt = now.Add(t.RetryPolicy.Backoff)
+	}
+	propletID, _ := msg["proplet_id"].(string)
+	svc.rejectUnsignedUpdate(ctx, &t, propletID)
 
 	if err := svc.taskRepo.Update(ctx, t); err != nil {
 		return err
 	}
 
+	span.SetAttributes(attribute.String("task.state", t.State.String()))
+
+	svc.load.release(t.ID)
+	actor := "proplet"
+	if propletID != "" {
+		actor += ":" + propletID
+	}
+	if reporter := cmp.Or(propletID, t.PropletID); svc.breakers.record(reporter, errMsg != "", now) {
+		svc.logger.WarnContext(ctx, "proplet failure breaker opened, skipping it for new tasks",
+			"proplet_id", reporter, "cooldown", svc.breakers.cooldown)
+	}
+	svc.recordAudit(ctx, audit.Entry{
+		Actor:      actor,
+		Action:     "report-results",
+		EntityType: audit.EntityTask,
+		EntityID:   t.ID,
+		OldState:   oldState,
+		NewState:   t.State.String(),
+		Metadata:   taskAuditMetadata(t),
+	})
+
+	if retry {
+		svc.scheduleRetry(ctx, t)
+
+		return nil
+	}
+	svc.taskFinished(ctx, t)
+
+	return nil
+}
+
+// taskFinished runs what follows a task reaching a terminal state: FL round
+// bookkeeping, completion notifications, and advancing its workflow or job.
+func (svc *service) taskFinished(ctx context.Context, t task.Task) {
+	taskID := t.ID
+	if roundID := t.Env["ROUND_ID"]; roundID != "" && t.State == task.Completed &&
+		!svc.rejectStaleUpdate(ctx, roundID, t.PropletID, t.FinishTime) {
+		svc.recordRoundUpdate(ctx, roundID, t.PropletID)
+	}
+	svc.recordEvaluation(ctx, t)
+
 	svc.notifyTaskComplete(ctx, t)
+	svc.releaseBlockedDependents(ctx, t)
 
 	if t.JobID == "" {
 		if err := svc.coordinator.OnTaskCompletion(ctx, taskID); err != nil {
 			svc.logger.ErrorContext(ctx, "failed to trigger workflow coordinator", "task_id", taskID, "error", err)
 		}
 
-		return nil
+		return
 	}
 
 	jobTasks, err := svc.getJobTasks(ctx, t.JobID)
@@ -1355,7 +1968,7 @@ func (svc *service) updateResultsHandler(ctx context.Context, msg map[string]any
 			svc.logger.ErrorContext(ctx, "failed to trigger workflow coordinator", "task_id", taskID, "error", err)
 		}
 
-		return nil
+		return
 	}
 
 	if t.State == task.Failed {
@@ -1365,7 +1978,7 @@ func (svc *service) updateResultsHandler(ctx context.Context, msg map[string]any
 			svc.logger.ErrorContext(ctx, "failed to trigger workflow coordinator", "task_id", taskID, "error", err)
 		}
 
-		return nil
+		return
 	}
 
 	allCompleted := true
@@ -1391,11 +2004,49 @@ func (svc *service) updateResultsHandler(ctx context.Context, msg map[string]any
 	if err := svc.coordinator.OnTaskCompletion(ctx, taskID); err != nil {
 		svc.logger.ErrorContext(ctx, "failed to trigger workflow coordinator", "task_id", taskID, "error", err)
 	}
+}
+
+// queueTask adds t to the pending queue and persists when it was queued, so
+// that recoverQueued can put it back after a restart.
+func (svc *service) queueTask(ctx context.Context, t task.Task) error {
+	if t.QueuedAt.IsZero() {
+		t.QueuedAt = time.Now()
+		if err := svc.taskRepo.Update(ctx, t); err != nil {
+			return err
+		}
+	}
+	svc.pending.Push(t)
 
 	return nil
 }
 
+// recoverQueued puts the tasks that were waiting for a proplet when the
+// manager went down back in the pending queue. They are started by the next
+// schedulePending, e.g. on the first proplet heartbeat.
+func (svc *service) recoverQueued(tasks []task.Task) {
+	for i := range tasks {
+		if !tasks[i].QueuedAt.IsZero() {
+			svc.pending.Push(tasks[i])
+		}
+	}
+}
+
+// schedulePending retries queued tasks highest priority first. Tasks that still
+// find no proplet are queued again by StartTask.
+func (svc *service) schedulePending(ctx context.Context) {
+	if svc.shuttingDown.Load() || svc.pending.Len() == 0 {
+		return
+	}
+
+	for _, t := range svc.pending.Drain() {
+		if err := svc.StartTask(ctx, t.ID); err != nil {
+			svc.logger.WarnContext(ctx, "failed to start queued task", "task_id", t.ID, "error", err)
+		}
+	}
+}
+
 func (svc *service) startJobDependentTasks(ctx context.Context, jobTasks []task.Task, completedTaskID string) {
+	jobTasks = scheduler.GetReadyTasksByPriority(jobTasks)
 	for i := range jobTasks {
 		t := &jobTasks[i]
 		if t.State != task.Pending {
@@ -1446,21 +2097,70 @@ func (svc *service) processRoundStart(ctx context.Context, msg map[string]any) {
 	roundCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
+	roundCtx, span := tracer.Start(roundCtx, "fl-round-start")
+	defer span.End()
+
 	roundConfig, err := svc.parseRoundStartMessage(roundCtx, msg)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
 		return
 	}
+	span.SetAttributes(attribute.String("round.id", roundConfig.roundID))
 
 	participants := svc.extractParticipants(roundCtx, msg)
+	span.SetAttributes(attribute.Int("round.participants", len(participants)))
+	if len(participants) == 0 {
+		return
+	}
+
+	participants = svc.unlaunchedParticipants(roundCtx, roundConfig.roundID, participants)
+	participants = svc.reserveParticipants(roundCtx, roundConfig.roundID, participants)
 	if len(participants) == 0 {
+		svc.logger.InfoContext(roundCtx, "round already launched, ignoring duplicate start", "round_id", roundConfig.roundID)
+
+		return
+	}
+
+	modelData, err := pullModel(roundCtx, svc.registry, roundConfig.modelURI)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		svc.logger.ErrorContext(roundCtx, "failed to pull starting model", "round_id", roundConfig.roundID, "model_uri", roundConfig.modelURI, "error", err)
+		for _, propletID := range participants {
+			svc.releaseParticipant(roundCtx, roundConfig.roundID, propletID)
+		}
+
 		return
 	}
 
+	svc.recordAudit(roundCtx, audit.Entry{
+		Actor:      plugin.SystemUserID,
+		Action:     "start",
+		EntityType: audit.EntityFLRound,
+		EntityID:   roundConfig.roundID,
+		NewState:   "running",
+		Metadata: map[string]string{
+			"model_uri":    roundConfig.modelURI,
+			"participants": strings.Join(participants, ","),
+		},
+	})
+
+	if modelData != "" {
+		roundCtx = withStartingModel(roundCtx, roundConfig.modelURI, modelData)
+	}
 	svc.launchTasksForParticipants(roundCtx, roundConfig, participants)
 }
 
 type roundConfig struct {
 	roundID       string
+	experimentID  string
+	quorum        fl.Quorum
+	maxUpdateAgeS int
+	graceS        int
+	startedAt     time.Time
+	position      roundPosition
 	modelURI      string
 	taskWasmImage string
 	hyperparams   map[string]any
@@ -1502,9 +2202,39 @@ func (svc *service) parseRoundStartMessage(roundCtx context.Context, msg map[str
 	}
 
 	hyperparams, _ := msg["hyperparams"].(map[string]any)
+	experimentID, _ := msg["experiment_id"].(string)
+	kOfN, _ := msg["k_of_n"].(float64)
+	maxUpdateAgeS, _ := msg["max_update_age_s"].(float64)
+	graceS, _ := msg["aggregation_grace_period_s"].(float64)
+	round, _ := msg["round"].(float64)
+	totalRounds, _ := msg["total_rounds"].(float64)
+	startedAt := time.Now()
+	if v, ok := msg["started_at"].(string); ok {
+		if ts, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			startedAt = ts
+		}
+	}
+	quorum := fl.Quorum{Policy: fl.QuorumAnyK, K: int(kOfN)}
+	if policy, ok := msg["quorum_policy"].(string); ok && policy != "" {
+		quorum.Policy = fl.QuorumPolicy(policy)
+	}
+	if required, ok := msg["required_participants"].([]any); ok {
+		for _, r := range required {
+			if id, ok := r.(string); ok && id != "" {
+				quorum.Required = append(quorum.Required, id)
+			}
+		}
+	}
+	quorum = defaultQuorumK(quorum, len(participantsRaw))
 
 	return roundConfig{
 		roundID:       roundID,
+		experimentID:  experimentID,
+		quorum:        quorum,
+		maxUpdateAgeS: int(maxUpdateAgeS),
+		graceS:        int(graceS),
+		startedAt:     startedAt,
+		position:      roundPosition{round: uint64(round), total: uint64(totalRounds)},
 		modelURI:      modelURI,
 		taskWasmImage: taskWasmImage,
 		hyperparams:   hyperparams,
@@ -1531,15 +2261,80 @@ func (svc *service) extractParticipants(roundCtx context.Context, msg map[string
 	return participants
 }
 
-func (svc *service) launchTasksForParticipants(roundCtx context.Context, config roundConfig, participants []string) {
+// unlaunchedParticipants drops participants that already have a task for
+// roundID. Round tasks are persisted with the round in their metadata, so a
+// start redelivered after a manager restart does not launch the round twice.
+func (svc *service) unlaunchedParticipants(ctx context.Context, roundID string, participants []string) []string {
+	tasks, err := listAllTasksFromRepo(ctx, svc.taskRepo, task.Metadata{roundMetadataKey: roundID})
+	if err != nil {
+		svc.logger.WarnContext(ctx, "failed to look up launched round tasks", "round_id", roundID, "error", err)
+
+		return participants
+	}
+	launched := make(map[string]bool, len(tasks))
+	for i := range tasks {
+		launched[tasks[i].PropletID] = true
+	}
+
+	remaining := make([]string, 0, len(participants))
 	for _, propletID := range participants {
+		if launched[propletID] {
+			svc.logger.InfoContext(ctx, "participant already launched for round", "round_id", roundID, "proplet_id", propletID)
+
+			continue
+		}
+		remaining = append(remaining, propletID)
+	}
+
+	return remaining
+}
+
+// reserveParticipants returns the participants whose launch this manager
+// reserved in storage. The reservation is a conditional insert, so concurrent
+// starts of the same round, in this or another manager, launch each
+// participant once.
+func (svc *service) reserveParticipants(ctx context.Context, roundID string, participants []string) []string {
+	reserved := make([]string, 0, len(participants))
+	for _, propletID := range participants {
+		ok, err := svc.roundRepo.Reserve(ctx, roundID, propletID)
+		if err != nil {
+			svc.logger.ErrorContext(ctx, "failed to reserve round participant", "round_id", roundID, "proplet_id", propletID, "error", err)
+
+			continue
+		}
+		if !ok {
+			svc.logger.InfoContext(ctx, "participant already reserved for round", "round_id", roundID, "proplet_id", propletID)
+
+			continue
+		}
+		reserved = append(reserved, propletID)
+	}
+
+	return reserved
+}
+
+// releaseParticipant drops the reservation of a participant whose task was not
+// created, so a later start of the round can launch it.
+func (svc *service) releaseParticipant(ctx context.Context, roundID, propletID string) {
+	if err := svc.roundRepo.Release(context.WithoutCancel(ctx), roundID, propletID); err != nil {
+		svc.logger.WarnContext(ctx, "failed to release round participant", "round_id", roundID, "proplet_id", propletID, "error", err)
+	}
+}
+
+func (svc *service) launchTasksForParticipants(roundCtx context.Context, config roundConfig, participants []string) {
+	for i, propletID := range participants {
 		if roundCtx.Err() != nil {
-			svc.logger.WarnContext(roundCtx, "context cancelled during round processing", "round_id", config.roundID, "processed", len(participants))
+			svc.logger.WarnContext(roundCtx, "context cancelled during round processing", "round_id", config.roundID, "processed", i)
+			for _, pending := range participants[i:] {
+				svc.releaseParticipant(roundCtx, config.roundID, pending)
+			}
 
 			return
 		}
 
 		if !svc.isPropletAvailable(roundCtx, propletID) {
+			svc.releaseParticipant(roundCtx, config.roundID, propletID)
+
 			continue
 		}
 
@@ -1565,19 +2360,29 @@ func (svc *service) isPropletAvailable(roundCtx context.Context, propletID strin
 }
 
 func (svc *service) launchTaskForParticipant(roundCtx context.Context, config roundConfig, propletID string) {
+	roundCtx, span := tracer.Start(roundCtx, "fl-launch-participant", trace.WithAttributes(
+		attribute.String("round.id", config.roundID),
+		attribute.String("proplet.id", propletID),
+	))
+	defer span.End()
+
 	t := svc.createRoundTask(config, propletID)
 
 	created, err := svc.CreateTask(roundCtx, t)
 	if err != nil {
+		svc.releaseParticipant(roundCtx, config.roundID, propletID)
 		if roundCtx.Err() != nil {
 			svc.logger.WarnContext(roundCtx, "context cancelled during task creation", "round_id", config.roundID, "proplet_id", propletID)
 
 			return
 		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		svc.logger.ErrorContext(roundCtx, "failed to create task for participant", "proplet_id", propletID, "error", err)
 
 		return
 	}
+	span.SetAttributes(attribute.String("task.id", created.ID))
 
 	if err := svc.StartTask(roundCtx, created.ID); err != nil {
 		if roundCtx.Err() != nil {
@@ -1585,6 +2390,8 @@ func (svc *service) launchTaskForParticipant(roundCtx context.Context, config ro
 
 			return
 		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		svc.logger.ErrorContext(roundCtx, "failed to start task for participant", "proplet_id", propletID, "task_id", created.ID, "error", err)
 
 		return
@@ -1605,7 +2412,14 @@ func (svc *service) createRoundTask(config roundConfig, propletID string) task.T
 			"ROUND_ID":  config.roundID,
 			"MODEL_URI": config.modelURI,
 		},
+		Metadata: quorumMetadata(config.quorum),
 	}
+	t.Metadata[roundMetadataKey] = config.roundID
+	t.Metadata[experimentMetadataKey] = config.experimentID
+	t.Metadata[maxAgeMetadataKey] = strconv.Itoa(config.maxUpdateAgeS)
+	t.Metadata[graceMetadataKey] = strconv.Itoa(config.graceS)
+	t.Metadata[roundStartedMetadataKey] = config.startedAt.Format(time.RFC3339Nano)
+	stdmaps.Copy(t.Metadata, positionMetadata(config.position))
 
 	if config.hyperparams != nil {
 		hyperparamsJSON, err := json.Marshal(config.hyperparams)
@@ -1630,6 +2444,9 @@ func (svc *service) handleTaskMetrics(ctx context.Context, msg map[string]any) e
 	if !ok {
 		return errors.New("invalid proplet_id")
 	}
+	if !svc.ingestLimit.allow(ctx, ingestTaskMetrics, propletID) {
+		return nil
+	}
 
 	taskMetrics := TaskMetrics{
 		TaskID:    taskID,
@@ -1653,13 +2470,15 @@ func (svc *service) handleTaskMetrics(ctx context.Context, msg map[string]any) e
 		taskMetrics.Aggregated = svc.parseAggregatedMetrics(aggData)
 	}
 
-	if err := svc.metricsRepo.CreateTaskMetrics(ctx, taskMetrics); err != nil {
-		svc.logger.WarnContext(ctx, "failed to store task metrics", "error", err, "task_id", taskID)
+	return svc.storeMetrics(ctx, func(ctx context.Context) error {
+		if err := svc.metricsRepo.CreateTaskMetrics(ctx, taskMetrics); err != nil {
+			svc.logger.WarnContext(ctx, "failed to store task metrics", "error", err, "task_id", taskID)
 
-		return err
-	}
+			return err
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (svc *service) handlePropletMetrics(ctx context.Context, msg map[string]any) error {
@@ -1670,6 +2489,9 @@ func (svc *service) handlePropletMetrics(ctx context.Context, msg map[string]any
 	if propletID == "" {
 		return errors.New("proplet id is empty")
 	}
+	if !svc.ingestLimit.allow(ctx, ingestPropletStats, propletID) {
+		return nil
+	}
 	namespace, _ := msg["namespace"].(string)
 
 	propletMetrics := PropletMetrics{
@@ -1688,18 +2510,34 @@ func (svc *service) handlePropletMetrics(ctx context.Context, msg map[string]any
 
 	if cpuData, ok := msg["cpu_metrics"].(map[string]any); ok {
 		propletMetrics.CPU = svc.parseCPUMetrics(cpuData)
+		svc.load.observeCPU(propletID, propletMetrics.CPU.Percent)
 	}
 
 	if memData, ok := msg["memory_metrics"].(map[string]any); ok {
 		propletMetrics.Memory = svc.parseMemoryMetrics(memData)
 	}
 
-	if err := svc.metricsRepo.CreatePropletMetrics(ctx, propletMetrics); err != nil {
-		svc.logger.WarnContext(ctx, "failed to store proplet metrics", "error", err, "proplet_id", propletID)
+	return svc.storeMetrics(ctx, func(ctx context.Context) error {
+		if err := svc.metricsRepo.CreatePropletMetrics(ctx, propletMetrics); err != nil {
+			svc.logger.WarnContext(ctx, "failed to store proplet metrics", "error", err, "proplet_id", propletID)
 
-		return err
+			return err
+		}
+
+		return nil
+	})
+}
+
+// storeMetrics runs store inline, or on the metrics workers when they are
+// enabled. Failed asynchronous writes are only logged.
+func (svc *service) storeMetrics(ctx context.Context, store func(ctx context.Context) error) error {
+	if svc.metricsIngest == nil {
+		return store(ctx)
 	}
 
+	detached := context.WithoutCancel(ctx)
+	svc.metricsIngest.submit(ctx, func() { _ = store(detached) })
+
 	return nil
 }
 
@@ -1805,19 +2643,38 @@ func (svc *service) parseMemoryMetrics(data map[string]any) proplet.MemoryMetric
 	return metrics
 }
 
-// listAllTasksFromRepo paginates through all tasks in the given repository.
-func listAllTasksFromRepo(ctx context.Context, repo storage.TaskRepository) ([]task.Task, error) {
+// repoErr adds the failed operation to a storage error. The storage
+// not-found errors are also marked with pkgerrors.ErrNotFound, so callers and
+// the API can tell a missing entity from a failing backend.
+func repoErr(err error, format string, args ...any) error {
+	op := fmt.Sprintf(format, args...)
+	if errors.Is(err, storage.ErrTaskNotFound) || errors.Is(err, storage.ErrPropletNotFound) || errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("%s: %w: %w", op, pkgerrors.ErrNotFound, err)
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// listAllTasksFromRepo paginates through all tasks in the given repository
+// whose metadata matches filter.
+func listAllTasksFromRepo(ctx context.Context, repo storage.TaskRepository, filter task.Metadata) ([]task.Task, error) {
+	return queryAllTasks(ctx, repo, task.Query{Metadata: filter})
+}
+
+// queryAllTasks returns every task matching q, reading them page by page.
+// The query's offset and limit are ignored.
+func queryAllTasks(ctx context.Context, repo storage.TaskRepository, q task.Query) ([]task.Task, error) {
 	const pageSize uint64 = 100
 	var all []task.Task
-	var offset uint64
+	q.Offset, q.Limit = 0, pageSize
 	for {
-		page, total, err := repo.List(ctx, nil, offset, pageSize)
+		page, total, err := repo.Query(ctx, q)
 		if err != nil {
 			return nil, err
 		}
 		all = append(all, page...)
-		offset += uint64(len(page))
-		if offset >= total || len(page) == 0 {
+		q.Offset += uint64(len(page))
+		if q.Offset >= total || len(page) == 0 {
 			break
 		}
 	}
@@ -1826,7 +2683,7 @@ func listAllTasksFromRepo(ctx context.Context, repo storage.TaskRepository) ([]t
 }
 
 func (svc *service) listAllTasks(ctx context.Context) ([]task.Task, error) {
-	return listAllTasksFromRepo(ctx, svc.taskRepo)
+	return listAllTasksFromRepo(ctx, svc.taskRepo, nil)
 }
 
 func (svc *service) pinTaskToProplet(ctx context.Context, taskID, propletID string) error {
@@ -1855,7 +2712,11 @@ type startPayload struct {
 	MonitoringProfile *proplet.MonitoringProfile `json:"monitoring_profile,omitempty"`
 	PropletID         string                     `json:"proplet_id,omitempty"`
 	HalStoragePath    *string                    `json:"hal_storage_path,omitempty"`
+	MaxInstructions   uint64                     `json:"max_instructions,omitempty"`
 	ParentResults     map[string]any             `json:"parent_results,omitempty"`
+	// Traceparent carries the W3C trace context of the start request so the
+	// proplet can continue the trace and echo it back with the results.
+	Traceparent string `json:"traceparent,omitempty"`
 	// Metadata is intentionally excluded: it is a manager-side filtering field
 	// and is not needed by the proplet runtime.
 }
@@ -1877,6 +2738,42 @@ func (svc *service) publishStart(ctx context.Context, t task.Task, propletID str
 		MonitoringProfile: t.MonitoringProfile,
 		PropletID:         propletID,
 		HalStoragePath:    t.HalStoragePath,
+		MaxInstructions:   t.MaxInstructions,
+	}
+
+	if len(t.InputsFrom) > 0 {
+		env, err := svc.injectInputs(ctx, t)
+		if err != nil {
+			return err
+		}
+		payload.Env = env
+	}
+
+	payload.Env = svc.expandEnv(ctx, t, propletID, svc.withDefaultEnv(payload.Env))
+
+	env, err := svc.injectSecrets(ctx, t, payload.Env)
+	if err != nil {
+		return err
+	}
+	payload.Env = env
+
+	if t.Mode == task.ModeInfer {
+		payload.Env = svc.injectGlobalModel(ctx, payload.Env)
+	}
+	if _, ok := t.Metadata[roundMetadataKey]; ok {
+		env, err := svc.injectStartingModel(ctx, payload.Env)
+		if err != nil {
+			return err
+		}
+		payload.Env = env
+	}
+
+	if t.MonitoringProfile != nil {
+		profile, err := t.MonitoringProfile.Resolve()
+		if err != nil {
+			return fmt.Errorf("%w: %w", pkgerrors.ErrInvalidValue, err)
+		}
+		payload.MonitoringProfile = &profile
 	}
 
 	if len(t.DependsOn) > 0 {
@@ -1888,11 +2785,94 @@ func (svc *service) publishStart(ctx context.Context, t task.Task, propletID str
 		payload.ParentResults = parentResults
 	}
 
+	carrier := propagation.MapCarrier{}
+	traceContext.Inject(ctx, carrier)
+	payload.Traceparent = carrier.Get(traceparentKey)
+
 	topic := svc.baseTopic + "/control/manager/start"
+	// Only the env keys are logged: the payload carries resolved secrets and
+	// model data that must not reach the logs whatever their names.
+	svc.logger.DebugContext(ctx, "publishing task start", "task_id", t.ID, "proplet_id", propletID, "env_keys", slices.Sorted(stdmaps.Keys(payload.Env)))
 
 	return svc.pubsub.Publish(ctx, topic, payload)
 }
 
+// injectInputs returns a copy of t.Env with each InputsFrom source task's
+// results serialized as JSON into the named variable.
+func (svc *service) injectInputs(ctx context.Context, t task.Task) (map[string]string, error) {
+	env := make(map[string]string, len(t.Env)+len(t.InputsFrom))
+	stdmaps.Copy(env, t.Env)
+	for srcID, name := range t.InputsFrom {
+		src, err := svc.GetTask(ctx, srcID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get input source task %s: %w", srcID, err)
+		}
+		data, err := json.Marshal(src.Results)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal results of task %s: %w", srcID, err)
+		}
+		env[name] = string(data)
+	}
+
+	return env, nil
+}
+
+func validateInputsFrom(t task.Task) error {
+	for srcID, name := range t.InputsFrom {
+		if name == "" {
+			return fmt.Errorf("%w: inputs_from env var for task %s is empty", pkgerrors.ErrInvalidValue, srcID)
+		}
+		if !slices.Contains(t.DependsOn, srcID) {
+			return fmt.Errorf("%w: inputs_from source %s must be listed in depends_on", pkgerrors.ErrInvalidValue, srcID)
+		}
+	}
+
+	return nil
+}
+
+func validateMonitoringProfile(t task.Task) error {
+	if t.MonitoringProfile == nil {
+		return nil
+	}
+	if _, err := t.MonitoringProfile.Resolve(); err != nil {
+		return fmt.Errorf("%w: task %s: %w", pkgerrors.ErrInvalidValue, t.Name, err)
+	}
+
+	return nil
+}
+
+// validateFLTask checks the FL spec of a federated task, which is either of
+// the federated kind or trains in a round named by its ROUND_ID env, and
+// rewrites its FL_FORMAT to the canonical spelling the proplet expects.
+func validateFLTask(t *task.Task) error {
+	roundID := t.Env["ROUND_ID"]
+	if t.Kind != task.TaskKindFederated && roundID == "" {
+		return nil
+	}
+
+	spec := fl.Spec{
+		RoundID:      roundID,
+		UpdateFormat: t.Env["FL_FORMAT"],
+	}
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("%w: task %s: %w", pkgerrors.ErrInvalidValue, t.Name, err)
+	}
+	if format, _ := fl.NormalizeUpdateFormat(t.Env["FL_FORMAT"]); format != t.Env["FL_FORMAT"] {
+		t.Env = stdmaps.Clone(t.Env)
+		t.Env["FL_FORMAT"] = format
+		if format == "" {
+			delete(t.Env, "FL_FORMAT")
+		}
+	}
+	if v, ok := t.Env["FL_NUM_SAMPLES"]; ok {
+		if n, err := strconv.ParseUint(v, 10, 64); err != nil || n == 0 {
+			return fmt.Errorf("%w: task %s: FL_NUM_SAMPLES must be a positive integer, got %q", pkgerrors.ErrInvalidValue, t.Name, v)
+		}
+	}
+
+	return nil
+}
+
 func (svc *service) publishStop(ctx context.Context, t task.Task, propletID string) error {
 	stopPayload := map[string]any{
 		"id":         t.ID,
@@ -1953,19 +2933,48 @@ func (svc *service) selectPropletWithConstraints(ctx context.Context, t task.Tas
 		if !propletMatchesConstraints(p, constraints) {
 			continue
 		}
+		if !placeable(&p, t) {
+			continue
+		}
 		candidates = append(candidates, p)
 	}
 
+	if available := svc.unsaturated(candidates); len(available) < len(candidates) {
+		if len(available) == 0 {
+			return proplet.Proplet{}, errSaturated
+		}
+		candidates = available
+	}
+
 	if len(candidates) == 0 {
-		hasConstraints := len(constraints.RequiredTags) > 0 || constraints.MinMemoryBytes != nil
+		hasConstraints := len(constraints.RequiredTags) > 0 || constraints.MinMemoryBytes != nil ||
+			t.Group != "" || t.Namespace != ""
 		if hasConstraints {
-			return proplet.Proplet{}, errors.New("no proplet satisfies plugin-required constraints")
+			return proplet.Proplet{}, errNoMatch
 		}
 
-		return proplet.Proplet{}, errors.New("no active proplets available")
+		return proplet.Proplet{}, errNoProplets
 	}
 
-	return svc.scheduler.SelectProplet(t, candidates)
+	// The listing can be stale by the time the task is dispatched, so each
+	// choice is re-read and proplets that stopped heartbeating are dropped.
+	for len(candidates) > 0 {
+		p, err := svc.scheduler.SelectProplet(t, candidates)
+		if err != nil {
+			return proplet.Proplet{}, err
+		}
+		current, err := svc.GetProplet(ctx, p.ID)
+		if err != nil && !errors.Is(err, pkgerrors.ErrNotFound) {
+			return proplet.Proplet{}, err
+		}
+		if err == nil && current.Alive {
+			return current, nil
+		}
+		svc.logger.WarnContext(ctx, "selected proplet is no longer alive, reselecting", "task_id", t.ID, "proplet_id", p.ID)
+		candidates = slices.DeleteFunc(candidates, func(c proplet.Proplet) bool { return c.ID == p.ID })
+	}
+
+	return proplet.Proplet{}, errNoProplets
 }
 
 func propletMatchesConstraints(p proplet.Proplet, c plugin.PropletSelectResponse) bool {
@@ -2044,6 +3053,31 @@ func (svc *service) notifyTaskComplete(ctx context.Context, t task.Task) {
 	}
 }
 
+// releasePropletTask decrements the task count of a freshly read proplet,
+// undoing an earlier bump without clobbering concurrent changes.
+func (svc *service) releasePropletTask(ctx context.Context, propletID string) error {
+	p, err := svc.propletRepo.Get(ctx, propletID)
+	if err != nil {
+		return err
+	}
+
+	return svc.bumpPropletTaskCount(ctx, p, -1)
+}
+
+// rollback collects the undo steps of a multi-step change and runs them in
+// reverse order when a later step fails.
+type rollback []func()
+
+func (r *rollback) add(undo func()) {
+	*r = append(*r, undo)
+}
+
+func (r rollback) run() {
+	for i := len(r) - 1; i >= 0; i-- {
+		r[i]()
+	}
+}
+
 func (svc *service) bumpPropletTaskCount(ctx context.Context, p proplet.Proplet, delta int64) error {
 	newCount := max(int64(p.TaskCount)+delta, 0)
 	p.TaskCount = uint64(newCount)
@@ -2051,8 +3085,77 @@ func (svc *service) bumpPropletTaskCount(ctx context.Context, p proplet.Proplet,
 	return svc.propletRepo.Update(ctx, p)
 }
 
+// recordAudit appends entry to the audit log, stamping the time and, when not
+// already set, the actor from ctx, and publishes it to live event
+// subscribers. Failures are logged, not returned, so auditing never blocks a
+// state transition.
+func (svc *service) recordAudit(ctx context.Context, entry audit.Entry) {
+	entry.Timestamp = time.Now().UTC()
+	if entry.Actor == "" {
+		entry.Actor = auditActor(ctx)
+	}
+	if err := svc.auditLog.Append(ctx, entry); err != nil {
+		svc.logger.WarnContext(ctx, "failed to write audit entry",
+			"action", entry.Action, "entity_type", entry.EntityType, "entity_id", entry.EntityID, "error", err)
+	}
+
+	svc.events.Publish(events.Event{
+		Type:       entry.EntityType + "." + entry.Action,
+		Timestamp:  entry.Timestamp,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     entry.Action,
+		OldState:   entry.OldState,
+		NewState:   entry.NewState,
+		Metadata:   entry.Metadata,
+	})
+}
+
+func (svc *service) auditTask(ctx context.Context, action string, t task.Task, oldState string) {
+	svc.recordAudit(ctx, audit.Entry{
+		Action:     action,
+		EntityType: audit.EntityTask,
+		EntityID:   t.ID,
+		OldState:   oldState,
+		NewState:   t.State.String(),
+		Metadata:   taskAuditMetadata(t),
+	})
+}
+
+func auditActor(ctx context.Context) string {
+	if userID := plugin.AuthFromContext(ctx).UserID; userID != "" {
+		return userID
+	}
+
+	return "anonymous"
+}
+
+func taskAuditMetadata(t task.Task) map[string]string {
+	md := make(map[string]string)
+	if roundID := t.Env["ROUND_ID"]; roundID != "" {
+		md["round_id"] = roundID
+	}
+	if t.PropletID != "" {
+		md["proplet_id"] = t.PropletID
+	}
+	if t.WorkflowID != "" {
+		md["workflow_id"] = t.WorkflowID
+	}
+	if t.JobID != "" {
+		md["job_id"] = t.JobID
+	}
+	if len(md) == 0 {
+		return nil
+	}
+
+	return md
+}
+
 func (svc *service) markTaskRunning(ctx context.Context, t *task.Task) error {
 	t.State = task.Running
+	t.Attempts++
+	t.QueuedAt = time.Time{}
+	t.RetryAt = time.Time{}
 	t.StartTime = time.Now()
 	t.UpdatedAt = time.Now()
 
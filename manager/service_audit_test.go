@@ -0,0 +1,150 @@
+package manager_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/audit"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/plugin"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type auditStep struct {
+	actor    string
+	action   string
+	entity   string
+	oldState string
+	newState string
+}
+
+func auditSteps(entries []audit.Entry, match func(audit.Entry) bool) []auditStep {
+	var steps []auditStep
+	for _, e := range entries {
+		if match(e) {
+			steps = append(steps, auditStep{e.Actor, e.Action, e.EntityType, e.OldState, e.NewState})
+		}
+	}
+
+	return steps
+}
+
+func TestAuditFullRound(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+
+	var (
+		mu       sync.Mutex
+		handlers = map[string]mqtt.Handler{}
+	)
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+	}).Return(nil).Maybe()
+
+	auditLog := audit.NewMemoryLog()
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", srv.URL, slog.Default(), nil, manager.WithAuditLog(auditLog))
+	ctx := plugin.ContextWithAuth(context.Background(), plugin.AuthContext{UserID: "alice"})
+	require.NoError(t, svc.Subscribe(context.Background()))
+
+	handle := handlers["m/test-domain/c/test-channel/#"]
+	require.NoError(t, handle(testCreateTopic, map[string]any{"proplet_id": "proplet-a"}))
+	require.NoError(t, handle(testAliveTopic, map[string]any{"proplet_id": "proplet-a"}))
+
+	require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a"},
+		KOfN:          1,
+		TaskWasmImage: "oci://example/fl-client:latest",
+	}))
+	require.NoError(t, handlers[testRoundStartTopic](testRoundStartTopic, map[string]any{
+		"round_id":        "round-1",
+		"model_uri":       "fl/models/global_model_v0",
+		"task_wasm_image": "oci://example/fl-client:latest",
+		"participants":    []any{"proplet-a"},
+	}))
+
+	inRound := func(e audit.Entry) bool {
+		return e.EntityID == "round-1" || e.Metadata["round_id"] == "round-1"
+	}
+	require.Eventually(t, func() bool {
+		return len(auditSteps(auditLog.Entries(), inRound)) == 4
+	}, time.Second, 10*time.Millisecond)
+
+	var taskID string
+	for _, e := range auditLog.Entries() {
+		if e.EntityType == audit.EntityTask && inRound(e) {
+			taskID = e.EntityID
+		}
+	}
+	require.NotEmpty(t, taskID)
+	require.NoError(t, handle(testResultsTopic, map[string]any{
+		"task_id":    taskID,
+		"proplet_id": "proplet-a",
+		"results":    map[string]any{"w": []any{0.1}, "b": 0.2},
+	}))
+	require.NoError(t, handlers["fl/rounds/next"]("fl/rounds/next", map[string]any{
+		"round_id":          "round-1",
+		"new_model_version": 1.0,
+	}))
+
+	pending, running, completed := task.Pending.String(), task.Running.String(), task.Completed.String()
+	assert.Equal(t, []auditStep{
+		{"alice", "configure", audit.EntityFLRound, "", "configured"},
+		{plugin.SystemUserID, "start", audit.EntityFLRound, "", "running"},
+		{"anonymous", "create", audit.EntityTask, "", pending},
+		{"anonymous", "start", audit.EntityTask, pending, running},
+		{"proplet:proplet-a", "report-results", audit.EntityTask, running, completed},
+		{"proplet:proplet-a", "update", audit.EntityFLRound, "running", "running"},
+		{plugin.SystemUserID, "complete", audit.EntityFLRound, "running", "completed"},
+		{plugin.SystemUserID, "aggregate", audit.EntityFLRound, "completed", "aggregated"},
+	}, auditSteps(auditLog.Entries(), inRound))
+
+	for _, e := range auditLog.Entries() {
+		assert.False(t, e.Timestamp.IsZero(), "entry %s/%s has no timestamp", e.EntityType, e.Action)
+	}
+}
+
+func TestAuditStopTask(t *testing.T) {
+	t.Parallel()
+	auditLog := audit.NewMemoryLog()
+	svc, rec := newRecordingService(t, manager.WithAuditLog(auditLog))
+	registerProplet(t, rec, "proplet-1")
+	ctx := context.Background()
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "stoppable"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	require.NoError(t, svc.StopTask(ctx, created.ID))
+
+	running := task.Running.String()
+	assert.Equal(t, []auditStep{
+		{"anonymous", "create", audit.EntityTask, "", task.Pending.String()},
+		{"anonymous", "start", audit.EntityTask, task.Pending.String(), running},
+		{"anonymous", "stop", audit.EntityTask, running, running},
+	}, auditSteps(auditLog.Entries(), func(e audit.Entry) bool { return e.EntityID == created.ID }))
+}
@@ -0,0 +1,116 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropletBreakerSkipsFailingProplet(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t, manager.WithBreaker(manager.BreakerConfig{
+		FailureRate: 0.5,
+		Window:      2,
+		Cooldown:    200 * time.Millisecond,
+	}))
+	ctx := context.Background()
+	for _, id := range []string{"proplet-1", "proplet-2"} {
+		require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": id}))
+		require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": id}))
+	}
+
+	startOn := func(propletID string) string {
+		created, err := svc.CreateTask(ctx, task.Task{Name: "task", PropletID: propletID})
+		require.NoError(t, err)
+		require.NoError(t, svc.StartTask(ctx, created.ID))
+
+		starts := rec.startsOf(created.ID)
+		require.Len(t, starts, 1)
+
+		return starts[0]
+	}
+
+	for range 2 {
+		created, err := svc.CreateTask(ctx, task.Task{Name: "pinned", PropletID: "proplet-1"})
+		require.NoError(t, err)
+		require.NoError(t, svc.StartTask(ctx, created.ID))
+		reportResult(t, rec, created.ID, "proplet-1", "hardware fault")
+	}
+
+	p, err := svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	require.NotNil(t, p.Breaker)
+	assert.True(t, p.Breaker.Open)
+	assert.Equal(t, 2, p.Breaker.RecentFailures)
+	assert.NotNil(t, p.Breaker.OpenUntil)
+
+	for range 4 {
+		assert.Equal(t, "proplet-2", startOn(""))
+	}
+
+	time.Sleep(250 * time.Millisecond)
+
+	p, err = svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	require.NotNil(t, p.Breaker)
+	assert.False(t, p.Breaker.Open)
+	assert.Zero(t, p.Breaker.RecentResults)
+
+	var proplets []string
+	for range 2 {
+		proplets = append(proplets, startOn(""))
+	}
+	assert.ElementsMatch(t, []string{"proplet-1", "proplet-2"}, proplets)
+}
+
+func TestPropletBreakerIgnoresMixedResults(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t, manager.WithBreaker(manager.BreakerConfig{
+		FailureRate: 0.75,
+		Window:      4,
+	}))
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	for _, errMsg := range []string{"fault", "", "fault", ""} {
+		created, err := svc.CreateTask(ctx, task.Task{Name: "task"})
+		require.NoError(t, err)
+		require.NoError(t, svc.StartTask(ctx, created.ID))
+		reportResult(t, rec, created.ID, "proplet-1", errMsg)
+	}
+
+	p, err := svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	require.NotNil(t, p.Breaker)
+	assert.False(t, p.Breaker.Open)
+	assert.Equal(t, 4, p.Breaker.RecentResults)
+	assert.Equal(t, 2, p.Breaker.RecentFailures)
+
+	_, err = svc.SelectProplet(ctx, task.Task{Name: "next"})
+	assert.NoError(t, err)
+}
+
+func TestPropletBreakerDisabledByDefault(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	for range 10 {
+		created, err := svc.CreateTask(ctx, task.Task{Name: "task"})
+		require.NoError(t, err)
+		require.NoError(t, svc.StartTask(ctx, created.ID))
+		reportResult(t, rec, created.ID, "proplet-1", "fault")
+	}
+
+	p, err := svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	assert.Nil(t, p.Breaker)
+}
@@ -0,0 +1,168 @@
+package manager_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testMetricsTopic = "m/test-domain/c/test-channel/control/proplet/metrics"
+
+func alive(propletID string, running, limit int) map[string]any {
+	return map[string]any{
+		"proplet_id":           propletID,
+		"running_tasks":        float64(running),
+		"max_concurrent_tasks": float64(limit),
+	}
+}
+
+func TestSaturatedPropletsKeepTaskPending(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"proplet-1", "proplet-2"} {
+		require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": id}))
+		require.NoError(t, rec.handler(testAliveTopic, alive(id, 2, 2)))
+	}
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "saturated"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	assert.Empty(t, rec.started())
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Pending, got.State)
+
+	_, err = svc.SelectProplet(ctx, got)
+	require.Error(t, err)
+
+	require.NoError(t, rec.handler(testAliveTopic, alive("proplet-1", 2, 2)))
+	assert.Empty(t, rec.started())
+
+	require.NoError(t, rec.handler(testAliveTopic, alive("proplet-2", 1, 2)))
+	assert.Equal(t, []string{created.ID}, rec.started())
+
+	got, err = svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Running, got.State)
+	assert.Equal(t, "proplet-2", got.PropletID)
+}
+
+func TestDispatchCountsAgainstCapacity(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, alive("proplet-1", 0, 1)))
+
+	first, err := svc.CreateTask(ctx, task.Task{Name: "first"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, first.ID))
+
+	second, err := svc.CreateTask(ctx, task.Task{Name: "second"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, second.ID))
+	assert.Equal(t, []string{first.ID}, rec.started())
+
+	err = rec.handler(testResultsTopic, map[string]any{
+		"task_id":    first.ID,
+		"proplet_id": "proplet-1",
+		"results":    "done",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{first.ID, second.ID}, rec.started())
+}
+
+func TestCPUThresholdKeepsTaskPending(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t, manager.WithMaxPropletCPUPercent(80))
+	ctx := context.Background()
+
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	err := rec.handler(testMetricsTopic, map[string]any{
+		"proplet_id":  "proplet-1",
+		"cpu_metrics": map[string]any{"percent": 95.0},
+	})
+	require.NoError(t, err)
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "hot"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	assert.Empty(t, rec.started())
+
+	err = rec.handler(testMetricsTopic, map[string]any{
+		"proplet_id":  "proplet-1",
+		"cpu_metrics": map[string]any{"percent": 20.0},
+	})
+	require.NoError(t, err)
+	require.NoError(t, rec.handler(testAliveTopic, alive("proplet-1", 0, 0)))
+	assert.Equal(t, []string{created.ID}, rec.started())
+}
+
+func TestFLUpdateReleasesSlot(t *testing.T) {
+	t.Parallel()
+	coordinator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer coordinator.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	var handler mqtt.Handler
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if h, ok := args.Get(2).(mqtt.Handler); ok && handler == nil {
+			handler = h
+		}
+	}).Return(nil).Maybe()
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", coordinator.URL, slog.Default(), nil)
+	ctx := context.Background()
+	require.NoError(t, svc.Subscribe(ctx))
+
+	require.NoError(t, handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, handler(testAliveTopic, alive("proplet-1", 0, 1)))
+
+	participant, err := svc.CreateTask(ctx, task.Task{
+		Name:     "participant",
+		Env:      map[string]string{"ROUND_ID": "round-1"},
+		Metadata: task.Metadata{"fl_round_id": "round-1"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, participant.ID))
+
+	next, err := svc.CreateTask(ctx, task.Task{Name: "next"})
+	require.NoError(t, err)
+	_, err = svc.SelectProplet(ctx, next)
+	require.Error(t, err, "the participant holds proplet-1's only slot")
+
+	require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+		RoundID:   "round-1",
+		PropletID: "proplet-1",
+		Update:    map[string]any{"w": []any{1.0}},
+	}))
+	p, err := svc.SelectProplet(ctx, next)
+	require.NoError(t, err)
+	assert.Equal(t, "proplet-1", p.ID)
+
+	err = handler(testResultsTopic, map[string]any{"task_id": participant.ID, "results": "done"})
+	require.NoError(t, err)
+	p, err = svc.SelectProplet(ctx, next)
+	require.NoError(t, err, "a late result does not free the slot twice")
+	assert.Equal(t, "proplet-1", p.ID)
+}
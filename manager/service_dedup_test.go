@@ -0,0 +1,106 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTaskMetricsTopic = "m/test-domain/c/test-channel/control/proplet/task_metrics"
+
+func TestDuplicateResultsProcessedOnce(t *testing.T) {
+	t.Parallel()
+	svc, tasks, handler := newFlakyService(t, 0)
+
+	created, err := svc.CreateTask(context.Background(), task.Task{Name: "dup"})
+	require.NoError(t, err)
+
+	msg := map[string]any{
+		"message_id": "msg-1",
+		"task_id":    created.ID,
+		"proplet_id": "proplet-1",
+		"results":    "done",
+	}
+	require.NoError(t, handler(testResultsTopic, msg))
+	require.NoError(t, handler(testResultsTopic, msg))
+	assert.Equal(t, int32(1), tasks.calls.Load())
+}
+
+func TestDuplicateMetricsStoredOnce(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+
+	propletMsg := map[string]any{
+		"message_id":  "metrics-1",
+		"proplet_id":  "proplet-1",
+		"cpu_metrics": map[string]any{"percent": 12.5},
+	}
+	require.NoError(t, rec.handler(testMetricsTopic, propletMsg))
+	require.NoError(t, rec.handler(testMetricsTopic, propletMsg))
+
+	propletPage, err := svc.GetPropletMetrics(ctx, "proplet-1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), propletPage.Total)
+
+	taskMsg := map[string]any{
+		"message_id": "task-metrics-1",
+		"task_id":    "task-1",
+		"proplet_id": "proplet-1",
+		"metrics":    map[string]any{"cpu_percent": 3.0},
+	}
+	require.NoError(t, rec.handler(testTaskMetricsTopic, taskMsg))
+	require.NoError(t, rec.handler(testTaskMetricsTopic, taskMsg))
+
+	taskPage, err := svc.GetTaskMetrics(ctx, "task-1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), taskPage.Total)
+}
+
+func TestMessagesWithoutIDAreNotDeduplicated(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+
+	msg := map[string]any{"proplet_id": "proplet-1"}
+	require.NoError(t, rec.handler(testMetricsTopic, msg))
+	require.NoError(t, rec.handler(testMetricsTopic, msg))
+
+	page, err := svc.GetPropletMetrics(context.Background(), "proplet-1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), page.Total)
+}
+
+func TestFailedMessageProcessedOnRedelivery(t *testing.T) {
+	t.Parallel()
+	svc, tasks, handler := newFlakyService(t, 1, retries(0))
+	ctx := context.Background()
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "retry-on-redelivery"})
+	require.NoError(t, err)
+
+	msg := map[string]any{"message_id": "msg-1", "task_id": created.ID, "results": "done"}
+	require.ErrorIs(t, handler(testResultsTopic, msg), errTransient)
+	require.NoError(t, handler(testResultsTopic, msg))
+	assert.Equal(t, int32(2), tasks.calls.Load())
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Completed, got.State)
+}
+
+func TestDeduplicationDisabled(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t, manager.WithDedup(manager.DedupConfig{}))
+
+	msg := map[string]any{"message_id": "metrics-1", "proplet_id": "proplet-1"}
+	require.NoError(t, rec.handler(testMetricsTopic, msg))
+	require.NoError(t, rec.handler(testMetricsTopic, msg))
+
+	page, err := svc.GetPropletMetrics(context.Background(), "proplet-1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), page.Total)
+}
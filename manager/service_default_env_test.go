@@ -0,0 +1,53 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTaskEnv(t *testing.T) {
+	t.Parallel()
+	defaults := manager.ParseDefaultTaskEnv("MANAGER_COORDINATOR_URL=http://coordinator:8080, MODEL_REGISTRY_URL=http://registry:8081,LOG_LEVEL=info,malformed")
+	svc, rec := newRecordingService(t, manager.WithDefaultTaskEnv(defaults))
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	env := startedEnv(t, svc, rec, task.Task{Name: "no-env"})
+	assert.Equal(t, map[string]any{
+		"MANAGER_COORDINATOR_URL": "http://coordinator:8080",
+		"MODEL_REGISTRY_URL":      "http://registry:8081",
+		"LOG_LEVEL":               "info",
+	}, env)
+
+	env = startedEnv(t, svc, rec, task.Task{
+		Name: "override",
+		Env:  map[string]string{"LOG_LEVEL": "debug", "INPUT": "x"},
+	})
+	assert.Equal(t, map[string]any{
+		"MANAGER_COORDINATOR_URL": "http://coordinator:8080",
+		"MODEL_REGISTRY_URL":      "http://registry:8081",
+		"LOG_LEVEL":               "debug",
+		"INPUT":                   "x",
+	}, env)
+
+	page, err := svc.ListTasks(context.Background(), manager.PageMetadata{Limit: 10})
+	require.NoError(t, err)
+	for _, tk := range page.Tasks {
+		assert.NotContains(t, tk.Env, "MODEL_REGISTRY_URL", "defaults are not stored on task %s", tk.Name)
+	}
+}
+
+func TestNoDefaultTaskEnv(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	env := startedEnv(t, svc, rec, task.Task{Name: "plain", Env: map[string]string{"INPUT": "x"}})
+	assert.Equal(t, map[string]any{"INPUT": "x"}, env)
+}
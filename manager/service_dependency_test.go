@@ -0,0 +1,143 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testResultsTopic = "m/test-domain/c/test-channel/control/proplet/results"
+
+func registerProplet(t *testing.T, rec *startRecorder, propletID string) {
+	t.Helper()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": propletID}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": propletID}))
+}
+
+func createChain(t *testing.T, svc manager.Service) []string {
+	t.Helper()
+	tasks, err := svc.CreateWorkflow(context.Background(), []task.Task{
+		{ID: "chain-a", Name: "a"},
+		{ID: "chain-b", Name: "b", DependsOn: []string{"chain-a"}},
+		{ID: "chain-c", Name: "c", DependsOn: []string{"chain-b"}},
+	})
+	require.NoError(t, err)
+
+	ids := make([]string, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+	}
+
+	return ids
+}
+
+func assertStates(t *testing.T, svc manager.Service, ids []string, want ...task.State) {
+	t.Helper()
+	for i, id := range ids {
+		got, err := svc.GetTask(context.Background(), id)
+		require.NoError(t, err)
+		assert.Equal(t, want[i], got.State, "task %s", id)
+	}
+}
+
+func TestDependencyChainStartsInOrder(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	registerProplet(t, rec, "proplet-1")
+
+	ids := createChain(t, svc)
+	for _, id := range ids {
+		require.NoError(t, svc.StartTask(ctx, id))
+	}
+	assertStates(t, svc, ids, task.Running, task.Blocked, task.Blocked)
+	assert.Equal(t, ids[:1], rec.started())
+
+	require.NoError(t, rec.handler(testResultsTopic, map[string]any{"task_id": ids[0], "results": "a-done"}))
+	assertStates(t, svc, ids, task.Completed, task.Running, task.Blocked)
+	assert.Equal(t, ids[:2], rec.started())
+
+	require.NoError(t, rec.handler(testResultsTopic, map[string]any{"task_id": ids[1], "results": "b-done"}))
+	assertStates(t, svc, ids, task.Completed, task.Completed, task.Running)
+	assert.Equal(t, ids, rec.started())
+
+	require.NoError(t, rec.handler(testResultsTopic, map[string]any{"task_id": ids[2], "results": "c-done"}))
+	assertStates(t, svc, ids, task.Completed, task.Completed, task.Completed)
+}
+
+func TestDependencyFailurePropagates(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	registerProplet(t, rec, "proplet-1")
+
+	ids := createChain(t, svc)
+	for _, id := range ids {
+		require.NoError(t, svc.StartTask(ctx, id))
+	}
+
+	require.NoError(t, rec.handler(testResultsTopic, map[string]any{"task_id": ids[0], "error": "boom"}))
+	assertStates(t, svc, ids, task.Failed, task.Failed, task.Failed)
+	assert.Equal(t, ids[:1], rec.started())
+
+	b, err := svc.GetTask(ctx, ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, "dependency chain-a failed", b.Error)
+
+	c, err := svc.GetTask(ctx, ids[2])
+	require.NoError(t, err)
+	assert.Equal(t, "dependency chain-b failed", c.Error)
+}
+
+func TestInputsFromInjectsUpstreamResults(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	registerProplet(t, rec, "proplet-1")
+
+	tasks, err := svc.CreateWorkflow(ctx, []task.Task{
+		{ID: "upstream", Name: "upstream"},
+		{
+			ID:         "downstream",
+			Name:       "downstream",
+			DependsOn:  []string{"upstream"},
+			Env:        map[string]string{"MODE": "fast"},
+			InputsFrom: map[string]string{"upstream": "UPSTREAM_RESULTS"},
+		},
+	})
+	require.NoError(t, err)
+	up, down := tasks[0].ID, tasks[1].ID
+
+	require.NoError(t, svc.StartTask(ctx, up))
+	require.NoError(t, svc.StartTask(ctx, down))
+
+	results := map[string]any{"sum": float64(3), "labels": []any{"a", "b"}}
+	require.NoError(t, rec.handler(testResultsTopic, map[string]any{"task_id": up, "results": results}))
+
+	payload := rec.payload(down)
+	require.NotNil(t, payload)
+	env, ok := payload["env"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "fast", env["MODE"])
+	assert.JSONEq(t, `{"sum":3,"labels":["a","b"]}`, env["UPSTREAM_RESULTS"].(string))
+
+	stored, err := svc.GetTask(ctx, down)
+	require.NoError(t, err)
+	assert.NotContains(t, stored.Env, "UPSTREAM_RESULTS")
+}
+
+func TestInputsFromRequiresDependency(t *testing.T) {
+	t.Parallel()
+	svc := newService(t)
+
+	_, err := svc.CreateWorkflow(context.Background(), []task.Task{
+		{ID: "upstream", Name: "upstream"},
+		{ID: "downstream", Name: "downstream", InputsFrom: map[string]string{"upstream": "UPSTREAM_RESULTS"}},
+	})
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+}
@@ -0,0 +1,61 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskEnvTemplating(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, svc.StoreModel(ctx, 3, manager.Model{Data: map[string]any{"w": []any{0.3}}}))
+
+	created, err := svc.CreateTask(ctx, task.Task{
+		Name: "train",
+		Env: map[string]string{
+			"ROUND_ID":   "round-7",
+			"OUTPUT":     "results/${round_id}/${id}",
+			"WORKER":     "${proplet_id}",
+			"BASE_MODEL": "fl/models/global_model_v${global_version}",
+			"JOB":        "${job_id}",
+			"CUSTOM":     "${not_a_field}-${name}",
+			"SHELL":      "$HOME",
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	env, _ := rec.payload(created.ID)["env"].(map[string]any)
+	assert.Equal(t, "results/round-7/"+created.ID, env["OUTPUT"])
+	assert.Equal(t, "proplet-1", env["WORKER"])
+	assert.Equal(t, "fl/models/global_model_v3", env["BASE_MODEL"])
+	assert.Equal(t, "${job_id}", env["JOB"], "unset fields stay unresolved")
+	assert.Equal(t, "${not_a_field}-train", env["CUSTOM"], "unknown variables stay as written")
+	assert.Equal(t, "$HOME", env["SHELL"])
+
+	stored, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "results/${round_id}/${id}", stored.Env["OUTPUT"], "the stored task keeps its template")
+}
+
+func TestDefaultTaskEnvTemplating(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t, manager.WithDefaultTaskEnv(map[string]string{"RUN_DIR": "/runs/${id}"}))
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	created, err := svc.CreateTask(context.Background(), task.Task{Name: "plain"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(context.Background(), created.ID))
+
+	env, _ := rec.payload(created.ID)["env"].(map[string]any)
+	assert.Equal(t, "/runs/"+created.ID, env["RUN_DIR"])
+}
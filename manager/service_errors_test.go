@@ -0,0 +1,114 @@
+package manager_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// failingTaskRepo fails every write with a storage error.
+type failingTaskRepo struct {
+	storage.TaskRepository
+}
+
+func (failingTaskRepo) Create(context.Context, task.Task) (task.Task, error) {
+	return task.Task{}, fmt.Errorf("%w: disk full", storage.ErrCreate)
+}
+
+func (failingTaskRepo) Update(context.Context, task.Task) error {
+	return fmt.Errorf("%w: disk full", storage.ErrUpdate)
+}
+
+func TestServiceErrorsKeepSentinels(t *testing.T) {
+	t.Parallel()
+	svc, _ := newServiceWithRepos(t)
+	ctx := context.Background()
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "unstarted"})
+	require.NoError(t, err)
+
+	cases := []struct {
+		desc string
+		err  error
+		want error
+		msg  string
+	}{
+		{
+			desc: "get missing task",
+			err:  func() error { _, err := svc.GetTask(ctx, "missing-task"); return err }(),
+			want: pkgerrors.ErrNotFound,
+			msg:  "missing-task",
+		},
+		{
+			desc: "update missing task",
+			err:  func() error { _, err := svc.UpdateTask(ctx, task.Task{ID: "missing-task"}); return err }(),
+			want: pkgerrors.ErrNotFound,
+			msg:  "missing-task",
+		},
+		{
+			desc: "get missing proplet",
+			err:  func() error { _, err := svc.GetProplet(ctx, "missing-proplet"); return err }(),
+			want: pkgerrors.ErrNotFound,
+			msg:  "missing-proplet",
+		},
+		{
+			desc: "delete missing proplet",
+			err:  svc.DeleteProplet(ctx, "missing-proplet"),
+			want: pkgerrors.ErrNotFound,
+			msg:  "missing-proplet",
+		},
+		{
+			desc: "stop task that never started",
+			err:  svc.StopTask(ctx, created.ID),
+			want: pkgerrors.ErrNotFound,
+			msg:  "failed to get proplet of task " + created.ID,
+		},
+		{
+			desc: "invalid task state filter",
+			err:  func() error { _, err := svc.ListTasks(ctx, manager.PageMetadata{State: "bogus"}); return err }(),
+			want: pkgerrors.ErrInvalidValue,
+		},
+	}
+	for _, tc := range cases {
+		require.Error(t, tc.err, tc.desc)
+		assert.ErrorIs(t, tc.err, tc.want, tc.desc)
+		assert.Contains(t, tc.err.Error(), tc.msg, tc.desc)
+	}
+}
+
+func TestServiceWrapsStorageFailures(t *testing.T) {
+	t.Parallel()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	existing, err := repos.Tasks.Create(context.Background(), task.Task{ID: "task-1", Name: "existing", Broadcast: true})
+	require.NoError(t, err)
+	repos.Tasks = failingTaskRepo{TaskRepository: repos.Tasks}
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil)
+	ctx := context.Background()
+
+	_, err = svc.CreateTask(ctx, task.Task{Name: "new"})
+	assert.ErrorIs(t, err, storage.ErrCreate)
+	assert.Contains(t, err.Error(), "failed to create task")
+	assert.NotErrorIs(t, err, pkgerrors.ErrNotFound)
+
+	_, err = svc.UpdateTask(ctx, task.Task{ID: existing.ID, Name: "renamed"})
+	assert.ErrorIs(t, err, storage.ErrUpdate)
+	assert.Contains(t, err.Error(), "failed to update task task-1")
+
+	err = svc.StartTask(ctx, existing.ID)
+	assert.ErrorIs(t, err, storage.ErrUpdate)
+	assert.Contains(t, err.Error(), "failed to persist task task-1")
+}
@@ -0,0 +1,50 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/events"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeEventsStreamsTaskLifecycle(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "watched"})
+	require.NoError(t, err)
+
+	sub, err := svc.SubscribeEvents(ctx, events.Filter{TaskID: created.ID})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	other, err := svc.CreateTask(ctx, task.Task{Name: "other"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, other.ID))
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	require.NotEmpty(t, sub.Events())
+	evt := <-sub.Events()
+	assert.Equal(t, "task.start", evt.Type)
+	assert.Equal(t, created.ID, evt.EntityID)
+	assert.Equal(t, task.Pending.String(), evt.OldState)
+	assert.Equal(t, task.Running.String(), evt.NewState)
+	assert.Equal(t, "proplet-1", evt.Metadata["proplet_id"])
+	assert.Empty(t, sub.Events())
+}
+
+func TestSubscribeEventsAfterShutdown(t *testing.T) {
+	t.Parallel()
+	svc, _ := newRecordingService(t)
+
+	require.NoError(t, svc.Shutdown(context.Background()))
+	_, err := svc.SubscribeEvents(context.Background(), events.Filter{})
+	require.Error(t, err)
+}
@@ -0,0 +1,234 @@
+package manager_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeJobState(t *testing.T, data []byte) manager.FLJobState {
+	t.Helper()
+	var state manager.FLJobState
+	require.NoError(t, json.Unmarshal(data, &state))
+	state.ExportedAt = time.Time{}
+	slices.SortFunc(state.Tasks, func(a, b task.Task) int { return strings.Compare(a.ID, b.ID) })
+
+	return state
+}
+
+func TestJobStateRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	coordinator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer coordinator.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	var mu sync.Mutex
+	handlers := map[string]mqtt.Handler{}
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+	}).Return(nil)
+	src, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", coordinator.URL, slog.Default(), nil)
+	ctx := context.Background()
+	require.NoError(t, src.Subscribe(ctx))
+
+	handle := handlers["m/test-domain/c/test-channel/#"]
+	participants := []string{"proplet-a", "proplet-b"}
+	for _, id := range participants {
+		require.NoError(t, handle(testCreateTopic, map[string]any{"proplet_id": id}))
+		require.NoError(t, handle(testAliveTopic, map[string]any{"proplet_id": id}))
+	}
+	require.NoError(t, src.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  participants,
+		KOfN:          2,
+		TaskWasmImage: "oci://example/fl-client:latest",
+	}))
+	require.NoError(t, handlers[testRoundStartTopic](testRoundStartTopic, map[string]any{
+		"round_id":        "round-1",
+		"experiment_id":   "exp-1",
+		"model_uri":       "fl/models/global_model_v0",
+		"task_wasm_image": "oci://example/fl-client:latest",
+		"participants":    []any{"proplet-a", "proplet-b"},
+	}))
+	for i, id := range participants {
+		require.NoError(t, src.PostFLUpdate(ctx, manager.FLUpdate{
+			RoundID:    "round-1",
+			PropletID:  id,
+			NumSamples: 10 * (i + 1),
+			Metrics:    map[string]any{"loss": 0.5},
+			Update:     map[string]any{"w": []any{0.1}, "b": 0.0},
+		}))
+	}
+	require.NoError(t, handlers["fl/rounds/next"]("fl/rounds/next", map[string]any{
+		"round_id":          "round-1",
+		"new_model_version": float64(1),
+		"model":             map[string]any{"w": []any{0.2}, "b": 0.1},
+	}))
+
+	data, err := src.ExportJobState(ctx, "exp-1")
+	require.NoError(t, err)
+	exported := decodeJobState(t, data)
+	assert.Equal(t, "exp-1", exported.JobID)
+	assert.Len(t, exported.Tasks, 2)
+	require.Len(t, exported.Rounds, 1)
+	assert.Equal(t, "round-1", exported.Rounds[0].RoundID)
+	assert.Contains(t, exported.Models, 1)
+
+	dst, _ := newServiceWithRepos(t)
+	require.NoError(t, dst.ImportJobState(ctx, data))
+
+	reexported, err := dst.ExportJobState(ctx, "exp-1")
+	require.NoError(t, err)
+	assert.Equal(t, exported, decodeJobState(t, reexported))
+
+	metrics, err := dst.GetFLJobMetrics(ctx, "exp-1")
+	require.NoError(t, err)
+	require.Len(t, metrics.Rounds, 1)
+	assert.Equal(t, 1, metrics.Rounds[0].ModelVersion)
+	assert.InDelta(t, 0.5, metrics.Rounds[0].Metrics["loss"], 1e-9)
+
+	model, err := dst.GetModel(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"w": []any{0.2}, "b": 0.1}, model.Data)
+
+	for _, tk := range exported.Tasks {
+		got, err := dst.GetTask(ctx, tk.ID)
+		require.NoError(t, err)
+		assert.Equal(t, tk.PropletID, got.PropletID)
+		assert.Equal(t, "round-1", got.Env["ROUND_ID"])
+	}
+}
+
+func TestJobStateErrors(t *testing.T) {
+	t.Parallel()
+	svc, _ := newServiceWithRepos(t)
+	ctx := context.Background()
+
+	_, err := svc.ExportJobState(ctx, "missing")
+	assert.ErrorIs(t, err, pkgerrors.ErrNotFound)
+
+	cases := []struct {
+		desc string
+		data string
+		err  error
+	}{
+		{
+			desc: "malformed bundle",
+			data: `{"version":`,
+			err:  pkgerrors.ErrInvalidData,
+		},
+		{
+			desc: "unknown version",
+			data: `{"version":2,"job_id":"exp-1"}`,
+			err:  pkgerrors.ErrInvalidValue,
+		},
+		{
+			desc: "missing job id",
+			data: `{"version":1}`,
+			err:  pkgerrors.ErrInvalidValue,
+		},
+		{
+			desc: "task of another job",
+			data: `{"version":1,"job_id":"exp-1","tasks":[{"id":"t1","metadata":{"fl_experiment_id":"exp-2"}}]}`,
+			err:  pkgerrors.ErrInvalidValue,
+		},
+	}
+	for _, tc := range cases {
+		assert.ErrorIs(t, svc.ImportJobState(ctx, []byte(tc.data)), tc.err, tc.desc)
+	}
+
+	_, err = svc.GetTask(ctx, "t1")
+	assert.ErrorIs(t, err, pkgerrors.ErrNotFound)
+}
+
+func TestJobStateImportRejectsUnsafeBundles(t *testing.T) {
+	t.Parallel()
+	svc, _ := newServiceWithRepos(t)
+	ctx := context.Background()
+
+	other, err := svc.CreateTask(ctx, task.Task{Name: "unrelated", Env: map[string]string{"MODE": "edge"}})
+	require.NoError(t, err)
+	require.NoError(t, svc.StoreModel(ctx, 3, manager.Model{Data: map[string]any{"w": []any{0.3}}}))
+
+	cases := []struct {
+		desc string
+		data string
+		err  error
+	}{
+		{
+			desc: "task id owned by another job",
+			data: `{"version":1,"job_id":"exp-1","tasks":[{"id":"` + other.ID + `","name":"hijack","metadata":{"fl_experiment_id":"exp-1"}}]}`,
+			err:  pkgerrors.ErrConflict,
+		},
+		{
+			desc: "invalid task after a valid one",
+			data: `{"version":1,"job_id":"exp-1","tasks":[` +
+				`{"id":"t-ok","metadata":{"fl_experiment_id":"exp-1"}},` +
+				`{"id":"t-bad","env":{"ROUND_ID":"r1","FL_NUM_SAMPLES":"0"},"metadata":{"fl_experiment_id":"exp-1"}}]}`,
+			err: pkgerrors.ErrInvalidValue,
+		},
+		{
+			desc: "model version that differs from the one held",
+			data: `{"version":1,"job_id":"exp-1","tasks":[{"id":"t-ok","metadata":{"fl_experiment_id":"exp-1"}}],"models":{"3":{"data":{"w":[0.9]}}}}`,
+			err:  pkgerrors.ErrConflict,
+		},
+	}
+	for _, tc := range cases {
+		assert.ErrorIs(t, svc.ImportJobState(ctx, []byte(tc.data)), tc.err, tc.desc)
+	}
+
+	got, err := svc.GetTask(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "unrelated", got.Name, "a task of another job is never overwritten")
+	_, err = svc.GetTask(ctx, "t-ok")
+	assert.ErrorIs(t, err, pkgerrors.ErrNotFound, "nothing is written when any part of the bundle is rejected")
+}
+
+func TestJobStateImportKeepsCurrentModel(t *testing.T) {
+	t.Parallel()
+	svc, _ := newServiceWithRepos(t)
+	ctx := context.Background()
+
+	require.NoError(t, svc.StoreModel(ctx, 2, manager.Model{Data: map[string]any{"w": []any{0.2}}}))
+	require.NoError(t, svc.StorePersonalizedModel(ctx, "client-1", manager.Model{Data: map[string]any{"w": []any{0.0}}}))
+
+	bundle := `{"version":1,"job_id":"exp-1","tasks":[{"id":"t1","metadata":{"fl_experiment_id":"exp-1"}}],` +
+		`"models":{"2":{"data":{"w":[0.2]}},"7":{"data":{"w":[0.7]}}}}`
+	require.NoError(t, svc.ImportJobState(ctx, []byte(bundle)))
+
+	imported, err := svc.GetModel(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"w": []any{0.7}}, imported.Data)
+
+	pm, err := svc.GetPersonalizedModel(ctx, "client-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, pm.GlobalVersion, "an imported model does not become the current global model")
+}
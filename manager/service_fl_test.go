@@ -0,0 +1,1586 @@
+package manager_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/events"
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newFLService(t *testing.T, coordinatorURL string, opts ...manager.Option) manager.Service {
+	t.Helper()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", coordinatorURL, slog.Default(), nil, opts...)
+
+	return svc
+}
+
+// newRoundNextService returns an FL service subscribed to its topics and the
+// handler it installed for coordinator aggregation notices.
+func newRoundNextService(t *testing.T, coordinatorURL string) (manager.Service, mqtt.Handler) {
+	t.Helper()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	var mu sync.Mutex
+	handlers := map[string]mqtt.Handler{}
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+	}).Return(nil)
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", coordinatorURL, slog.Default(), nil)
+	require.NoError(t, svc.Subscribe(context.Background()))
+	roundNext := handlers["fl/rounds/next"]
+	require.NotNil(t, roundNext)
+
+	return svc, roundNext
+}
+
+func TestGetRoundStatusParticipants(t *testing.T) {
+	t.Parallel()
+
+	round := fl.RoundState{
+		RoundID: "round-1",
+		KOfN:    2,
+		Updates: []fl.Update{
+			{
+				RoundID:    "round-1",
+				PropletID:  "proplet-a",
+				NumSamples: 120,
+				Metrics:    map[string]any{"loss": 0.25},
+				Update:     map[string]any{"w": []any{0.1, 0.2, 0.3}, "b": 0.5},
+				ReceivedAt: time.Now().UTC(),
+			},
+			{
+				RoundID:    "round-1",
+				PropletID:  "proplet-b",
+				NumSamples: 3,
+				Update:     map[string]any{"w": []any{}},
+			},
+		},
+	}
+
+	// coordinator-http reports round status flat; other coordinators wrap it
+	// in a "status" object. The manager must read both the same way.
+	flat := map[string]any{
+		"round_id":     round.RoundID,
+		"completed":    true,
+		"num_updates":  len(round.Updates),
+		"k_of_n":       round.KOfN,
+		"participants": round.Participants(),
+	}
+	cases := []struct {
+		desc string
+		body any
+	}{
+		{desc: "flat coordinator response", body: flat},
+		{desc: "wrapped coordinator response", body: map[string]any{"status": flat}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/rounds/round-1/complete", r.URL.Path)
+				_ = json.NewEncoder(w).Encode(tc.body)
+			}))
+			defer srv.Close()
+
+			svc := newFLService(t, srv.URL)
+			status, err := svc.GetRoundStatus(context.Background(), "round-1")
+			require.NoError(t, err)
+			assert.Equal(t, "round-1", status.RoundID)
+			assert.True(t, status.Completed)
+			assert.Equal(t, 2, status.NumUpdates)
+			assert.Equal(t, 2, status.KOfN)
+			require.Len(t, status.Participants, 2)
+
+			a := status.Participants[0]
+			assert.Equal(t, "proplet-a", a.PropletID)
+			assert.True(t, a.UpdateReceived)
+			assert.Equal(t, len(`{"b":0.5,"w":[0.1,0.2,0.3]}`), a.UpdateBytes)
+			assert.Equal(t, 120, a.NumSamples)
+			assert.InDelta(t, 0.25, a.Metrics["loss"], 1e-9)
+
+			b := status.Participants[1]
+			assert.Equal(t, "proplet-b", b.PropletID)
+			assert.Equal(t, len(`{"w":[]}`), b.UpdateBytes)
+			assert.Equal(t, 3, b.NumSamples)
+			assert.Empty(t, b.Metrics)
+		})
+	}
+}
+
+func TestConfigureExperimentAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	var received manager.ExperimentConfig
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	svc := newFLService(t, srv.URL)
+	config := manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a"},
+		TaskWasmImage: "oci://example/fl-client:latest",
+	}
+
+	config.Algorithm = "fedprox"
+	err := svc.ConfigureExperiment(context.Background(), config)
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+	assert.ErrorIs(t, err, fl.ErrUnknownAlgorithm)
+
+	config.Algorithm = fl.AlgorithmMedian
+	require.NoError(t, svc.ConfigureExperiment(context.Background(), config))
+	assert.Equal(t, fl.AlgorithmMedian, received.Algorithm)
+
+	config.KOfN = 2
+	err = svc.ConfigureExperiment(context.Background(), config)
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+	assert.ErrorIs(t, err, fl.ErrInvalidQuorum)
+}
+
+func TestConfigureExperimentCustomAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	var received manager.ExperimentConfig
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	const algorithm = "test-manager-first"
+	require.NoError(t, fl.RegisterAggregator(algorithm, func(updates []fl.UpdateEnvelope, _ map[string]any, _ uint64) (fl.UpdateEnvelope, error) {
+		return updates[0], nil
+	}))
+
+	svc := newFLService(t, srv.URL)
+	require.NoError(t, svc.ConfigureExperiment(context.Background(), manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a"},
+		TaskWasmImage: "oci://example/fl-client:latest",
+		Algorithm:     algorithm,
+	}))
+	assert.Equal(t, algorithm, received.Algorithm)
+}
+
+func TestUntrackedRoundUpdateIgnored(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	svc := newFLService(t, srv.URL)
+	ctx := context.Background()
+
+	sub, err := svc.SubscribeEvents(ctx, events.Filter{})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+		RoundID:   "round-unknown",
+		PropletID: "proplet-a",
+		Update:    map[string]any{"w": []any{0.1}},
+	}))
+	assert.Empty(t, sub.Events())
+
+	state, err := svc.DebugFLState(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, state.Jobs)
+}
+
+func TestRoundProgressEvents(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	svc := newFLService(t, srv.URL)
+	ctx := context.Background()
+
+	sub, err := svc.SubscribeEvents(ctx, events.Filter{ExperimentID: "exp-1"})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a", "proplet-b"},
+		KOfN:          2,
+		TaskWasmImage: "oci://example/fl-client:latest",
+	}))
+	for _, propletID := range []string{"proplet-a", "proplet-a", "proplet-b"} {
+		require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+			RoundID:   "round-1",
+			PropletID: propletID,
+			Update:    map[string]any{"w": []any{0.1}},
+		}))
+	}
+
+	type progress struct{ action, received string }
+	var got []progress
+	for len(sub.Events()) > 0 {
+		evt := <-sub.Events()
+		assert.Equal(t, "2", evt.Metadata["k_of_n"])
+		got = append(got, progress{evt.Action, evt.Metadata["received"]})
+	}
+	assert.Equal(t, []progress{
+		{"configure", "0"},
+		{"update", "1"},
+		{"update", "2"},
+		{"complete", "2"},
+	}, got)
+}
+
+func TestRoundQuorumPolicies(t *testing.T) {
+	t.Parallel()
+
+	participants := []string{"proplet-a", "proplet-b", "proplet-c", "proplet-d"}
+	cases := []struct {
+		desc     string
+		policy   fl.QuorumPolicy
+		kOfN     int
+		required []string
+		updates  []string
+		complete bool
+	}{
+		{
+			desc:     "any-k completes on the first k",
+			policy:   fl.QuorumAnyK,
+			kOfN:     2,
+			updates:  []string{"proplet-c", "proplet-d"},
+			complete: true,
+		},
+		{
+			desc:     "any-k without k waits for every participant",
+			policy:   fl.QuorumAnyK,
+			updates:  []string{"proplet-a", "proplet-b", "proplet-c"},
+			complete: false,
+		},
+		{
+			desc:     "any-k without k completes once every participant reports",
+			policy:   fl.QuorumAnyK,
+			updates:  participants,
+			complete: true,
+		},
+		{
+			desc:     "all-required completes once required report",
+			policy:   fl.QuorumAllRequired,
+			required: []string{"proplet-a", "proplet-b"},
+			updates:  []string{"proplet-b", "proplet-a"},
+			complete: true,
+		},
+		{
+			desc:     "all-required waits for a required participant that never reports",
+			policy:   fl.QuorumAllRequired,
+			kOfN:     1,
+			required: []string{"proplet-a", "proplet-b"},
+			updates:  []string{"proplet-a", "proplet-c", "proplet-d"},
+			complete: false,
+		},
+		{
+			desc:     "required-plus-k completes with required and k others",
+			policy:   fl.QuorumRequiredPlusK,
+			kOfN:     1,
+			required: []string{"proplet-a"},
+			updates:  []string{"proplet-a", "proplet-d"},
+			complete: true,
+		},
+		{
+			desc:     "required-plus-k waits for a required participant that never reports",
+			policy:   fl.QuorumRequiredPlusK,
+			kOfN:     1,
+			required: []string{"proplet-a"},
+			updates:  []string{"proplet-b", "proplet-c", "proplet-d"},
+			complete: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			svc := newFLService(t, srv.URL)
+			ctx := context.Background()
+
+			sub, err := svc.SubscribeEvents(ctx, events.Filter{ExperimentID: "exp-1"})
+			require.NoError(t, err)
+			defer sub.Close()
+
+			require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+				ExperimentID:         "exp-1",
+				RoundID:              "round-1",
+				ModelRef:             "fl/models/global_model_v0",
+				Participants:         participants,
+				KOfN:                 tc.kOfN,
+				QuorumPolicy:         tc.policy,
+				RequiredParticipants: tc.required,
+				TaskWasmImage:        "oci://example/fl-client:latest",
+			}))
+			for _, propletID := range tc.updates {
+				require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+					RoundID:   "round-1",
+					PropletID: propletID,
+					Update:    map[string]any{"w": []any{0.1}},
+				}))
+			}
+
+			var completed []string
+			for len(sub.Events()) > 0 {
+				evt := <-sub.Events()
+				assert.Equal(t, string(tc.policy), evt.Metadata["quorum_policy"])
+				if evt.Action == "complete" {
+					completed = append(completed, evt.Metadata["received"])
+				}
+			}
+			if tc.complete {
+				assert.Equal(t, []string{strconv.Itoa(len(tc.updates))}, completed)
+
+				return
+			}
+			assert.Empty(t, completed)
+		})
+	}
+}
+
+func TestConfigureExperimentQuorumValidation(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	svc := newFLService(t, srv.URL)
+	config := manager.ExperimentConfig{
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a", "proplet-b"},
+		TaskWasmImage: "oci://example/fl-client:latest",
+	}
+
+	for _, invalid := range []struct {
+		policy   fl.QuorumPolicy
+		required []string
+	}{
+		{policy: "majority"},
+		{policy: fl.QuorumAllRequired},
+		{policy: fl.QuorumAllRequired, required: []string{"proplet-z"}},
+	} {
+		config.QuorumPolicy = invalid.policy
+		config.RequiredParticipants = invalid.required
+		err := svc.ConfigureExperiment(context.Background(), config)
+		assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+		assert.ErrorIs(t, err, fl.ErrInvalidQuorum)
+	}
+}
+
+func TestAggregationWaitsForQuorum(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	var mu sync.Mutex
+	handlers := map[string]mqtt.Handler{}
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+	}).Return(nil)
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", srv.URL, slog.Default(), nil)
+	ctx := context.Background()
+	require.NoError(t, svc.Subscribe(ctx))
+	roundNext := handlers["fl/rounds/next"]
+	require.NotNil(t, roundNext)
+
+	sub, err := svc.SubscribeEvents(ctx, events.Filter{ExperimentID: "exp-1"})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:         "exp-1",
+		RoundID:              "round-1",
+		ModelRef:             "fl/models/global_model_v0",
+		Participants:         []string{"proplet-a", "proplet-b"},
+		QuorumPolicy:         fl.QuorumAllRequired,
+		RequiredParticipants: []string{"proplet-a", "proplet-b"},
+		TaskWasmImage:        "oci://example/fl-client:latest",
+	}))
+	post := func(propletID string) {
+		require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+			RoundID:   "round-1",
+			PropletID: propletID,
+			Update:    map[string]any{"w": []any{0.1}},
+		}))
+	}
+	next := map[string]any{"round_id": "round-1", "new_model_version": 1.0}
+
+	post("proplet-a")
+	post("proplet-b")
+	require.NoError(t, roundNext("fl/rounds/next", next))
+	require.NoError(t, roundNext("fl/rounds/next", next))
+
+	var actions []string
+	for len(sub.Events()) > 0 {
+		evt := <-sub.Events()
+		actions = append(actions, evt.Action)
+		assert.Empty(t, evt.Metadata["quorum_met"])
+	}
+	assert.Equal(t, []string{"configure", "update", "update", "complete", "aggregate"}, actions)
+}
+
+func TestTimeoutAggregationBeforeQuorum(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	svc, roundNext := newRoundNextService(t, srv.URL)
+	ctx := context.Background()
+
+	sub, err := svc.SubscribeEvents(ctx, events.Filter{ExperimentID: "exp-1"})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a", "proplet-b", "proplet-c"},
+		KOfN:          3,
+		TaskWasmImage: "oci://example/fl-client:latest",
+	}))
+	require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+		RoundID:   "round-1",
+		PropletID: "proplet-a",
+		Update:    map[string]any{"w": []any{0.1}},
+	}))
+
+	// The coordinator timed the round out and aggregated the one update it
+	// had; a repeated announcement must not be applied twice.
+	next := map[string]any{"round_id": "round-1", "new_model_version": 1.0, "model": map[string]any{"w": []any{0.1}}}
+	require.NoError(t, roundNext("fl/rounds/next", next))
+	require.NoError(t, roundNext("fl/rounds/next", next))
+
+	var actions []string
+	var aggregate events.Event
+	for len(sub.Events()) > 0 {
+		evt := <-sub.Events()
+		actions = append(actions, evt.Action)
+		if evt.Action == "aggregate" {
+			aggregate = evt
+		}
+	}
+	assert.Equal(t, []string{"configure", "update", "complete", "aggregate"}, actions)
+	assert.Equal(t, "false", aggregate.Metadata["quorum_met"])
+	assert.Equal(t, "1", aggregate.Metadata["received"])
+
+	model, err := svc.GetModel(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"w": []any{0.1}}, model.Data)
+}
+
+func TestStaleUpdateRejected(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		forwarded []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/update" {
+			var update manager.FLUpdate
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			mu.Lock()
+			forwarded = append(forwarded, update.PropletID)
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	svc := newFLService(t, srv.URL)
+	ctx := context.Background()
+
+	sub, err := svc.SubscribeEvents(ctx, events.Filter{ExperimentID: "exp-1"})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a", "proplet-b"},
+		KOfN:          2,
+		MaxUpdateAgeS: 60,
+		TaskWasmImage: "oci://example/fl-client:latest",
+	}))
+	post := func(propletID string, receivedAt time.Time) error {
+		return svc.PostFLUpdate(ctx, manager.FLUpdate{
+			RoundID:    "round-1",
+			PropletID:  propletID,
+			Update:     map[string]any{"w": []any{0.1}},
+			ReceivedAt: receivedAt,
+		})
+	}
+
+	require.NoError(t, post("proplet-a", time.Time{}))
+	err = post("proplet-b", time.Now().Add(2*time.Minute))
+	assert.ErrorIs(t, err, pkgerrors.ErrConflict)
+
+	type progress struct{ action, received, stale string }
+	var got []progress
+	for len(sub.Events()) > 0 {
+		evt := <-sub.Events()
+		got = append(got, progress{evt.Action, evt.Metadata["received"], evt.Metadata["stale_rejected"]})
+	}
+	assert.Equal(t, []progress{
+		{"configure", "0", ""},
+		{"update", "1", "0"},
+		{"reject-stale", "1", "1"},
+	}, got)
+
+	require.NoError(t, post("proplet-b", time.Now()))
+	evt := <-sub.Events()
+	assert.Equal(t, "update", evt.Action)
+	evt = <-sub.Events()
+	assert.Equal(t, "complete", evt.Action)
+	assert.Equal(t, "1", evt.Metadata["stale_rejected"])
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"proplet-a", "proplet-b"}, forwarded)
+}
+
+func TestAsyncRoundBlendsEachUpdate(t *testing.T) {
+	t.Parallel()
+
+	var forwarded int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/update" {
+			forwarded++
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	var (
+		mu     sync.Mutex
+		models []map[string]any
+	)
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if args.String(1) != "m/test-domain/c/test-channel/fl/models/global" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		models = append(models, args.Get(2).(map[string]any))
+	}).Return(nil)
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", srv.URL, slog.Default(), nil)
+	ctx := context.Background()
+
+	require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a", "proplet-b", "proplet-c"},
+		TaskWasmImage: "oci://example/fl-client:latest",
+		Async:         true,
+		AsyncAlpha:    0.5,
+	}))
+	post := func(propletID, base string, b float64, w ...any) {
+		require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+			RoundID:      "round-1",
+			PropletID:    propletID,
+			BaseModelURI: base,
+			Update:       map[string]any{"w": w, "b": b},
+		}))
+	}
+
+	post("proplet-a", "fl/models/global_model_v0", 1, 2.0, 4.0)
+	post("proplet-b", "fl/models/global_model_v1", 3, 6.0, 0.0)
+	post("proplet-c", "fl/models/global_model_v0", 8, 10.0, 2.0)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, models, 3)
+	for i, m := range models {
+		assert.Equal(t, i+1, m["new_model_version"])
+		assert.Equal(t, "fl/models/global_model_v"+strconv.Itoa(i+1), m["model_uri"])
+	}
+
+	seeded := models[0]["model"].(map[string]any)
+	assert.Equal(t, []float64{2, 4}, seeded["w"])
+
+	fresh := models[1]["model"].(map[string]any)
+	assert.InDeltaSlice(t, []float64{4, 2}, fresh["w"], 1e-12)
+	assert.InDelta(t, 2.0, fresh["b"], 1e-12)
+
+	alpha := fl.StalenessAlpha(0.5, 2)
+	stale := models[2]["model"].(map[string]any)
+	assert.InDeltaSlice(t, []float64{(1-alpha)*4 + alpha*10, (1-alpha)*2 + alpha*2}, stale["w"], 1e-12)
+	assert.InDelta(t, (1-alpha)*2+alpha*8, stale["b"], 1e-12)
+
+	assert.Zero(t, forwarded)
+}
+
+func TestRoundStartRedeliveredAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+
+	start := func() (manager.Service, map[string]mqtt.Handler) {
+		var mu sync.Mutex
+		handlers := map[string]mqtt.Handler{}
+		pubsub := mqttmocks.NewMockPubSub(t)
+		pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			mu.Lock()
+			defer mu.Unlock()
+			handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+		}).Return(nil).Maybe()
+
+		svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil)
+		require.NoError(t, svc.Subscribe(context.Background()))
+
+		return svc, handlers
+	}
+	roundStart := map[string]any{
+		"round_id":        "round-1",
+		"model_uri":       "fl/models/global_model_v0",
+		"task_wasm_image": "oci://example/fl-client:latest",
+		"participants":    []any{"proplet-a", "proplet-b"},
+	}
+	roundTasks := func(svc manager.Service) []string {
+		page, err := svc.ListTasks(context.Background(), manager.PageMetadata{Limit: 100})
+		require.NoError(t, err)
+		var ids []string
+		for _, tk := range page.Tasks {
+			if tk.Env["ROUND_ID"] == "round-1" {
+				ids = append(ids, tk.PropletID)
+			}
+		}
+
+		return ids
+	}
+
+	first, handlers := start()
+	handle := handlers["m/test-domain/c/test-channel/#"]
+	for _, id := range []string{"proplet-a", "proplet-b"} {
+		require.NoError(t, handle(testCreateTopic, map[string]any{"proplet_id": id}))
+		require.NoError(t, handle(testAliveTopic, map[string]any{"proplet_id": id}))
+	}
+	require.NoError(t, handlers[testRoundStartTopic](testRoundStartTopic, roundStart))
+	require.NoError(t, first.Shutdown(context.Background()))
+	assert.ElementsMatch(t, []string{"proplet-a", "proplet-b"}, roundTasks(first))
+
+	second, handlers := start()
+	require.NoError(t, handlers[testRoundStartTopic](testRoundStartTopic, roundStart))
+	require.NoError(t, second.Shutdown(context.Background()))
+	assert.ElementsMatch(t, []string{"proplet-a", "proplet-b"}, roundTasks(second))
+}
+
+// recoverRound seeds a completed round task for proplet-a and a running one
+// for each other proplet, all carrying meta, recovers them into a new service
+// and returns the actions of the round events that reporting the given
+// proplets' results produces.
+func recoverRound(t *testing.T, meta task.Metadata, running []string, report []string) []string {
+	t.Helper()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	seed := func(propletID string, state task.State) task.Task {
+		metadata := task.Metadata{"fl_round_id": "round-1", "fl_experiment_id": "exp-1"}
+		maps.Copy(metadata, meta)
+		created, err := repos.Tasks.Create(ctx, task.Task{
+			ID:        uuid.NewString(),
+			Name:      "fl-round-round-1-" + propletID,
+			PropletID: propletID,
+			State:     state,
+			CreatedAt: time.Now(),
+			Env:       map[string]string{"ROUND_ID": "round-1"},
+			Metadata:  metadata,
+		})
+		require.NoError(t, err)
+
+		return created
+	}
+	seed("proplet-a", task.Completed)
+	taskIDs := make(map[string]string, len(running))
+	for _, propletID := range running {
+		taskIDs[propletID] = seed(propletID, task.Running).ID
+	}
+
+	var handler mqtt.Handler
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, "m/test-domain/c/test-channel/#", mock.Anything).Run(func(args mock.Arguments) {
+		handler = args.Get(2).(mqtt.Handler)
+	}).Return(nil)
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil)
+	require.NoError(t, svc.RecoverInterruptedTasks(ctx))
+	require.NoError(t, svc.Subscribe(ctx))
+
+	sub, err := svc.SubscribeEvents(ctx, events.Filter{ExperimentID: "exp-1"})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	for _, propletID := range report {
+		require.NoError(t, handler(testResultsTopic, map[string]any{
+			"task_id":    taskIDs[propletID],
+			"proplet_id": propletID,
+			"results":    map[string]any{"w": []any{0.1}},
+		}))
+	}
+
+	var actions []string
+	for len(sub.Events()) > 0 {
+		actions = append(actions, (<-sub.Events()).Action)
+	}
+
+	return actions
+}
+
+func TestRecoverInFlightRound(t *testing.T) {
+	t.Parallel()
+
+	actions := recoverRound(t, task.Metadata{"fl_k_of_n": "2"}, []string{"proplet-b"}, []string{"proplet-b"})
+	assert.Equal(t, []string{"update", "complete"}, actions)
+}
+
+func TestRecoverRoundKeepsStartTime(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc        string
+		startedAt   time.Time
+		wantActions []string
+	}{
+		{
+			desc:        "update past the window of the persisted start is stale",
+			startedAt:   time.Now().Add(-2 * time.Minute),
+			wantActions: []string{"reject-stale"},
+		},
+		{
+			desc:        "update within the window is accepted",
+			startedAt:   time.Now().Add(-10 * time.Second),
+			wantActions: []string{"update", "complete"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			actions := recoverRound(t, task.Metadata{
+				"fl_k_of_n":           "2",
+				"fl_max_update_age_s": "60",
+				"fl_round_started_at": tc.startedAt.Format(time.RFC3339Nano),
+			}, []string{"proplet-b"}, []string{"proplet-b"})
+			assert.Equal(t, tc.wantActions, actions)
+		})
+	}
+}
+
+func TestRecoverRoundKeepsGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	meta := task.Metadata{"fl_k_of_n": "2", "fl_aggregation_grace_period_s": "60"}
+	running := []string{"proplet-b", "proplet-c"}
+
+	actions := recoverRound(t, meta, running, []string{"proplet-b"})
+	assert.Equal(t, []string{"update"}, actions, "round completed before its grace period")
+
+	actions = recoverRound(t, meta, running, []string{"proplet-b", "proplet-c"})
+	assert.Equal(t, []string{"update", "update", "complete"}, actions)
+}
+
+// roundLookupBarrier holds the first lookup of a round's tasks, after it has
+// read them, until a second lookup arrives or a short timeout passes. Both
+// round starts therefore see the round unlaunched and only the storage
+// reservation keeps them from launching it twice.
+type roundLookupBarrier struct {
+	storage.TaskRepository
+	once    sync.Once
+	arrived chan struct{}
+	calls   atomic.Int32
+}
+
+func (b *roundLookupBarrier) Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error) {
+	tasks, total, err := b.TaskRepository.Query(ctx, q)
+	if _, ok := q.Metadata["fl_round_id"]; ok {
+		if b.calls.Add(1) == 1 {
+			select {
+			case <-b.arrived:
+			case <-time.After(100 * time.Millisecond):
+			}
+		} else {
+			b.once.Do(func() { close(b.arrived) })
+		}
+	}
+
+	return tasks, total, err
+}
+
+func TestConcurrentRoundStartsLaunchOnce(t *testing.T) {
+	t.Parallel()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	lookups := &roundLookupBarrier{TaskRepository: repos.Tasks, arrived: make(chan struct{})}
+	repos.Tasks = lookups
+	ctx := context.Background()
+
+	// Two managers share the storage, as replicas behind one database do.
+	newManager := func() (manager.Service, map[string]mqtt.Handler) {
+		var mu sync.Mutex
+		handlers := map[string]mqtt.Handler{}
+		pubsub := mqttmocks.NewMockPubSub(t)
+		pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+		pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			mu.Lock()
+			defer mu.Unlock()
+			handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+		}).Return(nil).Maybe()
+		svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil)
+		require.NoError(t, svc.Subscribe(ctx))
+
+		return svc, handlers
+	}
+	first, firstHandlers := newManager()
+	second, secondHandlers := newManager()
+
+	participants := []any{"proplet-a", "proplet-b", "proplet-c"}
+	handle := firstHandlers["m/test-domain/c/test-channel/#"]
+	for _, id := range participants {
+		require.NoError(t, handle(testCreateTopic, map[string]any{"proplet_id": id}))
+		require.NoError(t, handle(testAliveTopic, map[string]any{"proplet_id": id}))
+	}
+
+	roundStart := map[string]any{
+		"round_id":        "round-2",
+		"model_uri":       "fl/models/global_model_v1",
+		"task_wasm_image": "oci://example/fl-client:latest",
+		"participants":    participants,
+	}
+	var wg sync.WaitGroup
+	for _, handlers := range []map[string]mqtt.Handler{firstHandlers, secondHandlers} {
+		wg.Go(func() {
+			assert.NoError(t, handlers[testRoundStartTopic](testRoundStartTopic, roundStart))
+		})
+	}
+	wg.Wait()
+
+	launched := func() []string {
+		page, err := first.ListTasks(ctx, manager.PageMetadata{Limit: 100})
+		require.NoError(t, err)
+		var ids []string
+		for _, tk := range page.Tasks {
+			if tk.Env["ROUND_ID"] == "round-2" {
+				ids = append(ids, tk.PropletID)
+			}
+		}
+
+		return ids
+	}
+	assert.Eventually(t, func() bool {
+		return lookups.calls.Load() == 2 && len(launched()) >= len(participants)
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, first.Shutdown(ctx))
+	require.NoError(t, second.Shutdown(ctx))
+	assert.ElementsMatch(t, []string{"proplet-a", "proplet-b", "proplet-c"}, launched())
+}
+
+func TestAggregationGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc     string
+		grace    int
+		post     []string
+		straggle string
+		received string
+	}{
+		{desc: "straggler within grace is included", grace: 1, post: []string{"proplet-a", "proplet-b"}, straggle: "proplet-c", received: "3"},
+		{desc: "all participants complete immediately", grace: 30, post: []string{"proplet-a", "proplet-b", "proplet-c"}, received: "3"},
+		{desc: "grace expiry completes with quorum", grace: 1, post: []string{"proplet-a", "proplet-b"}, received: "2"},
+		{desc: "no grace completes at quorum", post: []string{"proplet-a", "proplet-b"}, received: "2"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			svc := newFLService(t, srv.URL)
+			ctx := context.Background()
+
+			sub, err := svc.SubscribeEvents(ctx, events.Filter{ExperimentID: "exp-1"})
+			require.NoError(t, err)
+			defer sub.Close()
+
+			require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+				ExperimentID:            "exp-1",
+				RoundID:                 "round-1",
+				ModelRef:                "fl/models/global_model_v0",
+				Participants:            []string{"proplet-a", "proplet-b", "proplet-c"},
+				KOfN:                    2,
+				AggregationGracePeriodS: tc.grace,
+				TaskWasmImage:           "oci://example/fl-client:latest",
+			}))
+			post := func(propletID string) {
+				require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+					RoundID:   "round-1",
+					PropletID: propletID,
+					Update:    map[string]any{"w": []any{0.1}},
+				}))
+			}
+
+			start := time.Now()
+			for _, id := range tc.post {
+				post(id)
+			}
+			if tc.straggle != "" {
+				post(tc.straggle)
+			}
+
+			timeout := time.After(time.Duration(tc.grace+2) * time.Second)
+			for {
+				select {
+				case evt := <-sub.Events():
+					if evt.Action != "complete" {
+						continue
+					}
+					assert.Equal(t, tc.received, evt.Metadata["received"])
+					if tc.grace > 0 && tc.received == "2" {
+						assert.GreaterOrEqual(t, time.Since(start), time.Duration(tc.grace)*time.Second)
+					}
+
+					return
+				case <-timeout:
+					t.Fatal("round did not complete")
+				}
+			}
+		})
+	}
+}
+
+func TestNegativeAggregationGracePeriodRejected(t *testing.T) {
+	t.Parallel()
+	svc := newFLService(t, "http://127.0.0.1:0")
+
+	err := svc.ConfigureExperiment(context.Background(), manager.ExperimentConfig{
+		ExperimentID:            "exp-1",
+		RoundID:                 "round-1",
+		ModelRef:                "fl/models/global_model_v0",
+		Participants:            []string{"proplet-a"},
+		AggregationGracePeriodS: -1,
+		TaskWasmImage:           "oci://example/fl-client:latest",
+	})
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+}
+
+func TestAggregatedModelEvaluated(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rounds/round-1/complete" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status": manager.RoundStatus{RoundID: "round-1", Completed: true, NumUpdates: 1},
+			})
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		desc      string
+		evaluator string
+	}{
+		{desc: "evaluator configured", evaluator: "proplet-eval"},
+		{desc: "no evaluator"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+			require.NoError(t, err)
+			var mu sync.Mutex
+			handlers := map[string]mqtt.Handler{}
+			pubsub := mqttmocks.NewMockPubSub(t)
+			pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+			pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				mu.Lock()
+				defer mu.Unlock()
+				handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+			}).Return(nil)
+			svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", srv.URL, slog.Default(), nil)
+			ctx := context.Background()
+			require.NoError(t, svc.Subscribe(ctx))
+			handle := handlers["m/test-domain/c/test-channel/#"]
+			require.NoError(t, handle(testCreateTopic, map[string]any{"proplet_id": "proplet-eval"}))
+			require.NoError(t, handle(testAliveTopic, map[string]any{"proplet_id": "proplet-eval"}))
+
+			sub, err := svc.SubscribeEvents(ctx, events.Filter{ExperimentID: "exp-1"})
+			require.NoError(t, err)
+			defer sub.Close()
+
+			require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+				ExperimentID:     "exp-1",
+				RoundID:          "round-1",
+				ModelRef:         "fl/models/global_model_v0",
+				Participants:     []string{"proplet-a"},
+				KOfN:             1,
+				TaskWasmImage:    "oci://example/fl-client:latest",
+				EvaluatorProplet: tc.evaluator,
+			}))
+			require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+				RoundID:   "round-1",
+				PropletID: "proplet-a",
+				Update:    map[string]any{"w": []any{0.1}},
+			}))
+			require.NoError(t, handlers["fl/rounds/next"]("fl/rounds/next", map[string]any{
+				"round_id":          "round-1",
+				"new_model_version": 1.0,
+				"model_uri":         "fl/models/global_model_v1",
+				"model":             map[string]any{"w": []any{0.1}},
+			}))
+
+			page, err := svc.ListTasks(ctx, manager.PageMetadata{Limit: 100})
+			require.NoError(t, err)
+			var evals []task.Task
+			for _, tk := range page.Tasks {
+				if tk.Mode == task.ModeInfer {
+					evals = append(evals, tk)
+				}
+			}
+			if tc.evaluator == "" {
+				assert.Empty(t, evals)
+
+				return
+			}
+			require.Len(t, evals, 1)
+			eval := evals[0]
+			assert.Equal(t, "proplet-eval", eval.PropletID)
+			assert.Equal(t, "fl/models/global_model_v1", eval.Env["MODEL_URI"])
+			assert.JSONEq(t, `{"w":[0.1]}`, eval.Env["MODEL_DATA"])
+			assert.Empty(t, eval.Env["ROUND_ID"])
+
+			require.NoError(t, handle(testResultsTopic, map[string]any{
+				"task_id":    eval.ID,
+				"proplet_id": "proplet-eval",
+				"results":    map[string]any{"accuracy": 0.91, "loss": 0.2},
+			}))
+
+			status, err := svc.GetRoundStatus(ctx, "round-1")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]float64{"accuracy": 0.91, "loss": 0.2}, status.EvalMetrics)
+
+			var evaluated *events.Event
+			for len(sub.Events()) > 0 {
+				if evt := <-sub.Events(); evt.Action == "evaluate" {
+					evaluated = &evt
+				}
+			}
+			require.NotNil(t, evaluated)
+			assert.Equal(t, "0.91", evaluated.Metadata["eval_accuracy"])
+			assert.Equal(t, "0.2", evaluated.Metadata["eval_loss"])
+		})
+	}
+}
+
+func TestFLJobMetricsSeries(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	var mu sync.Mutex
+	handlers := map[string]mqtt.Handler{}
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+	}).Return(nil)
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", srv.URL, slog.Default(), nil)
+	ctx := context.Background()
+	require.NoError(t, svc.Subscribe(ctx))
+	roundNext := handlers["fl/rounds/next"]
+	require.NotNil(t, roundNext)
+
+	_, err = svc.GetFLJobMetrics(ctx, "exp-1")
+	assert.ErrorIs(t, err, pkgerrors.ErrNotFound)
+
+	losses := [][2]float64{{0.9, 0.5}, {0.6, 0.2}, {0.3, 0.1}}
+	for i, loss := range losses {
+		roundID := "round-" + strconv.Itoa(i+1)
+		require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+			ExperimentID:  "exp-1",
+			RoundID:       roundID,
+			ModelRef:      "fl/models/global_model_v" + strconv.Itoa(i),
+			Participants:  []string{"proplet-a", "proplet-b"},
+			KOfN:          2,
+			TaskWasmImage: "oci://example/fl-client:latest",
+		}))
+		for j, propletID := range []string{"proplet-a", "proplet-b"} {
+			require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+				RoundID:    roundID,
+				PropletID:  propletID,
+				NumSamples: 10 + 20*j,
+				Metrics:    map[string]any{"loss": loss[j]},
+				Update:     map[string]any{"w": []any{0.1}},
+			}))
+		}
+		require.NoError(t, roundNext("fl/rounds/next", map[string]any{
+			"round_id":          roundID,
+			"new_model_version": float64(i + 1),
+		}))
+	}
+
+	got, err := svc.GetFLJobMetrics(ctx, "exp-1")
+	require.NoError(t, err)
+	assert.Equal(t, "exp-1", got.JobID)
+	require.Len(t, got.Rounds, len(losses))
+	for i, round := range got.Rounds {
+		assert.Equal(t, "round-"+strconv.Itoa(i+1), round.RoundID)
+		assert.Equal(t, i+1, round.ModelVersion)
+		assert.Equal(t, 2, round.NumUpdates)
+		assert.InDelta(t, (10*losses[i][0]+30*losses[i][1])/40, round.Metrics["loss"], 1e-9)
+	}
+}
+
+func TestFLJobCompletion(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	var mu sync.Mutex
+	handlers := map[string]mqtt.Handler{}
+	var completions []manager.FLJobComplete
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if args.String(1) != "m/test-domain/c/test-channel/control/manager/fl/complete" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		completions = append(completions, args.Get(2).(manager.FLJobComplete))
+	}).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+	}).Return(nil)
+	ctx := context.Background()
+	start := func() manager.Service {
+		svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", srv.URL, slog.Default(), nil)
+		require.NoError(t, svc.Subscribe(ctx))
+
+		return svc
+	}
+	runRound := func(svc manager.Service, i int) {
+		roundID := "round-" + strconv.Itoa(i+1)
+		require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+			ExperimentID:  "exp-done",
+			RoundID:       roundID,
+			ModelRef:      "fl/models/global_model_v" + strconv.Itoa(i),
+			Participants:  []string{"proplet-a"},
+			KOfN:          1,
+			TaskWasmImage: "oci://example/fl-client:latest",
+			Round:         uint64(i + 1),
+			TotalRounds:   2,
+		}))
+		require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+			RoundID:    roundID,
+			PropletID:  "proplet-a",
+			NumSamples: 10,
+			Metrics:    map[string]any{"loss": 0.5 / float64(i+1)},
+			Update:     map[string]any{"w": []any{0.1}},
+		}))
+		mu.Lock()
+		roundNext := handlers["fl/rounds/next"]
+		mu.Unlock()
+		require.NotNil(t, roundNext)
+		next := map[string]any{"round_id": roundID, "new_model_version": float64(i + 1)}
+		require.NoError(t, roundNext("fl/rounds/next", next))
+		require.NoError(t, roundNext("fl/rounds/next", next))
+	}
+	svc := start()
+
+	jobTask, err := svc.CreateTask(ctx, task.Task{
+		Name:     "fl-round-task",
+		Metadata: task.Metadata{"fl_experiment_id": "exp-done"},
+	})
+	require.NoError(t, err)
+
+	for i := range 2 {
+		runRound(svc, i)
+
+		mu.Lock()
+		got := len(completions)
+		mu.Unlock()
+		if i == 0 {
+			assert.Zero(t, got, "job completed before its last round")
+		}
+	}
+
+	mu.Lock()
+	require.Len(t, completions, 1)
+	done := completions[0]
+	assert.Equal(t, "exp-done", done.JobID)
+	assert.Equal(t, "round-2", done.RoundID)
+	assert.Equal(t, uint64(2), done.TotalRounds)
+	assert.Equal(t, 2, done.ModelVersion)
+	assert.InDelta(t, 0.25, done.Metrics["loss"], 1e-9)
+
+	marked, err := svc.GetTask(ctx, jobTask.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "true", marked.Metadata["fl_job_complete"])
+	mu.Unlock()
+
+	// A restarted manager finds the job's tasks marked and does not announce
+	// it again when the last round is replayed.
+	runRound(start(), 1)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, completions, 1)
+}
+
+func TestConfigureExperimentRoundBounds(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	svc := newFLService(t, srv.URL)
+
+	cases := map[string]manager.ExperimentConfig{
+		"round past total": {ExperimentID: "exp-1", Round: 3, TotalRounds: 2},
+		"missing round":    {ExperimentID: "exp-1", TotalRounds: 2},
+		"missing job":      {Round: 1, TotalRounds: 2},
+	}
+	for desc, config := range cases {
+		config.RoundID = "round-1"
+		config.ModelRef = "fl/models/global_model_v0"
+		config.Participants = []string{"proplet-a"}
+		config.KOfN = 1
+		config.TaskWasmImage = "oci://example/fl-client:latest"
+		err := svc.ConfigureExperiment(context.Background(), config)
+		assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue, desc)
+	}
+}
+
+func TestRollbackModel(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/task" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"task":{"round_id":"round-4","model_ref":"fl/models/global_model_v3"}}`))
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	var mu sync.Mutex
+	handlers := map[string]mqtt.Handler{}
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+	}).Return(nil)
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", srv.URL, slog.Default(), nil)
+	ctx := context.Background()
+	require.NoError(t, svc.Subscribe(ctx))
+	roundNext := handlers["fl/rounds/next"]
+	require.NotNil(t, roundNext)
+
+	for i := range 3 {
+		roundID := "round-" + strconv.Itoa(i+1)
+		require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+			ExperimentID:  "exp-1",
+			RoundID:       roundID,
+			ModelRef:      "fl/models/global_model_v" + strconv.Itoa(i),
+			Participants:  []string{"proplet-a"},
+			KOfN:          1,
+			TaskWasmImage: "oci://example/fl-client:latest",
+		}))
+		require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+			RoundID:   roundID,
+			PropletID: "proplet-a",
+			Update:    map[string]any{"w": []any{0.1}},
+		}))
+		require.NoError(t, roundNext("fl/rounds/next", map[string]any{
+			"round_id":          roundID,
+			"new_model_version": float64(i + 1),
+			"model":             map[string]any{"w": []any{float64(i)}},
+		}))
+	}
+
+	got, err := svc.GetFLTask(ctx, "round-4", "proplet-a")
+	require.NoError(t, err)
+	assert.Equal(t, "fl/models/global_model_v3", got.ModelRef)
+
+	require.ErrorIs(t, svc.RollbackModel(ctx, 7), pkgerrors.ErrNotFound)
+	require.NoError(t, svc.RollbackModel(ctx, 1))
+
+	got, err = svc.GetFLTask(ctx, "round-4", "proplet-a")
+	require.NoError(t, err)
+	assert.Equal(t, "fl/models/global_model_v1", got.ModelRef)
+}
+
+func TestAggregatedModelStoredOnlyWithVersion(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	svc, roundNext := newRoundNextService(t, srv.URL)
+	ctx := context.Background()
+
+	for i, msg := range []map[string]any{
+		{"model": map[string]any{"w": []any{0.5}}},
+		{"new_model_version": 2.0, "model": map[string]any{"w": []any{0.25}}},
+	} {
+		roundID := "round-" + strconv.Itoa(i+1)
+		require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+			ExperimentID:  "exp-1",
+			RoundID:       roundID,
+			ModelRef:      "fl/models/global_model_v0",
+			Participants:  []string{"proplet-a"},
+			KOfN:          1,
+			TaskWasmImage: "oci://example/fl-client:latest",
+		}))
+		require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+			RoundID:   roundID,
+			PropletID: "proplet-a",
+			Update:    map[string]any{"w": []any{0.1}},
+		}))
+		msg["round_id"] = roundID
+		require.NoError(t, roundNext("fl/rounds/next", msg))
+	}
+
+	_, err := svc.GetModel(ctx, 0)
+	require.ErrorIs(t, err, pkgerrors.ErrNotFound, "a model without a version is not stored")
+	got, err := svc.GetModel(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"w": []any{0.25}}, got.Data)
+}
+
+func TestStoreModel(t *testing.T) {
+	t.Parallel()
+	svc, _ := newRecordingService(t)
+	ctx := context.Background()
+
+	model := manager.Model{
+		Data:     map[string]any{"w": []any{0.1, 0.2}},
+		Metadata: map[string]any{"source": "import"},
+	}
+	_, err := svc.GetModel(ctx, 1)
+	require.ErrorIs(t, err, pkgerrors.ErrNotFound)
+
+	require.NoError(t, svc.StoreModel(ctx, 1, model))
+	got, err := svc.GetModel(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model, got)
+
+	require.ErrorIs(t, svc.StoreModel(ctx, 1, manager.Model{Data: map[string]any{}}), pkgerrors.ErrConflict)
+	require.ErrorIs(t, svc.StoreModel(ctx, -1, model), pkgerrors.ErrInvalidValue)
+}
+
+func TestPersonalizedModels(t *testing.T) {
+	t.Parallel()
+	svc, _ := newRecordingService(t)
+	ctx := context.Background()
+
+	modelA := manager.Model{Data: map[string]any{"head": []any{0.1}}}
+	modelB := manager.Model{Data: map[string]any{"head": []any{0.9}}, Metadata: map[string]any{"round_id": "round-3"}}
+
+	_, err := svc.GetPersonalizedModel(ctx, "client-a")
+	require.ErrorIs(t, err, pkgerrors.ErrNotFound)
+
+	require.NoError(t, svc.StorePersonalizedModel(ctx, "client-a", modelA))
+	got, err := svc.GetPersonalizedModel(ctx, "client-a")
+	require.NoError(t, err)
+	assert.Equal(t, "client-a", got.ClientID)
+	assert.Equal(t, modelA, got.Personalized)
+	assert.Nil(t, got.Global)
+
+	require.NoError(t, svc.StorePersonalizedModel(ctx, "client-b", modelB))
+	global := manager.Model{Data: map[string]any{"w": []any{0.5}}}
+	require.NoError(t, svc.StoreModel(ctx, 2, global))
+
+	for clientID, want := range map[string]manager.Model{"client-a": modelA, "client-b": modelB} {
+		got, err := svc.GetPersonalizedModel(ctx, clientID)
+		require.NoError(t, err)
+		assert.Equal(t, want, got.Personalized, clientID)
+		assert.Equal(t, 2, got.GlobalVersion, clientID)
+		require.NotNil(t, got.Global, clientID)
+		assert.Equal(t, global, *got.Global, clientID)
+	}
+
+	updated := manager.Model{Data: map[string]any{"head": []any{0.2}}}
+	require.NoError(t, svc.StorePersonalizedModel(ctx, "client-a", updated))
+	got, err = svc.GetPersonalizedModel(ctx, "client-a")
+	require.NoError(t, err)
+	assert.Equal(t, updated, got.Personalized)
+	got, err = svc.GetPersonalizedModel(ctx, "client-b")
+	require.NoError(t, err)
+	assert.Equal(t, modelB, got.Personalized)
+
+	require.ErrorIs(t, svc.StorePersonalizedModel(ctx, " ", modelA), pkgerrors.ErrInvalidValue)
+	require.ErrorIs(t, svc.StorePersonalizedModel(ctx, "client-c", manager.Model{}), pkgerrors.ErrInvalidValue)
+}
+
+func TestDebugFLState(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	svc := newFLService(t, srv.URL)
+	ctx := context.Background()
+
+	require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a", "proplet-b", "proplet-c"},
+		KOfN:          2,
+		TaskWasmImage: "oci://example/fl-client:latest",
+	}))
+	require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:  "exp-2",
+		RoundID:       "round-async",
+		ModelRef:      "fl/models/global_model_v3",
+		Participants:  []string{"proplet-a"},
+		TaskWasmImage: "oci://example/fl-client:latest",
+		Async:         true,
+		AsyncAlpha:    0.4,
+	}))
+	require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+		RoundID:    "round-1",
+		PropletID:  "proplet-b",
+		NumSamples: 10,
+		Update:     map[string]any{"w": []any{0.1}},
+	}))
+
+	state, err := svc.DebugFLState(ctx)
+	require.NoError(t, err)
+	require.Len(t, state.Jobs["exp-1"], 1)
+	round := state.Jobs["exp-1"][0]
+	assert.Equal(t, "round-1", round.RoundID)
+	assert.Equal(t, 2, round.KOfN)
+	assert.Equal(t, []string{"proplet-a", "proplet-b", "proplet-c"}, round.Expected)
+	assert.Equal(t, []string{"proplet-b"}, round.Received)
+	assert.Equal(t, []string{"proplet-a", "proplet-c"}, round.Missing)
+	assert.False(t, round.Aggregated)
+	assert.False(t, round.StartedAt.IsZero())
+	assert.Nil(t, round.Async)
+
+	require.Len(t, state.Jobs["exp-2"], 1)
+	async := state.Jobs["exp-2"][0]
+	require.NotNil(t, async.Async)
+	assert.InDelta(t, 0.4, async.Async.Alpha, 1e-9)
+	assert.Equal(t, 3, async.Async.ModelVersion)
+
+	require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+		RoundID:    "round-1",
+		PropletID:  "proplet-a",
+		NumSamples: 10,
+		Update:     map[string]any{"w": []any{0.3}},
+	}))
+
+	state, err = svc.DebugFLState(ctx)
+	require.NoError(t, err)
+	round = state.Jobs["exp-1"][0]
+	assert.True(t, round.Aggregated)
+	assert.Equal(t, []string{"proplet-a", "proplet-b"}, round.Received)
+	assert.Equal(t, []string{"proplet-c"}, round.Missing)
+}
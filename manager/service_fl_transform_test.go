@@ -0,0 +1,118 @@
+package manager_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaleTransformerAppliedBeforeAggregation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		forwarded []manager.FLUpdate
+		models    []map[string]any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/update" {
+			var update manager.FLUpdate
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			mu.Lock()
+			forwarded = append(forwarded, update)
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if args.String(1) != "m/test-domain/c/test-channel/fl/models/global" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		models = append(models, args.Get(2).(map[string]any))
+	}).Return(nil)
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", srv.URL, slog.Default(), nil,
+		manager.WithUpdateTransform(manager.UpdateTransformConfig{Name: "scale", Scale: 0.5}))
+	ctx := context.Background()
+
+	for _, roundID := range []string{"round-sync", "round-async"} {
+		require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+			ExperimentID:  "exp-1",
+			RoundID:       roundID,
+			ModelRef:      "fl/models/global_model_v0",
+			Participants:  []string{"proplet-a", "proplet-b"},
+			TaskWasmImage: "oci://example/fl-client:latest",
+			Async:         roundID == "round-async",
+			AsyncAlpha:    0.5,
+		}))
+	}
+	post := func(roundID, propletID, base string, b float64, w ...any) error {
+		return svc.PostFLUpdate(ctx, manager.FLUpdate{
+			RoundID:      roundID,
+			PropletID:    propletID,
+			BaseModelURI: base,
+			Update:       map[string]any{"w": w, "b": b},
+		})
+	}
+
+	require.NoError(t, post("round-sync", "proplet-a", "fl/models/global_model_v0", 4, 2.0, 8.0))
+	require.NoError(t, post("round-async", "proplet-a", "fl/models/global_model_v0", 4, 2.0, 8.0))
+	require.NoError(t, post("round-async", "proplet-b", "fl/models/global_model_v1", 8, 6.0, 0.0))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, forwarded, 1)
+	assert.Equal(t, []any{1.0, 4.0}, forwarded[0].Update["w"])
+	assert.Equal(t, 2.0, forwarded[0].Update["b"])
+
+	require.Len(t, models, 2)
+	seeded := models[0]["model"].(map[string]any)
+	assert.Equal(t, []float64{1, 4}, seeded["w"])
+	blended := models[1]["model"].(map[string]any)
+	assert.InDeltaSlice(t, []float64{2, 2}, blended["w"], 1e-12)
+	assert.InDelta(t, 3.0, blended["b"], 1e-12)
+
+	err = post("round-sync", "proplet-b", "fl/models/global_model_v0", 1, "not-a-number")
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+}
+
+func TestUnknownTransformLeavesUpdatesUnchanged(t *testing.T) {
+	t.Parallel()
+
+	var forwarded manager.FLUpdate
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/update" {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&forwarded))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	svc := newFLService(t, srv.URL, manager.WithUpdateTransform(manager.UpdateTransformConfig{Name: "dequantize-int8"}))
+
+	require.NoError(t, svc.PostFLUpdate(context.Background(), manager.FLUpdate{
+		RoundID:   "round-1",
+		PropletID: "proplet-a",
+		Update:    map[string]any{"w": []any{2.0}, "b": 4.0},
+	}))
+	assert.Equal(t, []any{2.0}, forwarded.Update["w"])
+	assert.Equal(t, 4.0, forwarded.Update["b"])
+}
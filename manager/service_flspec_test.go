@@ -0,0 +1,114 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTaskValidatesFLSpec(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc    string
+		task    task.Task
+		wantErr error
+	}{
+		{
+			desc: "standard task is not validated",
+			task: task.Task{Name: "plain", Env: map[string]string{"FL_FORMAT": "anything"}},
+		},
+		{
+			desc: "round task with defaults",
+			task: task.Task{Name: "train", Env: map[string]string{"ROUND_ID": "round-1"}},
+		},
+		{
+			desc: "federated task with format and samples",
+			task: task.Task{Name: "train", Kind: task.TaskKindFederated, Env: map[string]string{
+				"ROUND_ID":       "round-1",
+				"FL_FORMAT":      fl.FormatJSONF64,
+				"FL_NUM_SAMPLES": "128",
+			}},
+		},
+		{
+			desc:    "federated task without a round",
+			task:    task.Task{Name: "train", Kind: task.TaskKindFederated},
+			wantErr: fl.ErrInvalidSpec,
+		},
+		{
+			desc:    "unknown update format",
+			task:    task.Task{Name: "train", Env: map[string]string{"ROUND_ID": "round-1", "FL_FORMAT": "f16"}},
+			wantErr: fl.ErrInvalidSpec,
+		},
+		{
+			desc:    "zero sample count",
+			task:    task.Task{Name: "train", Env: map[string]string{"ROUND_ID": "round-1", "FL_NUM_SAMPLES": "0"}},
+			wantErr: pkgerrors.ErrInvalidValue,
+		},
+		{
+			desc:    "non-numeric sample count",
+			task:    task.Task{Name: "train", Env: map[string]string{"ROUND_ID": "round-1", "FL_NUM_SAMPLES": "many"}},
+			wantErr: pkgerrors.ErrInvalidValue,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			svc := newService(t)
+
+			_, err := svc.CreateTask(context.Background(), tc.task)
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+			require.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestCreateTaskCanonicalizesUpdateFormat(t *testing.T) {
+	t.Parallel()
+	svc := newService(t)
+	ctx := context.Background()
+
+	env := map[string]string{"ROUND_ID": "round-1", "FL_FORMAT": " Int8_Quant "}
+	created, err := svc.CreateTask(ctx, task.Task{Name: "train", Env: env})
+	require.NoError(t, err)
+	assert.Equal(t, fl.FormatInt8Quant, created.Env["FL_FORMAT"])
+	assert.Equal(t, " Int8_Quant ", env["FL_FORMAT"], "the caller's env is not modified")
+
+	stored, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, fl.FormatInt8Quant, stored.Env["FL_FORMAT"])
+
+	blank, err := svc.CreateTask(ctx, task.Task{Name: "train", Env: map[string]string{"ROUND_ID": "round-1", "FL_FORMAT": " "}})
+	require.NoError(t, err)
+	assert.NotContains(t, blank.Env, "FL_FORMAT", "a blank format leaves the proplet default")
+
+	_, err = svc.CreateTask(ctx, task.Task{Name: "train", Env: map[string]string{"ROUND_ID": "round-1", "FL_FORMAT": "json-f46"}})
+	require.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+	assert.ErrorIs(t, err, fl.ErrUnknownFormat)
+}
+
+func TestCreateWorkflowValidatesFLSpec(t *testing.T) {
+	t.Parallel()
+	svc := newService(t)
+
+	_, err := svc.CreateWorkflow(context.Background(), []task.Task{
+		{ID: "task1", Name: "prepare"},
+		{ID: "task2", Name: "train", DependsOn: []string{"task1"}, Env: map[string]string{
+			"ROUND_ID":  "round-1",
+			"FL_FORMAT": "f16",
+		}},
+	})
+	require.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+	assert.ErrorIs(t, err, fl.ErrInvalidSpec)
+}
@@ -0,0 +1,33 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartPayloadCarriesMaxInstructions(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	metered, err := svc.CreateTask(ctx, task.Task{Name: "metered", MaxInstructions: 1_000_000})
+	require.NoError(t, err)
+	unmetered, err := svc.CreateTask(ctx, task.Task{Name: "unmetered"})
+	require.NoError(t, err)
+
+	got, err := svc.GetTask(ctx, metered.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1_000_000), got.MaxInstructions)
+
+	require.NoError(t, svc.StartTask(ctx, metered.ID))
+	require.NoError(t, svc.StartTask(ctx, unmetered.ID))
+
+	assert.InDelta(t, 1_000_000, rec.payload(metered.ID)["max_instructions"], 0)
+	assert.NotContains(t, rec.payload(unmetered.ID), "max_instructions")
+}
@@ -0,0 +1,95 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scopedResultsTopic(identity string) string {
+	return "m/test-domain/c/test-channel/control/proplet/" + identity + "/results"
+}
+
+func TestResultsPublisherIdentity(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc          string
+		enforce       bool
+		bindings      string
+		topic         string
+		propletID     string
+		wantCompleted bool
+	}{
+		{
+			desc:          "results from the claimed proplet's identity",
+			topic:         scopedResultsTopic("proplet-1"),
+			propletID:     "proplet-1",
+			wantCompleted: true,
+		},
+		{
+			desc:      "results spoofing another proplet are rejected",
+			topic:     scopedResultsTopic("proplet-2"),
+			propletID: "proplet-1",
+		},
+		{
+			desc:          "results from an identity bound to the proplet",
+			bindings:      "client-a=proplet-1",
+			topic:         scopedResultsTopic("client-a"),
+			propletID:     "proplet-1",
+			wantCompleted: true,
+		},
+		{
+			desc:      "bound identity cannot claim its own name",
+			bindings:  "client-a=proplet-1",
+			topic:     scopedResultsTopic("client-a"),
+			propletID: "client-a",
+		},
+		{
+			desc:          "unscoped results accepted without enforcement",
+			topic:         testResultsTopic,
+			propletID:     "proplet-1",
+			wantCompleted: true,
+		},
+		{
+			desc:      "unscoped results rejected when enforcing",
+			enforce:   true,
+			topic:     testResultsTopic,
+			propletID: "proplet-1",
+		},
+		{
+			desc:          "scoped results accepted when enforcing",
+			enforce:       true,
+			topic:         scopedResultsTopic("proplet-1"),
+			propletID:     "proplet-1",
+			wantCompleted: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			svc, rec := newRecordingService(t, manager.WithPublisherIdentity(manager.PublisherIdentityConfig{
+				Enforce:  tc.enforce,
+				Bindings: tc.bindings,
+			}))
+			ctx := context.Background()
+
+			created, err := svc.CreateTask(ctx, task.Task{Name: "identity"})
+			require.NoError(t, err)
+			require.NoError(t, rec.handler(tc.topic, map[string]any{
+				"task_id":    created.ID,
+				"proplet_id": tc.propletID,
+				"results":    "done",
+			}))
+
+			got, err := svc.GetTask(ctx, created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCompleted, got.State == task.Completed)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startedEnv(t *testing.T, svc manager.Service, rec *startRecorder, tk task.Task) map[string]any {
+	t.Helper()
+	ctx := context.Background()
+
+	created, err := svc.CreateTask(ctx, tk)
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	payload := rec.payload(created.ID)
+	require.NotNil(t, payload)
+	env, _ := payload["env"].(map[string]any)
+
+	return env
+}
+
+func TestInferTaskReceivesGlobalModel(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	env := startedEnv(t, svc, rec, task.Task{Name: "infer-empty", Mode: task.ModeInfer})
+	assert.NotContains(t, env, "MODEL_DATA", "no global model has been stored yet")
+
+	require.NoError(t, svc.StoreModel(ctx, 1, manager.Model{Data: map[string]any{"w": []any{0.1}}}))
+	require.NoError(t, svc.StoreModel(ctx, 2, manager.Model{Data: map[string]any{"w": []any{0.2}}}))
+
+	env = startedEnv(t, svc, rec, task.Task{
+		Name: "infer",
+		Mode: task.ModeInfer,
+		Env:  map[string]string{"INPUT": "x"},
+	})
+	assert.Equal(t, "fl/models/global_model_v2", env["MODEL_URI"])
+	assert.JSONEq(t, `{"w":[0.2]}`, env["MODEL_DATA"].(string))
+	assert.Equal(t, "x", env["INPUT"])
+
+	require.NoError(t, svc.RollbackModel(ctx, 1))
+	env = startedEnv(t, svc, rec, task.Task{Name: "infer-rolled-back", Mode: task.ModeInfer})
+	assert.Equal(t, "fl/models/global_model_v1", env["MODEL_URI"])
+	assert.JSONEq(t, `{"w":[0.1]}`, env["MODEL_DATA"].(string))
+
+	env = startedEnv(t, svc, rec, task.Task{
+		Name: "infer-pinned",
+		Mode: task.ModeInfer,
+		Env:  map[string]string{"MODEL_URI": "fl/models/global_model_v2"},
+	})
+	assert.Equal(t, "fl/models/global_model_v2", env["MODEL_URI"])
+	assert.NotContains(t, env, "MODEL_DATA", "a pinned model is fetched by the proplet")
+
+	env = startedEnv(t, svc, rec, task.Task{Name: "train", Mode: task.ModeTrain})
+	assert.NotContains(t, env, "MODEL_DATA")
+
+	page, err := svc.ListTasks(ctx, manager.PageMetadata{Limit: 10})
+	require.NoError(t, err)
+	for _, tk := range page.Tasks {
+		assert.NotContains(t, tk.Env, "MODEL_DATA", "the stored task %s is not modified", tk.Name)
+	}
+}
@@ -0,0 +1,77 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPropletLabels(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	labels := map[string]string{proplet.GroupLabel: "edge-us-east", "rack": "r1"}
+	p, err := svc.SetPropletLabels(ctx, "proplet-1", labels)
+	require.NoError(t, err)
+	assert.Equal(t, labels, p.Labels)
+
+	// Liveliness updates keep the labels.
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+	p, err = svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	assert.Equal(t, labels, p.Labels)
+	assert.True(t, p.InGroup("edge-us-east"))
+
+	p, err = svc.SetPropletLabels(ctx, "proplet-1", map[string]string{})
+	require.NoError(t, err)
+	assert.Nil(t, p.Labels)
+
+	_, err = svc.SetPropletLabels(ctx, "proplet-1", map[string]string{" ": "x"})
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+
+	_, err = svc.SetPropletLabels(ctx, "unknown", labels)
+	assert.ErrorIs(t, err, pkgerrors.ErrNotFound)
+}
+
+func TestGroupFilteredScheduling(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	for _, id := range []string{"proplet-1", "proplet-2", "proplet-3"} {
+		require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": id}))
+		require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": id}))
+	}
+	_, err := svc.SetPropletLabels(ctx, "proplet-2", map[string]string{proplet.GroupLabel: "edge-us-east"})
+	require.NoError(t, err)
+
+	for range 3 {
+		created, err := svc.CreateTask(ctx, task.Task{Name: "edge", Group: "edge-us-east"})
+		require.NoError(t, err)
+		assert.Equal(t, "edge-us-east", created.Group)
+		require.NoError(t, svc.StartTask(ctx, created.ID))
+		assert.Equal(t, []string{"proplet-2"}, rec.startsOf(created.ID))
+	}
+
+	selected, err := svc.SelectProplet(ctx, task.Task{Name: "edge", Group: "edge-us-east"})
+	require.NoError(t, err)
+	assert.Equal(t, "proplet-2", selected.ID)
+
+	// No proplet is in the group yet, so the task waits in the queue.
+	created, err := svc.CreateTask(ctx, task.Task{Name: "eu", Group: "edge-eu-west"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	assert.Empty(t, rec.startsOf(created.ID))
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Pending, got.State)
+
+	_, err = svc.CreateTask(ctx, task.Task{Name: "all", Group: "edge-us-east", Broadcast: true})
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+}
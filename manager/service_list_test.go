@@ -0,0 +1,53 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTasksFiltered(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	for i, mode := range []task.Mode{task.ModeTrain, task.ModeTrain, task.ModeTrain, task.ModeInfer, task.ModeInfer} {
+		created, err := svc.CreateTask(ctx, task.Task{Name: "task", Mode: mode})
+		require.NoError(t, err)
+		if i%2 == 0 {
+			require.NoError(t, svc.StartTask(ctx, created.ID))
+		}
+	}
+
+	cases := []struct {
+		desc      string
+		pm        manager.PageMetadata
+		wantTotal uint64
+		wantLen   int
+	}{
+		{desc: "mode", pm: manager.PageMetadata{Limit: 10, Mode: task.ModeTrain}, wantTotal: 3, wantLen: 3},
+		{desc: "state", pm: manager.PageMetadata{Limit: 10, State: "running"}, wantTotal: 3, wantLen: 3},
+		{desc: "proplet", pm: manager.PageMetadata{Limit: 10, PropletID: "proplet-1"}, wantTotal: 3, wantLen: 3},
+		{desc: "kind defaults to standard", pm: manager.PageMetadata{Limit: 10, Kind: task.TaskKindStandard}, wantTotal: 5, wantLen: 5},
+		{desc: "no federated tasks", pm: manager.PageMetadata{Limit: 10, Kind: task.TaskKindFederated}},
+		{desc: "combined", pm: manager.PageMetadata{Limit: 10, Mode: task.ModeTrain, State: "Running"}, wantTotal: 2, wantLen: 2},
+		{desc: "paginated", pm: manager.PageMetadata{Offset: 1, Limit: 1, Mode: task.ModeTrain}, wantTotal: 3, wantLen: 1},
+		{desc: "offset past end", pm: manager.PageMetadata{Offset: 5, Limit: 10, Mode: task.ModeInfer}, wantTotal: 2},
+	}
+	for _, tc := range cases {
+		page, err := svc.ListTasks(ctx, tc.pm)
+		require.NoError(t, err, tc.desc)
+		assert.Equal(t, tc.wantTotal, page.Total, tc.desc)
+		assert.Len(t, page.Tasks, tc.wantLen, tc.desc)
+	}
+
+	_, err := svc.ListTasks(ctx, manager.PageMetadata{Limit: 10, State: "sleeping"})
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+}
@@ -0,0 +1,145 @@
+package manager_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// dyingScheduler lets the proplet it picks first miss its heartbeats right
+// after being selected, as if it died before the start command went out.
+type dyingScheduler struct {
+	scheduler.Scheduler
+	repo storage.PropletRepository
+	once sync.Once
+}
+
+func (s *dyingScheduler) SelectProplet(t task.Task, proplets []proplet.Proplet) (proplet.Proplet, error) {
+	p, err := s.Scheduler.SelectProplet(t, proplets)
+	if err != nil {
+		return p, err
+	}
+	s.once.Do(func() {
+		dead, err := s.repo.Get(context.Background(), p.ID)
+		if err != nil {
+			return
+		}
+		dead.AliveHistory = []time.Time{time.Now().Add(-time.Minute)}
+		_ = s.repo.Update(context.Background(), dead)
+	})
+
+	return p, nil
+}
+
+func newDyingSchedulerService(t *testing.T) (manager.Service, mqtt.Handler, func() []string) {
+	t.Helper()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+
+	var (
+		mu      sync.Mutex
+		starts  []string
+		handler mqtt.Handler
+	)
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if args.String(1) != testStartTopic {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		starts = append(starts, propletOf(args.Get(2)))
+	}).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if h, ok := args.Get(2).(mqtt.Handler); ok && handler == nil {
+			handler = h
+		}
+	}).Return(nil).Maybe()
+
+	sched := &dyingScheduler{Scheduler: scheduler.NewRoundRobin(), repo: repos.Proplets}
+	svc, _, _ := manager.NewService(repos, sched, pubsub, "test-domain", "test-channel", "", slog.Default(), nil)
+	require.NoError(t, svc.Subscribe(context.Background()))
+
+	return svc, handler, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]string(nil), starts...)
+	}
+}
+
+func propletOf(payload any) string {
+	data, _ := json.Marshal(payload)
+	var start struct {
+		PropletID string `json:"proplet_id"`
+	}
+	_ = json.Unmarshal(data, &start)
+
+	return start.PropletID
+}
+
+func TestStartTaskReselectsWhenSelectedPropletDies(t *testing.T) {
+	t.Parallel()
+	svc, handler, starts := newDyingSchedulerService(t)
+	ctx := context.Background()
+	for _, id := range []string{"proplet-1", "proplet-2"} {
+		require.NoError(t, handler(testCreateTopic, map[string]any{"proplet_id": id}))
+		require.NoError(t, handler(testAliveTopic, map[string]any{"proplet_id": id}))
+	}
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "task"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	got := starts()
+	require.Len(t, got, 1)
+	survivor := got[0]
+
+	dead := "proplet-1"
+	if survivor == "proplet-1" {
+		dead = "proplet-2"
+	}
+	p, err := svc.GetProplet(ctx, dead)
+	require.NoError(t, err)
+	assert.False(t, p.Alive)
+
+	stored, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, survivor, stored.PropletID)
+	assert.Equal(t, task.Running, stored.State)
+}
+
+func TestStartTaskQueuesWhenOnlyPropletDies(t *testing.T) {
+	t.Parallel()
+	svc, handler, starts := newDyingSchedulerService(t)
+	ctx := context.Background()
+	require.NoError(t, handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "task"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	assert.Empty(t, starts())
+
+	stored, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Pending, stored.State)
+	assert.Empty(t, stored.PropletID)
+
+	require.NoError(t, handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+	assert.Equal(t, []string{"proplet-1"}, starts())
+}
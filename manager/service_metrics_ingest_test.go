@@ -0,0 +1,118 @@
+package manager_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedMetrics holds every proplet metrics write until release is closed and
+// signals each write as it starts.
+type gatedMetrics struct {
+	storage.MetricsRepository
+	started chan struct{}
+	release chan struct{}
+}
+
+func (g *gatedMetrics) CreatePropletMetrics(ctx context.Context, m storage.PropletMetrics) error {
+	g.started <- struct{}{}
+	<-g.release
+
+	return g.MetricsRepository.CreatePropletMetrics(ctx, m)
+}
+
+func newGatedMetricsService(t *testing.T, cfg manager.MetricsIngestConfig) (manager.Service, *gatedMetrics, mqtt.Handler) {
+	t.Helper()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	gated := &gatedMetrics{
+		MetricsRepository: repos.Metrics,
+		started:           make(chan struct{}, 16),
+		release:           make(chan struct{}),
+	}
+	repos.Metrics = gated
+
+	var handler mqtt.Handler
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if h, ok := args.Get(2).(mqtt.Handler); ok && handler == nil {
+			handler = h
+		}
+	}).Return(nil).Maybe()
+
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil, manager.WithMetricsIngest(cfg))
+	require.NoError(t, svc.Subscribe(context.Background()))
+
+	return svc, gated, handler
+}
+
+func propletMetricsTotal(t *testing.T, svc manager.Service) uint64 {
+	t.Helper()
+	page, err := svc.GetPropletMetrics(context.Background(), "proplet-1", 0, 10)
+	require.NoError(t, err)
+
+	return page.Total
+}
+
+func TestMetricsWorkersPersistMetrics(t *testing.T) {
+	t.Parallel()
+	svc, gated, handler := newGatedMetricsService(t, manager.MetricsIngestConfig{Workers: 2, Buffer: 1024})
+	close(gated.release)
+
+	for range 5 {
+		require.NoError(t, handler(testMetricsTopic, map[string]any{"proplet_id": "proplet-1"}))
+	}
+
+	require.NoError(t, svc.Shutdown(context.Background()))
+	assert.Equal(t, uint64(5), propletMetricsTotal(t, svc))
+}
+
+func TestMetricsBufferFullDrops(t *testing.T) {
+	t.Parallel()
+	svc, gated, handler := newGatedMetricsService(t, manager.MetricsIngestConfig{Workers: 1, Buffer: 1, FullPolicy: manager.MetricsPolicyDrop})
+	msg := map[string]any{"proplet_id": "proplet-1"}
+
+	require.NoError(t, handler(testMetricsTopic, msg))
+	<-gated.started
+	require.NoError(t, handler(testMetricsTopic, msg))
+	require.NoError(t, handler(testMetricsTopic, msg))
+	require.NoError(t, handler(testMetricsTopic, msg))
+
+	close(gated.release)
+	require.NoError(t, svc.Shutdown(context.Background()))
+	assert.Equal(t, uint64(2), propletMetricsTotal(t, svc))
+}
+
+func TestMetricsBufferFullBlocks(t *testing.T) {
+	t.Parallel()
+	svc, gated, handler := newGatedMetricsService(t, manager.MetricsIngestConfig{Workers: 1, Buffer: 1, FullPolicy: manager.MetricsPolicyBlock})
+	msg := map[string]any{"proplet_id": "proplet-1"}
+
+	require.NoError(t, handler(testMetricsTopic, msg))
+	<-gated.started
+	require.NoError(t, handler(testMetricsTopic, msg))
+
+	blocked := make(chan error)
+	go func() { blocked <- handler(testMetricsTopic, msg) }()
+	select {
+	case <-blocked:
+		t.Fatal("handler returned while the metrics buffer was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(gated.release)
+	require.NoError(t, <-blocked)
+	require.NoError(t, svc.Shutdown(context.Background()))
+	assert.Equal(t, uint64(3), propletMetricsTotal(t, svc))
+}
@@ -0,0 +1,79 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartTaskResolvesMonitoringProfile(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	cases := []struct {
+		desc     string
+		profile  *proplet.MonitoringProfile
+		enabled  bool
+		interval time.Duration
+	}{
+		{
+			desc:     "detailed",
+			profile:  &proplet.MonitoringProfile{Name: proplet.MonitoringDetailed},
+			enabled:  true,
+			interval: 5 * time.Second,
+		},
+		{
+			desc:     "basic",
+			profile:  &proplet.MonitoringProfile{Name: proplet.MonitoringBasic},
+			enabled:  true,
+			interval: 30 * time.Second,
+		},
+		{
+			desc:    "off",
+			profile: &proplet.MonitoringProfile{Name: proplet.MonitoringOff},
+		},
+		{
+			desc:     "custom",
+			profile:  &proplet.MonitoringProfile{Enabled: true, Interval: time.Minute, ExportToMQTT: true},
+			enabled:  true,
+			interval: time.Minute,
+		},
+	}
+
+	for _, tc := range cases {
+		created, err := svc.CreateTask(ctx, task.Task{Name: tc.desc, MonitoringProfile: tc.profile})
+		require.NoError(t, err, tc.desc)
+		require.NoError(t, svc.StartTask(ctx, created.ID), tc.desc)
+
+		published, ok := rec.payload(created.ID)["monitoring_profile"].(map[string]any)
+		require.True(t, ok, tc.desc)
+		assert.Equal(t, tc.enabled, published["enabled"], tc.desc)
+		assert.InDelta(t, float64(tc.interval), published["interval"], 0, tc.desc)
+	}
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "default"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	assert.NotContains(t, rec.payload(created.ID), "monitoring_profile", "the proplet picks its default")
+}
+
+func TestCreateTaskRejectsUnknownMonitoringProfile(t *testing.T) {
+	t.Parallel()
+	svc := newService(t)
+
+	_, err := svc.CreateTask(context.Background(), task.Task{
+		Name:              "verbose",
+		MonitoringProfile: &proplet.MonitoringProfile{Name: "verbose"},
+	})
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+	assert.ErrorIs(t, err, proplet.ErrUnknownMonitoringProfile)
+}
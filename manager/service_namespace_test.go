@@ -0,0 +1,71 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaceScopedPlacement(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	for id, ns := range map[string]string{"proplet-1": "team-a", "proplet-2": "team-b"} {
+		require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": id, "namespace": ns}))
+		require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": id, "namespace": ns}))
+	}
+
+	p, err := svc.GetProplet(ctx, "proplet-2")
+	require.NoError(t, err)
+	assert.Equal(t, "team-b", p.Namespace)
+
+	for range 3 {
+		created, err := svc.CreateTask(ctx, task.Task{Name: "scoped", Namespace: "team-b"})
+		require.NoError(t, err)
+		assert.Equal(t, "team-b", created.Namespace)
+		require.NoError(t, svc.StartTask(ctx, created.ID))
+		assert.Equal(t, []string{"proplet-2"}, rec.startsOf(created.ID))
+	}
+
+	// An empty namespace places the task on any proplet.
+	var proplets []string
+	for range 2 {
+		created, err := svc.CreateTask(ctx, task.Task{Name: "any"})
+		require.NoError(t, err)
+		require.NoError(t, svc.StartTask(ctx, created.ID))
+		proplets = append(proplets, rec.startsOf(created.ID)...)
+	}
+	assert.ElementsMatch(t, []string{"proplet-1", "proplet-2"}, proplets)
+
+	// No proplet is in the namespace, so the task waits in the queue.
+	created, err := svc.CreateTask(ctx, task.Task{Name: "orphan", Namespace: "team-c"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	assert.Empty(t, rec.startsOf(created.ID))
+
+	// A proplet that moves namespace is picked up on its next liveliness update.
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1", "namespace": "team-c"}))
+	_, err = svc.SelectProplet(ctx, task.Task{Name: "orphan", Namespace: "team-c"})
+	require.NoError(t, err)
+}
+
+func TestNamespaceMismatchOnPinnedProplet(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1", "namespace": "team-a"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1", "namespace": "team-a"}))
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "pinned", PropletID: "proplet-1", Namespace: "team-b"})
+	require.NoError(t, err)
+	err = svc.StartTask(ctx, created.ID)
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+	assert.Empty(t, rec.startsOf(created.ID))
+
+	_, err = svc.CreateTask(ctx, task.Task{Name: "all", Namespace: "team-a", Broadcast: true})
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+}
@@ -92,7 +92,7 @@ func TestListPropletsFilterByStatus(t *testing.T) {
 				require.NoError(t, repos.Proplets.Create(ctx, inactiveProplet))
 			}
 
-			page, err := svc.ListProplets(ctx, 0, 100, tc.status)
+			page, err := svc.ListProplets(ctx, 0, 100, tc.status, manager.Sort{})
 			if tc.err {
 				require.Error(t, err)
 				require.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
@@ -150,7 +150,7 @@ func TestListPropletsFilterPagination(t *testing.T) {
 				require.NoError(t, repos.Proplets.Create(ctx, p))
 			}
 
-			page, err := svc.ListProplets(ctx, tc.offset, tc.limit, proplet.ActiveStatus.String())
+			page, err := svc.ListProplets(ctx, tc.offset, tc.limit, proplet.ActiveStatus.String(), manager.Sort{})
 			require.NoError(t, err)
 			assert.Equal(t, tc.expectedTotal, page.Total)
 			assert.Len(t, page.Proplets, tc.expectedLen)
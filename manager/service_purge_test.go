@@ -0,0 +1,125 @@
+package manager_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeTasks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	seed := []task.Task{
+		{ID: "old-completed", State: task.Completed, FinishTime: now.Add(-48 * time.Hour)},
+		{ID: "old-failed", State: task.Failed, FinishTime: now.Add(-48 * time.Hour)},
+		{ID: "new-completed", State: task.Completed, FinishTime: now.Add(-time.Hour)},
+		{ID: "old-running", State: task.Running, StartTime: now.Add(-48 * time.Hour), UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "old-pending", State: task.Pending, UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "old-unfinished-interrupted", State: task.Interrupted, UpdatedAt: now.Add(-48 * time.Hour)},
+	}
+
+	cases := []struct {
+		desc   string
+		filter manager.PurgeFilter
+		purged []string
+	}{
+		{
+			desc:   "completed older than a day",
+			filter: manager.PurgeFilter{States: []task.State{task.Completed}, OlderThan: 24 * time.Hour},
+			purged: []string{"old-completed"},
+		},
+		{
+			desc:   "completed and failed older than a day",
+			filter: manager.PurgeFilter{States: []task.State{task.Completed, task.Failed}, OlderThan: 24 * time.Hour},
+			purged: []string{"old-completed", "old-failed"},
+		},
+		{
+			desc:   "all terminal states older than a day",
+			filter: manager.PurgeFilter{OlderThan: 24 * time.Hour},
+			purged: []string{"old-completed", "old-failed", "old-unfinished-interrupted"},
+		},
+		{
+			desc:   "completed of any age",
+			filter: manager.PurgeFilter{States: []task.State{task.Completed}},
+			purged: []string{"old-completed", "new-completed"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			svc, repos := newServiceWithRepos(t)
+			ctx := context.Background()
+			for _, st := range seed {
+				_, err := repos.Tasks.Create(ctx, st)
+				require.NoError(t, err)
+				require.NoError(t, repos.TaskProplets.Create(ctx, st.ID, "proplet-1"))
+			}
+
+			deleted, err := svc.PurgeTasks(ctx, tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(len(tc.purged)), deleted)
+
+			for _, st := range seed {
+				_, getErr := svc.GetTask(ctx, st.ID)
+				propletID, _ := repos.TaskProplets.Get(ctx, st.ID)
+				if slices.Contains(tc.purged, st.ID) {
+					assert.Error(t, getErr, st.ID)
+					assert.Empty(t, propletID, st.ID)
+
+					continue
+				}
+				assert.NoError(t, getErr, st.ID)
+				assert.Equal(t, "proplet-1", propletID, st.ID)
+			}
+		})
+	}
+}
+
+func TestPurgeTasksRejectsActiveStates(t *testing.T) {
+	t.Parallel()
+	svc, repos := newServiceWithRepos(t)
+	ctx := context.Background()
+	_, err := repos.Tasks.Create(ctx, task.Task{ID: "running", State: task.Running})
+	require.NoError(t, err)
+
+	deleted, err := svc.PurgeTasks(ctx, manager.PurgeFilter{States: []task.State{task.Running}})
+	assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue)
+	assert.Zero(t, deleted)
+
+	_, err = svc.GetTask(ctx, "running")
+	assert.NoError(t, err)
+}
+
+func TestPurgeTasksReleasesRoundParticipants(t *testing.T) {
+	t.Parallel()
+	svc, repos := newServiceWithRepos(t)
+	ctx := context.Background()
+	_, err := repos.Tasks.Create(ctx, task.Task{
+		ID:         "round-task",
+		State:      task.Completed,
+		PropletID:  "proplet-1",
+		FinishTime: time.Now(),
+		Metadata:   task.Metadata{"fl_round_id": "round-1"},
+	})
+	require.NoError(t, err)
+	reserved, err := repos.Rounds.Reserve(ctx, "round-1", "proplet-1")
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	deleted, err := svc.PurgeTasks(ctx, manager.PurgeFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), deleted)
+
+	reserved, err = repos.Rounds.Reserve(ctx, "round-1", "proplet-1")
+	require.NoError(t, err)
+	assert.True(t, reserved, "purging the round task releases its participant")
+}
@@ -0,0 +1,337 @@
+package manager_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testStartTopic  = "m/test-domain/c/test-channel/control/manager/start"
+	testAliveTopic  = "m/test-domain/c/test-channel/control/proplet/alive"
+	testCreateTopic = "m/test-domain/c/test-channel/control/proplet/create"
+)
+
+type startRecorder struct {
+	mu       sync.Mutex
+	ids      []string
+	payloads []map[string]any
+	handler  mqtt.Handler
+}
+
+func (r *startRecorder) started() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.ids...)
+}
+
+func (r *startRecorder) payload(taskID string) map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, id := range r.ids {
+		if id == taskID {
+			return r.payloads[i]
+		}
+	}
+
+	return nil
+}
+
+func newRecordingService(t *testing.T, opts ...manager.Option) (manager.Service, *startRecorder) {
+	t.Helper()
+
+	return newLoggedService(t, slog.Default(), opts...)
+}
+
+func newLoggedService(t *testing.T, logger *slog.Logger, opts ...manager.Option) (manager.Service, *startRecorder) {
+	t.Helper()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+
+	return newServiceOn(t, repos, logger, opts...)
+}
+
+// newServiceOn returns a recording service on repos, so that several
+// services can share storage like managers restarted on the same database.
+func newServiceOn(t *testing.T, repos *storage.Repositories, logger *slog.Logger, opts ...manager.Option) (manager.Service, *startRecorder) {
+	t.Helper()
+	rec := &startRecorder{}
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if args.String(1) != testStartTopic {
+			return
+		}
+		data, err := json.Marshal(args.Get(2))
+		require.NoError(t, err)
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal(data, &payload))
+		rec.mu.Lock()
+		id, _ := payload["id"].(string)
+		rec.ids = append(rec.ids, id)
+		rec.payloads = append(rec.payloads, payload)
+		rec.mu.Unlock()
+	}).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if h, ok := args.Get(2).(mqtt.Handler); ok && rec.handler == nil {
+			rec.handler = h
+		}
+	}).Return(nil).Maybe()
+
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", logger, nil, opts...)
+	require.NoError(t, svc.Subscribe(context.Background()))
+	require.NotNil(t, rec.handler)
+
+	return svc, rec
+}
+
+func TestStartTaskQueuesWithoutProplets(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "queued"})
+	require.NoError(t, err)
+
+	err = svc.StartTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, rec.started())
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Pending, got.State)
+
+	err = svc.StopTask(ctx, created.ID)
+	require.NoError(t, err)
+	got, err = svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, got.QueuedAt.IsZero())
+
+	err = rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"})
+	require.NoError(t, err)
+	err = rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"})
+	require.NoError(t, err)
+	assert.Empty(t, rec.started())
+}
+
+func TestQueuedTasksRecoveredAfterRestart(t *testing.T) {
+	t.Parallel()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	svc, _ := newServiceOn(t, repos, slog.Default())
+	low, err := svc.CreateTask(ctx, task.Task{Name: "low", Priority: 1})
+	require.NoError(t, err)
+	high, err := svc.CreateTask(ctx, task.Task{Name: "high", Priority: 10})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, low.ID))
+	require.NoError(t, svc.StartTask(ctx, high.ID))
+
+	queued, err := repos.Tasks.Get(ctx, low.ID)
+	require.NoError(t, err)
+	assert.False(t, queued.QueuedAt.IsZero())
+
+	restarted, rec := newServiceOn(t, repos, slog.Default())
+	require.NoError(t, restarted.RecoverInterruptedTasks(ctx))
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+	assert.Equal(t, []string{high.ID, low.ID}, rec.started())
+
+	started, err := repos.Tasks.Get(ctx, low.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Running, started.State)
+	assert.True(t, started.QueuedAt.IsZero())
+}
+
+func TestStartTaskPublishesStartOnce(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	created, err := svc.CreateTask(ctx, task.Task{
+		Name:    "fl-train",
+		Kind:    task.TaskKindFederated,
+		CLIArgs: []string{"--epochs", "1"},
+		Env: map[string]string{
+			"ROUND_ID":       "round-1",
+			"FL_NUM_SAMPLES": "10",
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	require.Equal(t, []string{created.ID}, rec.started())
+	payload := rec.payload(created.ID)
+	assert.Equal(t, "proplet-1", payload["proplet_id"])
+	assert.Equal(t, []any{"--epochs", "1"}, payload["cli_args"])
+	env, ok := payload["env"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "round-1", env["ROUND_ID"])
+	assert.Equal(t, "10", env["FL_NUM_SAMPLES"])
+}
+
+func TestStartTaskIsIdempotent(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "task"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	assert.Equal(t, []string{created.ID}, rec.started())
+	p, err := svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), p.TaskCount)
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Running, got.State)
+	assert.Equal(t, 1, got.Attempts)
+}
+
+func TestPendingQueueSchedulesByPriority(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc       string
+		priorities []int
+		order      []int
+	}{
+		{
+			desc:       "higher priority first",
+			priorities: []int{10, 90, 50},
+			order:      []int{1, 2, 0},
+		},
+		{
+			desc:       "unset priority uses default",
+			priorities: []int{0, 40, 60},
+			order:      []int{2, 0, 1},
+		},
+		{
+			desc:       "equal priority keeps creation order",
+			priorities: []int{70, 70, 70},
+			order:      []int{0, 1, 2},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			svc, rec := newRecordingService(t)
+			ctx := context.Background()
+
+			ids := make([]string, len(tc.priorities))
+			for i, prio := range tc.priorities {
+				created, err := svc.CreateTask(ctx, task.Task{Name: "task", Priority: prio})
+				require.NoError(t, err)
+				ids[i] = created.ID
+				require.NoError(t, svc.StartTask(ctx, created.ID))
+			}
+			require.Empty(t, rec.started())
+
+			err := rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"})
+			require.NoError(t, err)
+			require.Empty(t, rec.started())
+
+			err = rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"})
+			require.NoError(t, err)
+
+			want := make([]string, len(tc.order))
+			for i, idx := range tc.order {
+				want[i] = ids[idx]
+			}
+			assert.Equal(t, want, rec.started())
+
+			for _, id := range ids {
+				got, err := svc.GetTask(ctx, id)
+				require.NoError(t, err)
+				assert.Equal(t, task.Running, got.State)
+			}
+		})
+	}
+}
+
+func TestTopicPrefixScopesTopics(t *testing.T) {
+	t.Parallel()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		topics []string
+	)
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		topics = append(topics, args.String(1))
+		mu.Unlock()
+	}).Return(nil)
+
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil, manager.WithTopicPrefix("staging"))
+	require.NoError(t, svc.Subscribe(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, topics, "staging/test-domain/c/test-channel/#")
+	assert.True(t, slices.ContainsFunc(topics, func(pattern string) bool {
+		return mqtt.TopicMatch("staging/test-domain/c/test-channel/control/proplet/alive", pattern)
+	}))
+	assert.False(t, slices.ContainsFunc(topics, func(pattern string) bool {
+		return mqtt.TopicMatch(testAliveTopic, pattern)
+	}))
+}
+
+func TestHandleRoutesOnControlPath(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc    string
+		topic   string
+		created bool
+	}{
+		{desc: "base topic", topic: testCreateTopic, created: true},
+		{desc: "extra leading segments", topic: "tenant/" + testCreateTopic, created: true},
+		{desc: "other prefix", topic: "staging/other-domain/c/other-channel/control/proplet/create", created: true},
+		{desc: "bare control path", topic: "control/proplet/create", created: true},
+		{desc: "unknown action", topic: "m/test-domain/c/test-channel/control/proplet/unknown", created: false},
+		{desc: "manager control path", topic: "m/test-domain/c/test-channel/control/manager/create", created: false},
+		{desc: "control path not trailing", topic: testCreateTopic + "/extra", created: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			svc, rec := newRecordingService(t)
+
+			require.NoError(t, rec.handler(tc.topic, map[string]any{"proplet_id": "proplet-1"}))
+
+			_, err := svc.GetProplet(context.Background(), "proplet-1")
+			if tc.created {
+				require.NoError(t, err)
+
+				return
+			}
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package manager_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rateLimitedTotal(t *testing.T, kind string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "manager_ingest_rate_limited_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "kind" && label.GetValue() == kind {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestIngestRateLimit(t *testing.T) {
+	svc, rec := newRecordingService(t, manager.WithIngestLimit(manager.IngestLimitConfig{Rate: 0.001, Burst: 3}))
+	ctx := context.Background()
+
+	metricsBefore := rateLimitedTotal(t, "metrics")
+	for range 5 {
+		require.NoError(t, rec.handler(testMetricsTopic, map[string]any{"proplet_id": "proplet-1"}))
+	}
+	require.NoError(t, rec.handler(testMetricsTopic, map[string]any{"proplet_id": "proplet-2"}))
+
+	page, err := svc.GetPropletMetrics(ctx, "proplet-1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), page.Total)
+	page, err = svc.GetPropletMetrics(ctx, "proplet-2", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), page.Total)
+	assert.InDelta(t, 2, rateLimitedTotal(t, "metrics")-metricsBefore, 0)
+
+	taskMetricsBefore := rateLimitedTotal(t, "task_metrics")
+	for range 4 {
+		require.NoError(t, rec.handler(testTaskMetricsTopic, map[string]any{
+			"task_id":    "task-1",
+			"proplet_id": "proplet-1",
+			"metrics":    map[string]any{"cpu_percent": 3.0},
+		}))
+	}
+	taskPage, err := svc.GetTaskMetrics(ctx, "task-1", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), taskPage.Total)
+	assert.InDelta(t, 1, rateLimitedTotal(t, "task_metrics")-taskMetricsBefore, 0)
+
+	resultsBefore := rateLimitedTotal(t, "results")
+	var ids []string
+	for i := range 4 {
+		created, err := svc.CreateTask(ctx, task.Task{Name: "limited-" + strconv.Itoa(i)})
+		require.NoError(t, err)
+		ids = append(ids, created.ID)
+		require.NoError(t, rec.handler(testResultsTopic, map[string]any{
+			"task_id":    created.ID,
+			"proplet_id": "proplet-1",
+			"results":    "done",
+		}))
+	}
+	for i, id := range ids {
+		got, err := svc.GetTask(ctx, id)
+		require.NoError(t, err)
+		if i < 3 {
+			assert.Equal(t, task.Completed, got.State)
+		} else {
+			assert.NotEqual(t, task.Completed, got.State)
+		}
+	}
+	assert.InDelta(t, 1, rateLimitedTotal(t, "results")-resultsBefore, 0)
+}
@@ -0,0 +1,160 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startOnProplet(t *testing.T, svc manager.Service, names ...string) []string {
+	t.Helper()
+	ctx := context.Background()
+
+	ids := make([]string, len(names))
+	for i, name := range names {
+		created, err := svc.CreateTask(ctx, task.Task{Name: name, PropletID: "proplet-1"})
+		require.NoError(t, err)
+		require.NoError(t, svc.StartTask(ctx, created.ID))
+		ids[i] = created.ID
+	}
+
+	return ids
+}
+
+func heartbeat(taskIDs ...string) map[string]any {
+	ids := make([]any, len(taskIDs))
+	for i, id := range taskIDs {
+		ids[i] = id
+	}
+
+	return map[string]any{"proplet_id": "proplet-1", "task_ids": ids}
+}
+
+func TestHeartbeatReconcilesTaskCount(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t, manager.WithOrphans(manager.OrphanConfig{Confirmations: 3, Grace: time.Hour}))
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	ids := startOnProplet(t, svc, "a", "b", "c")
+	p, err := svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), p.TaskCount)
+
+	require.NoError(t, rec.handler(testAliveTopic, heartbeat(ids[0])))
+	p, err = svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), p.TaskCount)
+
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+	p, err = svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), p.TaskCount, "heartbeats without a task list leave the count alone")
+
+	for _, id := range ids {
+		got, err := svc.GetTask(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, task.Running, got.State, "tasks are not orphaned within the grace period")
+	}
+}
+
+func TestHeartbeatFailsOrphanedTasks(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t, manager.WithOrphans(manager.OrphanConfig{Confirmations: 3, Grace: 10 * time.Millisecond}))
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	ids := startOnProplet(t, svc, "kept", "lost", "late")
+
+	// "late" is missing from the first heartbeat only, as when its start
+	// message has not reached the proplet yet.
+	require.NoError(t, rec.handler(testAliveTopic, heartbeat(ids[0])))
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, rec.handler(testAliveTopic, heartbeat(ids[0], ids[2])))
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, rec.handler(testAliveTopic, heartbeat(ids[0], ids[2])))
+
+	kept, err := svc.GetTask(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, task.Running, kept.State)
+
+	lost, err := svc.GetTask(ctx, ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, task.Failed, lost.State)
+	assert.Contains(t, lost.Error, "lost on proplet proplet-1")
+
+	late, err := svc.GetTask(ctx, ids[2])
+	require.NoError(t, err)
+	assert.Equal(t, task.Running, late.State)
+
+	p, err := svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), p.TaskCount)
+}
+
+func TestTaskLostAfterConfirmations(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t, manager.WithOrphans(manager.OrphanConfig{Confirmations: 3}))
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	ids := startOnProplet(t, svc, "flapping")
+	state := func() task.State {
+		got, err := svc.GetTask(ctx, ids[0])
+		require.NoError(t, err)
+
+		return got.State
+	}
+
+	// Dropping the task from fewer than three heartbeats in a row, even
+	// repeatedly, is not enough.
+	for range 2 {
+		require.NoError(t, rec.handler(testAliveTopic, heartbeat()))
+		require.NoError(t, rec.handler(testAliveTopic, heartbeat()))
+		require.Equal(t, task.Running, state())
+		require.NoError(t, rec.handler(testAliveTopic, heartbeat(ids[0])))
+	}
+
+	for range 2 {
+		require.NoError(t, rec.handler(testAliveTopic, heartbeat()))
+	}
+	require.Equal(t, task.Running, state())
+	require.NoError(t, rec.handler(testAliveTopic, heartbeat()))
+
+	got, err := svc.GetTask(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, task.Failed, got.State)
+	assert.Equal(t, "lost on proplet proplet-1: missing from 3 consecutive heartbeats", got.Error)
+	assert.False(t, got.FinishTime.IsZero())
+
+	err = svc.StopTask(ctx, ids[0])
+	assert.Error(t, err, "the lost task is no longer pinned to the proplet")
+}
+
+func TestOrphanDetectionDisabled(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t, manager.WithOrphans(manager.OrphanConfig{Confirmations: 0}))
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	ids := startOnProplet(t, svc, "a")
+	require.NoError(t, rec.handler(testAliveTopic, heartbeat()))
+	require.NoError(t, rec.handler(testAliveTopic, heartbeat()))
+
+	got, err := svc.GetTask(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, task.Running, got.State)
+
+	p, err := svc.GetProplet(ctx, "proplet-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), p.TaskCount)
+}
@@ -0,0 +1,47 @@
+package manager_test
+
+import (
+	"testing"
+
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensitiveEnvRedactedInLogs(t *testing.T) {
+	svc, rec, logs := newCapturingService(t)
+
+	env := startedEnv(t, svc, rec, task.Task{
+		Name: "train",
+		Env: map[string]string{
+			"DATASET_TOKEN": "tok-123",
+			"db_password":   "hunter2",
+			"MODE":          "edge",
+		},
+	})
+	assert.Equal(t, map[string]any{
+		"DATASET_TOKEN": "tok-123",
+		"db_password":   "hunter2",
+		"MODE":          "edge",
+	}, env, "sensitive values are still delivered to the proplet")
+
+	out := logs.String()
+	assert.Contains(t, out, "publishing task start")
+	assert.Contains(t, out, "DATASET_TOKEN")
+	assert.NotContains(t, out, "tok-123")
+	assert.NotContains(t, out, "hunter2")
+}
+
+func TestSecretRefValuesNeverLogged(t *testing.T) {
+	t.Setenv("PROPELLER_SECRET_DB", "postgres://user:pw@db")
+	svc, rec, logs := newCapturingService(t)
+
+	env := startedEnv(t, svc, rec, task.Task{
+		Name:       "train",
+		SecretRefs: map[string]string{"DB_CONNECTION": "DB"},
+	})
+	assert.Equal(t, "postgres://user:pw@db", env["DB_CONNECTION"])
+
+	out := logs.String()
+	assert.Contains(t, out, "DB_CONNECTION")
+	assert.NotContains(t, out, "postgres://user:pw@db", "secret refs are hidden even when no pattern matches their name")
+}
@@ -0,0 +1,207 @@
+package manager_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var errTransient = errors.New("storage temporarily unavailable")
+
+func retries(attempts int) manager.Option {
+	return manager.WithRedelivery(manager.RedeliveryConfig{Attempts: attempts, Backoff: time.Millisecond})
+}
+
+// flakyTasks fails the first failures updates that finish a task.
+type flakyTasks struct {
+	storage.TaskRepository
+	failures atomic.Int32
+	calls    atomic.Int32
+}
+
+func (f *flakyTasks) Update(ctx context.Context, t task.Task) error {
+	if t.State.IsTerminal() {
+		f.calls.Add(1)
+		if f.failures.Add(-1) >= 0 {
+			return errTransient
+		}
+	}
+
+	return f.TaskRepository.Update(ctx, t)
+}
+
+type deadLetter struct {
+	topic   string
+	payload string
+	cause   string
+}
+
+// deadLetterPubSub adds dead-lettering to the mock PubSub.
+type deadLetterPubSub struct {
+	*mqttmocks.MockPubSub
+	mu      sync.Mutex
+	letters []deadLetter
+}
+
+func (p *deadLetterPubSub) DeadLetter(_ context.Context, topic string, payload []byte, cause error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.letters = append(p.letters, deadLetter{topic: topic, payload: string(payload), cause: cause.Error()})
+
+	return nil
+}
+
+func (p *deadLetterPubSub) deadLetters() []deadLetter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]deadLetter(nil), p.letters...)
+}
+
+func newFlakyService(t *testing.T, failures int32, opts ...manager.Option) (manager.Service, *flakyTasks, mqtt.Handler) {
+	t.Helper()
+	svc, tasks, _, handler := newDeadLetterService(t, failures, opts...)
+
+	return svc, tasks, handler
+}
+
+func newDeadLetterService(t *testing.T, failures int32, opts ...manager.Option) (manager.Service, *flakyTasks, *deadLetterPubSub, mqtt.Handler) {
+	t.Helper()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	tasks := &flakyTasks{TaskRepository: repos.Tasks}
+	tasks.failures.Store(failures)
+	repos.Tasks = tasks
+
+	var handler mqtt.Handler
+	pubsub := &deadLetterPubSub{MockPubSub: mqttmocks.NewMockPubSub(t)}
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if h, ok := args.Get(2).(mqtt.Handler); ok && handler == nil {
+			handler = h
+		}
+	}).Return(nil).Maybe()
+
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil, opts...)
+	require.NoError(t, svc.Subscribe(context.Background()))
+	require.NotNil(t, handler)
+
+	return svc, tasks, pubsub, handler
+}
+
+func TestResultsRedeliveredAfterTransientFailures(t *testing.T) {
+	t.Parallel()
+	svc, tasks, handler := newFlakyService(t, 2, retries(5))
+	ctx := context.Background()
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "flaky"})
+	require.NoError(t, err)
+
+	err = handler(testResultsTopic, map[string]any{
+		"task_id":    created.ID,
+		"proplet_id": "proplet-1",
+		"results":    "done",
+	})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		got, err := svc.GetTask(ctx, created.ID)
+
+		return err == nil && got.State == task.Completed
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(3), tasks.calls.Load())
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "done", got.Results)
+}
+
+func TestResultsDeadLetteredAfterRetriesExhausted(t *testing.T) {
+	t.Parallel()
+	svc, tasks, handler := newFlakyService(t, 10, retries(2))
+	ctx := context.Background()
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "doomed"})
+	require.NoError(t, err)
+
+	require.NoError(t, handler(testResultsTopic, map[string]any{
+		"task_id": created.ID,
+		"results": "done",
+	}))
+
+	assert.Eventually(t, func() bool {
+		return tasks.calls.Load() == 3
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, svc.Shutdown(ctx))
+	assert.Equal(t, int32(3), tasks.calls.Load())
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Pending, got.State)
+}
+
+func TestMalformedResultsAreNotRetried(t *testing.T) {
+	t.Parallel()
+	svc, tasks, handler := newFlakyService(t, 0)
+
+	err := handler(testResultsTopic, map[string]any{"task_id": 42})
+	require.Error(t, err)
+
+	err = handler(testResultsTopic, map[string]any{"task_id": "missing"})
+	require.Error(t, err)
+
+	require.NoError(t, svc.Shutdown(context.Background()))
+	assert.Zero(t, tasks.calls.Load())
+}
+
+func TestResultRetriesDisabled(t *testing.T) {
+	t.Parallel()
+	svc, tasks, handler := newFlakyService(t, 1, retries(0))
+
+	created, err := svc.CreateTask(context.Background(), task.Task{Name: "no-retry"})
+	require.NoError(t, err)
+
+	err = handler(testResultsTopic, map[string]any{"task_id": created.ID, "results": "done"})
+	require.ErrorIs(t, err, errTransient)
+	assert.Equal(t, int32(1), tasks.calls.Load())
+	require.NoError(t, svc.Shutdown(context.Background()))
+}
+
+func TestExhaustedResultsAreDeadLettered(t *testing.T) {
+	t.Parallel()
+	svc, tasks, pubsub, handler := newDeadLetterService(t, 10, retries(1))
+	ctx := context.Background()
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "doomed"})
+	require.NoError(t, err)
+
+	require.NoError(t, handler(testResultsTopic, map[string]any{
+		"task_id": created.ID,
+		"results": "done",
+	}))
+
+	assert.Eventually(t, func() bool {
+		return len(pubsub.deadLetters()) == 1
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, svc.Shutdown(ctx))
+	assert.Equal(t, int32(2), tasks.calls.Load())
+
+	letter := pubsub.deadLetters()[0]
+	assert.Equal(t, testResultsTopic, letter.topic)
+	assert.JSONEq(t, `{"task_id":"`+created.ID+`","results":"done"}`, letter.payload)
+	assert.Equal(t, errTransient.Error(), letter.cause)
+}
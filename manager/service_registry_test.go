@@ -0,0 +1,274 @@
+package manager_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/events"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRegistry is a minimal in-memory OCI distribution registry.
+type stubRegistry struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	types     map[string]string
+}
+
+func newStubRegistry() *stubRegistry {
+	return &stubRegistry{
+		blobs:     make(map[string][]byte),
+		manifests: make(map[string][]byte),
+		types:     make(map[string]string),
+	}
+}
+
+func (s *stubRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/blobs/uploads/"):
+		w.Header().Set("Location", path+"upload")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && strings.HasSuffix(path, "/blobs/uploads/upload"):
+		body, _ := io.ReadAll(r.Body)
+		s.blobs[r.URL.Query().Get("digest")] = body
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		s.serve(w, r, s.blobs, path[strings.LastIndex(path, "/")+1:], "application/octet-stream")
+	case r.Method == http.MethodPut && strings.Contains(path, "/manifests/"):
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		ref := path[strings.LastIndex(path, "/")+1:]
+		for _, key := range []string{digest, ref} {
+			s.manifests[key] = body
+			s.types[key] = r.Header.Get("Content-Type")
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/manifests/"):
+		ref := path[strings.LastIndex(path, "/")+1:]
+		s.serve(w, r, s.manifests, ref, s.types[ref])
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *stubRegistry) serve(w http.ResponseWriter, r *http.Request, store map[string][]byte, key, contentType string) {
+	body, ok := store[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.Header().Set("Docker-Content-Digest", "sha256:"+hex.EncodeToString(sum[:]))
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
+
+func (s *stubRegistry) manifest(ref string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, ok := s.manifests[ref]
+
+	return body, ok
+}
+
+func TestAggregatedModelExportedToRegistry(t *testing.T) {
+	t.Parallel()
+	registry := newStubRegistry()
+	regSrv := httptest.NewServer(registry)
+	defer regSrv.Close()
+	coordinator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer coordinator.Close()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	var mu sync.Mutex
+	handlers := map[string]mqtt.Handler{}
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+	}).Return(nil)
+	checkpoints := manager.WithCheckpointRepository(strings.TrimPrefix(regSrv.URL, "http://") + "/fl/model")
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", coordinator.URL, slog.Default(), nil, checkpoints, manager.WithRegistry(manager.RegistryConfig{PlainHTTP: true}))
+	ctx := context.Background()
+	require.NoError(t, svc.Subscribe(ctx))
+	roundNext := handlers["fl/rounds/next"]
+	require.NotNil(t, roundNext)
+
+	sub, err := svc.SubscribeEvents(ctx, events.Filter{ExperimentID: "exp-1"})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, svc.ConfigureExperiment(ctx, manager.ExperimentConfig{
+		ExperimentID:  "exp-1",
+		RoundID:       "round-1",
+		ModelRef:      "fl/models/global_model_v0",
+		Participants:  []string{"proplet-a"},
+		KOfN:          1,
+		TaskWasmImage: "oci://example/fl-client:latest",
+	}))
+	require.NoError(t, svc.PostFLUpdate(ctx, manager.FLUpdate{
+		RoundID:   "round-1",
+		PropletID: "proplet-a",
+		Update:    map[string]any{"w": []any{0.1}},
+	}))
+	require.NoError(t, roundNext("fl/rounds/next", map[string]any{
+		"round_id":          "round-1",
+		"new_model_version": 1.0,
+		"model_uri":         "fl/models/global_model_v1",
+		"model":             map[string]any{"w": []any{0.1}},
+	}))
+
+	var ref string
+	for len(sub.Events()) > 0 {
+		if evt := <-sub.Events(); evt.Action == "aggregate" {
+			ref = evt.Metadata["aggregated_model_ref"]
+		}
+	}
+	prefix := "oci://" + strings.TrimPrefix(regSrv.URL, "http://") + "/fl/model@sha256:"
+	require.True(t, strings.HasPrefix(ref, prefix), "unexpected ref %q", ref)
+
+	tagged, ok := registry.manifest("round-1-aggregated")
+	require.True(t, ok)
+	byDigest, ok := registry.manifest(ref[strings.LastIndex(ref, "@")+1:])
+	require.True(t, ok)
+	assert.Equal(t, tagged, byDigest)
+	assert.Contains(t, string(tagged), "application/vnd.propeller.fl.model.v1+json")
+}
+
+func TestRoundSeededWithStartingModel(t *testing.T) {
+	t.Parallel()
+	registry := httptest.NewServer(newStubRegistry())
+	defer registry.Close()
+
+	host := strings.TrimPrefix(registry.URL, "http://")
+	model := `{"w":[0.5,-0.25,1],"b":0.125}`
+	pusher := proxy.HTTPProxyConfig{PlainHTTP: true}
+	_, err := pusher.PushToReg(context.Background(), host+"/fl/pretrained", "v0", "application/vnd.propeller.fl.model.v1", "application/json", []byte(model))
+	require.NoError(t, err)
+
+	cases := []struct {
+		desc      string
+		modelURI  string
+		modelData string
+	}{
+		{desc: "oci model ref", modelURI: "oci://" + host + "/fl/pretrained:v0", modelData: model},
+		{desc: "registry model uri", modelURI: "fl/models/global_model_v0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+			require.NoError(t, err)
+			var mu sync.Mutex
+			handlers := map[string]mqtt.Handler{}
+			var startEnvs []map[string]any
+			pubsub := mqttmocks.NewMockPubSub(t)
+			pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				if args.String(1) != testStartTopic {
+					return
+				}
+				data, err := json.Marshal(args.Get(2))
+				require.NoError(t, err)
+				var payload map[string]any
+				require.NoError(t, json.Unmarshal(data, &payload))
+				mu.Lock()
+				defer mu.Unlock()
+				env, _ := payload["env"].(map[string]any)
+				startEnvs = append(startEnvs, env)
+			}).Return(nil).Maybe()
+			pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				mu.Lock()
+				defer mu.Unlock()
+				handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+			}).Return(nil).Maybe()
+			plainHTTP := manager.WithRegistry(manager.RegistryConfig{PlainHTTP: true})
+			svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil, plainHTTP)
+			ctx := context.Background()
+			require.NoError(t, svc.Subscribe(ctx))
+
+			participants := []any{"proplet-a", "proplet-b"}
+			handle := handlers["m/test-domain/c/test-channel/#"]
+			for _, id := range participants {
+				require.NoError(t, handle(testCreateTopic, map[string]any{"proplet_id": id}))
+				require.NoError(t, handle(testAliveTopic, map[string]any{"proplet_id": id}))
+			}
+			require.NoError(t, handlers[testRoundStartTopic](testRoundStartTopic, map[string]any{
+				"round_id":        "round-1",
+				"model_uri":       tc.modelURI,
+				"task_wasm_image": "oci://example/fl-client:latest",
+				"participants":    participants,
+			}))
+
+			roundTasks := func() []map[string]string {
+				page, err := svc.ListTasks(ctx, manager.PageMetadata{Limit: 100})
+				require.NoError(t, err)
+				var envs []map[string]string
+				for _, tk := range page.Tasks {
+					if tk.Env["ROUND_ID"] == "round-1" {
+						envs = append(envs, tk.Env)
+					}
+				}
+
+				return envs
+			}
+			require.Eventually(t, func() bool {
+				return len(roundTasks()) == len(participants)
+			}, time.Second, 5*time.Millisecond)
+			for _, env := range roundTasks() {
+				assert.Equal(t, tc.modelURI, env["MODEL_URI"])
+				assert.NotContains(t, env, "MODEL_DATA", "round tasks only store the model reference")
+			}
+			require.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+
+				return len(startEnvs) == len(participants)
+			}, time.Second, 5*time.Millisecond)
+			mu.Lock()
+			for _, env := range startEnvs {
+				assert.Equal(t, tc.modelURI, env["MODEL_URI"])
+				if tc.modelData == "" {
+					assert.NotContains(t, env, "MODEL_DATA")
+				} else {
+					assert.Equal(t, tc.modelData, env["MODEL_DATA"])
+				}
+			}
+			mu.Unlock()
+			require.NoError(t, svc.Shutdown(ctx))
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultsWithSinkReference(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "large-output"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	ref := map[string]any{
+		"uri":    "s3://results/results/" + created.ID + "/msg-1",
+		"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		"size":   float64(1 << 20),
+	}
+	require.NoError(t, rec.handler(testResultsTopic, map[string]any{
+		"task_id":    created.ID,
+		"proplet_id": "proplet-1",
+		"results":    "",
+		"result_ref": ref,
+	}))
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Completed, got.State)
+	assert.Equal(t, ref, got.Results)
+}
@@ -0,0 +1,206 @@
+package manager_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *startRecorder) startsOf(taskID string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var proplets []string
+	for i, id := range r.ids {
+		if id == taskID {
+			propletID, _ := r.payloads[i]["proplet_id"].(string)
+			proplets = append(proplets, propletID)
+		}
+	}
+
+	return proplets
+}
+
+func reportResult(t *testing.T, rec *startRecorder, taskID, propletID, errMsg string) {
+	t.Helper()
+	msg := map[string]any{
+		"task_id":    taskID,
+		"proplet_id": propletID,
+		"results":    "done",
+	}
+	if errMsg != "" {
+		msg["error"] = errMsg
+	}
+	require.NoError(t, rec.handler(testResultsTopic, msg))
+}
+
+func TestRetryTaskUntilSuccess(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	created, err := svc.CreateTask(ctx, task.Task{
+		Name:        "flaky",
+		RetryPolicy: &task.RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		require.Len(t, rec.startsOf(created.ID), attempt)
+		reportResult(t, rec, created.ID, "proplet-1", "image pull failed")
+
+		got, err := svc.GetTask(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, task.Pending, got.State)
+		assert.Equal(t, attempt, got.Attempts)
+
+		require.Eventually(t, func() bool {
+			return len(rec.startsOf(created.ID)) == attempt+1
+		}, time.Second, 5*time.Millisecond)
+	}
+
+	reportResult(t, rec, created.ID, "proplet-1", "")
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Completed, got.State)
+	assert.Equal(t, 3, got.Attempts)
+}
+
+func TestRetryTaskExhausted(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	created, err := svc.CreateTask(ctx, task.Task{
+		Name:        "broken",
+		RetryPolicy: &task.RetryPolicy{MaxAttempts: 2},
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	reportResult(t, rec, created.ID, "proplet-1", "trap")
+	require.Eventually(t, func() bool {
+		return len(rec.startsOf(created.ID)) == 2
+	}, time.Second, 5*time.Millisecond)
+	reportResult(t, rec, created.ID, "proplet-1", "trap")
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Failed, got.State)
+	assert.Equal(t, "trap", got.Error)
+	assert.Equal(t, 2, got.Attempts)
+	assert.False(t, got.FinishTime.IsZero())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, rec.startsOf(created.ID), 2)
+}
+
+func TestRetryFLTaskOnPinnedProplet(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	ctx := context.Background()
+	for _, id := range []string{"proplet-1", "proplet-2"} {
+		require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": id}))
+		require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": id}))
+	}
+
+	created, err := svc.CreateTask(ctx, task.Task{
+		Name:        "train",
+		Mode:        task.ModeTrain,
+		Env:         map[string]string{"ROUND_ID": "round-1"},
+		PropletID:   "proplet-2",
+		RetryPolicy: &task.RetryPolicy{MaxAttempts: 3},
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		reportResult(t, rec, created.ID, "proplet-2", "proplet restarted")
+		require.Eventually(t, func() bool {
+			return len(rec.startsOf(created.ID)) == attempt+1
+		}, time.Second, 5*time.Millisecond)
+	}
+
+	assert.Equal(t, []string{"proplet-2", "proplet-2", "proplet-2"}, rec.startsOf(created.ID))
+}
+
+func TestRetryPolicyValidation(t *testing.T) {
+	t.Parallel()
+	svc, _ := newRecordingService(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		desc string
+		task task.Task
+	}{
+		{
+			desc: "negative max attempts",
+			task: task.Task{Name: "t", RetryPolicy: &task.RetryPolicy{MaxAttempts: -1}},
+		},
+		{
+			desc: "negative backoff",
+			task: task.Task{Name: "t", RetryPolicy: &task.RetryPolicy{MaxAttempts: 2, Backoff: -time.Second}},
+		},
+		{
+			desc: "broadcast",
+			task: task.Task{Name: "t", Broadcast: true, RetryPolicy: &task.RetryPolicy{MaxAttempts: 2}},
+		},
+	}
+	for _, tc := range cases {
+		_, err := svc.CreateTask(ctx, tc.task)
+		assert.ErrorIs(t, err, pkgerrors.ErrInvalidValue, tc.desc)
+	}
+}
+
+func TestRetryResumesAfterRestart(t *testing.T) {
+	t.Parallel()
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	svc, rec := newServiceOn(t, repos, slog.Default())
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+	created, err := svc.CreateTask(ctx, task.Task{
+		Name:        "flaky",
+		RetryPolicy: &task.RetryPolicy{MaxAttempts: 2, Backoff: time.Hour},
+	})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	reportResult(t, rec, created.ID, "proplet-1", "image pull failed")
+
+	got, err := svc.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Pending, got.State)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), got.RetryAt, time.Minute)
+
+	// The manager goes down before the backoff passes; its successor finds
+	// the retry already due.
+	got.RetryAt = time.Now()
+	require.NoError(t, repos.Tasks.Update(ctx, got))
+	restarted, rec := newServiceOn(t, repos, slog.Default())
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, restarted.RecoverInterruptedTasks(ctx))
+
+	require.Eventually(t, func() bool {
+		return len(rec.startsOf(created.ID)) == 1
+	}, time.Second, 5*time.Millisecond)
+	got, err = restarted.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Running, got.State)
+	assert.Equal(t, 2, got.Attempts)
+	assert.True(t, got.RetryAt.IsZero())
+}
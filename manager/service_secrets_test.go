@@ -0,0 +1,98 @@
+package manager_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCapturingService(t *testing.T, opts ...manager.Option) (manager.Service, *startRecorder, *bytes.Buffer) {
+	t.Helper()
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	svc, rec := newLoggedService(t, logger, opts...)
+	require.NoError(t, rec.handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+	require.NoError(t, rec.handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+	return svc, rec, &logs
+}
+
+func TestSecretRefsFromEnvStore(t *testing.T) {
+	t.Setenv("PROPELLER_SECRET_DATASET_TOKEN", "s3cr3t-token")
+	svc, rec, logs := newCapturingService(t)
+
+	env := startedEnv(t, svc, rec, task.Task{
+		Name:       "train",
+		Env:        map[string]string{"DATASET_URL": "http://data"},
+		SecretRefs: map[string]string{"DATASET_ACCESS_TOKEN": "DATASET_TOKEN"},
+	})
+	assert.Equal(t, map[string]any{
+		"DATASET_URL":          "http://data",
+		"DATASET_ACCESS_TOKEN": "s3cr3t-token",
+	}, env)
+
+	page, err := svc.ListTasks(context.Background(), manager.PageMetadata{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.NotContains(t, page.Tasks[0].Env, "DATASET_ACCESS_TOKEN", "secrets are not stored on the task")
+
+	assert.Contains(t, logs.String(), "DATASET_ACCESS_TOKEN")
+	assert.NotContains(t, logs.String(), "s3cr3t-token")
+}
+
+func TestSecretRefsFromFileStore(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dataset-token"), []byte("file-token\n"), 0o600))
+	svc, rec, logs := newCapturingService(t, manager.WithSecretStore(manager.NewFileSecretStore(dir)))
+
+	env := startedEnv(t, svc, rec, task.Task{
+		Name:       "train",
+		SecretRefs: map[string]string{"DATASET_ACCESS_TOKEN": "dataset-token"},
+	})
+	assert.Equal(t, map[string]any{"DATASET_ACCESS_TOKEN": "file-token"}, env)
+	assert.NotContains(t, logs.String(), "file-token")
+}
+
+func TestMissingSecretFailsStart(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	svc, rec, _ := newCapturingService(t, manager.WithSecretStore(manager.NewFileSecretStore(dir)))
+	ctx := context.Background()
+
+	for _, key := range []string{"missing", "../escape"} {
+		created, err := svc.CreateTask(ctx, task.Task{
+			Name:       "train",
+			SecretRefs: map[string]string{"DATASET_ACCESS_TOKEN": key},
+		})
+		require.NoError(t, err)
+
+		err = svc.StartTask(ctx, created.ID)
+		require.ErrorIs(t, err, pkgerrors.ErrInvalidValue, "key %q", key)
+		require.ErrorIs(t, err, manager.ErrSecretNotFound, "key %q", key)
+		assert.Nil(t, rec.payload(created.ID))
+	}
+}
+
+func TestFileSecretStore(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("value\r\n"), 0o600))
+	store := manager.NewFileSecretStore(dir)
+
+	got, err := store.Secret(context.Background(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, "value", got)
+
+	_, err = store.Secret(context.Background(), "absent")
+	assert.ErrorIs(t, err, manager.ErrSecretNotFound)
+}
@@ -0,0 +1,117 @@
+package manager_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedUpdate(t *testing.T, key string) map[string]any {
+	t.Helper()
+	envelope := map[string]any{
+		"task_id":     "task-1",
+		"round_id":    "round-1",
+		"proplet_id":  "proplet-1",
+		"num_samples": 10.0,
+		"update_b64":  "WzAuMSwwLjJd",
+		"format":      "json-f64",
+		"metrics":     map[string]any{},
+	}
+	sig, err := fl.SignEnvelope([]byte(key), envelope)
+	require.NoError(t, err)
+	envelope[fl.SignatureKey] = sig
+
+	return envelope
+}
+
+func TestFLUpdateSignatureVerification(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc      string
+		sharedKey string
+		keys      string
+		results   func(t *testing.T) any
+		wantState task.State
+	}{
+		{
+			desc:      "valid signature with the shared key",
+			sharedKey: "shared",
+			results:   func(t *testing.T) any { return signedUpdate(t, "shared") },
+			wantState: task.Completed,
+		},
+		{
+			desc:      "valid signature with the proplet's key",
+			sharedKey: "shared",
+			keys:      "proplet-1=own",
+			results:   func(t *testing.T) any { return signedUpdate(t, "own") },
+			wantState: task.Completed,
+		},
+		{
+			desc:      "shared key rejected when the proplet has its own",
+			sharedKey: "shared",
+			keys:      "proplet-1=own",
+			results:   func(t *testing.T) any { return signedUpdate(t, "shared") },
+			wantState: task.Failed,
+		},
+		{
+			desc:      "tampered envelope",
+			sharedKey: "shared",
+			results: func(t *testing.T) any {
+				update := signedUpdate(t, "shared")
+				update["num_samples"] = 5000.0
+
+				return update
+			},
+			wantState: task.Failed,
+		},
+		{
+			desc:      "unsigned envelope",
+			sharedKey: "shared",
+			results: func(t *testing.T) any {
+				update := signedUpdate(t, "shared")
+				delete(update, fl.SignatureKey)
+
+				return update
+			},
+			wantState: task.Failed,
+		},
+		{
+			desc:      "unsigned envelope without verification",
+			results:   func(*testing.T) any { return map[string]any{"update_b64": "WzAuMSwwLjJd"} },
+			wantState: task.Completed,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			svc, rec := newRecordingService(t, manager.WithSigning(manager.SigningConfig{Key: tc.sharedKey, Keys: tc.keys}))
+			ctx := context.Background()
+
+			created, err := svc.CreateTask(ctx, task.Task{
+				Name: "fl-train",
+				Env:  map[string]string{"ROUND_ID": "round-1"},
+			})
+			require.NoError(t, err)
+			require.NoError(t, rec.handler(testResultsTopic, map[string]any{
+				"task_id":    created.ID,
+				"proplet_id": "proplet-1",
+				"results":    tc.results(t),
+			}))
+
+			got, err := svc.GetTask(ctx, created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantState, got.State)
+			if tc.wantState == task.Failed {
+				assert.Nil(t, got.Results)
+				assert.Contains(t, got.Error, "FL update rejected")
+			}
+		})
+	}
+}
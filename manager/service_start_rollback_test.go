@@ -0,0 +1,110 @@
+package manager_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testStopTopic = "m/test-domain/c/test-channel/control/manager/stop"
+
+var errBrokerDown = errors.New("broker down")
+
+// runningUpdateFails rejects the update that marks a task running.
+type runningUpdateFails struct {
+	storage.TaskRepository
+}
+
+func (r runningUpdateFails) Update(ctx context.Context, t task.Task) error {
+	if t.State == task.Running {
+		return storage.ErrUpdate
+	}
+
+	return r.TaskRepository.Update(ctx, t)
+}
+
+func TestStartTaskRollsBackPinAndTaskCount(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc       string
+		publishErr error
+		failUpdate bool
+		wantErr    error
+		wantStop   bool
+	}{
+		{
+			desc:       "publish fails",
+			publishErr: errBrokerDown,
+			wantErr:    errBrokerDown,
+		},
+		{
+			desc:       "marking the task running fails",
+			failUpdate: true,
+			wantErr:    storage.ErrUpdate,
+			wantStop:   true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+			require.NoError(t, err)
+			if tc.failUpdate {
+				repos.Tasks = runningUpdateFails{TaskRepository: repos.Tasks}
+			}
+
+			var handler mqtt.Handler
+			stops := 0
+			pubsub := mqttmocks.NewMockPubSub(t)
+			pubsub.On("Publish", mock.Anything, testStartTopic, mock.Anything).Return(tc.publishErr).Maybe()
+			pubsub.On("Publish", mock.Anything, testStopTopic, mock.Anything).Run(func(mock.Arguments) {
+				stops++
+			}).Return(nil).Maybe()
+			pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+			pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				if h, ok := args.Get(2).(mqtt.Handler); ok && handler == nil {
+					handler = h
+				}
+			}).Return(nil).Maybe()
+			svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil)
+			ctx := context.Background()
+			require.NoError(t, svc.Subscribe(ctx))
+			require.NoError(t, handler(testCreateTopic, map[string]any{"proplet_id": "proplet-1"}))
+			require.NoError(t, handler(testAliveTopic, map[string]any{"proplet_id": "proplet-1"}))
+
+			before, err := repos.Proplets.Get(ctx, "proplet-1")
+			require.NoError(t, err)
+			before.TaskCount = 3
+			require.NoError(t, repos.Proplets.Update(ctx, before))
+
+			created, err := svc.CreateTask(ctx, task.Task{Name: "task"})
+			require.NoError(t, err)
+			err = svc.StartTask(ctx, created.ID)
+			assert.ErrorIs(t, err, tc.wantErr)
+
+			after, err := svc.GetProplet(ctx, "proplet-1")
+			require.NoError(t, err)
+			assert.Equal(t, before.TaskCount, after.TaskCount)
+
+			_, err = repos.TaskProplets.Get(ctx, created.ID)
+			assert.Error(t, err, "task should no longer be pinned")
+
+			stored, err := svc.GetTask(ctx, created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, task.Failed, stored.State)
+			assert.Equal(t, tc.wantStop, stops > 0)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package manager_test
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/manager"
+	"github.com/absmach/propeller/pkg/mqtt"
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/scheduler"
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testRoundStartTopic = "m/test-domain/c/test-channel/fl/rounds/start"
+
+var (
+	spanRecorderOnce sync.Once
+	spanRecorder     *tracetest.SpanRecorder
+)
+
+// recordSpans installs a global span recorder. The manager's tracer binds to
+// the first global provider, so every test shares one recorder and filters
+// spans by attribute.
+func recordSpans() *tracetest.SpanRecorder {
+	spanRecorderOnce.Do(func() {
+		spanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+	})
+
+	return spanRecorder
+}
+
+func spansWith(rec *tracetest.SpanRecorder, attr attribute.KeyValue) []string {
+	var names []string
+	for _, s := range rec.Ended() {
+		for _, a := range s.Attributes() {
+			if a == attr {
+				names = append(names, s.Name())
+
+				break
+			}
+		}
+	}
+
+	return names
+}
+
+func TestRoundStartSpans(t *testing.T) {
+	t.Parallel()
+	spans := recordSpans()
+
+	repos, err := storage.NewRepositories(storage.Config{Type: "memory"})
+	require.NoError(t, err)
+
+	var (
+		mu       sync.Mutex
+		handlers = map[string]mqtt.Handler{}
+	)
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	pubsub.On("Subscribe", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		handlers[args.String(1)] = args.Get(2).(mqtt.Handler)
+	}).Return(nil).Maybe()
+
+	roundID, propletID := "round-"+uuid.NewString(), "proplet-"+uuid.NewString()
+	svc, _, _ := manager.NewService(repos, scheduler.NewRoundRobin(), pubsub, "test-domain", "test-channel", "", slog.Default(), nil)
+	ctx := context.Background()
+	require.NoError(t, svc.Subscribe(ctx))
+
+	handle := handlers["m/test-domain/c/test-channel/#"]
+	require.NoError(t, handle(testCreateTopic, map[string]any{"proplet_id": propletID}))
+	require.NoError(t, handle(testAliveTopic, map[string]any{"proplet_id": propletID}))
+
+	require.NoError(t, handlers[testRoundStartTopic](testRoundStartTopic, map[string]any{
+		"round_id":        roundID,
+		"model_uri":       "fl/models/global_model_v0",
+		"task_wasm_image": "oci://example/fl-client:latest",
+		"participants":    []any{propletID},
+	}))
+
+	roundAttr := attribute.String("round.id", roundID)
+	assert.Eventually(t, func() bool {
+		return len(spansWith(spans, roundAttr)) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{"fl-launch-participant", "fl-round-start"}, spansWith(spans, roundAttr))
+
+	var taskID string
+	for _, s := range spans.Ended() {
+		if s.Name() != "fl-launch-participant" {
+			continue
+		}
+		for _, a := range s.Attributes() {
+			if a.Key == "task.id" && slices.Contains(s.Attributes(), roundAttr) {
+				taskID = a.Value.AsString()
+			}
+		}
+	}
+	require.NotEmpty(t, taskID)
+
+	require.NoError(t, handle(testResultsTopic, map[string]any{"task_id": taskID, "results": "ok"}))
+	assert.ElementsMatch(t, []string{"fl-launch-participant", "handle-task-results"}, spansWith(spans, attribute.String("task.id", taskID)))
+	assert.Contains(t, spansWith(spans, attribute.String("task.state", task.Completed.String())), "handle-task-results")
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	t.Parallel()
+	spans := recordSpans()
+	svc, rec := newRecordingService(t)
+	registerProplet(t, rec, "proplet-1")
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "test-start")
+	created, err := svc.CreateTask(ctx, task.Task{Name: "traced"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+	parent.End()
+
+	payload := rec.payload(created.ID)
+	require.NotNil(t, payload)
+	traceparent, ok := payload["traceparent"].(string)
+	require.True(t, ok)
+	assert.Contains(t, traceparent, parent.SpanContext().TraceID().String())
+
+	require.NoError(t, rec.handler(testResultsTopic, map[string]any{
+		"task_id":     created.ID,
+		"results":     "ok",
+		"traceparent": traceparent,
+	}))
+
+	var found bool
+	for _, s := range spans.Ended() {
+		if s.Name() != "handle-task-results" || !slices.Contains(s.Attributes(), attribute.String("task.id", created.ID)) {
+			continue
+		}
+		found = true
+		assert.Equal(t, parent.SpanContext().TraceID(), s.SpanContext().TraceID())
+		assert.True(t, s.Parent().IsRemote())
+	}
+	assert.True(t, found)
+}
+
+func TestStartWithoutTraceOmitsTraceparent(t *testing.T) {
+	t.Parallel()
+	svc, rec := newRecordingService(t)
+	registerProplet(t, rec, "proplet-1")
+	ctx := context.Background()
+
+	created, err := svc.CreateTask(ctx, task.Task{Name: "untraced"})
+	require.NoError(t, err)
+	require.NoError(t, svc.StartTask(ctx, created.ID))
+
+	payload := rec.payload(created.ID)
+	require.NotNil(t, payload)
+	assert.NotContains(t, payload, "traceparent")
+}
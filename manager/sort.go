@@ -0,0 +1,65 @@
+package manager
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	pkgerrors "github.com/absmach/propeller/pkg/errors"
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/absmach/propeller/pkg/task"
+)
+
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// taskSortFields and propletSortFields are the fields listings may be
+// sorted by.
+var (
+	taskSortFields    = task.SortFields
+	propletSortFields = map[string]func(a, b proplet.Proplet) int{
+		"id":         func(a, b proplet.Proplet) int { return cmp.Compare(a.ID, b.ID) },
+		"name":       func(a, b proplet.Proplet) int { return cmp.Compare(a.Name, b.Name) },
+		"task_count": func(a, b proplet.Proplet) int { return cmp.Compare(a.TaskCount, b.TaskCount) },
+	}
+)
+
+// validateSort checks s against the sortable fields of a listing.
+func validateSort[T any](s Sort, fields map[string]func(a, b T) int) error {
+	switch s.Order {
+	case "", OrderAsc, OrderDesc:
+	default:
+		return fmt.Errorf("%w: invalid sort order %q", pkgerrors.ErrInvalidValue, s.Order)
+	}
+	if _, ok := fields[s.Field]; s.Field != "" && !ok {
+		return fmt.Errorf("%w: cannot sort by %q", pkgerrors.ErrInvalidValue, s.Field)
+	}
+
+	return nil
+}
+
+// sortBy stably sorts items by the validated s.
+func sortBy[T any](items []T, s Sort, fields map[string]func(a, b T) int) {
+	compare, ok := fields[s.Field]
+	if !ok {
+		return
+	}
+	slices.SortStableFunc(items, func(a, b T) int {
+		if s.Order == OrderDesc {
+			return compare(b, a)
+		}
+
+		return compare(a, b)
+	})
+}
+
+// paginate returns the page of items at offset and limit.
+func paginate[T any](items []T, offset, limit uint64) []T {
+	total := uint64(len(items))
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	return items[start:end]
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/absmach/propeller/pkg/dag"
 	"github.com/absmach/propeller/pkg/plugin"
+	"github.com/absmach/propeller/pkg/scheduler"
 	"github.com/absmach/propeller/pkg/storage"
 	"github.com/absmach/propeller/pkg/task"
 )
@@ -95,7 +96,7 @@ func (wc *WorkflowCoordinator) CheckAndStartReadyTasks(ctx context.Context, work
 		}
 	}
 
-	readyTasks := dag.GetReadyTasks(tasks, completed)
+	readyTasks := scheduler.GetReadyTasksByPriority(dag.GetReadyTasks(tasks, completed))
 
 	for i := range readyTasks {
 		t := &readyTasks[i]
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 
 	"github.com/absmach/magistrala"
@@ -15,20 +16,26 @@ const (
 	OffsetKey   = "offset"
 	LimitKey    = "limit"
 	MetadataKey = "metadata"
+	SortKey     = "sort"
+	OrderKey    = "order"
 	DefOffset   = 0
 	DefLimit    = 100
 
-	ContentType = "application/json"
+	ContentType     = "application/json"
+	CBORContentType = "application/cbor"
 
 	MaxLimitSize = 100
 )
 
-func EncodeResponse(_ context.Context, w http.ResponseWriter, response any) error {
+// EncodeResponse encodes response as CBOR when the request, as recorded by
+// PopulateAccept, prefers it and as JSON otherwise.
+func EncodeResponse(ctx context.Context, w http.ResponseWriter, response any) error {
+	contentType := negotiate(ctx)
 	if ar, ok := response.(magistrala.Response); ok {
 		for k, v := range ar.Headers() {
 			w.Header().Set(k, v)
 		}
-		w.Header().Set("Content-Type", ContentType)
+		w.Header().Set("Content-Type", contentType)
 		w.WriteHeader(ar.Code())
 
 		if ar.Empty() {
@@ -36,12 +43,24 @@ func EncodeResponse(_ context.Context, w http.ResponseWriter, response any) erro
 		}
 	}
 
+	if contentType == CBORContentType {
+		w.Header().Set("Content-Type", CBORContentType)
+
+		return cborEnc.NewEncoder(w).Encode(response)
+	}
+
 	return json.NewEncoder(w).Encode(response)
 }
 
 func EncodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", ContentType)
+	var maxBytesErr *http.MaxBytesError
+	var netErr net.Error
 	switch {
+	case errors.As(err, &maxBytesErr):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		w.WriteHeader(http.StatusRequestTimeout)
 	case errors.Is(err, apiutil.ErrValidation),
 		errors.Is(err, pkgerrors.ErrEmptyKey),
 		errors.Is(err, pkgerrors.ErrInvalidValue):
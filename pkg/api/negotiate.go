@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	apiutil "github.com/absmach/magistrala/api/http/util"
+	"github.com/fxamacker/cbor/v2"
+)
+
+var (
+	cborEnc, _ = cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+	// cborDec decodes maps into map[string]any, as encoding/json does, so
+	// CBOR bodies pass the same validation as JSON ones.
+	cborDec, _ = cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]any(nil))}.DecMode()
+)
+
+type acceptKey struct{}
+
+// PopulateAccept is a go-kit ServerBefore function recording the request's
+// Accept header for EncodeResponse.
+func PopulateAccept(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, acceptKey{}, r.Header.Get("Accept"))
+}
+
+// DecodeBody decodes the JSON or CBOR request body into v according to its
+// Content-Type.
+func DecodeBody(r *http.Request, v any) error {
+	contentType := r.Header.Get("Content-Type")
+	var err error
+	switch {
+	case strings.Contains(contentType, CBORContentType):
+		err = cborDec.NewDecoder(r.Body).Decode(v)
+	case strings.Contains(contentType, ContentType):
+		err = json.NewDecoder(r.Body).Decode(v)
+	default:
+		return errors.Join(apiutil.ErrValidation, apiutil.ErrUnsupportedContentType)
+	}
+	if err != nil {
+		return errors.Join(err, apiutil.ErrValidation)
+	}
+
+	return nil
+}
+
+// negotiate returns CBORContentType when the recorded Accept header ranks
+// CBOR above JSON and ContentType otherwise.
+func negotiate(ctx context.Context) string {
+	accept, _ := ctx.Value(acceptKey{}).(string)
+	if accept == "" {
+		return ContentType
+	}
+
+	var jsonQ, cborQ float64
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case ContentType:
+			jsonQ = max(jsonQ, q)
+		case CBORContentType:
+			cborQ = max(cborQ, q)
+		}
+	}
+	if cborQ > jsonQ {
+		return CBORContentType
+	}
+
+	return ContentType
+}
@@ -0,0 +1,116 @@
+// Package audit records an append-only trail of task and FL round state
+// transitions.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	EntityTask    = "task"
+	EntityFLRound = "fl_round"
+	EntityFLModel = "fl_model"
+)
+
+// Entry is a single audited state transition.
+type Entry struct {
+	Timestamp  time.Time         `json:"timestamp"`
+	Actor      string            `json:"actor"`
+	Action     string            `json:"action"`
+	EntityType string            `json:"entity_type"`
+	EntityID   string            `json:"entity_id"`
+	OldState   string            `json:"old_state,omitempty"`
+	NewState   string            `json:"new_state,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// AuditLog appends entries to an audit trail. Entries are never modified or
+// removed once written.
+type AuditLog interface {
+	Append(ctx context.Context, entry Entry) error
+}
+
+var (
+	_ AuditLog = (*FileLog)(nil)
+	_ AuditLog = (*MemoryLog)(nil)
+	_ AuditLog = nopLog{}
+)
+
+// FileLog writes entries as JSON lines to a file opened in append-only mode.
+type FileLog struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func NewFileLog(path string) (*FileLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	return &FileLog{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (l *FileLog) Append(_ context.Context, entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.enc.Encode(entry); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (l *FileLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
+
+// MemoryLog keeps entries in memory. It is intended for tests and
+// development setups.
+type MemoryLog struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{}
+}
+
+func (l *MemoryLog) Append(_ context.Context, entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, entry)
+
+	return nil
+}
+
+// Entries returns a copy of the recorded entries in append order.
+func (l *MemoryLog) Entries() []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return slices.Clone(l.entries)
+}
+
+type nopLog struct{}
+
+// NewNopLog returns an AuditLog that discards every entry.
+func NewNopLog() AuditLog {
+	return nopLog{}
+}
+
+func (nopLog) Append(context.Context, Entry) error {
+	return nil
+}
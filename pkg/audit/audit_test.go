@@ -0,0 +1,76 @@
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/pkg/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLogAppends(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	ctx := context.Background()
+
+	first := audit.Entry{
+		Timestamp:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Actor:      "alice",
+		Action:     "create",
+		EntityType: audit.EntityTask,
+		EntityID:   "task-1",
+		NewState:   "Pending",
+	}
+	second := audit.Entry{
+		Timestamp:  time.Date(2026, 1, 2, 3, 4, 6, 0, time.UTC),
+		Actor:      "proplet:p1",
+		Action:     "report-results",
+		EntityType: audit.EntityTask,
+		EntityID:   "task-1",
+		OldState:   "Running",
+		NewState:   "Completed",
+		Metadata:   map[string]string{"round_id": "r1"},
+	}
+
+	log, err := audit.NewFileLog(path)
+	require.NoError(t, err)
+	require.NoError(t, log.Append(ctx, first))
+	require.NoError(t, log.Close())
+
+	log, err = audit.NewFileLog(path)
+	require.NoError(t, err)
+	require.NoError(t, log.Append(ctx, second))
+	require.NoError(t, log.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var got []audit.Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e audit.Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		got = append(got, e)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []audit.Entry{first, second}, got)
+}
+
+func TestMemoryLogEntriesAreCopied(t *testing.T) {
+	t.Parallel()
+	log := audit.NewMemoryLog()
+	require.NoError(t, log.Append(context.Background(), audit.Entry{Action: "create", EntityID: "task-1"}))
+
+	entries := log.Entries()
+	require.Len(t, entries, 1)
+	entries[0].Action = "tampered"
+
+	assert.Equal(t, "create", log.Entries()[0].Action)
+}
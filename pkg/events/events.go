@@ -0,0 +1,136 @@
+// Package events fans out task and FL round lifecycle transitions to live
+// subscribers such as dashboards.
+package events
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultBuffer is the number of events a subscriber may lag behind before
+// it is dropped.
+const DefaultBuffer = 64
+
+// ErrSlowConsumer is reported by a subscription that was dropped because its
+// buffer filled up.
+var ErrSlowConsumer = errors.New("subscriber too slow, events dropped")
+
+// Event is a single lifecycle transition. Type is "<entity_type>.<action>".
+type Event struct {
+	Type       string            `json:"type"`
+	Timestamp  time.Time         `json:"timestamp"`
+	EntityType string            `json:"entity_type"`
+	EntityID   string            `json:"entity_id"`
+	Action     string            `json:"action"`
+	OldState   string            `json:"old_state,omitempty"`
+	NewState   string            `json:"new_state,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// Filter narrows a subscription to one task, job or FL experiment. Empty
+// fields match all.
+type Filter struct {
+	TaskID       string
+	JobID        string
+	ExperimentID string
+}
+
+func (f Filter) Match(e Event) bool {
+	if f.TaskID != "" && (e.EntityType != "task" || e.EntityID != f.TaskID) {
+		return false
+	}
+	if f.JobID != "" && e.Metadata["job_id"] != f.JobID {
+		return false
+	}
+	if f.ExperimentID != "" && e.Metadata["experiment_id"] != f.ExperimentID {
+		return false
+	}
+
+	return true
+}
+
+// Bus delivers published events to every matching subscriber without ever
+// blocking the publisher.
+type Bus struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Publish hands e to all matching subscribers. A subscriber whose buffer is
+// full is closed with ErrSlowConsumer rather than stalling the caller.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			sub.err = ErrSlowConsumer
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber buffering up to buffer events. A
+// non-positive buffer uses DefaultBuffer.
+func (b *Bus) Subscribe(filter Filter, buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	sub := &Subscription{
+		bus:    b,
+		filter: filter,
+		ch:     make(chan Event, buffer),
+	}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+func (b *Bus) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	close(sub.ch)
+}
+
+type Subscription struct {
+	bus    *Bus
+	filter Filter
+	ch     chan Event
+	err    error
+}
+
+// Events is closed once the subscription ends, either through Close or
+// because the subscriber fell behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Err reports why the subscription ended; nil if it was closed normally.
+func (s *Subscription) Err() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.bus.remove(s)
+}
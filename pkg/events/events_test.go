@@ -0,0 +1,134 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/absmach/propeller/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func taskEvent(taskID, jobID string) events.Event {
+	return events.Event{
+		Type:       "task.start",
+		EntityType: "task",
+		EntityID:   taskID,
+		Action:     "start",
+		Metadata:   map[string]string{"job_id": jobID},
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc   string
+		filter events.Filter
+		event  events.Event
+		match  bool
+	}{
+		{
+			desc:  "empty filter matches everything",
+			event: taskEvent("t1", "j1"),
+			match: true,
+		},
+		{
+			desc:   "task id matches",
+			filter: events.Filter{TaskID: "t1"},
+			event:  taskEvent("t1", ""),
+			match:  true,
+		},
+		{
+			desc:   "other task is filtered out",
+			filter: events.Filter{TaskID: "t1"},
+			event:  taskEvent("t2", ""),
+			match:  false,
+		},
+		{
+			desc:   "task id does not match rounds with the same id",
+			filter: events.Filter{TaskID: "r1"},
+			event:  events.Event{EntityType: "fl_round", EntityID: "r1"},
+			match:  false,
+		},
+		{
+			desc:   "job id matches metadata",
+			filter: events.Filter{JobID: "j1"},
+			event:  taskEvent("t1", "j1"),
+			match:  true,
+		},
+		{
+			desc:   "experiment id matches metadata",
+			filter: events.Filter{ExperimentID: "exp-1"},
+			event:  events.Event{EntityType: "fl_round", Metadata: map[string]string{"experiment_id": "exp-1"}},
+			match:  true,
+		},
+		{
+			desc:   "other experiment is filtered out",
+			filter: events.Filter{ExperimentID: "exp-1"},
+			event:  events.Event{EntityType: "fl_round", Metadata: map[string]string{"experiment_id": "exp-2"}},
+			match:  false,
+		},
+		{
+			desc:   "task and job must both match",
+			filter: events.Filter{TaskID: "t1", JobID: "j2"},
+			event:  taskEvent("t1", "j1"),
+			match:  false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.match, tc.filter.Match(tc.event))
+		})
+	}
+}
+
+func TestBusDeliversMatchingEvents(t *testing.T) {
+	t.Parallel()
+	bus := events.NewBus()
+	sub := bus.Subscribe(events.Filter{TaskID: "t1"}, 4)
+	defer sub.Close()
+
+	bus.Publish(taskEvent("t2", ""))
+	bus.Publish(taskEvent("t1", ""))
+
+	got := <-sub.Events()
+	assert.Equal(t, "t1", got.EntityID)
+	assert.Empty(t, sub.Events())
+}
+
+func TestBusDropsSlowConsumer(t *testing.T) {
+	t.Parallel()
+	bus := events.NewBus()
+	slow := bus.Subscribe(events.Filter{}, 1)
+	fast := bus.Subscribe(events.Filter{}, 4)
+	defer fast.Close()
+
+	bus.Publish(taskEvent("t1", ""))
+	bus.Publish(taskEvent("t2", ""))
+
+	_, ok := <-slow.Events()
+	require.True(t, ok)
+	_, ok = <-slow.Events()
+	assert.False(t, ok)
+	require.ErrorIs(t, slow.Err(), events.ErrSlowConsumer)
+
+	assert.Len(t, fast.Events(), 2)
+	require.NoError(t, fast.Err())
+	slow.Close()
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	t.Parallel()
+	bus := events.NewBus()
+	sub := bus.Subscribe(events.Filter{}, 0)
+	sub.Close()
+	sub.Close()
+
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	require.NoError(t, sub.Err())
+
+	bus.Publish(taskEvent("t1", ""))
+}
@@ -1,21 +1,45 @@
 package fl
 
+import "fmt"
+
 type FedAvgAggregator struct{}
 
 func NewFedAvgAggregator() Aggregator {
 	return &FedAvgAggregator{}
 }
 
-func initializeAggregatedWeights(updates []Update) []float64 {
-	if len(updates) == 0 || updates[0].Update == nil {
-		return nil
-	}
-
-	if w, ok := updates[0].Update["w"].([]any); ok {
-		return make([]float64, len(w))
+// initializeAggregatedWeights returns a zeroed weight vector shaped like the
+// updates' "w", or nil when they carry none. Every update with a payload
+// must have a "w" of the same length and a numeric "b"; a client sending a
+// shorter "w" would otherwise add to only some coordinates while its samples
+// still count towards all of them. Such rounds fail with ErrShapeMismatch.
+func initializeAggregatedWeights(updates []Update) ([]float64, error) {
+	var (
+		w    []float64
+		seen bool
+	)
+	for i, update := range updates {
+		if update.Update == nil {
+			continue
+		}
+		raw, hasW := update.Update["w"].([]any)
+		switch {
+		case !seen:
+			seen = true
+			if hasW {
+				w = make([]float64, len(raw))
+			}
+		case hasW != (w != nil):
+			return nil, fmt.Errorf("%w: update %d from proplet %q disagrees on the presence of w", ErrShapeMismatch, i, update.PropletID)
+		case hasW && len(raw) != len(w):
+			return nil, fmt.Errorf("%w: update %d from proplet %q has %d weights, expected %d", ErrShapeMismatch, i, update.PropletID, len(raw), len(w))
+		}
+		if _, ok := update.Update["b"].(float64); !ok {
+			return nil, fmt.Errorf("%w: update %d from proplet %q has no numeric b", ErrShapeMismatch, i, update.PropletID)
+		}
 	}
 
-	return nil
+	return w, nil
 }
 
 func validateAndProcessUpdate(update Update, totalSamples int64) (weight float64, newTotalSamples int64, err error) {
@@ -37,67 +61,15 @@ func validateAndProcessUpdate(update Update, totalSamples int64) (weight float64
 	return weight, newTotalSamples, nil
 }
 
-func aggregateWeights(aggregatedW []float64, update Update, weight float64) {
-	if w, ok := update.Update["w"].([]any); ok {
-		for i, v := range w {
-			if f, ok := v.(float64); ok {
-				if i < len(aggregatedW) {
-					aggregatedW[i] += f * weight
-				}
-			}
-		}
-	}
-}
-
-func aggregateBias(aggregatedB *float64, update Update, weight float64) {
-	if b, ok := update.Update["b"].(float64); ok {
-		*aggregatedB += b * weight
-	}
-}
-
-func normalizeWeights(aggregatedW []float64, aggregatedB *float64, totalSamples int64) {
-	if totalSamples > 0 {
-		weightNorm := float64(totalSamples)
-		for i := range aggregatedW {
-			aggregatedW[i] /= weightNorm
-		}
-		*aggregatedB /= weightNorm
-	}
-}
-
 func (f *FedAvgAggregator) Aggregate(updates []Update) (Model, error) {
-	if len(updates) == 0 {
-		return Model{}, ErrNoUpdates
-	}
-
-	aggregatedW := initializeAggregatedWeights(updates)
-	var aggregatedB float64
 	// Use int64 for totalSamples to prevent integer overflow on 32-bit systems
 	// when aggregating updates from many clients with large sample counts.
 	// update.NumSamples is int (32-bit on 32-bit systems), so we cast to int64.
-	var totalSamples int64
-
-	for _, update := range updates {
-		weight, newTotalSamples, err := validateAndProcessUpdate(update, totalSamples)
-		if err != nil {
-			return Model{}, err
-		}
-
-		if weight == 0 {
-			continue
-		}
-
-		totalSamples = newTotalSamples
-
-		if aggregatedW != nil {
-			aggregateWeights(aggregatedW, update, weight)
-		}
-
-		aggregateBias(&aggregatedB, update, weight)
+	aggregatedW, aggregatedB, totalSamples, err := aggregateUpdates(updates, AlgorithmFedAvg)
+	if err != nil {
+		return Model{}, err
 	}
 
-	normalizeWeights(aggregatedW, &aggregatedB, totalSamples)
-
 	return Model{
 		Data: map[string]any{
 			"w": aggregatedW,
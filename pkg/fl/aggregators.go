@@ -0,0 +1,181 @@
+package fl
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// ParamFormat names the aggregation parameter carrying the payload format of
+// the aggregated envelope. Built-in algorithms default it to the format of the
+// first update.
+const ParamFormat = "format"
+
+// AggregateFunc combines update envelopes into one. totalSamples is the
+// combined sample count of the updates, already summed by the registry when
+// the caller passed zero.
+type AggregateFunc func(updates []UpdateEnvelope, params map[string]any, totalSamples uint64) (UpdateEnvelope, error)
+
+// AggregatorRegistry maps algorithm names to their aggregation functions so
+// custom algorithms can be plugged in without touching the built-ins.
+type AggregatorRegistry struct {
+	mu    sync.RWMutex
+	funcs map[string]AggregateFunc
+}
+
+// DefaultAggregators holds the built-in algorithms and is what Aggregate and
+// NewAggregator resolve names through.
+var DefaultAggregators = NewAggregatorRegistry()
+
+func init() {
+	for name, reduce := range map[string]vectorReducer{
+		AlgorithmFedAvg:      fedAvg,
+		AlgorithmMedian:      coordinateWiseReducer(median),
+		AlgorithmTrimmedMean: coordinateWiseReducer(trimmedMean),
+		AlgorithmKrum:        krumReducer,
+	} {
+		if err := DefaultAggregators.Register(name, vectorAggregate(reduce)); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func NewAggregatorRegistry() *AggregatorRegistry {
+	return &AggregatorRegistry{funcs: make(map[string]AggregateFunc)}
+}
+
+// RegisterAggregator adds a custom algorithm to DefaultAggregators.
+func RegisterAggregator(name string, fn AggregateFunc) error {
+	return DefaultAggregators.Register(name, fn)
+}
+
+// Register adds fn under name. Names are unique; registering a taken name
+// fails with ErrAlgorithmExists.
+func (r *AggregatorRegistry) Register(name string, fn AggregateFunc) error {
+	if name == "" || fn == nil {
+		return fmt.Errorf("%w: name and function are required", ErrUnknownAlgorithm)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.funcs[name]; ok {
+		return fmt.Errorf("%w: %q", ErrAlgorithmExists, name)
+	}
+	r.funcs[name] = fn
+
+	return nil
+}
+
+// Lookup returns the function registered under name. An empty name selects
+// FedAvg.
+func (r *AggregatorRegistry) Lookup(name string) (AggregateFunc, error) {
+	if name == "" {
+		name = AlgorithmFedAvg
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fn, ok := r.funcs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, name)
+	}
+
+	return fn, nil
+}
+
+// Names lists the registered algorithms in sorted order.
+func (r *AggregatorRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.funcs))
+	for name := range r.funcs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Aggregate resolves algorithm and runs it over updates. A zero totalSamples
+// is summed from the envelopes first.
+func (r *AggregatorRegistry) Aggregate(algorithm string, updates []UpdateEnvelope, params map[string]any, totalSamples uint64) (UpdateEnvelope, error) {
+	if len(updates) == 0 {
+		return UpdateEnvelope{}, ErrNoUpdates
+	}
+
+	fn, err := r.Lookup(algorithm)
+	if err != nil {
+		return UpdateEnvelope{}, err
+	}
+
+	if totalSamples == 0 {
+		for _, u := range updates {
+			if totalSamples > math.MaxUint64-u.NumSamples {
+				return UpdateEnvelope{}, ErrOverflow
+			}
+			totalSamples += u.NumSamples
+		}
+	}
+
+	return fn(updates, params, totalSamples)
+}
+
+// vectorReducer merges decoded json-f64 vectors of equal length.
+type vectorReducer func(vectors [][]float64, updates []UpdateEnvelope, totalSamples uint64) []float64
+
+// vectorAggregate adapts a vectorReducer to an AggregateFunc. Payloads that
+// cannot be decoded as vectors of the same length fall back to a concat
+// aggregate framing the raw data in order.
+func vectorAggregate(reduce vectorReducer) AggregateFunc {
+	return func(updates []UpdateEnvelope, params map[string]any, totalSamples uint64) (UpdateEnvelope, error) {
+		format, _ := params[ParamFormat].(string)
+		if format == "" {
+			format = updates[0].Format
+		}
+
+		out := UpdateEnvelope{
+			Format:     format,
+			NumSamples: totalSamples,
+		}
+		for _, u := range updates {
+			out.NumSources += u.sources()
+		}
+
+		vectors, ok := decodeVectors(updates, format)
+		if !ok {
+			return concatUpdates(out, updates)
+		}
+
+		data, err := encodeVector(reduce(vectors, updates, totalSamples), format)
+		if err != nil {
+			return UpdateEnvelope{}, fmt.Errorf("failed to encode aggregated update: %w", err)
+		}
+		out.Data = data
+
+		return out, nil
+	}
+}
+
+func fedAvg(vectors [][]float64, updates []UpdateEnvelope, totalSamples uint64) []float64 {
+	weights := make([]float64, len(updates))
+	for i, u := range updates {
+		weights[i] = float64(u.NumSamples)
+	}
+
+	return weightedMean(vectors, weights, float64(totalSamples))
+}
+
+func coordinateWiseReducer(reduce func([]float64) float64) vectorReducer {
+	return func(vectors [][]float64, _ []UpdateEnvelope, _ uint64) []float64 {
+		return coordinateWise(vectors, reduce)
+	}
+}
+
+func krumReducer(vectors [][]float64, _ []UpdateEnvelope, _ uint64) []float64 {
+	return slices.Clone(krum(vectors))
+}
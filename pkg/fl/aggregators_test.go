@@ -0,0 +1,115 @@
+package fl_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// maxAggregate keeps the coordinate-wise maximum, scaled by the "scale"
+// parameter when present.
+func maxAggregate(updates []fl.UpdateEnvelope, params map[string]any, totalSamples uint64) (fl.UpdateEnvelope, error) {
+	scale := 1.0
+	if s, ok := params["scale"].(float64); ok {
+		scale = s
+	}
+
+	var result []float64
+	for _, u := range updates {
+		var vec []float64
+		if err := json.Unmarshal(u.Data, &vec); err != nil {
+			return fl.UpdateEnvelope{}, err
+		}
+		if result == nil {
+			result = make([]float64, len(vec))
+			copy(result, vec)
+
+			continue
+		}
+		for i, v := range vec {
+			result[i] = max(result[i], v)
+		}
+	}
+	for i := range result {
+		result[i] *= scale
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fl.UpdateEnvelope{}, err
+	}
+
+	return fl.UpdateEnvelope{Format: fl.FormatJSONF64, NumSamples: totalSamples, Data: data}, nil
+}
+
+func TestAggregatorRegistryCustomAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	registry := fl.NewAggregatorRegistry()
+	require.NoError(t, registry.Register("max", maxAggregate))
+
+	updates := []fl.UpdateEnvelope{
+		envelope(t, 30, 1, 7),
+		envelope(t, 10, 4, 2),
+	}
+	out, err := registry.Aggregate("max", updates, map[string]any{"scale": 2.0}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{8, 14}, decode(t, out))
+	assert.Equal(t, uint64(40), out.NumSamples)
+
+	_, err = registry.Aggregate(fl.AlgorithmFedAvg, updates, nil, 0)
+	assert.ErrorIs(t, err, fl.ErrUnknownAlgorithm, "a new registry has no built-ins")
+}
+
+func TestAggregatorRegistryErrors(t *testing.T) {
+	t.Parallel()
+
+	registry := fl.NewAggregatorRegistry()
+	require.NoError(t, registry.Register("max", maxAggregate))
+
+	err := registry.Register("max", maxAggregate)
+	assert.ErrorIs(t, err, fl.ErrAlgorithmExists)
+
+	err = registry.Register("", maxAggregate)
+	assert.ErrorIs(t, err, fl.ErrUnknownAlgorithm)
+
+	_, err = registry.Lookup("fedprox")
+	assert.ErrorIs(t, err, fl.ErrUnknownAlgorithm)
+
+	_, err = registry.Aggregate("max", nil, nil, 0)
+	assert.ErrorIs(t, err, fl.ErrNoUpdates)
+
+	assert.Equal(t, []string{"max"}, registry.Names())
+}
+
+func TestDefaultAggregatorsCustomAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	assert.Subset(t, fl.DefaultAggregators.Names(), []string{
+		fl.AlgorithmFedAvg, fl.AlgorithmMedian, fl.AlgorithmTrimmedMean, fl.AlgorithmKrum,
+	})
+
+	const name = "test-default-max"
+	require.NoError(t, fl.RegisterAggregator(name, maxAggregate))
+	assert.ErrorIs(t, fl.RegisterAggregator(fl.AlgorithmFedAvg, maxAggregate), fl.ErrAlgorithmExists)
+
+	out, err := fl.Aggregate([]fl.UpdateEnvelope{
+		envelope(t, 1, 1, 5),
+		envelope(t, 1, 3, 2),
+	}, name, fl.FormatJSONF64, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3, 5}, decode(t, out))
+
+	agg, err := fl.NewAggregator(name)
+	require.NoError(t, err)
+	model, err := agg.Aggregate([]fl.Update{
+		update(1, 0.5, 1.0, 2.0),
+		update(1, 1.5, 3.0, 0.0),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3, 2}, model.Data["w"])
+	assert.Equal(t, 1.5, model.Data["b"])
+}
@@ -0,0 +1,217 @@
+package fl
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+)
+
+const (
+	AlgorithmFedAvg      = "fedavg"
+	AlgorithmMedian      = "median"
+	AlgorithmTrimmedMean = "trimmed-mean"
+	AlgorithmKrum        = "krum"
+
+	// trimFraction is the share of values dropped from each end of every
+	// coordinate by the trimmed-mean aggregator.
+	trimFraction = 0.1
+)
+
+// NewAggregator returns the aggregator for the named algorithm, which must be
+// registered in DefaultAggregators. An empty name selects FedAvg.
+func NewAggregator(algorithm string) (Aggregator, error) {
+	if algorithm == "" || algorithm == AlgorithmFedAvg {
+		return NewFedAvgAggregator(), nil
+	}
+	if _, err := DefaultAggregators.Lookup(algorithm); err != nil {
+		return nil, err
+	}
+
+	return &robustAggregator{algorithm: algorithm}, nil
+}
+
+// robustAggregator runs every algorithm other than FedAvg, which covers the
+// Byzantine-tolerant built-ins and registered custom algorithms. Each update
+// is flattened to its "w" vector followed by the "b" bias and aggregated as
+// a json-f64 envelope.
+type robustAggregator struct {
+	algorithm string
+}
+
+func (r *robustAggregator) Aggregate(updates []Update) (Model, error) {
+	w, b, totalSamples, err := aggregateUpdates(updates, r.algorithm)
+	if err != nil {
+		return Model{}, err
+	}
+
+	return Model{
+		Data: map[string]any{
+			"w": w,
+			"b": b,
+		},
+		Metadata: map[string]any{
+			"total_samples": totalSamples,
+			"num_updates":   len(updates),
+			"algorithm":     r.algorithm,
+		},
+	}, nil
+}
+
+// aggregateUpdates flattens the updates into json-f64 envelopes and runs them
+// through Aggregate, so the map-based aggregators share its arithmetic.
+// Updates without a payload are skipped. FedAvg over no payloads yields a
+// zero model; the robust algorithms report ErrNoUpdates.
+func aggregateUpdates(updates []Update, algorithm string) (w []float64, b float64, totalSamples int64, err error) {
+	if len(updates) == 0 {
+		return nil, 0, 0, ErrNoUpdates
+	}
+
+	w, err = initializeAggregatedWeights(updates)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	dim := len(w)
+
+	envelopes := make([]UpdateEnvelope, 0, len(updates))
+	for _, update := range updates {
+		_, totalSamples, err = validateAndProcessUpdate(update, totalSamples)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if update.Update == nil {
+			continue
+		}
+		data, err := json.Marshal(flattenUpdate(update, dim))
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to encode update: %w", err)
+		}
+		envelopes = append(envelopes, UpdateEnvelope{
+			PropletID:  update.PropletID,
+			Format:     FormatJSONF64,
+			NumSamples: uint64(update.NumSamples),
+			Data:       data,
+		})
+	}
+	if len(envelopes) == 0 {
+		if algorithm == "" || algorithm == AlgorithmFedAvg {
+			return w, 0, totalSamples, nil
+		}
+
+		return nil, 0, 0, ErrNoUpdates
+	}
+
+	out, err := Aggregate(envelopes, algorithm, FormatJSONF64, uint64(totalSamples))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	var vec []float64
+	if err := json.Unmarshal(out.Data, &vec); err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to decode aggregated update: %w", err)
+	}
+	if w != nil {
+		w = vec[:dim]
+	}
+
+	return w, vec[dim], totalSamples, nil
+}
+
+// flattenUpdate returns the update's weights, truncated or zero-padded to dim,
+// with the bias appended. Non-numeric entries count as zero.
+func flattenUpdate(update Update, dim int) []float64 {
+	vec := make([]float64, dim+1)
+	if raw, ok := update.Update["w"].([]any); ok {
+		for i, v := range raw[:min(len(raw), dim)] {
+			if f, ok := v.(float64); ok {
+				vec[i] = f
+			}
+		}
+	}
+	if b, ok := update.Update["b"].(float64); ok {
+		vec[dim] = b
+	}
+
+	return vec
+}
+
+func coordinateWise(vectors [][]float64, reduce func([]float64) float64) []float64 {
+	dim := len(vectors[0])
+	result := make([]float64, dim)
+	column := make([]float64, 0, len(vectors))
+	for i := range dim {
+		column = column[:0]
+		for _, v := range vectors {
+			if i < len(v) {
+				column = append(column, v[i])
+			}
+		}
+		result[i] = reduce(column)
+	}
+
+	return result
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func trimmedMean(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	k := int(math.Floor(float64(len(sorted)) * trimFraction))
+	kept := sorted[k : len(sorted)-k]
+
+	var sum float64
+	for _, v := range kept {
+		sum += v
+	}
+
+	return sum / float64(len(kept))
+}
+
+// krum returns the vector with the smallest summed squared distance to its
+// n-f-2 nearest neighbours, tolerating up to f = (n-3)/2 Byzantine clients.
+func krum(vectors [][]float64) []float64 {
+	n := len(vectors)
+	f := max((n-3)/2, 0)
+	neighbours := max(n-f-2, 0)
+
+	best, bestScore := 0, math.Inf(1)
+	dists := make([]float64, 0, n-1)
+	for i := range vectors {
+		dists = dists[:0]
+		for j := range vectors {
+			if i != j {
+				dists = append(dists, squaredDistance(vectors[i], vectors[j]))
+			}
+		}
+		slices.Sort(dists)
+
+		var score float64
+		for _, d := range dists[:min(neighbours, len(dists))] {
+			score += d
+		}
+		if score < bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	return vectors[best]
+}
+
+func squaredDistance(a, b []float64) float64 {
+	var sum float64
+	for i := range min(len(a), len(b)) {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+
+	return sum
+}
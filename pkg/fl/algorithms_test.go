@@ -0,0 +1,186 @@
+package fl_test
+
+import (
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func update(numSamples int, b float64, w ...any) fl.Update {
+	return fl.Update{
+		NumSamples: numSamples,
+		Update:     map[string]any{"w": w, "b": b},
+	}
+}
+
+func TestNewAggregatorAlgorithms(t *testing.T) {
+	t.Parallel()
+
+	updates := []fl.Update{
+		update(10, 1, 1.0, 10.0),
+		update(10, 2, 2.0, 20.0),
+		update(10, 3, 3.0, 30.0),
+		update(10, 4, 4.0, 40.0),
+		update(10, 100, 100.0, 1000.0),
+	}
+
+	cases := []struct {
+		desc      string
+		algorithm string
+		w         []float64
+		b         float64
+	}{
+		{
+			desc:      "fedavg",
+			algorithm: fl.AlgorithmFedAvg,
+			w:         []float64{22, 220},
+			b:         22,
+		},
+		{
+			desc:      "median",
+			algorithm: fl.AlgorithmMedian,
+			w:         []float64{3, 30},
+			b:         3,
+		},
+		{
+			desc:      "trimmed mean keeps all of five updates",
+			algorithm: fl.AlgorithmTrimmedMean,
+			w:         []float64{22, 220},
+			b:         22,
+		},
+		{
+			desc:      "krum picks an update from the honest cluster",
+			algorithm: fl.AlgorithmKrum,
+			w:         []float64{2, 20},
+			b:         2,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			agg, err := fl.NewAggregator(tc.algorithm)
+			require.NoError(t, err)
+
+			model, err := agg.Aggregate(updates)
+			require.NoError(t, err)
+			assert.InDeltaSlice(t, tc.w, model.Data["w"], 1e-9)
+			assert.InDelta(t, tc.b, model.Data["b"], 1e-9)
+			assert.Equal(t, int64(50), model.Metadata["total_samples"])
+			assert.Equal(t, len(updates), model.Metadata["num_updates"])
+		})
+	}
+}
+
+func TestTrimmedMeanWithTenUpdates(t *testing.T) {
+	t.Parallel()
+
+	var updates []fl.Update
+	for i := 1; i <= 9; i++ {
+		updates = append(updates, update(1, float64(i), float64(i)))
+	}
+	updates = append(updates, update(1, 1000, 1000.0))
+
+	agg, err := fl.NewAggregator(fl.AlgorithmTrimmedMean)
+	require.NoError(t, err)
+
+	model, err := agg.Aggregate(updates)
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{5.5}, model.Data["w"], 1e-9)
+	assert.InDelta(t, 5.5, model.Data["b"], 1e-9)
+}
+
+func TestDefaultAlgorithmIsFedAvg(t *testing.T) {
+	t.Parallel()
+
+	updates := []fl.Update{
+		update(30, 0.5, 1.0, 2.0),
+		update(10, 1.5, 3.0, 4.0),
+	}
+
+	want, err := fl.NewFedAvgAggregator().Aggregate(updates)
+	require.NoError(t, err)
+
+	for _, algorithm := range []string{"", fl.AlgorithmFedAvg} {
+		agg, err := fl.NewAggregator(algorithm)
+		require.NoError(t, err)
+
+		got, err := agg.Aggregate(updates)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "algorithm %q", algorithm)
+	}
+}
+
+func TestNewAggregatorUnknown(t *testing.T) {
+	t.Parallel()
+
+	_, err := fl.NewAggregator("fedprox")
+	assert.ErrorIs(t, err, fl.ErrUnknownAlgorithm)
+}
+
+func TestRobustAggregatorNoUpdates(t *testing.T) {
+	t.Parallel()
+
+	agg, err := fl.NewAggregator(fl.AlgorithmMedian)
+	require.NoError(t, err)
+
+	_, err = agg.Aggregate(nil)
+	assert.ErrorIs(t, err, fl.ErrNoUpdates)
+}
+
+func TestAggregateRejectsMismatchedShapes(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc    string
+		updates []fl.Update
+	}{
+		{
+			desc:    "shorter w",
+			updates: []fl.Update{update(10, 1, 1.0, 2.0, 3.0), update(10, 1, 1.0, 2.0)},
+		},
+		{
+			desc:    "longer w",
+			updates: []fl.Update{update(10, 1, 1.0), update(10, 1, 1.0, 2.0)},
+		},
+		{
+			desc: "missing w",
+			updates: []fl.Update{
+				update(10, 1, 1.0, 2.0),
+				{NumSamples: 10, Update: map[string]any{"b": 1.0}},
+			},
+		},
+		{
+			desc: "missing b",
+			updates: []fl.Update{
+				update(10, 1, 1.0, 2.0),
+				{PropletID: "proplet-2", NumSamples: 10, Update: map[string]any{"w": []any{1.0, 2.0}}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		for _, algorithm := range []string{fl.AlgorithmFedAvg, fl.AlgorithmMedian} {
+			agg, err := fl.NewAggregator(algorithm)
+			require.NoError(t, err)
+
+			_, err = agg.Aggregate(tc.updates)
+			assert.ErrorIs(t, err, fl.ErrShapeMismatch, "%s with %s", tc.desc, algorithm)
+		}
+	}
+}
+
+func TestAggregateSkipsEmptyUpdatesWhenCheckingShapes(t *testing.T) {
+	t.Parallel()
+
+	model, err := fl.NewFedAvgAggregator().Aggregate([]fl.Update{
+		{NumSamples: 0},
+		update(10, 1, 1.0, 2.0),
+		update(10, 3, 3.0, 4.0),
+	})
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{2, 3}, model.Data["w"], 1e-9)
+	assert.InDelta(t, 2.0, model.Data["b"], 1e-9)
+}
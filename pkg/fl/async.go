@@ -0,0 +1,83 @@
+package fl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultAsyncAlpha is the mixing weight FedAsync gives a fresh update.
+	DefaultAsyncAlpha = 0.5
+
+	// asyncStalenessExponent is a in the polynomial staleness discount
+	// (staleness+1)^-a from the FedAsync paper.
+	asyncStalenessExponent = 0.5
+)
+
+// StalenessAlpha discounts alpha by how many model versions the update's base
+// model lags behind the current global model.
+func StalenessAlpha(alpha float64, staleness int) float64 {
+	if staleness < 0 {
+		staleness = 0
+	}
+
+	return alpha * math.Pow(float64(staleness+1), -asyncStalenessExponent)
+}
+
+// AsyncBlend returns (1-alpha)*global + alpha*client over the "w" vector and
+// "b" bias. A nil global is seeded with the client update as is.
+func AsyncBlend(global map[string]any, client Update, alpha float64) (map[string]any, error) {
+	if len(client.Update) == 0 {
+		return nil, ErrNoUpdates
+	}
+	cw, cb := modelVector(client.Update)
+	if global == nil {
+		return map[string]any{"w": cw, "b": cb}, nil
+	}
+
+	gw, gb := modelVector(global)
+	if len(gw) != len(cw) {
+		return nil, fmt.Errorf("%w: global has %d weights, update has %d", ErrShapeMismatch, len(gw), len(cw))
+	}
+
+	w := make([]float64, len(gw))
+	for i := range gw {
+		w[i] = (1-alpha)*gw[i] + alpha*cw[i]
+	}
+
+	return map[string]any{"w": w, "b": (1-alpha)*gb + alpha*cb}, nil
+}
+
+// ModelVersion parses the trailing "_v<N>" of a model reference such as
+// "fl/models/global_model_v3".
+func ModelVersion(ref string) (int, bool) {
+	i := strings.LastIndex(ref, "_v")
+	if i < 0 {
+		return 0, false
+	}
+	v, err := strconv.Atoi(ref[i+2:])
+	if err != nil || v < 0 {
+		return 0, false
+	}
+
+	return v, true
+}
+
+func modelVector(data map[string]any) (w []float64, b float64) {
+	switch raw := data["w"].(type) {
+	case []float64:
+		w = append(w, raw...)
+	case []any:
+		w = make([]float64, len(raw))
+		for i, v := range raw {
+			if f, ok := v.(float64); ok {
+				w[i] = f
+			}
+		}
+	}
+	b, _ = data["b"].(float64)
+
+	return w, b
+}
@@ -0,0 +1,64 @@
+package fl_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStalenessAlpha(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 0.5, fl.StalenessAlpha(0.5, 0), 1e-12)
+	assert.InDelta(t, 0.5/math.Sqrt(2), fl.StalenessAlpha(0.5, 1), 1e-12)
+	assert.InDelta(t, 0.25, fl.StalenessAlpha(0.5, 3), 1e-12)
+	assert.InDelta(t, 0.5, fl.StalenessAlpha(0.5, -2), 1e-12)
+	assert.Less(t, fl.StalenessAlpha(0.5, 10), fl.StalenessAlpha(0.5, 9))
+}
+
+func TestAsyncBlend(t *testing.T) {
+	t.Parallel()
+
+	seeded, err := fl.AsyncBlend(nil, update(1, 1, 2.0, 4.0), 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{2, 4}, seeded["w"])
+	assert.InDelta(t, 1.0, seeded["b"], 1e-12)
+
+	blended, err := fl.AsyncBlend(seeded, update(1, 3, 6.0, 0.0), 0.25)
+	require.NoError(t, err)
+	w, ok := blended["w"].([]float64)
+	require.True(t, ok)
+	assert.InDeltaSlice(t, []float64{0.75*2 + 0.25*6, 0.75 * 4}, w, 1e-12)
+	assert.InDelta(t, 0.75*1+0.25*3, blended["b"], 1e-12)
+
+	_, err = fl.AsyncBlend(seeded, update(1, 0, 1.0), 0.5)
+	require.ErrorIs(t, err, fl.ErrShapeMismatch)
+
+	_, err = fl.AsyncBlend(seeded, fl.Update{}, 0.5)
+	require.ErrorIs(t, err, fl.ErrNoUpdates)
+}
+
+func TestModelVersion(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		ref     string
+		version int
+		ok      bool
+	}{
+		{ref: "fl/models/global_model_v0", version: 0, ok: true},
+		{ref: "fl/models/global_model_v12", version: 12, ok: true},
+		{ref: "fl/models/latest", ok: false},
+		{ref: "fl/models/global_model_vx", ok: false},
+		{ref: "", ok: false},
+	}
+
+	for _, tc := range cases {
+		version, ok := fl.ModelVersion(tc.ref)
+		assert.Equal(t, tc.ok, ok, tc.ref)
+		assert.Equal(t, tc.version, version, tc.ref)
+	}
+}
@@ -0,0 +1,92 @@
+package fl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// FormatConcat marks an aggregate the built-in algorithms could not merge
+// numerically. Its payload frames each update's raw data with a 4-byte
+// big-endian length, in update order, and Parts records who sent each frame,
+// so SplitConcat recovers the updates even when they hold arbitrary bytes.
+const FormatConcat = "concat"
+
+// concatFrameHeader is the size of the length prefix of each concat frame.
+const concatFrameHeader = 4
+
+// ConcatPart describes one update framed into a concat aggregate.
+type ConcatPart struct {
+	PropletID  string `json:"proplet_id,omitempty"`
+	Format     string `json:"format"`
+	NumSamples uint64 `json:"num_samples"`
+	Length     uint64 `json:"length"`
+}
+
+// concatUpdates frames the updates' data into out, which becomes a concat
+// envelope.
+func concatUpdates(out UpdateEnvelope, updates []UpdateEnvelope) (UpdateEnvelope, error) {
+	size := 0
+	for i, u := range updates {
+		if uint64(len(u.Data)) > math.MaxUint32 {
+			return UpdateEnvelope{}, fmt.Errorf("%w: update %d from proplet %q is too large to frame", ErrInvalidEncoding, i, u.PropletID)
+		}
+		size += concatFrameHeader + len(u.Data)
+	}
+
+	out.Format = FormatConcat
+	out.Data = make([]byte, 0, size)
+	out.Parts = make([]ConcatPart, len(updates))
+	for i, u := range updates {
+		out.Data = binary.BigEndian.AppendUint32(out.Data, uint32(len(u.Data)))
+		out.Data = append(out.Data, u.Data...)
+		out.Parts[i] = ConcatPart{
+			PropletID:  u.PropletID,
+			Format:     u.Format,
+			NumSamples: u.NumSamples,
+			Length:     uint64(len(u.Data)),
+		}
+	}
+
+	return out, nil
+}
+
+// SplitConcat splits a concat aggregate back into the updates it was built
+// from. Frames that run past the payload, trailing bytes, or Parts that
+// disagree with the frames fail with ErrInvalidEncoding.
+func SplitConcat(env UpdateEnvelope) ([]UpdateEnvelope, error) {
+	if env.Format != FormatConcat {
+		return nil, fmt.Errorf("%w: %q is not a concat aggregate", ErrInvalidEncoding, env.Format)
+	}
+
+	var updates []UpdateEnvelope
+	for data := env.Data; len(data) > 0; {
+		if len(data) < concatFrameHeader {
+			return nil, fmt.Errorf("%w: truncated frame header", ErrInvalidEncoding)
+		}
+		n := binary.BigEndian.Uint32(data)
+		data = data[concatFrameHeader:]
+		if uint64(n) > uint64(len(data)) {
+			return nil, fmt.Errorf("%w: frame of %d bytes overruns the payload", ErrInvalidEncoding, n)
+		}
+		updates = append(updates, UpdateEnvelope{Data: data[:n:n]})
+		data = data[n:]
+	}
+
+	if env.Parts == nil {
+		return updates, nil
+	}
+	if len(env.Parts) != len(updates) {
+		return nil, fmt.Errorf("%w: %d parts recorded for %d frames", ErrInvalidEncoding, len(env.Parts), len(updates))
+	}
+	for i, part := range env.Parts {
+		if part.Length != uint64(len(updates[i].Data)) {
+			return nil, fmt.Errorf("%w: part %d records %d bytes, frame has %d", ErrInvalidEncoding, i, part.Length, len(updates[i].Data))
+		}
+		updates[i].PropletID = part.PropletID
+		updates[i].Format = part.Format
+		updates[i].NumSamples = part.NumSamples
+	}
+
+	return updates, nil
+}
@@ -0,0 +1,69 @@
+package fl_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcatSplitsBinaryPayloads(t *testing.T) {
+	t.Parallel()
+
+	// Payloads full of bytes a delimiter or a length prefix could be
+	// mistaken for: NULs, newlines, commas and a fake frame header.
+	fake := binary.BigEndian.AppendUint32(nil, 1)
+	updates := []fl.UpdateEnvelope{
+		{PropletID: "proplet-a", Format: "safetensors", NumSamples: 4, Data: []byte{0, 0, '\n', ',', 0xff}},
+		{PropletID: "proplet-b", Format: "safetensors", NumSamples: 0, Data: nil},
+		{PropletID: "proplet-c", Format: "safetensors", NumSamples: 6, Data: append(fake, 0, '\n')},
+	}
+
+	out, err := fl.Aggregate(updates, fl.AlgorithmFedAvg, "safetensors", 0)
+	require.NoError(t, err)
+	assert.Equal(t, fl.FormatConcat, out.Format)
+	assert.Equal(t, uint64(10), out.NumSamples)
+	assert.Equal(t, []fl.ConcatPart{
+		{PropletID: "proplet-a", Format: "safetensors", NumSamples: 4, Length: 5},
+		{PropletID: "proplet-b", Format: "safetensors", NumSamples: 0, Length: 0},
+		{PropletID: "proplet-c", Format: "safetensors", NumSamples: 6, Length: 6},
+	}, out.Parts)
+
+	parts, err := fl.SplitConcat(out)
+	require.NoError(t, err)
+	require.Len(t, parts, len(updates))
+	for i, part := range parts {
+		assert.Equal(t, updates[i].PropletID, part.PropletID)
+		assert.Equal(t, updates[i].NumSamples, part.NumSamples)
+		assert.Equal(t, len(updates[i].Data), len(part.Data))
+		assert.Equal(t, string(updates[i].Data), string(part.Data))
+	}
+
+	out.Parts = nil
+	bare, err := fl.SplitConcat(out)
+	require.NoError(t, err)
+	require.Len(t, bare, len(updates))
+	assert.Equal(t, updates[2].Data, bare[2].Data)
+}
+
+func TestSplitConcatRejectsMalformedFrames(t *testing.T) {
+	t.Parallel()
+
+	frame := func(data ...byte) []byte {
+		return append(binary.BigEndian.AppendUint32(nil, uint32(len(data))), data...)
+	}
+
+	cases := map[string]fl.UpdateEnvelope{
+		"wrong format":     {Format: fl.FormatJSONF64, Data: frame(1)},
+		"truncated header": {Format: fl.FormatConcat, Data: append(frame(1), 0, 0)},
+		"overrun":          {Format: fl.FormatConcat, Data: frame(1, 2)[:5]},
+		"part count":       {Format: fl.FormatConcat, Data: frame(1), Parts: []fl.ConcatPart{{Length: 1}, {Length: 0}}},
+		"part length":      {Format: fl.FormatConcat, Data: frame(1, 2), Parts: []fl.ConcatPart{{Length: 1}}},
+	}
+	for desc, env := range cases {
+		_, err := fl.SplitConcat(env)
+		assert.ErrorIs(t, err, fl.ErrInvalidEncoding, desc)
+	}
+}
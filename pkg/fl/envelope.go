@@ -0,0 +1,163 @@
+package fl
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// FormatJSONF64 marks an update payload encoded as a JSON array of
+	// float64.
+	FormatJSONF64 = "json-f64"
+	// FormatF32Delta is the format proplets declare for an update when the
+	// task does not set FL_FORMAT.
+	FormatF32Delta = "f32-delta"
+	// FormatInt8Quant marks an update payload quantized to int8 with a
+	// per-vector scale, as written by QuantizeInt8. It is a quarter of the
+	// size of f32 deltas, for constrained devices.
+	FormatInt8Quant = "int8-quant"
+	// FormatSparseF64 marks an update payload carrying only some coordinates
+	// as a JSON SparseUpdate, e.g. a client's top-k changes.
+	FormatSparseF64 = "sparse-f64"
+)
+
+// UpdateFormats lists the update payload formats FL tasks may declare.
+var UpdateFormats = []string{FormatJSONF64, FormatF32Delta, FormatInt8Quant, FormatSparseF64}
+
+// NormalizeUpdateFormat returns the canonical spelling of an update format.
+// Case, surrounding spaces and underscores in place of hyphens are
+// forgiven, so "JSON_F64" is json-f64. An empty format stays empty, leaving
+// the proplet's default. Anything outside UpdateFormats fails with
+// ErrUnknownFormat.
+func NormalizeUpdateFormat(format string) (string, error) {
+	canonical := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(format)), "_", "-")
+	if canonical == "" {
+		return "", nil
+	}
+	if !slices.Contains(UpdateFormats, canonical) {
+		return "", fmt.Errorf("%w: %q (allowed: %s)", ErrUnknownFormat, format, strings.Join(UpdateFormats, ", "))
+	}
+
+	return canonical, nil
+}
+
+// UpdateEnvelope is a serialized model update as it travels between proplets
+// and the aggregation point. An envelope produced by PreAggregate stands for
+// NumSources leaf clients and NumSamples is their combined sample count; a
+// leaf client's envelope leaves NumSources zero. Parts is only set on a
+// concat aggregate.
+type UpdateEnvelope struct {
+	PropletID  string       `json:"proplet_id,omitempty"`
+	Format     string       `json:"format"`
+	NumSamples uint64       `json:"num_samples"`
+	NumSources uint64       `json:"num_sources,omitempty"`
+	Data       []byte       `json:"data"`
+	Parts      []ConcatPart `json:"parts,omitempty"`
+}
+
+func (u UpdateEnvelope) sources() uint64 {
+	return max(u.NumSources, 1)
+}
+
+// PreAggregate lets an edge aggregator fold its local clients into a single
+// FedAvg envelope. Because the result carries the clients' combined sample
+// count, aggregating pre-aggregated envelopes with FedAvg gives the same model
+// as a flat FedAvg over every leaf client. The local updates must be json-f64
+// vectors of equal length.
+func PreAggregate(local []UpdateEnvelope) (UpdateEnvelope, error) {
+	if len(local) == 0 {
+		return UpdateEnvelope{}, ErrNoUpdates
+	}
+	if _, ok := decodeVectors(local, FormatJSONF64); !ok {
+		return UpdateEnvelope{}, fmt.Errorf("%w: pre-aggregation needs %s updates of equal length", ErrShapeMismatch, FormatJSONF64)
+	}
+
+	return Aggregate(local, AlgorithmFedAvg, FormatJSONF64, 0)
+}
+
+// Aggregate combines update envelopes with the algorithm registered under
+// the given name in DefaultAggregators. For the built-ins, json-f64 payloads
+// of equal length are merged numerically; FedAvg weights each update by its
+// sample count over totalSamples, which is summed from the envelopes when
+// zero, so a pre-aggregated envelope counts as all of its clients. The robust
+// algorithms treat every envelope as one vote. int8-quant payloads are
+// dequantized and aggregated as floats; the result is requantized only when
+// format is int8-quant. sparse-f64 payloads are scattered into dense vectors
+// first, so coordinates a client left out count as zero for its weight. Any
+// other format, or payloads that cannot be decoded as vectors of the same
+// length, fall back to a concat aggregate that SplitConcat takes apart.
+func Aggregate(updates []UpdateEnvelope, algorithm, format string, totalSamples uint64) (UpdateEnvelope, error) {
+	return DefaultAggregators.Aggregate(algorithm, updates, map[string]any{ParamFormat: format}, totalSamples)
+}
+
+func decodeVectors(updates []UpdateEnvelope, format string) ([][]float64, bool) {
+	switch format {
+	case FormatJSONF64, FormatInt8Quant, FormatSparseF64:
+	default:
+		return nil, false
+	}
+
+	vectors := make([][]float64, len(updates))
+	for i, u := range updates {
+		v, err := decodeVector(u)
+		if err != nil {
+			return nil, false
+		}
+		if i > 0 && len(v) != len(vectors[0]) {
+			return nil, false
+		}
+		vectors[i] = v
+	}
+
+	return vectors, true
+}
+
+func decodeVector(u UpdateEnvelope) ([]float64, error) {
+	switch u.Format {
+	case FormatJSONF64:
+		var v []float64
+		if err := json.Unmarshal(u.Data, &v); err != nil {
+			return nil, err
+		}
+
+		return v, nil
+	case FormatInt8Quant:
+		return DequantizeInt8(u.Data)
+	case FormatSparseF64:
+		return decodeSparse(u.Data)
+	default:
+		return nil, fmt.Errorf("%w: %q is not a vector format", ErrInvalidEncoding, u.Format)
+	}
+}
+
+func encodeVector(v []float64, format string) ([]byte, error) {
+	switch format {
+	case FormatInt8Quant:
+		return QuantizeInt8(v)
+	case FormatSparseF64:
+		return json.Marshal(TopK(v, 0))
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// weightedMean sums each vector scaled by its weight and divides by total.
+// A zero total leaves the sum unnormalized, which is all zeros when every
+// weight is zero.
+func weightedMean(vectors [][]float64, weights []float64, total float64) []float64 {
+	result := make([]float64, len(vectors[0]))
+	for i, v := range vectors {
+		for j, f := range v {
+			result[j] += f * weights[i]
+		}
+	}
+	if total > 0 {
+		for j := range result {
+			result[j] /= total
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,252 @@
+package fl_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envelope(t *testing.T, numSamples uint64, vec ...float64) fl.UpdateEnvelope {
+	t.Helper()
+	data, err := json.Marshal(vec)
+	require.NoError(t, err)
+
+	return fl.UpdateEnvelope{Format: fl.FormatJSONF64, NumSamples: numSamples, Data: data}
+}
+
+func decode(t *testing.T, env fl.UpdateEnvelope) []float64 {
+	t.Helper()
+	var vec []float64
+	require.NoError(t, json.Unmarshal(env.Data, &vec))
+
+	return vec
+}
+
+func TestAggregateJSONF64(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc         string
+		algorithm    string
+		totalSamples uint64
+		want         []float64
+		wantSamples  uint64
+	}{
+		{
+			desc:        "weighted mean derives total samples",
+			algorithm:   fl.AlgorithmFedAvg,
+			want:        []float64{1.75, 3.25},
+			wantSamples: 40,
+		},
+		{
+			desc:        "empty algorithm is fedavg",
+			want:        []float64{1.75, 3.25},
+			wantSamples: 40,
+		},
+		{
+			desc:         "explicit total samples normalizes the sum",
+			algorithm:    fl.AlgorithmFedAvg,
+			totalSamples: 70,
+			want:         []float64{1, 65.0 / 35},
+			wantSamples:  70,
+		},
+		{
+			desc:        "median",
+			algorithm:   fl.AlgorithmMedian,
+			want:        []float64{2.5, 4.5},
+			wantSamples: 40,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			updates := []fl.UpdateEnvelope{
+				envelope(t, 30, 1, 2),
+				envelope(t, 10, 4, 7),
+			}
+
+			out, err := fl.Aggregate(updates, tc.algorithm, fl.FormatJSONF64, tc.totalSamples)
+			require.NoError(t, err)
+			assert.Equal(t, fl.FormatJSONF64, out.Format)
+			assert.Equal(t, tc.wantSamples, out.NumSamples)
+			assert.InDeltaSlice(t, tc.want, decode(t, out), 1e-9)
+		})
+	}
+}
+
+func TestAggregateConcatFallback(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc    string
+		format  string
+		updates []fl.UpdateEnvelope
+		want    []string
+	}{
+		{
+			desc:   "opaque format",
+			format: "safetensors",
+			updates: []fl.UpdateEnvelope{
+				{Format: "safetensors", NumSamples: 1, Data: []byte("ab")},
+				{Format: "safetensors", NumSamples: 2, Data: []byte("cd")},
+			},
+			want: []string{"ab", "cd"},
+		},
+		{
+			desc:   "mismatched lengths",
+			format: fl.FormatJSONF64,
+			updates: []fl.UpdateEnvelope{
+				{Format: fl.FormatJSONF64, NumSamples: 1, Data: []byte("[1,2]")},
+				{Format: fl.FormatJSONF64, NumSamples: 2, Data: []byte("[3]")},
+			},
+			want: []string{"[1,2]", "[3]"},
+		},
+		{
+			desc:   "undecodable payload",
+			format: fl.FormatJSONF64,
+			updates: []fl.UpdateEnvelope{
+				{Format: fl.FormatJSONF64, NumSamples: 1, Data: []byte("[1]")},
+				{Format: fl.FormatJSONF64, NumSamples: 2, Data: []byte("oops")},
+			},
+			want: []string{"[1]", "oops"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			out, err := fl.Aggregate(tc.updates, fl.AlgorithmFedAvg, tc.format, 0)
+			require.NoError(t, err)
+			assert.Equal(t, fl.FormatConcat, out.Format)
+			assert.Equal(t, uint64(3), out.NumSamples)
+
+			parts, err := fl.SplitConcat(out)
+			require.NoError(t, err)
+			require.Len(t, parts, len(tc.want))
+			for i, part := range parts {
+				assert.Equal(t, tc.want[i], string(part.Data))
+				assert.Equal(t, tc.updates[i].Format, part.Format)
+				assert.Equal(t, tc.updates[i].NumSamples, part.NumSamples)
+			}
+		})
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := fl.Aggregate(nil, fl.AlgorithmFedAvg, fl.FormatJSONF64, 0)
+	assert.ErrorIs(t, err, fl.ErrNoUpdates)
+
+	_, err = fl.Aggregate([]fl.UpdateEnvelope{envelope(t, 1, 1)}, "fedprox", fl.FormatJSONF64, 0)
+	assert.ErrorIs(t, err, fl.ErrUnknownAlgorithm)
+
+	_, err = fl.Aggregate([]fl.UpdateEnvelope{
+		envelope(t, ^uint64(0), 1),
+		envelope(t, 1, 1),
+	}, fl.AlgorithmFedAvg, fl.FormatJSONF64, 0)
+	assert.ErrorIs(t, err, fl.ErrOverflow)
+}
+
+func TestAggregatorsMatchAggregate(t *testing.T) {
+	t.Parallel()
+
+	updates := []fl.Update{
+		update(30, 0.5, 1.0, 2.0),
+		update(10, 1.5, 3.0, 4.0),
+		update(20, -1, 0.5, 8.0),
+	}
+	envelopes := []fl.UpdateEnvelope{
+		envelope(t, 30, 1, 2, 0.5),
+		envelope(t, 10, 3, 4, 1.5),
+		envelope(t, 20, 0.5, 8, -1),
+	}
+
+	for _, algorithm := range []string{fl.AlgorithmFedAvg, fl.AlgorithmMedian, fl.AlgorithmTrimmedMean, fl.AlgorithmKrum} {
+		agg, err := fl.NewAggregator(algorithm)
+		require.NoError(t, err)
+		model, err := agg.Aggregate(updates)
+		require.NoError(t, err)
+
+		out, err := fl.Aggregate(envelopes, algorithm, fl.FormatJSONF64, 0)
+		require.NoError(t, err)
+		vec := decode(t, out)
+
+		assert.Equal(t, vec[:2], model.Data["w"], "algorithm %s", algorithm)
+		assert.Equal(t, vec[2], model.Data["b"], "algorithm %s", algorithm)
+		assert.Equal(t, int64(out.NumSamples), model.Metadata["total_samples"], "algorithm %s", algorithm)
+	}
+}
+
+func TestTwoTierAggregationMatchesFlat(t *testing.T) {
+	t.Parallel()
+
+	edgeA := []fl.UpdateEnvelope{
+		envelope(t, 30, 1, 2, 0.5),
+		envelope(t, 10, 3, 4, 1.5),
+	}
+	edgeB := []fl.UpdateEnvelope{
+		envelope(t, 20, 0.5, 8, -1),
+		envelope(t, 5, -2, 1, 4),
+		envelope(t, 35, 6, -3, 2),
+	}
+
+	preA, err := fl.PreAggregate(edgeA)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(40), preA.NumSamples)
+	assert.Equal(t, uint64(2), preA.NumSources)
+
+	preB, err := fl.PreAggregate(edgeB)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(60), preB.NumSamples)
+	assert.Equal(t, uint64(3), preB.NumSources)
+
+	twoTier, err := fl.Aggregate([]fl.UpdateEnvelope{preA, preB}, fl.AlgorithmFedAvg, fl.FormatJSONF64, 0)
+	require.NoError(t, err)
+	flat, err := fl.Aggregate(append(append([]fl.UpdateEnvelope{}, edgeA...), edgeB...), fl.AlgorithmFedAvg, fl.FormatJSONF64, 0)
+	require.NoError(t, err)
+
+	assert.InDeltaSlice(t, decode(t, flat), decode(t, twoTier), 1e-12)
+	assert.Equal(t, flat.NumSamples, twoTier.NumSamples)
+	assert.Equal(t, uint64(5), twoTier.NumSources)
+	assert.Equal(t, flat.NumSources, twoTier.NumSources)
+
+	mixed, err := fl.Aggregate([]fl.UpdateEnvelope{preA, edgeB[0], edgeB[1], edgeB[2]}, fl.AlgorithmFedAvg, fl.FormatJSONF64, 0)
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, decode(t, flat), decode(t, mixed), 1e-12)
+}
+
+func TestPreAggregateErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := fl.PreAggregate(nil)
+	require.ErrorIs(t, err, fl.ErrNoUpdates)
+
+	_, err = fl.PreAggregate([]fl.UpdateEnvelope{envelope(t, 1, 1, 2), envelope(t, 1, 1)})
+	require.ErrorIs(t, err, fl.ErrShapeMismatch)
+
+	_, err = fl.PreAggregate([]fl.UpdateEnvelope{{Format: "raw", NumSamples: 1, Data: []byte("x")}})
+	require.ErrorIs(t, err, fl.ErrShapeMismatch)
+}
+
+func TestPreAggregatedEnvelopeJSON(t *testing.T) {
+	t.Parallel()
+
+	pre, err := fl.PreAggregate([]fl.UpdateEnvelope{envelope(t, 3, 1), envelope(t, 1, 5)})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(pre)
+	require.NoError(t, err)
+	var decoded fl.UpdateEnvelope
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, uint64(2), decoded.NumSources)
+	assert.Equal(t, uint64(4), decoded.NumSamples)
+
+	leaf, err := json.Marshal(envelope(t, 1, 1))
+	require.NoError(t, err)
+	assert.NotContains(t, string(leaf), "num_sources")
+}
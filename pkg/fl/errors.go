@@ -5,4 +5,17 @@ import "errors"
 var (
 	ErrNoUpdates = errors.New("no updates provided for aggregation")
 	ErrOverflow  = errors.New("sample count overflow during aggregation")
+
+	ErrUnknownAlgorithm = errors.New("unknown aggregation algorithm")
+	ErrAlgorithmExists  = errors.New("aggregation algorithm already registered")
+	ErrInvalidQuorum    = errors.New("invalid quorum policy")
+	ErrInvalidSpec      = errors.New("invalid FL spec")
+	ErrShapeMismatch    = errors.New("model and update shapes differ")
+	ErrInvalidEncoding  = errors.New("malformed update payload")
+	ErrUnknownFormat    = errors.New("unknown update format")
+	ErrModelNotFound    = errors.New("model version not found")
+	ErrModelExists      = errors.New("model version already exists")
+
+	ErrMissingSignature = errors.New("update envelope is not signed")
+	ErrInvalidSignature = errors.New("update envelope signature does not match")
 )
@@ -0,0 +1,166 @@
+package fl
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+const (
+	// DefaultQualityMetric is the client metric quality weighting reads when
+	// none is configured.
+	DefaultQualityMetric = "loss"
+
+	defaultQualityQuantile  = 0.5
+	defaultQualityThreshold = 2.0
+)
+
+// QualityWeighting configures a FedAvg that down-weights clients reporting an
+// anomalously high loss, which often points at bad local data. The reference
+// is the Quantile of the losses reported in the round, the median when left
+// zero. An update whose loss exceeds Threshold times the reference, 2 when
+// left zero, has its sample weight scaled by threshold*reference/loss, so the
+// further it strays the less it counts. Updates that report no loss keep
+// their sample weight.
+type QualityWeighting struct {
+	Metric    string  `json:"metric,omitempty"`
+	Quantile  float64 `json:"quantile,omitempty"`
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+func (q QualityWeighting) withDefaults() QualityWeighting {
+	if q.Metric == "" {
+		q.Metric = DefaultQualityMetric
+	}
+	if q.Quantile == 0 {
+		q.Quantile = defaultQualityQuantile
+	}
+	if q.Threshold == 0 {
+		q.Threshold = defaultQualityThreshold
+	}
+
+	return q
+}
+
+// Validate rejects quantiles outside [0, 1] and thresholds below 1, which
+// would down-weight clients reporting the reference loss itself.
+func (q QualityWeighting) Validate() error {
+	q = q.withDefaults()
+	if math.IsNaN(q.Quantile) || q.Quantile < 0 || q.Quantile > 1 {
+		return fmt.Errorf("%w: quality quantile %v must be within [0, 1]", ErrInvalidSpec, q.Quantile)
+	}
+	if math.IsNaN(q.Threshold) || q.Threshold < 1 {
+		return fmt.Errorf("%w: quality threshold %v must be at least 1", ErrInvalidSpec, q.Threshold)
+	}
+
+	return nil
+}
+
+type qualityAggregator struct {
+	cfg QualityWeighting
+}
+
+// NewQualityWeightedAggregator returns a FedAvg aggregator that combines
+// sample weighting with the loss-based weighting cfg describes. The model's
+// metadata records each update's adjusted weight, in update order, and the
+// clients that were down-weighted.
+func NewQualityWeightedAggregator(cfg QualityWeighting) (Aggregator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &qualityAggregator{cfg: cfg.withDefaults()}, nil
+}
+
+func (q *qualityAggregator) Aggregate(updates []Update) (Model, error) {
+	if len(updates) == 0 {
+		return Model{}, ErrNoUpdates
+	}
+	w, err := initializeAggregatedWeights(updates)
+	if err != nil {
+		return Model{}, err
+	}
+	dim := len(w)
+
+	losses := make([]float64, len(updates))
+	var reported []float64
+	for i, update := range updates {
+		losses[i] = math.NaN()
+		if update.Update == nil {
+			continue
+		}
+		if loss, ok := update.Metrics[q.cfg.Metric].(float64); ok && loss >= 0 && !math.IsInf(loss, 0) {
+			losses[i] = loss
+			reported = append(reported, loss)
+		}
+	}
+	reference := quantile(reported, q.cfg.Quantile)
+	limit := q.cfg.Threshold * reference
+
+	var (
+		totalSamples int64
+		totalWeight  float64
+		vectors      [][]float64
+		weights      []float64
+		downWeighted []string
+	)
+	adjusted := make([]float64, len(updates))
+	for i, update := range updates {
+		weight, total, err := validateAndProcessUpdate(update, totalSamples)
+		if err != nil {
+			return Model{}, err
+		}
+		totalSamples = total
+		if update.Update == nil {
+			continue
+		}
+		if loss := losses[i]; reference > 0 && loss > limit {
+			weight *= limit / loss
+			downWeighted = append(downWeighted, update.PropletID)
+		}
+		adjusted[i] = weight
+		totalWeight += weight
+		vectors = append(vectors, flattenUpdate(update, dim))
+		weights = append(weights, weight)
+	}
+
+	b := 0.0
+	if len(vectors) > 0 {
+		vec := weightedMean(vectors, weights, totalWeight)
+		if w != nil {
+			w = vec[:dim]
+		}
+		b = vec[dim]
+	}
+
+	return Model{
+		Data: map[string]any{
+			"w": w,
+			"b": b,
+		},
+		Metadata: map[string]any{
+			"total_samples":    totalSamples,
+			"num_updates":      len(updates),
+			"algorithm":        "FedAvg",
+			"quality_metric":   q.cfg.Metric,
+			"reference_loss":   reference,
+			"adjusted_weights": adjusted,
+			"down_weighted":    downWeighted,
+		},
+	}, nil
+}
+
+// quantile interpolates linearly between the closest ranks of values. It is
+// zero when values is empty.
+func quantile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
@@ -0,0 +1,79 @@
+package fl_test
+
+import (
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withLoss(u fl.Update, propletID string, loss float64) fl.Update {
+	u.PropletID = propletID
+	u.Metrics = map[string]any{"loss": loss}
+
+	return u
+}
+
+func TestQualityWeightedAggregatorReducesHighLoss(t *testing.T) {
+	t.Parallel()
+
+	updates := []fl.Update{
+		withLoss(update(10, 1, 1.0), "proplet-a", 0.2),
+		withLoss(update(10, 1, 1.0), "proplet-b", 0.3),
+		withLoss(update(10, 1, 1.0), "proplet-c", 0.25),
+		withLoss(update(10, 10, 10.0), "proplet-bad", 5.0),
+	}
+
+	plain, err := fl.NewFedAvgAggregator().Aggregate(updates)
+	require.NoError(t, err)
+	aggregator, err := fl.NewQualityWeightedAggregator(fl.QualityWeighting{})
+	require.NoError(t, err)
+	model, err := aggregator.Aggregate(updates)
+	require.NoError(t, err)
+
+	// The median loss is 0.275, so proplet-bad's weight is scaled by
+	// 2*0.275/5 = 0.11.
+	badWeight := 10 * 2 * 0.275 / 5
+	want := (30 + 10*badWeight) / (30 + badWeight)
+	assert.InDelta(t, want, model.Data["b"], 1e-9)
+	assert.InDelta(t, want, model.Data["w"].([]float64)[0], 1e-9)
+	assert.Less(t, model.Data["b"], plain.Data["b"])
+
+	assert.InDelta(t, 0.275, model.Metadata["reference_loss"], 1e-9)
+	assert.InDeltaSlice(t, []float64{10, 10, 10, badWeight}, model.Metadata["adjusted_weights"], 1e-9)
+	assert.Equal(t, []string{"proplet-bad"}, model.Metadata["down_weighted"])
+	assert.Equal(t, int64(40), model.Metadata["total_samples"])
+}
+
+func TestQualityWeightedAggregatorKeepsSampleWeighting(t *testing.T) {
+	t.Parallel()
+
+	updates := []fl.Update{
+		withLoss(update(10, 1, 1.0), "proplet-a", 0.5),
+		withLoss(update(30, 3, 3.0), "proplet-b", 0.6),
+		update(20, 2, 2.0),
+	}
+	aggregator, err := fl.NewQualityWeightedAggregator(fl.QualityWeighting{Quantile: 0.25, Threshold: 1.5})
+	require.NoError(t, err)
+	model, err := aggregator.Aggregate(updates)
+	require.NoError(t, err)
+
+	plain, err := fl.NewFedAvgAggregator().Aggregate(updates)
+	require.NoError(t, err)
+	assert.InDelta(t, plain.Data["b"], model.Data["b"], 1e-9)
+	assert.Empty(t, model.Metadata["down_weighted"])
+}
+
+func TestQualityWeightingValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, cfg := range []fl.QualityWeighting{
+		{Quantile: -0.1},
+		{Quantile: 1.5},
+		{Threshold: 0.5},
+	} {
+		_, err := fl.NewQualityWeightedAggregator(cfg)
+		assert.ErrorIs(t, err, fl.ErrInvalidSpec, "%+v", cfg)
+	}
+}
@@ -0,0 +1,59 @@
+package fl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// int8QuantHeader is the size of the little-endian float32 scale that
+// prefixes an int8-quant payload.
+const int8QuantHeader = 4
+
+// QuantizeInt8 encodes v as an int8-quant payload: a little-endian float32
+// scale followed by one signed byte per value, where value = q * scale. The
+// scale maps the largest magnitude in v to 127, so each value is off by at
+// most half a scale step.
+func QuantizeInt8(v []float64) ([]byte, error) {
+	var maxAbs float64
+	for i, f := range v {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%w: value %d is not finite", ErrInvalidEncoding, i)
+		}
+		maxAbs = max(maxAbs, math.Abs(f))
+	}
+	scale := float32(maxAbs / math.MaxInt8)
+	if math.IsInf(float64(scale), 0) {
+		return nil, fmt.Errorf("%w: values exceed the float32 scale range", ErrInvalidEncoding)
+	}
+
+	data := make([]byte, int8QuantHeader+len(v))
+	binary.LittleEndian.PutUint32(data, math.Float32bits(scale))
+	for i, f := range v {
+		var q float64
+		if scale > 0 {
+			q = math.Round(f / float64(scale))
+		}
+		data[int8QuantHeader+i] = byte(int8(min(max(q, -math.MaxInt8), math.MaxInt8)))
+	}
+
+	return data, nil
+}
+
+// DequantizeInt8 decodes a payload written by QuantizeInt8.
+func DequantizeInt8(data []byte) ([]float64, error) {
+	if len(data) < int8QuantHeader {
+		return nil, fmt.Errorf("%w: int8-quant payload of %d bytes has no scale", ErrInvalidEncoding, len(data))
+	}
+	scale := float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+	if math.IsNaN(scale) || math.IsInf(scale, 0) || scale < 0 {
+		return nil, fmt.Errorf("%w: invalid int8-quant scale %v", ErrInvalidEncoding, scale)
+	}
+
+	v := make([]float64, len(data)-int8QuantHeader)
+	for i, b := range data[int8QuantHeader:] {
+		v[i] = float64(int8(b)) * scale
+	}
+
+	return v, nil
+}
@@ -0,0 +1,94 @@
+package fl_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func quantized(t *testing.T, numSamples uint64, vec ...float64) fl.UpdateEnvelope {
+	t.Helper()
+	data, err := fl.QuantizeInt8(vec)
+	require.NoError(t, err)
+
+	return fl.UpdateEnvelope{Format: fl.FormatInt8Quant, NumSamples: numSamples, Data: data}
+}
+
+func TestQuantizeInt8RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	vec := []float64{0.5, -1.27, 0.001, 0, 1.27, -0.33}
+	data, err := fl.QuantizeInt8(vec)
+	require.NoError(t, err)
+	assert.Len(t, data, 4+len(vec))
+
+	got, err := fl.DequantizeInt8(data)
+	require.NoError(t, err)
+	require.Len(t, got, len(vec))
+	step := 1.27 / 127
+	for i := range vec {
+		assert.InDelta(t, vec[i], got[i], step/2+1e-9, "value %d", i)
+	}
+
+	zeros, err := fl.QuantizeInt8([]float64{0, 0})
+	require.NoError(t, err)
+	got, err = fl.DequantizeInt8(zeros)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 0}, got)
+}
+
+func TestAggregateInt8QuantMatchesFloatFedAvg(t *testing.T) {
+	t.Parallel()
+
+	vecs := [][]float64{
+		{0.12, -0.5, 0.33, 0.9},
+		{0.1, -0.45, 0.4, 1.1},
+		{0.2, -0.6, 0.25, 0.7},
+	}
+	samples := []uint64{10, 30, 60}
+	floats := make([]fl.UpdateEnvelope, len(vecs))
+	quants := make([]fl.UpdateEnvelope, len(vecs))
+	for i, v := range vecs {
+		floats[i] = envelope(t, samples[i], v...)
+		quants[i] = quantized(t, samples[i], v...)
+	}
+
+	want, err := fl.Aggregate(floats, fl.AlgorithmFedAvg, fl.FormatJSONF64, 0)
+	require.NoError(t, err)
+
+	global, err := fl.Aggregate(quants, fl.AlgorithmFedAvg, fl.FormatInt8Quant, 0)
+	require.NoError(t, err)
+	assert.Equal(t, fl.FormatInt8Quant, global.Format)
+	assert.Equal(t, uint64(100), global.NumSamples)
+	requantized, err := fl.DequantizeInt8(global.Data)
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, decode(t, want), requantized, 0.01)
+
+	float, err := fl.Aggregate(quants, fl.AlgorithmFedAvg, fl.FormatJSONF64, 0)
+	require.NoError(t, err)
+	assert.Equal(t, fl.FormatJSONF64, float.Format)
+	assert.InDeltaSlice(t, decode(t, want), decode(t, float), 0.005)
+}
+
+func TestInt8QuantRejectsMalformedPayloads(t *testing.T) {
+	t.Parallel()
+
+	_, err := fl.QuantizeInt8([]float64{1, math.NaN()})
+	assert.ErrorIs(t, err, fl.ErrInvalidEncoding)
+	_, err = fl.QuantizeInt8([]float64{math.MaxFloat64})
+	assert.ErrorIs(t, err, fl.ErrInvalidEncoding)
+
+	_, err = fl.DequantizeInt8([]byte{1, 2})
+	assert.ErrorIs(t, err, fl.ErrInvalidEncoding)
+	_, err = fl.DequantizeInt8([]byte{0, 0, 0xc0, 0x7f, 1})
+	assert.ErrorIs(t, err, fl.ErrInvalidEncoding)
+
+	short := quantized(t, 1, 1, 2)
+	long := quantized(t, 1, 1, 2, 3)
+	out, err := fl.Aggregate([]fl.UpdateEnvelope{short, long}, fl.AlgorithmFedAvg, fl.FormatInt8Quant, 0)
+	require.NoError(t, err)
+	assert.Equal(t, fl.FormatConcat, out.Format)
+}
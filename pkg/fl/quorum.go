@@ -0,0 +1,92 @@
+package fl
+
+import (
+	"fmt"
+	"slices"
+)
+
+// QuorumPolicy decides which updates a round needs before it may be
+// aggregated.
+type QuorumPolicy string
+
+const (
+	// QuorumAnyK completes a round once any K participants have reported.
+	QuorumAnyK QuorumPolicy = "any-k"
+	// QuorumAllRequired completes a round once every required participant
+	// has reported, regardless of K.
+	QuorumAllRequired QuorumPolicy = "all-required"
+	// QuorumRequiredPlusK completes a round once every required participant
+	// and at least K others have reported.
+	QuorumRequiredPlusK QuorumPolicy = "required-plus-k"
+)
+
+// Quorum is a round's completion rule. An empty Policy means QuorumAnyK.
+type Quorum struct {
+	Policy   QuorumPolicy
+	K        int
+	Required []string
+}
+
+func (q Quorum) policy() QuorumPolicy {
+	if q.Policy == "" {
+		return QuorumAnyK
+	}
+
+	return q.Policy
+}
+
+// Validate checks that the policy is known and has the participants it
+// needs. participants, when non-empty, must include every required one.
+func (q Quorum) Validate(participants []string) error {
+	if q.K < 0 {
+		return fmt.Errorf("%w: negative k_of_n", ErrInvalidQuorum)
+	}
+
+	switch q.policy() {
+	case QuorumAnyK:
+		if len(q.Required) > 0 {
+			return fmt.Errorf("%w: %s does not take required participants", ErrInvalidQuorum, QuorumAnyK)
+		}
+	case QuorumAllRequired, QuorumRequiredPlusK:
+		if len(q.Required) == 0 {
+			return fmt.Errorf("%w: %s needs required participants", ErrInvalidQuorum, q.Policy)
+		}
+	default:
+		return fmt.Errorf("%w: unknown policy %q", ErrInvalidQuorum, q.Policy)
+	}
+
+	if len(participants) == 0 {
+		return nil
+	}
+	for _, id := range q.Required {
+		if !slices.Contains(participants, id) {
+			return fmt.Errorf("%w: required participant %q is not a round participant", ErrInvalidQuorum, id)
+		}
+	}
+
+	return nil
+}
+
+// Met reports whether the updates from the received proplets satisfy the
+// quorum. A K of zero never completes an any-k round.
+func (q Quorum) Met(received []string) bool {
+	required := 0
+	for _, id := range q.Required {
+		if !slices.Contains(received, id) {
+			return false
+		}
+		required++
+	}
+	others := len(received) - required
+
+	switch q.policy() {
+	case QuorumAnyK:
+		return q.K > 0 && len(received) >= q.K
+	case QuorumAllRequired:
+		return true
+	case QuorumRequiredPlusK:
+		return others >= q.K
+	default:
+		return false
+	}
+}
@@ -0,0 +1,124 @@
+package fl_test
+
+import (
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuorumMet(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc     string
+		quorum   fl.Quorum
+		received []string
+		met      bool
+	}{
+		{
+			desc:     "any-k below k",
+			quorum:   fl.Quorum{Policy: fl.QuorumAnyK, K: 2},
+			received: []string{"a"},
+			met:      false,
+		},
+		{
+			desc:     "any-k reaches k",
+			quorum:   fl.Quorum{Policy: fl.QuorumAnyK, K: 2},
+			received: []string{"a", "c"},
+			met:      true,
+		},
+		{
+			desc:     "empty policy defaults to any-k",
+			quorum:   fl.Quorum{K: 1},
+			received: []string{"b"},
+			met:      true,
+		},
+		{
+			desc:     "any-k with zero k never completes",
+			quorum:   fl.Quorum{Policy: fl.QuorumAnyK},
+			received: []string{"a", "b"},
+			met:      false,
+		},
+		{
+			desc:     "all-required waits for a missing participant",
+			quorum:   fl.Quorum{Policy: fl.QuorumAllRequired, K: 1, Required: []string{"a", "b"}},
+			received: []string{"a", "c", "d"},
+			met:      false,
+		},
+		{
+			desc:     "all-required ignores k",
+			quorum:   fl.Quorum{Policy: fl.QuorumAllRequired, K: 5, Required: []string{"a", "b"}},
+			received: []string{"b", "a"},
+			met:      true,
+		},
+		{
+			desc:     "required-plus-k needs the required ones",
+			quorum:   fl.Quorum{Policy: fl.QuorumRequiredPlusK, K: 1, Required: []string{"a"}},
+			received: []string{"b", "c"},
+			met:      false,
+		},
+		{
+			desc:     "required-plus-k needs k others",
+			quorum:   fl.Quorum{Policy: fl.QuorumRequiredPlusK, K: 2, Required: []string{"a"}},
+			received: []string{"a", "b"},
+			met:      false,
+		},
+		{
+			desc:     "required-plus-k met",
+			quorum:   fl.Quorum{Policy: fl.QuorumRequiredPlusK, K: 2, Required: []string{"a"}},
+			received: []string{"a", "b", "c"},
+			met:      true,
+		},
+		{
+			desc:     "unknown policy never completes",
+			quorum:   fl.Quorum{Policy: "majority", K: 1},
+			received: []string{"a"},
+			met:      false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.met, tc.quorum.Met(tc.received))
+		})
+	}
+}
+
+func TestQuorumValidate(t *testing.T) {
+	t.Parallel()
+
+	participants := []string{"a", "b", "c"}
+	cases := []struct {
+		desc   string
+		quorum fl.Quorum
+		valid  bool
+	}{
+		{desc: "default policy", quorum: fl.Quorum{K: 2}, valid: true},
+		{desc: "any-k", quorum: fl.Quorum{Policy: fl.QuorumAnyK, K: 2}, valid: true},
+		{desc: "any-k with required", quorum: fl.Quorum{Policy: fl.QuorumAnyK, K: 2, Required: []string{"a"}}, valid: false},
+		{desc: "all-required", quorum: fl.Quorum{Policy: fl.QuorumAllRequired, Required: []string{"a", "b"}}, valid: true},
+		{desc: "all-required without required", quorum: fl.Quorum{Policy: fl.QuorumAllRequired}, valid: false},
+		{desc: "required-plus-k", quorum: fl.Quorum{Policy: fl.QuorumRequiredPlusK, K: 1, Required: []string{"a"}}, valid: true},
+		{desc: "required-plus-k without required", quorum: fl.Quorum{Policy: fl.QuorumRequiredPlusK, K: 1}, valid: false},
+		{desc: "required not a participant", quorum: fl.Quorum{Policy: fl.QuorumAllRequired, Required: []string{"z"}}, valid: false},
+		{desc: "negative k", quorum: fl.Quorum{K: -1}, valid: false},
+		{desc: "unknown policy", quorum: fl.Quorum{Policy: "majority"}, valid: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.quorum.Validate(participants)
+			if tc.valid {
+				require.NoError(t, err)
+
+				return
+			}
+			require.ErrorIs(t, err, fl.ErrInvalidQuorum)
+		})
+	}
+}
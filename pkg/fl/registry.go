@@ -0,0 +1,167 @@
+package fl
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// ModelRegistry keeps the global model versions of an FL deployment and
+// tracks which one is current. Versions are mirrored to PersistentStorage
+// when one is given. With a positive maxVersions only the newest
+// maxVersions versions are retained, plus v0 and the current version.
+type ModelRegistry struct {
+	mu          sync.RWMutex
+	models      map[int]Model
+	current     int
+	storage     *PersistentStorage
+	maxVersions int
+}
+
+// NewModelRegistry returns a registry holding the models already in storage,
+// which may be nil. The newest stored version becomes current.
+func NewModelRegistry(storage *PersistentStorage, maxVersions int) (*ModelRegistry, error) {
+	r := &ModelRegistry{
+		models:      make(map[int]Model),
+		storage:     storage,
+		maxVersions: maxVersions,
+	}
+	if storage == nil {
+		return r, nil
+	}
+
+	versions, err := storage.ListModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored models: %w", err)
+	}
+	for _, version := range versions {
+		model, err := storage.LoadModel(version)
+		if err != nil {
+			return nil, err
+		}
+		r.models[version] = *model
+		r.current = max(r.current, version)
+	}
+
+	return r, nil
+}
+
+// Store saves model as version. A version newer than the current one
+// becomes current. Old versions are pruned afterwards.
+func (r *ModelRegistry) Store(version int, model Model) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store(version, model)
+}
+
+// Add is Store for versions the registry does not hold yet; it fails with
+// ErrModelExists otherwise.
+func (r *ModelRegistry) Add(version int, model Model) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.models[version]; ok {
+		return fmt.Errorf("%w: v%d", ErrModelExists, version)
+	}
+
+	return r.store(version, model)
+}
+
+// Restore is Add for versions brought back from a backup or another
+// deployment: it leaves the current version alone.
+func (r *ModelRegistry) Restore(version int, model Model) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.models[version]; ok {
+		return fmt.Errorf("%w: v%d", ErrModelExists, version)
+	}
+	if r.storage != nil {
+		if err := r.storage.SaveModel(version, model); err != nil {
+			return err
+		}
+	}
+	r.models[version] = model
+
+	return r.prune()
+}
+
+func (r *ModelRegistry) store(version int, model Model) error {
+	if r.storage != nil {
+		if err := r.storage.SaveModel(version, model); err != nil {
+			return err
+		}
+	}
+	r.models[version] = model
+	if version > r.current {
+		r.current = version
+	}
+
+	return r.prune()
+}
+
+func (r *ModelRegistry) Get(version int) (Model, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	model, ok := r.models[version]
+	if !ok {
+		return Model{}, fmt.Errorf("%w: v%d", ErrModelNotFound, version)
+	}
+
+	return model, nil
+}
+
+// List returns the held versions in ascending order.
+func (r *ModelRegistry) List() []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(r.models))
+}
+
+// Current returns the current global model version.
+func (r *ModelRegistry) Current() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current
+}
+
+// SetCurrent makes the held version current.
+func (r *ModelRegistry) SetCurrent(version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.models[version]; !ok {
+		return fmt.Errorf("%w: v%d", ErrModelNotFound, version)
+	}
+	r.current = version
+
+	return nil
+}
+
+// prune drops the versions outside the retention policy from memory and
+// storage. Callers must hold the write lock.
+func (r *ModelRegistry) prune() error {
+	if r.maxVersions <= 0 || len(r.models) <= r.maxVersions {
+		return nil
+	}
+
+	versions := slices.Sorted(maps.Keys(r.models))
+	for _, version := range versions[:len(versions)-r.maxVersions] {
+		if version == 0 || version == r.current {
+			continue
+		}
+		if r.storage != nil {
+			if err := r.storage.DeleteModel(version); err != nil {
+				return err
+			}
+		}
+		delete(r.models, version)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package fl_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelRetention(t *testing.T) {
+	t.Parallel()
+	ps, _ := newStorage(t)
+	reg, err := fl.NewModelRegistry(ps, 3)
+	require.NoError(t, err)
+
+	for version := range 7 {
+		require.NoError(t, reg.Store(version, fl.Model{Data: map[string]any{"v": float64(version)}}))
+	}
+
+	assert.Equal(t, 6, reg.Current())
+	assert.Equal(t, []int{0, 4, 5, 6}, reg.List())
+	_, err = reg.Get(3)
+	require.ErrorIs(t, err, fl.ErrModelNotFound)
+	got, err := reg.Get(0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"v": 0.0}, got.Data)
+
+	stored, err := ps.ListModels()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{0, 4, 5, 6}, stored)
+}
+
+func TestModelRegistryReloadsStorage(t *testing.T) {
+	t.Parallel()
+	ps, _ := newStorage(t)
+	reg, err := fl.NewModelRegistry(ps, 0)
+	require.NoError(t, err)
+	for version := range 3 {
+		require.NoError(t, reg.Store(version, fl.Model{Data: map[string]any{"v": float64(version)}}))
+	}
+
+	reloaded, err := fl.NewModelRegistry(ps, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, reloaded.Current())
+	assert.Equal(t, []int{0, 1, 2}, reloaded.List())
+}
+
+func TestModelRegistryRestore(t *testing.T) {
+	t.Parallel()
+	reg, err := fl.NewModelRegistry(nil, 0)
+	require.NoError(t, err)
+	require.NoError(t, reg.Store(2, fl.Model{Data: map[string]any{"v": 2.0}}))
+
+	require.NoError(t, reg.Restore(5, fl.Model{Data: map[string]any{"v": 5.0}}))
+	assert.Equal(t, 2, reg.Current(), "a restored version does not become current")
+	assert.Equal(t, []int{2, 5}, reg.List())
+	require.ErrorIs(t, reg.Restore(5, fl.Model{}), fl.ErrModelExists)
+}
+
+func TestModelRegistryConcurrentAccess(t *testing.T) {
+	t.Parallel()
+	ps, _ := newStorage(t)
+	reg, err := fl.NewModelRegistry(ps, 5)
+	require.NoError(t, err)
+	require.NoError(t, reg.Store(0, fl.Model{Data: map[string]any{"v": 0.0}}))
+
+	const writers, perWriter = 4, 25
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				version := 1 + w*perWriter + i
+				assert.NoError(t, reg.Store(version, fl.Model{Data: map[string]any{"v": float64(version)}}))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range perWriter {
+				// A concurrent Store may prune the version between the calls.
+				if err := reg.SetCurrent(reg.Current()); err != nil {
+					assert.ErrorIs(t, err, fl.ErrModelNotFound)
+				}
+				assert.Contains(t, reg.List(), 0)
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, err = reg.Get(reg.Current())
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(reg.List()), 7)
+	assert.Contains(t, reg.List(), 0)
+	assert.Contains(t, reg.List(), writers*perWriter)
+}
@@ -0,0 +1,66 @@
+package fl
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+)
+
+// SignatureKey is the update envelope field holding its signature.
+const SignatureKey = "signature"
+
+// SignEnvelope returns the hex-encoded HMAC-SHA256 of envelope under key.
+// The MAC covers the envelope's canonical form: compact JSON with sorted keys
+// and without the signature field, which is how serde_json serializes it on
+// proplets.
+func SignEnvelope(key []byte, envelope map[string]any) (string, error) {
+	data, err := canonicalEnvelope(envelope)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyEnvelope checks the signature an envelope carries against key.
+func VerifyEnvelope(key []byte, envelope map[string]any) error {
+	signature, _ := envelope[SignatureKey].(string)
+	if signature == "" {
+		return ErrMissingSignature
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	want, err := SignEnvelope(key, envelope)
+	if err != nil {
+		return err
+	}
+	wantBytes, _ := hex.DecodeString(want)
+	if !hmac.Equal(got, wantBytes) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func canonicalEnvelope(envelope map[string]any) ([]byte, error) {
+	unsigned := maps.Clone(envelope)
+	delete(unsigned, SignatureKey)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(unsigned); err != nil {
+		return nil, fmt.Errorf("failed to encode update envelope: %w", err)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,135 @@
+package fl_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEnvelope() map[string]any {
+	return map[string]any{
+		"task_id":     "task-1",
+		"round_id":    "round-1",
+		"proplet_id":  "proplet-1",
+		"num_samples": 10.0,
+		"update_b64":  "WzAuMSwwLjJd",
+		"format":      "json-f64",
+		"metrics":     map[string]any{"note": "<a&b>"},
+	}
+}
+
+func TestSignEnvelopeCanonicalForm(t *testing.T) {
+	t.Parallel()
+	key := []byte("secret")
+
+	// The canonical form serde_json produces for the same envelope on a
+	// proplet: compact, sorted keys, no HTML escaping.
+	canonical := `{"format":"json-f64","metrics":{"note":"<a&b>"},"num_samples":10,"proplet_id":"proplet-1","round_id":"round-1","task_id":"task-1","update_b64":"WzAuMSwwLjJd"}`
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(canonical))
+
+	got, err := fl.SignEnvelope(key, testEnvelope())
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), got)
+}
+
+func TestSignEnvelopeMatchesProplet(t *testing.T) {
+	t.Parallel()
+	env := testEnvelope()
+	env["metrics"] = map[string]any{}
+
+	// Pinned in the proplet's build_fl_update_envelope test as well.
+	got, err := fl.SignEnvelope([]byte("secret"), env)
+	require.NoError(t, err)
+	assert.Equal(t, "28ddf470ec35c6ee3f84754eba2a2e7f3637a3ed7d5a49c63586ec86c6e59c39", got)
+}
+
+func TestVerifyEnvelope(t *testing.T) {
+	t.Parallel()
+	key := []byte("secret")
+
+	signed := func() map[string]any {
+		env := testEnvelope()
+		sig, err := fl.SignEnvelope(key, env)
+		require.NoError(t, err)
+		env[fl.SignatureKey] = sig
+
+		return env
+	}
+
+	cases := []struct {
+		desc     string
+		envelope func() map[string]any
+		key      []byte
+		wantErr  error
+	}{
+		{
+			desc:     "valid signature",
+			envelope: signed,
+			key:      key,
+		},
+		{
+			desc: "tampered update",
+			envelope: func() map[string]any {
+				env := signed()
+				env["update_b64"] = "WzkuOSw5LjVd"
+
+				return env
+			},
+			key:     key,
+			wantErr: fl.ErrInvalidSignature,
+		},
+		{
+			desc: "tampered sample count",
+			envelope: func() map[string]any {
+				env := signed()
+				env["num_samples"] = 1000.0
+
+				return env
+			},
+			key:     key,
+			wantErr: fl.ErrInvalidSignature,
+		},
+		{
+			desc:     "wrong key",
+			envelope: signed,
+			key:      []byte("other"),
+			wantErr:  fl.ErrInvalidSignature,
+		},
+		{
+			desc:     "unsigned envelope",
+			envelope: testEnvelope,
+			key:      key,
+			wantErr:  fl.ErrMissingSignature,
+		},
+		{
+			desc: "malformed signature",
+			envelope: func() map[string]any {
+				env := testEnvelope()
+				env[fl.SignatureKey] = "not-hex"
+
+				return env
+			},
+			key:     key,
+			wantErr: fl.ErrInvalidSignature,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			err := fl.VerifyEnvelope(tc.key, tc.envelope())
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package fl
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// maxSparseDim bounds the dense length a sparse-f64 payload may declare, so
+// a malformed envelope cannot make the aggregator allocate without limit.
+const maxSparseDim = 1 << 24
+
+// SparseUpdate is the sparse-f64 payload: the Values of the coordinates at
+// Indices of a vector of length Dim. Coordinates that are not listed are
+// zero, so a client sending only its top-k changes contributes nothing to
+// the others.
+type SparseUpdate struct {
+	Dim     int       `json:"dim"`
+	Indices []int     `json:"indices"`
+	Values  []float64 `json:"values"`
+}
+
+// TopK keeps the k coordinates of v with the largest magnitude. Ties keep
+// the lower index. A k of zero or above len(v) keeps every non-zero value.
+func TopK(v []float64, k int) SparseUpdate {
+	indices := make([]int, 0, len(v))
+	for i, f := range v {
+		if f != 0 {
+			indices = append(indices, i)
+		}
+	}
+	if k > 0 && k < len(indices) {
+		slices.SortStableFunc(indices, func(a, b int) int {
+			return cmp.Compare(math.Abs(v[b]), math.Abs(v[a]))
+		})
+		indices = indices[:k]
+		slices.Sort(indices)
+	}
+
+	s := SparseUpdate{Dim: len(v), Indices: indices, Values: make([]float64, len(indices))}
+	for i, idx := range indices {
+		s.Values[i] = v[idx]
+	}
+
+	return s
+}
+
+// Dense scatters the update into a vector of length Dim.
+func (s SparseUpdate) Dense() ([]float64, error) {
+	if s.Dim < 0 || s.Dim > maxSparseDim {
+		return nil, fmt.Errorf("%w: sparse dimension %d out of range", ErrInvalidEncoding, s.Dim)
+	}
+	if len(s.Indices) != len(s.Values) {
+		return nil, fmt.Errorf("%w: %d sparse indices for %d values", ErrInvalidEncoding, len(s.Indices), len(s.Values))
+	}
+
+	dense := make([]float64, s.Dim)
+	seen := make(map[int]struct{}, len(s.Indices))
+	for i, idx := range s.Indices {
+		if idx < 0 || idx >= s.Dim {
+			return nil, fmt.Errorf("%w: sparse index %d outside dimension %d", ErrInvalidEncoding, idx, s.Dim)
+		}
+		if _, dup := seen[idx]; dup {
+			return nil, fmt.Errorf("%w: sparse index %d repeated", ErrInvalidEncoding, idx)
+		}
+		seen[idx] = struct{}{}
+		dense[idx] = s.Values[i]
+	}
+
+	return dense, nil
+}
+
+// decodeSparse scatters a sparse-f64 payload into a dense vector, so the
+// aggregators accumulate it like any other update.
+func decodeSparse(data []byte) ([]float64, error) {
+	var s SparseUpdate
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEncoding, err)
+	}
+
+	return s.Dense()
+}
@@ -0,0 +1,108 @@
+package fl_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sparse(t *testing.T, numSamples uint64, s fl.SparseUpdate) fl.UpdateEnvelope {
+	t.Helper()
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	return fl.UpdateEnvelope{Format: fl.FormatSparseF64, NumSamples: numSamples, Data: data}
+}
+
+func TestAggregateSparse(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc    string
+		updates []fl.UpdateEnvelope
+		want    []float64
+	}{
+		{
+			desc: "disjoint indices",
+			updates: []fl.UpdateEnvelope{
+				sparse(t, 10, fl.SparseUpdate{Dim: 4, Indices: []int{0, 1}, Values: []float64{1, 2}}),
+				sparse(t, 30, fl.SparseUpdate{Dim: 4, Indices: []int{2, 3}, Values: []float64{4, 8}}),
+			},
+			want: []float64{0.25, 0.5, 3, 6},
+		},
+		{
+			desc: "overlapping indices",
+			updates: []fl.UpdateEnvelope{
+				sparse(t, 10, fl.SparseUpdate{Dim: 3, Indices: []int{0, 1}, Values: []float64{4, 2}}),
+				sparse(t, 30, fl.SparseUpdate{Dim: 3, Indices: []int{1, 2}, Values: []float64{6, -4}}),
+			},
+			want: []float64{1, 5, -3},
+		},
+		{
+			desc: "mixed with a dense update",
+			updates: []fl.UpdateEnvelope{
+				sparse(t, 20, fl.SparseUpdate{Dim: 2, Indices: []int{1}, Values: []float64{4}}),
+				envelope(t, 20, 2, 2),
+			},
+			want: []float64{1, 3},
+		},
+	}
+
+	for _, tc := range cases {
+		out, err := fl.Aggregate(tc.updates, fl.AlgorithmFedAvg, fl.FormatJSONF64, 0)
+		require.NoError(t, err, tc.desc)
+		assert.InDeltaSlice(t, tc.want, decode(t, out), 1e-12, tc.desc)
+	}
+}
+
+func TestAggregateSparseOutput(t *testing.T) {
+	t.Parallel()
+
+	updates := []fl.UpdateEnvelope{
+		sparse(t, 1, fl.SparseUpdate{Dim: 5, Indices: []int{1}, Values: []float64{2}}),
+		sparse(t, 1, fl.SparseUpdate{Dim: 5, Indices: []int{3}, Values: []float64{-4}}),
+	}
+	out, err := fl.Aggregate(updates, fl.AlgorithmFedAvg, fl.FormatSparseF64, 0)
+	require.NoError(t, err)
+	assert.Equal(t, fl.FormatSparseF64, out.Format)
+
+	var got fl.SparseUpdate
+	require.NoError(t, json.Unmarshal(out.Data, &got))
+	assert.Equal(t, fl.SparseUpdate{Dim: 5, Indices: []int{1, 3}, Values: []float64{1, -2}}, got)
+}
+
+func TestTopK(t *testing.T) {
+	t.Parallel()
+
+	v := []float64{0.1, -3, 0, 2, -2, 0.5}
+	assert.Equal(t, fl.SparseUpdate{Dim: 6, Indices: []int{1, 3, 4}, Values: []float64{-3, 2, -2}}, fl.TopK(v, 3))
+	assert.Equal(t, fl.SparseUpdate{Dim: 6, Indices: []int{0, 1, 3, 4, 5}, Values: []float64{0.1, -3, 2, -2, 0.5}}, fl.TopK(v, 0))
+
+	dense, err := fl.TopK(v, 2).Dense()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, -3, 0, 2, 0, 0}, dense)
+}
+
+func TestSparseRejectsMalformedPayloads(t *testing.T) {
+	t.Parallel()
+
+	for desc, s := range map[string]fl.SparseUpdate{
+		"index out of range": {Dim: 2, Indices: []int{2}, Values: []float64{1}},
+		"negative index":     {Dim: 2, Indices: []int{-1}, Values: []float64{1}},
+		"repeated index":     {Dim: 2, Indices: []int{1, 1}, Values: []float64{1, 2}},
+		"length mismatch":    {Dim: 2, Indices: []int{0, 1}, Values: []float64{1}},
+		"huge dimension":     {Dim: 1 << 30},
+	} {
+		_, err := s.Dense()
+		assert.ErrorIs(t, err, fl.ErrInvalidEncoding, desc)
+	}
+
+	bad := sparse(t, 1, fl.SparseUpdate{Dim: 2, Indices: []int{5}, Values: []float64{1}})
+	good := sparse(t, 1, fl.SparseUpdate{Dim: 2, Indices: []int{0}, Values: []float64{1}})
+	out, err := fl.Aggregate([]fl.UpdateEnvelope{good, bad}, fl.AlgorithmFedAvg, fl.FormatSparseF64, 0)
+	require.NoError(t, err)
+	assert.Equal(t, fl.FormatConcat, out.Format, "undecodable rounds fall back to concatenation")
+}
@@ -0,0 +1,41 @@
+package fl
+
+import "fmt"
+
+// Spec is the FL configuration shared by configured experiment rounds and
+// the federated tasks that train in them.
+type Spec struct {
+	RoundID      string
+	Algorithm    string
+	UpdateFormat string
+	Participants []string
+	Quorum       Quorum
+	TimeoutS     int
+}
+
+// Validate checks that the spec names a round, a registered algorithm and a
+// known update format, and that its quorum can be met by its participants.
+// Empty Algorithm and UpdateFormat are left to their defaults. UpdateFormat
+// is accepted in any spelling NormalizeUpdateFormat canonicalizes.
+func (s Spec) Validate() error {
+	if s.RoundID == "" {
+		return fmt.Errorf("%w: round_id is required", ErrInvalidSpec)
+	}
+	if _, err := DefaultAggregators.Lookup(s.Algorithm); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+	if _, err := NormalizeUpdateFormat(s.UpdateFormat); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+	if s.TimeoutS < 0 {
+		return fmt.Errorf("%w: negative timeout_s", ErrInvalidSpec)
+	}
+	if err := s.Quorum.Validate(s.Participants); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+	if len(s.Participants) > 0 && s.Quorum.K > len(s.Participants) {
+		return fmt.Errorf("%w: %w: k_of_n %d exceeds the %d participants", ErrInvalidSpec, ErrInvalidQuorum, s.Quorum.K, len(s.Participants))
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package fl_test
+
+import (
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := fl.Spec{
+		RoundID:      "round-1",
+		Algorithm:    fl.AlgorithmMedian,
+		UpdateFormat: fl.FormatJSONF64,
+		Participants: []string{"proplet-a", "proplet-b"},
+		Quorum:       fl.Quorum{Policy: fl.QuorumAnyK, K: 2},
+		TimeoutS:     60,
+	}
+
+	cases := []struct {
+		desc    string
+		mutate  func(s *fl.Spec)
+		wantErr error
+	}{
+		{
+			desc:   "valid spec",
+			mutate: func(*fl.Spec) {},
+		},
+		{
+			desc: "defaults only",
+			mutate: func(s *fl.Spec) {
+				*s = fl.Spec{RoundID: "round-1"}
+			},
+		},
+		{
+			desc:    "missing round",
+			mutate:  func(s *fl.Spec) { s.RoundID = "" },
+			wantErr: fl.ErrInvalidSpec,
+		},
+		{
+			desc:    "unknown algorithm",
+			mutate:  func(s *fl.Spec) { s.Algorithm = "fedprox" },
+			wantErr: fl.ErrUnknownAlgorithm,
+		},
+		{
+			desc:    "unknown update format",
+			mutate:  func(s *fl.Spec) { s.UpdateFormat = "f16" },
+			wantErr: fl.ErrInvalidSpec,
+		},
+		{
+			desc:    "negative timeout",
+			mutate:  func(s *fl.Spec) { s.TimeoutS = -1 },
+			wantErr: fl.ErrInvalidSpec,
+		},
+		{
+			desc:    "negative k",
+			mutate:  func(s *fl.Spec) { s.Quorum.K = -1 },
+			wantErr: fl.ErrInvalidQuorum,
+		},
+		{
+			desc:    "k exceeds participants",
+			mutate:  func(s *fl.Spec) { s.Quorum.K = 3 },
+			wantErr: fl.ErrInvalidQuorum,
+		},
+		{
+			desc: "required participant not in round",
+			mutate: func(s *fl.Spec) {
+				s.Quorum = fl.Quorum{Policy: fl.QuorumAllRequired, Required: []string{"proplet-c"}}
+			},
+			wantErr: fl.ErrInvalidQuorum,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			spec := valid
+			spec.Participants = append([]string(nil), valid.Participants...)
+			tc.mutate(&spec)
+
+			err := spec.Validate()
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+			require.ErrorIs(t, err, fl.ErrInvalidSpec)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestNormalizeUpdateFormat(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"":             "",
+		"json-f64":     fl.FormatJSONF64,
+		" JSON_F64 ":   fl.FormatJSONF64,
+		"F32-Delta":    fl.FormatF32Delta,
+		"int8_quant":   fl.FormatInt8Quant,
+		"SPARSE-F64\n": fl.FormatSparseF64,
+	} {
+		got, err := fl.NormalizeUpdateFormat(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, typo := range []string{"json-f46", "f32delta", "jsonf64", "custom", "f16"} {
+		_, err := fl.NormalizeUpdateFormat(typo)
+		assert.ErrorIs(t, err, fl.ErrUnknownFormat, typo)
+
+		err = fl.Spec{RoundID: "round-1", UpdateFormat: typo}.Validate()
+		assert.ErrorIs(t, err, fl.ErrInvalidSpec, typo)
+		assert.ErrorIs(t, err, fl.ErrUnknownFormat, typo)
+	}
+}
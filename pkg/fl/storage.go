@@ -1,10 +1,16 @@
 package fl
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -99,29 +105,40 @@ func (ps *PersistentStorage) ListRounds() ([]string, error) {
 	return roundIDs, nil
 }
 
+// SaveModel stores model gzip-compressed; the ".gz" suffix of the model file
+// marks it as compressed.
 func (ps *PersistentStorage) SaveModel(version int, model Model) error {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 
-	modelFile := filepath.Join(ps.modelsDir, fmt.Sprintf("model_v%d.json", version))
-	data, err := json.MarshalIndent(model, "", "  ")
+	data, err := json.Marshal(model)
 	if err != nil {
 		return fmt.Errorf("failed to marshal model: %w", err)
 	}
 
-	if err := os.WriteFile(modelFile, data, 0o644); err != nil {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return fmt.Errorf("failed to compress model: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress model: %w", err)
+	}
+
+	if err := os.WriteFile(ps.modelFile(version)+compressedSuffix, buf.Bytes(), 0o644); err != nil {
 		return fmt.Errorf("failed to write model file: %w", err)
 	}
 
 	return nil
 }
 
+// LoadModel reads a model stored by SaveModel, falling back to the
+// uncompressed files written by earlier versions.
 func (ps *PersistentStorage) LoadModel(version int) (*Model, error) {
 	ps.mu.RLock()
 	defer ps.mu.RUnlock()
 
-	modelFile := filepath.Join(ps.modelsDir, fmt.Sprintf("model_v%d.json", version))
-	data, err := os.ReadFile(modelFile)
+	data, err := ps.readModelFile(version)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read model file: %w", err)
 	}
@@ -149,7 +166,7 @@ func (ps *PersistentStorage) ListModels() ([]int, error) {
 			continue
 		}
 		var version int
-		if _, err := fmt.Sscanf(entry.Name(), "model_v%d.json", &version); err == nil {
+		if _, err := fmt.Sscanf(entry.Name(), "model_v%d.json", &version); err == nil && !slices.Contains(versions, version) {
 			versions = append(versions, version)
 		}
 	}
@@ -157,6 +174,45 @@ func (ps *PersistentStorage) ListModels() ([]int, error) {
 	return versions, nil
 }
 
+// DeleteModel removes every stored file of version.
+func (ps *PersistentStorage) DeleteModel(version int) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	for _, file := range []string{ps.modelFile(version) + compressedSuffix, ps.modelFile(version)} {
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete model file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+const compressedSuffix = ".gz"
+
+func (ps *PersistentStorage) modelFile(version int) string {
+	return filepath.Join(ps.modelsDir, fmt.Sprintf("model_v%d.json", version))
+}
+
+func (ps *PersistentStorage) readModelFile(version int) ([]byte, error) {
+	f, err := os.Open(ps.modelFile(version) + compressedSuffix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.ReadFile(ps.modelFile(version))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
+
 // sanitizeRoundID removes path traversal sequences and other dangerous characters
 // from roundID to prevent directory traversal attacks.
 func sanitizeRoundID(roundID string) string {
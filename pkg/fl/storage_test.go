@@ -0,0 +1,69 @@
+package fl_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/fl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStorage(t *testing.T) (*fl.PersistentStorage, string) {
+	t.Helper()
+	dir := t.TempDir()
+	modelsDir := filepath.Join(dir, "models")
+	ps, err := fl.NewPersistentStorage(filepath.Join(dir, "rounds"), modelsDir)
+	require.NoError(t, err)
+
+	return ps, modelsDir
+}
+
+func TestModelStoredCompressed(t *testing.T) {
+	t.Parallel()
+	ps, modelsDir := newStorage(t)
+
+	weights := make([]any, 10000)
+	for i := range weights {
+		weights[i] = float64(i%100) / 100
+	}
+	model := fl.Model{
+		Data:     map[string]any{"w": weights, "b": 0.5},
+		Metadata: map[string]any{"round_id": "round-1"},
+	}
+	require.NoError(t, ps.SaveModel(1, model))
+
+	got, err := ps.LoadModel(1)
+	require.NoError(t, err)
+	assert.Equal(t, model, *got)
+
+	raw, err := json.Marshal(model)
+	require.NoError(t, err)
+	entries, err := os.ReadDir(modelsDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+	assert.Less(t, info.Size(), int64(len(raw)))
+}
+
+func TestLoadUncompressedModel(t *testing.T) {
+	t.Parallel()
+	ps, modelsDir := newStorage(t)
+
+	model := fl.Model{Data: map[string]any{"w": []any{0.1, 0.2}}}
+	raw, err := json.Marshal(model)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "model_v0.json"), raw, 0o644))
+	require.NoError(t, ps.SaveModel(1, model))
+
+	got, err := ps.LoadModel(0)
+	require.NoError(t, err)
+	assert.Equal(t, model, *got)
+
+	versions, err := ps.ListModels()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{0, 1}, versions)
+}
@@ -1,12 +1,16 @@
 package fl
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type RoundState struct {
 	RoundID   string
 	ModelRef  string
 	KOfN      int
 	TimeoutS  int
+	Algorithm string
 	StartTime time.Time
 	Updates   []Update
 	Completed bool
@@ -22,6 +26,43 @@ type Update struct {
 	ReceivedAt   time.Time      `json:"received_at"`
 }
 
+// ParticipantStatus summarizes the update a single proplet contributed to a
+// round, so undersized or malformed updates stand out.
+type ParticipantStatus struct {
+	PropletID      string         `json:"proplet_id"`
+	UpdateReceived bool           `json:"update_received"`
+	UpdateBytes    int            `json:"update_bytes"`
+	NumSamples     int            `json:"num_samples"`
+	Metrics        map[string]any `json:"metrics,omitempty"`
+	ReceivedAt     time.Time      `json:"received_at"`
+}
+
+func NewParticipantStatus(u Update) ParticipantStatus {
+	size := 0
+	if data, err := json.Marshal(u.Update); err == nil && len(u.Update) > 0 {
+		size = len(data)
+	}
+
+	return ParticipantStatus{
+		PropletID:      u.PropletID,
+		UpdateReceived: len(u.Update) > 0,
+		UpdateBytes:    size,
+		NumSamples:     u.NumSamples,
+		Metrics:        u.Metrics,
+		ReceivedAt:     u.ReceivedAt,
+	}
+}
+
+// Participants returns one status entry per update received for the round.
+func (r *RoundState) Participants() []ParticipantStatus {
+	statuses := make([]ParticipantStatus, 0, len(r.Updates))
+	for i := range r.Updates {
+		statuses = append(statuses, NewParticipantStatus(r.Updates[i]))
+	}
+
+	return statuses
+}
+
 type Task struct {
 	RoundID     string         `json:"round_id"`
 	ModelRef    string         `json:"model_ref"`
@@ -0,0 +1,79 @@
+// Package logging holds the log level plumbing shared by the Go binaries:
+// parsing the configured level and changing it while the process runs.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// ParseLevel parses a level name such as "debug", "INFO" or "warn+2". The
+// "warning" spelling used by the proplet is accepted as an alias of "warn".
+func ParseLevel(s string) (slog.Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if rest, ok := strings.CutPrefix(name, "warning"); ok {
+		name = "warn" + rest
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+
+	return level, nil
+}
+
+// NewLevelVar returns a LevelVar initialised to the parsed level.
+func NewLevelVar(s string) (*slog.LevelVar, error) {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return nil, err
+	}
+	lv := new(slog.LevelVar)
+	lv.Set(level)
+
+	return lv, nil
+}
+
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler reports the current level on GET and sets it from a
+// {"level": "..."} body on PUT.
+func LevelHandler(lv *slog.LevelVar, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body levelBody
+			if err := json.NewDecoder(io.LimitReader(r.Body, 1024)).Decode(&body); err != nil {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+
+				return
+			}
+			level, err := ParseLevel(body.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+
+				return
+			}
+			if prev := lv.Level(); prev != level {
+				lv.Set(level)
+				logger.Info("log level changed", slog.String("from", prev.String()), slog.String("to", level.String()))
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levelBody{Level: lv.Level().String()})
+	}
+}
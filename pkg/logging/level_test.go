@@ -0,0 +1,88 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		in    string
+		level slog.Level
+		err   bool
+	}{
+		{in: "debug", level: slog.LevelDebug},
+		{in: "INFO", level: slog.LevelInfo},
+		{in: " warn ", level: slog.LevelWarn},
+		{in: "warning", level: slog.LevelWarn},
+		{in: "error", level: slog.LevelError},
+		{in: "info+2", level: slog.LevelInfo + 2},
+		{in: "", err: true},
+		{in: "verbose", err: true},
+	}
+	for _, tc := range cases {
+		level, err := logging.ParseLevel(tc.in)
+		if tc.err {
+			assert.Error(t, err, tc.in)
+
+			continue
+		}
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.level, level, tc.in)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	t.Parallel()
+
+	lv, err := logging.NewLevelVar("info")
+	require.NoError(t, err)
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: lv}))
+	handler := logging.LevelHandler(lv, logger)
+
+	do := func(method, body string) (int, string) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(method, "/loglevel", strings.NewReader(body)))
+		var got struct {
+			Level string `json:"level"`
+		}
+		_ = json.NewDecoder(rec.Body).Decode(&got)
+
+		return rec.Code, got.Level
+	}
+
+	code, level := do(http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "INFO", level)
+
+	logger.Debug("hidden")
+	assert.NotContains(t, out.String(), "hidden")
+
+	code, level = do(http.MethodPut, `{"level":"debug"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "DEBUG", level)
+	logger.Debug("shown")
+	assert.Contains(t, out.String(), "shown")
+
+	code, _ = do(http.MethodPut, `{"level":"loud"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, slog.LevelDebug, lv.Level())
+
+	code, _ = do(http.MethodPut, `not json`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = do(http.MethodDelete, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
@@ -85,6 +85,10 @@ func GetUint64(m map[string]any, key string) uint64 {
 	if ok {
 		return uint64(i)
 	}
+	f, ok := value.(float64)
+	if ok && f > 0 {
+		return uint64(f)
+	}
 
 	return 0
 }
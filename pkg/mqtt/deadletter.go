@@ -0,0 +1,41 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var errNoDeadLetterTopic = errors.New("no dead-letter topic configured")
+
+// DeadLetter wraps a message that could not be handled so it can be
+// inspected or replayed. Payload is the original message body as received.
+type DeadLetter struct {
+	Topic     string    `json:"topic"`
+	Payload   string    `json:"payload"`
+	Error     string    `json:"error"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DeadLetterer is implemented by PubSubs that can republish unprocessable
+// messages to a dead-letter topic.
+type DeadLetterer interface {
+	DeadLetter(ctx context.Context, topic string, payload []byte, cause error) error
+}
+
+func (ps *pubsub) DeadLetter(ctx context.Context, topic string, payload []byte, cause error) error {
+	if ps.deadLetterTopic == "" {
+		return errNoDeadLetterTopic
+	}
+
+	msg := DeadLetter{
+		Topic:     topic,
+		Payload:   string(payload),
+		Timestamp: time.Now().UTC(),
+	}
+	if cause != nil {
+		msg.Error = cause.Error()
+	}
+
+	return ps.Publish(ctx, ps.deadLetterTopic, msg)
+}
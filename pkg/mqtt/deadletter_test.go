@@ -0,0 +1,153 @@
+package mqtt_test
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/pkg/mqtt"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type doneToken struct{}
+
+func (doneToken) Wait() bool                     { return true }
+func (doneToken) WaitTimeout(time.Duration) bool { return true }
+func (doneToken) Error() error                   { return nil }
+
+func (doneToken) Done() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+
+	return ch
+}
+
+type published struct {
+	topic   string
+	payload []byte
+}
+
+// recordingClient captures publishes; every other method panics if called.
+type recordingClient struct {
+	paho.Client
+	mu        sync.Mutex
+	published []published
+}
+
+func (c *recordingClient) Publish(topic string, _ byte, _ bool, payload any) paho.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, _ := payload.([]byte)
+	c.published = append(c.published, published{topic: topic, payload: data})
+
+	return doneToken{}
+}
+
+type message struct {
+	topic   string
+	payload []byte
+	acked   bool
+}
+
+func (m *message) Duplicate() bool   { return false }
+func (m *message) Qos() byte         { return 1 }
+func (m *message) Retained() bool    { return false }
+func (m *message) Topic() string     { return m.topic }
+func (m *message) MessageID() uint16 { return 1 }
+func (m *message) Payload() []byte   { return m.payload }
+func (m *message) Ack()              { m.acked = true }
+
+const deadLetterTopic = "m/d1/c/c1/dead-letter"
+
+func decodeDeadLetter(t *testing.T, p published) mqtt.DeadLetter {
+	t.Helper()
+	require.Equal(t, deadLetterTopic, p.topic)
+	var dl mqtt.DeadLetter
+	require.NoError(t, json.Unmarshal(p.payload, &dl))
+
+	return dl
+}
+
+func TestHandlerErrorIsDeadLettered(t *testing.T) {
+	t.Parallel()
+
+	client := &recordingClient{}
+	handler := mqtt.NewMessageHandler(client, deadLetterTopic, func(string, map[string]any) error {
+		return errors.New("cannot process")
+	})
+	msg := &message{topic: "m/d1/c/c1/control/proplet/results", payload: []byte(`{"task_id":"t1"}`)}
+	handler(client, msg)
+
+	require.Len(t, client.published, 1)
+	dl := decodeDeadLetter(t, client.published[0])
+	assert.Equal(t, msg.topic, dl.Topic)
+	assert.JSONEq(t, `{"task_id":"t1"}`, dl.Payload)
+	assert.Equal(t, "cannot process", dl.Error)
+	assert.False(t, dl.Timestamp.IsZero())
+	assert.True(t, msg.acked)
+}
+
+func TestMalformedMessageIsDeadLettered(t *testing.T) {
+	t.Parallel()
+
+	client := &recordingClient{}
+	called := false
+	handler := mqtt.NewMessageHandler(client, deadLetterTopic, func(string, map[string]any) error {
+		called = true
+
+		return nil
+	})
+	msg := &message{topic: "m/d1/c/c1/control/proplet/alive", payload: []byte(`not json`)}
+	handler(client, msg)
+
+	assert.False(t, called)
+	require.Len(t, client.published, 1)
+	dl := decodeDeadLetter(t, client.published[0])
+	assert.Equal(t, "not json", dl.Payload)
+	assert.NotEmpty(t, dl.Error)
+	assert.True(t, msg.acked)
+}
+
+func TestHandlerErrorWithoutDeadLetterTopic(t *testing.T) {
+	t.Parallel()
+
+	client := &recordingClient{}
+	handler := mqtt.NewMessageHandler(client, "", func(string, map[string]any) error {
+		return errors.New("cannot process")
+	})
+	msg := &message{topic: "m/d1/c/c1/control/proplet/results", payload: []byte(`{}`)}
+	handler(client, msg)
+
+	assert.Empty(t, client.published)
+	assert.False(t, msg.acked)
+}
+
+func TestDeadLetterTopicIsNotDeadLettered(t *testing.T) {
+	t.Parallel()
+
+	client := &recordingClient{}
+	handler := mqtt.NewMessageHandler(client, deadLetterTopic, func(string, map[string]any) error {
+		return errors.New("cannot process")
+	})
+	handler(client, &message{topic: deadLetterTopic, payload: []byte(`{}`)})
+
+	assert.Empty(t, client.published)
+}
+
+func TestSuccessfulMessageIsNotDeadLettered(t *testing.T) {
+	t.Parallel()
+
+	client := &recordingClient{}
+	handler := mqtt.NewMessageHandler(client, deadLetterTopic, func(string, map[string]any) error {
+		return nil
+	})
+	msg := &message{topic: "m/d1/c/c1/control/proplet/alive", payload: []byte(`{}`)}
+	handler(client, msg)
+
+	assert.Empty(t, client.published)
+	assert.True(t, msg.acked)
+}
@@ -1,3 +1,22 @@
 package mqtt
 
+import (
+	"log/slog"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
 var TLSConfigFrom = tlsConfigFrom
+
+// NewMessageHandler wraps h the way Subscribe does, publishing through client.
+func NewMessageHandler(client mqtt.Client, deadLetterTopic string, h Handler) mqtt.MessageHandler {
+	ps := &pubsub{
+		client:          client,
+		timeout:         time.Second,
+		logger:          slog.Default(),
+		deadLetterTopic: deadLetterTopic,
+	}
+
+	return ps.mqttHandler(h)
+}
@@ -41,15 +41,14 @@ var (
 	errUnsubscribeTimeout = errors.New("failed to unsubscribe due to timeout reached")
 	errEmptyTopic         = errors.New("empty topic")
 	errEmptyID            = errors.New("empty ID")
-
-	aliveTopicTemplate = "m/%s/c/%s/control/proplet/alive"
 )
 
 type pubsub struct {
-	client  mqtt.Client
-	qos     byte
-	timeout time.Duration
-	logger  *slog.Logger
+	client          mqtt.Client
+	qos             byte
+	timeout         time.Duration
+	logger          *slog.Logger
+	deadLetterTopic string
 }
 
 type Handler func(topic string, msg map[string]any) error
@@ -61,21 +60,25 @@ type PubSub interface {
 	Disconnect(ctx context.Context) error
 }
 
-func NewPubSub(url string, qos byte, id, username, password, domainID, channelID string, timeout time.Duration, logger *slog.Logger, tlsCfg *TLSConfig) (PubSub, error) {
+// NewPubSub connects to the broker at url. When deadLetterTopic is set,
+// messages that cannot be decoded or handled are republished there instead of
+// being left for the broker to redeliver.
+func NewPubSub(url string, qos byte, id, username, password, domainID, channelID, topicPrefix, deadLetterTopic string, timeout time.Duration, logger *slog.Logger, tlsCfg *TLSConfig) (PubSub, error) {
 	if id == "" {
 		return nil, errEmptyID
 	}
 
-	client, err := newClient(url, id, username, password, domainID, channelID, timeout, logger, tlsCfg)
+	client, err := newClient(url, id, username, password, domainID, channelID, topicPrefix, timeout, logger, tlsCfg)
 	if err != nil {
 		return nil, err
 	}
 
 	return &pubsub{
-		client:  client,
-		qos:     qos,
-		timeout: timeout,
-		logger:  logger,
+		client:          client,
+		qos:             qos,
+		timeout:         timeout,
+		logger:          logger,
+		deadLetterTopic: deadLetterTopic,
 	}, nil
 }
 
@@ -211,7 +214,7 @@ func tlsConfigFrom(cfg *TLSConfig) (*tls.Config, error) {
 	return tlsCfg, nil
 }
 
-func newClient(address, id, username, password, domainID, channelID string, timeout time.Duration, logger *slog.Logger, tlsCfg *TLSConfig) (mqtt.Client, error) {
+func newClient(address, id, username, password, domainID, channelID, topicPrefix string, timeout time.Duration, logger *slog.Logger, tlsCfg *TLSConfig) (mqtt.Client, error) {
 	opts := mqtt.NewClientOptions().
 		AddBroker(address).
 		SetClientID(id).
@@ -245,7 +248,7 @@ func newClient(address, id, username, password, domainID, channelID string, time
 	}
 
 	if channelID != "" {
-		topic := fmt.Sprintf(aliveTopicTemplate, domainID, channelID)
+		topic := BaseTopic(topicPrefix, domainID, channelID) + aliveTopicPath
 		lwtPayload, err := json.Marshal(lwtMessage{Status: "offline", PropletID: id})
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal LWT payload: %w", err)
@@ -297,6 +300,7 @@ func (ps *pubsub) mqttHandler(h Handler) mqtt.MessageHandler {
 		var msg map[string]any
 		if err := json.Unmarshal(m.Payload(), &msg); err != nil {
 			ps.logger.Warn(fmt.Sprintf("Failed to unmarshal received message: %s", err))
+			ps.deadLetter(m, err)
 			// Ack malformed messages; redelivery cannot fix a bad payload.
 			m.Ack()
 
@@ -305,10 +309,29 @@ func (ps *pubsub) mqttHandler(h Handler) mqtt.MessageHandler {
 
 		if err := h(m.Topic(), msg); err != nil {
 			ps.logger.Warn(fmt.Sprintf("Failed to handle MQTT message: %s", err))
-			// Do not ack on handler error so the broker can redeliver for transient failures.
+			// Without a dead-letter topic, do not ack so the broker can
+			// redeliver for transient failures.
+			if ps.deadLetter(m, err) {
+				m.Ack()
+			}
+
 			return
 		}
 
 		m.Ack()
 	}
 }
+
+// deadLetter republishes m with its error and reports whether it succeeded.
+func (ps *pubsub) deadLetter(m mqtt.Message, cause error) bool {
+	if ps.deadLetterTopic == "" || m.Topic() == ps.deadLetterTopic {
+		return false
+	}
+	if err := ps.DeadLetter(context.Background(), m.Topic(), m.Payload(), cause); err != nil {
+		ps.logger.Warn(fmt.Sprintf("Failed to dead-letter MQTT message: %s", err))
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,68 @@
+package mqtt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultTopicPrefix is the leading topic segment used when no prefix is
+// configured.
+const DefaultTopicPrefix = "m"
+
+const aliveTopicPath = "/control/proplet/alive"
+
+// BaseTopic returns "<prefix>/<domainID>/c/<channelID>", the root of a
+// deployment's control plane. Deployments sharing a broker pick distinct
+// prefixes to keep their traffic apart; an empty prefix means
+// DefaultTopicPrefix.
+func BaseTopic(prefix, domainID, channelID string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		prefix = DefaultTopicPrefix
+	}
+
+	return fmt.Sprintf("%s/%s/c/%s", prefix, domainID, channelID)
+}
+
+// SplitTopic splits a topic name or filter into its levels. Empty levels are
+// kept, so "a//b" has three levels. An empty topic has none.
+func SplitTopic(topic string) []string {
+	if topic == "" {
+		return nil
+	}
+
+	return strings.Split(topic, "/")
+}
+
+// TopicMatch reports whether topic matches the subscription filter pattern
+// following the MQTT rules: "+" matches exactly one level, "#" matches any
+// number of levels including the parent and must be the last level, and both
+// must occupy a whole level. Topics starting with "$" are not matched by a
+// leading wildcard. Malformed patterns and topics containing wildcards never
+// match.
+func TopicMatch(topic, pattern string) bool {
+	if topic == "" || pattern == "" || strings.ContainsAny(topic, "+#") {
+		return false
+	}
+
+	levels := SplitTopic(topic)
+	filter := SplitTopic(pattern)
+	if strings.HasPrefix(topic, "$") && (filter[0] == "+" || filter[0] == "#") {
+		return false
+	}
+
+	for i, f := range filter {
+		switch {
+		case f == "#":
+			return i == len(filter)-1
+		case strings.ContainsAny(f, "+#") && f != "+":
+			return false
+		case i >= len(levels):
+			return false
+		case f != "+" && f != levels[i]:
+			return false
+		}
+	}
+
+	return len(levels) == len(filter)
+}
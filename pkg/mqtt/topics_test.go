@@ -0,0 +1,122 @@
+package mqtt_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/mqtt"
+)
+
+func TestBaseTopic(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc   string
+		prefix string
+		want   string
+	}{
+		{desc: "empty prefix uses default", prefix: "", want: "m/d1/c/c1"},
+		{desc: "custom prefix", prefix: "staging", want: "staging/d1/c/c1"},
+		{desc: "surrounding slashes are trimmed", prefix: "/tenant-a/", want: "tenant-a/d1/c/c1"},
+		{desc: "nested prefix", prefix: "org/team", want: "org/team/d1/c/c1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if got := mqtt.BaseTopic(tc.prefix, "d1", "c1"); got != tc.want {
+				t.Fatalf("expected %q got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSplitTopic(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		topic string
+		want  []string
+	}{
+		{topic: "", want: nil},
+		{topic: "a", want: []string{"a"}},
+		{topic: "a/b/c", want: []string{"a", "b", "c"}},
+		{topic: "/a", want: []string{"", "a"}},
+		{topic: "a//b", want: []string{"a", "", "b"}},
+		{topic: "a/", want: []string{"a", ""}},
+		{topic: "/", want: []string{"", ""}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.topic, func(t *testing.T) {
+			t.Parallel()
+
+			if got := mqtt.SplitTopic(tc.topic); !slices.Equal(got, tc.want) {
+				t.Fatalf("expected %q got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTopicMatch(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc    string
+		topic   string
+		pattern string
+		match   bool
+	}{
+		{desc: "exact", topic: "sport/tennis", pattern: "sport/tennis", match: true},
+		{desc: "exact mismatch", topic: "sport/tennis", pattern: "sport/golf", match: false},
+		{desc: "case sensitive", topic: "Sport", pattern: "sport", match: false},
+		{desc: "longer topic", topic: "sport/tennis/player1", pattern: "sport/tennis", match: false},
+		{desc: "shorter topic", topic: "sport", pattern: "sport/tennis", match: false},
+
+		{desc: "hash matches everything", topic: "sport/tennis/player1", pattern: "#", match: true},
+		{desc: "hash matches children", topic: "sport/tennis/player1/ranking", pattern: "sport/tennis/player1/#", match: true},
+		{desc: "hash matches parent", topic: "sport/tennis/player1", pattern: "sport/tennis/player1/#", match: true},
+		{desc: "hash matches empty child", topic: "sport/", pattern: "sport/#", match: true},
+		{desc: "hash requires prefix", topic: "music/rock", pattern: "sport/#", match: false},
+		{desc: "hash not last", topic: "sport/tennis/player1", pattern: "sport/#/player1", match: false},
+		{desc: "hash sharing level", topic: "sport/tennis", pattern: "sport/tennis#", match: false},
+		{desc: "hash sharing level with suffix", topic: "sport/tennis", pattern: "sport#", match: false},
+
+		{desc: "plus matches one level", topic: "sport/tennis/player1", pattern: "sport/tennis/+", match: true},
+		{desc: "plus does not match two levels", topic: "sport/tennis/player1/ranking", pattern: "sport/tennis/+", match: false},
+		{desc: "plus does not match parent", topic: "sport/tennis", pattern: "sport/tennis/+", match: false},
+		{desc: "plus in the middle", topic: "sport/tennis/player1", pattern: "sport/+/player1", match: true},
+		{desc: "plus alone", topic: "sport", pattern: "+", match: true},
+		{desc: "plus alone multi level", topic: "sport/tennis", pattern: "+", match: false},
+		{desc: "plus matches empty level", topic: "/finance", pattern: "+/+", match: true},
+		{desc: "plus with leading slash", topic: "/finance", pattern: "/+", match: true},
+		{desc: "plus then hash", topic: "sport/tennis/player1", pattern: "+/tennis/#", match: true},
+		{desc: "plus sharing level", topic: "sport/tennis", pattern: "sport+", match: false},
+		{desc: "plus sharing level after separator", topic: "sport/tennis", pattern: "sport/ten+", match: false},
+
+		{desc: "dollar topic not matched by hash", topic: "$SYS/broker", pattern: "#", match: false},
+		{desc: "dollar topic not matched by leading plus", topic: "$SYS/broker", pattern: "+/broker", match: false},
+		{desc: "dollar topic matched explicitly", topic: "$SYS/broker", pattern: "$SYS/#", match: true},
+		{desc: "dollar topic matched with inner plus", topic: "$SYS/broker", pattern: "$SYS/+", match: true},
+
+		{desc: "empty topic", topic: "", pattern: "#", match: false},
+		{desc: "empty pattern", topic: "sport", pattern: "", match: false},
+		{desc: "wildcard in topic", topic: "sport/+", pattern: "sport/+", match: false},
+		{desc: "hash in topic", topic: "sport/#", pattern: "#", match: false},
+
+		{desc: "manager control topic", topic: "m/d1/c/c1/control/proplet/alive", pattern: "m/d1/c/c1/#", match: true},
+		{desc: "other channel", topic: "m/d1/c/c2/control/proplet/alive", pattern: "m/d1/c/c1/#", match: false},
+		{desc: "any proplet results", topic: "m/d1/c/c1/control/proplet/results", pattern: "m/+/c/+/control/proplet/results", match: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if got := mqtt.TopicMatch(tc.topic, tc.pattern); got != tc.match {
+				t.Fatalf("TopicMatch(%q, %q): expected %v got %v", tc.topic, tc.pattern, tc.match, got)
+			}
+		})
+	}
+}
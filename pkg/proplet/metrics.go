@@ -1,6 +1,10 @@
 package proplet
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 type CPUMetrics struct {
 	UserSeconds   float64 `json:"user_seconds"`
@@ -40,7 +44,20 @@ type AggregatedMetrics struct {
 	SampleCount    int     `json:"sample_count"`
 }
 
+// Named monitoring profiles. A task may set only MonitoringProfile.Name and
+// have the rest filled from the preset.
+const (
+	MonitoringOff      = "off"
+	MonitoringBasic    = "basic"
+	MonitoringDetailed = "detailed"
+)
+
+var ErrUnknownMonitoringProfile = errors.New("unknown monitoring profile")
+
+// MonitoringProfile controls how a proplet samples a task's process metrics
+// and how often it publishes them. Interval is sent as nanoseconds.
 type MonitoringProfile struct {
+	Name                   string        `json:"name,omitempty"`
 	Enabled                bool          `json:"enabled"`
 	Interval               time.Duration `json:"interval"`
 	CollectCPU             bool          `json:"collect_cpu"`
@@ -52,3 +69,50 @@ type MonitoringProfile struct {
 	RetainHistory          bool          `json:"retain_history"`
 	HistorySize            int           `json:"history_size"`
 }
+
+// NamedMonitoringProfile returns the preset for name: off disables task
+// monitoring, basic samples CPU and memory every 30s, and detailed samples
+// everything every 5s.
+func NamedMonitoringProfile(name string) (MonitoringProfile, error) {
+	switch name {
+	case MonitoringOff:
+		return MonitoringProfile{Name: name}, nil
+	case MonitoringBasic:
+		return MonitoringProfile{
+			Name:          name,
+			Enabled:       true,
+			Interval:      30 * time.Second,
+			CollectCPU:    true,
+			CollectMemory: true,
+			ExportToMQTT:  true,
+			RetainHistory: true,
+			HistorySize:   20,
+		}, nil
+	case MonitoringDetailed:
+		return MonitoringProfile{
+			Name:                   name,
+			Enabled:                true,
+			Interval:               5 * time.Second,
+			CollectCPU:             true,
+			CollectMemory:          true,
+			CollectDiskIO:          true,
+			CollectThreads:         true,
+			CollectFileDescriptors: true,
+			ExportToMQTT:           true,
+			RetainHistory:          true,
+			HistorySize:            200,
+		}, nil
+	default:
+		return MonitoringProfile{}, fmt.Errorf("%w: %q", ErrUnknownMonitoringProfile, name)
+	}
+}
+
+// Resolve returns the preset named by p.Name, or p itself when it has no
+// name.
+func (p MonitoringProfile) Resolve() (MonitoringProfile, error) {
+	if p.Name == "" {
+		return p, nil
+	}
+
+	return NamedMonitoringProfile(p.Name)
+}
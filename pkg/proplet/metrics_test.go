@@ -0,0 +1,83 @@
+package proplet_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamedMonitoringProfile(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc     string
+		name     string
+		enabled  bool
+		interval time.Duration
+		err      error
+	}{
+		{
+			desc: "off disables monitoring",
+			name: proplet.MonitoringOff,
+		},
+		{
+			desc:     "basic samples every 30s",
+			name:     proplet.MonitoringBasic,
+			enabled:  true,
+			interval: 30 * time.Second,
+		},
+		{
+			desc:     "detailed samples every 5s",
+			name:     proplet.MonitoringDetailed,
+			enabled:  true,
+			interval: 5 * time.Second,
+		},
+		{
+			desc: "unknown profile",
+			name: "verbose",
+			err:  proplet.ErrUnknownMonitoringProfile,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			profile, err := proplet.MonitoringProfile{Name: tc.name}.Resolve()
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.name, profile.Name)
+			assert.Equal(t, tc.enabled, profile.Enabled)
+			assert.Equal(t, tc.interval, profile.Interval)
+			assert.Equal(t, tc.enabled, profile.ExportToMQTT)
+		})
+	}
+}
+
+func TestCustomMonitoringProfileUnchanged(t *testing.T) {
+	t.Parallel()
+
+	custom := proplet.MonitoringProfile{Enabled: true, Interval: time.Minute, CollectCPU: true}
+	profile, err := custom.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, custom, profile)
+}
+
+func TestMonitoringProfileIntervalIsNanoseconds(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(proplet.MonitoringProfile{Interval: 5 * time.Second})
+	require.NoError(t, err)
+
+	var raw map[string]any
+	require.NoError(t, json.Unmarshal(data, &raw))
+	assert.InDelta(t, 5e9, raw["interval"], 0)
+	assert.NotContains(t, raw, "name")
+}
@@ -60,13 +60,39 @@ type PropletMetadata struct {
 	WasmRuntime      string   `json:"wasm_runtime,omitempty"`
 }
 
+// BreakerState is a proplet's task failure circuit breaker. While it is open
+// the manager sends the proplet no new tasks.
+type BreakerState struct {
+	Open           bool       `json:"open"`
+	RecentResults  int        `json:"recent_results"`
+	RecentFailures int        `json:"recent_failures"`
+	OpenUntil      *time.Time `json:"open_until,omitempty"`
+}
+
+// GroupLabel is the label a task's group is matched against.
+const GroupLabel = "group"
+
 type Proplet struct {
-	ID           string          `json:"id"`
-	Name         string          `json:"name"`
-	TaskCount    uint64          `json:"task_count"`
-	Alive        bool            `json:"alive"`
-	AliveHistory []time.Time     `json:"alive_at"`
-	Metadata     PropletMetadata `json:"metadata"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Namespace    string            `json:"namespace,omitempty"`
+	TaskCount    uint64            `json:"task_count"`
+	Alive        bool              `json:"alive"`
+	AliveHistory []time.Time       `json:"alive_at"`
+	Metadata     PropletMetadata   `json:"metadata"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Breaker      *BreakerState     `json:"breaker,omitempty"`
+}
+
+// InGroup reports whether the proplet is labeled as a member of group.
+func (p *Proplet) InGroup(group string) bool {
+	return p.Labels[GroupLabel] == group
+}
+
+// InNamespace reports whether the proplet is in namespace. Every proplet is
+// in the empty namespace.
+func (p *Proplet) InNamespace(namespace string) bool {
+	return namespace == "" || p.Namespace == namespace
 }
 
 func (p *Proplet) SetAlive() {
@@ -89,21 +115,27 @@ type PropletPage struct {
 }
 
 type PropletView struct {
-	ID          string          `json:"id"`
-	Name        string          `json:"name"`
-	TaskCount   uint64          `json:"task_count"`
-	Alive       bool            `json:"alive"`
-	LastAliveAt *time.Time      `json:"last_alive_at,omitempty"`
-	Metadata    PropletMetadata `json:"metadata"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	TaskCount   uint64            `json:"task_count"`
+	Alive       bool              `json:"alive"`
+	LastAliveAt *time.Time        `json:"last_alive_at,omitempty"`
+	Metadata    PropletMetadata   `json:"metadata"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Breaker     *BreakerState     `json:"breaker,omitempty"`
 }
 
 func (p *Proplet) View() PropletView {
 	v := PropletView{
 		ID:        p.ID,
 		Name:      p.Name,
+		Namespace: p.Namespace,
 		TaskCount: p.TaskCount,
 		Alive:     p.Alive,
 		Metadata:  p.Metadata,
+		Labels:    p.Labels,
+		Breaker:   p.Breaker,
 	}
 	if n := len(p.AliveHistory); n > 0 {
 		t := p.AliveHistory[n-1]
@@ -141,9 +173,27 @@ type PropletAliveHistoryPage struct {
 	History []time.Time `json:"history"`
 }
 
+// ChunkRequest asks the proxy for an app's binary. MaxChunkSize is the
+// largest chunk the proplet can receive over its MQTT connection; zero
+// leaves the size to the proxy.
+type ChunkRequest struct {
+	AppName      string `json:"app_name"`
+	MaxChunkSize int    `json:"max_chunk_size,omitempty"`
+}
+
 type ChunkPayload struct {
 	AppName     string `json:"app_name"`
 	ChunkIdx    int    `json:"chunk_idx"`
 	TotalChunks int    `json:"total_chunks"`
 	Data        []byte `json:"data"`
 }
+
+// MissingChunks asks the proxy to resend only the listed chunks of an app
+// whose transfer to a proplet stalled. MaxChunkSize repeats the size from
+// the original ChunkRequest so the indices still line up if the proxy has
+// to fetch the app again.
+type MissingChunks struct {
+	AppName      string `json:"app_name"`
+	Missing      []int  `json:"missing"`
+	MaxChunkSize int    `json:"max_chunk_size,omitempty"`
+}
@@ -12,7 +12,7 @@ func GetReadyTasksByPriority(tasks []task.Task) []task.Task {
 	sorted := make([]task.Task, len(tasks))
 	copy(sorted, tasks)
 
-	slices.SortFunc(sorted, func(a, b task.Task) int {
+	slices.SortStableFunc(sorted, func(a, b task.Task) int {
 		pa, pb := a.Priority, b.Priority
 		if pa == 0 {
 			pa = defaultPriority
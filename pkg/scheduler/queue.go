@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/absmach/propeller/pkg/task"
+)
+
+// Queue holds tasks waiting for proplet capacity. Tasks are handed back
+// highest priority first, ties broken by creation time.
+type Queue struct {
+	mu    sync.Mutex
+	tasks []task.Task
+}
+
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+// Push adds t to the queue, replacing any queued task with the same ID.
+func (q *Queue) Push(t task.Task) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i := range q.tasks {
+		if q.tasks[i].ID == t.ID {
+			q.tasks[i] = t
+
+			return
+		}
+	}
+	q.tasks = append(q.tasks, t)
+}
+
+func (q *Queue) Remove(taskID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i := range q.tasks {
+		if q.tasks[i].ID == taskID {
+			q.tasks = append(q.tasks[:i], q.tasks[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.tasks)
+}
+
+// Drain empties the queue and returns its tasks in scheduling order.
+func (q *Queue) Drain() []task.Task {
+	q.mu.Lock()
+	tasks := q.tasks
+	q.tasks = nil
+	q.mu.Unlock()
+
+	return GetReadyTasksByPriority(tasks)
+}
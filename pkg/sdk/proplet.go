@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -35,10 +36,59 @@ type PropletPage struct {
 	Proplets []Proplet `json:"proplets"`
 }
 
-func (sdk *propSDK) GetPropletAliveHistory(id string, offset, limit uint64) (proplet.PropletAliveHistoryPage, error) {
+// PropletMetrics is one resource usage sample reported by a proplet.
+type PropletMetrics struct {
+	PropletID string                `json:"proplet_id"`
+	Namespace string                `json:"namespace"`
+	Timestamp time.Time             `json:"timestamp"`
+	CPU       proplet.CPUMetrics    `json:"cpu_metrics"`
+	Memory    proplet.MemoryMetrics `json:"memory_metrics"`
+}
+
+// PropletMetricsPage mirrors the manager proplet metrics response.
+type PropletMetricsPage struct {
+	Offset  uint64           `json:"offset"`
+	Limit   uint64           `json:"limit"`
+	Total   uint64           `json:"total"`
+	Metrics []PropletMetrics `json:"metrics"`
+}
+
+func (sdk *propSDK) GetProplet(ctx context.Context, id string) (proplet.PropletView, error) {
+	reqURL := sdk.managerURL + propletsEndpoint + "/" + id
+
+	body, err := sdk.processRequest(ctx, http.MethodGet, reqURL, nil, http.StatusOK)
+	if err != nil {
+		return proplet.PropletView{}, err
+	}
+
+	var p proplet.PropletView
+	if err := json.Unmarshal(body, &p); err != nil {
+		return proplet.PropletView{}, err
+	}
+
+	return p, nil
+}
+
+func (sdk *propSDK) GetPropletMetrics(ctx context.Context, id string, offset, limit uint64) (PropletMetricsPage, error) {
+	reqURL := fmt.Sprintf("%s%s/%s/metrics?offset=%d&limit=%d", sdk.managerURL, propletsEndpoint, id, offset, limit)
+
+	body, err := sdk.processRequest(ctx, http.MethodGet, reqURL, nil, http.StatusOK)
+	if err != nil {
+		return PropletMetricsPage{}, err
+	}
+
+	var page PropletMetricsPage
+	if err := json.Unmarshal(body, &page); err != nil {
+		return PropletMetricsPage{}, err
+	}
+
+	return page, nil
+}
+
+func (sdk *propSDK) GetPropletAliveHistory(ctx context.Context, id string, offset, limit uint64) (proplet.PropletAliveHistoryPage, error) {
 	reqURL := fmt.Sprintf("%s%s/%s/alive-history?offset=%d&limit=%d", sdk.managerURL, propletsEndpoint, id, offset, limit)
 
-	body, err := sdk.processRequest(http.MethodGet, reqURL, nil, http.StatusOK)
+	body, err := sdk.processRequest(ctx, http.MethodGet, reqURL, nil, http.StatusOK)
 	if err != nil {
 		return proplet.PropletAliveHistoryPage{}, err
 	}
@@ -51,10 +101,10 @@ func (sdk *propSDK) GetPropletAliveHistory(id string, offset, limit uint64) (pro
 	return page, nil
 }
 
-func (sdk *propSDK) GetPropletSDF(id string) (sdf.Document, error) {
+func (sdk *propSDK) GetPropletSDF(ctx context.Context, id string) (sdf.Document, error) {
 	reqURL := sdk.managerURL + propletsEndpoint + "/" + id + "/sdf"
 
-	body, err := sdk.processRequest(http.MethodGet, reqURL, nil, http.StatusOK)
+	body, err := sdk.processRequest(ctx, http.MethodGet, reqURL, nil, http.StatusOK)
 	if err != nil {
 		return sdf.Document{}, err
 	}
@@ -67,7 +117,7 @@ func (sdk *propSDK) GetPropletSDF(id string) (sdf.Document, error) {
 	return doc, nil
 }
 
-func (sdk *propSDK) ListProplets(offset, limit uint64, status string) (PropletPage, error) {
+func (sdk *propSDK) ListProplets(ctx context.Context, offset, limit uint64, status string) (PropletPage, error) {
 	params := make([]string, 0)
 	if offset > 0 {
 		params = append(params, fmt.Sprintf("offset=%d", offset))
@@ -84,7 +134,7 @@ func (sdk *propSDK) ListProplets(offset, limit uint64, status string) (PropletPa
 	}
 	reqURL := sdk.managerURL + propletsEndpoint + query
 
-	body, err := sdk.processRequest(http.MethodGet, reqURL, nil, http.StatusOK)
+	body, err := sdk.processRequest(ctx, http.MethodGet, reqURL, nil, http.StatusOK)
 	if err != nil {
 		return PropletPage{}, err
 	}
@@ -97,10 +147,10 @@ func (sdk *propSDK) ListProplets(offset, limit uint64, status string) (PropletPa
 	return pp, nil
 }
 
-func (sdk *propSDK) DeleteProplet(id string) error {
+func (sdk *propSDK) DeleteProplet(ctx context.Context, id string) error {
 	reqURL := sdk.managerURL + propletsEndpoint + "/" + id
 
-	if _, err := sdk.processRequest(http.MethodDelete, reqURL, nil, http.StatusNoContent); err != nil {
+	if _, err := sdk.processRequest(ctx, http.MethodDelete, reqURL, nil, http.StatusNoContent); err != nil {
 		return err
 	}
 
@@ -0,0 +1,105 @@
+package sdk_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/absmach/propeller/pkg/sdk"
+)
+
+// managerStub serves body as JSON for requests matching method and path and
+// records the query of the last request.
+func managerStub(t *testing.T, path string, body any) (*httptest.Server, *string) {
+	t.Helper()
+
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != path {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", sdk.CTJSON)
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &query
+}
+
+func TestGetProplet(t *testing.T) {
+	t.Parallel()
+
+	want := proplet.PropletView{ID: "proplet-1", Name: "edge-1", TaskCount: 2, Alive: true}
+	srv, _ := managerStub(t, "/proplets/proplet-1", want)
+	s := sdk.NewSDK(sdk.Config{ManagerURL: srv.URL})
+
+	got, err := s.GetProplet(context.Background(), "proplet-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ID != want.ID || got.Name != want.Name || got.TaskCount != want.TaskCount || !got.Alive {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if _, err := s.GetProplet(context.Background(), "missing"); err == nil {
+		t.Error("expected an error for an unknown proplet")
+	}
+}
+
+func TestGetPropletMetrics(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := sdk.PropletMetricsPage{
+		Offset: 5,
+		Limit:  2,
+		Total:  7,
+		Metrics: []sdk.PropletMetrics{
+			{PropletID: "proplet-1", Timestamp: ts, CPU: proplet.CPUMetrics{Percent: 42.5}},
+		},
+	}
+	srv, query := managerStub(t, "/proplets/proplet-1/metrics", want)
+	s := sdk.NewSDK(sdk.Config{ManagerURL: srv.URL})
+
+	got, err := s.GetPropletMetrics(context.Background(), "proplet-1", 5, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *query != "offset=5&limit=2" {
+		t.Errorf("unexpected query %q", *query)
+	}
+	if got.Total != 7 || len(got.Metrics) != 1 {
+		t.Fatalf("unexpected page: %+v", got)
+	}
+	if m := got.Metrics[0]; m.PropletID != "proplet-1" || !m.Timestamp.Equal(ts) || m.CPU.Percent != 42.5 {
+		t.Errorf("unexpected metrics: %+v", m)
+	}
+}
+
+func TestListProplets(t *testing.T) {
+	t.Parallel()
+
+	want := sdk.PropletPage{Total: 1, Limit: 10, Proplets: []sdk.Proplet{{ID: "proplet-1", Alive: true}}}
+	srv, query := managerStub(t, "/proplets", want)
+	s := sdk.NewSDK(sdk.Config{ManagerURL: srv.URL})
+
+	got, err := s.ListProplets(context.Background(), 0, 10, proplet.Active)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *query != "limit=10&status=active" {
+		t.Errorf("unexpected query %q", *query)
+	}
+	if got.Total != 1 || len(got.Proplets) != 1 || got.Proplets[0].ID != "proplet-1" {
+		t.Errorf("unexpected page: %+v", got)
+	}
+}
@@ -2,10 +2,12 @@ package sdk
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/absmach/propeller/pkg/proplet"
 	"github.com/absmach/propeller/pkg/sdf"
@@ -14,6 +16,10 @@ import (
 
 const CTJSON string = "application/json"
 
+// retryBackoff is the wait before the first retry; it doubles on each
+// further attempt.
+const retryBackoff = 100 * time.Millisecond
+
 type PageMetadata struct {
 	Offset   uint64        `json:"offset"`
 	Limit    uint64        `json:"limit"`
@@ -27,23 +33,23 @@ type SDK interface {
 	//  task := sdk.Task{
 	//    Name:	 "John Doe"
 	//  }
-	//  task, _ := sdk.CreateTask(task)
+	//  task, _ := sdk.CreateTask(ctx, task)
 	//  fmt.Println(task)
-	CreateTask(task Task) (Task, error)
+	CreateTask(ctx context.Context, task Task) (Task, error)
 
 	// GetTask gets a task by id.
 	//
 	// example:
-	//  task, _ := sdk.GetTask("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	//  task, _ := sdk.GetTask(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
 	//  fmt.Println(task)
-	GetTask(id string) (Task, error)
+	GetTask(ctx context.Context, id string) (Task, error)
 
 	// ListTasks lists tasks with optional metadata filtering.
 	//
 	// example:
-	//  taskPage, _ := sdk.ListTasks(sdk.PageMetadata{Offset: 0, Limit: 10})
+	//  taskPage, _ := sdk.ListTasks(ctx, sdk.PageMetadata{Offset: 0, Limit: 10})
 	//  fmt.Println(taskPage)
-	ListTasks(pm PageMetadata) (TaskPage, error)
+	ListTasks(ctx context.Context, pm PageMetadata) (TaskPage, error)
 
 	// UpdateTask updates a task.
 	//
@@ -51,30 +57,30 @@ type SDK interface {
 	//  task := sdk.Task{
 	//    Name:	 "John Doe"
 	//  }
-	//  task, _ := sdk.UpdateTask(task)
+	//  task, _ := sdk.UpdateTask(ctx, task)
 	//  fmt.Println(task)
-	UpdateTask(task Task) (Task, error)
+	UpdateTask(ctx context.Context, task Task) (Task, error)
 
 	// DeleteTask deletes a task.
 	//
 	// example:
-	//  task, _ := sdk.DeleteTask("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	//  task, _ := sdk.DeleteTask(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
 	//  fmt.Println(task)
-	DeleteTask(id string) error
+	DeleteTask(ctx context.Context, id string) error
 
 	// StartTask starts a task.
 	//
 	// example:
-	//  task, _ := sdk.StartTask("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	//  task, _ := sdk.StartTask(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
 	//  fmt.Println(task)
-	StartTask(id string) error
+	StartTask(ctx context.Context, id string) error
 
 	// StopTask stops a task.
 	//
 	// example:
-	//  task, _ := sdk.StopTask("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	//  task, _ := sdk.StopTask(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
 	//  fmt.Println(task)
-	StopTask(id string) error
+	StopTask(ctx context.Context, id string) error
 
 	// CreateJob creates a new job with multiple tasks.
 	//
@@ -84,78 +90,107 @@ type SDK interface {
 	//    Tasks: []sdk.Task{...},
 	//    ExecutionMode: "parallel",
 	//  }
-	//  job, _ := sdk.CreateJob(req)
-	CreateJob(req JobRequest) (JobResponse, error)
+	//  job, _ := sdk.CreateJob(ctx, req)
+	CreateJob(ctx context.Context, req JobRequest) (JobResponse, error)
 
 	// GetJob gets a job by id.
 	//
 	// example:
-	//  job, _ := sdk.GetJob("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
-	GetJob(jobID string) (JobResponse, error)
+	//  job, _ := sdk.GetJob(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	GetJob(ctx context.Context, jobID string) (JobResponse, error)
 
 	// ListJobs lists jobs with optional status filter.
 	// Status can be "pending", "running", "completed", "failed", or "" (all).
 	//
 	// example:
-	//  jobPage, _ := sdk.ListJobs(0, 10, "")
-	//  jobPage, _ := sdk.ListJobs(0, 10, "running")
-	ListJobs(offset uint64, limit uint64, status string) (JobPage, error)
+	//  jobPage, _ := sdk.ListJobs(ctx, 0, 10, "")
+	//  jobPage, _ := sdk.ListJobs(ctx, 0, 10, "running")
+	ListJobs(ctx context.Context, offset uint64, limit uint64, status string) (JobPage, error)
 
 	// StartJob starts a job.
 	//
 	// example:
-	//  _ := sdk.StartJob("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
-	StartJob(jobID string) error
+	//  _ := sdk.StartJob(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	StartJob(ctx context.Context, jobID string) error
 
 	// StopJob stops a job.
 	//
 	// example:
-	//  _ := sdk.StopJob("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
-	StopJob(jobID string) error
+	//  _ := sdk.StopJob(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	StopJob(ctx context.Context, jobID string) error
 
 	// GetPropletAliveHistory returns the paginated heartbeat history for a proplet.
 	//
 	// example:
-	//  page, _ := sdk.GetPropletAliveHistory("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040", 0, 10)
+	//  page, _ := sdk.GetPropletAliveHistory(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040", 0, 10)
+	//  fmt.Println(page)
+	GetPropletAliveHistory(ctx context.Context, id string, offset, limit uint64) (proplet.PropletAliveHistoryPage, error)
+
+	// GetProplet gets a proplet by id.
+	//
+	// example:
+	//  p, _ := sdk.GetProplet(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	//  fmt.Println(p)
+	GetProplet(ctx context.Context, id string) (proplet.PropletView, error)
+
+	// GetPropletMetrics returns the paginated CPU and memory samples a
+	// proplet has reported.
+	//
+	// example:
+	//  page, _ := sdk.GetPropletMetrics(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040", 0, 10)
 	//  fmt.Println(page)
-	GetPropletAliveHistory(id string, offset, limit uint64) (proplet.PropletAliveHistoryPage, error)
+	GetPropletMetrics(ctx context.Context, id string, offset, limit uint64) (PropletMetricsPage, error)
 
 	// ListProplets returns a paginated list of proplets, optionally filtered by status.
 	//
 	// example:
-	//  page, _ := sdk.ListProplets(0, 10, "")
+	//  page, _ := sdk.ListProplets(ctx, 0, 10, "")
 	//  fmt.Println(page)
-	ListProplets(offset, limit uint64, status string) (PropletPage, error)
+	ListProplets(ctx context.Context, offset, limit uint64, status string) (PropletPage, error)
 
 	// GetPropletSDF returns the SDF description of a proplet.
 	//
 	// example:
-	//  doc, _ := sdk.GetPropletSDF("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	//  doc, _ := sdk.GetPropletSDF(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
 	//  fmt.Println(doc)
-	GetPropletSDF(id string) (sdf.Document, error)
+	GetPropletSDF(ctx context.Context, id string) (sdf.Document, error)
 
 	// DeleteProplet deletes a proplet by id.
 	//
 	// example:
-	//  err := sdk.DeleteProplet("b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
+	//  err := sdk.DeleteProplet(ctx, "b1d10738-c5d7-4ff1-8f4d-b9328ce6f040")
 	//  fmt.Println(err)
-	DeleteProplet(id string) error
+	DeleteProplet(ctx context.Context, id string) error
 }
 
 type propSDK struct {
 	managerURL string
+	token      string
+	retries    uint
 	client     *http.Client
 }
 
+// Config configures the HTTP client every SDK method shares.
 type Config struct {
 	ManagerURL      string
 	TLSVerification bool
+	// Token, when set, is sent as a bearer token with every request.
+	Token string
+	// Timeout bounds each attempt of a request. Zero means no timeout.
+	Timeout time.Duration
+	// Retries is how many more times a GET, PUT or DELETE request answered
+	// with a 5xx status is sent before its error is returned. POST requests
+	// are never retried, as they are not idempotent.
+	Retries uint
 }
 
 func NewSDK(cfg Config) SDK {
 	return &propSDK{
 		managerURL: cfg.ManagerURL,
+		token:      cfg.Token,
+		retries:    cfg.Retries,
 		client: &http.Client{
+			Timeout: cfg.Timeout,
 			Transport: &http.Transport{
 				TLSClientConfig: &tls.Config{
 					InsecureSkipVerify: !cfg.TLSVerification,
@@ -165,28 +200,67 @@ func NewSDK(cfg Config) SDK {
 	}
 }
 
-func (sdk *propSDK) processRequest(method, reqURL string, data []byte, expectedRespCode int) ([]byte, error) {
-	req, err := http.NewRequest(method, reqURL, bytes.NewReader(data))
+func (sdk *propSDK) processRequest(ctx context.Context, method, reqURL string, data []byte, expectedRespCode int) ([]byte, error) {
+	backoff := retryBackoff
+	for attempt := uint(0); ; attempt++ {
+		body, code, err := sdk.send(ctx, method, reqURL, data)
+		if err != nil {
+			return []byte{}, err
+		}
+		if code >= http.StatusInternalServerError && attempt < sdk.retries && idempotent(method) {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+
+				return []byte{}, ctx.Err()
+			case <-timer.C:
+			}
+			backoff *= 2
+
+			continue
+		}
+		if code != expectedRespCode {
+			return []byte{}, fmt.Errorf("unexpected response code: %d", code)
+		}
+
+		return body, nil
+	}
+}
+
+// idempotent reports whether a request with method can be sent again
+// without risking a repeated side effect, such as a second task being
+// created or started.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func (sdk *propSDK) send(ctx context.Context, method, reqURL string, data []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(data))
 	if err != nil {
-		return []byte{}, err
+		return nil, 0, err
 	}
 
 	req.Header.Add("Content-Type", CTJSON)
+	if sdk.token != "" {
+		req.Header.Set("Authorization", "Bearer "+sdk.token)
+	}
 
 	resp, err := sdk.client.Do(req)
 	if err != nil {
-		return []byte{}, err
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return []byte{}, err
-	}
-
-	if resp.StatusCode != expectedRespCode {
-		return []byte{}, fmt.Errorf("unexpected response code: %d", resp.StatusCode)
+		return nil, 0, err
 	}
 
-	return body, nil
+	return body, resp.StatusCode, nil
 }
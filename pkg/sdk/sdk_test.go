@@ -0,0 +1,152 @@
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/pkg/sdk"
+)
+
+// flakyServer answers the first failures requests with status and the rest
+// with 200 OK and an empty JSON object, recording the Authorization header of
+// the last request.
+func flakyServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32, *atomic.Value) {
+	t.Helper()
+
+	var calls atomic.Int32
+	var auth atomic.Value
+	auth.Store("")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls, &auth
+}
+
+func TestSDKBearerToken(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		desc  string
+		token string
+		want  string
+	}{
+		{desc: "token set", token: "secret", want: "Bearer secret"},
+		{desc: "no token", token: "", want: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srv, _, auth := flakyServer(t, 0, 0)
+			s := sdk.NewSDK(sdk.Config{ManagerURL: srv.URL, Token: tc.token})
+			if err := s.StartTask(context.Background(), "task-1"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := auth.Load().(string); got != tc.want {
+				t.Errorf("expected Authorization %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSDKRetries(t *testing.T) {
+	t.Parallel()
+
+	getTask := func(s sdk.SDK) error {
+		_, err := s.GetTask(context.Background(), "task-1")
+
+		return err
+	}
+	startTask := func(s sdk.SDK) error {
+		return s.StartTask(context.Background(), "task-1")
+	}
+
+	cases := []struct {
+		desc      string
+		call      func(sdk.SDK) error
+		failures  int32
+		status    int
+		retries   uint
+		wantCalls int32
+		wantErr   bool
+	}{
+		{desc: "recovers within retries", call: getTask, failures: 2, status: http.StatusServiceUnavailable, retries: 2, wantCalls: 3},
+		{desc: "gives up after retries", call: getTask, failures: 5, status: http.StatusInternalServerError, retries: 2, wantCalls: 3, wantErr: true},
+		{desc: "no retries by default", call: getTask, failures: 1, status: http.StatusBadGateway, wantCalls: 1, wantErr: true},
+		{desc: "client errors are not retried", call: getTask, failures: 1, status: http.StatusNotFound, retries: 2, wantCalls: 1, wantErr: true},
+		{desc: "POST requests are not retried", call: startTask, failures: 1, status: http.StatusServiceUnavailable, retries: 2, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srv, calls, auth := flakyServer(t, tc.failures, tc.status)
+			s := sdk.NewSDK(sdk.Config{ManagerURL: srv.URL, Token: "secret", Retries: tc.retries})
+			err := tc.call(s)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if got := calls.Load(); got != tc.wantCalls {
+				t.Errorf("expected %d requests, got %d", tc.wantCalls, got)
+			}
+			if got := auth.Load().(string); got != "Bearer secret" {
+				t.Errorf("retried request lost the token: %q", got)
+			}
+		})
+	}
+}
+
+func TestSDKRetryBackoffCanceled(t *testing.T) {
+	t.Parallel()
+
+	srv, calls, _ := flakyServer(t, 100, http.StatusServiceUnavailable)
+	s := sdk.NewSDK(sdk.Config{ManagerURL: srv.URL, Retries: 20})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := s.GetTask(ctx, "task-1")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("canceled request kept backing off for %s", elapsed)
+	}
+	if got := calls.Load(); got > 2 {
+		t.Errorf("expected at most 2 requests before the deadline, got %d", got)
+	}
+}
+
+func TestSDKTimeout(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	s := sdk.NewSDK(sdk.Config{ManagerURL: srv.URL, Timeout: 50 * time.Millisecond})
+	if err := s.StartTask(context.Background(), "task-1"); err == nil {
+		t.Fatal("expected the request to time out")
+	}
+}
@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -35,7 +36,7 @@ type TaskPage struct {
 	Tasks  []Task `json:"tasks"`
 }
 
-func (sdk *propSDK) CreateTask(task Task) (Task, error) {
+func (sdk *propSDK) CreateTask(ctx context.Context, task Task) (Task, error) {
 	data, err := json.Marshal(task)
 	if err != nil {
 		return Task{}, err
@@ -43,7 +44,7 @@ func (sdk *propSDK) CreateTask(task Task) (Task, error) {
 
 	reqURL := sdk.managerURL + tasksEndpoint
 
-	body, err := sdk.processRequest(http.MethodPost, reqURL, data, http.StatusCreated)
+	body, err := sdk.processRequest(ctx, http.MethodPost, reqURL, data, http.StatusCreated)
 	if err != nil {
 		return Task{}, err
 	}
@@ -56,10 +57,10 @@ func (sdk *propSDK) CreateTask(task Task) (Task, error) {
 	return t, nil
 }
 
-func (sdk *propSDK) GetTask(id string) (Task, error) {
+func (sdk *propSDK) GetTask(ctx context.Context, id string) (Task, error) {
 	reqURL := sdk.managerURL + tasksEndpoint + "/" + id
 
-	body, err := sdk.processRequest(http.MethodGet, reqURL, nil, http.StatusOK)
+	body, err := sdk.processRequest(ctx, http.MethodGet, reqURL, nil, http.StatusOK)
 	if err != nil {
 		return Task{}, err
 	}
@@ -72,7 +73,7 @@ func (sdk *propSDK) GetTask(id string) (Task, error) {
 	return t, nil
 }
 
-func (sdk *propSDK) ListTasks(pm PageMetadata) (TaskPage, error) {
+func (sdk *propSDK) ListTasks(ctx context.Context, pm PageMetadata) (TaskPage, error) {
 	queries := make([]string, 0)
 	if pm.Offset > 0 {
 		queries = append(queries, fmt.Sprintf("offset=%d", pm.Offset))
@@ -93,7 +94,7 @@ func (sdk *propSDK) ListTasks(pm PageMetadata) (TaskPage, error) {
 	}
 	reqURL := sdk.managerURL + tasksEndpoint + query
 
-	body, err := sdk.processRequest(http.MethodGet, reqURL, nil, http.StatusOK)
+	body, err := sdk.processRequest(ctx, http.MethodGet, reqURL, nil, http.StatusOK)
 	if err != nil {
 		return TaskPage{}, err
 	}
@@ -106,14 +107,14 @@ func (sdk *propSDK) ListTasks(pm PageMetadata) (TaskPage, error) {
 	return t, nil
 }
 
-func (sdk *propSDK) UpdateTask(task Task) (Task, error) {
+func (sdk *propSDK) UpdateTask(ctx context.Context, task Task) (Task, error) {
 	data, err := json.Marshal(task)
 	if err != nil {
 		return Task{}, err
 	}
 	reqURL := sdk.managerURL + tasksEndpoint + "/" + task.ID
 
-	body, err := sdk.processRequest(http.MethodPut, reqURL, data, http.StatusOK)
+	body, err := sdk.processRequest(ctx, http.MethodPut, reqURL, data, http.StatusOK)
 	if err != nil {
 		return Task{}, err
 	}
@@ -126,30 +127,30 @@ func (sdk *propSDK) UpdateTask(task Task) (Task, error) {
 	return t, nil
 }
 
-func (sdk *propSDK) DeleteTask(id string) error {
+func (sdk *propSDK) DeleteTask(ctx context.Context, id string) error {
 	reqURL := sdk.managerURL + tasksEndpoint + "/" + id
 
-	if _, err := sdk.processRequest(http.MethodDelete, reqURL, nil, http.StatusNoContent); err != nil {
+	if _, err := sdk.processRequest(ctx, http.MethodDelete, reqURL, nil, http.StatusNoContent); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (sdk *propSDK) StartTask(id string) error {
+func (sdk *propSDK) StartTask(ctx context.Context, id string) error {
 	reqURL := fmt.Sprintf("%s/tasks/%s/start", sdk.managerURL, id)
 
-	if _, err := sdk.processRequest(http.MethodPost, reqURL, nil, http.StatusOK); err != nil {
+	if _, err := sdk.processRequest(ctx, http.MethodPost, reqURL, nil, http.StatusOK); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (sdk *propSDK) StopTask(id string) error {
+func (sdk *propSDK) StopTask(ctx context.Context, id string) error {
 	reqURL := fmt.Sprintf("%s/tasks/%s/stop", sdk.managerURL, id)
 
-	if _, err := sdk.processRequest(http.MethodPost, reqURL, nil, http.StatusOK); err != nil {
+	if _, err := sdk.processRequest(ctx, http.MethodPost, reqURL, nil, http.StatusOK); err != nil {
 		return err
 	}
 
@@ -186,7 +187,7 @@ type JobResponse struct {
 	Tasks []Task `json:"tasks"`
 }
 
-func (sdk *propSDK) CreateJob(req JobRequest) (JobResponse, error) {
+func (sdk *propSDK) CreateJob(ctx context.Context, req JobRequest) (JobResponse, error) {
 	data, err := json.Marshal(req)
 	if err != nil {
 		return JobResponse{}, err
@@ -194,7 +195,7 @@ func (sdk *propSDK) CreateJob(req JobRequest) (JobResponse, error) {
 
 	reqURL := sdk.managerURL + jobsEndpoint
 
-	body, err := sdk.processRequest(http.MethodPost, reqURL, data, http.StatusCreated)
+	body, err := sdk.processRequest(ctx, http.MethodPost, reqURL, data, http.StatusCreated)
 	if err != nil {
 		return JobResponse{}, err
 	}
@@ -207,10 +208,10 @@ func (sdk *propSDK) CreateJob(req JobRequest) (JobResponse, error) {
 	return jr, nil
 }
 
-func (sdk *propSDK) GetJob(jobID string) (JobResponse, error) {
+func (sdk *propSDK) GetJob(ctx context.Context, jobID string) (JobResponse, error) {
 	reqURL := sdk.managerURL + jobsEndpoint + "/" + jobID
 
-	body, err := sdk.processRequest(http.MethodGet, reqURL, nil, http.StatusOK)
+	body, err := sdk.processRequest(ctx, http.MethodGet, reqURL, nil, http.StatusOK)
 	if err != nil {
 		return JobResponse{}, err
 	}
@@ -223,7 +224,7 @@ func (sdk *propSDK) GetJob(jobID string) (JobResponse, error) {
 	return jr, nil
 }
 
-func (sdk *propSDK) ListJobs(offset, limit uint64, status string) (JobPage, error) {
+func (sdk *propSDK) ListJobs(ctx context.Context, offset, limit uint64, status string) (JobPage, error) {
 	params := make([]string, 0)
 	if offset > 0 {
 		params = append(params, fmt.Sprintf("offset=%d", offset))
@@ -240,7 +241,7 @@ func (sdk *propSDK) ListJobs(offset, limit uint64, status string) (JobPage, erro
 	}
 	reqURL := sdk.managerURL + jobsEndpoint + query
 
-	body, err := sdk.processRequest(http.MethodGet, reqURL, nil, http.StatusOK)
+	body, err := sdk.processRequest(ctx, http.MethodGet, reqURL, nil, http.StatusOK)
 	if err != nil {
 		return JobPage{}, err
 	}
@@ -253,20 +254,20 @@ func (sdk *propSDK) ListJobs(offset, limit uint64, status string) (JobPage, erro
 	return jp, nil
 }
 
-func (sdk *propSDK) StartJob(jobID string) error {
+func (sdk *propSDK) StartJob(ctx context.Context, jobID string) error {
 	reqURL := fmt.Sprintf("%s/jobs/%s/start", sdk.managerURL, jobID)
 
-	if _, err := sdk.processRequest(http.MethodPost, reqURL, nil, http.StatusOK); err != nil {
+	if _, err := sdk.processRequest(ctx, http.MethodPost, reqURL, nil, http.StatusOK); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (sdk *propSDK) StopJob(jobID string) error {
+func (sdk *propSDK) StopJob(ctx context.Context, jobID string) error {
 	reqURL := fmt.Sprintf("%s/jobs/%s/stop", sdk.managerURL, jobID)
 
-	if _, err := sdk.processRequest(http.MethodPost, reqURL, nil, http.StatusOK); err != nil {
+	if _, err := sdk.processRequest(ctx, http.MethodPost, reqURL, nil, http.StatusOK); err != nil {
 		return err
 	}
 
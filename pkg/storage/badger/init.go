@@ -45,6 +45,7 @@ type TaskRepository interface {
 	Get(ctx context.Context, id string) (task.Task, error)
 	Update(ctx context.Context, t task.Task) error
 	List(ctx context.Context, filter task.Metadata, offset, limit uint64) ([]task.Task, uint64, error)
+	Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error)
 	ListByWorkflowID(ctx context.Context, workflowID string) ([]task.Task, error)
 	ListByJobID(ctx context.Context, jobID string) ([]task.Task, error)
 	Delete(ctx context.Context, id string) error
@@ -73,6 +74,11 @@ type TaskPropletRepository interface {
 	Delete(ctx context.Context, taskID string) error
 }
 
+type RoundRepository interface {
+	Reserve(ctx context.Context, roundID, propletID string) (bool, error)
+	Release(ctx context.Context, roundID, propletID string) error
+}
+
 type MetricsRepository interface {
 	CreateTaskMetrics(ctx context.Context, m TaskMetrics) error
 	CreatePropletMetrics(ctx context.Context, m PropletMetrics) error
@@ -86,6 +92,7 @@ type Repositories struct {
 	TaskProplets TaskPropletRepository
 	Jobs         JobRepository
 	Metrics      MetricsRepository
+	Rounds       RoundRepository
 }
 
 func NewRepositories(db *Database) *Repositories {
@@ -95,6 +102,7 @@ func NewRepositories(db *Database) *Repositories {
 		TaskProplets: NewTaskPropletRepository(db),
 		Jobs:         NewJobRepository(db),
 		Metrics:      NewMetricsRepository(db),
+		Rounds:       NewRoundRepository(db),
 	}
 }
 
@@ -0,0 +1,49 @@
+package badger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+type roundRepo struct {
+	db *Database
+}
+
+func NewRoundRepository(db *Database) RoundRepository {
+	return &roundRepo{db: db}
+}
+
+func (r *roundRepo) Reserve(ctx context.Context, roundID, propletID string) (bool, error) {
+	key := []byte("round:" + roundID + ":" + propletID)
+	reserved := false
+	err := r.db.updateTxn(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		switch {
+		case err == nil:
+			return nil
+		case !errors.Is(err, badger.ErrKeyNotFound):
+			return err
+		}
+		reserved = true
+
+		return txn.Set(key, []byte(propletID))
+	})
+	switch {
+	case errors.Is(err, badger.ErrConflict):
+		// A concurrent transaction reserved the key first.
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("%w: %w", ErrCreate, err)
+	}
+
+	return reserved, nil
+}
+
+func (r *roundRepo) Release(ctx context.Context, roundID, propletID string) error {
+	key := []byte("round:" + roundID + ":" + propletID)
+
+	return r.db.delete(key)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"slices"
 
 	"github.com/absmach/propeller/pkg/task"
@@ -101,6 +102,22 @@ func (r *taskRepo) List(ctx context.Context, filter task.Metadata, offset, limit
 	return r.listByMetadata(ctx, filter, offset, limit)
 }
 
+func (r *taskRepo) Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error) {
+	var tasks []task.Task
+	var err error
+	if len(q.Metadata) == 0 {
+		tasks, err = r.listBy(ctx, q.Match)
+	} else {
+		tasks, _, err = r.listByMetadata(ctx, q.Metadata, 0, math.MaxUint64)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	page, total := q.Apply(tasks)
+
+	return page, total, nil
+}
+
 func (r *taskRepo) ListByWorkflowID(ctx context.Context, workflowID string) ([]task.Task, error) {
 	return r.listBy(ctx, func(t task.Task) bool {
 		return t.WorkflowID == workflowID
@@ -14,4 +14,5 @@ var (
 	ErrTaskNotFound    = errors.New("task not found")
 	ErrPropletNotFound = errors.New("proplet not found")
 	ErrNotFound        = errors.New("not found")
+	ErrUnsupported     = errors.New("unsupported storage type")
 )
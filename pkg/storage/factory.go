@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/absmach/propeller/pkg/job"
@@ -13,8 +14,12 @@ import (
 	"github.com/absmach/propeller/pkg/storage/postgres"
 	"github.com/absmach/propeller/pkg/storage/sqlite"
 	"github.com/absmach/propeller/pkg/task"
+	"github.com/go-kit/kit/metrics"
 )
 
+// StorageTypes lists the backends MANAGER_STORAGE_TYPE can select.
+var StorageTypes = []string{"memory", "sqlite", "badger", "postgres"}
+
 type Config struct {
 	Type string `env:"MANAGER_STORAGE_TYPE" envDefault:"memory"`
 
@@ -28,6 +33,11 @@ type Config struct {
 	SQLitePath string `env:"MANAGER_SQLITE_PATH" envDefault:"./propeller.db"`
 
 	BadgerPath string `env:"MANAGER_BADGER_PATH" envDefault:"./data/badger"`
+
+	// Latency and Errors, when both are set, instrument every repository
+	// operation of the selected backend; see MakeMetrics.
+	Latency metrics.Histogram
+	Errors  metrics.Counter
 }
 
 type Repositories struct {
@@ -36,24 +46,34 @@ type Repositories struct {
 	TaskProplets TaskPropletRepository
 	Jobs         JobRepository
 	Metrics      MetricsRepository
+	Rounds       RoundRepository
 	// Closer closes the underlying persistent storage connection.
 	// It is nil for the in-memory backend.
 	Closer io.Closer
 }
 
 func NewRepositories(cfg Config) (*Repositories, error) {
+	var (
+		repos *Repositories
+		err   error
+	)
 	switch cfg.Type {
 	case "postgres":
-		return newPostgresRepositories(cfg)
+		repos, err = newPostgresRepositories(cfg)
 	case "sqlite":
-		return newSQLiteRepositories(cfg)
+		repos, err = newSQLiteRepositories(cfg)
 	case "badger":
-		return newBadgerRepositories(cfg)
+		repos, err = newBadgerRepositories(cfg)
 	case "memory":
-		return newMemoryRepositories()
+		repos, err = newMemoryRepositories()
 	default:
-		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Type)
+		return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnsupported, cfg.Type, strings.Join(StorageTypes, ", "))
 	}
+	if err != nil || cfg.Latency == nil || cfg.Errors == nil {
+		return repos, err
+	}
+
+	return Metrics(cfg.Latency, cfg.Errors, repos), nil
 }
 
 func newPostgresRepositories(cfg Config) (*Repositories, error) {
@@ -77,6 +97,7 @@ func newPostgresRepositories(cfg Config) (*Repositories, error) {
 		TaskProplets: &postgresTaskPropletAdapter{repo: repos.TaskProplets},
 		Jobs:         &postgresJobAdapter{repo: repos.Jobs},
 		Metrics:      &postgresMetricsAdapter{repo: repos.Metrics},
+		Rounds:       repos.Rounds,
 		Closer:       db,
 	}, nil
 }
@@ -95,6 +116,7 @@ func newSQLiteRepositories(cfg Config) (*Repositories, error) {
 		TaskProplets: &sqliteTaskPropletAdapter{repo: repos.TaskProplets},
 		Jobs:         &sqliteJobAdapter{repo: repos.Jobs},
 		Metrics:      &sqliteMetricsAdapter{repo: repos.Metrics},
+		Rounds:       repos.Rounds,
 		Closer:       db,
 	}, nil
 }
@@ -113,6 +135,7 @@ func newBadgerRepositories(cfg Config) (*Repositories, error) {
 		TaskProplets: &badgerTaskPropletAdapter{repo: repos.TaskProplets},
 		Jobs:         &badgerJobAdapter{repo: repos.Jobs},
 		Metrics:      &badgerMetricsAdapter{repo: repos.Metrics},
+		Rounds:       repos.Rounds,
 		Closer:       db,
 	}, nil
 }
@@ -123,6 +146,7 @@ func newMemoryRepositories() (*Repositories, error) {
 	taskPropletStorage := NewInMemoryStorage()
 	jobStorage := NewInMemoryStorage()
 	metricsStorage := NewInMemoryStorage()
+	roundStorage := NewInMemoryStorage()
 
 	return &Repositories{
 		Tasks:        newMemoryTaskRepository(taskStorage),
@@ -130,6 +154,7 @@ func newMemoryRepositories() (*Repositories, error) {
 		TaskProplets: newMemoryTaskPropletRepository(taskPropletStorage),
 		Jobs:         newMemoryJobRepository(jobStorage),
 		Metrics:      newMemoryMetricsRepository(metricsStorage),
+		Rounds:       newMemoryRoundRepository(roundStorage),
 	}, nil
 }
 
@@ -153,6 +178,10 @@ func (a *postgresTaskAdapter) List(ctx context.Context, filter task.Metadata, of
 	return a.repo.List(ctx, filter, offset, limit)
 }
 
+func (a *postgresTaskAdapter) Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error) {
+	return a.repo.Query(ctx, q)
+}
+
 func (a *postgresTaskAdapter) ListByWorkflowID(ctx context.Context, workflowID string) ([]task.Task, error) {
 	return a.repo.ListByWorkflowID(ctx, workflowID)
 }
@@ -309,6 +338,10 @@ func (a *sqliteTaskAdapter) List(ctx context.Context, filter task.Metadata, offs
 	return a.repo.List(ctx, filter, offset, limit)
 }
 
+func (a *sqliteTaskAdapter) Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error) {
+	return a.repo.Query(ctx, q)
+}
+
 func (a *sqliteTaskAdapter) ListByWorkflowID(ctx context.Context, workflowID string) ([]task.Task, error) {
 	return a.repo.ListByWorkflowID(ctx, workflowID)
 }
@@ -465,6 +498,10 @@ func (a *badgerTaskAdapter) List(ctx context.Context, filter task.Metadata, offs
 	return a.repo.List(ctx, filter, offset, limit)
 }
 
+func (a *badgerTaskAdapter) Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error) {
+	return a.repo.Query(ctx, q)
+}
+
 func (a *badgerTaskAdapter) ListByWorkflowID(ctx context.Context, workflowID string) ([]task.Task, error) {
 	return a.repo.ListByWorkflowID(ctx, workflowID)
 }
@@ -0,0 +1,76 @@
+package storage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRepositories(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cases := []struct {
+		desc       string
+		cfg        storage.Config
+		persistent bool
+	}{
+		{
+			desc: "memory",
+			cfg:  storage.Config{Type: "memory"},
+		},
+		{
+			desc:       "sqlite",
+			cfg:        storage.Config{Type: "sqlite", SQLitePath: filepath.Join(dir, "propeller.db")},
+			persistent: true,
+		},
+		{
+			desc:       "badger",
+			cfg:        storage.Config{Type: "badger", BadgerPath: filepath.Join(dir, "badger")},
+			persistent: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			repos, err := storage.NewRepositories(tc.cfg)
+			require.NoError(t, err)
+			if tc.persistent {
+				require.NotNil(t, repos.Closer)
+				t.Cleanup(func() { assert.NoError(t, repos.Closer.Close()) })
+			} else {
+				assert.Nil(t, repos.Closer)
+			}
+			require.NotNil(t, repos.Tasks)
+			require.NotNil(t, repos.Proplets)
+			require.NotNil(t, repos.TaskProplets)
+			require.NotNil(t, repos.Jobs)
+			require.NotNil(t, repos.Metrics)
+
+			ctx := context.Background()
+			created, err := repos.Tasks.Create(ctx, task.Task{ID: uuid.NewString(), Name: "probe"})
+			require.NoError(t, err)
+			got, err := repos.Tasks.Get(ctx, created.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "probe", got.Name)
+		})
+	}
+}
+
+func TestNewRepositoriesUnknownType(t *testing.T) {
+	t.Parallel()
+
+	for _, typ := range []string{"", "bolt", "Memory"} {
+		_, err := storage.NewRepositories(storage.Config{Type: typ})
+		require.ErrorIs(t, err, storage.ErrUnsupported, typ)
+		assert.Contains(t, err.Error(), "memory, sqlite, badger, postgres")
+	}
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"slices"
@@ -79,6 +80,16 @@ func (r *memoryTaskRepo) List(ctx context.Context, filter task.Metadata, offset,
 	return r.listByMetadata(ctx, filter, offset, limit)
 }
 
+func (r *memoryTaskRepo) Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error) {
+	tasks, _, err := r.List(ctx, q.Metadata, 0, math.MaxUint64)
+	if err != nil {
+		return nil, 0, err
+	}
+	page, total := q.Apply(tasks)
+
+	return page, total, nil
+}
+
 func (r *memoryTaskRepo) ListByWorkflowID(ctx context.Context, workflowID string) ([]task.Task, error) {
 	data, _, err := r.storage.List(ctx, 0, math.MaxUint64)
 	if err != nil {
@@ -389,6 +400,27 @@ func (r *memoryTaskPropletRepo) Delete(ctx context.Context, taskID string) error
 	return r.storage.Delete(ctx, taskID)
 }
 
+type memoryRoundRepo struct {
+	storage Storage
+}
+
+func newMemoryRoundRepository(s Storage) RoundRepository {
+	return &memoryRoundRepo{storage: s}
+}
+
+func (r *memoryRoundRepo) Reserve(ctx context.Context, roundID, propletID string) (bool, error) {
+	err := r.storage.Create(ctx, roundID+":"+propletID, propletID)
+	if errors.Is(err, pkgerrors.ErrEntityExists) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (r *memoryRoundRepo) Release(ctx context.Context, roundID, propletID string) error {
+	return r.storage.Delete(ctx, roundID+":"+propletID)
+}
+
 type memoryJobRepo struct {
 	storage Storage
 }
@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/absmach/propeller/pkg/job"
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/go-kit/kit/metrics"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// MakeMetrics returns the Prometheus storage operation latency histogram, in
+// seconds, and error counter, both labelled by "repository" and "operation".
+func MakeMetrics(namespace string) (metrics.Histogram, metrics.Counter) {
+	latency := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of storage operations in seconds.",
+		Buckets:   stdprometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"repository", "operation"})
+	errs := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "storage",
+		Name:      "operation_errors_total",
+		Help:      "Number of failed storage operations.",
+	}, []string{"repository", "operation"})
+
+	return latency, errs
+}
+
+// Metrics wraps every repository in repos so that each operation observes
+// its latency and counts its failures. It works the same for every backend.
+func Metrics(latency metrics.Histogram, errs metrics.Counter, repos *Repositories) *Repositories {
+	observer := func(repository string) observer {
+		return observer{repository: repository, latency: latency, errors: errs}
+	}
+
+	return &Repositories{
+		Tasks:        &taskMetrics{observer: observer("tasks"), repo: repos.Tasks},
+		Proplets:     &propletMetrics{observer: observer("proplets"), repo: repos.Proplets},
+		TaskProplets: &taskPropletMetrics{observer: observer("task_proplets"), repo: repos.TaskProplets},
+		Jobs:         &jobMetrics{observer: observer("jobs"), repo: repos.Jobs},
+		Metrics:      &metricsMetrics{observer: observer("metrics"), repo: repos.Metrics},
+		Rounds:       &roundMetrics{observer: observer("rounds"), repo: repos.Rounds},
+		Closer:       repos.Closer,
+	}
+}
+
+type observer struct {
+	repository string
+	latency    metrics.Histogram
+	errors     metrics.Counter
+}
+
+func (o observer) observe(operation string, begin time.Time, err *error) {
+	o.latency.With("repository", o.repository, "operation", operation).Observe(time.Since(begin).Seconds())
+	if *err != nil {
+		o.errors.With("repository", o.repository, "operation", operation).Add(1)
+	}
+}
+
+var _ TaskRepository = (*taskMetrics)(nil)
+
+type taskMetrics struct {
+	observer
+	repo TaskRepository
+}
+
+func (m *taskMetrics) Create(ctx context.Context, t task.Task) (_ task.Task, err error) {
+	defer m.observe("create", time.Now(), &err)
+
+	return m.repo.Create(ctx, t)
+}
+
+func (m *taskMetrics) Get(ctx context.Context, id string) (_ task.Task, err error) {
+	defer m.observe("get", time.Now(), &err)
+
+	return m.repo.Get(ctx, id)
+}
+
+func (m *taskMetrics) Update(ctx context.Context, t task.Task) (err error) {
+	defer m.observe("update", time.Now(), &err)
+
+	return m.repo.Update(ctx, t)
+}
+
+func (m *taskMetrics) List(ctx context.Context, filter task.Metadata, offset, limit uint64) (_ []task.Task, _ uint64, err error) {
+	defer m.observe("list", time.Now(), &err)
+
+	return m.repo.List(ctx, filter, offset, limit)
+}
+
+func (m *taskMetrics) Query(ctx context.Context, q task.Query) (_ []task.Task, _ uint64, err error) {
+	defer m.observe("query", time.Now(), &err)
+
+	return m.repo.Query(ctx, q)
+}
+
+func (m *taskMetrics) ListByWorkflowID(ctx context.Context, workflowID string) (_ []task.Task, err error) {
+	defer m.observe("list_by_workflow_id", time.Now(), &err)
+
+	return m.repo.ListByWorkflowID(ctx, workflowID)
+}
+
+func (m *taskMetrics) ListByJobID(ctx context.Context, jobID string) (_ []task.Task, err error) {
+	defer m.observe("list_by_job_id", time.Now(), &err)
+
+	return m.repo.ListByJobID(ctx, jobID)
+}
+
+func (m *taskMetrics) Delete(ctx context.Context, id string) (err error) {
+	defer m.observe("delete", time.Now(), &err)
+
+	return m.repo.Delete(ctx, id)
+}
+
+var _ PropletRepository = (*propletMetrics)(nil)
+
+type propletMetrics struct {
+	observer
+	repo PropletRepository
+}
+
+func (m *propletMetrics) Create(ctx context.Context, p proplet.Proplet) (err error) {
+	defer m.observe("create", time.Now(), &err)
+
+	return m.repo.Create(ctx, p)
+}
+
+func (m *propletMetrics) Get(ctx context.Context, id string) (_ proplet.Proplet, err error) {
+	defer m.observe("get", time.Now(), &err)
+
+	return m.repo.Get(ctx, id)
+}
+
+func (m *propletMetrics) Update(ctx context.Context, p proplet.Proplet) (err error) {
+	defer m.observe("update", time.Now(), &err)
+
+	return m.repo.Update(ctx, p)
+}
+
+func (m *propletMetrics) List(ctx context.Context, offset, limit uint64) (_ []proplet.Proplet, _ uint64, err error) {
+	defer m.observe("list", time.Now(), &err)
+
+	return m.repo.List(ctx, offset, limit)
+}
+
+func (m *propletMetrics) ListByAlive(ctx context.Context, offset, limit uint64, alive bool, since time.Time) (_ []proplet.Proplet, _ uint64, err error) {
+	defer m.observe("list_by_alive", time.Now(), &err)
+
+	return m.repo.ListByAlive(ctx, offset, limit, alive, since)
+}
+
+func (m *propletMetrics) Delete(ctx context.Context, id string) (err error) {
+	defer m.observe("delete", time.Now(), &err)
+
+	return m.repo.Delete(ctx, id)
+}
+
+func (m *propletMetrics) GetAliveHistory(ctx context.Context, id string, offset, limit uint64) (_ []time.Time, _ uint64, err error) {
+	defer m.observe("get_alive_history", time.Now(), &err)
+
+	return m.repo.GetAliveHistory(ctx, id, offset, limit)
+}
+
+var _ TaskPropletRepository = (*taskPropletMetrics)(nil)
+
+type taskPropletMetrics struct {
+	observer
+	repo TaskPropletRepository
+}
+
+func (m *taskPropletMetrics) Create(ctx context.Context, taskID, propletID string) (err error) {
+	defer m.observe("create", time.Now(), &err)
+
+	return m.repo.Create(ctx, taskID, propletID)
+}
+
+func (m *taskPropletMetrics) Get(ctx context.Context, taskID string) (_ string, err error) {
+	defer m.observe("get", time.Now(), &err)
+
+	return m.repo.Get(ctx, taskID)
+}
+
+func (m *taskPropletMetrics) Delete(ctx context.Context, taskID string) (err error) {
+	defer m.observe("delete", time.Now(), &err)
+
+	return m.repo.Delete(ctx, taskID)
+}
+
+var _ JobRepository = (*jobMetrics)(nil)
+
+type jobMetrics struct {
+	observer
+	repo JobRepository
+}
+
+func (m *jobMetrics) Create(ctx context.Context, j job.Job) (_ job.Job, err error) {
+	defer m.observe("create", time.Now(), &err)
+
+	return m.repo.Create(ctx, j)
+}
+
+func (m *jobMetrics) Get(ctx context.Context, id string) (_ job.Job, err error) {
+	defer m.observe("get", time.Now(), &err)
+
+	return m.repo.Get(ctx, id)
+}
+
+func (m *jobMetrics) List(ctx context.Context, offset, limit uint64) (_ []job.Job, _ uint64, err error) {
+	defer m.observe("list", time.Now(), &err)
+
+	return m.repo.List(ctx, offset, limit)
+}
+
+func (m *jobMetrics) Delete(ctx context.Context, id string) (err error) {
+	defer m.observe("delete", time.Now(), &err)
+
+	return m.repo.Delete(ctx, id)
+}
+
+var _ MetricsRepository = (*metricsMetrics)(nil)
+
+type metricsMetrics struct {
+	observer
+	repo MetricsRepository
+}
+
+func (m *metricsMetrics) CreateTaskMetrics(ctx context.Context, tm TaskMetrics) (err error) {
+	defer m.observe("create_task_metrics", time.Now(), &err)
+
+	return m.repo.CreateTaskMetrics(ctx, tm)
+}
+
+func (m *metricsMetrics) CreatePropletMetrics(ctx context.Context, pm PropletMetrics) (err error) {
+	defer m.observe("create_proplet_metrics", time.Now(), &err)
+
+	return m.repo.CreatePropletMetrics(ctx, pm)
+}
+
+func (m *metricsMetrics) ListTaskMetrics(ctx context.Context, taskID string, offset, limit uint64) (_ []TaskMetrics, _ uint64, err error) {
+	defer m.observe("list_task_metrics", time.Now(), &err)
+
+	return m.repo.ListTaskMetrics(ctx, taskID, offset, limit)
+}
+
+func (m *metricsMetrics) ListPropletMetrics(ctx context.Context, propletID string, offset, limit uint64) (_ []PropletMetrics, _ uint64, err error) {
+	defer m.observe("list_proplet_metrics", time.Now(), &err)
+
+	return m.repo.ListPropletMetrics(ctx, propletID, offset, limit)
+}
+
+var _ RoundRepository = (*roundMetrics)(nil)
+
+type roundMetrics struct {
+	observer
+	repo RoundRepository
+}
+
+func (m *roundMetrics) Reserve(ctx context.Context, roundID, propletID string) (_ bool, err error) {
+	defer m.observe("reserve", time.Now(), &err)
+
+	return m.repo.Reserve(ctx, roundID, propletID)
+}
+
+func (m *roundMetrics) Release(ctx context.Context, roundID, propletID string) (err error) {
+	defer m.observe("release", time.Now(), &err)
+
+	return m.repo.Release(ctx, roundID, propletID)
+}
@@ -0,0 +1,132 @@
+package storage_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/storage"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/go-kit/kit/metrics"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a metrics.Counter and metrics.Histogram that tallies the
+// values recorded under each "repository.operation" label pair.
+type recorder struct {
+	mu     *sync.Mutex
+	label  string
+	values map[string][]float64
+}
+
+func newRecorder() *recorder {
+	return &recorder{mu: &sync.Mutex{}, values: map[string][]float64{}}
+}
+
+func (r *recorder) With(labelValues ...string) *recorder {
+	next := *r
+	var repository, operation string
+	for i := 0; i+1 < len(labelValues); i += 2 {
+		switch labelValues[i] {
+		case "repository":
+			repository = labelValues[i+1]
+		case "operation":
+			operation = labelValues[i+1]
+		}
+	}
+	next.label = repository + "." + operation
+
+	return &next
+}
+
+func (r *recorder) record(v float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[r.label] = append(r.values[r.label], v)
+}
+
+func (r *recorder) count(operation string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.values[operation])
+}
+
+type counter struct{ *recorder }
+
+func (c counter) With(labelValues ...string) metrics.Counter {
+	return counter{c.recorder.With(labelValues...)}
+}
+
+func (c counter) Add(delta float64) { c.record(delta) }
+
+type histogram struct{ *recorder }
+
+func (h histogram) With(labelValues ...string) metrics.Histogram {
+	return histogram{h.recorder.With(labelValues...)}
+}
+
+func (h histogram) Observe(value float64) { h.record(value) }
+
+func TestMetricsRepositories(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cases := []struct {
+		desc string
+		cfg  storage.Config
+	}{
+		{desc: "memory", cfg: storage.Config{Type: "memory"}},
+		{desc: "sqlite", cfg: storage.Config{Type: "sqlite", SQLitePath: filepath.Join(dir, "propeller.db")}},
+		{desc: "badger", cfg: storage.Config{Type: "badger", BadgerPath: filepath.Join(dir, "badger")}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			latency, errs := newRecorder(), newRecorder()
+			tc.cfg.Latency, tc.cfg.Errors = histogram{latency}, counter{errs}
+			repos, err := storage.NewRepositories(tc.cfg)
+			require.NoError(t, err)
+			if repos.Closer != nil {
+				t.Cleanup(func() { assert.NoError(t, repos.Closer.Close()) })
+			}
+			ctx := context.Background()
+
+			created, err := repos.Tasks.Create(ctx, task.Task{ID: uuid.NewString(), Name: "probe"})
+			require.NoError(t, err)
+			_, err = repos.Tasks.Get(ctx, created.ID)
+			require.NoError(t, err)
+			_, err = repos.Tasks.Get(ctx, uuid.NewString())
+			require.Error(t, err)
+			_, _, err = repos.Tasks.List(ctx, nil, 0, 10)
+			require.NoError(t, err)
+			require.NoError(t, repos.TaskProplets.Create(ctx, created.ID, uuid.NewString()))
+			_, err = repos.Jobs.Get(ctx, uuid.NewString())
+			require.Error(t, err)
+			reserved, err := repos.Rounds.Reserve(ctx, "r1", "p1")
+			require.NoError(t, err)
+			assert.True(t, reserved)
+			require.NoError(t, repos.Tasks.Delete(ctx, created.ID))
+
+			for op, want := range map[string]int{
+				"tasks.create":         1,
+				"tasks.get":            2,
+				"tasks.list":           1,
+				"tasks.delete":         1,
+				"task_proplets.create": 1,
+				"jobs.get":             1,
+				"rounds.reserve":       1,
+			} {
+				assert.Equal(t, want, latency.count(op), "latency observations for %s", op)
+			}
+			for op, want := range map[string]int{"tasks.create": 0, "tasks.get": 1, "jobs.get": 1, "rounds.reserve": 0} {
+				assert.Equal(t, want, errs.count(op), "errors counted for %s", op)
+			}
+		})
+	}
+}
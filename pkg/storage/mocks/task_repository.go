@@ -444,6 +444,77 @@ func (_c *MockTaskRepository_ListByWorkflowID_Call) RunAndReturn(run func(ctx co
 	return _c
 }
 
+// Query provides a mock function for the type MockTaskRepository
+func (_mock *MockTaskRepository) Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error) {
+	ret := _mock.Called(ctx, q)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Query")
+	}
+
+	var r0 []task.Task
+	var r1 uint64
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, task.Query) ([]task.Task, uint64, error)); ok {
+		return returnFunc(ctx, q)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, task.Query) []task.Task); ok {
+		r0 = returnFunc(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]task.Task)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, task.Query) uint64); ok {
+		r1 = returnFunc(ctx, q)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, task.Query) error); ok {
+		r2 = returnFunc(ctx, q)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockTaskRepository_Query_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Query'
+type MockTaskRepository_Query_Call struct {
+	*mock.Call
+}
+
+// Query is a helper method to define mock.On call
+//   - ctx context.Context
+//   - q task.Query
+func (_e *MockTaskRepository_Expecter) Query(ctx interface{}, q interface{}) *MockTaskRepository_Query_Call {
+	return &MockTaskRepository_Query_Call{Call: _e.mock.On("Query", ctx, q)}
+}
+
+func (_c *MockTaskRepository_Query_Call) Run(run func(ctx context.Context, q task.Query)) *MockTaskRepository_Query_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 task.Query
+		if args[1] != nil {
+			arg1 = args[1].(task.Query)
+		}
+		run(arg0, arg1)
+	})
+	return _c
+}
+
+func (_c *MockTaskRepository_Query_Call) Return(tasks []task.Task, v uint64, err error) *MockTaskRepository_Query_Call {
+	_c.Call.Return(tasks, v, err)
+	return _c
+}
+
+func (_c *MockTaskRepository_Query_Call) RunAndReturn(run func(ctx context.Context, q task.Query) ([]task.Task, uint64, error)) *MockTaskRepository_Query_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Update provides a mock function for the type MockTaskRepository
 func (_mock *MockTaskRepository) Update(ctx context.Context, t task.Task) error {
 	ret := _mock.Called(ctx, t)
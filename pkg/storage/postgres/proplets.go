@@ -25,10 +25,12 @@ type dbProplet struct {
 	Alive        bool   `db:"alive"`
 	AliveHistory []byte `db:"alive_history"`
 	Metadata     []byte `db:"metadata"`
+	Labels       []byte `db:"labels"`
+	Namespace    string `db:"namespace"`
 }
 
 func (r *propletRepo) Create(ctx context.Context, p proplet.Proplet) error {
-	query := `INSERT INTO proplets (id, name, task_count, alive, alive_history, metadata) VALUES ($1, $2, $3, $4, $5, $6)`
+	query := `INSERT INTO proplets (id, name, task_count, alive, alive_history, metadata, labels, namespace) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
 	aliveHistory, err := jsonBytes(p.AliveHistory)
 	if err != nil {
@@ -40,7 +42,12 @@ func (r *propletRepo) Create(ctx context.Context, p proplet.Proplet) error {
 		return fmt.Errorf("%w: %w", ErrDBQuery, err)
 	}
 
-	if _, err = r.db.ExecContext(ctx, query, p.ID, p.Name, p.TaskCount, p.Alive, aliveHistory, metadata); err != nil {
+	labels, err := jsonBytes(p.Labels)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
+	if _, err = r.db.ExecContext(ctx, query, p.ID, p.Name, p.TaskCount, p.Alive, aliveHistory, metadata, labels, p.Namespace); err != nil {
 		return fmt.Errorf("%w: %w", ErrCreate, err)
 	}
 
@@ -48,7 +55,7 @@ func (r *propletRepo) Create(ctx context.Context, p proplet.Proplet) error {
 }
 
 func (r *propletRepo) Get(ctx context.Context, id string) (proplet.Proplet, error) {
-	query := `SELECT id, name, task_count, alive, alive_history, metadata FROM proplets WHERE id = $1`
+	query := `SELECT id, name, task_count, alive, alive_history, metadata, labels, namespace FROM proplets WHERE id = $1`
 
 	var dbp dbProplet
 
@@ -64,7 +71,7 @@ func (r *propletRepo) Get(ctx context.Context, id string) (proplet.Proplet, erro
 }
 
 func (r *propletRepo) Update(ctx context.Context, p proplet.Proplet) error {
-	query := `UPDATE proplets SET name = $2, task_count = $3, alive = $4, alive_history = $5, metadata = $6 WHERE id = $1`
+	query := `UPDATE proplets SET name = $2, task_count = $3, alive = $4, alive_history = $5, metadata = $6, labels = $7, namespace = $8 WHERE id = $1`
 
 	aliveHistory, err := jsonBytes(p.AliveHistory)
 	if err != nil {
@@ -76,7 +83,12 @@ func (r *propletRepo) Update(ctx context.Context, p proplet.Proplet) error {
 		return fmt.Errorf("%w: %w", ErrDBQuery, err)
 	}
 
-	if _, err = r.db.ExecContext(ctx, query, p.ID, p.Name, p.TaskCount, p.Alive, aliveHistory, metadata); err != nil {
+	labels, err := jsonBytes(p.Labels)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
+	if _, err = r.db.ExecContext(ctx, query, p.ID, p.Name, p.TaskCount, p.Alive, aliveHistory, metadata, labels, p.Namespace); err != nil {
 		return fmt.Errorf("%w: %w", ErrUpdate, err)
 	}
 
@@ -90,7 +102,7 @@ func (r *propletRepo) List(ctx context.Context, offset, limit uint64) ([]proplet
 		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
 	}
 
-	query := `SELECT id, name, task_count, alive, alive_history, metadata FROM proplets LIMIT $1 OFFSET $2`
+	query := `SELECT id, name, task_count, alive, alive_history, metadata, labels, namespace FROM proplets LIMIT $1 OFFSET $2`
 
 	rows, err := r.db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
@@ -101,7 +113,7 @@ func (r *propletRepo) List(ctx context.Context, offset, limit uint64) ([]proplet
 	proplets := make([]proplet.Proplet, 0)
 	for rows.Next() {
 		var dbp dbProplet
-		if err := rows.Scan(&dbp.ID, &dbp.Name, &dbp.TaskCount, &dbp.Alive, &dbp.AliveHistory, &dbp.Metadata); err != nil {
+		if err := rows.Scan(&dbp.ID, &dbp.Name, &dbp.TaskCount, &dbp.Alive, &dbp.AliveHistory, &dbp.Metadata, &dbp.Labels, &dbp.Namespace); err != nil {
 			return nil, 0, fmt.Errorf("%w: %w", ErrDBScan, err)
 		}
 
@@ -139,7 +151,7 @@ func (r *propletRepo) ListByAlive(ctx context.Context, offset, limit uint64, ali
 		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
 	}
 
-	query := fmt.Sprintf(`SELECT id, name, task_count, alive, alive_history, metadata FROM proplets %s LIMIT $2 OFFSET $3`, whereClause)
+	query := fmt.Sprintf(`SELECT id, name, task_count, alive, alive_history, metadata, labels, namespace FROM proplets %s LIMIT $2 OFFSET $3`, whereClause)
 	rows, err := tx.QueryContext(ctx, query, since, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
@@ -149,7 +161,7 @@ func (r *propletRepo) ListByAlive(ctx context.Context, offset, limit uint64, ali
 	proplets := make([]proplet.Proplet, 0)
 	for rows.Next() {
 		var dbp dbProplet
-		if err := rows.Scan(&dbp.ID, &dbp.Name, &dbp.TaskCount, &dbp.Alive, &dbp.AliveHistory, &dbp.Metadata); err != nil {
+		if err := rows.Scan(&dbp.ID, &dbp.Name, &dbp.TaskCount, &dbp.Alive, &dbp.AliveHistory, &dbp.Metadata, &dbp.Labels, &dbp.Namespace); err != nil {
 			return nil, 0, fmt.Errorf("%w: %w", ErrDBScan, err)
 		}
 		p, err := r.toProplet(dbp)
@@ -197,6 +209,7 @@ func (r *propletRepo) toProplet(dbp dbProplet) (proplet.Proplet, error) {
 		Name:      dbp.Name,
 		TaskCount: dbp.TaskCount,
 		Alive:     dbp.Alive,
+		Namespace: dbp.Namespace,
 	}
 
 	if dbp.AliveHistory != nil {
@@ -211,5 +224,11 @@ func (r *propletRepo) toProplet(dbp dbProplet) (proplet.Proplet, error) {
 		}
 	}
 
+	if dbp.Labels != nil {
+		if err := jsonUnmarshal(dbp.Labels, &p.Labels); err != nil {
+			return proplet.Proplet{}, err
+		}
+	}
+
 	return p, nil
 }
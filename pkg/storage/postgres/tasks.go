@@ -46,15 +46,26 @@ type dbTask struct {
 	Mode              *string       `db:"mode"`
 	Broadcast         bool          `db:"broadcast"`
 	Metadata          []byte        `db:"metadata"`
+	InputsFrom        []byte        `db:"inputs_from"`
+	SecretRefs        []byte        `db:"secret_refs"`
+	RetryPolicy       []byte        `db:"retry_policy"`
+	Attempts          int           `db:"attempts"`
+	MaxInstructions   uint64        `db:"max_instructions"`
+	Group             string        `db:"proplet_group"`
+	Namespace         string        `db:"namespace"`
+	Priority          int           `db:"priority"`
+	QueuedAt          *sql.NullTime `db:"queued_at"`
+	RetryAt           *sql.NullTime `db:"retry_at"`
 }
 
 const taskColumns = `id, name, state, image_url, file, cli_args, inputs, env, daemon, encrypted,
 	kbs_resource_path, proplet_id, results, error, monitoring_profile, start_time, finish_time,
-	created_at, updated_at, workflow_id, job_id, depends_on, run_if, kind, mode, broadcast, metadata`
+	created_at, updated_at, workflow_id, job_id, depends_on, run_if, kind, mode, broadcast, metadata, inputs_from, secret_refs,
+	retry_policy, attempts, max_instructions, proplet_group, namespace, priority, queued_at, retry_at`
 
 func (r *taskRepo) Create(ctx context.Context, t task.Task) (task.Task, error) {
 	query := `INSERT INTO tasks (` + taskColumns + `)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27)`
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37)`
 
 	cliArgs, err := jsonBytes(t.CLIArgs)
 	if err != nil {
@@ -91,6 +102,21 @@ func (r *taskRepo) Create(ctx context.Context, t task.Task) (task.Task, error) {
 		return task.Task{}, fmt.Errorf("%w: %w", ErrDBQuery, err)
 	}
 
+	inputsFrom, err := jsonBytes(t.InputsFrom)
+	if err != nil {
+		return task.Task{}, fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
+	secretRefs, err := jsonBytes(t.SecretRefs)
+	if err != nil {
+		return task.Task{}, fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
+	retryPolicy, err := jsonBytes(t.RetryPolicy)
+	if err != nil {
+		return task.Task{}, fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
 	_, err = r.db.ExecContext(ctx, query,
 		t.ID, t.Name, uint8(t.State),
 		nullString(t.ImageURL),
@@ -115,6 +141,16 @@ func (r *taskRepo) Create(ctx context.Context, t task.Task) (task.Task, error) {
 		nullString(string(t.Mode)),
 		t.Broadcast,
 		metadata,
+		inputsFrom,
+		secretRefs,
+		retryPolicy,
+		t.Attempts,
+		t.MaxInstructions,
+		t.Group,
+		t.Namespace,
+		t.Priority,
+		nullTime(t.QueuedAt),
+		nullTime(t.RetryAt),
 	)
 	if err != nil {
 		return task.Task{}, fmt.Errorf("%w: %w", ErrCreate, err)
@@ -145,7 +181,10 @@ func (r *taskRepo) Update(ctx context.Context, t task.Task) error {
 		env = $8, daemon = $9, encrypted = $10, kbs_resource_path = $11, proplet_id = $12,
 		results = $13, error = $14, monitoring_profile = $15, start_time = $16,
 		finish_time = $17, updated_at = $18, workflow_id = $19, job_id = $20,
-		depends_on = $21, run_if = $22, kind = $23, mode = $24, broadcast = $25, metadata = $26
+		depends_on = $21, run_if = $22, kind = $23, mode = $24, broadcast = $25, metadata = $26,
+		inputs_from = $27, secret_refs = $28, retry_policy = $29, attempts = $30,
+		max_instructions = $31, proplet_group = $32, namespace = $33,
+		priority = $34, queued_at = $35, retry_at = $36
 		WHERE id = $1`
 
 	cliArgs, err := jsonBytes(t.CLIArgs)
@@ -183,6 +222,21 @@ func (r *taskRepo) Update(ctx context.Context, t task.Task) error {
 		return fmt.Errorf("%w: %w", ErrDBQuery, err)
 	}
 
+	inputsFrom, err := jsonBytes(t.InputsFrom)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
+	secretRefs, err := jsonBytes(t.SecretRefs)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
+	retryPolicy, err := jsonBytes(t.RetryPolicy)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
 	res, err := r.db.ExecContext(ctx, query,
 		t.ID, t.Name, uint8(t.State),
 		nullString(t.ImageURL),
@@ -205,6 +259,16 @@ func (r *taskRepo) Update(ctx context.Context, t task.Task) error {
 		nullString(string(t.Mode)),
 		t.Broadcast,
 		metadata,
+		inputsFrom,
+		secretRefs,
+		retryPolicy,
+		t.Attempts,
+		t.MaxInstructions,
+		t.Group,
+		t.Namespace,
+		t.Priority,
+		nullTime(t.QueuedAt),
+		nullTime(t.RetryAt),
 	)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrUpdate, err)
@@ -244,6 +308,74 @@ func (r *taskRepo) List(ctx context.Context, filter task.Metadata, offset, limit
 	return tasks, total, nil
 }
 
+// taskSortColumns maps the task.SortFields a query can sort by to their
+// columns.
+var taskSortColumns = map[string]string{
+	"name":        "name",
+	"state":       "state",
+	"priority":    "priority",
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+	"start_time":  "start_time",
+	"finish_time": "finish_time",
+}
+
+func (r *taskRepo) Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error) {
+	whereClause, args, nextIdx, err := buildPostgresMetadataWhere(q.Metadata)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+	where := func(cond string, arg any) {
+		cond = fmt.Sprintf(cond, nextIdx)
+		if whereClause == "" {
+			whereClause = " WHERE " + cond
+		} else {
+			whereClause += " AND " + cond
+		}
+		args = append(args, arg)
+		nextIdx++
+	}
+	if q.State != nil {
+		where("state = $%d", uint8(*q.State))
+	}
+	switch q.Kind {
+	case "":
+	case task.TaskKindStandard:
+		where("COALESCE(kind, '') IN ('', $%d)", string(q.Kind))
+	default:
+		where("kind = $%d", string(q.Kind))
+	}
+	if q.Mode != "" {
+		where("mode = $%d", string(q.Mode))
+	}
+	if q.PropletID != "" {
+		where("proplet_id = $%d", q.PropletID)
+	}
+
+	var total uint64
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM tasks"+whereClause, args...); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
+	orderBy := " ORDER BY created_at DESC"
+	if column, ok := taskSortColumns[q.Sort]; ok {
+		// NULL times sort first ascending, like zero times in memory.
+		dir := "ASC NULLS FIRST"
+		if q.Desc {
+			dir = "DESC NULLS LAST"
+		}
+		orderBy = " ORDER BY " + column + " " + dir + ", created_at DESC"
+	}
+	query := `SELECT ` + taskColumns + ` FROM tasks` + whereClause + orderBy +
+		fmt.Sprintf(` LIMIT $%d OFFSET $%d`, nextIdx, nextIdx+1)
+	tasks, err := r.scanTasks(ctx, query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
 func buildPostgresMetadataWhere(filter task.Metadata) (clause string, args []any, nextIdx int, err error) {
 	if len(filter) == 0 {
 		return "", nil, 1, nil
@@ -295,7 +427,9 @@ func (r *taskRepo) scanTasks(ctx context.Context, query string, args ...any) ([]
 			&dbt.Results, &dbt.Error, &dbt.MonitoringProfile,
 			&dbt.StartTime, &dbt.FinishTime, &dbt.CreatedAt, &dbt.UpdatedAt,
 			&dbt.WorkflowID, &dbt.JobID, &dbt.DependsOn, &dbt.RunIf,
-			&dbt.Kind, &dbt.Mode, &dbt.Broadcast, &dbt.Metadata,
+			&dbt.Kind, &dbt.Mode, &dbt.Broadcast, &dbt.Metadata, &dbt.InputsFrom, &dbt.SecretRefs,
+			&dbt.RetryPolicy, &dbt.Attempts, &dbt.MaxInstructions, &dbt.Group, &dbt.Namespace,
+			&dbt.Priority, &dbt.QueuedAt, &dbt.RetryAt,
 		); err != nil {
 			return nil, fmt.Errorf("%w: %w", ErrDBScan, err)
 		}
@@ -384,6 +518,26 @@ func (r *taskRepo) toTask(dbt dbTask) (task.Task, error) {
 			return task.Task{}, err
 		}
 	}
+	if err := jsonUnmarshal(dbt.InputsFrom, &t.InputsFrom); err != nil {
+		return task.Task{}, err
+	}
+	if err := jsonUnmarshal(dbt.SecretRefs, &t.SecretRefs); err != nil {
+		return task.Task{}, err
+	}
+	if err := jsonUnmarshal(dbt.RetryPolicy, &t.RetryPolicy); err != nil {
+		return task.Task{}, err
+	}
+	t.Attempts = dbt.Attempts
+	t.MaxInstructions = dbt.MaxInstructions
+	t.Group = dbt.Group
+	t.Namespace = dbt.Namespace
+	t.Priority = dbt.Priority
+	if dbt.QueuedAt != nil && dbt.QueuedAt.Valid {
+		t.QueuedAt = dbt.QueuedAt.Time
+	}
+	if dbt.RetryAt != nil && dbt.RetryAt.Valid {
+		t.RetryAt = dbt.RetryAt.Time
+	}
 
 	return t, nil
 }
@@ -14,6 +14,7 @@ type TaskRepository interface {
 	Get(ctx context.Context, id string) (task.Task, error)
 	Update(ctx context.Context, t task.Task) error
 	List(ctx context.Context, filter task.Metadata, offset, limit uint64) ([]task.Task, uint64, error)
+	Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error)
 	ListByWorkflowID(ctx context.Context, workflowID string) ([]task.Task, error)
 	ListByJobID(ctx context.Context, jobID string) ([]task.Task, error)
 	Delete(ctx context.Context, id string) error
@@ -35,6 +36,14 @@ type TaskPropletRepository interface {
 	Delete(ctx context.Context, taskID string) error
 }
 
+// RoundRepository reserves the launch of a round's participants. Reserve is a
+// conditional insert shared by every manager using the storage: it reports
+// false when the participant is already reserved for the round.
+type RoundRepository interface {
+	Reserve(ctx context.Context, roundID, propletID string) (bool, error)
+	Release(ctx context.Context, roundID, propletID string) error
+}
+
 type JobRepository interface {
 	Create(ctx context.Context, j job.Job) (job.Job, error)
 	Get(ctx context.Context, id string) (job.Job, error)
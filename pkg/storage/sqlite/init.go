@@ -47,6 +47,7 @@ type TaskRepository interface {
 	Get(ctx context.Context, id string) (task.Task, error)
 	Update(ctx context.Context, t task.Task) error
 	List(ctx context.Context, filter task.Metadata, offset, limit uint64) ([]task.Task, uint64, error)
+	Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error)
 	ListByWorkflowID(ctx context.Context, workflowID string) ([]task.Task, error)
 	ListByJobID(ctx context.Context, jobID string) ([]task.Task, error)
 	Delete(ctx context.Context, id string) error
@@ -75,6 +76,11 @@ type TaskPropletRepository interface {
 	Delete(ctx context.Context, taskID string) error
 }
 
+type RoundRepository interface {
+	Reserve(ctx context.Context, roundID, propletID string) (bool, error)
+	Release(ctx context.Context, roundID, propletID string) error
+}
+
 type MetricsRepository interface {
 	CreateTaskMetrics(ctx context.Context, m TaskMetrics) error
 	CreatePropletMetrics(ctx context.Context, m PropletMetrics) error
@@ -88,6 +94,7 @@ type Repositories struct {
 	TaskProplets TaskPropletRepository
 	Jobs         JobRepository
 	Metrics      MetricsRepository
+	Rounds       RoundRepository
 }
 
 func NewRepositories(db *Database) *Repositories {
@@ -97,6 +104,7 @@ func NewRepositories(db *Database) *Repositories {
 		TaskProplets: NewTaskPropletRepository(db),
 		Jobs:         NewJobRepository(db),
 		Metrics:      NewMetricsRepository(db),
+		Rounds:       NewRoundRepository(db),
 	}
 }
 
@@ -263,6 +271,100 @@ func (db *Database) Migrate() error {
 					`ALTER TABLE tasks DROP COLUMN metadata`,
 				},
 			},
+			{
+				Id: "6_add_task_inputs_from",
+				Up: []string{
+					`ALTER TABLE tasks ADD COLUMN inputs_from TEXT`,
+				},
+				Down: []string{
+					`ALTER TABLE tasks DROP COLUMN inputs_from`,
+				},
+			},
+			{
+				Id: "7_add_task_secret_refs",
+				Up: []string{
+					`ALTER TABLE tasks ADD COLUMN secret_refs TEXT`,
+				},
+				Down: []string{
+					`ALTER TABLE tasks DROP COLUMN secret_refs`,
+				},
+			},
+			{
+				Id: "8_add_task_retry_policy",
+				Up: []string{
+					`ALTER TABLE tasks ADD COLUMN retry_policy TEXT`,
+					`ALTER TABLE tasks ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0`,
+				},
+				Down: []string{
+					`ALTER TABLE tasks DROP COLUMN attempts`,
+					`ALTER TABLE tasks DROP COLUMN retry_policy`,
+				},
+			},
+			{
+				Id: "9_add_task_max_instructions",
+				Up: []string{
+					`ALTER TABLE tasks ADD COLUMN max_instructions INTEGER NOT NULL DEFAULT 0`,
+				},
+				Down: []string{
+					`ALTER TABLE tasks DROP COLUMN max_instructions`,
+				},
+			},
+			{
+				Id: "10_add_proplet_labels",
+				Up: []string{
+					`ALTER TABLE proplets ADD COLUMN labels JSONB DEFAULT '{}'`,
+					`ALTER TABLE tasks ADD COLUMN proplet_group TEXT NOT NULL DEFAULT ''`,
+				},
+				Down: []string{
+					`ALTER TABLE tasks DROP COLUMN proplet_group`,
+					`ALTER TABLE proplets DROP COLUMN labels`,
+				},
+			},
+			{
+				Id: "11_add_namespace",
+				Up: []string{
+					`ALTER TABLE proplets ADD COLUMN namespace TEXT NOT NULL DEFAULT ''`,
+					`ALTER TABLE tasks ADD COLUMN namespace TEXT NOT NULL DEFAULT ''`,
+				},
+				Down: []string{
+					`ALTER TABLE tasks DROP COLUMN namespace`,
+					`ALTER TABLE proplets DROP COLUMN namespace`,
+				},
+			},
+			{
+				Id: "12_add_task_priority",
+				Up: []string{
+					`ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0`,
+					`ALTER TABLE tasks ADD COLUMN queued_at TIMESTAMP`,
+				},
+				Down: []string{
+					`ALTER TABLE tasks DROP COLUMN queued_at`,
+					`ALTER TABLE tasks DROP COLUMN priority`,
+				},
+			},
+			{
+				Id: "13_create_round_launches",
+				Up: []string{
+					`CREATE TABLE IF NOT EXISTS round_launches (
+						round_id TEXT NOT NULL,
+						proplet_id TEXT NOT NULL,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						PRIMARY KEY (round_id, proplet_id)
+					)`,
+				},
+				Down: []string{
+					`DROP TABLE IF EXISTS round_launches`,
+				},
+			},
+			{
+				Id: "14_add_task_retry_at",
+				Up: []string{
+					`ALTER TABLE tasks ADD COLUMN retry_at TIMESTAMP`,
+				},
+				Down: []string{
+					`ALTER TABLE tasks DROP COLUMN retry_at`,
+				},
+			},
 		},
 	}
 
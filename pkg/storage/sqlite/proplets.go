@@ -25,10 +25,12 @@ type dbProplet struct {
 	Alive        bool   `db:"alive"`
 	AliveHistory []byte `db:"alive_history"`
 	Metadata     []byte `db:"metadata"`
+	Labels       []byte `db:"labels"`
+	Namespace    string `db:"namespace"`
 }
 
 func (r *propletRepo) Create(ctx context.Context, p proplet.Proplet) error {
-	query := `INSERT INTO proplets (id, name, task_count, alive, alive_history, metadata) VALUES (?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO proplets (id, name, task_count, alive, alive_history, metadata, labels, namespace) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
 	aliveHistory, err := jsonBytes(p.AliveHistory)
 	if err != nil {
@@ -40,7 +42,12 @@ func (r *propletRepo) Create(ctx context.Context, p proplet.Proplet) error {
 		return fmt.Errorf("marshal error: %w", err)
 	}
 
-	_, err = r.db.ExecContext(ctx, query, p.ID, p.Name, p.TaskCount, p.Alive, aliveHistory, metadata)
+	labels, err := jsonBytes(p.Labels)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, query, p.ID, p.Name, p.TaskCount, p.Alive, aliveHistory, metadata, labels, p.Namespace)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrCreate, err)
 	}
@@ -49,7 +56,7 @@ func (r *propletRepo) Create(ctx context.Context, p proplet.Proplet) error {
 }
 
 func (r *propletRepo) Get(ctx context.Context, id string) (proplet.Proplet, error) {
-	query := `SELECT id, name, task_count, alive, alive_history, metadata FROM proplets WHERE id = ?`
+	query := `SELECT id, name, task_count, alive, alive_history, metadata, labels, namespace FROM proplets WHERE id = ?`
 
 	var dbp dbProplet
 	err := r.db.GetContext(ctx, &dbp, query, id)
@@ -65,7 +72,7 @@ func (r *propletRepo) Get(ctx context.Context, id string) (proplet.Proplet, erro
 }
 
 func (r *propletRepo) Update(ctx context.Context, p proplet.Proplet) error {
-	query := `UPDATE proplets SET name = ?, task_count = ?, alive = ?, alive_history = ?, metadata = ? WHERE id = ?`
+	query := `UPDATE proplets SET name = ?, task_count = ?, alive = ?, alive_history = ?, metadata = ?, labels = ?, namespace = ? WHERE id = ?`
 
 	aliveHistory, err := jsonBytes(p.AliveHistory)
 	if err != nil {
@@ -77,7 +84,12 @@ func (r *propletRepo) Update(ctx context.Context, p proplet.Proplet) error {
 		return fmt.Errorf("marshal error: %w", err)
 	}
 
-	if _, err = r.db.ExecContext(ctx, query, p.Name, p.TaskCount, p.Alive, aliveHistory, metadata, p.ID); err != nil {
+	labels, err := jsonBytes(p.Labels)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	if _, err = r.db.ExecContext(ctx, query, p.Name, p.TaskCount, p.Alive, aliveHistory, metadata, labels, p.Namespace, p.ID); err != nil {
 		return fmt.Errorf("%w: %w", ErrUpdate, err)
 	}
 
@@ -91,7 +103,7 @@ func (r *propletRepo) List(ctx context.Context, offset, limit uint64) ([]proplet
 		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
 	}
 
-	query := `SELECT id, name, task_count, alive, alive_history, metadata FROM proplets LIMIT ? OFFSET ?`
+	query := `SELECT id, name, task_count, alive, alive_history, metadata, labels, namespace FROM proplets LIMIT ? OFFSET ?`
 
 	rows, err := r.db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
@@ -102,7 +114,7 @@ func (r *propletRepo) List(ctx context.Context, offset, limit uint64) ([]proplet
 	proplets := make([]proplet.Proplet, 0)
 	for rows.Next() {
 		var dbp dbProplet
-		if err := rows.Scan(&dbp.ID, &dbp.Name, &dbp.TaskCount, &dbp.Alive, &dbp.AliveHistory, &dbp.Metadata); err != nil {
+		if err := rows.Scan(&dbp.ID, &dbp.Name, &dbp.TaskCount, &dbp.Alive, &dbp.AliveHistory, &dbp.Metadata, &dbp.Labels, &dbp.Namespace); err != nil {
 			return nil, 0, fmt.Errorf("%w: %w", ErrDBScan, err)
 		}
 
@@ -144,7 +156,7 @@ func (r *propletRepo) ListByAlive(ctx context.Context, offset, limit uint64, ali
 		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
 	}
 
-	query := `SELECT id, name, task_count, alive, alive_history, metadata FROM proplets ` + whereClause + ` LIMIT ? OFFSET ?`
+	query := `SELECT id, name, task_count, alive, alive_history, metadata, labels, namespace FROM proplets ` + whereClause + ` LIMIT ? OFFSET ?`
 	rows, err := tx.QueryContext(ctx, query, sinceArg, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
@@ -154,7 +166,7 @@ func (r *propletRepo) ListByAlive(ctx context.Context, offset, limit uint64, ali
 	proplets := make([]proplet.Proplet, 0)
 	for rows.Next() {
 		var dbp dbProplet
-		if err := rows.Scan(&dbp.ID, &dbp.Name, &dbp.TaskCount, &dbp.Alive, &dbp.AliveHistory, &dbp.Metadata); err != nil {
+		if err := rows.Scan(&dbp.ID, &dbp.Name, &dbp.TaskCount, &dbp.Alive, &dbp.AliveHistory, &dbp.Metadata, &dbp.Labels, &dbp.Namespace); err != nil {
 			return nil, 0, fmt.Errorf("%w: %w", ErrDBScan, err)
 		}
 		p, err := r.toProplet(dbp)
@@ -202,6 +214,7 @@ func (r *propletRepo) toProplet(dbp dbProplet) (proplet.Proplet, error) {
 		Name:      dbp.Name,
 		TaskCount: dbp.TaskCount,
 		Alive:     dbp.Alive,
+		Namespace: dbp.Namespace,
 	}
 
 	if dbp.AliveHistory != nil {
@@ -216,5 +229,11 @@ func (r *propletRepo) toProplet(dbp dbProplet) (proplet.Proplet, error) {
 		}
 	}
 
+	if dbp.Labels != nil {
+		if err := jsonUnmarshal(dbp.Labels, &p.Labels); err != nil {
+			return proplet.Proplet{}, err
+		}
+	}
+
 	return p, nil
 }
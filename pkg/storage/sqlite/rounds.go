@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+)
+
+type roundRepo struct {
+	db *Database
+}
+
+func NewRoundRepository(db *Database) RoundRepository {
+	return &roundRepo{db: db}
+}
+
+func (r *roundRepo) Reserve(ctx context.Context, roundID, propletID string) (bool, error) {
+	query := `INSERT INTO round_launches (round_id, proplet_id) VALUES (?, ?) ON CONFLICT DO NOTHING`
+
+	res, err := r.db.ExecContext(ctx, query, roundID, propletID)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrCreate, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrCreate, err)
+	}
+
+	return n == 1, nil
+}
+
+func (r *roundRepo) Release(ctx context.Context, roundID, propletID string) error {
+	query := `DELETE FROM round_launches WHERE round_id = ? AND proplet_id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, roundID, propletID); err != nil {
+		return fmt.Errorf("%w: %w", ErrDelete, err)
+	}
+
+	return nil
+}
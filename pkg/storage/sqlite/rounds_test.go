@@ -0,0 +1,57 @@
+package sqlite_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/absmach/propeller/pkg/storage/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundReserve(t *testing.T) {
+	t.Parallel()
+	repo := sqlite.NewRoundRepository(newTestDB(t))
+	ctx := context.Background()
+
+	ok, err := repo.Reserve(ctx, "round-1", "proplet-a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.Reserve(ctx, "round-1", "proplet-a")
+	require.NoError(t, err)
+	assert.False(t, ok, "a reserved participant is not reserved again")
+
+	ok, err = repo.Reserve(ctx, "round-2", "proplet-a")
+	require.NoError(t, err)
+	assert.True(t, ok, "reservations are per round")
+
+	require.NoError(t, repo.Release(ctx, "round-1", "proplet-a"))
+	ok, err = repo.Reserve(ctx, "round-1", "proplet-a")
+	require.NoError(t, err)
+	assert.True(t, ok, "a released participant can be reserved again")
+}
+
+func TestRoundReserveConcurrent(t *testing.T) {
+	t.Parallel()
+	repo := sqlite.NewRoundRepository(newTestDB(t))
+
+	var (
+		wg       sync.WaitGroup
+		reserved atomic.Int32
+	)
+	for range 8 {
+		wg.Go(func() {
+			ok, err := repo.Reserve(context.Background(), "round-1", "proplet-a")
+			assert.NoError(t, err)
+			if ok {
+				reserved.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), reserved.Load())
+}
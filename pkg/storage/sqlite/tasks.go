@@ -51,15 +51,26 @@ type dbTask struct {
 	Mode              *string      `db:"mode"`
 	Broadcast         bool         `db:"broadcast"`
 	Metadata          []byte       `db:"metadata"`
+	InputsFrom        []byte       `db:"inputs_from"`
+	SecretRefs        []byte       `db:"secret_refs"`
+	RetryPolicy       []byte       `db:"retry_policy"`
+	Attempts          int          `db:"attempts"`
+	MaxInstructions   uint64       `db:"max_instructions"`
+	Group             string       `db:"proplet_group"`
+	Namespace         string       `db:"namespace"`
+	Priority          int          `db:"priority"`
+	QueuedAt          sql.NullTime `db:"queued_at"`
+	RetryAt           sql.NullTime `db:"retry_at"`
 }
 
 const taskColumns = `id, name, state, image_url, file, cli_args, inputs, env, daemon, encrypted,
 	kbs_resource_path, proplet_id, results, error, monitoring_profile, start_time, finish_time,
-	created_at, updated_at, workflow_id, job_id, depends_on, run_if, kind, mode, broadcast, metadata`
+	created_at, updated_at, workflow_id, job_id, depends_on, run_if, kind, mode, broadcast, metadata, inputs_from, secret_refs,
+	retry_policy, attempts, max_instructions, proplet_group, namespace, priority, queued_at, retry_at`
 
 func (r *taskRepo) Create(ctx context.Context, t task.Task) (task.Task, error) {
 	query := `INSERT INTO tasks (` + taskColumns + `)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	cliArgs, err := jsonBytes(t.CLIArgs)
 	if err != nil {
@@ -96,6 +107,21 @@ func (r *taskRepo) Create(ctx context.Context, t task.Task) (task.Task, error) {
 		return task.Task{}, fmt.Errorf("marshal error: %w", err)
 	}
 
+	inputsFrom, err := jsonBytes(t.InputsFrom)
+	if err != nil {
+		return task.Task{}, fmt.Errorf("marshal error: %w", err)
+	}
+
+	secretRefs, err := jsonBytes(t.SecretRefs)
+	if err != nil {
+		return task.Task{}, fmt.Errorf("marshal error: %w", err)
+	}
+
+	retryPolicy, err := jsonBytes(t.RetryPolicy)
+	if err != nil {
+		return task.Task{}, fmt.Errorf("marshal error: %w", err)
+	}
+
 	_, err = r.db.ExecContext(ctx, query,
 		t.ID, t.Name, uint8(t.State), nullString(t.ImageURL),
 		t.File, cliArgs, inputs, env,
@@ -109,6 +135,16 @@ func (r *taskRepo) Create(ctx context.Context, t task.Task) (task.Task, error) {
 		nullString(string(t.Kind)), nullString(string(t.Mode)),
 		t.Broadcast,
 		metadata,
+		inputsFrom,
+		secretRefs,
+		retryPolicy,
+		t.Attempts,
+		t.MaxInstructions,
+		t.Group,
+		t.Namespace,
+		t.Priority,
+		nullTime(t.QueuedAt),
+		nullTime(t.RetryAt),
 	)
 	if err != nil {
 		return task.Task{}, fmt.Errorf("%w: %w", ErrCreate, err)
@@ -139,7 +175,10 @@ func (r *taskRepo) Update(ctx context.Context, t task.Task) error {
 		env = ?, daemon = ?, encrypted = ?, kbs_resource_path = ?, proplet_id = ?,
 		results = ?, error = ?, monitoring_profile = ?, start_time = ?,
 		finish_time = ?, updated_at = ?, workflow_id = ?, job_id = ?,
-		depends_on = ?, run_if = ?, kind = ?, mode = ?, broadcast = ?, metadata = ?
+		depends_on = ?, run_if = ?, kind = ?, mode = ?, broadcast = ?, metadata = ?,
+		inputs_from = ?, secret_refs = ?, retry_policy = ?, attempts = ?,
+		max_instructions = ?, proplet_group = ?, namespace = ?, priority = ?,
+		queued_at = ?, retry_at = ?
 	WHERE id = ?`
 
 	cliArgs, err := jsonBytes(t.CLIArgs)
@@ -177,6 +216,21 @@ func (r *taskRepo) Update(ctx context.Context, t task.Task) error {
 		return fmt.Errorf("marshal error: %w", err)
 	}
 
+	inputsFrom, err := jsonBytes(t.InputsFrom)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	secretRefs, err := jsonBytes(t.SecretRefs)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
+	retryPolicy, err := jsonBytes(t.RetryPolicy)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+
 	_, err = r.db.ExecContext(ctx, query,
 		t.Name, uint8(t.State), nullString(t.ImageURL),
 		t.File, cliArgs, inputs, env,
@@ -189,6 +243,16 @@ func (r *taskRepo) Update(ctx context.Context, t task.Task) error {
 		nullString(string(t.Kind)), nullString(string(t.Mode)),
 		t.Broadcast,
 		metadata,
+		inputsFrom,
+		secretRefs,
+		retryPolicy,
+		t.Attempts,
+		t.MaxInstructions,
+		t.Group,
+		t.Namespace,
+		t.Priority,
+		nullTime(t.QueuedAt),
+		nullTime(t.RetryAt),
 		t.ID,
 	)
 	if err != nil {
@@ -220,6 +284,70 @@ func (r *taskRepo) List(ctx context.Context, filter task.Metadata, offset, limit
 	return tasks, total, nil
 }
 
+// taskSortColumns maps the task.SortFields a query can sort by to their
+// columns.
+var taskSortColumns = map[string]string{
+	"name":        "name",
+	"state":       "state",
+	"priority":    "priority",
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+	"start_time":  "start_time",
+	"finish_time": "finish_time",
+}
+
+func (r *taskRepo) Query(ctx context.Context, q task.Query) ([]task.Task, uint64, error) {
+	whereClause, args, err := buildSQLiteMetadataWhere(q.Metadata)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+	where := func(cond string, arg any) {
+		if whereClause == "" {
+			whereClause = " WHERE " + cond
+		} else {
+			whereClause += " AND " + cond
+		}
+		args = append(args, arg)
+	}
+	if q.State != nil {
+		where("state = ?", uint8(*q.State))
+	}
+	switch q.Kind {
+	case "":
+	case task.TaskKindStandard:
+		where("COALESCE(kind, '') IN ('', ?)", string(q.Kind))
+	default:
+		where("kind = ?", string(q.Kind))
+	}
+	if q.Mode != "" {
+		where("mode = ?", string(q.Mode))
+	}
+	if q.PropletID != "" {
+		where("proplet_id = ?", q.PropletID)
+	}
+
+	var total uint64
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM tasks"+whereClause, args...); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrDBQuery, err)
+	}
+
+	orderBy := " ORDER BY created_at DESC"
+	if column, ok := taskSortColumns[q.Sort]; ok {
+		dir := "ASC"
+		if q.Desc {
+			dir = "DESC"
+		}
+		orderBy = " ORDER BY " + column + " " + dir + ", created_at DESC"
+	}
+	query := `SELECT ` + taskColumns + ` FROM tasks` + whereClause + orderBy + ` LIMIT ? OFFSET ?`
+	tasks, err := r.scanTasks(ctx, query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
 // buildSQLiteMetadataWhere builds a WHERE clause for metadata filtering.
 // Keys are interpolated directly into the SQL because sqliteMetadataKeyRe
 // restricts them to [a-zA-Z0-9._\-]+, which contains no SQL metacharacters.
@@ -285,7 +413,9 @@ func (r *taskRepo) scanTasks(ctx context.Context, query string, args ...any) ([]
 			&dbt.Results, &dbt.Error, &dbt.MonitoringProfile,
 			&dbt.StartTime, &dbt.FinishTime, &dbt.CreatedAt, &dbt.UpdatedAt,
 			&dbt.WorkflowID, &dbt.JobID, &dbt.DependsOn, &dbt.RunIf,
-			&dbt.Kind, &dbt.Mode, &dbt.Broadcast, &dbt.Metadata,
+			&dbt.Kind, &dbt.Mode, &dbt.Broadcast, &dbt.Metadata, &dbt.InputsFrom, &dbt.SecretRefs,
+			&dbt.RetryPolicy, &dbt.Attempts, &dbt.MaxInstructions, &dbt.Group, &dbt.Namespace,
+			&dbt.Priority, &dbt.QueuedAt, &dbt.RetryAt,
 		); err != nil {
 			return nil, fmt.Errorf("%w: %w", ErrDBScan, err)
 		}
@@ -374,6 +504,26 @@ func (r *taskRepo) toTask(dbt dbTask) (task.Task, error) {
 			return task.Task{}, err
 		}
 	}
+	if err := jsonUnmarshal(dbt.InputsFrom, &t.InputsFrom); err != nil {
+		return task.Task{}, err
+	}
+	if err := jsonUnmarshal(dbt.SecretRefs, &t.SecretRefs); err != nil {
+		return task.Task{}, err
+	}
+	if err := jsonUnmarshal(dbt.RetryPolicy, &t.RetryPolicy); err != nil {
+		return task.Task{}, err
+	}
+	t.Attempts = dbt.Attempts
+	t.MaxInstructions = dbt.MaxInstructions
+	t.Group = dbt.Group
+	t.Namespace = dbt.Namespace
+	t.Priority = dbt.Priority
+	if dbt.QueuedAt.Valid {
+		t.QueuedAt = dbt.QueuedAt.Time
+	}
+	if dbt.RetryAt.Valid {
+		t.RetryAt = dbt.RetryAt.Time
+	}
 
 	return t, nil
 }
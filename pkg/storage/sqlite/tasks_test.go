@@ -0,0 +1,126 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/absmach/propeller/pkg/storage/sqlite"
+	"github.com/absmach/propeller/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskQuery(t *testing.T) {
+	t.Parallel()
+	repo := sqlite.NewTaskRepository(newTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, tk := range []task.Task{
+		{ID: "a", Name: "a", State: task.Running, PropletID: "proplet-1", Priority: 1},
+		{ID: "b", Name: "b", State: task.Running, PropletID: "proplet-1", Priority: 3, Kind: task.TaskKindStandard},
+		{ID: "c", Name: "c", State: task.Running, PropletID: "proplet-2", Priority: 2},
+		{ID: "d", Name: "d", State: task.Pending, Kind: task.TaskKindFederated, Mode: task.ModeTrain, Metadata: task.Metadata{"fl_round_id": "r1"}},
+	} {
+		tk.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		tk.UpdatedAt = tk.CreatedAt
+		_, err := repo.Create(ctx, tk)
+		require.NoError(t, err)
+	}
+	running := task.Running
+
+	ids := func(tasks []task.Task) []string {
+		out := make([]string, len(tasks))
+		for i, tk := range tasks {
+			out[i] = tk.ID
+		}
+
+		return out
+	}
+
+	cases := []struct {
+		desc      string
+		query     task.Query
+		wantIDs   []string
+		wantTotal uint64
+	}{
+		{
+			desc:      "newest first by default",
+			query:     task.Query{Limit: 10},
+			wantIDs:   []string{"d", "c", "b", "a"},
+			wantTotal: 4,
+		},
+		{
+			desc:      "state and proplet",
+			query:     task.Query{State: &running, PropletID: "proplet-1", Limit: 10},
+			wantIDs:   []string{"b", "a"},
+			wantTotal: 2,
+		},
+		{
+			desc:      "standard kind includes tasks without a kind",
+			query:     task.Query{Kind: task.TaskKindStandard, Limit: 10},
+			wantIDs:   []string{"c", "b", "a"},
+			wantTotal: 3,
+		},
+		{
+			desc:      "kind, mode and metadata",
+			query:     task.Query{Kind: task.TaskKindFederated, Mode: task.ModeTrain, Metadata: task.Metadata{"fl_round_id": "r1"}, Limit: 10},
+			wantIDs:   []string{"d"},
+			wantTotal: 1,
+		},
+		{
+			desc:      "sorted by priority and paged",
+			query:     task.Query{State: &running, Sort: "priority", Desc: true, Offset: 1, Limit: 1},
+			wantIDs:   []string{"c"},
+			wantTotal: 3,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tasks, total, err := repo.Query(ctx, tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantIDs, ids(tasks))
+			assert.Equal(t, tc.wantTotal, total)
+		})
+	}
+}
+
+func TestTaskRetryAt(t *testing.T) {
+	t.Parallel()
+	repo := sqlite.NewTaskRepository(newTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	created, err := repo.Create(ctx, task.Task{ID: "a", Name: "a", CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+	got, err := repo.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, got.RetryAt.IsZero())
+
+	got.State = task.Pending
+	got.RetryAt = now.Add(time.Minute)
+	require.NoError(t, repo.Update(ctx, got))
+	got, err = repo.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(time.Minute), got.RetryAt, time.Millisecond)
+}
+
+func TestTaskQueuedAt(t *testing.T) {
+	t.Parallel()
+	repo := sqlite.NewTaskRepository(newTestDB(t))
+	ctx := context.Background()
+	now := time.Now()
+
+	created, err := repo.Create(ctx, task.Task{ID: "a", Name: "a", CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+	got, err := repo.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, got.QueuedAt.IsZero())
+
+	got.QueuedAt = now
+	require.NoError(t, repo.Update(ctx, got))
+	got, err = repo.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, now, got.QueuedAt, time.Millisecond)
+}
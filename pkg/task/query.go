@@ -0,0 +1,83 @@
+package task
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Query selects, orders and pages tasks in a repository. Unset fields match
+// every task.
+type Query struct {
+	Metadata Metadata
+	State    *State
+	// Kind matches tasks of that kind; TaskKindStandard also matches tasks
+	// with no kind.
+	Kind      TaskKind
+	Mode      Mode
+	PropletID string
+	// Sort is one of SortFields, ascending unless Desc is set. Without it
+	// tasks keep the repository's order.
+	Sort   string
+	Desc   bool
+	Offset uint64
+	Limit  uint64
+}
+
+// SortFields compares tasks by each field a Query can be sorted by.
+var SortFields = map[string]func(a, b Task) int{
+	"name":        func(a, b Task) int { return cmp.Compare(a.Name, b.Name) },
+	"state":       func(a, b Task) int { return cmp.Compare(a.State, b.State) },
+	"priority":    func(a, b Task) int { return cmp.Compare(a.Priority, b.Priority) },
+	"created_at":  func(a, b Task) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at":  func(a, b Task) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+	"start_time":  func(a, b Task) int { return a.StartTime.Compare(b.StartTime) },
+	"finish_time": func(a, b Task) int { return a.FinishTime.Compare(b.FinishTime) },
+}
+
+// Match reports whether t passes the query's state, kind, mode and proplet
+// filters. Metadata is left to the repository's index.
+func (q Query) Match(t Task) bool {
+	kind := t.Kind
+	if kind == "" {
+		kind = TaskKindStandard
+	}
+	switch {
+	case q.State != nil && t.State != *q.State,
+		q.Kind != "" && kind != q.Kind,
+		q.Mode != "" && t.Mode != q.Mode,
+		q.PropletID != "" && t.PropletID != q.PropletID:
+		return false
+	default:
+		return true
+	}
+}
+
+// Apply filters tasks already matching the query's metadata, sorts them and
+// returns the page at the query's offset and limit along with the number of
+// matching tasks. Repositories that cannot run the query natively use it.
+func (q Query) Apply(tasks []Task) ([]Task, uint64) {
+	matched := make([]Task, 0, len(tasks))
+	for _, t := range tasks {
+		if q.Match(t) {
+			matched = append(matched, t)
+		}
+	}
+	if compare, ok := SortFields[q.Sort]; ok {
+		slices.SortStableFunc(matched, func(a, b Task) int {
+			if q.Desc {
+				return compare(b, a)
+			}
+
+			return compare(a, b)
+		})
+	}
+
+	total := uint64(len(matched))
+	start := min(q.Offset, total)
+	end := total
+	if q.Limit < total-start {
+		end = start + q.Limit
+	}
+
+	return matched[start:end], total
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/absmach/propeller/pkg/proplet"
@@ -44,6 +45,7 @@ const (
 	Failed
 	Skipped
 	Interrupted
+	Blocked
 )
 
 func (s State) IsTerminal() bool {
@@ -66,11 +68,24 @@ func (s State) String() string {
 		return "Skipped"
 	case Interrupted:
 		return "Interrupted"
+	case Blocked:
+		return "Blocked"
 	default:
 		return "Unknown"
 	}
 }
 
+// ToState returns the state named s, ignoring case.
+func ToState(s string) (State, error) {
+	for st := Pending; st <= Blocked; st++ {
+		if strings.EqualFold(s, st.String()) {
+			return st, nil
+		}
+	}
+
+	return State(0), ErrInvalidState
+}
+
 type Mode string
 
 const (
@@ -90,7 +105,10 @@ const (
 	RunIfFailure = "failure"
 )
 
-var ErrInvalidJobStatus = errors.New("invalid job status")
+var (
+	ErrInvalidJobStatus = errors.New("invalid job status")
+	ErrInvalidState     = errors.New("invalid task state")
+)
 
 type JobStatus uint8
 
@@ -156,6 +174,13 @@ func (s JobStatus) State() State {
 
 type Metadata map[string]any
 
+// RetryPolicy re-runs a task that fails. MaxAttempts counts every run,
+// including the first, and Backoff is how long to wait before each retry.
+type RetryPolicy struct {
+	MaxAttempts int           `json:"max_attempts"`
+	Backoff     time.Duration `json:"backoff,omitempty"`
+}
+
 type Task struct {
 	ID                string                     `json:"id"`
 	Name              string                     `json:"name"`
@@ -166,16 +191,24 @@ type Task struct {
 	CLIArgs           []string                   `json:"cli_args"`
 	Inputs            FlexStrings                `json:"inputs,omitempty"`
 	Env               map[string]string          `json:"env,omitempty"`
+	SecretRefs        map[string]string          `json:"secret_refs,omitempty"`
 	Daemon            bool                       `json:"daemon"`
 	Encrypted         bool                       `json:"encrypted"`
 	KBSResourcePath   string                     `json:"kbs_resource_path,omitempty"`
 	PropletID         string                     `json:"proplet_id,omitempty"`
+	Group             string                     `json:"group,omitempty"`
+	Namespace         string                     `json:"namespace,omitempty"`
 	DependsOn         []string                   `json:"depends_on,omitempty"`
+	InputsFrom        map[string]string          `json:"inputs_from,omitempty"`
 	RunIf             string                     `json:"run_if,omitempty"`
 	WorkflowID        string                     `json:"workflow_id,omitempty"`
 	JobID             string                     `json:"job_id,omitempty"`
 	Results           any                        `json:"results,omitempty"`
 	Error             string                     `json:"error,omitempty"`
+	RetryPolicy       *RetryPolicy               `json:"retry_policy,omitempty"`
+	Attempts          int                        `json:"attempts,omitempty"`
+	RetryAt           time.Time                  `json:"retry_at,omitzero"`
+	MaxInstructions   uint64                     `json:"max_instructions,omitempty"`
 	MonitoringProfile *proplet.MonitoringProfile `json:"monitoring_profile,omitempty"`
 	StartTime         time.Time                  `json:"start_time"`
 	FinishTime        time.Time                  `json:"finish_time"`
@@ -188,6 +221,7 @@ type Task struct {
 	Timezone          string                     `json:"timezone,omitempty"`
 	Broadcast         bool                       `json:"broadcast,omitempty"`
 	Priority          int                        `json:"priority,omitempty"`
+	QueuedAt          time.Time                  `json:"queued_at,omitzero"`
 	Metadata          Metadata                   `json:"metadata,omitempty"`
 	HalStoragePath    *string                    `json:"hal_storage_path,omitempty"`
 }
@@ -1,3 +1,4 @@
+use crate::mqtt::{Channel, DEFAULT_TOPIC_PREFIX};
 use crate::tee_detection;
 use rumqttc::QoS;
 use serde::Deserialize;
@@ -9,6 +10,9 @@ use std::time::Duration;
 
 const DEFAULT_CONFIG_PATH: &str = "config.toml";
 const DEFAULT_CONFIG_SECTION: &str = "proplet";
+/// Room left in each registry chunk packet for the MQTT topic and the JSON
+/// fields around the chunk data.
+const CHUNK_ENVELOPE_OVERHEAD: usize = 4 * 1024;
 
 /// Configuration fields that can be loaded from TOML file
 #[derive(Debug, Clone, Deserialize)]
@@ -37,8 +41,12 @@ pub struct PropletConfig {
     pub http_tls_insecure_skip_verify: bool,
     pub liveliness_interval: u64,
     pub metrics_interval: u64,
+    pub topic_prefix: String,
     pub domain_id: String,
     pub channel_id: String,
+    /// Channels served besides `domain_id`/`channel_id`, for edge nodes
+    /// shared by several tenants.
+    pub extra_channels: Vec<Channel>,
     pub client_id: String,
     pub client_key: String,
     pub k8s_namespace: Option<String>,
@@ -49,6 +57,8 @@ pub struct PropletConfig {
     pub aa_config_path: Option<String>,
     pub layer_store_path: String,
     pub pull_concurrent_limit: usize,
+    pub max_concurrent_tasks: usize,
+    pub queue_when_full: bool,
     pub hal_enabled: bool,
     pub http_enabled: bool,
     pub usb_enabled: bool,
@@ -63,6 +73,13 @@ pub struct PropletConfig {
     pub metrics_enabled: bool,
     pub otel_url: Option<String>,
     pub trace_ratio: f64,
+    pub fl_signing_key: Option<String>,
+    pub result_sink_endpoint: Option<String>,
+    pub result_sink_bucket: String,
+    pub result_sink_region: String,
+    pub result_sink_access_key: Option<String>,
+    pub result_sink_secret_key: Option<String>,
+    pub result_sink_threshold: usize,
 }
 
 impl Default for PropletConfig {
@@ -84,8 +101,10 @@ impl Default for PropletConfig {
             http_tls_insecure_skip_verify: false,
             liveliness_interval: 10,
             metrics_interval: 10,
+            topic_prefix: DEFAULT_TOPIC_PREFIX.to_string(),
             domain_id: String::new(),
             channel_id: String::new(),
+            extra_channels: Vec::new(),
             client_id: String::new(),
             client_key: String::new(),
             k8s_namespace: None,
@@ -96,6 +115,8 @@ impl Default for PropletConfig {
             aa_config_path: None,
             layer_store_path: "/tmp/proplet/layers".to_string(),
             pull_concurrent_limit: 4,
+            max_concurrent_tasks: 0,
+            queue_when_full: false,
             hal_enabled: true,
             http_enabled: false,
             usb_enabled: false,
@@ -110,6 +131,13 @@ impl Default for PropletConfig {
             metrics_enabled: true,
             otel_url: None,
             trace_ratio: 0.0,
+            fl_signing_key: None,
+            result_sink_endpoint: None,
+            result_sink_bucket: String::new(),
+            result_sink_region: "us-east-1".to_string(),
+            result_sink_access_key: None,
+            result_sink_secret_key: None,
+            result_sink_threshold: 256 * 1024, // 256KB
         }
     }
 }
@@ -292,6 +320,12 @@ impl PropletConfig {
             }
         }
 
+        if let Ok(val) = env::var("PROPLET_TOPIC_PREFIX") {
+            if !val.is_empty() {
+                config.topic_prefix = val;
+            }
+        }
+
         if let Ok(val) = env::var("PROPLET_DOMAIN_ID") {
             if !val.is_empty() {
                 config.domain_id = val;
@@ -304,6 +338,10 @@ impl PropletConfig {
             }
         }
 
+        if let Ok(val) = env::var("PROPLET_EXTRA_CHANNELS") {
+            config.extra_channels = parse_channels(&val);
+        }
+
         if let Ok(val) = env::var("PROPLET_CLIENT_ID") {
             if !val.is_empty() {
                 config.client_id = val;
@@ -338,6 +376,16 @@ impl PropletConfig {
             config.enable_monitoring = val.to_lowercase() == "true" || val == "1";
         }
 
+        if let Ok(val) = env::var("PROPLET_MAX_CONCURRENT_TASKS") {
+            if let Ok(limit) = val.parse() {
+                config.max_concurrent_tasks = limit;
+            }
+        }
+
+        if let Ok(val) = env::var("PROPLET_QUEUE_WHEN_FULL") {
+            config.queue_when_full = val.to_lowercase() == "true" || val == "1";
+        }
+
         {
             if let Ok(val) = env::var("PROPLET_KBS_URI") {
                 config.kbs_uri = if val.is_empty() { None } else { Some(val) };
@@ -442,6 +490,46 @@ impl PropletConfig {
             }
         }
 
+        if let Ok(val) = env::var("PROPLET_FL_SIGNING_KEY") {
+            if !val.is_empty() {
+                config.fl_signing_key = Some(val);
+            }
+        }
+
+        if let Ok(val) = env::var("PROPLET_RESULT_SINK_ENDPOINT") {
+            if !val.is_empty() {
+                config.result_sink_endpoint = Some(val);
+            }
+        }
+
+        if let Ok(val) = env::var("PROPLET_RESULT_SINK_BUCKET") {
+            config.result_sink_bucket = val;
+        }
+
+        if let Ok(val) = env::var("PROPLET_RESULT_SINK_REGION") {
+            if !val.is_empty() {
+                config.result_sink_region = val;
+            }
+        }
+
+        if let Ok(val) = env::var("PROPLET_RESULT_SINK_ACCESS_KEY") {
+            if !val.is_empty() {
+                config.result_sink_access_key = Some(val);
+            }
+        }
+
+        if let Ok(val) = env::var("PROPLET_RESULT_SINK_SECRET_KEY") {
+            if !val.is_empty() {
+                config.result_sink_secret_key = Some(val);
+            }
+        }
+
+        if let Ok(val) = env::var("PROPLET_RESULT_SINK_THRESHOLD") {
+            if let Ok(threshold) = val.parse() {
+                config.result_sink_threshold = threshold;
+            }
+        }
+
         config
     }
 
@@ -468,6 +556,54 @@ impl PropletConfig {
     pub fn metrics_interval(&self) -> Duration {
         Duration::from_secs(self.metrics_interval)
     }
+
+    /// The channel the proplet registers on and fetches Wasm binaries from.
+    pub fn primary_channel(&self) -> Channel {
+        Channel::new(&self.domain_id, &self.channel_id)
+    }
+
+    /// Every channel the proplet takes tasks from, primary first.
+    pub fn channels(&self) -> Vec<Channel> {
+        let mut channels = vec![self.primary_channel()];
+        for channel in &self.extra_channels {
+            if !channels.contains(channel) {
+                channels.push(channel.clone());
+            }
+        }
+        channels
+    }
+
+    /// Largest registry chunk this proplet can receive. Chunk data travels
+    /// base64-encoded, so it must fit in 3/4 of what is left of a packet
+    /// after the envelope. Never zero, which the proxy reads as "no limit".
+    pub fn max_chunk_size(&self) -> usize {
+        (self
+            .mqtt_max_packet_size
+            .saturating_sub(CHUNK_ENVELOPE_OVERHEAD)
+            / 4
+            * 3)
+        .max(1)
+    }
+}
+
+/// Parses a comma-separated list of `domain_id:channel_id` pairs, skipping
+/// malformed entries.
+fn parse_channels(val: &str) -> Vec<Channel> {
+    val.split(',')
+        .map(str::trim)
+        .filter(|entry| !entry.is_empty())
+        .filter_map(|entry| match entry.split_once(':') {
+            Some((domain_id, channel_id))
+                if !domain_id.trim().is_empty() && !channel_id.trim().is_empty() =>
+            {
+                Some(Channel::new(domain_id.trim(), channel_id.trim()))
+            }
+            _ => {
+                eprintln!("warn: ignoring malformed PROPLET_EXTRA_CHANNELS entry '{entry}'");
+                None
+            }
+        })
+        .collect()
 }
 
 #[cfg(test)]
@@ -506,6 +642,21 @@ mod tests {
         }
     }
 
+    #[test]
+    fn test_proplet_config_max_chunk_size() {
+        let config = PropletConfig {
+            mqtt_max_packet_size: 1024 * 1024,
+            ..Default::default()
+        };
+        assert_eq!(config.max_chunk_size(), 783_360);
+
+        let tiny = PropletConfig {
+            mqtt_max_packet_size: 1024,
+            ..Default::default()
+        };
+        assert_eq!(tiny.max_chunk_size(), 1);
+    }
+
     #[test]
     fn test_proplet_config_qos_at_most_once() {
         let config = PropletConfig {
@@ -636,6 +787,36 @@ mod tests {
         assert_eq!(config.channel_id, "channel-456");
     }
 
+    #[test]
+    fn test_proplet_config_from_env_extra_channels() {
+        let _lock = env_lock();
+        env::set_var("PROPLET_DOMAIN_ID", "domain-1");
+        env::set_var("PROPLET_CHANNEL_ID", "channel-1");
+        env::set_var(
+            "PROPLET_EXTRA_CHANNELS",
+            "domain-2:channel-2, domain-1:channel-1,bogus,domain-3:",
+        );
+        let config = PropletConfig::from_env();
+        env::remove_var("PROPLET_DOMAIN_ID");
+        env::remove_var("PROPLET_CHANNEL_ID");
+        env::remove_var("PROPLET_EXTRA_CHANNELS");
+
+        assert_eq!(
+            config.extra_channels,
+            vec![
+                Channel::new("domain-2", "channel-2"),
+                Channel::new("domain-1", "channel-1"),
+            ]
+        );
+        assert_eq!(
+            config.channels(),
+            vec![
+                Channel::new("domain-1", "channel-1"),
+                Channel::new("domain-2", "channel-2"),
+            ]
+        );
+    }
+
     #[test]
     fn test_proplet_config_from_env_client_id() {
         let _lock = env_lock();
@@ -699,6 +880,32 @@ mod tests {
         assert_eq!(config.mqtt_qos, 2);
     }
 
+    #[test]
+    fn test_proplet_config_from_env_max_concurrent_tasks() {
+        let _lock = env_lock();
+        env::set_var("PROPLET_MAX_CONCURRENT_TASKS", "3");
+        env::set_var("PROPLET_QUEUE_WHEN_FULL", "true");
+        let config = PropletConfig::from_env();
+        env::remove_var("PROPLET_MAX_CONCURRENT_TASKS");
+        env::remove_var("PROPLET_QUEUE_WHEN_FULL");
+
+        assert_eq!(config.max_concurrent_tasks, 3);
+        assert!(config.queue_when_full);
+    }
+
+    #[test]
+    fn test_proplet_config_from_env_topic_prefix() {
+        let _lock = env_lock();
+        env::remove_var("PROPLET_TOPIC_PREFIX");
+        assert_eq!(PropletConfig::from_env().topic_prefix, "m");
+
+        env::set_var("PROPLET_TOPIC_PREFIX", "staging");
+        let config = PropletConfig::from_env();
+        env::remove_var("PROPLET_TOPIC_PREFIX");
+
+        assert_eq!(config.topic_prefix, "staging");
+    }
+
     #[test]
     fn test_proplet_config_from_env_no_env_vars() {
         let _lock = env_lock();
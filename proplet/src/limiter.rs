@@ -0,0 +1,141 @@
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::sync::Arc;
+use tokio::sync::{OwnedSemaphorePermit, Semaphore};
+
+/// Bounds the number of tasks executing at once on this proplet.
+///
+/// A limit of zero disables the bound. When the limit is reached, starts
+/// either wait for a free slot (`queue_when_full`) or are rejected.
+#[derive(Clone)]
+pub struct TaskLimiter {
+    semaphore: Option<Arc<Semaphore>>,
+    limit: usize,
+    queue_when_full: bool,
+    running: Arc<AtomicUsize>,
+}
+
+/// Holds one execution slot; the slot is released on drop.
+pub struct TaskPermit {
+    _permit: Option<OwnedSemaphorePermit>,
+    running: Arc<AtomicUsize>,
+}
+
+impl Drop for TaskPermit {
+    fn drop(&mut self) {
+        self.running.fetch_sub(1, Ordering::SeqCst);
+    }
+}
+
+impl TaskLimiter {
+    pub fn new(limit: usize, queue_when_full: bool) -> Self {
+        Self {
+            semaphore: (limit > 0).then(|| Arc::new(Semaphore::new(limit))),
+            limit,
+            queue_when_full,
+            running: Arc::new(AtomicUsize::new(0)),
+        }
+    }
+
+    pub fn limit(&self) -> usize {
+        self.limit
+    }
+
+    pub fn queue_when_full(&self) -> bool {
+        self.queue_when_full
+    }
+
+    /// Number of tasks currently holding a slot.
+    pub fn running(&self) -> usize {
+        self.running.load(Ordering::SeqCst)
+    }
+
+    /// Takes a slot without waiting. Returns `None` when at capacity.
+    pub fn try_acquire(&self) -> Option<TaskPermit> {
+        let permit = match &self.semaphore {
+            Some(semaphore) => Some(semaphore.clone().try_acquire_owned().ok()?),
+            None => None,
+        };
+
+        Some(self.track(permit))
+    }
+
+    /// Waits until a slot is free and takes it.
+    pub async fn acquire(&self) -> TaskPermit {
+        let permit = match &self.semaphore {
+            Some(semaphore) => Some(
+                semaphore
+                    .clone()
+                    .acquire_owned()
+                    .await
+                    .expect("task limiter semaphore is never closed"),
+            ),
+            None => None,
+        };
+
+        self.track(permit)
+    }
+
+    fn track(&self, permit: Option<OwnedSemaphorePermit>) -> TaskPermit {
+        self.running.fetch_add(1, Ordering::SeqCst);
+        TaskPermit {
+            _permit: permit,
+            running: self.running.clone(),
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::time::Duration;
+
+    #[test]
+    fn test_unlimited_never_rejects() {
+        let limiter = TaskLimiter::new(0, false);
+        let permits: Vec<_> = (0..100).map(|_| limiter.try_acquire().unwrap()).collect();
+
+        assert_eq!(limiter.running(), 100);
+        drop(permits);
+        assert_eq!(limiter.running(), 0);
+    }
+
+    #[test]
+    fn test_rejects_beyond_limit() {
+        let limiter = TaskLimiter::new(2, false);
+        let first = limiter.try_acquire().unwrap();
+        let _second = limiter.try_acquire().unwrap();
+
+        assert!(limiter.try_acquire().is_none());
+        assert_eq!(limiter.running(), 2);
+
+        drop(first);
+        assert_eq!(limiter.running(), 1);
+        assert!(limiter.try_acquire().is_some());
+    }
+
+    #[tokio::test]
+    async fn test_queued_start_waits_for_slot() {
+        let limiter = TaskLimiter::new(1, true);
+        let held = limiter.acquire().await;
+
+        let waiter = {
+            let limiter = limiter.clone();
+            tokio::spawn(async move {
+                let _permit = limiter.acquire().await;
+                limiter.running()
+            })
+        };
+
+        tokio::time::sleep(Duration::from_millis(50)).await;
+        assert!(!waiter.is_finished());
+        assert_eq!(limiter.running(), 1);
+
+        drop(held);
+        let running_inside = tokio::time::timeout(Duration::from_secs(1), waiter)
+            .await
+            .expect("queued start should run once a slot frees up")
+            .unwrap();
+        assert_eq!(running_inside, 1);
+        assert_eq!(limiter.running(), 0);
+    }
+}
@@ -1,10 +1,12 @@
 mod config;
 mod hal;
 mod hal_component;
+mod limiter;
 mod metrics;
 mod monitoring;
 mod mqtt;
 mod plugin;
+mod result_sink;
 mod runtime;
 mod service;
 mod task_handler;
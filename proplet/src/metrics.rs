@@ -1,4 +1,6 @@
 use serde::{Deserialize, Serialize};
+use std::fs;
+use std::path::Path;
 use sysinfo::System;
 
 #[derive(Debug, Clone, Serialize, Deserialize)]
@@ -89,8 +91,6 @@ impl MetricsCollector {
 
     #[cfg(target_os = "linux")]
     fn read_proc_stat() -> Result<(f64, f64), std::io::Error> {
-        use std::fs;
-
         let contents = fs::read_to_string("/proc/self/stat")?;
 
         let close_paren = contents.rfind(')').ok_or_else(|| {
@@ -130,49 +130,48 @@ impl MetricsCollector {
 
         #[cfg(target_os = "linux")]
         {
-            if let Ok((usage, limit)) = Self::read_cgroup_memory() {
+            if let Some((usage, limit)) = read_cgroup_memory(Path::new(CGROUP_ROOT)) {
                 mem_metrics.container_usage_bytes = Some(usage);
-                if limit > 0 {
-                    mem_metrics.container_limit_bytes = Some(limit);
-                }
+                mem_metrics.container_limit_bytes = limit;
             }
         }
 
         mem_metrics
     }
+}
 
-    #[cfg(target_os = "linux")]
-    fn read_cgroup_memory() -> Result<(u64, u64), std::io::Error> {
-        use std::fs;
-
-        if let Ok(usage_str) = fs::read_to_string("/sys/fs/cgroup/memory.current") {
-            let usage = usage_str.trim().parse().unwrap_or(0);
-            let limit = if let Ok(limit_str) = fs::read_to_string("/sys/fs/cgroup/memory.max") {
-                let limit_str = limit_str.trim();
-                if limit_str == "max" {
-                    0
-                } else {
-                    limit_str.parse().unwrap_or(0)
-                }
-            } else {
-                0
-            };
-            return Ok((usage, limit));
-        }
+#[cfg(target_os = "linux")]
+const CGROUP_ROOT: &str = "/sys/fs/cgroup";
+
+// cgroup v1 reports an unlimited cgroup as a page-aligned i64::MAX.
+const CGROUP_V1_UNLIMITED: u64 = 0x7FFF_FFFF_FFFF_F000;
+
+/// Reads the memory usage and limit of the cgroup mounted at `root`,
+/// preferring cgroup v2 and falling back to v1. Returns `None` when no
+/// memory controller is found, i.e. on hosts that are not containerized. The
+/// limit is `None` when the cgroup is unlimited.
+#[cfg_attr(not(target_os = "linux"), allow(dead_code))]
+fn read_cgroup_memory(root: &Path) -> Option<(u64, Option<u64>)> {
+    if let Some(usage) = read_cgroup_value(&root.join("memory.current")) {
+        let limit = read_cgroup_value(&root.join("memory.max"));
+        return Some((usage, limit));
+    }
 
-        if let Ok(usage_str) = fs::read_to_string("/sys/fs/cgroup/memory/memory.usage_in_bytes") {
-            let usage = usage_str.trim().parse().unwrap_or(0);
-            let limit = if let Ok(limit_str) =
-                fs::read_to_string("/sys/fs/cgroup/memory/memory.limit_in_bytes")
-            {
-                limit_str.trim().parse().unwrap_or(0)
-            } else {
-                0
-            };
-            return Ok((usage, limit));
-        }
+    let v1 = root.join("memory");
+    if let Some(usage) = read_cgroup_value(&v1.join("memory.usage_in_bytes")) {
+        let limit = read_cgroup_value(&v1.join("memory.limit_in_bytes"))
+            .filter(|&limit| limit < CGROUP_V1_UNLIMITED);
+        return Some((usage, limit));
+    }
 
-        Ok((0, 0))
+    None
+}
+
+fn read_cgroup_value(path: &Path) -> Option<u64> {
+    let value = fs::read_to_string(path).ok()?;
+    match value.trim() {
+        "max" => None,
+        v => v.parse().ok(),
     }
 }
 
@@ -181,3 +180,50 @@ impl Default for MetricsCollector {
         Self::new()
     }
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn cgroup_root(files: &[(&str, &str)]) -> std::path::PathBuf {
+        let root = std::env::temp_dir().join(format!("proplet-cgroup-{}", uuid::Uuid::new_v4()));
+        fs::create_dir_all(&root).unwrap();
+        for (name, contents) in files {
+            let path = root.join(name);
+            fs::create_dir_all(path.parent().unwrap()).unwrap();
+            fs::write(path, contents).unwrap();
+        }
+        root
+    }
+
+    #[test]
+    fn test_read_cgroup_v2_memory() {
+        let root = cgroup_root(&[("memory.current", "1048576\n"), ("memory.max", "4194304\n")]);
+        assert_eq!(read_cgroup_memory(&root), Some((1048576, Some(4194304))));
+
+        fs::write(root.join("memory.max"), "max\n").unwrap();
+        assert_eq!(read_cgroup_memory(&root), Some((1048576, None)));
+        fs::remove_dir_all(root).unwrap();
+    }
+
+    #[test]
+    fn test_read_cgroup_v1_memory() {
+        let root = cgroup_root(&[
+            ("memory/memory.usage_in_bytes", "2048"),
+            ("memory/memory.limit_in_bytes", "8192"),
+        ]);
+        assert_eq!(read_cgroup_memory(&root), Some((2048, Some(8192))));
+
+        let limit = root.join("memory/memory.limit_in_bytes");
+        fs::write(limit, "9223372036854771712").unwrap();
+        assert_eq!(read_cgroup_memory(&root), Some((2048, None)));
+        fs::remove_dir_all(root).unwrap();
+    }
+
+    #[test]
+    fn test_read_cgroup_memory_outside_container() {
+        let root = cgroup_root(&[]);
+        assert_eq!(read_cgroup_memory(&root), None);
+        fs::remove_dir_all(root).unwrap();
+    }
+}
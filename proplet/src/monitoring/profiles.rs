@@ -4,6 +4,7 @@ use std::time::Duration;
 impl MonitoringProfile {
     pub fn standard() -> Self {
         Self {
+            name: None,
             enabled: true,
             interval: Duration::from_secs(10),
             collect_cpu: true,
@@ -19,6 +20,7 @@ impl MonitoringProfile {
 
     pub fn long_running_daemon() -> Self {
         Self {
+            name: None,
             enabled: true,
             interval: Duration::from_secs(120),
             collect_cpu: true,
@@ -31,4 +33,95 @@ impl MonitoringProfile {
             history_size: 500,
         }
     }
+
+    /// Returns the preset for `name`, matching the manager's
+    /// proplet.NamedMonitoringProfile.
+    pub fn named(name: &str) -> Option<Self> {
+        let profile = match name {
+            "off" => Self {
+                enabled: false,
+                export_to_mqtt: false,
+                ..Self::standard()
+            },
+            "basic" => Self {
+                interval: Duration::from_secs(30),
+                collect_disk_io: false,
+                collect_threads: false,
+                collect_file_descriptors: false,
+                history_size: 20,
+                ..Self::standard()
+            },
+            "detailed" => Self {
+                interval: Duration::from_secs(5),
+                history_size: 200,
+                ..Self::standard()
+            },
+            _ => return None,
+        };
+
+        Some(Self {
+            name: Some(name.to_string()),
+            ..profile
+        })
+    }
+
+    /// Expands a named profile to its preset and replaces a zero interval,
+    /// which cannot drive a timer, with the standard one.
+    pub fn resolve(self) -> Self {
+        let mut profile = match self.name.as_deref().and_then(Self::named) {
+            Some(preset) => preset,
+            None => self,
+        };
+        if profile.interval.is_zero() {
+            profile.interval = Self::standard().interval;
+        }
+
+        profile
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_named_profiles_set_publish_interval() {
+        let cases = [
+            ("basic", true, Duration::from_secs(30)),
+            ("detailed", true, Duration::from_secs(5)),
+        ];
+        for (name, enabled, interval) in cases {
+            let profile = MonitoringProfile::named(name).unwrap();
+            assert_eq!(profile.enabled, enabled, "profile {name}");
+            assert_eq!(profile.interval, interval, "profile {name}");
+        }
+
+        let off = MonitoringProfile::named("off").unwrap();
+        assert!(!off.enabled);
+        assert!(!off.export_to_mqtt);
+
+        assert!(MonitoringProfile::named("verbose").is_none());
+    }
+
+    #[test]
+    fn test_resolve_profile_from_manager() {
+        // The manager encodes the interval as Go time.Duration nanoseconds.
+        let profile: MonitoringProfile =
+            serde_json::from_str(r#"{"enabled":true,"interval":60000000000}"#).unwrap();
+        let profile = profile.resolve();
+        assert_eq!(profile.interval, Duration::from_secs(60));
+        assert!(
+            profile.collect_cpu,
+            "omitted fields take the standard values"
+        );
+
+        let named: MonitoringProfile = serde_json::from_str(r#"{"name":"detailed"}"#).unwrap();
+        assert_eq!(named.resolve().interval, Duration::from_secs(5));
+
+        let zero: MonitoringProfile = serde_json::from_str(r#"{"interval":0}"#).unwrap();
+        assert_eq!(
+            zero.resolve().interval,
+            MonitoringProfile::standard().interval
+        );
+    }
 }
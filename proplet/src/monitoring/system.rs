@@ -42,14 +42,15 @@ impl SystemMonitor {
     ) -> Result<ProcessMetrics> {
         let pid = Pid::from_u32(pid);
 
-        sys.refresh_processes_specifics(
-            ProcessesToUpdate::Some(&[pid]),
-            true,
-            ProcessRefreshKind::default()
-                .with_cpu()
-                .with_memory()
-                .with_disk_usage(),
-        );
+        let mut refresh = ProcessRefreshKind::default()
+            .with_cpu()
+            .with_memory()
+            .with_disk_usage();
+        if profile.collect_threads {
+            // Thread lists are only populated when tasks are refreshed.
+            refresh = refresh.with_tasks();
+        }
+        sys.refresh_processes_specifics(ProcessesToUpdate::Some(&[pid]), true, refresh);
 
         let process = sys
             .process(pid)
@@ -294,3 +295,62 @@ impl ProcessMonitor for SystemMonitor {
         Ok(())
     }
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::time::Duration;
+    use tokio::sync::mpsc;
+
+    #[tokio::test]
+    async fn test_attached_task_publishes_process_metrics() {
+        let monitor = SystemMonitor::new(MonitoringProfile::default());
+        let profile = MonitoringProfile {
+            interval: Duration::from_millis(50),
+            collect_file_descriptors: false,
+            ..MonitoringProfile::standard()
+        };
+        monitor.start_monitoring("task-1", profile).await.unwrap();
+
+        let (tx, mut rx) = mpsc::unbounded_channel();
+        monitor
+            .attach_pid("task-1", std::process::id(), move |metrics, aggregated| {
+                let _ = tx.send((metrics, aggregated));
+            })
+            .await;
+
+        let mut samples = Vec::new();
+        while samples.len() < 2 {
+            let sample = tokio::time::timeout(Duration::from_secs(5), rx.recv())
+                .await
+                .expect("metrics published on the profile interval")
+                .expect("monitor still running");
+            samples.push(sample);
+        }
+        monitor.stop_monitoring("task-1").await.unwrap();
+
+        let (metrics, aggregated) = samples.pop().unwrap();
+        assert!(metrics.memory_bytes > 0);
+        #[cfg(target_os = "linux")]
+        assert!(metrics.thread_count > 0);
+        let aggregated = aggregated.expect("history is retained");
+        assert_eq!(aggregated.sample_count, 2);
+        assert!(aggregated.max_memory_usage >= metrics.memory_bytes);
+    }
+
+    #[tokio::test]
+    async fn test_disabled_profile_publishes_nothing() {
+        let monitor = SystemMonitor::new(MonitoringProfile::default());
+        let profile = MonitoringProfile::named("off").unwrap();
+        monitor.start_monitoring("task-1", profile).await.unwrap();
+
+        let (tx, mut rx) = mpsc::unbounded_channel::<ProcessMetrics>();
+        monitor
+            .attach_pid("task-1", std::process::id(), move |metrics, _| {
+                let _ = tx.send(metrics);
+            })
+            .await;
+
+        assert!(rx.recv().await.is_none());
+    }
+}
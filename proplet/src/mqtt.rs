@@ -199,8 +199,61 @@ pub async fn process_mqtt_events(mut eventloop: EventLoop, tx: mpsc::Sender<Mqtt
     }
 }
 
-pub fn build_topic(domain_id: &str, channel_id: &str, path: &str) -> String {
-    format!("m/{domain_id}/c/{channel_id}/{path}")
+/// Leading topic segment used when no prefix is configured.
+pub const DEFAULT_TOPIC_PREFIX: &str = "m";
+
+/// Builds `<prefix>/<domain_id>/c/<channel_id>/<path>`. The prefix lets
+/// deployments sharing a broker keep their control planes apart.
+pub fn build_topic(prefix: &str, domain_id: &str, channel_id: &str, path: &str) -> String {
+    let prefix = normalize_prefix(prefix);
+    format!("{prefix}/{domain_id}/c/{channel_id}/{path}")
+}
+
+fn normalize_prefix(prefix: &str) -> &str {
+    let prefix = prefix.trim_matches('/');
+    if prefix.is_empty() {
+        DEFAULT_TOPIC_PREFIX
+    } else {
+        prefix
+    }
+}
+
+/// A (domain, channel) pair the proplet takes tasks from. Tasks report back
+/// on the channel they were started from.
+#[derive(Debug, Clone, PartialEq, Eq, Hash)]
+pub struct Channel {
+    pub domain_id: String,
+    pub channel_id: String,
+}
+
+impl Channel {
+    pub fn new(domain_id: impl Into<String>, channel_id: impl Into<String>) -> Self {
+        Self {
+            domain_id: domain_id.into(),
+            channel_id: channel_id.into(),
+        }
+    }
+
+    pub fn topic(&self, prefix: &str, path: &str) -> String {
+        build_topic(prefix, &self.domain_id, &self.channel_id, path)
+    }
+
+    /// Returns the channel of a topic built by [`build_topic`] with `prefix`.
+    pub fn from_topic(prefix: &str, topic: &str) -> Option<Self> {
+        let rest = topic
+            .strip_prefix(normalize_prefix(prefix))?
+            .strip_prefix('/')?;
+        let (domain_id, rest) = rest.split_once("/c/")?;
+        let channel_id = rest.split('/').next()?;
+        Some(Self::new(domain_id, channel_id))
+    }
+}
+
+/// Returns the path results are published on, scoped by the proplet's MQTT
+/// client ID. Brokers can restrict each client to its own results topic, which
+/// lets the manager trust the `proplet_id` of results it receives there.
+pub fn results_topic_path(client_id: &str) -> String {
+    format!("control/proplet/{client_id}/results")
 }
 
 fn parse_mqtt_address(address: &str) -> Result<(String, u16, bool)> {
@@ -350,22 +403,75 @@ mod tests {
 
     #[test]
     fn test_build_topic() {
-        let topic = build_topic("domain-1", "channel-1", "control/manager/start");
+        let topic = build_topic("m", "domain-1", "channel-1", "control/manager/start");
+        assert_eq!(topic, "m/domain-1/c/channel-1/control/manager/start");
+    }
+
+    #[test]
+    fn test_build_topic_with_prefix() {
+        let topic = build_topic("staging", "domain-1", "channel-1", "control/manager/start");
+        assert_eq!(topic, "staging/domain-1/c/channel-1/control/manager/start");
+
+        let topic = build_topic(
+            "/tenant/a/",
+            "domain-1",
+            "channel-1",
+            "control/proplet/alive",
+        );
+        assert_eq!(topic, "tenant/a/domain-1/c/channel-1/control/proplet/alive");
+    }
+
+    #[test]
+    fn test_build_topic_empty_prefix_uses_default() {
+        let topic = build_topic("", "domain-1", "channel-1", "control/manager/start");
         assert_eq!(topic, "m/domain-1/c/channel-1/control/manager/start");
     }
 
+    #[test]
+    fn test_channel_from_topic() {
+        let channel = Channel::new("domain-1", "channel-1");
+        for prefix in ["m", "", "/tenant/a/"] {
+            let topic = channel.topic(prefix, "control/manager/start");
+            assert_eq!(Channel::from_topic(prefix, &topic), Some(channel.clone()));
+        }
+
+        assert_eq!(
+            Channel::from_topic("m", "m/domain-2/c/channel-2/registry/server"),
+            Some(Channel::new("domain-2", "channel-2"))
+        );
+        assert_eq!(
+            Channel::from_topic("staging", "m/domain-1/c/channel-1/x"),
+            None
+        );
+        assert_eq!(Channel::from_topic("m", "m/domain-1/channel-1"), None);
+    }
+
     #[test]
     fn test_build_topic_with_empty_path() {
-        let topic = build_topic("domain-1", "channel-1", "");
+        let topic = build_topic("m", "domain-1", "channel-1", "");
         assert_eq!(topic, "m/domain-1/c/channel-1/");
     }
 
     #[test]
     fn test_build_topic_with_slashes() {
-        let topic = build_topic("domain/1", "channel/1", "path/to/topic");
+        let topic = build_topic("m", "domain/1", "channel/1", "path/to/topic");
         assert_eq!(topic, "m/domain/1/c/channel/1/path/to/topic");
     }
 
+    #[test]
+    fn test_results_topic_path() {
+        let topic = build_topic(
+            "m",
+            "domain-1",
+            "channel-1",
+            &results_topic_path("proplet-1"),
+        );
+        assert_eq!(
+            topic,
+            "m/domain-1/c/channel-1/control/proplet/proplet-1/results"
+        );
+    }
+
     #[test]
     fn test_mqtt_message_decode_success() {
         #[derive(Debug, serde::Deserialize, PartialEq)]
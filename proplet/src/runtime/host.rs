@@ -485,6 +485,10 @@ impl Runtime for HostRuntime {
             Ok(None)
         }
     }
+
+    async fn running_apps(&self) -> Vec<String> {
+        self.processes.lock().await.keys().cloned().collect()
+    }
 }
 
 #[cfg(test)]
@@ -22,6 +22,9 @@ pub struct StartConfig {
     /// `container_<n>/` directories. When `None`, the runtime derives a
     /// default of `/tmp/proplet/hal-storage/<task-id>`.
     pub hal_storage_path: Option<String>,
+    /// Instruction (fuel) budget for the task. The Wasmtime runtime stops a
+    /// task that exhausts it; `None` leaves the task unmetered.
+    pub max_instructions: Option<u64>,
 }
 
 #[async_trait]
@@ -41,6 +44,10 @@ pub trait Runtime: Send + Sync {
     /// - The task does not exist or is not running
     /// - The platform does not support PID retrieval
     async fn get_pid(&self, id: &str) -> Result<Option<u32>>;
+
+    /// Returns the ids of the apps the runtime is tracking, including daemon
+    /// apps whose start has already returned.
+    async fn running_apps(&self) -> Vec<String>;
 }
 
 #[derive(Clone)]
@@ -360,4 +360,8 @@ impl Runtime for TeeWasmRuntime {
     async fn get_pid(&self, _id: &str) -> Result<Option<u32>> {
         Ok(None)
     }
+
+    async fn running_apps(&self) -> Vec<String> {
+        Vec::new()
+    }
 }
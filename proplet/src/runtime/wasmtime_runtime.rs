@@ -47,6 +47,29 @@ fn is_proxy_component(bytes: &[u8]) -> bool {
         .any(|w| w == b"wasi:http/incoming-handler")
 }
 
+/// Gives a store its task's instruction budget. Fuel metering is enabled on
+/// the engine, so a store without a budget would trap on its first
+/// instruction; unmetered tasks get an effectively unlimited one instead.
+fn set_fuel_budget<T>(store: &mut Store<T>, max_instructions: Option<u64>) -> Result<()> {
+    store
+        .set_fuel(max_instructions.unwrap_or(u64::MAX))
+        .map_err(|e| anyhow::anyhow!("Failed to set fuel budget: {e}"))
+}
+
+/// Returns the "fuel exhausted" error reported for a task that ran out of its
+/// instruction budget, or `None` when `err` has another cause.
+fn fuel_exhausted_error(
+    err: &wasmtime::Error,
+    max_instructions: Option<u64>,
+) -> Option<anyhow::Error> {
+    match (err.downcast_ref::<Trap>(), max_instructions) {
+        (Some(Trap::OutOfFuel), Some(budget)) => Some(anyhow::anyhow!(
+            "fuel exhausted: task used its budget of {budget} instructions"
+        )),
+        _ => None,
+    }
+}
+
 fn find_available_port(start_port: u16) -> Result<(Socket, u16)> {
     let max_attempts = 100u16;
     for port in start_port..start_port.saturating_add(max_attempts) {
@@ -455,6 +478,7 @@ impl WasmtimeRuntime {
         config.wasm_bulk_memory(true);
         config.wasm_simd(true);
         config.wasm_component_model(true);
+        config.consume_fuel(true);
 
         let engine = Engine::new(&config)?;
 
@@ -595,6 +619,10 @@ impl Runtime for WasmtimeRuntime {
 
         Ok(Some(std::process::id()))
     }
+
+    async fn running_apps(&self) -> Vec<String> {
+        self.tasks.lock().await.keys().cloned().collect()
+    }
 }
 
 impl WasmtimeRuntime {
@@ -627,6 +655,7 @@ impl WasmtimeRuntime {
         let wasi = wasi_builder.build_p1();
 
         let mut store = Store::new(&self.engine, wasi);
+        set_fuel_budget(&mut store, config.max_instructions)?;
 
         let mut linker = Linker::new(&self.engine);
         let _ = wasmtime_wasi::p1::add_to_linker_sync(&mut linker, |ctx| ctx)
@@ -691,6 +720,7 @@ impl WasmtimeRuntime {
         };
 
         let mut store = Store::new(&self.engine, store_data);
+        set_fuel_budget(&mut store, config.max_instructions)?;
 
         let mut linker: component::Linker<StoreData> = component::Linker::new(&self.engine);
         let _ = wasmtime_wasi::p2::add_to_linker_async(&mut linker)
@@ -713,6 +743,7 @@ impl WasmtimeRuntime {
         let task_id = config.id.clone();
         let task_id_for_cleanup = task_id.clone();
         let tasks = self.tasks.clone();
+        let max_instructions = config.max_instructions;
 
         let (result_tx, result_rx) = oneshot::channel();
 
@@ -734,7 +765,9 @@ impl WasmtimeRuntime {
                         .call_run(&mut store)
                         .await
                         .map_err(|e| {
-                            anyhow::anyhow!("Failed to call wasi:cli/run on component: {e}")
+                            fuel_exhausted_error(&e, max_instructions).unwrap_or_else(|| {
+                                anyhow::anyhow!("Failed to call wasi:cli/run on component: {e}")
+                            })
                         })?;
 
                 match program_result {
@@ -813,6 +846,7 @@ impl WasmtimeRuntime {
         };
 
         let mut store = Store::new(&self.engine, store_data);
+        set_fuel_budget(&mut store, config.max_instructions)?;
 
         let mut linker: component::Linker<StoreData> = component::Linker::new(&self.engine);
         let _ = wasmtime_wasi::p2::add_to_linker_async(&mut linker)
@@ -838,6 +872,7 @@ impl WasmtimeRuntime {
         let args = config.args.clone();
         let tasks = self.tasks.clone();
         let wasm_binary = config.wasm_binary.clone();
+        let max_instructions = config.max_instructions;
 
         let (result_tx, result_rx) = oneshot::channel();
 
@@ -894,7 +929,9 @@ impl WasmtimeRuntime {
                 func.call_async(&mut store, &wasm_args, &mut results)
                     .await
                     .map_err(|e| {
-                        anyhow::anyhow!("Failed to call export '{}': {e}", function_name)
+                        fuel_exhausted_error(&e, max_instructions).unwrap_or_else(|| {
+                            anyhow::anyhow!("Failed to call export '{}': {e}", function_name)
+                        })
                     })?;
 
                 let result_string = results
@@ -1033,6 +1070,7 @@ impl WasmtimeRuntime {
         let tasks = self.tasks.clone();
         let proxy_cancellers = self.proxy_cancellers.clone();
         let http_tls_config = self.http_tls_config.clone();
+        let max_instructions = config.max_instructions;
 
         let (cancel_tx, mut cancel_rx) = watch::channel(false);
         proxy_cancellers
@@ -1090,8 +1128,16 @@ impl WasmtimeRuntime {
                                 let task_id_req = task_id_conn.clone();
                                 let tls_cfg = tls_config.clone();
                                 async move {
-                                    handle_proxy_request(pre, env, dirs, req, task_id_req, tls_cfg)
-                                        .await
+                                    handle_proxy_request(
+                                        pre,
+                                        env,
+                                        dirs,
+                                        req,
+                                        task_id_req,
+                                        tls_cfg,
+                                        max_instructions,
+                                    )
+                                    .await
                                 }
                             }),
                         )
@@ -1121,6 +1167,8 @@ impl WasmtimeRuntime {
         let function_name = config.function_name.clone();
         let args = config.args.clone();
         let tasks = self.tasks.clone();
+        let max_instructions = config.max_instructions;
+        let (done_tx, done_rx) = oneshot::channel::<Result<()>>();
 
         let handle = tokio::task::spawn(async move {
             let task_id_for_blocking = task_id.clone();
@@ -1132,7 +1180,11 @@ impl WasmtimeRuntime {
                     );
                     init_func
                         .call(&mut store, &[], &mut [])
-                        .map_err(|e| anyhow::anyhow!("Failed to initialize WASM runtime via _initialize: {e}"))?;
+                        .map_err(|e| {
+                            fuel_exhausted_error(&e, max_instructions).unwrap_or_else(|| {
+                                anyhow::anyhow!("Failed to initialize WASM runtime via _initialize: {e}")
+                            })
+                        })?;
                     info!(
                         "WASM runtime initialized successfully for task: {}",
                         task_id_for_blocking
@@ -1243,7 +1295,11 @@ impl WasmtimeRuntime {
                     .collect();
 
                 func.call(&mut store, &wasm_args, &mut results)
-                    .map_err(|e| anyhow::anyhow!("Failed to call function '{function_name}': {e}"))?;
+                    .map_err(|e| {
+                        fuel_exhausted_error(&e, max_instructions).unwrap_or_else(|| {
+                            anyhow::anyhow!("Failed to call function '{function_name}': {e}")
+                        })
+                    })?;
 
                 info!("Function '{}' executed successfully", function_name);
 
@@ -1279,21 +1335,25 @@ impl WasmtimeRuntime {
 
             tasks.lock().await.remove(&task_id_for_cleanup);
 
-            match result {
+            let done = match result {
                 Ok(Ok(data)) => {
                     info!(
                         "Task {} completed, result size: {} bytes",
                         task_id,
                         data.len()
                     );
+                    Ok(())
                 }
                 Ok(Err(e)) => {
                     error!("Task {} failed: {}", task_id, e);
+                    Err(e)
                 }
                 Err(e) => {
                     error!("Task {} join error: {}", task_id, e);
+                    Err(anyhow::anyhow!("Task {task_id} join error: {e}"))
                 }
-            }
+            };
+            let _ = done_tx.send(done);
         });
 
         {
@@ -1309,29 +1369,12 @@ impl WasmtimeRuntime {
                 "Running in synchronous mode, waiting for task: {}",
                 config.id
             );
-            // Spawn a watcher that signals completion via the registered handle
-            let task_id = config.id.clone();
-            let tasks = self.tasks.clone();
-            let (result_tx, result_rx) = oneshot::channel();
-
-            let watcher = tokio::spawn(async move {
-                loop {
-                    tokio::time::sleep(tokio::time::Duration::from_millis(100)).await;
-                    let tasks_guard = tasks.lock().await;
-                    if !tasks_guard.contains_key(&task_id) {
-                        let _ = result_tx.send(Ok(Vec::new()));
-                        break;
-                    }
-                    drop(tasks_guard);
-                }
-            });
-
-            let result = match result_rx.await {
-                Ok(result) => result,
-                Err(_) => Err(anyhow::anyhow!("Task was cancelled or panicked")),
-            };
-            watcher.abort();
-            result
+            // Failures such as fuel exhaustion are returned so the caller
+            // reports them; a task stopped via stop_app drops the sender.
+            match done_rx.await {
+                Ok(Err(e)) => Err(e),
+                Ok(Ok(())) | Err(_) => Ok(Vec::new()),
+            }
         }
     }
 }
@@ -1345,6 +1388,7 @@ async fn handle_proxy_request(
     req: hyper::Request<hyper::body::Incoming>,
     task_id: String,
     http_tls_config: Option<Arc<rustls::ClientConfig>>,
+    max_instructions: Option<u64>,
 ) -> Result<hyper::Response<HyperOutgoingBody>> {
     let mut wasi_builder = WasiCtxBuilder::new();
     wasi_builder.inherit_stdio();
@@ -1368,6 +1412,8 @@ async fn handle_proxy_request(
     };
 
     let mut store = Store::new(pre.engine(), store_data);
+    // Proxy components are metered per request.
+    set_fuel_budget(&mut store, max_instructions)?;
 
     let (sender, receiver) = oneshot::channel();
     let incoming = store
@@ -1496,6 +1542,7 @@ mod tests {
             args: Vec::new(),
             mode: None,
             hal_storage_path: None,
+            max_instructions: None,
         };
 
         let result = runtime.start_app(ctx, config).await;
@@ -1507,4 +1554,70 @@ mod tests {
         let output = result.unwrap();
         assert!(!output.is_empty());
     }
+
+    // (module (func (export "run") (loop (br 0))))
+    const SPIN_WASM: &[u8] = &[
+        0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x04, 0x01, 0x60, 0x00, 0x00, 0x03,
+        0x02, 0x01, 0x00, 0x07, 0x07, 0x01, 0x03, 0x72, 0x75, 0x6e, 0x00, 0x00, 0x0a, 0x09, 0x01,
+        0x07, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b,
+    ];
+
+    // (module (func (export "run") (result i32) (i32.const 42)))
+    const ANSWER_WASM: &[u8] = &[
+        0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, 0x01, 0x05, 0x01, 0x60, 0x00, 0x01, 0x7f,
+        0x03, 0x02, 0x01, 0x00, 0x07, 0x07, 0x01, 0x03, 0x72, 0x75, 0x6e, 0x00, 0x00, 0x0a, 0x06,
+        0x01, 0x04, 0x00, 0x41, 0x2a, 0x0b,
+    ];
+
+    fn metered_config(wasm_binary: &[u8], max_instructions: Option<u64>) -> StartConfig {
+        StartConfig {
+            id: uuid::Uuid::new_v4().to_string(),
+            function_name: "run".to_string(),
+            daemon: false,
+            wasm_binary: wasm_binary.to_vec(),
+            cli_args: Vec::new(),
+            env: HashMap::new(),
+            args: Vec::new(),
+            mode: None,
+            hal_storage_path: None,
+            max_instructions,
+        }
+    }
+
+    #[tokio::test]
+    async fn test_fuel_exhausted_stops_task() {
+        let runtime =
+            WasmtimeRuntime::new_with_options(false, false, false, Vec::new(), 8222, None, false)
+                .unwrap();
+        let ctx = RuntimeContext {
+            proplet_id: "test".to_string(),
+        };
+
+        let result = runtime
+            .start_app(ctx, metered_config(SPIN_WASM, Some(10_000)))
+            .await;
+        let err = result.expect_err("infinite loop should exhaust its fuel");
+        assert!(
+            err.to_string().contains("fuel exhausted"),
+            "unexpected error: {err}"
+        );
+        assert!(runtime.running_apps().await.is_empty());
+    }
+
+    #[tokio::test]
+    async fn test_fuel_budget_allows_short_task() {
+        let runtime =
+            WasmtimeRuntime::new_with_options(false, false, false, Vec::new(), 8222, None, false)
+                .unwrap();
+
+        for max_instructions in [Some(10_000), None] {
+            let ctx = RuntimeContext {
+                proplet_id: "test".to_string(),
+            };
+            let result = runtime
+                .start_app(ctx, metered_config(ANSWER_WASM, max_instructions))
+                .await;
+            assert!(result.is_ok(), "metered task failed: {:?}", result.err());
+        }
+    }
 }
@@ -1,9 +1,11 @@
 use crate::config::PropletConfig;
+use crate::limiter::TaskLimiter;
 use crate::metrics::MetricsCollector;
 use crate::monitoring::{system::SystemMonitor, ProcessMonitor};
-use crate::mqtt::{build_topic, MqttMessage, PubSub};
+use crate::mqtt::{build_topic, results_topic_path, Channel, MqttMessage, PubSub};
 use crate::plugin::registry::PluginRegistry;
 use crate::plugin::{TaskInfo as PluginTaskInfo, TaskResult as PluginTaskResult};
+use crate::result_sink::ResultSink;
 use crate::runtime::{Runtime, RuntimeContext, StartConfig};
 use crate::telemetry::PropletMetrics;
 use crate::types::*;
@@ -18,6 +20,11 @@ use tokio::time::Instant;
 use tracing::{debug, error, info, warn, Instrument};
 
 const WASM_FETCH_MAX_BYTES: usize = 100 * 1024 * 1024; // 100MB
+const CAPACITY_EXCEEDED: &str = "capacity exceeded";
+const STOPPED_ERROR: &str = "stopped by stop-all";
+/// How many times a stalled chunk transfer asks the proxy to resend the
+/// chunks it is missing before giving up.
+const MAX_CHUNK_RETRANSMITS: usize = 3;
 
 #[derive(Debug)]
 struct ChunkAssemblyState {
@@ -39,6 +46,13 @@ impl ChunkAssemblyState {
         self.chunks.len() == self.total_chunks
     }
 
+    /// Indices of the chunks not received yet, in order.
+    fn missing(&self) -> Vec<usize> {
+        (0..self.total_chunks)
+            .filter(|idx| !self.chunks.contains_key(idx))
+            .collect()
+    }
+
     fn is_expired(&self, ttl: tokio::time::Duration) -> bool {
         self.created_at.elapsed() > ttl
     }
@@ -60,11 +74,15 @@ pub struct PropletService {
     tee_runtime: Option<Arc<dyn Runtime>>,
     chunk_assembly: Arc<Mutex<HashMap<String, ChunkAssemblyState>>>,
     running_tasks: Arc<Mutex<HashMap<String, TaskState>>>,
+    /// Channel each running task was started from, where its result goes.
+    task_channels: Arc<Mutex<HashMap<String, Channel>>>,
     monitor: Arc<SystemMonitor>,
     metrics_collector: Arc<Mutex<MetricsCollector>>,
     http_client: HttpClient,
     plugin_registry: Option<Arc<PluginRegistry>>,
     metrics: Arc<PropletMetrics>,
+    limiter: TaskLimiter,
+    result_sink: Option<Arc<ResultSink>>,
 }
 
 impl PropletService {
@@ -82,6 +100,8 @@ impl PropletService {
             config.http_tls_ca_cert.as_deref(),
             config.http_tls_insecure_skip_verify,
         );
+        let limiter = TaskLimiter::new(config.max_concurrent_tasks, config.queue_when_full);
+        let result_sink = ResultSink::from_config(&config, http_client.clone()).map(Arc::new);
 
         let service = Self {
             config,
@@ -91,11 +111,14 @@ impl PropletService {
             tee_runtime: None,
             chunk_assembly: Arc::new(Mutex::new(HashMap::new())),
             running_tasks: Arc::new(Mutex::new(HashMap::new())),
+            task_channels: Arc::new(Mutex::new(HashMap::new())),
             monitor,
             metrics_collector,
             http_client,
             plugin_registry,
             metrics,
+            limiter,
+            result_sink,
         };
 
         service.start_chunk_expiry_task();
@@ -118,6 +141,8 @@ impl PropletService {
             config.http_tls_ca_cert.as_deref(),
             config.http_tls_insecure_skip_verify,
         );
+        let limiter = TaskLimiter::new(config.max_concurrent_tasks, config.queue_when_full);
+        let result_sink = ResultSink::from_config(&config, http_client.clone()).map(Arc::new);
 
         let service = Self {
             config,
@@ -127,11 +152,14 @@ impl PropletService {
             tee_runtime: Some(tee_runtime),
             chunk_assembly: Arc::new(Mutex::new(HashMap::new())),
             running_tasks: Arc::new(Mutex::new(HashMap::new())),
+            task_channels: Arc::new(Mutex::new(HashMap::new())),
             monitor,
             metrics_collector,
             http_client,
             plugin_registry,
             metrics,
+            limiter,
+            result_sink,
         };
 
         service.start_chunk_expiry_task();
@@ -207,26 +235,25 @@ impl PropletService {
 
     async fn subscribe_topics(&self) -> Result<()> {
         let qos = self.config.qos();
+        let prefix = &self.config.topic_prefix;
+
+        for channel in self.config.channels() {
+            for path in [
+                "control/manager/start",
+                "control/manager/stop",
+                "control/manager/stop-all",
+            ] {
+                self.pubsub
+                    .subscribe(&channel.topic(prefix, path), qos)
+                    .await?;
+            }
+        }
 
-        let start_topic = build_topic(
-            &self.config.domain_id,
-            &self.config.channel_id,
-            "control/manager/start",
-        );
-        self.pubsub.subscribe(&start_topic, qos).await?;
-
-        let stop_topic = build_topic(
-            &self.config.domain_id,
-            &self.config.channel_id,
-            "control/manager/stop",
-        );
-        self.pubsub.subscribe(&stop_topic, qos).await?;
-
-        let chunk_topic = build_topic(
-            &self.config.domain_id,
-            &self.config.channel_id,
-            "registry/server",
-        );
+        // Wasm binaries are only fetched over the primary channel.
+        let chunk_topic = self
+            .config
+            .primary_channel()
+            .topic(prefix, "registry/server");
         self.pubsub.subscribe(&chunk_topic, qos).await?;
 
         Ok(())
@@ -295,15 +322,12 @@ impl PropletService {
             },
         };
 
-        let topic = build_topic(
-            &self.config.domain_id,
-            &self.config.channel_id,
-            "control/proplet/create",
-        );
-
-        self.pubsub
-            .publish(&topic, &discovery, self.config.qos())
-            .await?;
+        for channel in self.config.channels() {
+            let topic = channel.topic(&self.config.topic_prefix, "control/proplet/create");
+            self.pubsub
+                .publish(&topic, &discovery, self.config.qos())
+                .await?;
+        }
         info!("Published discovery message");
 
         Ok(())
@@ -327,26 +351,35 @@ impl PropletService {
 
         let running_tasks = self.running_tasks.lock().await;
         proplet.task_count = running_tasks.len();
+        let task_channels = self.task_channels.lock().await;
+        let primary = self.config.primary_channel();
+
+        // Each channel only learns about the tasks it started.
+        for channel in self.config.channels() {
+            let task_ids: Vec<String> = running_tasks
+                .keys()
+                .filter(|id| task_channels.get(*id).unwrap_or(&primary) == &channel)
+                .cloned()
+                .collect();
+
+            let liveliness = LivelinessMessage {
+                proplet_id: self.config.client_id.clone(),
+                status: "alive".to_string(),
+                namespace: self
+                    .config
+                    .k8s_namespace
+                    .clone()
+                    .unwrap_or_else(|| "default".to_string()),
+                running_tasks: self.limiter.running(),
+                max_concurrent_tasks: self.limiter.limit(),
+                task_ids,
+            };
 
-        let liveliness = LivelinessMessage {
-            proplet_id: self.config.client_id.clone(),
-            status: "alive".to_string(),
-            namespace: self
-                .config
-                .k8s_namespace
-                .clone()
-                .unwrap_or_else(|| "default".to_string()),
-        };
-
-        let topic = build_topic(
-            &self.config.domain_id,
-            &self.config.channel_id,
-            "control/proplet/alive",
-        );
-
-        self.pubsub
-            .publish(&topic, &liveliness, self.config.qos())
-            .await?;
+            let topic = channel.topic(&self.config.topic_prefix, "control/proplet/alive");
+            self.pubsub
+                .publish(&topic, &liveliness, self.config.qos())
+                .await?;
+        }
         debug!("Published liveliness update");
 
         Ok(())
@@ -387,6 +420,7 @@ impl PropletService {
 
         #[derive(serde::Serialize)]
         struct PropletMetricsMessage {
+            message_id: String,
             proplet_id: String,
             namespace: String,
             timestamp: SystemTime,
@@ -395,6 +429,7 @@ impl PropletService {
         }
 
         let msg = PropletMetricsMessage {
+            message_id: new_message_id(),
             proplet_id: self.config.client_id.clone(),
             namespace: self
                 .config
@@ -406,13 +441,10 @@ impl PropletService {
             memory_metrics,
         };
 
-        let topic = build_topic(
-            &self.config.domain_id,
-            &self.config.channel_id,
-            "control/proplet/metrics",
-        );
-
-        self.pubsub.publish(&topic, &msg, self.config.qos()).await?;
+        for channel in self.config.channels() {
+            let topic = channel.topic(&self.config.topic_prefix, "control/proplet/metrics");
+            self.pubsub.publish(&topic, &msg, self.config.qos()).await?;
+        }
         debug!("Published proplet metrics");
 
         Ok(())
@@ -437,10 +469,15 @@ impl PropletService {
             debug!("Raw message payload: {}", payload_str);
         }
 
+        let channel = Channel::from_topic(&self.config.topic_prefix, &msg.topic)
+            .unwrap_or_else(|| self.config.primary_channel());
+
         if msg.topic.contains("control/manager/start") {
-            self.handle_start_command(msg).await
+            self.handle_start_command(msg, channel).await
+        } else if msg.topic.contains("control/manager/stop-all") {
+            self.handle_stop_all_command(channel).await
         } else if msg.topic.contains("control/manager/stop") {
-            self.handle_stop_command(msg).await
+            self.handle_stop_command(msg, channel).await
         } else if msg.topic.contains("registry/server") {
             self.handle_chunk(msg).await
         } else {
@@ -449,8 +486,12 @@ impl PropletService {
         }
     }
 
-    #[tracing::instrument(skip(self, msg), name = "task.start", fields(task_id, task_name))]
-    async fn handle_start_command(&self, msg: MqttMessage) -> Result<()> {
+    #[tracing::instrument(
+        skip(self, msg, channel),
+        name = "task.start",
+        fields(task_id, task_name)
+    )]
+    async fn handle_start_command(&self, msg: MqttMessage, channel: Channel) -> Result<()> {
         let req: StartRequest = msg.decode().map_err(|e| {
             error!(
                 "Failed to decode start request ({} bytes): {}",
@@ -463,6 +504,9 @@ impl PropletService {
 
         tracing::Span::current().record("task_id", req.id.as_str());
         tracing::Span::current().record("task_name", req.name.as_str());
+        if let Some(ref traceparent) = req.traceparent {
+            continue_trace(&tracing::Span::current(), traceparent);
+        }
 
         if !req.broadcast {
             if let Some(ref target_id) = req.proplet_id {
@@ -491,8 +535,14 @@ impl PropletService {
         if let Some(ref registry) = self.plugin_registry {
             if let Some(reason) = registry.authorize(&plugin_task_info)? {
                 error!("Plugin denied task {}: {}", req.id, reason);
-                self.publish_result(&req.id, Vec::new(), Some(reason.clone()))
-                    .await?;
+                self.publish_result(
+                    &channel,
+                    &req.id,
+                    req.traceparent.as_deref(),
+                    Vec::new(),
+                    Some(reason.clone()),
+                )
+                .await?;
                 return Err(anyhow::anyhow!("task denied by plugin: {}", reason));
             }
         }
@@ -510,7 +560,9 @@ impl PropletService {
             } else {
                 error!("TEE runtime not available but encrypted workload requested");
                 self.publish_result(
+                    &channel,
                     &req.id,
+                    req.traceparent.as_deref(),
                     Vec::new(),
                     Some("TEE runtime not available".to_string()),
                 )
@@ -537,6 +589,31 @@ impl PropletService {
             }
         }
 
+        // With queueing enabled the spawned task waits for a slot instead.
+        let permit = if self.limiter.queue_when_full() {
+            None
+        } else if let Some(permit) = self.limiter.try_acquire() {
+            Some(permit)
+        } else {
+            warn!(
+                "Rejecting task {}: {} tasks already running",
+                req.id,
+                self.limiter.limit()
+            );
+            self.running_tasks.lock().await.remove(&req.id);
+            self.metrics.tasks_failed.inc();
+            self.metrics.tasks_running.dec();
+            self.publish_result(
+                &channel,
+                &req.id,
+                req.traceparent.as_deref(),
+                Vec::new(),
+                Some(CAPACITY_EXCEEDED.to_string()),
+            )
+            .await?;
+            return Ok(());
+        };
+
         let wasm_binary = if !req.file.is_empty() {
             use base64::{engine::general_purpose::STANDARD, Engine};
             match STANDARD.decode(&req.file) {
@@ -550,8 +627,14 @@ impl PropletService {
                     self.running_tasks.lock().await.remove(&req.id);
                     self.metrics.tasks_failed.inc();
                     self.metrics.tasks_running.dec();
-                    self.publish_result(&req.id, Vec::new(), Some(e.to_string()))
-                        .await?;
+                    self.publish_result(
+                        &channel,
+                        &req.id,
+                        req.traceparent.as_deref(),
+                        Vec::new(),
+                        Some(e.to_string()),
+                    )
+                    .await?;
                     return Err(e.into());
                 }
             }
@@ -571,8 +654,14 @@ impl PropletService {
                         self.running_tasks.lock().await.remove(&req.id);
                         self.metrics.tasks_failed.inc();
                         self.metrics.tasks_running.dec();
-                        self.publish_result(&req.id, Vec::new(), Some(e.to_string()))
-                            .await?;
+                        self.publish_result(
+                            &channel,
+                            &req.id,
+                            req.traceparent.as_deref(),
+                            Vec::new(),
+                            Some(e.to_string()),
+                        )
+                        .await?;
                         return Err(e);
                     }
                 }
@@ -586,8 +675,14 @@ impl PropletService {
                     self.running_tasks.lock().await.remove(&req.id);
                     self.metrics.tasks_failed.inc();
                     self.metrics.tasks_running.dec();
-                    self.publish_result(&req.id, Vec::new(), Some(e.to_string()))
-                        .await?;
+                    self.publish_result(
+                        &channel,
+                        &req.id,
+                        req.traceparent.as_deref(),
+                        Vec::new(),
+                        Some(e.to_string()),
+                    )
+                    .await?;
                     return Err(e);
                 }
 
@@ -601,8 +696,14 @@ impl PropletService {
                         self.running_tasks.lock().await.remove(&req.id);
                         self.metrics.tasks_failed.inc();
                         self.metrics.tasks_running.dec();
-                        self.publish_result(&req.id, Vec::new(), Some(e.to_string()))
-                            .await?;
+                        self.publish_result(
+                            &channel,
+                            &req.id,
+                            req.traceparent.as_deref(),
+                            Vec::new(),
+                            Some(e.to_string()),
+                        )
+                        .await?;
                         return Err(e);
                     }
                 }
@@ -613,30 +714,44 @@ impl PropletService {
             self.running_tasks.lock().await.remove(&req.id);
             self.metrics.tasks_failed.inc();
             self.metrics.tasks_running.dec();
-            self.publish_result(&req.id, Vec::new(), Some(err.to_string()))
-                .await?;
+            self.publish_result(
+                &channel,
+                &req.id,
+                req.traceparent.as_deref(),
+                Vec::new(),
+                Some(err.to_string()),
+            )
+            .await?;
             return Err(err);
         };
 
-        let monitoring_profile = req.monitoring_profile.clone().unwrap_or_else(|| {
-            if req.daemon {
-                MonitoringProfile::long_running_daemon()
-            } else {
-                MonitoringProfile::standard()
-            }
-        });
+        let monitoring_profile = req
+            .monitoring_profile
+            .clone()
+            .map(MonitoringProfile::resolve)
+            .unwrap_or_else(|| {
+                if req.daemon {
+                    MonitoringProfile::long_running_daemon()
+                } else {
+                    MonitoringProfile::standard()
+                }
+            });
 
         let pubsub = self.pubsub.clone();
         let running_tasks = self.running_tasks.clone();
         let monitor = self.monitor.clone();
         let metrics = self.metrics.clone();
-        let domain_id = self.config.domain_id.clone();
-        let channel_id = self.config.channel_id.clone();
+        let topic_prefix = self.config.topic_prefix.clone();
+        let task_channels = self.task_channels.clone();
         let qos = self.config.qos();
         let proplet_id = self.config.client_id.clone();
+        let fl_signing_key = self.config.fl_signing_key.clone();
+        let result_sink = self.result_sink.clone();
         let task_id = req.id.clone();
         let task_name = req.name.clone();
         let plugin_registry = self.plugin_registry.clone();
+        let limiter = self.limiter.clone();
+        let traceparent = req.traceparent.clone();
         let mut env = req.env.unwrap_or_default();
         if !env.is_empty() {
             info!(
@@ -672,9 +787,22 @@ impl PropletService {
 
         let export_metrics = monitoring_profile.enabled && monitoring_profile.export_to_mqtt;
 
+        task_channels
+            .lock()
+            .await
+            .insert(task_id.clone(), channel.clone());
+
         let execute_span =
             tracing::info_span!("task.execute", task_id = %task_id, task_name = %task_name);
         tokio::spawn(async move {
+            let _permit = match permit {
+                Some(permit) => permit,
+                None => {
+                    debug!("Task {} waiting for a free execution slot", task_id);
+                    limiter.acquire().await
+                }
+            };
+
             let ctx = RuntimeContext {
                 proplet_id: proplet_id.clone(),
             };
@@ -702,6 +830,7 @@ impl PropletService {
                 args: inputs,
                 mode: req.mode.clone(),
                 hal_storage_path: req.hal_storage_path.clone(),
+                max_instructions: req.max_instructions,
             };
 
             if export_metrics {
@@ -718,8 +847,8 @@ impl PropletService {
                 let runtime_clone = runtime.clone();
                 let task_id_clone = task_id.clone();
                 let pubsub_clone = pubsub.clone();
-                let domain_clone = domain_id.clone();
-                let channel_clone = channel_id.clone();
+                let prefix_clone = topic_prefix.clone();
+                let channel_clone = channel.clone();
                 let proplet_id_clone = proplet_id.clone();
 
                 Some(tokio::spawn(async move {
@@ -731,13 +860,14 @@ impl PropletService {
                         monitor_clone
                             .attach_pid(&task_id_clone, pid, move |metrics, aggregated| {
                                 let pubsub = pubsub_clone.clone();
-                                let domain = domain_clone.clone();
+                                let prefix = prefix_clone.clone();
                                 let channel = channel_clone.clone();
                                 let task_id = task_id_for_closure.clone();
                                 let proplet_id = proplet_id_for_closure.clone();
 
                                 tokio::spawn(async move {
                                     let metrics_msg = MetricsMessage {
+                                        message_id: new_message_id(),
                                         task_id,
                                         proplet_id,
                                         metrics,
@@ -745,11 +875,8 @@ impl PropletService {
                                         timestamp: SystemTime::now(),
                                     };
 
-                                    let topic = build_topic(
-                                        &domain,
-                                        &channel,
-                                        "control/proplet/task_metrics",
-                                    );
+                                    let topic =
+                                        channel.topic(&prefix, "control/proplet/task_metrics");
                                     if let Err(e) = pubsub.publish(&topic, &metrics_msg, qos).await
                                     {
                                         debug!("Failed to publish task metrics: {}", e);
@@ -768,9 +895,13 @@ impl PropletService {
                 None
             };
 
-            // Fetch model if MODEL_URI is present (FML task)
+            // Fetch model if MODEL_URI is present (FML task), unless the manager
+            // already seeded MODEL_DATA from an OCI starting model.
             // Use environment variables only - no fallbacks, must be set in .env file
-            if let Some(model_uri) = env.get("MODEL_URI") {
+            if let Some(model_uri) = env
+                .get("MODEL_URI")
+                .filter(|_| !env.contains_key("MODEL_DATA"))
+            {
                 let model_registry_url = match env
                     .get("MODEL_REGISTRY_URL")
                     .cloned()
@@ -991,24 +1122,38 @@ impl PropletService {
                 }
             }
 
+            // Results are published under the proplet's own identity so the
+            // manager can bind the claimed proplet_id to the publisher.
+            let results_path = results_topic_path(&proplet_id);
+
             if env.contains_key("ROUND_ID") {
-                let update_envelope =
-                    build_fl_update_envelope(&task_id, &proplet_id, &result_str, &env);
+                let update_envelope = build_fl_update_envelope(
+                    &task_id,
+                    &proplet_id,
+                    &result_str,
+                    &env,
+                    fl_signing_key.as_deref(),
+                );
 
                 #[derive(serde::Serialize)]
                 struct FLResultMessage {
+                    message_id: String,
                     task_id: String,
                     results: serde_json::Value,
                     error: Option<String>,
+                    #[serde(skip_serializing_if = "Option::is_none")]
+                    traceparent: Option<String>,
                 }
 
                 let fl_result = FLResultMessage {
+                    message_id: new_message_id(),
                     task_id: task_id.clone(),
                     results: serde_json::to_value(&update_envelope).unwrap_or_default(),
                     error,
+                    traceparent,
                 };
 
-                let topic = build_topic(&domain_id, &channel_id, "control/proplet/results");
+                let topic = channel.topic(&topic_prefix, &results_path);
                 info!("Publishing FL update for task {}", task_id);
 
                 if let Err(e) = pubsub.publish(&topic, &fl_result, qos).await {
@@ -1017,14 +1162,22 @@ impl PropletService {
                     info!("Successfully published FL update for task {}", task_id);
                 }
             } else {
-                let result_msg = ResultMessage {
+                let mut result_msg = ResultMessage {
+                    message_id: new_message_id(),
                     task_id: task_id.clone(),
                     proplet_id,
                     results: result_str,
                     error,
+                    traceparent,
+                    result_ref: None,
                 };
+                if let Some(ref sink) = result_sink {
+                    if let Err(e) = sink.offload(&mut result_msg).await {
+                        warn!("{:#}, publishing it inline", e);
+                    }
+                }
 
-                let topic = build_topic(&domain_id, &channel_id, "control/proplet/results");
+                let topic = channel.topic(&topic_prefix, &results_path);
 
                 info!("Publishing result for task {}", task_id);
 
@@ -1038,25 +1191,99 @@ impl PropletService {
             if running_tasks.lock().await.remove(&task_id).is_some() {
                 metrics.tasks_running.dec();
             }
+            task_channels.lock().await.remove(&task_id);
         }.instrument(execute_span));
 
         Ok(())
     }
 
-    #[tracing::instrument(skip(self, msg), name = "task.stop", fields(task_id))]
-    async fn handle_stop_command(&self, msg: MqttMessage) -> Result<()> {
+    #[tracing::instrument(skip(self, msg, channel), name = "task.stop", fields(task_id))]
+    async fn handle_stop_command(&self, msg: MqttMessage, channel: Channel) -> Result<()> {
         let req: StopRequest = msg.decode()?;
         req.validate()?;
 
         tracing::Span::current().record("task_id", req.id.as_str());
         info!("Received stop command for task: {}", req.id);
 
+        if !self.started_on(&req.id, &channel).await {
+            warn!(
+                "Ignoring stop command for task {} from channel {}: task was started on another channel",
+                req.id, channel.channel_id
+            );
+            return Ok(());
+        }
+
         self.runtime.stop_app(req.id.clone()).await?;
         self.monitor.stop_monitoring(&req.id).await.ok();
 
         if self.running_tasks.lock().await.remove(&req.id).is_some() {
             self.metrics.tasks_running.dec();
         }
+        self.task_channels.lock().await.remove(&req.id);
+
+        Ok(())
+    }
+
+    /// Reports whether task `id` was started from `channel`. Tasks with no
+    /// recorded channel, such as daemons, belong to the primary channel.
+    async fn started_on(&self, id: &str, channel: &Channel) -> bool {
+        match self.task_channels.lock().await.get(id) {
+            Some(started) => started == channel,
+            None => *channel == self.config.primary_channel(),
+        }
+    }
+
+    /// Stops every app started from `channel` and tracked by the runtimes,
+    /// e.g. before maintenance, and publishes a stopped result for each.
+    /// Daemon apps are tracked only by the runtime once started, while other
+    /// apps are also running tasks.
+    #[tracing::instrument(skip(self, channel), name = "task.stop_all")]
+    async fn handle_stop_all_command(&self, channel: Channel) -> Result<()> {
+        let mut runtimes = vec![self.runtime.clone()];
+        if let Some(tee_runtime) = &self.tee_runtime {
+            runtimes.push(tee_runtime.clone());
+        }
+
+        let mut stopped = 0;
+        for runtime in runtimes {
+            for id in runtime.running_apps().await {
+                if !self.started_on(&id, &channel).await {
+                    continue;
+                }
+                let daemon = !self.running_tasks.lock().await.contains_key(&id);
+                if daemon {
+                    info!("Stopping daemon task {}", id);
+                } else {
+                    info!("Stopping task {}", id);
+                }
+
+                if let Err(e) = runtime.stop_app(id.clone()).await {
+                    warn!("Failed to stop task {}: {}", id, e);
+                    continue;
+                }
+                self.monitor.stop_monitoring(&id).await.ok();
+                if self.running_tasks.lock().await.remove(&id).is_some() {
+                    self.metrics.tasks_running.dec();
+                }
+                self.task_channels.lock().await.remove(&id);
+
+                if let Err(e) = self
+                    .publish_result(
+                        &channel,
+                        &id,
+                        None,
+                        Vec::new(),
+                        Some(STOPPED_ERROR.to_string()),
+                    )
+                    .await
+                {
+                    error!("Failed to publish stopped result for task {}: {}", id, e);
+                }
+                stopped += 1;
+            }
+        }
+
+        info!("Stopped {} tasks", stopped);
 
         Ok(())
     }
@@ -1109,6 +1336,7 @@ impl PropletService {
 
     async fn request_binary_from_registry(&self, app_name: &str) -> Result<()> {
         let topic = build_topic(
+            &self.config.topic_prefix,
             &self.config.domain_id,
             &self.config.channel_id,
             "registry/proplet",
@@ -1117,10 +1345,12 @@ impl PropletService {
         #[derive(serde::Serialize)]
         struct RegistryRequest {
             app_name: String,
+            max_chunk_size: usize,
         }
 
         let req = RegistryRequest {
             app_name: app_name.to_string(),
+            max_chunk_size: self.config.max_chunk_size(),
         };
         self.pubsub.publish(&topic, &req, self.config.qos()).await?;
 
@@ -1128,14 +1358,65 @@ impl PropletService {
         Ok(())
     }
 
+    async fn request_missing_chunks(&self, app_name: &str, missing: &[usize]) -> Result<()> {
+        let topic = build_topic(
+            &self.config.topic_prefix,
+            &self.config.domain_id,
+            &self.config.channel_id,
+            "registry/proplet/missing",
+        );
+
+        #[derive(serde::Serialize)]
+        struct MissingChunksRequest<'a> {
+            app_name: &'a str,
+            missing: &'a [usize],
+            max_chunk_size: usize,
+        }
+
+        let req = MissingChunksRequest {
+            app_name,
+            missing,
+            max_chunk_size: self.config.max_chunk_size(),
+        };
+        self.pubsub.publish(&topic, &req, self.config.qos()).await?;
+
+        info!(
+            "Requested {} missing chunks for app '{}': {:?}",
+            missing.len(),
+            app_name,
+            missing
+        );
+        Ok(())
+    }
+
+    /// Returns the chunks still missing from a partially received app, or
+    /// `None` if no chunk of it has arrived.
+    async fn missing_chunks(&self, app_name: &str) -> Option<Vec<usize>> {
+        let assembly = self.chunk_assembly.lock().await;
+        assembly
+            .get(app_name)
+            .map(ChunkAssemblyState::missing)
+            .filter(|missing| !missing.is_empty())
+    }
+
     async fn wait_for_binary(&self, app_name: &str) -> Result<Vec<u8>> {
         let timeout = tokio::time::Duration::from_secs(60);
-        let start = tokio::time::Instant::now();
+        let mut start = tokio::time::Instant::now();
         let polling_interval = tokio::time::Duration::from_secs(5);
+        let mut retransmits = 0;
 
         loop {
             if start.elapsed() > timeout {
-                return Err(anyhow::anyhow!("Timeout waiting for binary chunks"));
+                // A partial transfer asks for just the gaps instead of
+                // starting over.
+                match self.missing_chunks(app_name).await {
+                    Some(missing) if retransmits < MAX_CHUNK_RETRANSMITS => {
+                        self.request_missing_chunks(app_name, &missing).await?;
+                        retransmits += 1;
+                        start = tokio::time::Instant::now();
+                    }
+                    _ => return Err(anyhow::anyhow!("Timeout waiting for binary chunks")),
+                }
             }
 
             let assembled = self.try_assemble_chunks(app_name).await?;
@@ -1176,25 +1457,27 @@ impl PropletService {
 
     async fn publish_result(
         &self,
+        channel: &Channel,
         task_id: &str,
+        traceparent: Option<&str>,
         results: Vec<u8>,
         error: Option<String>,
     ) -> Result<()> {
         let proplet_id = self.config.client_id.clone();
+        let results_path = results_topic_path(&proplet_id);
         let result_str = String::from_utf8_lossy(&results).to_string();
 
         let result_msg = ResultMessage {
+            message_id: new_message_id(),
             task_id: task_id.to_string(),
             proplet_id,
             results: result_str,
             error,
+            traceparent: traceparent.map(str::to_string),
+            result_ref: None,
         };
 
-        let topic = build_topic(
-            &self.config.domain_id,
-            &self.config.channel_id,
-            "control/proplet/results",
-        );
+        let topic = channel.topic(&self.config.topic_prefix, &results_path);
 
         self.pubsub
             .publish(&topic, &result_msg, self.config.qos())
@@ -1359,6 +1642,7 @@ fn build_fl_update_envelope(
     proplet_id: &str,
     result_str: &str,
     env: &HashMap<String, String>,
+    signing_key: Option<&str>,
 ) -> serde_json::Value {
     use base64::{engine::general_purpose::STANDARD, Engine};
 
@@ -1376,7 +1660,7 @@ fn build_fl_update_envelope(
 
     let update_b64 = STANDARD.encode(result_str.as_bytes());
 
-    serde_json::json!({
+    let mut envelope = serde_json::json!({
         "task_id": task_id,
         "round_id": round_id,
         "proplet_id": proplet_id,
@@ -1384,7 +1668,37 @@ fn build_fl_update_envelope(
         "update_b64": update_b64,
         "format": update_format,
         "metrics": {}
-    })
+    });
+
+    if let Some(key) = signing_key {
+        let signature = sign_fl_update_envelope(key.as_bytes(), &envelope);
+        envelope["signature"] = serde_json::Value::String(signature);
+    }
+
+    envelope
+}
+
+/// Signs an FL update envelope with HMAC-SHA256 over its compact JSON
+/// encoding. serde_json sorts object keys, which is the canonical form the
+/// manager verifies against.
+fn sign_fl_update_envelope(key: &[u8], envelope: &serde_json::Value) -> String {
+    use hmac::{Hmac, Mac};
+    use sha2::Sha256;
+
+    let mut mac = Hmac::<Sha256>::new_from_slice(key).expect("HMAC accepts keys of any length");
+    mac.update(&serde_json::to_vec(envelope).unwrap_or_default());
+    hex::encode(mac.finalize().into_bytes())
+}
+
+/// Parents `span` on the manager's span carried in a W3C traceparent, so a
+/// task is traced across manager, proplet and back.
+fn continue_trace(span: &tracing::Span, traceparent: &str) {
+    use opentelemetry::propagation::TextMapPropagator;
+    use tracing_opentelemetry::OpenTelemetrySpanExt;
+
+    let carrier = HashMap::from([("traceparent".to_string(), traceparent.to_string())]);
+    let parent = opentelemetry_sdk::propagation::TraceContextPropagator::new().extract(&carrier);
+    let _ = span.set_parent(parent);
 }
 
 fn build_http_client(ca_cert_path: Option<&str>, insecure_skip_verify: bool) -> HttpClient {
@@ -1604,4 +1918,292 @@ mod tests {
         assert!(result.is_err());
         assert!(result.unwrap_err().to_string().contains("size limit"));
     }
+
+    #[test]
+    fn test_build_fl_update_envelope_signature() {
+        let env = HashMap::from([
+            ("ROUND_ID".to_string(), "round-1".to_string()),
+            ("FL_NUM_SAMPLES".to_string(), "10".to_string()),
+            ("FL_FORMAT".to_string(), "json-f64".to_string()),
+        ]);
+
+        let unsigned = build_fl_update_envelope("task-1", "proplet-1", "[0.1,0.2]", &env, None);
+        assert!(unsigned.get("signature").is_none());
+
+        // The same envelope and key are signed by the manager's
+        // fl.SignEnvelope, so both sides must agree on this value.
+        let signed =
+            build_fl_update_envelope("task-1", "proplet-1", "[0.1,0.2]", &env, Some("secret"));
+        assert_eq!(
+            signed["signature"],
+            "28ddf470ec35c6ee3f84754eba2a2e7f3637a3ed7d5a49c63586ec86c6e59c39"
+        );
+    }
+
+    struct FakeRuntime {
+        apps: Mutex<Vec<String>>,
+    }
+
+    #[async_trait::async_trait]
+    impl Runtime for FakeRuntime {
+        async fn start_app(&self, _ctx: RuntimeContext, config: StartConfig) -> Result<Vec<u8>> {
+            self.apps.lock().await.push(config.id);
+            Ok(Vec::new())
+        }
+
+        async fn stop_app(&self, id: String) -> Result<()> {
+            self.apps.lock().await.retain(|app| app != &id);
+            Ok(())
+        }
+
+        async fn get_pid(&self, _id: &str) -> Result<Option<u32>> {
+            Ok(None)
+        }
+
+        async fn running_apps(&self) -> Vec<String> {
+            self.apps.lock().await.clone()
+        }
+    }
+
+    async fn new_test_service(runtime: Arc<dyn Runtime>) -> PropletService {
+        let config = PropletConfig {
+            domain_id: "domain".to_string(),
+            channel_id: "channel".to_string(),
+            ..PropletConfig::default()
+        };
+        new_test_service_with_config(config, runtime).await
+    }
+
+    async fn new_test_service_with_config(
+        config: PropletConfig,
+        runtime: Arc<dyn Runtime>,
+    ) -> PropletService {
+        let mqtt_config = crate::mqtt::MqttConfig {
+            address: config.mqtt_address.clone(),
+            client_id: config.client_id.clone(),
+            timeout: config.mqtt_timeout(),
+            qos: config.qos(),
+            keep_alive: config.mqtt_keep_alive(),
+            max_packet_size: config.mqtt_max_packet_size,
+            inflight: config.mqtt_inflight,
+            request_channel_capacity: 16,
+            username: String::new(),
+            password: String::new(),
+            tls_ca_cert: None,
+            tls_client_cert: None,
+            tls_client_key: None,
+            tls_insecure_skip_verify: false,
+        };
+        // The event loop is never polled; published messages stay queued.
+        let (pubsub, _eventloop) = PubSub::new(mqtt_config).await.unwrap();
+        let metrics = Arc::new(PropletMetrics::new().unwrap());
+
+        PropletService::new(config, pubsub, runtime, None, metrics)
+    }
+
+    #[tokio::test]
+    async fn test_stop_all_stops_every_app() {
+        let runtime = Arc::new(FakeRuntime {
+            apps: Mutex::new(Vec::new()),
+        });
+        let service = new_test_service(runtime.clone()).await;
+
+        for (id, daemon) in [("task-1", false), ("task-2", false), ("daemon-1", true)] {
+            let ctx = RuntimeContext {
+                proplet_id: "proplet-1".to_string(),
+            };
+            let config = StartConfig {
+                id: id.to_string(),
+                function_name: "main".to_string(),
+                daemon,
+                wasm_binary: Vec::new(),
+                cli_args: Vec::new(),
+                env: HashMap::new(),
+                args: Vec::new(),
+                mode: None,
+                hal_storage_path: None,
+                max_instructions: None,
+            };
+            runtime.start_app(ctx, config).await.unwrap();
+            if !daemon {
+                service
+                    .running_tasks
+                    .lock()
+                    .await
+                    .insert(id.to_string(), TaskState::Running);
+            }
+        }
+
+        service
+            .handle_message(MqttMessage {
+                topic: "m/domain/c/channel/control/manager/stop-all".to_string(),
+                payload: b"{}".to_vec(),
+                is_reconnect: false,
+            })
+            .await
+            .unwrap();
+
+        assert!(runtime.running_apps().await.is_empty());
+        assert!(service.running_tasks.lock().await.is_empty());
+    }
+
+    /// Keeps every started app running until it is stopped.
+    struct PendingRuntime;
+
+    #[async_trait::async_trait]
+    impl Runtime for PendingRuntime {
+        async fn start_app(&self, _ctx: RuntimeContext, _config: StartConfig) -> Result<Vec<u8>> {
+            std::future::pending().await
+        }
+
+        async fn stop_app(&self, _id: String) -> Result<()> {
+            Ok(())
+        }
+
+        async fn get_pid(&self, _id: &str) -> Result<Option<u32>> {
+            Ok(None)
+        }
+
+        async fn running_apps(&self) -> Vec<String> {
+            Vec::new()
+        }
+    }
+
+    #[tokio::test]
+    async fn test_tasks_report_to_their_channel() {
+        let primary = Channel::new("domain-1", "channel-1");
+        let extra = Channel::new("domain-2", "channel-2");
+        let config = PropletConfig {
+            domain_id: primary.domain_id.clone(),
+            channel_id: primary.channel_id.clone(),
+            extra_channels: vec![extra.clone()],
+            ..PropletConfig::default()
+        };
+        let service = new_test_service_with_config(config, Arc::new(PendingRuntime)).await;
+
+        for (id, channel) in [("task-1", &primary), ("task-2", &extra)] {
+            let req = serde_json::json!({
+                "id": id,
+                "name": "main",
+                "file": "AGFzbQEAAAA=",
+            });
+            service
+                .handle_message(MqttMessage {
+                    topic: channel.topic("m", "control/manager/start"),
+                    payload: serde_json::to_vec(&req).unwrap(),
+                    is_reconnect: false,
+                })
+                .await
+                .unwrap();
+        }
+
+        {
+            let task_channels = service.task_channels.lock().await;
+            assert_eq!(task_channels.get("task-1"), Some(&primary));
+            assert_eq!(task_channels.get("task-2"), Some(&extra));
+        }
+        assert_eq!(
+            extra.topic("m", &results_topic_path(&service.config.client_id)),
+            format!(
+                "m/domain-2/c/channel-2/control/proplet/{}/results",
+                service.config.client_id
+            )
+        );
+
+        // A channel cannot stop a task another channel started.
+        let stop = |channel: &Channel| MqttMessage {
+            topic: channel.topic("m", "control/manager/stop"),
+            payload: br#"{"id":"task-2"}"#.to_vec(),
+            is_reconnect: false,
+        };
+        service.handle_message(stop(&primary)).await.unwrap();
+        assert!(service.running_tasks.lock().await.contains_key("task-2"));
+
+        service.handle_message(stop(&extra)).await.unwrap();
+        assert!(!service.running_tasks.lock().await.contains_key("task-2"));
+        assert!(!service.task_channels.lock().await.contains_key("task-2"));
+        assert!(service.running_tasks.lock().await.contains_key("task-1"));
+    }
+
+    #[test]
+    fn test_chunk_assembly_missing() {
+        let mut state = ChunkAssemblyState::new(4);
+        assert_eq!(state.missing(), vec![0, 1, 2, 3]);
+
+        state.chunks.insert(0, vec![0]);
+        state.chunks.insert(3, vec![3]);
+        assert_eq!(state.missing(), vec![1, 2]);
+        assert!(!state.is_complete());
+    }
+
+    #[tokio::test]
+    async fn test_dropped_middle_chunk_is_rerequested() {
+        use base64::{engine::general_purpose::STANDARD, Engine};
+
+        let runtime = Arc::new(FakeRuntime {
+            apps: Mutex::new(Vec::new()),
+        });
+        let service = new_test_service(runtime).await;
+        let app_name = "registry.local/app";
+
+        // Chunk 1 of 3 is dropped on the way.
+        for idx in [0usize, 2] {
+            let chunk = serde_json::json!({
+                "app_name": app_name,
+                "chunk_idx": idx,
+                "total_chunks": 3,
+                "data": STANDARD.encode([idx as u8]),
+            });
+            service
+                .handle_message(MqttMessage {
+                    topic: "m/domain/c/channel/registry/server".to_string(),
+                    payload: serde_json::to_vec(&chunk).unwrap(),
+                    is_reconnect: false,
+                })
+                .await
+                .unwrap();
+        }
+
+        assert!(service
+            .try_assemble_chunks(app_name)
+            .await
+            .unwrap()
+            .is_none());
+        let missing = service.missing_chunks(app_name).await;
+        assert_eq!(missing, Some(vec![1]));
+        service
+            .request_missing_chunks(app_name, &missing.unwrap())
+            .await
+            .unwrap();
+
+        let chunk = serde_json::json!({
+            "app_name": app_name,
+            "chunk_idx": 1,
+            "total_chunks": 3,
+            "data": STANDARD.encode([1u8]),
+        });
+        service
+            .handle_message(MqttMessage {
+                topic: "m/domain/c/channel/registry/server".to_string(),
+                payload: serde_json::to_vec(&chunk).unwrap(),
+                is_reconnect: false,
+            })
+            .await
+            .unwrap();
+
+        assert_eq!(
+            service.try_assemble_chunks(app_name).await.unwrap(),
+            Some(vec![0, 1, 2])
+        );
+        assert!(service.missing_chunks(app_name).await.is_none());
+    }
+
+    #[tokio::test]
+    async fn test_missing_chunks_without_transfer() {
+        let runtime = Arc::new(FakeRuntime {
+            apps: Mutex::new(Vec::new()),
+        });
+        let service = new_test_service(runtime).await;
+        assert!(service.missing_chunks("never-requested").await.is_none());
+    }
 }
@@ -75,6 +75,7 @@ impl TaskHandler {
             args: Vec::new(),
             mode: Some("train".to_string()),
             hal_storage_path: None,
+            max_instructions: None,
         };
 
         (backend, start_config)
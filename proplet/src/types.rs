@@ -70,6 +70,10 @@ pub struct StartRequest {
     pub broadcast: bool,
     #[serde(default)]
     pub hal_storage_path: Option<String>,
+    #[serde(default)]
+    pub traceparent: Option<String>,
+    #[serde(default)]
+    pub max_instructions: Option<u64>,
 }
 
 fn deserialize_null_default<'de, D, T>(deserializer: D) -> std::result::Result<T, D::Error>
@@ -148,6 +152,15 @@ pub struct LivelinessMessage {
     pub proplet_id: String,
     pub status: String,
     pub namespace: String,
+    #[serde(default)]
+    pub running_tasks: usize,
+    /// Zero means the proplet runs tasks without a concurrency limit.
+    #[serde(default)]
+    pub max_concurrent_tasks: usize,
+    /// IDs of the tasks currently running, so the manager can reconcile its
+    /// view of this proplet.
+    #[serde(default)]
+    pub task_ids: Vec<String>,
 }
 
 #[derive(Debug, Clone, Serialize, Deserialize)]
@@ -179,14 +192,38 @@ pub struct DiscoveryMessage {
 
 #[derive(Debug, Clone, Serialize, Deserialize)]
 pub struct ResultMessage {
+    /// Unique per publish so the manager can drop QoS-1 redeliveries.
+    #[serde(default)]
+    pub message_id: String,
     pub task_id: String,
     pub proplet_id: String,
     pub results: String,
     pub error: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub traceparent: Option<String>,
+    /// Set instead of `results` when the output was uploaded to the result
+    /// sink.
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub result_ref: Option<ResultRef>,
 }
 
+/// Locates a task output uploaded to the result sink.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct ResultRef {
+    pub uri: String,
+    /// Hex-encoded SHA-256 of the uploaded output.
+    pub sha256: String,
+    pub size: usize,
+}
+
+/// Controls how often a task's process metrics are sampled and published.
+/// A profile carrying only a `name` (`off`, `basic` or `detailed`) resolves
+/// to that preset; omitted fields take the standard profile's values.
 #[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
 pub struct MonitoringProfile {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub name: Option<String>,
     pub enabled: bool,
     #[serde(with = "serde_duration")]
     pub interval: Duration,
@@ -203,6 +240,7 @@ pub struct MonitoringProfile {
 impl Default for MonitoringProfile {
     fn default() -> Self {
         Self {
+            name: None,
             enabled: true,
             interval: Duration::from_secs(10),
             collect_cpu: true,
@@ -225,20 +263,23 @@ mod serde_duration {
     where
         S: Serializer,
     {
-        serializer.serialize_u64(duration.as_secs())
+        serializer.serialize_u64(duration.as_nanos() as u64)
     }
 
+    /// Intervals are nanoseconds, matching Go's encoding of time.Duration
+    /// used by the manager.
     pub fn deserialize<'de, D>(deserializer: D) -> Result<Duration, D::Error>
     where
         D: Deserializer<'de>,
     {
-        let secs = u64::deserialize(deserializer)?;
-        Ok(Duration::from_secs(secs))
+        let nanos = u64::deserialize(deserializer)?;
+        Ok(Duration::from_nanos(nanos))
     }
 }
 
 #[derive(Debug, Clone, Serialize, Deserialize)]
 pub struct MetricsMessage {
+    pub message_id: String,
     pub task_id: String,
     pub proplet_id: String,
     pub metrics: crate::monitoring::metrics::ProcessMetrics,
@@ -246,6 +287,11 @@ pub struct MetricsMessage {
     pub timestamp: SystemTime,
 }
 
+/// Returns a fresh ID for an outgoing results or metrics message.
+pub fn new_message_id() -> String {
+    uuid::Uuid::new_v4().to_string()
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -318,6 +364,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         assert!(req.validate().is_ok());
@@ -342,6 +390,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         assert!(req.validate().is_ok());
@@ -366,6 +416,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         let result = req.validate();
@@ -392,6 +444,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         let result = req.validate();
@@ -418,6 +472,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         let result = req.validate();
@@ -447,6 +503,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         assert!(req.validate().is_ok());
@@ -471,6 +529,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         let result = req.validate();
@@ -500,6 +560,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         let result = req.validate();
@@ -617,6 +679,9 @@ mod tests {
             proplet_id: "proplet-123".to_string(),
             status: "alive".to_string(),
             namespace: "default".to_string(),
+            running_tasks: 1,
+            max_concurrent_tasks: 4,
+            task_ids: vec!["task-1".to_string()],
         };
 
         let json = serde_json::to_string(&msg).unwrap();
@@ -625,6 +690,9 @@ mod tests {
         assert_eq!(deserialized.proplet_id, "proplet-123");
         assert_eq!(deserialized.status, "alive");
         assert_eq!(deserialized.namespace, "default");
+        assert_eq!(deserialized.running_tasks, 1);
+        assert_eq!(deserialized.max_concurrent_tasks, 4);
+        assert_eq!(deserialized.task_ids, vec!["task-1".to_string()]);
     }
 
     #[test]
@@ -657,10 +725,13 @@ mod tests {
     #[test]
     fn test_result_message_with_success() {
         let msg = ResultMessage {
+            message_id: new_message_id(),
             task_id: "task-result-1".to_string(),
             proplet_id: Uuid::new_v4().to_string(),
             results: String::from("hello world"),
             error: None,
+            traceparent: None,
+            result_ref: None,
         };
 
         let json = serde_json::to_string(&msg).unwrap();
@@ -674,10 +745,13 @@ mod tests {
     #[test]
     fn test_result_message_with_error() {
         let msg = ResultMessage {
+            message_id: new_message_id(),
             task_id: "task-result-2".to_string(),
             proplet_id: Uuid::new_v4().to_string(),
             results: String::new(),
             error: Some("Execution failed".to_string()),
+            traceparent: None,
+            result_ref: None,
         };
 
         let json = serde_json::to_string(&msg).unwrap();
@@ -688,6 +762,36 @@ mod tests {
         assert_eq!(deserialized.error, Some("Execution failed".to_string()));
     }
 
+    #[test]
+    fn test_traceparent_round_trip() {
+        let traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01";
+        let json = format!(
+            r#"{{"id":"task-trace","name":"traced","traceparent":"{}"}}"#,
+            traceparent
+        );
+        let req: StartRequest = serde_json::from_str(&json).unwrap();
+        assert_eq!(req.traceparent.as_deref(), Some(traceparent));
+
+        let msg = ResultMessage {
+            message_id: new_message_id(),
+            task_id: req.id,
+            proplet_id: "proplet-1".to_string(),
+            results: String::new(),
+            error: None,
+            traceparent: req.traceparent,
+            result_ref: None,
+        };
+        let value = serde_json::to_value(&msg).unwrap();
+        assert_eq!(value["traceparent"], traceparent);
+
+        let untraced = ResultMessage {
+            traceparent: None,
+            ..msg
+        };
+        let value = serde_json::to_value(&untraced).unwrap();
+        assert!(value.get("traceparent").is_none());
+    }
+
     #[test]
     fn test_task_state_serialization() {
         let states = vec![TaskState::Running, TaskState::Completed, TaskState::Failed];
@@ -759,6 +863,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         assert_eq!(req.env.as_ref().unwrap().len(), 2);
@@ -826,6 +932,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         let json = serde_json::to_string(&req).unwrap();
@@ -857,6 +965,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         assert!(req.validate().is_ok());
@@ -881,6 +991,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         assert!(req.validate().is_ok());
@@ -905,6 +1017,8 @@ mod tests {
             proplet_id: None,
             broadcast: false,
             hal_storage_path: None,
+            traceparent: None,
+            max_instructions: None,
         };
 
         let result = req.validate();
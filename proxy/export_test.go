@@ -0,0 +1,11 @@
+package proxy
+
+import "github.com/absmach/propeller/pkg/proplet"
+
+var CreateChunks = createChunks
+
+// RememberChunks records chunks as sent, the way a completed registry fetch
+// does.
+func (s *ProxyService) RememberChunks(name string, chunkSize int, chunks []proplet.ChunkPayload) {
+	s.rememberChunks(name, chunkSize, chunks)
+}
@@ -1,6 +1,7 @@
 package proxy
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,6 +11,9 @@ import (
 
 	"github.com/absmach/propeller/pkg/proplet"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras-go/v2/registry/remote/retry"
@@ -18,8 +22,13 @@ import (
 const (
 	tag  = "latest"
 	size = 1024 * 1024
+
+	// minChunkSize is the smallest chunk size a proplet may ask for.
+	minChunkSize = 4 * 1024
 )
 
+var ErrChunkSizeTooSmall = errors.New("requested chunk size is too small")
+
 type HTTPProxyConfig struct {
 	ChunkSize    int
 	Authenticate bool
@@ -27,6 +36,22 @@ type HTTPProxyConfig struct {
 	Username     string
 	Password     string
 	RegistryURL  string
+	PlainHTTP    bool
+}
+
+// NegotiateChunkSize returns the chunk size to use for a proplet that can
+// receive at most requested bytes per chunk. ChunkSize is the largest chunk
+// the proxy's broker accepts, so larger requests are clamped to it. Zero
+// means the proplet has no preference.
+func (c *HTTPProxyConfig) NegotiateChunkSize(requested int) (int, error) {
+	switch {
+	case requested == 0:
+		return c.ChunkSize, nil
+	case requested < minChunkSize:
+		return 0, fmt.Errorf("%w: %d bytes, minimum is %d", ErrChunkSizeTooSmall, requested, minChunkSize)
+	default:
+		return min(requested, c.ChunkSize), nil
+	}
 }
 
 func (c *HTTPProxyConfig) FetchFromReg(ctx context.Context, containerPath string, chunkSize int) ([]proplet.ChunkPayload, error) {
@@ -34,10 +59,11 @@ func (c *HTTPProxyConfig) FetchFromReg(ctx context.Context, containerPath string
 	if err != nil {
 		return nil, fmt.Errorf("failed to create repository for %s: %w", containerPath, err)
 	}
+	repo.PlainHTTP = c.PlainHTTP
 
 	c.setupAuthentication(repo)
 
-	manifest, err := c.fetchManifest(ctx, repo, containerPath)
+	manifest, err := c.fetchManifest(ctx, repo, containerPath, tag)
 	if err != nil {
 		return nil, err
 	}
@@ -63,6 +89,76 @@ func (c *HTTPProxyConfig) FetchFromReg(ctx context.Context, containerPath string
 	return createChunks(data, containerPath, chunkSize), nil
 }
 
+// PullFromReg fetches the largest layer of the artifact at ref. The ref's tag
+// or digest is resolved, defaulting to "latest".
+func (c *HTTPProxyConfig) PullFromReg(ctx context.Context, ref string) ([]byte, error) {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repository for %s: %w", ref, err)
+	}
+	repo.PlainHTTP = c.PlainHTTP
+
+	c.setupAuthentication(repo)
+
+	reference := repo.Reference.Reference
+	if reference == "" {
+		reference = tag
+	}
+	manifest, err := c.fetchManifest(ctx, repo, ref, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	layer, err := findLargestLayer(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find layer for %s: %w", ref, err)
+	}
+
+	reader, err := repo.Fetch(ctx, layer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch layer for %s: %w", ref, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layer for %s: %w", ref, err)
+	}
+
+	return data, nil
+}
+
+// PushToReg packages data as a single-layer OCI artifact, pushes it to
+// repository under reference and returns the digest-pinned reference of the
+// pushed manifest.
+func (c *HTTPProxyConfig) PushToReg(ctx context.Context, repository, reference, artifactType, mediaType string, data []byte) (string, error) {
+	repo, err := remote.NewRepository(repository)
+	if err != nil {
+		return "", fmt.Errorf("failed to create repository for %s: %w", repository, err)
+	}
+	repo.PlainHTTP = c.PlainHTTP
+
+	c.setupAuthentication(repo)
+
+	layer := content.NewDescriptorFromBytes(mediaType, data)
+	if err := repo.Push(ctx, layer, bytes.NewReader(data)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return "", fmt.Errorf("failed to push layer to %s: %w", repository, err)
+	}
+
+	manifest, err := oras.PackManifest(ctx, repo, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Layers: []ocispec.Descriptor{layer},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to push manifest to %s: %w", repository, err)
+	}
+
+	if err := repo.Tag(ctx, manifest, reference); err != nil {
+		return "", fmt.Errorf("failed to tag %s in %s: %w", reference, repository, err)
+	}
+
+	return fmt.Sprintf("%s/%s@%s", repo.Reference.Registry, repo.Reference.Repository, manifest.Digest), nil
+}
+
 func (c *HTTPProxyConfig) setupAuthentication(repo *remote.Repository) {
 	if !c.Authenticate {
 		return
@@ -87,8 +183,8 @@ func (c *HTTPProxyConfig) setupAuthentication(repo *remote.Repository) {
 	}
 }
 
-func (c *HTTPProxyConfig) fetchManifest(ctx context.Context, repo *remote.Repository, containerName string) (*ocispec.Manifest, error) {
-	descriptor, err := repo.Resolve(ctx, tag)
+func (c *HTTPProxyConfig) fetchManifest(ctx context.Context, repo *remote.Repository, containerName, reference string) (*ocispec.Manifest, error) {
+	descriptor, err := repo.Resolve(ctx, reference)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve manifest for %s: %w", containerName, err)
 	}
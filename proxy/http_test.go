@@ -0,0 +1,67 @@
+package proxy_test
+
+import (
+	"testing"
+
+	"github.com/absmach/propeller/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateChunkSize(t *testing.T) {
+	t.Parallel()
+	cfg := proxy.HTTPProxyConfig{ChunkSize: 512000}
+
+	cases := []struct {
+		desc      string
+		requested int
+		size      int
+		err       error
+	}{
+		{desc: "no preference", requested: 0, size: 512000},
+		{desc: "smaller than the proxy limit", requested: 64 * 1024, size: 64 * 1024},
+		{desc: "beyond the proxy limit", requested: 8 * 1024 * 1024, size: 512000},
+		{desc: "too small", requested: 100, err: proxy.ErrChunkSizeTooSmall},
+		{desc: "negative", requested: -1, err: proxy.ErrChunkSizeTooSmall},
+	}
+	for _, tc := range cases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			size, err := cfg.NegotiateChunkSize(tc.requested)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.size, size)
+		})
+	}
+}
+
+func TestChunkCountFollowsNegotiatedSize(t *testing.T) {
+	t.Parallel()
+	cfg := proxy.HTTPProxyConfig{ChunkSize: 512000}
+	data := make([]byte, 1024*1024)
+
+	cases := []struct {
+		requested int
+		chunks    int
+	}{
+		{requested: 0, chunks: 3},
+		{requested: 256 * 1024, chunks: 4},
+		{requested: 100 * 1024, chunks: 11},
+		{requested: 4 * 1024 * 1024, chunks: 3},
+	}
+	for _, tc := range cases {
+		size, err := cfg.NegotiateChunkSize(tc.requested)
+		require.NoError(t, err)
+
+		chunks := proxy.CreateChunks(data, "app", size)
+		assert.Len(t, chunks, tc.chunks, "requested %d", tc.requested)
+		for _, c := range chunks {
+			assert.LessOrEqual(t, len(c.Data), size)
+			assert.Equal(t, tc.chunks, c.TotalChunks)
+		}
+	}
+}
@@ -2,9 +2,9 @@ package proxy
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	pkgmqtt "github.com/absmach/propeller/pkg/mqtt"
 	"github.com/absmach/propeller/pkg/proplet"
@@ -14,48 +14,79 @@ const (
 	chunkBuffer       = 10
 	containerChanSize = 100
 
-	PubTopic = "m/%s/c/%s/registry/server"
-	SubTopic = "m/%s/c/%s/registry/proplet"
+	// PubTopic and SubTopic are appended to the channel's base topic.
+	PubTopic = "/registry/server"
+	SubTopic = "/registry/proplet"
+	// MissingTopic carries proplets' requests to resend chunks they never
+	// received.
+	MissingTopic = "/registry/proplet/missing"
 
 	maxConcurrentFetches = 50
+	// sentChunksTTL is how long sent chunks are kept for retransmission. It
+	// matches how long a proplet keeps an incomplete assembly.
+	sentChunksTTL = 5 * time.Minute
 )
 
+type sentChunks struct {
+	chunks    []proplet.ChunkPayload
+	chunkSize int
+	sentAt    time.Time
+}
+
 type ProxyService struct {
 	orasconfig    HTTPProxyConfig
 	pubsub        pkgmqtt.PubSub
-	domainID      string
-	channelID     string
+	baseTopic     string
 	logger        *slog.Logger
-	containerChan chan string
+	containerChan chan proplet.ChunkRequest
+	missingChan   chan proplet.MissingChunks
 	dataChan      chan proplet.ChunkPayload
 	mu            sync.Mutex
 	fetching      map[string]bool
 	activeFetches int
+	sent          map[string]sentChunks
 }
 
-func NewService(ctx context.Context, pubsub pkgmqtt.PubSub, domainID, channelID string, httpCfg HTTPProxyConfig, logger *slog.Logger) (*ProxyService, error) {
+func NewService(ctx context.Context, pubsub pkgmqtt.PubSub, domainID, channelID, topicPrefix string, httpCfg HTTPProxyConfig, logger *slog.Logger) (*ProxyService, error) {
 	return &ProxyService{
 		orasconfig:    httpCfg,
 		pubsub:        pubsub,
-		domainID:      domainID,
-		channelID:     channelID,
+		baseTopic:     pkgmqtt.BaseTopic(topicPrefix, domainID, channelID),
 		logger:        logger,
-		containerChan: make(chan string, containerChanSize),
+		containerChan: make(chan proplet.ChunkRequest, containerChanSize),
+		missingChan:   make(chan proplet.MissingChunks, containerChanSize),
 		dataChan:      make(chan proplet.ChunkPayload, chunkBuffer),
 		fetching:      make(map[string]bool),
+		sent:          make(map[string]sentChunks),
 	}, nil
 }
 
-func (s *ProxyService) ContainerChan() chan string {
+func (s *ProxyService) ContainerChan() chan proplet.ChunkRequest {
 	return s.containerChan
 }
 
+func (s *ProxyService) MissingChan() chan proplet.MissingChunks {
+	return s.missingChan
+}
+
 func (s *ProxyService) StreamHTTP(ctx context.Context) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case containerName := <-s.containerChan:
+		case req := <-s.missingChan:
+			go s.retransmit(ctx, req)
+		case req := <-s.containerChan:
+			containerName := req.AppName
+			chunkSize, err := s.orasconfig.NegotiateChunkSize(req.MaxChunkSize)
+			if err != nil {
+				s.logger.Error("rejecting container request",
+					slog.String("container", containerName),
+					slog.Any("error", err))
+
+				continue
+			}
+
 			s.mu.Lock()
 			if s.activeFetches >= maxConcurrentFetches {
 				s.mu.Unlock()
@@ -85,9 +116,10 @@ func (s *ProxyService) StreamHTTP(ctx context.Context) error {
 				}()
 
 				s.logger.Info("fetching container from registry",
-					slog.String("container", name))
+					slog.String("container", name),
+					slog.Int("chunk_size", chunkSize))
 
-				chunks, err := s.orasconfig.FetchFromReg(ctx, name, s.orasconfig.ChunkSize)
+				chunks, err := s.orasconfig.FetchFromReg(ctx, name, chunkSize)
 				if err != nil {
 					s.logger.Error("failed to fetch container",
 						slog.String("container", name),
@@ -100,22 +132,90 @@ func (s *ProxyService) StreamHTTP(ctx context.Context) error {
 					slog.String("container", name),
 					slog.Int("total_chunks", len(chunks)))
 
-				for _, chunk := range chunks {
-					select {
-					case s.dataChan <- chunk:
-						s.logger.Debug("sent container chunk to MQTT stream",
-							slog.String("container", name),
-							slog.Int("chunk", chunk.ChunkIdx),
-							slog.Int("total", chunk.TotalChunks))
-					case <-ctx.Done():
-						return
-					}
-				}
+				s.rememberChunks(name, chunkSize, chunks)
+				s.sendChunks(ctx, chunks)
 			}(containerName)
 		}
 	}
 }
 
+func (s *ProxyService) sendChunks(ctx context.Context, chunks []proplet.ChunkPayload) {
+	for _, chunk := range chunks {
+		select {
+		case s.dataChan <- chunk:
+			s.logger.Debug("sent container chunk to MQTT stream",
+				slog.String("container", chunk.AppName),
+				slog.Int("chunk", chunk.ChunkIdx),
+				slog.Int("total", chunk.TotalChunks))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *ProxyService) rememberChunks(name string, chunkSize int, chunks []proplet.ChunkPayload) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for app, sc := range s.sent {
+		if now.Sub(sc.sentAt) > sentChunksTTL {
+			delete(s.sent, app)
+		}
+	}
+	s.sent[name] = sentChunks{chunks: chunks, chunkSize: chunkSize, sentAt: now}
+}
+
+// retransmit resends only the chunks a proplet reported missing. Chunks sent
+// within sentChunksTTL at the same chunk size are resent from memory;
+// otherwise the container is fetched from the registry again.
+func (s *ProxyService) retransmit(ctx context.Context, req proplet.MissingChunks) {
+	chunkSize, err := s.orasconfig.NegotiateChunkSize(req.MaxChunkSize)
+	if err != nil {
+		s.logger.Error("rejecting missing chunk request",
+			slog.String("container", req.AppName),
+			slog.Any("error", err))
+
+		return
+	}
+
+	s.mu.Lock()
+	sc, ok := s.sent[req.AppName]
+	s.mu.Unlock()
+
+	chunks := sc.chunks
+	if !ok || sc.chunkSize != chunkSize || time.Since(sc.sentAt) > sentChunksTTL {
+		chunks, err = s.orasconfig.FetchFromReg(ctx, req.AppName, chunkSize)
+		if err != nil {
+			s.logger.Error("failed to fetch container for retransmission",
+				slog.String("container", req.AppName),
+				slog.Any("error", err))
+
+			return
+		}
+		s.rememberChunks(req.AppName, chunkSize, chunks)
+	}
+
+	resend := make([]proplet.ChunkPayload, 0, len(req.Missing))
+	for _, idx := range req.Missing {
+		if idx < 0 || idx >= len(chunks) {
+			s.logger.Warn("ignoring request for unknown chunk",
+				slog.String("container", req.AppName),
+				slog.Int("chunk", idx),
+				slog.Int("total", len(chunks)))
+
+			continue
+		}
+		resend = append(resend, chunks[idx])
+	}
+
+	s.logger.Info("retransmitting missing chunks",
+		slog.String("container", req.AppName),
+		slog.Any("chunks", req.Missing))
+	s.sendChunks(ctx, resend)
+}
+
 func (s *ProxyService) StreamMQTT(ctx context.Context) error {
 	containerChunks := make(map[string]int)
 
@@ -124,7 +224,7 @@ func (s *ProxyService) StreamMQTT(ctx context.Context) error {
 		case <-ctx.Done():
 			return ctx.Err()
 		case chunk := <-s.dataChan:
-			if err := s.pubsub.Publish(ctx, fmt.Sprintf(PubTopic, s.domainID, s.channelID), chunk); err != nil {
+			if err := s.pubsub.Publish(ctx, s.baseTopic+PubTopic, chunk); err != nil {
 				s.logger.Error("failed to publish container chunk",
 					slog.Any("error", err),
 					slog.Int("chunk", chunk.ChunkIdx),
@@ -0,0 +1,104 @@
+package proxy_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	mqttmocks "github.com/absmach/propeller/pkg/mqtt/mocks"
+	"github.com/absmach/propeller/pkg/proplet"
+	"github.com/absmach/propeller/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testChunkSize = 512000
+
+func TestRetransmitOnlyMissingChunks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		published []int
+	)
+	pubsub := mqttmocks.NewMockPubSub(t)
+	pubsub.On("Publish", mock.Anything, "m/domain/c/channel"+proxy.PubTopic, mock.Anything).Run(func(args mock.Arguments) {
+		chunk, ok := args.Get(2).(proplet.ChunkPayload)
+		require.True(t, ok)
+		mu.Lock()
+		published = append(published, chunk.ChunkIdx)
+		mu.Unlock()
+	}).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	svc, err := proxy.NewService(ctx, pubsub, "domain", "channel", "m", proxy.HTTPProxyConfig{ChunkSize: testChunkSize}, slog.Default())
+	require.NoError(t, err)
+
+	const app = "registry.local/app"
+	chunks := make([]proplet.ChunkPayload, 3)
+	for i := range chunks {
+		chunks[i] = proplet.ChunkPayload{AppName: app, ChunkIdx: i, TotalChunks: len(chunks), Data: []byte{byte(i)}}
+	}
+	svc.RememberChunks(app, testChunkSize, chunks)
+
+	go func() { _ = svc.StreamHTTP(ctx) }()
+	go func() { _ = svc.StreamMQTT(ctx) }()
+
+	// The proplet got chunks 0 and 2; the middle one was dropped.
+	svc.MissingChan() <- proplet.MissingChunks{AppName: app, Missing: []int{1}}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(published) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{1}, published)
+}
+
+func TestRetransmitIgnoresUnknownChunks(t *testing.T) {
+	t.Parallel()
+
+	pubsub := mqttmocks.NewMockPubSub(t)
+	published := make(chan int, 4)
+	pubsub.On("Publish", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		chunk, _ := args.Get(2).(proplet.ChunkPayload)
+		published <- chunk.ChunkIdx
+	}).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	svc, err := proxy.NewService(ctx, pubsub, "domain", "channel", "m", proxy.HTTPProxyConfig{ChunkSize: testChunkSize}, slog.Default())
+	require.NoError(t, err)
+
+	const app = "registry.local/app"
+	svc.RememberChunks(app, testChunkSize, []proplet.ChunkPayload{
+		{AppName: app, ChunkIdx: 0, TotalChunks: 2},
+		{AppName: app, ChunkIdx: 1, TotalChunks: 2},
+	})
+
+	go func() { _ = svc.StreamHTTP(ctx) }()
+	go func() { _ = svc.StreamMQTT(ctx) }()
+
+	svc.MissingChan() <- proplet.MissingChunks{AppName: app, Missing: []int{-1, 0, 7}}
+
+	select {
+	case idx := <-published:
+		assert.Equal(t, 0, idx)
+	case <-time.After(time.Second):
+		t.Fatal("missing chunk was not retransmitted")
+	}
+	select {
+	case idx := <-published:
+		t.Fatalf("unexpected retransmission of chunk %d", idx)
+	case <-time.After(20 * time.Millisecond):
+	}
+}